@@ -0,0 +1,468 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/am"
+	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+	"github.com/mikejsmith1985/forge-terminal/internal/instance"
+	"github.com/mikejsmith1985/forge-terminal/internal/service"
+	"github.com/mikejsmith1985/forge-terminal/internal/terminal"
+	"github.com/mikejsmith1985/forge-terminal/internal/updater"
+)
+
+const usageText = `Usage:
+  forge [flags]            Start Forge and open it in the browser (or open the
+                           running Forge, with --workspace as a new tab)
+  forge [flags] <dir>      Same as --workspace <dir>: open a tab in dir
+  forge version            Print the version
+  forge update [--check]   Install the latest release (--check only reports it)
+  forge sessions list      List saved tab layouts
+  forge open [dir]         Open a tab in dir (default: the current directory)
+                           in the running Forge
+  forge run <command-id> [--tab id]
+                           Run a command card in the running Forge, in this
+                           tab when run inside Forge
+  forge am last [--json]   Print the most recent AM session
+  forge service install [flags]
+                           Start Forge at login in the background, with these flags
+  forge service uninstall  Remove the background service
+  forge service status     Show whether the service is installed and running
+
+Flags:
+`
+
+// options are the flags for running the server
+type options struct {
+	host        string
+	port        int
+	noBrowser   bool
+	workspace   string
+	newInstance bool
+}
+
+// serverFlags defines the server flags, storing their values in opts.
+func serverFlags(opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet("forge", flag.ExitOnError)
+	flags.Usage = func() { printUsage(flags.Output(), flags) }
+	flags.StringVar(&opts.host, "host", "", "address to listen on (default 127.0.0.1; other addresses require auth)")
+	flags.IntVar(&opts.port, "port", 0, "port to listen on (default: first free of 8333, 8080, 9000, 3000, 3333)")
+	flags.BoolVar(&opts.noBrowser, "no-browser", os.Getenv("NO_BROWSER") != "", "don't open a browser (also NO_BROWSER=1)")
+	flags.StringVar(&opts.workspace, "workspace", "", "directory new tabs start in")
+	flags.BoolVar(&opts.newInstance, "new-instance", false, "start another server even if Forge is already running")
+	return flags
+}
+
+// parseOptions parses the server flags, exiting with usage on bad input.
+func parseOptions(args []string) *options {
+	opts := &options{}
+	flags := serverFlags(opts)
+	flags.Parse(args)
+	opts.validate(flags)
+	return opts
+}
+
+// validate checks parsed options, exiting with usage on bad input.
+func (opts *options) validate(flags *flag.FlagSet) {
+
+	// A single directory argument is the workspace (forge ~/projects/app)
+	if flags.NArg() == 1 && opts.workspace == "" {
+		opts.workspace = flags.Arg(0)
+	} else if flags.NArg() > 0 {
+		fmt.Fprintf(flags.Output(), "Unexpected argument %q\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+	if opts.workspace != "" {
+		dir, err := filepath.Abs(opts.workspace)
+		if err == nil {
+			var info os.FileInfo
+			if info, err = os.Stat(dir); err == nil && !info.IsDir() {
+				err = fmt.Errorf("not a directory")
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --workspace %s: %v\n", opts.workspace, err)
+			os.Exit(2)
+		}
+		opts.workspace = dir
+	}
+}
+
+// args returns the command line flags that reproduce opts.
+func (opts *options) args() []string {
+	var args []string
+	if opts.host != "" {
+		args = append(args, "--host", opts.host)
+	}
+	if opts.port != 0 {
+		args = append(args, "--port", strconv.Itoa(opts.port))
+	}
+	if opts.workspace != "" {
+		args = append(args, "--workspace", opts.workspace)
+	}
+	return args
+}
+
+// openRunning hands this launch over to an already running server: its pages
+// open the workspace as a new tab, or a browser is opened if none are
+// connected. Returns the process exit code.
+func openRunning(running *instance.Info, opts *options) int {
+	pages, err := instance.Open(running, opts.workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Forge is running at %s but could not be opened: %v\n", running.URL, err)
+		fmt.Fprintln(os.Stderr, "Use --new-instance to start another server.")
+		return 1
+	}
+	fmt.Printf("Forge is already running at %s\n", running.URL)
+	if pages == 0 && !opts.noBrowser {
+		openBrowser(running.PageURL(opts.workspace))
+	}
+	return 0
+}
+
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprint(w, usageText)
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+}
+
+// isSubcommand reports whether the command line names a subcommand rather
+// than server flags or a workspace directory. Subcommand names win over
+// directories with the same name.
+func isSubcommand(args []string) bool {
+	if len(args) == 0 || len(args[0]) == 0 || args[0][0] == '-' {
+		return false
+	}
+	switch args[0] {
+	case "version", "update", "sessions", "service", "open", "run", "am", "help":
+		return true
+	}
+	info, err := os.Stat(args[0])
+	return err != nil || !info.IsDir()
+}
+
+// runSubcommand runs a subcommand and returns the process exit code.
+func runSubcommand(args []string) int {
+	switch args[0] {
+	case "version":
+		fmt.Println(updater.GetVersion())
+		return 0
+	case "update":
+		return runUpdate(args[1:])
+	case "sessions":
+		if len(args) == 2 && args[1] == "list" {
+			return runSessionsList(os.Stdout)
+		}
+		fmt.Fprintln(os.Stderr, "Usage: forge sessions list")
+		return 2
+	case "service":
+		return runService(args[1:])
+	case "open":
+		return runOpen(args[1:])
+	case "run":
+		return runCommandCard(args[1:])
+	case "am":
+		if len(args) >= 2 && args[1] == "last" {
+			return runAMLast(os.Stdout, args[2:])
+		}
+		fmt.Fprintln(os.Stderr, "Usage: forge am last [--json]")
+		return 2
+	case "help":
+		printUsage(os.Stdout, serverFlags(&options{}))
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		printUsage(os.Stderr, serverFlags(&options{}))
+		return 2
+	}
+}
+
+// runUpdate checks for a release using the configured channel and source, and
+// installs it unless --check is given. A running server picks the new binary
+// up on its next restart.
+func runUpdate(args []string) int {
+	flags := flag.NewFlagSet("forge update", flag.ContinueOnError)
+	check := flags.Bool("check", false, "only report whether an update is available")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if config, err := commands.LoadConfig(); err == nil {
+		if err := updater.Configure(updaterSettings(config)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring update settings: %v\n", err)
+		}
+	}
+
+	info, err := updater.CheckForUpdate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Update check failed: %v\n", err)
+		return 1
+	}
+	if !info.Available {
+		fmt.Printf("Forge %s is up to date (%s channel)\n", info.CurrentVersion, info.Channel)
+		return 0
+	}
+	fmt.Printf("Update available: %s -> %s\n", info.CurrentVersion, info.LatestVersion)
+	if *check {
+		return 0
+	}
+
+	fmt.Println("Downloading...")
+	path, err := updater.DownloadUpdate(info)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Download failed: %v\n", err)
+		return 1
+	}
+	if err := updater.ApplyUpdate(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Installed Forge %s. Restart Forge to use it.\n", info.LatestVersion)
+	return 0
+}
+
+// runSessionsList prints the current tab layout and the saved named layouts.
+func runSessionsList(w io.Writer) int {
+	current, err := commands.LoadSession()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load session: %v\n", err)
+		return 1
+	}
+	named, _, err := commands.ListNamedSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load named sessions: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(w, "Current layout: %d tab(s)\n", len(current.Tabs))
+	if len(named) == 0 {
+		fmt.Fprintln(w, "No named sessions saved")
+		return 0
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTABS\tUPDATED\t")
+	for _, s := range named {
+		name := s.Name
+		if s.Active {
+			name += " (active)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t\n", name, s.TabCount, s.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	tw.Flush()
+	return 0
+}
+
+// runService installs, removes, or reports on the background service.
+func runService(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: forge service install [flags] | uninstall | status")
+		return 2
+	}
+
+	switch args[0] {
+	case "install":
+		opts := &options{}
+		flags := serverFlags(opts)
+		flags.Parse(args[1:])
+		opts.validate(flags)
+
+		spec, err := service.NewSpec(opts.args())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
+			return 1
+		}
+		path, err := service.Install(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Installed %s\n", path)
+		fmt.Printf("Forge now starts at login; output goes to %s\n", spec.LogPath)
+		if runtime.GOOS == "linux" {
+			fmt.Println("To keep it running after you log out, run: loginctl enable-linger")
+		}
+		return 0
+
+	case "uninstall":
+		if err := service.Uninstall(); err != nil {
+			fmt.Fprintf(os.Stderr, "Uninstall failed: %v\n", err)
+			return 1
+		}
+		fmt.Println("Service removed")
+		return 0
+
+	case "status":
+		status, err := service.GetStatus()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !status.Installed {
+			fmt.Printf("Not installed (%s)\n", status.Manager)
+			return 0
+		}
+		state := "stopped"
+		if status.Running {
+			state = "running"
+		}
+		if status.Detail != "" {
+			state += " (" + status.Detail + ")"
+		}
+		fmt.Printf("Installed: %s\nManager:   %s\nState:     %s\n", status.Path, status.Manager, state)
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown service command %q\n", args[0])
+		return 2
+	}
+}
+
+// runningServer returns the running Forge, or reports that there is none.
+func runningServer() *instance.Info {
+	running := instance.FindRunning()
+	if running == nil {
+		fmt.Fprintln(os.Stderr, "Forge isn't running. Start it with: forge")
+	}
+	return running
+}
+
+// runOpen asks the running Forge to open a tab in a directory.
+func runOpen(args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: forge open [dir]")
+		return 2
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid directory: %v\n", err)
+		return 2
+	}
+
+	running := runningServer()
+	if running == nil {
+		return 1
+	}
+	pages, err := instance.Open(running, dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", dir, err)
+		return 1
+	}
+	if pages == 0 {
+		fmt.Printf("No Forge page is open; opening %s in the browser\n", dir)
+		openBrowser(running.PageURL(dir))
+		return 0
+	}
+	fmt.Printf("Opened %s in a new tab\n", dir)
+	return 0
+}
+
+// runCommandCard runs a command card in the running Forge: in the tab given
+// with --tab, else in the tab this shell belongs to. Cards with their own
+// shell or directory run in a background session.
+func runCommandCard(args []string) int {
+	flags := flag.NewFlagSet("forge run", flag.ContinueOnError)
+	tab := flags.String("tab", os.Getenv(terminal.TabIDEnv), "tab to run the command in (default: this tab, inside Forge)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	// Flags may also follow the ID
+	idArg := flags.Arg(0)
+	if flags.NArg() > 1 {
+		if err := flags.Parse(flags.Args()[1:]); err != nil {
+			return 2
+		}
+		if flags.NArg() > 0 {
+			idArg = ""
+		}
+	}
+	if idArg == "" {
+		fmt.Fprintln(os.Stderr, "Usage: forge run <command-id> [--tab id]")
+		return 2
+	}
+	id, err := strconv.Atoi(idArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid command ID %q\n", idArg)
+		return 2
+	}
+
+	running := runningServer()
+	if running == nil {
+		return 1
+	}
+	var result struct {
+		TabID      string `json:"tabId"`
+		Background bool   `json:"background"`
+	}
+	body := map[string]interface{}{"commandId": id, "tabId": *tab}
+	if err := instance.Call(running, http.MethodPost, "/commands/run", body, &result); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to run command %d: %v\n", id, err)
+		return 1
+	}
+	if result.Background {
+		fmt.Printf("Ran command %d in a background session\n", id)
+	} else {
+		fmt.Printf("Ran command %d in tab %s\n", id, result.TabID)
+	}
+	return 0
+}
+
+// runAMLast prints the most recently active AM session from the running Forge.
+func runAMLast(w io.Writer, args []string) int {
+	flags := flag.NewFlagSet("forge am last", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the session as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	running := runningServer()
+	if running == nil {
+		return 1
+	}
+	var session am.RestoreContext
+	if err := instance.Call(running, http.MethodGet, "/am/last", nil, &session); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get the latest AM session: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(session)
+		return 0
+	}
+
+	status := "finished"
+	if session.WasInterrupted {
+		status = "interrupted"
+	}
+	fmt.Fprintln(w, session.Summary)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Conversation:\t%s\n", session.ConversationID)
+	fmt.Fprintf(tw, "Tab:\t%s\n", session.TabID)
+	fmt.Fprintf(tw, "Started:\t%s\n", session.StartTime.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(tw, "Last active:\t%s\n", session.LastActivity.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(tw, "Status:\t%s\n", status)
+	if session.Metadata != nil && session.Metadata.WorkingDirectory != "" {
+		fmt.Fprintf(tw, "Directory:\t%s\n", session.Metadata.WorkingDirectory)
+	}
+	if session.LastUserPrompt != "" {
+		fmt.Fprintf(tw, "Last prompt:\t%s\n", session.LastUserPrompt)
+	}
+	tw.Flush()
+	return 0
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -16,42 +18,110 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/mikejsmith1985/forge-terminal/internal/access"
 	"github.com/mikejsmith1985/forge-terminal/internal/am"
+	"github.com/mikejsmith1985/forge-terminal/internal/api"
 	"github.com/mikejsmith1985/forge-terminal/internal/assistant"
+	"github.com/mikejsmith1985/forge-terminal/internal/audit"
+	"github.com/mikejsmith1985/forge-terminal/internal/certs"
 	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+	"github.com/mikejsmith1985/forge-terminal/internal/crash"
+	"github.com/mikejsmith1985/forge-terminal/internal/diagnostics"
+	"github.com/mikejsmith1985/forge-terminal/internal/editor"
 	"github.com/mikejsmith1985/forge-terminal/internal/files"
+	"github.com/mikejsmith1985/forge-terminal/internal/forwards"
+	"github.com/mikejsmith1985/forge-terminal/internal/guard"
+	"github.com/mikejsmith1985/forge-terminal/internal/instance"
 	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+	"github.com/mikejsmith1985/forge-terminal/internal/middleware"
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
+	"github.com/mikejsmith1985/forge-terminal/internal/ports"
+	"github.com/mikejsmith1985/forge-terminal/internal/power"
+	"github.com/mikejsmith1985/forge-terminal/internal/profiles"
 	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 	"github.com/mikejsmith1985/forge-terminal/internal/terminal"
 	"github.com/mikejsmith1985/forge-terminal/internal/updater"
+	"github.com/mikejsmith1985/forge-terminal/internal/workspaces"
 )
 
 //go:embed all:web
 var embeddedFS embed.FS
 
-// Preferred ports to try, in order
-var preferredPorts = []int{8333, 8080, 9000, 3000, 3333}
+// Preferred ports to try, in order (preferredPorts in config), and the ones
+// that were taken at startup
+var (
+	preferredPorts = ports.DefaultPreferred
+	portConflicts  []ports.Conflict
+)
 
 // Global assistant service (initialized in main)
 var assistantService assistant.Service
 
+// Global terminal handler (initialized in main), used to drive sessions from API handlers
+var termHandler *terminal.Handler
+
+// Global command scheduler (initialized in main)
+var commandScheduler *commands.Scheduler
+
+// Global watcher for external edits to config.json and commands.json (initialized in main)
+var configWatcher *commands.Watcher
+
+// Per-profile Forge processes that profile requests are proxied to
+var profileManager = profiles.NewManager()
+
+// apiRoutes serves the REST API under /api/v1 and documents it for /api/v1/spec
+var apiRoutes = api.NewRouter(http.DefaultServeMux, WrapWithMiddleware)
+
+// Server log file, start time, and listen address (reported in diagnostics bundles)
+var (
+	serverLogPath   = filepath.Join(storage.GetForgeDir(), "forge.log")
+	serverStartedAt = time.Now()
+	serverAddr      string
+	serverTLS       *certs.Info // Certificate served over HTTPS (nil for plain HTTP)
+)
+
 func main() {
+	// Subcommands (forge version, forge update, ...) run and exit; see cli.go
+	if isSubcommand(os.Args[1:]) {
+		os.Exit(runSubcommand(os.Args[1:]))
+	}
+	opts := parseOptions(os.Args[1:])
+
+	// Show the running Forge rather than starting a second one on another port
+	if !opts.newInstance && storage.CurrentProfile() == "" {
+		if running := instance.FindRunning(); running != nil {
+			os.Exit(openRunning(running, opts))
+		}
+	}
+
 	// Set up file-based logging for production diagnostics
-	logFile, err := os.OpenFile(filepath.Join(os.Getenv("HOME"), ".forge", "forge.log"),
+	var logOutput io.Writer = os.Stdout
+	logFile, err := os.OpenFile(serverLogPath,
 		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err == nil {
 		// Log to both file and stdout
-		log.SetOutput(os.Stdout) // Keep stdout for console
-		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-		// Also write to file by wrapping
-		multiWriter := io.MultiWriter(os.Stdout, logFile)
-		log.SetOutput(multiWriter)
+		logOutput = io.MultiWriter(os.Stdout, logFile)
 		defer logFile.Close()
 	}
+	// Leveled, structured logging (FORGE_LOG_FORMAT=json, FORGE_LOG_LEVEL=debug, or
+	// POST /api/logs/level while running for the AM pipeline's detailed trace). The log
+	// package is routed through it too, and recent entries are served by /api/logs.
+	logging.Setup(logOutput, logging.OptionsFromEnv())
+
+	// A panic that takes the server down leaves a report in ~/.forge/crashes;
+	// the UI is told about it on the next start
+	crash.SetVersion(updater.GetVersion())
+	defer crash.Recover()
+	if notice := crash.PendingNotice(); notice != nil {
+		log.Printf("[Forge] Recovered from a crash at %s: %s (report %s)", notice.Latest.Time.Format(time.RFC3339), notice.Latest.Panic, notice.Latest.ID)
+	}
 
 	// Migrate storage structure if needed
 	log.Printf("[Forge] Checking storage structure...")
@@ -63,6 +133,21 @@ func main() {
 	}
 	log.Printf("[Forge] Storage structure: %s", storage.GetCurrentStructure())
 
+	// Add settings introduced since the config file was written
+	if added, err := commands.MergeConfigDefaults(); err != nil {
+		log.Printf("[Forge] Warning: failed to merge config defaults: %v", err)
+	} else if len(added) > 0 {
+		log.Printf("[Forge] Added default config fields: %s", strings.Join(added, ", "))
+	}
+
+	// Apply server-side file access allowlist, updater and storage settings from config
+	if config, err := commands.LoadConfig(); err == nil {
+		if err := commands.ValidateConfig(config); err != nil {
+			log.Printf("[Forge] Warning: config file has an invalid value: %v", err)
+		}
+		applyServerConfig(config)
+	}
+
 	// Serve embedded frontend with no-cache headers
 	webFS, err := fs.Sub(embeddedFS, "web")
 	if err != nil {
@@ -88,17 +173,20 @@ func main() {
 	// WebSocket terminal handler
 	// Run AM cleanup on startup and initialize AM system
 	go am.CleanupOldLogs()
+	crash.Go(files.ScheduleTrashPurge)
 	amSystem := am.InitSystem(am.DefaultAMDir())
 	if err := amSystem.Start(); err != nil {
 		log.Printf("[AM] Failed to start AM system: %v", err)
 	}
+	crash.Go(func() { am.ScheduleDigests(amSystem.AMDir) })
+	go publishStartupNotifications()
 
 	// Initialize assistant core with AM system
 	assistantCore := assistant.NewCore(amSystem)
 	log.Printf("[Assistant] Core initialized")
 
 	// Index documentation for RAG
-	go func() {
+	crash.Go(func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 		defer cancel()
 
@@ -126,98 +214,123 @@ func main() {
 		} else {
 			log.Printf("[RAG] Docs path not found: %s", docsPath)
 		}
-	}()
+	})
 
 	// Wrap core in LocalService (v1 implementation)
 	assistantService = assistant.NewLocalService(assistantCore)
 	log.Printf("[Assistant] LocalService initialized")
 
-	termHandler := terminal.NewHandler(assistantService, assistantCore)
+	termHandler = terminal.NewHandler(assistantService, assistantCore)
+	if opts.workspace != "" {
+		termHandler.SetDefaultWorkingDir(opts.workspace)
+		if _, err := workspaces.RecordOpen(opts.workspace); err != nil {
+			log.Printf("[Forge] Failed to record workspace %s: %v", opts.workspace, err)
+		}
+		log.Printf("[Forge] New tabs start in %s", opts.workspace)
+	}
+	termHandler.SetOriginCheck(crossOrigin.AllowRequest)
 	http.HandleFunc("/ws", termHandler.HandleWebSocket)
+	power.Watch(func() string {
+		if len(am.GetActiveConversations()) > 0 {
+			return "an AI conversation is running"
+		}
+		if time.Since(termHandler.LastOutputAt()) < power.IdleAfter {
+			return "a terminal is printing output"
+		}
+		return ""
+	})
+	http.HandleFunc(terminal.PreviewPrefix, termHandler.HandlePreview)
+
+	// Scheduled and startup commands run in open tabs
+	commandScheduler = commands.NewScheduler(resolveScheduleTarget)
+	commandScheduler.Start()
+
+	// Pick up external edits to config.json and commands.json without a restart
+	configWatcher = commands.NewWatcher()
+	configWatcher.Start()
+	crash.Go(applyConfigChanges)
+
+	// Background downloads and quiet-hours installs, per the update policy. Only
+	// the host process updates; profile processes run the same binary.
+	if storage.CurrentProfile() == "" {
+		crash.Go(runAutoUpdates)
+	} else {
+		log.Printf("[Forge] Running as profile %s (data: %s)", storage.CurrentProfile(), storage.GetForgeDir())
+	}
+
+	// REST API under /api/v1 (see routes.go)
+	registerAPIRoutes(apiRoutes)
 
-	// Commands API
-	http.HandleFunc("/api/commands", WrapWithMiddleware(handleCommands))
-	http.HandleFunc("/api/commands/restore-defaults", WrapWithMiddleware(handleRestoreDefaultCommands))
-
-	// Config API
-	http.HandleFunc("/api/config", WrapWithMiddleware(handleConfig))
-
-	// WSL detection API
-	http.HandleFunc("/api/wsl/detect", WrapWithMiddleware(handleWSLDetect))
-
-	// Shutdown API - allows graceful shutdown from browser
-	http.HandleFunc("/api/shutdown", WrapWithMiddleware(handleShutdown))
-
-	// Update API - check for updates and apply them
-	http.HandleFunc("/api/version", WrapWithMiddleware(handleVersion))
-	http.HandleFunc("/api/update/check", WrapWithMiddleware(handleUpdateCheck))
-	http.HandleFunc("/api/update/apply", WrapWithMiddleware(handleUpdateApply))
-	http.HandleFunc("/api/update/versions", WrapWithMiddleware(handleListVersions))
-	http.HandleFunc("/api/update/events", WrapWithMiddleware(handleUpdateEvents))                // SSE for push update notifications
-	http.HandleFunc("/api/update/install-manual", WrapWithMiddleware(handleInstallManualUpdate)) // Install manually downloaded binary
-
-	// Sessions API - persist tab state across refreshes
-	http.HandleFunc("/api/sessions", WrapWithMiddleware(handleSessions))
-
-	// Welcome screen API - track if welcome has been shown
-	http.HandleFunc("/api/welcome", WrapWithMiddleware(handleWelcome))
-
-	// AM (Artificial Memory) API - session logging and recovery
-	http.HandleFunc("/api/am/check", WrapWithMiddleware(handleAMCheck))
-	http.HandleFunc("/api/am/check/enhanced", WrapWithMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		handleAMCheckEnhanced(w, r)
-	}))
-	http.HandleFunc("/api/am/check/grouped", WrapWithMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		handleAMCheckGrouped(w, r)
-	}))
-	http.HandleFunc("/api/am/content/", WrapWithMiddleware(handleAMContent))
-	http.HandleFunc("/api/am/archive/", WrapWithMiddleware(handleAMArchive))
-	http.HandleFunc("/api/am/cleanup", WrapWithMiddleware(handleAMCleanup))
-	http.HandleFunc("/api/am/llm/conversations/", WrapWithMiddleware(handleAMLLMConversations))
-	http.HandleFunc("/api/am/llm/conversation/", WrapWithMiddleware(handleAMLLMConversationDetail))
-	http.HandleFunc("/api/am/health", WrapWithMiddleware(handleAMHealth))
-	http.HandleFunc("/api/am/conversations", WrapWithMiddleware(handleAMActiveConversations))
-	http.HandleFunc("/api/am/master-control", WrapWithMiddleware(handleAMMasterControl))
-	http.HandleFunc("/api/am/restore/sessions", WrapWithMiddleware(handleAMRestoreSessions))
-	http.HandleFunc("/api/am/restore/context/", WrapWithMiddleware(handleAMRestoreContext))
-	http.HandleFunc("/api/am/log", WrapWithMiddleware(handleAMLog))
-
-	// Vision Configuration & Insights API
-	http.HandleFunc("/api/vision/config", WrapWithMiddleware(handleVisionConfig))
-	http.HandleFunc("/api/vision/insights/", WrapWithMiddleware(handleVisionInsights))
-	http.HandleFunc("/api/vision/insights/summary/", WrapWithMiddleware(handleVisionInsightsSummary))
-
-	// Diagnostics API - keyboard lockout debugging
-	http.HandleFunc("/api/diagnostics/keyboard", WrapWithMiddleware(handleDiagnosticsKeyboard))
-
-	// Desktop shortcut API
-	http.HandleFunc("/api/desktop-shortcut", WrapWithMiddleware(handleDesktopShortcut))
-
-	// File management API
-	http.HandleFunc("/api/files/list", WrapWithMiddleware(files.HandleList))
-	http.HandleFunc("/api/files/stats", WrapWithMiddleware(files.HandleStats))
-	http.HandleFunc("/api/files/read", WrapWithMiddleware(files.HandleRead))
-	http.HandleFunc("/api/files/write", WrapWithMiddleware(files.HandleWrite))
-	http.HandleFunc("/api/files/delete", WrapWithMiddleware(files.HandleDelete))
-	http.HandleFunc("/api/files/stream", WrapWithMiddleware(files.HandleReadStream))
-	http.HandleFunc("/api/files/access-mode", WrapWithMiddleware(files.HandleFileAccessMode))
-
-	// Assistant API - AI chat and command suggestions (Dev Mode only)
-	http.HandleFunc("/api/assistant/status", WrapWithMiddleware(handleAssistantStatus))
-	http.HandleFunc("/api/assistant/chat", WrapWithMiddleware(handleAssistantChat))
-	http.HandleFunc("/api/assistant/execute", WrapWithMiddleware(handleAssistantExecute))
-	http.HandleFunc("/api/assistant/model", WrapWithMiddleware(handleAssistantSetModel))
-	http.HandleFunc("/api/assistant/run-tests", WrapWithMiddleware(handleAssistantRunTests))
-	http.HandleFunc("/api/assistant/train-model", WrapWithMiddleware(handleAssistantTrainModel))
-	http.HandleFunc("/api/assistant/training-status/", WrapWithMiddleware(handleAssistantTrainingStatus))
+	// Listen address and remote access policy from config and flags
+	host, port, policy, err := remoteAccess(opts.host, opts.port)
+	if err != nil {
+		log.Fatalf("Failed to set up remote access: %v", err)
+	}
 
 	// Find an available port
-	addr, listener, err := findAvailablePort()
+	if config, err := commands.LoadConfig(); err == nil {
+		preferredPorts = ports.Preferred(config.PreferredPorts)
+	}
+	addr, listener, err := findAvailablePort(host, port)
 	if err != nil {
 		log.Fatalf("Failed to find available port: %v", err)
 	}
-
-	log.Printf("🔥 Forge Terminal starting at http://%s", addr)
+	serverAddr = addr
+
+	// Prometheus-style metrics for self-hosters
+	metricsRegistry, httpMetrics := newMetricsRegistry()
+	http.HandleFunc("/metrics", WrapWithMiddleware(metricsRegistry.Handler()))
+
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		// Unversioned /api paths are mapped onto /api/v1 first; every request
+		// is then counted, given an ID and logged, and guarded against handler
+		// panics. CORS and framing headers are added (answering preflight
+		// requests before authentication), remote clients are checked, and
+		// requests for a profile proxied to that profile's own Forge process.
+		Handler: api.Compat(httpMetrics.Middleware(middleware.RequestLog(middleware.Recover(
+			crossOrigin.Middleware(policy.Middleware(profileManager.Middleware(http.DefaultServeMux))))))),
+		TLSConfig: serverTLSConfig(),
+		// Cancelled on shutdown so long-lived requests (SSE streams) end
+		BaseContext: func(net.Listener) context.Context { return requestCtx },
+	}
+	httpServer, cancelHTTPRequests = server, cancelRequests
+	scheme := "http"
+	if server.TLSConfig != nil {
+		scheme = "https"
+	}
+
+	log.Printf("🔥 Forge Terminal starting at %s://%s", scheme, addr)
+
+	// Record this server so later launches open it instead of starting another.
+	// Profile processes sit behind the host server and are never opened directly,
+	// and a --new-instance server leaves the existing record alone.
+	if storage.CurrentProfile() == "" && !opts.newInstance {
+		instanceURL := scheme + "://" + browserAddr(addr)
+		instanceServer := instance.NewServer(instanceURL, updater.GetVersion())
+		instanceServer.SetTabOpener(termHandler.OpenTab)
+		editor.SetTabOpener(func(dir, command string) (string, error) {
+			tabID := termHandler.OpenTabRunning(dir, command)
+			if instanceServer.AnnounceTab(tabID) == 0 {
+				return "", fmt.Errorf("can't open a tab for the editor: no Forge page is connected")
+			}
+			return tabID, nil
+		})
+		apiRoutes.HandleFunc("/instance", instanceServer.HandleInfo, api.Doc{Methods: "GET", Summary: "The running instance", Response: instance.Info{}})
+		apiRoutes.HandleFunc("/instance/open", instanceServer.HandleOpen, api.Doc{Methods: "POST", Summary: "Hand a later launch over to the open pages, with a new tab in workspace if given", Request: instance.OpenRequest{}, Response: instance.OpenResponse{}})
+		apiRoutes.HandleFunc("/instance/events", instanceServer.HandleEvents, api.Doc{Methods: "GET", Summary: "Stream open requests from later launches", Stream: true}) // SSE: "open" when a later launch hands over
+		if err := instance.Register(instanceURL, updater.GetVersion()); err != nil {
+			log.Printf("[Instance] Failed to record running instance: %v", err)
+		}
+	}
+	if !access.IsLoopbackHost(host) {
+		if policy.Token != "" {
+			configPath, _ := commands.GetConfigPath()
+			log.Printf("[Access] Remote access is on at %s://%s; clients need the access token (authToken in %s)", scheme, addr, configPath)
+		} else {
+			log.Printf("[Access] Remote clients must log in as %s", policy.Username)
+		}
+	}
 
 	// Handle graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -226,15 +339,121 @@ func main() {
 	go func() {
 		<-stop
 		log.Println("\n👋 Shutting down Forge...")
-		os.Exit(0)
+		shutdown()
 	}()
 
-	// Auto-open browser (skip if NO_BROWSER env var is set for testing)
-	if os.Getenv("NO_BROWSER") == "" {
-		go openBrowser("http://" + addr)
+	// Auto-open browser (skip with --no-browser or NO_BROWSER, e.g. for testing)
+	if !opts.noBrowser {
+		page := &instance.Info{URL: scheme + "://" + browserAddr(addr)}
+		go openBrowser(page.PageURL(opts.workspace))
+	}
+
+	if server.TLSConfig != nil {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	// Shutting down; shutdown() exits once state is flushed
+	select {}
+}
+
+// Set in main for shutdown
+var (
+	httpServer         *http.Server
+	cancelHTTPRequests context.CancelFunc
+	shutdownOnce       sync.Once
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// shutdown stops the server cleanly and exits: background jobs stop, AM
+// conversations are flushed, terminals are closed (their scrollback saved as
+// for a restart), in-flight requests and writes finish, and profile processes
+// are shut down.
+func shutdown() {
+	shutdownOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if commandScheduler != nil {
+			commandScheduler.Stop()
+		}
+		if configWatcher != nil {
+			configWatcher.Stop()
+		}
+
+		// Save buffered AM output before the sessions that produce it close
+		am.FlushLLMLoggers()
+
+		if termHandler != nil {
+			state := termHandler.Shutdown(ctx)
+			state.Version = updater.GetVersion()
+			if err := terminal.SaveRestartState(state); err != nil {
+				log.Printf("[Forge] Failed to save terminal state: %v", err)
+			} else {
+				log.Printf("[Forge] Saved state for %d terminal(s)", len(state.Sessions))
+			}
+		}
+
+		if httpServer != nil {
+			cancelHTTPRequests()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				log.Printf("[Forge] Timed out waiting for requests: %v", err)
+			}
+		}
+		if !inflightWrites.drain(ctx) {
+			log.Printf("[Forge] Timed out waiting for in-flight writes")
+		}
+		profileManager.StopAll()
+		forwards.StopAll()
+		power.Stop()
+		instance.Unregister()
+		if system := am.GetSystem(); system != nil {
+			system.Stop()
+		}
+
+		log.Printf("[Forge] Shutdown complete")
+		os.Exit(0)
+	})
+}
+
+// serverTLSConfig returns the TLS config to serve HTTPS with when enabled in
+// config, or nil for plain HTTP. Profile processes always serve plain HTTP to
+// the host server's proxy. If the certificate can't be loaded Forge logs why and
+// falls back to HTTP rather than not starting at all.
+func serverTLSConfig() *tls.Config {
+	if storage.CurrentProfile() != "" {
+		return nil
+	}
+	config, err := commands.LoadConfig()
+	if err != nil || !config.TLS {
+		return nil
 	}
+	tlsConfig, info, err := certs.Load(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		log.Printf("[TLS] Failed to load certificate, serving plain HTTP: %v", err)
+		return nil
+	}
+	serverTLS = info
+	log.Printf("[TLS] Serving HTTPS with %s (SHA-256 %s, expires %s)", info.CertFile, info.Fingerprint, info.NotAfter.Format("2006-01-02"))
+	return tlsConfig
+}
 
-	log.Fatal(http.Serve(listener, nil))
+// handleTLS reports whether HTTPS is on and which certificate is served, so
+// users can check the fingerprint before trusting a self-signed certificate
+func handleTLS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":     serverTLS != nil,
+		"certificate": serverTLS, // null when serving plain HTTP
+	})
 }
 
 func handleCommands(w http.ResponseWriter, r *http.Request) {
@@ -260,6 +479,35 @@ func handleCommands(w http.ResponseWriter, r *http.Request) {
 			cmds = migrated
 		}
 
+		usage, err := commands.LoadUsage()
+		if err != nil {
+			log.Printf("[API] Failed to load command usage: %v", err)
+		}
+		commands.ApplyUsage(cmds, usage)
+
+		// Optional filtering: ?group= (empty selects ungrouped cards) and ?tag=
+		query := r.URL.Query()
+		if query.Has("group") || query.Get("tag") != "" {
+			cmds = commands.FilterCommands(cmds, commands.CommandFilter{
+				Group:    query.Get("group"),
+				HasGroup: query.Has("group"),
+				Tag:      query.Get("tag"),
+			})
+		}
+
+		// Optional ?sort=manual|mostUsed|recent
+		if order := query.Get("sort"); order != "" {
+			if err := commands.SortCommands(cmds, order); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Clients send the version back in If-Match so concurrent windows don't clobber each other
+		if version, err := commands.CommandsVersion(); err == nil && version != "" {
+			w.Header().Set("ETag", strconv.Quote(version))
+		}
+
 		log.Printf("[API] Successfully loaded %d commands", len(cmds))
 		json.NewEncoder(w).Encode(cmds)
 
@@ -270,14 +518,40 @@ func handleCommands(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		// The UI sends cards in display order (including after drag-and-drop)
+		commands.AssignOrderFromPosition(cmds)
+		for i := range cmds {
+			cmds[i].Tags = commands.NormalizeTags(cmds[i].Tags)
+		}
+		// Usage fields are echoed back by the UI but tracked separately
+		commands.ApplyUsage(cmds, nil)
+		for i := range cmds {
+			if err := commands.ValidateCommand(&cmds[i]); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		commands.NormalizeKeyBindings(cmds)
+		warnings := commands.ValidateKeyBindings(cmds)
 		log.Printf("[API] Saving %d commands...", len(cmds))
-		if err := commands.SaveCommands(cmds); err != nil {
+		version, err := commands.SaveCommandsIfVersion(cmds, strings.Trim(r.Header.Get("If-Match"), `"`))
+		if err == commands.ErrVersionConflict {
+			log.Printf("[API] Rejected stale commands save")
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
 			log.Printf("[API] Failed to save commands: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		log.Printf("[API] Successfully saved commands")
-		w.WriteHeader(http.StatusOK)
+		w.Header().Set("ETag", strconv.Quote(version))
+		// Bindings are saved as given; warnings let the UI prompt for resolution
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"warnings": warnings,
+		})
 
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -318,7 +592,7 @@ func handleRestoreDefaultCommands(w http.ResponseWriter, r *http.Request) {
 	newCommands := existingCmds
 	restoredCount := 0
 
-	for _, defaultCmd := range commands.DefaultCommands {
+	for _, defaultCmd := range commands.SelectedPackCommands() {
 		// Check if we should restore this command
 		shouldRestore := false
 		if len(req.CommandIDs) == 0 {
@@ -355,105 +629,952 @@ func handleRestoreDefaultCommands(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func openBrowser(url string) {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		// Use Chrome on Windows, fall back to system default if not found
-		chromeExe := "chrome.exe"
-		if _, err := exec.LookPath(chromeExe); err != nil {
-			// Try common installation path
-			chromeExe = filepath.Join(os.Getenv("ProgramFiles"), "Google", "Chrome", "Application", "chrome.exe")
-			if _, err := os.Stat(chromeExe); err != nil {
-				// Try 32-bit path
-				chromeExe = filepath.Join(os.Getenv("ProgramFiles(x86)"), "Google", "Chrome", "Application", "chrome.exe")
-				if _, err := os.Stat(chromeExe); err != nil {
-					// Fall back to system default
-					cmd = exec.Command("cmd", "/c", "start", url)
-					break
-				}
+// handleExportCommands returns a versioned bundle of command cards (?ids=1,2,3 to select)
+func handleExportCommands(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cmds, err := commands.LoadCommands()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var ids []int
+	if idsParam := r.URL.Query().Get("ids"); idsParam != "" {
+		for _, part := range strings.Split(idsParam, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				http.Error(w, "Invalid ids parameter", http.StatusBadRequest)
+				return
 			}
+			ids = append(ids, id)
 		}
-		cmd = exec.Command(chromeExe, url)
 	}
-	if cmd != nil {
-		_ = cmd.Start()
+
+	bundle := commands.ExportCommands(cmds, ids, updater.GetVersion())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="forge-commands.json"`)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(bundle)
+}
+
+// handleImportCommands merges an exported bundle into saved commands.
+// Body: {"bundle": {...}, "strategy": "duplicate"|"overwrite"|"skip"}
+func handleImportCommands(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		Bundle   json.RawMessage `json:"bundle"`
+		Strategy string          `json:"strategy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+
+	bundle, err := commands.ParseExportBundle(req.Bundle)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := commands.LoadCommands()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	merged, summary, err := commands.ImportCommands(existing, bundle, req.Strategy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := commands.SaveCommands(merged); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[API] Imported commands: %+v", summary)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"summary":  summary,
+		"commands": merged,
+	})
 }
 
-func handleShutdown(w http.ResponseWriter, r *http.Request) {
+// handleValidateKeyBindings checks a command list's key bindings without saving it.
+// Body: the same command array POSTed to /api/commands
+func handleValidateKeyBindings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"shutting down"}`))
-	log.Println("👋 Shutdown requested from browser")
-	// Give the response time to send before exiting
-	go func() {
-		<-time.After(500 * time.Millisecond)
-		os.Exit(0)
-	}()
+
+	var cmds []commands.Command
+	if err := json.NewDecoder(r.Body).Decode(&cmds); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"warnings": commands.ValidateKeyBindings(cmds),
+	})
 }
 
-func handleConfig(w http.ResponseWriter, r *http.Request) {
+// handleCommandPacks lists built-in command packs (GET) or installs one (POST).
+// Body: {"packId": "devops", "mode": "merge"|"replace"}. Installing with replace
+// also makes the pack the one used by "restore defaults".
+func handleCommandPacks(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch r.Method {
 	case http.MethodGet:
-		config, err := commands.LoadConfig()
+		selected := commands.DefaultPackID
+		if config, err := commands.LoadConfig(); err == nil && config.CommandPack != "" {
+			selected = config.CommandPack
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"packs":    commands.CommandPacks,
+			"selected": selected,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			PackID string `json:"packId"`
+			Mode   string `json:"mode"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		existing, err := commands.LoadCommands()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		json.NewEncoder(w).Encode(config)
 
-	case http.MethodPost:
-		var config commands.Config
-		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		cmds, summary, err := commands.InstallPack(existing, req.PackID, req.Mode)
+		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		if err := commands.SaveConfig(&config); err != nil {
+		if err := commands.SaveCommands(cmds); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
+
+		if req.Mode == commands.PackReplace {
+			config, err := commands.LoadConfig()
+			if err == nil {
+				updated := *config
+				updated.CommandPack = req.PackID
+				if err := commands.SaveConfig(&updated); err != nil {
+					log.Printf("[API] Failed to save selected command pack: %v", err)
+				}
+			}
+		}
+
+		log.Printf("[API] Installed command pack %s (%s): %+v", req.PackID, req.Mode, summary)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"summary":  summary,
+			"commands": cmds,
+		})
 
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func handleWSLDetect(w http.ResponseWriter, r *http.Request) {
+// commandBackupRestoreRequest is the body for POST /api/v1/commands/history
+type commandBackupRestoreRequest struct {
+	ID string `json:"id"` // e.g. "commands-20240115-083000.000000000.json"
+}
+
+// handleCommandHistory lists commands.json backups (GET) or restores one (POST).
+func handleCommandHistory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if runtime.GOOS != "windows" {
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"available": false,
-			"reason":    "Not running on Windows",
-		})
-		return
-	}
+	switch r.Method {
+	case http.MethodGet:
+		backups, err := commands.ListCommandBackups()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(backups)
 
-	// Get list of WSL distros
-	cmd := exec.Command("wsl", "--list", "--quiet")
-	hideWindow(cmd) // Prevent console window flash
-	output, err := cmd.Output()
-	if err != nil {
+	case http.MethodPost:
+		var req commandBackupRestoreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cmds, err := commands.RestoreCommandBackup(req.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[API] Restored commands from backup %s", req.ID)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"available": false,
-			"reason":    "WSL not installed or not available",
+			"success":  true,
+			"commands": cmds,
 		})
-		return
-	}
 
-	// Parse distro names (handle UTF-16 output from wsl.exe)
-	distros := []string{}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCommandUsage returns run counts by command ID (GET) or records a card run (POST).
+// Body: {"commandId": 3}
+func handleCommandUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		usage, err := commands.LoadUsage()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(usage)
+
+	case http.MethodPost:
+		var req struct {
+			CommandID int `json:"commandId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CommandID <= 0 {
+			http.Error(w, "commandId is required", http.StatusBadRequest)
+			return
+		}
+		if err := commands.RecordUsage(req.CommandID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRenderCommand expands {{input:...}}, {{cwd}}, {{clipboard}} and {{selection}}
+// placeholders in a command card, reporting which inputs still need values
+func handleRenderCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		CommandID int    `json:"commandId,omitempty"` // Render a saved card...
+		Command   string `json:"command,omitempty"`   // ...or raw command text
+		commands.RenderContext
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	text := req.Command
+	if req.CommandID != 0 {
+		cmds, err := commands.LoadCommands()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		found := false
+		for _, cmd := range cmds {
+			if cmd.ID == req.CommandID {
+				text = cmd.Command
+				found = true
+				break
+			}
+		}
+		if !found {
+			http.Error(w, fmt.Sprintf("Command %d not found", req.CommandID), http.StatusNotFound)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(commands.RenderCommand(text, req.RenderContext))
+}
+
+// handleChains lists (GET) or replaces (POST) saved command chains
+func handleChains(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		chains, err := commands.LoadChains()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(chains)
+
+	case http.MethodPost:
+		var chains []commands.Chain
+		if err := json.NewDecoder(r.Body).Decode(&chains); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := commands.SaveChains(chains); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(chains)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRunChain starts a chain in a terminal tab (POST), reports run status (GET ?id=),
+// or cancels a running chain (DELETE ?id=)
+func handleRunChain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		if id := r.URL.Query().Get("id"); id != "" {
+			run, ok := commands.GetChainRun(id)
+			if !ok {
+				http.Error(w, "Run not found", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(run)
+			return
+		}
+		json.NewEncoder(w).Encode(commands.ListChainRuns())
+
+	case http.MethodPost:
+		var req struct {
+			ChainID string `json:"chainId"`
+			TabID   string `json:"tabId"`
+			Confirm bool   `json:"confirm,omitempty"` // Run steps the dangerous-command guard asked to confirm
+			commands.RenderContext
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		chains, err := commands.LoadChains()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var chain *commands.Chain
+		for i := range chains {
+			if chains[i].ID == req.ChainID {
+				chain = &chains[i]
+				break
+			}
+		}
+		if chain == nil {
+			http.Error(w, "Chain not found", http.StatusNotFound)
+			return
+		}
+
+		target, err := termHandler.Target(req.TabID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		cmds, err := commands.LoadCommands()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		decision, err := commands.CheckChain(*chain, cmds, req.RenderContext)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !decision.Allowed(req.Confirm) {
+			guard.WriteDecision(w, decision)
+			return
+		}
+
+		run, err := commands.StartChain(*chain, cmds, req.TabID, target, req.RenderContext)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(run)
+
+	case http.MethodDelete:
+		if !commands.CancelChainRun(r.URL.Query().Get("id")) {
+			http.Error(w, "Run not active", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSnippets lists snippets (GET), saves one (POST), or deletes one (DELETE ?id=)
+func handleSnippets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		snippets, err := commands.LoadSnippets()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(snippets)
+
+	case http.MethodPost:
+		var snippet commands.Snippet
+		if err := json.NewDecoder(r.Body).Decode(&snippet); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		saved, err := commands.SaveSnippet(snippet)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(saved)
+
+	case http.MethodDelete:
+		deleted, err := commands.DeleteSnippet(r.URL.Query().Get("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, "Snippet not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// snippetInjectRequest is the body for POST /api/v1/snippets/inject
+type snippetInjectRequest struct {
+	ID      string            `json:"id"`
+	TabID   string            `json:"tabId"`
+	Values  map[string]string `json:"values,omitempty"` // Field name -> value, overriding saved values
+	Submit  bool              `json:"submit,omitempty"`
+	Confirm bool              `json:"confirm,omitempty"` // Inject text the dangerous-command guard asked to confirm
+}
+
+// handleInjectSnippet expands a snippet and types it into a tab (POST). Secret
+// values are read from the keychain only now and are masked in AM logs; if a
+// field has no value nothing is typed and the missing fields are returned.
+func handleInjectSnippet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req snippetInjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	snippet, ok, err := commands.GetSnippet(req.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "Snippet not found", http.StatusNotFound)
+		return
+	}
+	if _, ok := termHandler.Session(req.TabID); !ok {
+		http.Error(w, "No open tab with that tabId", http.StatusNotFound)
+		return
+	}
+
+	expansion, err := commands.ExpandSnippet(snippet, req.Values)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(expansion.Missing) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Some fields have no value",
+			"missing": expansion.Missing,
+		})
+		return
+	}
+
+	if decision := guard.Check(expansion.Text); !decision.Allowed(req.Confirm) {
+		for _, secret := range expansion.Secrets {
+			decision.Command = strings.ReplaceAll(decision.Command, secret, "[redacted]")
+		}
+		guard.WriteDecision(w, decision)
+		return
+	}
+
+	for _, secret := range expansion.Secrets {
+		am.RedactSecret(secret)
+	}
+	if err := termHandler.InjectInput(req.TabID, expansion.Text, req.Submit); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Printf("[Snippets] Injected snippet %q into tab %s (%d secret fields)", snippet.Name, req.TabID, len(expansion.Secrets))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"missing": expansion.Missing,
+	})
+}
+
+// resolveScheduleTarget finds the tab a schedule runs in, falling back to the first open tab
+func resolveScheduleTarget(tabID string) (commands.ChainTarget, string, error) {
+	if tabID == "" {
+		ids := termHandler.SessionIDs()
+		if len(ids) == 0 {
+			return nil, "", fmt.Errorf("no terminal tabs are open")
+		}
+		tabID = ids[0]
+	}
+	target, err := termHandler.Target(tabID)
+	if err != nil {
+		return nil, "", err
+	}
+	return target, tabID, nil
+}
+
+// handleSchedules lists schedules with run status (GET) or replaces them (POST)
+func handleSchedules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		views, err := commandScheduler.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(views)
+
+	case http.MethodPost:
+		var schedules []commands.Schedule
+		if err := json.NewDecoder(r.Body).Decode(&schedules); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := commands.SaveSchedules(schedules); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(schedules)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRunSchedule runs a schedule immediately. Body: {"id": "..."}
+func handleRunSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	run, err := commandScheduler.RunNow(req.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
+
+// handleCommandSchema returns accepted values and defaults for command card fields
+func handleCommandSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(commands.GetCommandSchema())
+}
+
+// handleRunCommand runs a command card server-side. Cards with a shellType or
+// workingDirectory run in a background session started that way; others run in tabId.
+// GET ?tabId= returns the session's recent output so callers can show results.
+func handleRunCommand(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		session, ok := termHandler.Session(r.URL.Query().Get("tabId"))
+		if !ok {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tabId":    session.ID,
+			"output":   session.RecentOutput(),
+			"atPrompt": session.AtPrompt(),
+		})
+
+	case http.MethodPost:
+		var req struct {
+			CommandID int    `json:"commandId"`
+			TabID     string `json:"tabId,omitempty"`   // Used when the card has no shell/directory of its own
+			Confirm   bool   `json:"confirm,omitempty"` // Run a command the dangerous-command guard asked to confirm
+			commands.RenderContext
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		cmds, err := commands.LoadCommands()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var cmd *commands.Command
+		for i := range cmds {
+			if cmds[i].ID == req.CommandID {
+				cmd = &cmds[i]
+				break
+			}
+		}
+		if cmd == nil {
+			http.Error(w, fmt.Sprintf("Command %d not found", req.CommandID), http.StatusNotFound)
+			return
+		}
+
+		if req.Cwd == "" {
+			req.Cwd = cmd.WorkingDirectory
+		}
+		rendered := commands.RenderCommand(cmd.Command, req.RenderContext)
+		if !rendered.Complete {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(rendered)
+			return
+		}
+		if decision := guard.Check(rendered.Command); !decision.Allowed(req.Confirm) {
+			guard.WriteDecision(w, decision)
+			return
+		}
+
+		var target *terminal.TabTarget
+		tabID, created := req.TabID, false
+		if cmd.ShellType != "" || cmd.WorkingDirectory != "" {
+			shellConfig := terminal.ShellConfig{ShellType: cmd.ShellType, WorkingDir: cmd.WorkingDirectory}
+			if config, err := commands.LoadConfig(); err == nil {
+				switch cmd.ShellType {
+				case "wsl":
+					shellConfig.WSLDistro = config.WSLDistro
+				case "tmux":
+					shellConfig.WSLDistro, shellConfig.TmuxSession = config.WSLDistro, config.TmuxSession
+				case "docker":
+					shellConfig.Container, shellConfig.ContainerShell = config.DockerContainer, config.DockerShell
+				case "ssh":
+					shellConfig.SSHHost = config.SSHHost
+				}
+			}
+			target, tabID, created, err = termHandler.RunTarget(shellConfig)
+		} else {
+			target, err = termHandler.Target(req.TabID)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		if err := target.InjectInput(rendered.Command, !cmd.PasteOnly); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := commands.RecordUsage(cmd.ID); err != nil {
+			log.Printf("[API] Failed to record usage for command %d: %v", cmd.ID, err)
+		}
+
+		log.Printf("[API] Ran command %d in session %s (created=%v)", cmd.ID, tabID, created)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"tabId":      tabID,
+			"created":    created,
+			"background": terminal.IsBackgroundSession(tabID),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		// Use Chrome on Windows, fall back to system default if not found
+		chromeExe := "chrome.exe"
+		if _, err := exec.LookPath(chromeExe); err != nil {
+			// Try common installation path
+			chromeExe = filepath.Join(os.Getenv("ProgramFiles"), "Google", "Chrome", "Application", "chrome.exe")
+			if _, err := os.Stat(chromeExe); err != nil {
+				// Try 32-bit path
+				chromeExe = filepath.Join(os.Getenv("ProgramFiles(x86)"), "Google", "Chrome", "Application", "chrome.exe")
+				if _, err := os.Stat(chromeExe); err != nil {
+					// Fall back to system default
+					cmd = exec.Command("cmd", "/c", "start", url)
+					break
+				}
+			}
+		}
+		cmd = exec.Command(chromeExe, url)
+	}
+	if cmd != nil {
+		_ = cmd.Start()
+	}
+}
+
+func handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"shutting down"}`))
+	log.Println("👋 Shutdown requested from browser")
+	// Shutdown waits for this response to be sent
+	go shutdown()
+}
+
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		config, err := commands.LoadConfig()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(config)
+
+	case http.MethodPost:
+		// Start from the saved config so fields the settings UI doesn't send
+		// (e.g. allowedRoots) are preserved
+		base := commands.DefaultConfig
+		if existing, err := commands.LoadConfig(); err == nil {
+			base = *existing
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		config, warnings, err := commands.ParseConfig(body, base)
+		if err != nil {
+			http.Error(w, "Invalid config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := updater.Configure(updaterSettings(config)); err != nil {
+			http.Error(w, "Invalid config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := commands.SaveConfig(config); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		applyServerConfig(config)
+		for _, warning := range warnings {
+			log.Printf("[API] Config: %s", warning)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"warnings": warnings,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleKeymap returns the effective keyboard shortcut for each rebindable action.
+// Shortcuts are changed through /api/config's keymap field.
+func handleKeymap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	config, err := commands.LoadConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keymap":   commands.ResolveKeymap(config.Keymap),
+		"defaults": commands.DefaultKeymap,
+		"actions":  commands.KeymapActions,
+	})
+}
+
+// handleValidateKeymap checks keymap overrides without saving them, including
+// conflicts with the saved command cards' bindings.
+// Body: {"keymap": {"newTab": "Ctrl+Alt+T", ...}}
+func handleValidateKeymap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		Keymap map[string]string `json:"keymap"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cmds, err := commands.LoadCommands()
+	if err != nil {
+		log.Printf("[API] Failed to load commands for keymap validation: %v", err)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"warnings": commands.ValidateKeymap(req.Keymap, cmds),
+	})
+}
+
+// handleExportConfig downloads config, commands, chains and schedules as one bundle.
+// Query parameters (e.g. ?theme=dark&colorTheme=molten) are the browser's theme
+// settings and are included so they travel with the bundle.
+func handleExportConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	themes := map[string]string{}
+	for key, values := range r.URL.Query() {
+		themes[key] = values[0]
+	}
+
+	bundle, err := commands.ExportConfigBundle(updater.GetVersion(), themes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="forge-config.json"`)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(bundle)
+}
+
+// handleImportConfig applies an exported config bundle.
+// Body: {"bundle": {...}, "strategy": "merge"|"keep"|"replace"}
+// The response's themes are for the browser to apply.
+func handleImportConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		Bundle   json.RawMessage `json:"bundle"`
+		Strategy string          `json:"strategy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := commands.ParseConfigBundle(req.Bundle)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Imported schedules start typing into tabs without a click, so bundles
+	// with schedules or chains need terminal.inject, as /commands/schedules does
+	if (len(bundle.Chains) > 0 || len(bundle.Schedules) > 0) && !capabilities.Enabled(middleware.CapTerminalInject) {
+		middleware.Forbidden(w, middleware.CapTerminalInject)
+		return
+	}
+
+	summary, err := commands.ImportConfigBundle(bundle, req.Strategy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Apply server-side settings now rather than waiting for the file watcher
+	if config, err := commands.LoadConfig(); err == nil {
+		applyServerConfig(config)
+	}
+
+	log.Printf("[API] Imported config bundle (%s)", summary.Strategy)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"summary": summary,
+		"themes":  bundle.Themes,
+	})
+}
+
+// listWSLDistros returns the installed WSL distributions (Windows only)
+func listWSLDistros() ([]string, error) {
+	cmd := exec.Command("wsl", "--list", "--quiet")
+	hideWindow(cmd) // Prevent console window flash
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse distro names (handle UTF-16 output from wsl.exe)
+	distros := []string{}
 	lines := strings.Split(string(bytes.ReplaceAll(output, []byte{0}, []byte{})), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -461,6 +1582,28 @@ func handleWSLDetect(w http.ResponseWriter, r *http.Request) {
 			distros = append(distros, line)
 		}
 	}
+	return distros, nil
+}
+
+func handleWSLDetect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if runtime.GOOS != "windows" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"available": false,
+			"reason":    "Not running on Windows",
+		})
+		return
+	}
+
+	distros, err := listWSLDistros()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"available": false,
+			"reason":    "WSL not installed or not available",
+		})
+		return
+	}
 
 	if len(distros) == 0 {
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -489,32 +1632,205 @@ func handleWSLDetect(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// findAvailablePort tries preferred ports in order and returns the first available one
-func findAvailablePort() (string, net.Listener, error) {
+// findAvailablePort listens on host at the given port, or when port is 0 tries
+// preferred ports in order and returns the first available one
+func findAvailablePort(host string, port int) (string, net.Listener, error) {
+	// Profile processes listen where the host server tells them to
+	if addr := os.Getenv(profiles.ListenEnv); addr != "" {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return "", nil, err
+		}
+		return addr, listener, nil
+	}
+
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if port != 0 {
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			if holder := ports.FindHolder(port); holder != nil {
+				return "", nil, fmt.Errorf("port %d unavailable (in use by %s): %w", port, holder, err)
+			}
+			return "", nil, fmt.Errorf("port %d unavailable: %w", port, err)
+		}
+		return addr, listener, nil
+	}
+
+	portConflicts = nil
 	for _, port := range preferredPorts {
-		addr := fmt.Sprintf("127.0.0.1:%d", port)
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
 		listener, err := net.Listen("tcp", addr)
 		if err == nil {
 			return addr, listener, nil
 		}
+		portConflicts = append(portConflicts, ports.Conflict{Port: port, Error: err.Error()})
 		log.Printf("Port %d unavailable, trying next...", port)
 	}
 
-	// Fallback: let OS assign a random available port
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	// Every preferred port is taken: say by what, so the user can free one or
+	// pick others with preferredPorts in config
+	for i := range portConflicts {
+		conflict := &portConflicts[i]
+		conflict.Holder = ports.FindHolder(conflict.Port)
+		if conflict.Holder != nil {
+			log.Printf("[Forge] Port %d is in use by %s", conflict.Port, conflict.Holder)
+		} else {
+			log.Printf("[Forge] Port %d is in use (process unknown)", conflict.Port)
+		}
+	}
+
+	// Fallback: let OS assign a random available port
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return "", nil, fmt.Errorf("no available ports: %w", err)
+	}
+	addr := listener.Addr().String()
+	log.Printf("Using OS-assigned port: %s", addr)
+	return addr, listener, nil
+}
+
+// browserAddr returns the address the local browser should open: a server
+// listening on all interfaces is reached through loopback.
+func browserAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return addr
+}
+
+// remoteAccess returns the listen host and port (config values, overridden by
+// --host and --port) and the policy for remote clients. Binding a non-loopback
+// address requires credentials: if config has none, an access token is
+// generated and saved there.
+func remoteAccess(hostFlag string, portFlag int) (string, int, *access.Policy, error) {
+	policy := &access.Policy{}
+	if storage.CurrentProfile() != "" {
+		return "", 0, policy, nil
+	}
+	config, err := commands.LoadConfig()
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	host, port := config.Host, config.Port
+	if hostFlag != "" {
+		host = hostFlag
+	}
+	if portFlag != 0 {
+		port = portFlag
+	}
+
+	policy.AllowedIPs, err = access.ParseAllowedIPs(config.AllowedIPs)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	policy.Token = config.AuthToken
+	policy.Username, policy.Password = config.AuthUsername, config.AuthPassword
+
+	if !access.IsLoopbackHost(host) && !policy.HasAuth() {
+		token, err := access.NewToken()
+		if err != nil {
+			return "", 0, nil, err
+		}
+		config.AuthToken = token
+		if err := commands.SaveConfig(config); err != nil {
+			return "", 0, nil, fmt.Errorf("failed to save generated access token: %w", err)
+		}
+		policy.Token = token
+		log.Printf("[Access] Generated an access token for remote clients (authToken in config)")
+	}
+	return host, port, policy, nil
+}
+
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"version": updater.GetVersion(),
+	})
+}
+
+// applyServerConfig applies the settings the server itself acts on: the file
+// access allowlist, updater settings, and the storage backend.
+func applyServerConfig(config *commands.Config) {
+	files.SetAllowedRoots(config.AllowedRoots)
+	files.SetTrashRetentionDays(config.TrashRetentionDays)
+	applyOriginPolicy(config)
+	terminal.SetLimits(terminal.Limits{PerClient: config.MaxTabsPerClient, Total: config.MaxTabs})
+	terminal.SetPreviewProxy(config.PreviewProxy)
+	power.SetEnabled(config.PreventSleep)
+	if err := editor.Configure(editor.Settings{Editor: config.Editor, Commands: config.EditorCommands}); err != nil {
+		log.Printf("[Editor] Ignoring editor settings: %v", err)
+	}
+	capabilities.Set(config.LockedDown, config.Capabilities)
+	terminal.SetVisionInject(capabilities.Enabled(middleware.CapTerminalInject))
+	terminal.SetExternalEditor(capabilities.Enabled(middleware.CapTerminalInject))
+	if err := am.SetPrivateWorkspaces(config.AMPrivateWorkspaces); err != nil {
+		log.Printf("[AM] Ignoring private workspaces: %v", err)
+	}
+	if err := llm.SetPrices(config.LLMPrices); err != nil {
+		log.Printf("[LLM] Ignoring prices: %v", err)
+	}
+	if config.AMProjectNotes {
+		am.SetProjectNotes(extractProjectNotes)
+	} else {
+		am.SetProjectNotes(nil)
+	}
+	if err := guard.Configure(config.DangerousCommands); err != nil {
+		log.Printf("[Guard] Ignoring dangerous command rules: %v", err)
+	}
+	switch {
+	case config.LongCommandSeconds < 0:
+		terminal.SetLongCommandThreshold(0)
+	case config.LongCommandSeconds > 0:
+		terminal.SetLongCommandThreshold(time.Duration(config.LongCommandSeconds) * time.Second)
+	default:
+		terminal.SetLongCommandThreshold(terminal.DefaultLongCommandThreshold)
+	}
+	if err := updater.Configure(updaterSettings(config)); err != nil {
+		log.Printf("[Updater] Ignoring update settings: %v", err)
+	}
+	backend := storage.BackendConfig{}
+	if config.Storage != nil {
+		backend = *config.Storage
+	}
+	if err := storage.Configure(backend); err != nil {
+		log.Printf("[Storage] Ignoring storage settings: %v", err)
+	}
+}
+
+// extractProjectNotes asks the assistant backend for AM's project notes.
+func extractProjectNotes(ctx context.Context, prompt string) (string, error) {
+	if assistantService == nil {
+		return "", errors.New("assistant not started")
+	}
+	resp, err := assistantService.Chat(ctx, &assistant.ChatRequest{Message: prompt})
 	if err != nil {
-		return "", nil, fmt.Errorf("no available ports: %w", err)
+		return "", err
 	}
-	addr := listener.Addr().String()
-	log.Printf("Using OS-assigned port: %s", addr)
-	return addr, listener, nil
+	return resp.Message, nil
 }
 
-func handleVersion(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"version": updater.GetVersion(),
-	})
+// updaterSettings maps the update fields of the saved config to updater settings
+func updaterSettings(config *commands.Config) updater.Settings {
+	return updater.Settings{
+		Channel:        config.UpdateChannel,
+		Proxy:          config.UpdateProxy,
+		SourceDir:      config.UpdateSourceDir,
+		CheckInterval:  time.Duration(config.UpdateCheckSeconds) * time.Second,
+		ChecksDisabled: config.UpdateChecksDisabled,
+		Policy:         config.UpdatePolicy,
+		QuietHours:     config.UpdateQuietHours,
+
+		PinnedVersion:   config.UpdatePinnedVersion,
+		SkippedVersions: config.UpdateSkippedVersions,
+	}
 }
 
 func handleUpdateCheck(w http.ResponseWriter, r *http.Request) {
@@ -545,6 +1861,13 @@ func handleUpdateApply(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
+	// Optional body: {"force": true} installs immediately, even outside quiet
+	// hours or while terminals are running commands
+	var req struct {
+		Force bool `json:"force"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
 	// Check for update first
 	info, err := updater.CheckForUpdate()
 	if err != nil {
@@ -563,9 +1886,46 @@ func handleUpdateApply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Download the update
-	log.Printf("[Updater] Downloading %s...", info.AssetName)
-	tmpPath, err := updater.DownloadUpdate(info)
+	if !req.Force {
+		// Quiet-hours policy: download now, install in the next window
+		if updater.GetSettings().Policy == updater.PolicyQuietHours && !updater.GetQuietHours().Contains(time.Now()) {
+			if _, err := updater.StageUpdate(info); err != nil {
+				log.Printf("[Updater] Download failed: %v", err)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": false,
+					"error":   "Download failed: " + err.Error(),
+				})
+				return
+			}
+			installAt := updater.GetQuietHours().Next(time.Now())
+			log.Printf("[Updater] %s downloaded; install scheduled for %s", info.LatestVersion, installAt.Format("15:04"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":    true,
+				"scheduled":  true,
+				"installAt":  installAt,
+				"newVersion": info.LatestVersion,
+				"message":    "Update downloaded. It will install during quiet hours.",
+			})
+			return
+		}
+
+		// Never restart in the middle of a running command
+		if busy := termHandler.BusySessionIDs(); len(busy) > 0 {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":  false,
+				"error":    fmt.Sprintf("%d terminal(s) are running commands; finish them or install with force", len(busy)),
+				"busyTabs": busy,
+			})
+			return
+		}
+	}
+
+	// Download the update, reusing a background download if there is one
+	tmpPath, staged := updater.TakeStagedUpdate(info.LatestVersion)
+	if !staged {
+		log.Printf("[Updater] Downloading %s...", info.AssetName)
+		tmpPath, err = updater.DownloadUpdate(info)
+	}
 	if err != nil {
 		log.Printf("[Updater] Download failed: %v", err)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -607,8 +1967,7 @@ func handleUpdateApply(w http.ResponseWriter, r *http.Request) {
 	// 3. Set up server death detection polling
 	go func() {
 		time.Sleep(3 * time.Second)
-		log.Printf("[Updater] Restarting now...")
-		restartSelf()
+		drainAndRestart()
 	}()
 }
 
@@ -678,12 +2037,172 @@ func handleInstallManualUpdate(w http.ResponseWriter, r *http.Request) {
 	// Restart the application after delay
 	go func() {
 		time.Sleep(3 * time.Second)
-		log.Printf("[Updater] Restarting now...")
-		restartSelf()
+		drainAndRestart()
 	}()
 }
 
+// minAutoUpdateInterval keeps background downloads from polling GitHub as often as the notifier
+const minAutoUpdateInterval = 15 * time.Minute
+
+// publishUpdateNotification adds an available update to the notification
+// center; checks that find the same version again leave it as it is.
+func publishUpdateNotification(info *updater.UpdateInfo) {
+	_, err := notify.Publish(notify.Notification{
+		Kind:  notify.KindUpdate,
+		Key:   notify.KindUpdate,
+		Title: fmt.Sprintf("Forge %s is available", info.LatestVersion),
+		Body:  fmt.Sprintf("You are running %s (%s channel).", updater.GetVersion(), info.Channel),
+		Data: map[string]interface{}{
+			"latestVersion": info.LatestVersion,
+			"channel":       info.Channel,
+			"downloadURL":   info.DownloadURL,
+		},
+	})
+	if err != nil {
+		log.Printf("[Updater] Failed to add update notification: %v", err)
+	}
+}
+
+// publishStartupNotifications adds what the user should know about from
+// before this start: a crash, and AM sessions that can be restored.
+func publishStartupNotifications() {
+	if notice := crash.PendingNotice(); notice != nil {
+		body := notice.Latest.Panic
+		if notice.Count > 1 {
+			body = fmt.Sprintf("%s (%d crash reports)", body, notice.Count)
+		}
+		_, err := notify.Publish(notify.Notification{
+			Kind:  notify.KindCrash,
+			Key:   notify.KindCrash + ":" + notice.Latest.ID,
+			Title: notice.Message,
+			Body:  body,
+			Data:  map[string]interface{}{"reportId": notice.Latest.ID},
+		})
+		if err != nil {
+			log.Printf("[Forge] Failed to add crash notification: %v", err)
+		}
+	}
+
+	sessions, err := am.CheckForRecoverableSessions()
+	if err != nil || len(sessions) == 0 {
+		return
+	}
+	_, err = notify.Publish(notify.Notification{
+		Kind:  notify.KindAMRecovery,
+		Key:   notify.KindAMRecovery,
+		Title: fmt.Sprintf("%d AM session(s) can be restored", len(sessions)),
+		Body:  "Conversations were interrupted before they finished.",
+		Data:  map[string]interface{}{"sessions": len(sessions)},
+	})
+	if err != nil {
+		log.Printf("[AM] Failed to add recovery notification: %v", err)
+	}
+}
+
+// runAutoUpdates downloads updates in the background and, under the quiet-hours
+// policy, installs them during the quiet window when no terminal is busy.
+func runAutoUpdates() {
+	lastStaged := ""
+	for {
+		settings := updater.GetSettings()
+		time.Sleep(max(settings.CheckInterval, minAutoUpdateInterval))
+
+		settings = updater.GetSettings()
+		if settings.ChecksDisabled || settings.Policy == updater.PolicyManual {
+			continue
+		}
+
+		info, err := updater.CheckForUpdate()
+		if err != nil || !info.Available {
+			continue
+		}
+		publishUpdateNotification(info)
+		path, err := updater.StageUpdate(info)
+		if err != nil {
+			log.Printf("[Updater] Background download failed: %v", err)
+			continue
+		}
+		if info.LatestVersion != lastStaged {
+			lastStaged = info.LatestVersion
+			log.Printf("[Updater] Downloaded %s in the background (policy: %s)", info.LatestVersion, settings.Policy)
+		}
+
+		if settings.Policy != updater.PolicyQuietHours || !updater.GetQuietHours().Contains(time.Now()) {
+			continue
+		}
+		if busy := termHandler.BusySessionIDs(); len(busy) > 0 {
+			log.Printf("[Updater] Postponing install: %d terminal(s) busy", len(busy))
+			continue
+		}
+		if _, ok := updater.TakeStagedUpdate(info.LatestVersion); !ok {
+			continue
+		}
+		log.Printf("[Updater] Installing %s during quiet hours...", info.LatestVersion)
+		if err := updater.ApplyUpdate(path); err != nil {
+			log.Printf("[Updater] Apply failed: %v", err)
+			continue
+		}
+		drainAndRestart()
+		return
+	}
+}
+
+// restartDrainTimeout bounds how long a restart waits for terminals and in-flight writes
+const restartDrainTimeout = 10 * time.Second
+
+// drainAndRestart closes terminal WebSockets with terminal.CloseCodeRestart, saves
+// their scrollback, waits for in-flight writes to finish, then restarts.
+func drainAndRestart() {
+	log.Printf("[Updater] Draining before restart...")
+	ctx, cancel := context.WithTimeout(context.Background(), restartDrainTimeout)
+	defer cancel()
+
+	if commandScheduler != nil {
+		commandScheduler.Stop()
+	}
+	if termHandler != nil {
+		state := termHandler.Drain(ctx)
+		state.Version = updater.GetVersion()
+		if err := terminal.SaveRestartState(state); err != nil {
+			log.Printf("[Updater] Failed to save terminal state: %v", err)
+		} else {
+			log.Printf("[Updater] Saved state for %d terminal(s)", len(state.Sessions))
+		}
+	}
+	if !inflightWrites.drain(ctx) {
+		log.Printf("[Updater] Timed out waiting for in-flight writes")
+	}
+
+	profileManager.StopAll()
+	forwards.StopAll()
+	power.Stop()
+
+	log.Printf("[Updater] Restarting now...")
+	restartSelf()
+}
+
+// handleRestartState returns the terminal state saved by the last drainAndRestart
+func handleRestartState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := terminal.LoadRestartState()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"state": state, // null when no restart state was saved
+	})
+}
+
 func restartSelf() {
+	// The new process must not mistake this one for a running instance
+	instance.Unregister()
+
 	executable, err := os.Executable()
 	if err != nil {
 		log.Printf("[Updater] Failed to get executable path: %v", err)
@@ -723,13 +2242,122 @@ func handleListVersions(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleUpdateChangelog returns the combined release notes for every version after ?from= (default: installed)
+func handleUpdateChangelog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	entries, markdown, err := updater.Changelog(from)
+	if err != nil {
+		log.Printf("[Updater] Failed to build changelog: %v", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if from == "" {
+		from = updater.GetVersion()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":     from,
+		"releases": entries,
+		"markdown": markdown,
+	})
+}
+
+// handleUpdatePreferences reads (GET) or changes (POST) the pinned and skipped versions.
+// POST accepts {"pinnedVersion": "v1.2.0"} ("" unpins), {"skip": "v1.3.0"}, {"unskip": "v1.3.0"},
+// or a full {"skippedVersions": [...]} list.
+func handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	config, err := commands.LoadConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// Current preferences are returned below
+
+	case http.MethodPost:
+		var req struct {
+			PinnedVersion   *string   `json:"pinnedVersion"`
+			SkippedVersions *[]string `json:"skippedVersions"`
+			Skip            string    `json:"skip"`
+			Unskip          string    `json:"unskip"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if req.PinnedVersion != nil {
+			config.UpdatePinnedVersion = strings.TrimSpace(*req.PinnedVersion)
+		}
+		if req.SkippedVersions != nil {
+			config.UpdateSkippedVersions = *req.SkippedVersions
+		}
+		if req.Skip != "" && !containsVersion(config.UpdateSkippedVersions, req.Skip) {
+			config.UpdateSkippedVersions = append(config.UpdateSkippedVersions, req.Skip)
+		}
+		if req.Unskip != "" {
+			kept := []string{}
+			for _, v := range config.UpdateSkippedVersions {
+				if !containsVersion([]string{v}, req.Unskip) {
+					kept = append(kept, v)
+				}
+			}
+			config.UpdateSkippedVersions = kept
+		}
+
+		if err := updater.Configure(updaterSettings(config)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := commands.SaveConfig(config); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[Updater] Preferences updated (pinned: %q, skipped: %v)", config.UpdatePinnedVersion, config.UpdateSkippedVersions)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	skipped := config.UpdateSkippedVersions
+	if skipped == nil {
+		skipped = []string{}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"currentVersion":  updater.GetVersion(),
+		"pinnedVersion":   config.UpdatePinnedVersion,
+		"skippedVersions": skipped,
+	})
+}
+
+// containsVersion reports whether versions includes v, ignoring a "v" prefix
+func containsVersion(versions []string, v string) bool {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	for _, existing := range versions {
+		if strings.TrimPrefix(strings.TrimSpace(existing), "v") == v {
+			return true
+		}
+	}
+	return false
+}
+
 // handleUpdateEvents provides Server-Sent Events (SSE) for real-time update notifications
 func handleUpdateEvents(w http.ResponseWriter, r *http.Request) {
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Ensure we can flush
 	flusher, ok := w.(http.Flusher)
@@ -738,16 +2366,23 @@ func handleUpdateEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Send initial connection event with current version
-	fmt.Fprintf(w, "event: connected\ndata: {\"version\":\"%s\"}\n\n", updater.GetVersion())
+	// Send initial connection event with current version and channel
+	settings := updater.GetSettings()
+	fmt.Fprintf(w, "event: connected\ndata: {\"version\":\"%s\",\"channel\":\"%s\",\"checksEnabled\":%v}\n\n", updater.GetVersion(), settings.Channel, !settings.ChecksDisabled)
+	// Tell the user about a crash since they last acknowledged one
+	if notice := crash.PendingNotice(); notice != nil {
+		data, _ := json.Marshal(notice)
+		fmt.Fprintf(w, "event: crash\ndata: %s\n\n", data)
+	}
 	flusher.Flush()
 
-	// Check for updates every 30 seconds (more frequent for better UX)
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	// Check on the configured interval (settings are re-read each time so changes apply live)
+	timer := time.NewTimer(settings.CheckInterval)
+	defer timer.Stop()
 
 	// Track last known version to avoid duplicate notifications
 	lastNotifiedVersion := ""
+	lastChannel := settings.Channel
 	consecutiveErrors := 0
 	maxConsecutiveErrors := 3
 
@@ -757,66 +2392,333 @@ func handleUpdateEvents(w http.ResponseWriter, r *http.Request) {
 			// Client disconnected
 			log.Printf("[SSE] Client disconnected")
 			return
-		case <-ticker.C:
-			// Check for updates with timeout
+		case <-timer.C:
+			settings = updater.GetSettings()
+			if settings.ChecksDisabled {
+				timer.Reset(settings.CheckInterval)
+				continue
+			}
+
 			info, err := updater.CheckForUpdate()
 			if err != nil {
 				consecutiveErrors++
-				log.Printf("[SSE] Update check failed (attempt %d/%d): %v", consecutiveErrors, maxConsecutiveErrors, err)
+				// Offline or blocked by a proxy: back off instead of retrying every interval
+				backoff := updateCheckBackoff(settings.CheckInterval, consecutiveErrors)
+				timer.Reset(backoff)
 
-				// Send error event to client if too many failures
-				if consecutiveErrors >= maxConsecutiveErrors {
+				// Notify the client once per failure streak rather than every few attempts
+				if consecutiveErrors == maxConsecutiveErrors {
+					log.Printf("[SSE] Update check failed %d times, retrying every %s: %v", consecutiveErrors, backoff, err)
 					fmt.Fprintf(w, "event: error\ndata: {\"message\":\"Failed to check for updates\"}\n\n")
 					flusher.Flush()
-					log.Printf("[SSE] Sent error notification after %d failures", consecutiveErrors)
-					consecutiveErrors = 0 // Reset counter after notifying
+				} else if consecutiveErrors < maxConsecutiveErrors {
+					log.Printf("[SSE] Update check failed (attempt %d/%d): %v", consecutiveErrors, maxConsecutiveErrors, err)
 				}
 				continue
 			}
-
-			// Reset error counter on success
-			consecutiveErrors = 0
-
-			// Send update notification if available and not already notified
-			if info.Available && info.LatestVersion != lastNotifiedVersion {
-				lastNotifiedVersion = info.LatestVersion
-				data, _ := json.Marshal(map[string]interface{}{
-					"available":     true,
-					"latestVersion": info.LatestVersion,
-					"releaseNotes":  info.ReleaseNotes,
-					"downloadURL":   info.DownloadURL,
-				})
-				fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
-				flusher.Flush()
-				log.Printf("[SSE] Sent update notification: %s", info.LatestVersion)
+			timer.Reset(settings.CheckInterval)
+
+			// Reset error counter on success
+			if consecutiveErrors >= maxConsecutiveErrors {
+				log.Printf("[SSE] Update checks recovered")
+			}
+			consecutiveErrors = 0
+
+			// Switching channels may offer a different build; notify again
+			if info.Channel != lastChannel {
+				lastChannel = info.Channel
+				lastNotifiedVersion = ""
+			}
+
+			// Send update notification if available and not already notified
+			if info.Available && info.LatestVersion != lastNotifiedVersion {
+				lastNotifiedVersion = info.LatestVersion
+				publishUpdateNotification(info)
+				event := map[string]interface{}{
+					"available":     true,
+					"latestVersion": info.LatestVersion,
+					"releaseNotes":  info.ReleaseNotes,
+					"downloadURL":   info.DownloadURL,
+					"channel":       info.Channel,
+					"prerelease":    info.Prerelease,
+					"policy":        settings.Policy,
+					"downloaded":    updater.StagedVersion() == info.LatestVersion,
+				}
+				// Let the UI say when the update will install instead of prompting a restart
+				if settings.Policy == updater.PolicyQuietHours {
+					event["installAt"] = updater.GetQuietHours().Next(time.Now())
+				}
+				data, _ := json.Marshal(event)
+				fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
+				flusher.Flush()
+				log.Printf("[SSE] Sent update notification: %s (%s)", info.LatestVersion, info.Channel)
+			}
+		}
+	}
+}
+
+// applyConfigChanges applies server-side settings from config.json when it is edited outside the UI.
+func applyConfigChanges() {
+	changes, _ := configWatcher.Subscribe()
+	for change := range changes {
+		if change.File != commands.WatchConfig || change.Config == nil {
+			continue
+		}
+		applyServerConfig(change.Config)
+	}
+}
+
+// handleConfigEvents streams a "config" event with the new values whenever
+// config.json or commands.json is edited on disk, so the UI can reload them.
+func handleConfigEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	changes, cancel := configWatcher.Subscribe()
+	defer cancel()
+
+	fmt.Fprintf(w, "event: connected\ndata: {}\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case change := <-changes:
+			data, _ := json.Marshal(change)
+			fmt.Fprintf(w, "event: config\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// maxUpdateCheckBackoff caps the retry delay after repeated update check failures
+const maxUpdateCheckBackoff = time.Hour
+
+// updateCheckBackoff doubles the check interval for each consecutive failure, up to maxUpdateCheckBackoff.
+func updateCheckBackoff(interval time.Duration, failures int) time.Duration {
+	backoff := interval
+	for i := 1; i < failures && backoff < maxUpdateCheckBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxUpdateCheckBackoff)
+}
+
+func handleSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		session, err := commands.LoadSession()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(session)
+
+	case http.MethodPost:
+		var session commands.Session
+		if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := commands.SaveSession(&session); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// sessionRestoreRequest is the body for POST /api/v1/sessions/history
+type sessionRestoreRequest struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"` // Without id: the newest restore point at or before this time
+}
+
+// sessionRestorePoints is the response for GET /api/v1/sessions/history
+type sessionRestorePoints struct {
+	RestorePoints []commands.SessionRestorePoint `json:"restorePoints"`
+}
+
+// handleSessionHistory lists restore points of the tab layout (GET) or restores
+// one (POST)
+func handleSessionHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		points, err := commands.ListSessionRestorePoints()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(sessionRestorePoints{RestorePoints: points})
+
+	case http.MethodPost:
+		var req sessionRestoreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.ID == "" {
+			if req.Timestamp.IsZero() {
+				http.Error(w, "id or timestamp is required", http.StatusBadRequest)
+				return
+			}
+			point, err := commands.FindSessionRestorePoint(req.Timestamp)
+			if err == commands.ErrNoRestorePoint {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
 			}
+			req.ID = point.ID
+		}
+
+		session, err := commands.RestoreSessionRestorePoint(req.ID)
+		if err == commands.ErrNoRestorePoint {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		log.Printf("[API] Restored tab layout from %s (%d tabs)", req.ID, len(session.Tabs))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":      true,
+			"restoredFrom": req.ID,
+			"session":      session,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func handleSessions(w http.ResponseWriter, r *http.Request) {
+// handleNamedSessions manages named tab layouts:
+//
+//	GET    /api/sessions/              list named sessions
+//	GET    /api/sessions/{name}        get one
+//	PUT    /api/sessions/{name}        save a layout under the name (empty body = current layout)
+//	DELETE /api/sessions/{name}        delete one
+//	POST   /api/sessions/{name}/switch make it the current layout
+func handleNamedSessions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, api.Prefix+"/sessions/"), "/")
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		sessions, active, err := commands.ListNamedSessions()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sessions": sessions,
+			"active":   active,
+		})
+		return
+	}
+
+	name, action, _ := strings.Cut(rest, "/")
+	if action != "" {
+		if action != "switch" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		session, err := commands.SwitchSession(name)
+		if err == commands.ErrSessionNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[API] Switched to session %q (%d tabs)", name, len(session.Tabs))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"name":    name,
+			"session": session,
+		})
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
-		session, err := commands.LoadSession()
+		ns, err := commands.GetNamedSession(name)
+		if err == commands.ErrSessionNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		json.NewEncoder(w).Encode(session)
+		json.NewEncoder(w).Encode(ns)
 
-	case http.MethodPost:
+	case http.MethodPut, http.MethodPost:
+		if err := commands.ValidateSessionName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		var session commands.Session
-		if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		if err := commands.SaveSession(&session); err != nil {
+		if len(bytes.TrimSpace(body)) == 0 {
+			current, err := commands.LoadSession()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			session = *current
+		} else if err := json.Unmarshal(body, &session); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ns, err := commands.SaveNamedSession(name, session)
+		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ns)
+
+	case http.MethodDelete:
+		if err := commands.DeleteNamedSession(name); err == commands.ErrSessionNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
 
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -852,49 +2754,114 @@ func handleWelcome(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// AM (Artificial Memory) handlers
+// handleOnboarding stores which intro UI the user has seen, so features can
+// decide server-side whether to show their introduction.
+// GET returns the state and pending announcements. POST applies any of:
+// {"seen": ["feature"], "tour": {"id": "intro", "step": 2, "completed": false},
+// "dismiss": ["announcement-id"], "dismissAll": true, "welcome": true}.
+// DELETE clears seen flags and tour progress (?feature= or ?tour= clears one).
+func handleOnboarding(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	currentVersion := updater.GetVersion()
 
-func inferLLMProvider(explicit string, command string) llm.Provider {
-	// Use explicit provider if specified
-	switch strings.ToLower(explicit) {
-	case "copilot", "github-copilot":
-		return llm.ProviderGitHubCopilot
-	case "claude":
-		return llm.ProviderClaude
-	case "aider":
-		return llm.ProviderAider
-	}
+	var state *commands.OnboardingState
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		state, err = commands.LoadOnboarding()
 
-	// Fallback: infer from command text
-	lower := strings.ToLower(command)
-	if strings.Contains(lower, "copilot") || strings.Contains(lower, "gh copilot") {
-		return llm.ProviderGitHubCopilot
-	}
-	if strings.Contains(lower, "claude") {
-		return llm.ProviderClaude
-	}
-	if strings.Contains(lower, "aider") {
-		return llm.ProviderAider
-	}
+	case http.MethodPost:
+		var req struct {
+			Seen []string `json:"seen"`
+			Tour *struct {
+				ID        string `json:"id"`
+				Step      int    `json:"step"`
+				Completed bool   `json:"completed"`
+			} `json:"tour"`
+			Dismiss    []string `json:"dismiss"`
+			DismissAll bool     `json:"dismissAll"`
+			Welcome    bool     `json:"welcome"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		keys := append([]string{}, req.Seen...)
+		if req.Tour != nil {
+			if req.Tour.Step < 0 {
+				http.Error(w, "tour step must not be negative", http.StatusBadRequest)
+				return
+			}
+			keys = append(keys, req.Tour.ID)
+		}
+		for _, key := range keys {
+			if err := commands.ValidateOnboardingKey(key); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
 
-	return llm.ProviderUnknown
-}
+		state, err = commands.UpdateOnboarding(func(state *commands.OnboardingState) error {
+			for _, feature := range req.Seen {
+				if err := state.MarkSeen(feature); err != nil {
+					return err
+				}
+			}
+			if req.Tour != nil {
+				if err := state.SetTourStep(req.Tour.ID, req.Tour.Step, req.Tour.Completed); err != nil {
+					return err
+				}
+			}
+			state.DismissAnnouncements(req.Dismiss...)
+			if req.DismissAll {
+				for _, a := range state.PendingAnnouncements(currentVersion) {
+					state.DismissAnnouncements(a.ID)
+				}
+			}
+			if req.Welcome {
+				state.WelcomeVersion = currentVersion
+				if state.AnnouncedFrom == "" {
+					state.AnnouncedFrom = currentVersion
+				}
+			}
+			return nil
+		})
+
+	case http.MethodDelete:
+		feature, tour := r.URL.Query().Get("feature"), r.URL.Query().Get("tour")
+		state, err = commands.UpdateOnboarding(func(state *commands.OnboardingState) error {
+			switch {
+			case feature != "":
+				delete(state.Seen, feature)
+			case tour != "":
+				delete(state.Tours, tour)
+			default:
+				state.Seen = map[string]time.Time{}
+				state.Tours = map[string]commands.TourProgress{}
+			}
+			return nil
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-// inferLLMType determines the command type from explicit field
-func inferLLMType(explicit string) llm.CommandType {
-	switch strings.ToLower(explicit) {
-	case "chat":
-		return llm.CommandChat
-	case "suggest":
-		return llm.CommandSuggest
-	case "explain":
-		return llm.CommandExplain
-	case "code":
-		return llm.CommandCode
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	return llm.CommandChat // Default to chat
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version":       currentVersion,
+		"welcomeShown":  state.WelcomeVersion == currentVersion,
+		"seen":          state.Seen,
+		"tours":         state.Tours,
+		"announcements": state.PendingAnnouncements(currentVersion),
+	})
 }
 
+// AM (Artificial Memory) handlers
+
 func handleAMCheck(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -992,34 +2959,284 @@ func handleAMCheckGrouped(w http.ResponseWriter, r *http.Request, sessions ...[]
 	return result
 }
 
-func handleAMContent(w http.ResponseWriter, r *http.Request) {
+func handleAMContent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	// Extract tabId from URL path
+	tabID := strings.TrimPrefix(r.URL.Path, api.Prefix+"/am/content/")
+	if tabID == "" {
+		http.Error(w, "Tab ID required", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		err := am.DeleteSessionLog(tabID)
+		writeAMDeleteResult(w, audit.Event{Action: audit.AMDelete, Target: "session log", TabID: tabID}, err)
+		return
+	}
+
+	content, err := am.GetLogContent(tabID)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"content": content,
+	})
+}
+
+// writeAMDeleteResult audits an AM deletion and reports how it went.
+func writeAMDeleteResult(w http.ResponseWriter, event audit.Event, err error) {
+	audit.RecordResult(event, err)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, os.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	log.Printf("[AM API] Deleted %s", event.Target)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// handleAMErase erases everything AM holds for a workspace in two steps: a
+// request without confirmToken lists what would go and returns a token, and
+// repeating it with the token erases.
+func handleAMErase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		Workspace    string `json:"workspace"`
+		ConfirmToken string `json:"confirmToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.ConfirmToken == "" {
+		plan, err := am.PreviewWorkspaceErase(am.DefaultAMDir(), req.Workspace)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":           true,
+			"needsConfirmation": true,
+			"plan":              plan,
+		})
+		return
+	}
+
+	plan, err := am.EraseWorkspace(am.DefaultAMDir(), req.Workspace, req.ConfirmToken)
+	if plan == nil && err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	event := audit.Event{Action: audit.AMDelete, Target: "workspace " + req.Workspace,
+		Detail: fmt.Sprintf("%d conversations, %d session logs", len(plan.Conversations), len(plan.SessionLogs))}
+	audit.RecordResult(event, err)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[AM API] Erased workspace %s (%s)", req.Workspace, event.Detail)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"plan":    plan,
+	})
+}
+
+// writeAMLookupError reports a failed conversation lookup, 404 when there is
+// no such conversation.
+func writeAMLookupError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, os.ErrNotExist) {
+		status = http.StatusNotFound
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+// handleAMFork lists a conversation's forks (GET ?conversationId=) or forks
+// it into a tab (POST {"conversationId", "tabId"}): the next conversation
+// started in the tab continues it, recorded as a fork, while the original is
+// left intact.
+func handleAMFork(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		convID := r.URL.Query().Get("conversationId")
+		if convID == "" {
+			http.Error(w, "conversationId required", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"forks":   am.ListForks(am.DefaultAMDir(), convID),
+		})
+
+	case http.MethodPost:
+		var req struct {
+			ConversationID string `json:"conversationId"`
+			TabID          string `json:"tabId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		fork, err := am.ForkConversation(am.DefaultAMDir(), req.ConversationID, req.TabID)
+		if err != nil {
+			writeAMLookupError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"fork":    fork,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAMForkCompare compares the outcomes of two branches of a
+// conversation (GET ?a=&b=).
+func handleAMForkCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	a, b := r.URL.Query().Get("a"), r.URL.Query().Get("b")
+	if a == "" || b == "" {
+		http.Error(w, "a and b conversation IDs required", http.StatusBadRequest)
+		return
+	}
+	comparison, err := am.CompareBranches(am.DefaultAMDir(), a, b)
+	if err != nil {
+		writeAMLookupError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"comparison": comparison,
+	})
+}
+
+// handleLLMUsage estimates AI token use and spend over the last ?days=
+// (default 30), by project, day and provider.
+func handleLLMUsage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 366 {
+			http.Error(w, "days must be between 1 and 366", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	report := am.Usage(am.DefaultAMDir(), today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1))
 
 	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
 
-	// Extract tabId from URL path
-	tabID := strings.TrimPrefix(r.URL.Path, "/api/am/content/")
-	if tabID == "" {
-		http.Error(w, "Tab ID required", http.StatusBadRequest)
-		return
-	}
+// handleAMDigests lists the daily digests (GET), returns one as markdown
+// (GET ?name=), or writes a day's digests now (POST {"date": "YYYY-MM-DD"},
+// default yesterday).
+func handleAMDigests(w http.ResponseWriter, r *http.Request) {
+	amDir := am.DefaultAMDir()
+	switch r.Method {
+	case http.MethodGet:
+		if name := r.URL.Query().Get("name"); name != "" {
+			content, err := am.ReadDigest(amDir, name)
+			if err != nil {
+				http.Error(w, "Digest not found", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			io.WriteString(w, content)
+			return
+		}
+		digests, err := am.ListDigests(amDir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"digests": digests,
+		})
 
-	content, err := am.GetLogContent(tabID)
-	if err != nil {
+	case http.MethodPost:
+		var req struct {
+			Date string `json:"date"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+		day := time.Now().AddDate(0, 0, -1)
+		if req.Date != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+			if err != nil {
+				http.Error(w, "date must be YYYY-MM-DD", http.StatusBadRequest)
+				return
+			}
+			day = parsed
+		}
+		names, err := am.WriteDigests(amDir, day)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
+			"success": true,
+			"digests": names,
 		})
-		return
-	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"content": content,
-	})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
 }
 
 func handleAMArchive(w http.ResponseWriter, r *http.Request) {
@@ -1031,7 +3248,7 @@ func handleAMArchive(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract tabId from URL path
-	tabID := strings.TrimPrefix(r.URL.Path, "/api/am/archive/")
+	tabID := strings.TrimPrefix(r.URL.Path, api.Prefix+"/am/archive/")
 	if tabID == "" {
 		http.Error(w, "Tab ID required", http.StatusBadRequest)
 		return
@@ -1108,6 +3325,75 @@ func handleAMMasterControl(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleAMPause suspends all AM capture until /api/am/resume.
+func handleAMPause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	am.Pause()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"paused":  true,
+	})
+}
+
+// handleAMResume restarts AM capture after /api/am/pause.
+func handleAMResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	am.Resume()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"paused":  false,
+	})
+}
+
+// handleAMCapture reports or sets whether one tab's input and output are
+// captured, so capture can be turned off while typing a password without
+// pausing AM everywhere.
+func handleAMCapture(w http.ResponseWriter, r *http.Request) {
+	var tabID string
+	var enabled bool
+	switch r.Method {
+	case http.MethodGet:
+		tabID = r.URL.Query().Get("tabId")
+		if tabID == "" {
+			http.Error(w, "tabId required", http.StatusBadRequest)
+			return
+		}
+		enabled = am.GetLLMLogger(tabID, am.DefaultAMDir()).CaptureEnabled()
+	case http.MethodPost:
+		var req struct {
+			TabID   string `json:"tabId"`
+			Enabled bool   `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.TabID == "" {
+			http.Error(w, "tabId required", http.StatusBadRequest)
+			return
+		}
+		tabID, enabled = req.TabID, req.Enabled
+		am.GetLLMLogger(tabID, am.DefaultAMDir()).SetCaptureEnabled(enabled)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"tabId":   tabID,
+		"enabled": enabled,
+	})
+}
+
 func handleAMLLMConversations(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1124,24 +3410,24 @@ func handleAMLLMConversations(w http.ResponseWriter, r *http.Request) {
 	}
 	tabID := pathParts[len(pathParts)-1]
 
-	log.Printf("[AM API] GET /api/am/llm/conversations/%s", tabID)
+	logging.Debugf("[AM API] GET /api/am/llm/conversations/%s", tabID)
 
 	// Get LLM logger for this tab
 	llmLogger := am.GetLLMLogger(tabID, am.DefaultAMDir())
-	log.Printf("[AM API] Retrieved LLM logger for tab %s", tabID)
+	logging.Debugf("[AM API] Retrieved LLM logger for tab %s", tabID)
 
 	conversations := llmLogger.GetConversations()
 	count := len(conversations)
 
-	log.Printf("[AM API] GetConversations() returned %d conversations for tab %s", count, tabID)
+	logging.Debugf("[AM API] GetConversations() returned %d conversations for tab %s", count, tabID)
 
 	if count == 0 {
-		log.Printf("[AM API] ⚠️ ZERO conversations found for tab %s", tabID)
-		log.Printf("[AM API] Active conversation ID: '%s'", llmLogger.GetActiveConversationID())
+		logging.Debugf("[AM API] ⚠️ ZERO conversations found for tab %s", tabID)
+		logging.Debugf("[AM API] Active conversation ID: '%s'", llmLogger.GetActiveConversationID())
 	} else {
-		log.Printf("[AM API] ✓ Found %d conversations:", count)
+		logging.Debugf("[AM API] ✓ Found %d conversations:", count)
 		for i, conv := range conversations {
-			log.Printf("[AM API]   [%d] ID=%s provider=%s type=%s complete=%v turns=%d",
+			logging.Debugf("[AM API]   [%d] ID=%s provider=%s type=%s complete=%v turns=%d",
 				i, conv.ConversationID, conv.Provider, conv.CommandType, conv.Complete, len(conv.Turns))
 		}
 	}
@@ -1154,7 +3440,7 @@ func handleAMLLMConversations(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleAMLLMConversationDetail(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -1162,16 +3448,20 @@ func handleAMLLMConversationDetail(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract tab ID and conversation ID from URL path
-	// Format: /api/am/llm/conversation/{tabID}/{conversationID}
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 7 {
+	// Format: /api/v1/am/llm/conversation/{tabID}/{conversationID}
+	tabID, convID, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, api.Prefix+"/am/llm/conversation/"), "/")
+	if tabID == "" || convID == "" {
 		http.Error(w, "Tab ID and Conversation ID required", http.StatusBadRequest)
 		return
 	}
-	tabID := pathParts[5]
-	convID := pathParts[6]
 
-	log.Printf("[AM API] GET /api/am/llm/conversation/%s/%s", tabID, convID)
+	if r.Method == http.MethodDelete {
+		err := am.DeleteConversation(am.DefaultAMDir(), convID)
+		writeAMDeleteResult(w, audit.Event{Action: audit.AMDelete, Target: "conversation " + convID, TabID: tabID}, err)
+		return
+	}
+
+	logging.Debugf("[AM API] GET /api/am/llm/conversation/%s/%s", tabID, convID)
 
 	// Get LLM logger for this tab
 	llmLogger := am.GetLLMLogger(tabID, am.DefaultAMDir())
@@ -1184,7 +3474,7 @@ func handleAMLLMConversationDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("[AM API] ✓ Found conversation: ID=%s provider=%s turns=%d snapshots=%d",
+	logging.Debugf("[AM API] ✓ Found conversation: ID=%s provider=%s turns=%d snapshots=%d",
 		conversation.ConversationID, conversation.Provider,
 		len(conversation.Turns), len(conversation.ScreenSnapshots))
 
@@ -1268,6 +3558,22 @@ func handleAMRestoreSessions(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleAMLast returns the most recently active conversation's summary.
+func handleAMLast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	latest := am.NewContextBuilder(am.DefaultAMDir()).LatestSession()
+	if latest == nil {
+		http.Error(w, "No AM sessions recorded", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(latest)
+}
+
 // handleAMRestoreContext returns restore context for a specific conversation.
 func handleAMRestoreContext(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet && r.Method != http.MethodPost {
@@ -1278,7 +3584,7 @@ func handleAMRestoreContext(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract conversation ID from path: /api/am/restore/context/{conversationId}
-	convID := strings.TrimPrefix(r.URL.Path, "/api/am/restore/context/")
+	convID := strings.TrimPrefix(r.URL.Path, api.Prefix+"/am/restore/context/")
 	if convID == "" {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
@@ -1360,11 +3666,23 @@ func handleAMLog(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[AM Log] Received: tabId=%s, entryType=%s, triggerAM=%v, provider=%s",
 		req.TabID, req.EntryType, req.TriggerAM, req.LLMProvider)
 
-	// Normalize provider names
+	// Fill in metadata from the saved card, then map to AM's provider names
+	if req.CommandID != 0 && req.LLMProvider == "" {
+		if cmds, err := commands.LoadCommands(); err == nil {
+			for _, cmd := range cmds {
+				if cmd.ID == req.CommandID {
+					req.LLMProvider, req.LLMType = string(cmd.LLMProvider), string(cmd.LLMType)
+					break
+				}
+			}
+		}
+	}
 	provider := req.LLMProvider
-	switch strings.ToLower(provider) {
-	case "copilot", "gh-copilot":
-		provider = "github-copilot"
+	if known := llm.ParseProvider(provider); known != llm.ProviderUnknown {
+		provider = string(known)
+	}
+	if req.LLMType == "" {
+		req.LLMType = string(commands.DefaultLLMType)
 	}
 
 	// If this is a command card with triggerAM, start a conversation
@@ -1380,6 +3698,14 @@ func handleAMLog(w http.ResponseWriter, r *http.Request) {
 						req.LLMType,
 						0,
 					)
+					if convID == "" {
+						// The tab is in a private workspace
+						json.NewEncoder(w).Encode(map[string]interface{}{
+							"success": true,
+							"private": true,
+						})
+						return
+					}
 					log.Printf("[AM Log] Started conversation %s for tab %s (provider: %s)",
 						convID, req.TabID, provider)
 					json.NewEncoder(w).Encode(map[string]interface{}{
@@ -1399,31 +3725,6 @@ func handleAMLog(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func handleDesktopShortcut(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-
-	err := createDesktopShortcut()
-	if err != nil {
-		log.Printf("[Desktop] Failed to create shortcut: %v", err)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
-	}
-
-	log.Printf("[Desktop] Shortcut created successfully")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "Desktop shortcut created",
-	})
-}
-
 // handleAssistantStatus checks if Ollama is available.
 func handleAssistantStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -1485,7 +3786,17 @@ func handleAssistantExecute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if decision := guard.Check(req.Command); !decision.Allowed(req.Confirm) {
+		guard.WriteDecision(w, decision)
+		return
+	}
+
+	event := audit.Event{Action: audit.AssistantExecute, Target: req.Command, TabID: req.TabID}
 	response, err := assistantService.ExecuteCommand(ctx, &req)
+	if err == nil && !response.Success {
+		event.Error = response.Error
+	}
+	audit.RecordResult(event, err)
 	if err != nil {
 		log.Printf("[Assistant] Execute error: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -1650,7 +3961,7 @@ func handleAssistantTrainingStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract model name from URL path
-	model := strings.TrimPrefix(r.URL.Path, "/api/assistant/training-status/")
+	model := strings.TrimPrefix(r.URL.Path, api.Prefix+"/assistant/training-status/")
 	if model == "" {
 		http.Error(w, "Model name required", http.StatusBadRequest)
 		return
@@ -1694,7 +4005,7 @@ func handleVisionConfig(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		// Read config
-		data, err := os.ReadFile(configPath)
+		data, err := storage.ReadJSONFile(configPath)
 		if err != nil {
 			if os.IsNotExist(err) {
 				// Return default config
@@ -1739,7 +4050,7 @@ func handleVisionConfig(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if err := os.WriteFile(configPath, data, 0644); err != nil {
+		if err := storage.WriteFileAtomic(configPath, data, 0644); err != nil {
 			http.Error(w, "Failed to save config", http.StatusInternalServerError)
 			return
 		}
@@ -1766,7 +4077,7 @@ func handleVisionInsights(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract tabID from URL path: /api/vision/insights/{tabID}
-	tabID := strings.TrimPrefix(r.URL.Path, "/api/vision/insights/")
+	tabID := strings.TrimPrefix(r.URL.Path, api.Prefix+"/vision/insights/")
 	if tabID == "" {
 		http.Error(w, "Tab ID required", http.StatusBadRequest)
 		return
@@ -1815,7 +4126,7 @@ func handleVisionInsightsSummary(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract tabID from URL path: /api/vision/insights/summary/{tabID}
-	tabID := strings.TrimPrefix(r.URL.Path, "/api/vision/insights/summary/")
+	tabID := strings.TrimPrefix(r.URL.Path, api.Prefix+"/vision/insights/summary/")
 	if tabID == "" {
 		http.Error(w, "Tab ID required", http.StatusBadRequest)
 		return
@@ -1852,6 +4163,59 @@ func handleVisionInsightsSummary(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// diagnosticsReport gathers the server state included in diagnostics bundles
+// and feedback
+func diagnosticsReport() diagnostics.Report {
+	report := diagnostics.Report{
+		Version:    updater.GetVersion(),
+		StartedAt:  serverStartedAt,
+		ListenAddr: serverAddr,
+		Ports:      preferredPorts,
+		LogPath:    serverLogPath,
+		Extra: map[string]interface{}{
+			"updater": updater.GetSettings(),
+		},
+	}
+	if config, err := commands.LoadConfig(); err == nil {
+		report.Config = config
+	}
+	if system := am.GetSystem(); system != nil {
+		report.AMHealth = system.GetHealth()
+	} else {
+		report.AMHealth = map[string]string{"status": "NOT_INITIALIZED"}
+	}
+	if termHandler != nil {
+		report.Extra["terminals"] = map[string]interface{}{
+			"tabs": len(termHandler.SessionIDs()),
+			"busy": len(termHandler.BusySessionIDs()),
+		}
+	}
+	return report
+}
+
+// handleDiagnosticsBundle returns a zip of version, redacted config, AM health,
+// recent server logs, listen info, and OS details for attaching to bug reports
+func handleDiagnosticsBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := diagnostics.WriteBundle(&buf, diagnosticsReport()); err != nil {
+		log.Printf("[Diagnostics] Failed to build bundle: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := fmt.Sprintf("forge-diagnostics-%s.zip", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+	log.Printf("[Diagnostics] Generated %s (%d bytes)", name, buf.Len())
+}
+
 // handleDiagnosticsKeyboard logs keyboard diagnostic snapshots for debugging lockout issues
 func handleDiagnosticsKeyboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -1970,3 +4334,188 @@ func serveIndexWithVersion(w http.ResponseWriter, r *http.Request, webFS fs.FS)
 
 	w.Write([]byte(html))
 }
+
+// storageNamespaces are the top-level key prefixes clients may use through the storage API
+var storageNamespaces = []string{"am", "sessions", "recordings"}
+
+// handleStorage reports which storage backend is configured.
+func handleStorage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backendType := storage.BackendLocal
+	if config, err := commands.LoadConfig(); err == nil && config.Storage != nil && config.Storage.Type != "" {
+		backendType = config.Storage.Type
+	}
+	_, remote := storage.Remote()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":       backendType,
+		"remote":     remote,
+		"namespaces": storageNamespaces,
+	})
+}
+
+// handleStorageTest checks a backend by writing, reading, and deleting a probe
+// object. Body: a storage config to test before saving it (empty = the current backend).
+func handleStorageTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	backend := storage.Current()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		var config storage.BackendConfig
+		if err := json.Unmarshal(body, &config); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if backend, err = storage.NewBackend(config); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	key := fmt.Sprintf("forge-probe-%d.txt", time.Now().UnixNano())
+	probe := []byte("forge storage test")
+	err = backend.Put(ctx, key, probe)
+	if err == nil {
+		var data []byte
+		data, err = backend.Get(ctx, key)
+		if err == nil && string(data) != string(probe) {
+			err = fmt.Errorf("read back %d bytes that don't match what was written", len(data))
+		}
+		if deleteErr := backend.Delete(ctx, key); err == nil {
+			err = deleteErr
+		}
+	}
+	if err != nil {
+		log.Printf("[Storage] Backend test failed: %v", err)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// handleStorageObjects lists (GET /api/storage/objects/?prefix=am/), reads (GET),
+// writes (PUT, raw body), and deletes (DELETE) objects such as session exports
+// and recordings. Keys must start with one of storageNamespaces.
+func handleStorageObjects(w http.ResponseWriter, r *http.Request) {
+	backend := storage.Current()
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+
+	key := strings.TrimPrefix(r.URL.Path, api.Prefix+"/storage/objects/")
+	if key == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		prefix := r.URL.Query().Get("prefix")
+		objects, err := backend.List(ctx, prefix)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		// Only expose the namespaces the API manages
+		visible := []storage.Object{}
+		for _, object := range objects {
+			if storageNamespaceAllowed(object.Key) {
+				visible = append(visible, object)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"objects": visible,
+		})
+		return
+	}
+
+	key, err := storage.CleanKey(key)
+	if err != nil || !storageNamespaceAllowed(key) {
+		http.Error(w, fmt.Sprintf("Invalid key (must start with one of: %s)", strings.Join(storageNamespaces, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		data, err := backend.Get(ctx, key)
+		if err == storage.ErrNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+
+	case http.MethodPut:
+		if !capabilities.Enabled(middleware.CapFilesWrite) {
+			middleware.Forbidden(w, middleware.CapFilesWrite)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := backend.Put(ctx, key, data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"key":     key,
+			"size":    len(data),
+		})
+
+	case http.MethodDelete:
+		if !capabilities.Enabled(middleware.CapFilesDelete) {
+			middleware.Forbidden(w, middleware.CapFilesDelete)
+			return
+		}
+		if err := backend.Delete(ctx, key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// storageNamespaceAllowed reports whether a key is under one of storageNamespaces.
+func storageNamespaceAllowed(key string) bool {
+	for _, ns := range storageNamespaces {
+		if strings.HasPrefix(key, ns+"/") {
+			return true
+		}
+	}
+	return false
+}
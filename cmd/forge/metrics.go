@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/am"
+	"github.com/mikejsmith1985/forge-terminal/internal/metrics"
+	"github.com/mikejsmith1985/forge-terminal/internal/terminal"
+)
+
+// newMetricsRegistry returns the registry served at /metrics: HTTP traffic
+// (recorded by the returned middleware), terminal sessions and PTY throughput,
+// Go runtime stats, and AM capture counters.
+func newMetricsRegistry() (*metrics.Registry, *metrics.HTTP) {
+	reg := metrics.NewRegistry()
+	reg.RegisterRuntime(serverStartedAt)
+
+	// Label requests by the pattern that served them, not the raw path
+	httpMetrics := reg.NewHTTP(func(r *http.Request) string {
+		_, pattern := http.DefaultServeMux.Handler(r)
+		return pattern
+	})
+
+	reg.GaugeFunc("forge_terminal_websocket_sessions", "Terminal WebSocket sessions currently connected.",
+		func() float64 { return float64(terminal.ReadStats().WebSocketSessions) })
+	reg.CounterFunc("forge_terminal_pty_read_bytes_total", "Bytes of shell output read from PTYs.",
+		func() float64 { return float64(terminal.ReadStats().PTYBytesRead) })
+	reg.CounterFunc("forge_terminal_pty_written_bytes_total", "Bytes of input written to PTYs.",
+		func() float64 { return float64(terminal.ReadStats().PTYBytesWritten) })
+
+	// AM capture counters, from the health monitor (absent until AM starts)
+	amMetric := func(name, help string, typ metrics.Type, value func(*am.CaptureMetrics) float64) {
+		reg.Register(name, help, typ, func() []metrics.Sample {
+			system := am.GetSystem()
+			if system == nil || system.HealthMonitor == nil {
+				return nil
+			}
+			return []metrics.Sample{{Value: value(system.HealthMonitor.GetMetrics())}}
+		})
+	}
+	amMetric("forge_am_conversations_active", "AM conversations currently being captured.", metrics.Gauge,
+		func(m *am.CaptureMetrics) float64 { return float64(m.ConversationsActive) })
+	amMetric("forge_am_conversations_completed_total", "AM conversations captured to completion.", metrics.Counter,
+		func(m *am.CaptureMetrics) float64 { return float64(m.ConversationsComplete) })
+	amMetric("forge_am_input_turns_total", "User turns detected in AM conversations.", metrics.Counter,
+		func(m *am.CaptureMetrics) float64 { return float64(m.InputTurnsDetected) })
+	amMetric("forge_am_output_turns_total", "Assistant turns detected in AM conversations.", metrics.Counter,
+		func(m *am.CaptureMetrics) float64 { return float64(m.OutputTurnsDetected) })
+	amMetric("forge_am_parse_failures_total", "AM turns that could not be parsed.", metrics.Counter,
+		func(m *am.CaptureMetrics) float64 { return float64(m.InputParseFailures + m.OutputParseFailures) })
+	amMetric("forge_am_low_confidence_parses_total", "AM turns parsed with low confidence.", metrics.Counter,
+		func(m *am.CaptureMetrics) float64 { return float64(m.LowConfidenceParses) })
+
+	return reg, httpMetrics
+}
@@ -1,54 +1,32 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strings"
-)
+	"sync"
 
-// CORSMiddleware adds CORS headers to support GitHub Pages frontend
-func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Get allowed origins from environment or default to localhost
-		allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
-		if allowedOrigins == "" {
-			// Default: allow localhost and current origin
-			allowedOrigins = "http://localhost:3000,http://localhost:8333,http://127.0.0.1:8333"
-		}
+	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+	"github.com/mikejsmith1985/forge-terminal/internal/middleware"
+)
 
-		// Check if origin is allowed
-		origin := r.Header.Get("Origin")
-		isAllowed := false
-		
-		for _, allowed := range strings.Split(allowedOrigins, ",") {
-			allowed = strings.TrimSpace(allowed)
-			if allowed == "*" || origin == allowed {
-				isAllowed = true
-				break
-			}
-		}
+// crossOrigin decides which other sites may call the API, open terminals, and
+// frame Forge (allowedOrigins and frameAncestors in config)
+var crossOrigin = middleware.NewOrigins()
 
-		if isAllowed {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-		}
+// capabilities decides which system-changing features are enabled
+// (capabilities and lockedDown in config)
+var capabilities = middleware.NewCapabilities()
 
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			w.Header().Set("Access-Control-Max-Age", "3600")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Add response headers for all requests
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		next(w, r)
+// applyOriginPolicy applies the cross-origin settings, falling back to the
+// ALLOWED_ORIGINS environment variable (comma-separated) when none are configured
+func applyOriginPolicy(config *commands.Config) {
+	allowed := config.AllowedOrigins
+	if len(allowed) == 0 && os.Getenv("ALLOWED_ORIGINS") != "" {
+		allowed = strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",")
 	}
+	crossOrigin.Set(allowed, config.FrameAncestors)
 }
 
 // SecureHeaders adds security headers to responses
@@ -69,7 +47,65 @@ func SecureHeaders(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// WrapWithMiddleware wraps a handler with CORS and security middleware
+// writeTracker counts in-flight mutating requests so a restart can wait for them
+type writeTracker struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+}
+
+var inflightWrites writeTracker
+
+// begin registers a write; it returns false once draining has started.
+func (t *writeTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+// drain rejects new writes and waits for in-flight ones. Returns false if ctx ended first.
+func (t *writeTracker) drain(ctx context.Context) bool {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// TrackWrites tracks POST/PUT/DELETE requests and refuses them while the server drains for a restart
+func TrackWrites(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next(w, r)
+			return
+		}
+		if !inflightWrites.begin() {
+			w.Header().Set("Retry-After", "10")
+			http.Error(w, "Server is restarting", http.StatusServiceUnavailable)
+			return
+		}
+		defer inflightWrites.wg.Done()
+		next(w, r)
+	}
+}
+
+// WrapWithMiddleware wraps a handler with security and write-tracking
+// middleware. CORS is handled for every route by crossOrigin.
 func WrapWithMiddleware(handler http.HandlerFunc) http.HandlerFunc {
-	return CORSMiddleware(SecureHeaders(handler))
+	return SecureHeaders(TrackWrites(handler))
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/am"
+	"github.com/mikejsmith1985/forge-terminal/internal/api"
+	"github.com/mikejsmith1985/forge-terminal/internal/assistant"
+	"github.com/mikejsmith1985/forge-terminal/internal/audit"
+	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+	"github.com/mikejsmith1985/forge-terminal/internal/completion"
+	"github.com/mikejsmith1985/forge-terminal/internal/crash"
+	"github.com/mikejsmith1985/forge-terminal/internal/editor"
+	"github.com/mikejsmith1985/forge-terminal/internal/feedback"
+	"github.com/mikejsmith1985/forge-terminal/internal/files"
+	"github.com/mikejsmith1985/forge-terminal/internal/forwards"
+	"github.com/mikejsmith1985/forge-terminal/internal/git"
+	"github.com/mikejsmith1985/forge-terminal/internal/guard"
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+	"github.com/mikejsmith1985/forge-terminal/internal/middleware"
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
+	"github.com/mikejsmith1985/forge-terminal/internal/power"
+	"github.com/mikejsmith1985/forge-terminal/internal/secrets"
+	"github.com/mikejsmith1985/forge-terminal/internal/sshconfig"
+	"github.com/mikejsmith1985/forge-terminal/internal/terminal"
+	"github.com/mikejsmith1985/forge-terminal/internal/updater"
+	"github.com/mikejsmith1985/forge-terminal/internal/workspaces"
+)
+
+// registerAPIRoutes registers the REST API on routes. Handlers that change
+// the system are wrapped in capabilities.Require so locked-down mode can
+// refuse them; routes_test.go checks every mutating route.
+func registerAPIRoutes(routes *api.Router) {
+	// Expensive endpoints (directory scans, hashing, git history, GitHub API
+	// calls, long-running jobs) are rate limited per client and route
+	rateLimit := func(perMinute, burst int, handler http.HandlerFunc) http.HandlerFunc {
+		return middleware.NewRateLimiter(perMinute, burst).Wrap(handler)
+	}
+
+	// Terminal API - tabs, editors, containers and remote shells
+	routes.HandleFunc("/terminal/inject", capabilities.Require(middleware.CapTerminalInject, termHandler.HandleInject), api.Doc{Methods: "GET POST", Summary: "List injectable tabs, or type input into a tab", Request: terminal.InjectRequest{}})
+	routes.HandleFunc("/terminal/sessions", termHandler.HandleSessions, api.Doc{Methods: "GET", Summary: "Open terminals per client and in total, with the tab limits"})
+	routes.HandleFunc("/terminal/urls", termHandler.HandleURLs, api.Doc{Methods: "GET", Summary: "Local server URLs tabs have printed, with their preview proxy paths", Query: []string{"tabId"}})
+	routes.HandleFunc("/terminal/file-refs", termHandler.HandleFileRefs, api.Doc{Methods: "GET", Summary: "file:line references a tab has printed, resolved against its directory", Query: []string{"tabId"}})
+	routes.HandleFunc("/open-in-editor", capabilities.Require(middleware.CapTerminalInject, editor.HandleOpen), api.Doc{Methods: "POST", Summary: "Open a file at a line in the configured editor (VS Code, JetBrains, vim in a new tab, or a custom command)", Request: editor.OpenRequest{}, Response: editor.Result{}})
+	routes.HandleFunc("/terminal/open-file", termHandler.HandleOpenFile, api.Doc{Methods: "POST", Summary: "Resolve a file reference clicked in a tab and open it in the built-in or an external editor", Request: terminal.OpenFileRequest{}})
+	routes.HandleFunc("/docker/containers", terminal.HandleContainers, api.Doc{Methods: "GET", Summary: "Running Docker containers a docker tab can open a shell in"})
+	routes.HandleFunc("/forwards", forwards.HandleForwards, api.Doc{Methods: "GET POST DELETE", Summary: "List, create, or remove local port forwards to servers in SSH and WSL tabs, with detected servers to forward", Query: []string{"id"}, Request: forwards.Request{}, Response: forwards.Forward{}})
+	routes.HandleFunc("/power", power.HandleStatus, api.Doc{Methods: "GET", Summary: "Whether Forge is keeping the computer awake, and why", Response: power.Status{}})
+	routes.HandleFunc("/ssh/hosts", sshconfig.HandleHosts, api.Doc{Methods: "GET", Summary: "Hosts in ~/.ssh/config, with their ProxyJump chains, for ssh tabs"})
+	routes.HandleFunc("/tmux/sessions", terminal.HandleTmuxSessions, api.Doc{Methods: "GET", Summary: "Host tmux sessions with their windows and panes, which a tmux tab can attach to", Query: []string{"distro"}})
+
+	// OpenAPI document for every route registered here
+	routes.HandleFunc("/spec", routes.HandleSpec(updater.GetVersion()), api.Doc{Methods: "GET", Summary: "OpenAPI document for this API"})
+
+	// Commands API
+	routes.HandleFunc("/commands", handleCommands, api.Doc{Methods: "GET POST", Summary: "List command cards, or replace them all", Query: []string{"group", "sort", "tag"}, Request: []commands.Command{}, Response: []commands.Command{}})
+	routes.HandleFunc("/commands/restore-defaults", handleRestoreDefaultCommands, api.Doc{Methods: "POST", Summary: "Restore the default command cards"})
+	routes.HandleFunc("/commands/render", handleRenderCommand, api.Doc{Methods: "POST", Summary: "Render a command card's template with variable values"})
+	routes.HandleFunc("/commands/run", capabilities.Require(middleware.CapTerminalInject, handleRunCommand), api.Doc{Methods: "GET POST", Summary: "List tabs a command can run in, or run a command card in a tab", Query: []string{"tabId"}})
+	routes.HandleFunc("/commands/schema", handleCommandSchema, api.Doc{Methods: "GET", Summary: "JSON Schema for commands.json"})
+	routes.HandleFunc("/commands/export", handleExportCommands, api.Doc{Methods: "GET", Summary: "Export command cards as a file", Query: []string{"ids"}})
+	routes.HandleFunc("/commands/import", handleImportCommands, api.Doc{Methods: "POST", Summary: "Import command cards"})
+	routes.HandleFunc("/commands/chains", handleChains, api.Doc{Methods: "GET POST", Summary: "List command chains, or replace them all", Request: []commands.Chain{}, Response: []commands.Chain{}})
+	routes.HandleFunc("/commands/chains/run", capabilities.Require(middleware.CapTerminalInject, handleRunChain), api.Doc{Methods: "GET POST DELETE", Summary: "Get, start, or cancel a chain run", Query: []string{"id"}})
+	routes.HandleFunc("/audit", audit.HandleQuery, api.Doc{Methods: "GET", Summary: "Privileged operations Forge performed (file deletes, assistant commands, auto-respond, updates), newest first", Query: []string{"action", "since", "until", "q", "tabId", "limit"}, Response: []audit.Event{}})
+	routes.HandleFunc("/capabilities", capabilities.Handle, api.Doc{Methods: "GET", Summary: "Which system-changing features are enabled, and whether Forge is locked down"})
+	routes.HandleFunc("/guard/rules", guard.HandleRules, api.Doc{Methods: "GET", Summary: "Dangerous-command rules checked before Forge types commands into tabs", Response: []guard.Rule{}})
+	routes.HandleFunc("/secrets", secrets.HandleSecrets, api.Doc{Methods: "GET POST DELETE", Summary: "List secret names in the OS keychain, store a value, or delete one; values are never returned", Query: []string{"name"}, Request: secrets.SetRequest{}, Response: []secrets.Info{}})
+	routes.HandleFunc("/snippets", handleSnippets, api.Doc{Methods: "GET POST DELETE", Summary: "List snippets, save one, or delete one; secret values go to the OS keychain and are never returned", Query: []string{"id"}, Request: commands.Snippet{}, Response: []commands.Snippet{}})
+	routes.HandleFunc("/snippets/inject", capabilities.Require(middleware.CapTerminalInject, handleInjectSnippet), api.Doc{Methods: "POST", Summary: "Fill in a snippet's fields, secrets included, and type it into a tab", Request: snippetInjectRequest{}})
+	routes.HandleFunc("/commands/schedules", capabilities.Require(middleware.CapTerminalInject, handleSchedules), api.Doc{Methods: "GET POST", Summary: "List scheduled commands, or replace them all", Request: []commands.Schedule{}})
+	routes.HandleFunc("/commands/usage", handleCommandUsage, api.Doc{Methods: "GET POST", Summary: "Command card usage counts, or record a use"})
+	routes.HandleFunc("/commands/history", handleCommandHistory, api.Doc{Methods: "GET POST", Summary: "List backups of commands.json, or restore one", Request: commandBackupRestoreRequest{}, Response: []commands.CommandBackup{}})
+	routes.HandleFunc("/commands/packs", handleCommandPacks, api.Doc{Methods: "GET POST", Summary: "List command packs, or install one"})
+	routes.HandleFunc("/commands/keybindings/validate", handleValidateKeyBindings, api.Doc{Methods: "POST", Summary: "Check command card keybindings for conflicts", Request: []commands.Command{}})
+	routes.HandleFunc("/commands/schedules/run", capabilities.Require(middleware.CapTerminalInject, handleRunSchedule), api.Doc{Methods: "POST", Summary: "Run a scheduled command now"})
+
+	// Config API
+	routes.HandleFunc("/config", capabilities.Require(middleware.CapConfigWrite, handleConfig), api.Doc{Methods: "GET POST", Summary: "Get or update settings", Request: commands.Config{}, Response: commands.Config{}})
+	routes.HandleFunc("/config/events", handleConfigEvents, api.Doc{Methods: "GET", Summary: "Stream changes to config.json and commands.json", Stream: true}) // SSE for external config/commands edits
+	routes.HandleFunc("/config/export", handleExportConfig, api.Doc{Methods: "GET", Summary: "Export settings, commands and workspaces as a bundle"})
+	routes.HandleFunc("/config/keymap", handleKeymap, api.Doc{Methods: "GET", Summary: "Effective keyboard shortcuts"})
+	routes.HandleFunc("/config/keymap/validate", handleValidateKeymap, api.Doc{Methods: "POST", Summary: "Check a keymap for conflicts"})
+	routes.HandleFunc("/config/import", capabilities.Require(middleware.CapConfigWrite, handleImportConfig), api.Doc{Methods: "POST", Summary: "Import a settings bundle"})
+
+	// WSL detection API
+	routes.HandleFunc("/wsl/detect", handleWSLDetect, api.Doc{Methods: "GET", Summary: "Detect WSL distributions"})
+
+	// Shutdown API - allows graceful shutdown from browser
+	routes.HandleFunc("/shutdown", handleShutdown, api.Doc{Methods: "POST", Summary: "Shut the server down"})
+
+	// Update API - check for updates and apply them
+	routes.HandleFunc("/version", handleVersion, api.Doc{Methods: "GET", Summary: "Server version"})
+	routes.HandleFunc("/tls", handleTLS, api.Doc{Methods: "GET", Summary: "HTTPS certificate in use"})
+	routes.HandleFunc("/update/check", rateLimit(20, 5, handleUpdateCheck), api.Doc{Methods: "GET", Summary: "Check for a newer release"})
+	routes.HandleFunc("/update/apply", handleUpdateApply, api.Doc{Methods: "POST", Summary: "Install a release and restart"})
+	routes.HandleFunc("/update/versions", rateLimit(20, 5, handleListVersions), api.Doc{Methods: "GET", Summary: "List recent releases"})
+	routes.HandleFunc("/update/changelog", rateLimit(20, 5, handleUpdateChangelog), api.Doc{Methods: "GET", Summary: "Release notes since a version", Query: []string{"from"}})
+	routes.HandleFunc("/update/preferences", capabilities.Require(middleware.CapConfigWrite, handleUpdatePreferences), api.Doc{Methods: "GET POST", Summary: "Get or set the update policy"})
+	routes.HandleFunc("/update/events", handleUpdateEvents, api.Doc{Methods: "GET", Summary: "Stream update and crash recovery notifications", Stream: true})                      // SSE for push update notifications
+	routes.HandleFunc("/update/install-manual", handleInstallManualUpdate, api.Doc{Methods: "POST", Summary: "Install a manually downloaded binary"})                              // Install manually downloaded binary
+	routes.HandleFunc("/update/restart-state", handleRestartState, api.Doc{Methods: "GET", Summary: "Tab state saved before the last restart", Response: terminal.RestartState{}}) // Tab scrollback saved before the last restart
+
+	// Sessions API - persist tab state across refreshes
+	routes.HandleFunc("/sessions", handleSessions, api.Doc{Methods: "GET POST", Summary: "Get or save the open tabs", Request: commands.Session{}, Response: commands.Session{}})
+	routes.HandleFunc("/sessions/", handleNamedSessions, api.Doc{Methods: "GET PUT POST DELETE", Summary: "Named tab layouts: /sessions/{name}[/switch]"}) // Named layouts: /api/sessions/{name}[/switch]
+	routes.HandleFunc("/sessions/history", handleSessionHistory, api.Doc{Methods: "GET POST", Summary: "List restore points of the tab layout, or restore one by ID or time", Request: sessionRestoreRequest{}, Response: sessionRestorePoints{}})
+
+	// Welcome screen API - track if welcome has been shown
+	routes.HandleFunc("/welcome", handleWelcome, api.Doc{Methods: "GET POST", Summary: "Whether the welcome screen has been shown"})
+	routes.HandleFunc("/onboarding", handleOnboarding, api.Doc{Methods: "GET POST DELETE", Summary: "Onboarding tour progress", Query: []string{"feature", "tour"}})
+
+	// AM (Artificial Memory) API - session logging and recovery
+	routes.HandleFunc("/am/check", handleAMCheck, api.Doc{Methods: "GET", Summary: "Sessions with recoverable AM logs"})
+	routes.HandleFunc("/am/check/enhanced", func(w http.ResponseWriter, r *http.Request) {
+		handleAMCheckEnhanced(w, r)
+	}, api.Doc{Methods: "GET", Summary: "Recoverable AM sessions with details", Response: am.RecoveryInfo{}})
+	routes.HandleFunc("/am/check/grouped", func(w http.ResponseWriter, r *http.Request) {
+		handleAMCheckGrouped(w, r)
+	}, api.Doc{Methods: "GET", Summary: "Recoverable AM sessions grouped by workspace", Response: am.RecoveryInfoGrouped{}})
+	routes.HandleFunc("/am/content/", capabilities.Require(middleware.CapFilesDelete, handleAMContent), api.Doc{Methods: "GET DELETE", Summary: "Content of an AM log, or delete it"})
+	routes.HandleFunc("/am/archive/", handleAMArchive, api.Doc{Methods: "POST", Summary: "Archive an AM log"})
+	routes.HandleFunc("/am/digests", handleAMDigests, api.Doc{Methods: "GET POST", Summary: "Daily activity digests per workspace", Query: []string{"name"}})
+	routes.HandleFunc("/llm/usage", handleLLMUsage, api.Doc{Methods: "GET", Summary: "Estimated AI tokens and spend by project, day and provider", Query: []string{"days"}, Response: am.UsageReport{}})
+	routes.HandleFunc("/am/fork", handleAMFork, api.Doc{Methods: "GET POST", Summary: "Forks of a conversation, or fork one into a new tab", Query: []string{"conversationId"}, Response: am.Fork{}})
+	routes.HandleFunc("/am/fork/compare", handleAMForkCompare, api.Doc{Methods: "GET", Summary: "Compare the outcomes of two branches of a conversation", Query: []string{"a", "b"}, Response: am.BranchComparison{}})
+	routes.HandleFunc("/am/erase", capabilities.Require(middleware.CapFilesDelete, handleAMErase), api.Doc{Methods: "POST", Summary: "Erase all AM history for a workspace, after confirming"})
+	routes.HandleFunc("/am/cleanup", capabilities.Require(middleware.CapFilesDelete, handleAMCleanup), api.Doc{Methods: "POST", Summary: "Remove old AM logs"})
+	routes.HandleFunc("/am/llm/conversations/", handleAMLLMConversations, api.Doc{Methods: "GET", Summary: "LLM conversations captured for a tab"})
+	routes.HandleFunc("/am/llm/conversation/", capabilities.Require(middleware.CapFilesDelete, handleAMLLMConversationDetail), api.Doc{Methods: "GET DELETE", Summary: "One captured LLM conversation, or delete it"})
+	routes.HandleFunc("/am/health", handleAMHealth, api.Doc{Methods: "GET", Summary: "AM capture health"})
+	routes.HandleFunc("/am/conversations", handleAMActiveConversations, api.Doc{Methods: "GET", Summary: "Conversations being captured now"})
+	routes.HandleFunc("/am/master-control", handleAMMasterControl, api.Doc{Methods: "POST", Summary: "Turn AM capture on or off"})
+	routes.HandleFunc("/am/pause", handleAMPause, api.Doc{Methods: "POST", Summary: "Suspend all AM capture"})
+	routes.HandleFunc("/am/resume", handleAMResume, api.Doc{Methods: "POST", Summary: "Resume AM capture"})
+	routes.HandleFunc("/am/capture", handleAMCapture, api.Doc{Methods: "GET POST", Summary: "Whether a tab is captured, or turn its capture on or off", Query: []string{"tabId"}})
+	routes.HandleFunc("/am/last", handleAMLast, api.Doc{Methods: "GET", Summary: "The most recently active AM session", Response: am.RestoreContext{}})
+	routes.HandleFunc("/am/restore/sessions", handleAMRestoreSessions, api.Doc{Methods: "GET", Summary: "Sessions that can be restored"})
+	routes.HandleFunc("/am/restore/context/", handleAMRestoreContext, api.Doc{Methods: "GET POST", Summary: "Restore context for a conversation"})
+	routes.HandleFunc("/am/log", handleAMLog, api.Doc{Methods: "POST", Summary: "Append to a tab's AM log"})
+
+	// Vision Configuration & Insights API
+	routes.HandleFunc("/vision/config", handleVisionConfig, api.Doc{Methods: "GET POST", Summary: "Get or set Vision detectors"})
+	routes.HandleFunc("/vision/insights/", handleVisionInsights, api.Doc{Methods: "GET", Summary: "Vision insights for a session"})
+	routes.HandleFunc("/vision/insights/summary/", handleVisionInsightsSummary, api.Doc{Methods: "GET", Summary: "Summary of Vision insights for a session"})
+
+	// Server info API - version, platform, listen details, and available features
+	routes.HandleFunc("/server/info", handleServerInfo, api.Doc{Methods: "GET", Summary: "Server version, platform, listen details, and available features", Response: serverInfo{}})
+
+	// Diagnostics API - keyboard lockout debugging
+	routes.HandleFunc("/diagnostics/keyboard", handleDiagnosticsKeyboard, api.Doc{Methods: "POST", Summary: "Report a keyboard diagnostic from the browser"})
+	routes.HandleFunc("/notifications", notify.HandleNotifications, api.Doc{Methods: "GET POST", Summary: "List notifications, or mark them read or clear them", Query: []string{"unread"}, Request: notify.UpdateRequest{}})
+	routes.HandleFunc("/notifications/stream", notify.HandleStream, api.Doc{Methods: "GET", Summary: "Notification events", Response: notify.Event{}, Stream: true})
+	routes.HandleFunc("/crashes", crash.HandleCrashes, api.Doc{Methods: "GET POST", Summary: "List crash reports, or acknowledge them", Query: []string{"id"}, Request: crash.AcknowledgeRequest{}})
+	routes.HandleFunc("/diagnostics/bundle", rateLimit(6, 2, handleDiagnosticsBundle), api.Doc{Methods: "POST", Summary: "Build a diagnostics bundle"})
+
+	// Feedback API - files a GitHub issue with logs and screenshots, or saves a bundle offline
+	routes.HandleFunc("/feedback", rateLimit(6, 2, feedback.Handler(diagnosticsReport)), api.Doc{Methods: "POST", Summary: "Submit feedback", Request: feedback.Request{}, Response: feedback.Result{}})
+
+	// Server logs API - recent entries and live tail for the Application Logs view
+	routes.HandleFunc("/logs", logging.HandleLogs, api.Doc{Methods: "GET", Summary: "Recent server log entries", Query: []string{"limit"}})
+	routes.HandleFunc("/logs/level", logging.HandleLevel, api.Doc{Methods: "GET POST", Summary: "Server log level, or change it until restart"})
+	routes.HandleFunc("/logs/stream", logging.HandleStream, api.Doc{Methods: "GET", Summary: "Stream server log entries", Stream: true})
+
+	// Storage API - long-term data on the configured backend (local, S3, or WebDAV)
+	routes.HandleFunc("/storage", handleStorage, api.Doc{Methods: "GET", Summary: "Storage backend status"})
+	routes.HandleFunc("/storage/test", rateLimit(10, 3, handleStorageTest), api.Doc{Methods: "POST", Summary: "Test storage backend settings"})
+	routes.HandleFunc("/storage/objects/", handleStorageObjects, api.Doc{Methods: "GET PUT DELETE", Summary: "Objects on the storage backend", Query: []string{"prefix"}})
+
+	// Desktop shortcut API
+	routes.HandleFunc("/desktop-shortcut", handleDesktopShortcut, api.Doc{Methods: "POST DELETE", Summary: "Create desktop and application menu shortcuts, or remove them", Request: ShortcutRequest{}})
+
+	// File management API
+	routes.HandleFunc("/files/list", rateLimit(120, 30, files.HandleList), api.Doc{Methods: "GET", Summary: "Directory tree", Query: []string{"path", "rootPath", "shell", "distro"}, Response: files.FileNode{}})
+	routes.HandleFunc("/files/stats", rateLimit(120, 30, files.HandleStats), api.Doc{Methods: "GET", Summary: "File counts for a directory", Query: []string{"path", "rootPath", "shell", "distro"}})
+	routes.HandleFunc("/files/read", files.HandleRead, api.Doc{Methods: "POST", Summary: "Read a file", Request: files.FileReadRequest{}})
+	routes.HandleFunc("/files/write", capabilities.Require(middleware.CapFilesWrite, files.HandleWrite), api.Doc{Methods: "POST", Summary: "Write a file", Request: files.FileWriteRequest{}})
+	routes.HandleFunc("/files/create", capabilities.Require(middleware.CapFilesWrite, files.HandleCreate), api.Doc{Methods: "POST", Summary: "Create a file or directory, optionally from a template", Request: files.FileCreateRequest{}})
+	routes.HandleFunc("/files/templates", files.HandleTemplates, api.Doc{Methods: "GET", Summary: "File templates"})
+	routes.HandleFunc("/files/delete", capabilities.Require(middleware.CapFilesDelete, files.HandleDelete), api.Doc{Methods: "POST", Summary: "Move a file to the trash", Request: files.FileDeleteRequest{}})
+	routes.HandleFunc("/files/trash", capabilities.Require(middleware.CapFilesDelete, files.HandleTrash), api.Doc{Methods: "GET POST", Summary: "List the trash, or restore or purge an entry", Request: files.TrashRequest{}})
+	routes.HandleFunc("/files/stream", files.HandleReadStream, api.Doc{Methods: "GET", Summary: "Read or follow a file in chunks", Query: []string{"path", "rootPath", "offset", "limit", "tail", "follow"}})
+	routes.HandleFunc("/files/stat", files.HandleStat, api.Doc{Methods: "GET", Summary: "File metadata", Query: []string{"path", "rootPath", "distro"}, Response: files.FileStat{}})
+	routes.HandleFunc("/files/chmod", capabilities.Require(middleware.CapFilesWrite, files.HandleChmod), api.Doc{Methods: "POST", Summary: "Change file permissions", Request: files.FileChmodRequest{}})
+	routes.HandleFunc("/files/hash", rateLimit(30, 10, files.HandleHash), api.Doc{Methods: "GET POST", Summary: "Hash a file, or verify a manifest", Query: []string{"path", "rootPath"}, Request: files.HashVerifyRequest{}})
+	routes.HandleFunc("/files/access-mode", files.HandleFileAccessMode, api.Doc{Methods: "GET", Summary: "File access restrictions"})
+
+	// Profiles API - separate data trees for users sharing this server
+	routes.HandleFunc("/profiles", profileManager.HandleProfiles, api.Doc{Methods: "GET POST DELETE", Summary: "List, create, or remove profiles", Query: []string{"name"}})
+
+	// Workspaces API - recent and pinned project roots for new tabs
+	routes.HandleFunc("/workspaces", workspaces.HandleWorkspaces, api.Doc{Methods: "GET POST", Summary: "List workspaces, or open, pin, unpin or remove one", Request: workspaces.Request{}})
+	routes.HandleFunc("/complete", completion.HandleComplete, api.Doc{Methods: "GET", Summary: "Suggestions to finish a command line from history, command cards, files, and git branches, most recent first", Query: []string{"prefix", "cwd", "limit"}, Response: []completion.Suggestion{}})
+	routes.HandleFunc("/history/search", history.HandleSearch, api.Doc{Methods: "GET", Summary: "Search command lines typed in every tab, ranked by frequency and recency", Query: []string{"q", "workspace", "limit"}, Response: []history.Match{}})
+	routes.HandleFunc("/history", capabilities.Require(middleware.CapFilesDelete, history.HandleHistory), api.Doc{Methods: "DELETE", Summary: "Clear the command history of a workspace, or all of it", Query: []string{"workspace", "all"}})
+	routes.HandleFunc("/jump", capabilities.Require(middleware.CapTerminalInject, termHandler.HandleJump), api.Doc{Methods: "GET POST", Summary: "Find visited directories by a few letters of their path, or cd a tab (the focused one by default) to the best match", Query: []string{"query", "tabId", "limit"}, Request: terminal.JumpRequest{}, Response: []history.Dir{}})
+
+	// Git API - repository status for file tree badges and per-tab branch display,
+	// limited to the file API's allowed roots
+	git.SetPathFilter(files.PathAllowed)
+	routes.HandleFunc("/git/status", git.HandleStatus, api.Doc{Methods: "GET", Summary: "Repository status", Query: []string{"path"}, Response: git.Status{}})
+	routes.HandleFunc("/git/diff", rateLimit(120, 30, git.HandleDiff), api.Doc{Methods: "GET", Summary: "Diff of a file", Query: []string{"path", "file", "staged"}})
+	routes.HandleFunc("/git/log", rateLimit(60, 20, git.HandleLog), api.Doc{Methods: "GET", Summary: "Commit history", Query: []string{"path", "file", "limit"}})
+	routes.HandleFunc("/git/branch", git.HandleBranch, api.Doc{Methods: "GET", Summary: "Branches", Query: []string{"path"}, Response: git.Branches{}})
+
+	// Assistant API - AI chat and command suggestions (Dev Mode only)
+	routes.HandleFunc("/assistant/status", handleAssistantStatus, api.Doc{Methods: "GET", Summary: "Assistant availability"})
+	routes.HandleFunc("/assistant/chat", handleAssistantChat, api.Doc{Methods: "POST", Summary: "Chat with the assistant", Request: assistant.ChatRequest{}})
+	routes.HandleFunc("/assistant/execute", capabilities.Require(middleware.CapAssistantExecute, handleAssistantExecute), api.Doc{Methods: "POST", Summary: "Run a suggested command", Request: assistant.ExecuteCommandRequest{}})
+	routes.HandleFunc("/assistant/model", handleAssistantSetModel, api.Doc{Methods: "POST", Summary: "Choose the assistant model", Request: assistant.SetModelRequest{}})
+	routes.HandleFunc("/assistant/run-tests", rateLimit(6, 2, handleAssistantRunTests), api.Doc{Methods: "POST", Summary: "Run the assistant test suite"})
+	routes.HandleFunc("/assistant/train-model", rateLimit(6, 2, handleAssistantTrainModel), api.Doc{Methods: "POST", Summary: "Start training the assistant model"})
+	routes.HandleFunc("/assistant/training-status/", handleAssistantTrainingStatus, api.Doc{Methods: "GET", Summary: "Progress of a training job"})
+}
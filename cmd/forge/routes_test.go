@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/api"
+	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+)
+
+// TestPathParameterRoutes checks that handlers reading an ID from the path
+// see the same ID whether the request used the legacy or the versioned path.
+func TestPathParameterRoutes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	mux := http.NewServeMux()
+	routes := api.NewRouter(mux, nil)
+	routes.HandleFunc("/sessions/", handleNamedSessions, api.Doc{})
+	handler := api.Compat(mux)
+
+	for _, prefix := range []string{api.LegacyPrefix, api.Prefix} {
+		name := "work" + strings.ReplaceAll(prefix, "/", "-")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, prefix+"/sessions/"+name, strings.NewReader(`{"tabs":[]}`)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("PUT %s/sessions/%s: status %d: %s", prefix, name, rr.Code, rr.Body.String())
+		}
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, prefix+"/sessions/"+name, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET %s/sessions/%s: status %d: %s", prefix, name, rr.Code, rr.Body.String())
+		}
+		var got struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("GET %s/sessions/%s: %v", prefix, name, err)
+		}
+		if got.Name != name {
+			t.Errorf("GET %s/sessions/%s: name = %q", prefix, name, got.Name)
+		}
+	}
+}
+
+// ungatedRoutes take a write method but stay available when locked down.
+// Requests to them aren't sent.
+var ungatedRoutes = map[string]bool{
+	// Reads that take a request body
+	"POST /commands/render":               true,
+	"POST /commands/keybindings/validate": true,
+	"POST /config/keymap/validate":        true,
+	"POST /files/read":                    true,
+	"POST /files/hash":                    true,
+	"POST /storage/test":                  true,
+	"POST /assistant/chat":                true,
+
+	// Command cards, chains, snippets and their stores, which the gated
+	// routes decide whether to run
+	"POST /commands":                  true,
+	"POST /commands/restore-defaults": true,
+	"POST /commands/import":           true,
+	"POST /commands/chains":           true,
+	"POST /commands/packs":            true,
+	"POST /commands/usage":            true,
+	"POST /commands/history":          true,
+	"POST /snippets":                  true,
+	"DELETE /snippets":                true,
+	"POST /secrets":                   true,
+	"DELETE /secrets":                 true,
+
+	// Tab layouts, workspaces, UI state and notifications
+	"POST /sessions":              true,
+	"PUT /sessions/":              true,
+	"POST /sessions/":             true,
+	"DELETE /sessions/":           true,
+	"POST /sessions/history":      true,
+	"POST /welcome":               true,
+	"POST /onboarding":            true,
+	"DELETE /onboarding":          true,
+	"POST /workspaces":            true,
+	"POST /notifications":         true,
+	"POST /crashes":               true,
+	"POST /diagnostics/keyboard":  true,
+	"POST /diagnostics/bundle":    true,
+	"POST /feedback":              true,
+	"POST /logs/level":            true,
+	"POST /forwards":              true,
+	"DELETE /forwards":            true,
+	"POST /vision/config":         true,
+	"POST /assistant/model":       true,
+	"POST /assistant/run-tests":   true,
+	"POST /assistant/train-model": true,
+
+	// AM capture, which records but doesn't delete
+	"POST /am/archive/":         true,
+	"POST /am/digests":          true,
+	"POST /am/fork":             true,
+	"POST /am/master-control":   true,
+	"POST /am/pause":            true,
+	"POST /am/resume":           true,
+	"POST /am/capture":          true,
+	"POST /am/restore/context/": true,
+	"POST /am/log":              true,
+
+	// Forge's own process, install and profiles
+	"POST /shutdown":              true,
+	"POST /update/apply":          true,
+	"POST /update/install-manual": true,
+	"POST /desktop-shortcut":      true,
+	"DELETE /desktop-shortcut":    true,
+	"POST /profiles":              true,
+	"DELETE /profiles":            true,
+}
+
+// lockdownRequests give routes whose handler checks the capability itself a
+// path and body that reach the check
+var lockdownRequests = map[string]struct{ path, body string }{
+	"PUT /storage/objects/":    {"/storage/objects/sessions/lockdown.json", "{}"},
+	"DELETE /storage/objects/": {"/storage/objects/sessions/lockdown.json", ""},
+	"POST /terminal/open-file": {"/terminal/open-file", `{"path":"main.go","editor":"external"}`},
+}
+
+// TestLockedDownRefusesMutatingRoutes walks every registered route and checks
+// that its writes are refused in locked-down mode, unless listed in ungatedRoutes.
+func TestLockedDownRefusesMutatingRoutes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	applyServerConfig(&commands.Config{LockedDown: true})
+	defer applyServerConfig(&commands.Config{})
+
+	mux := http.NewServeMux()
+	routes := api.NewRouter(mux, nil)
+	registerAPIRoutes(routes)
+
+	seen := map[string]bool{}
+	for _, route := range routes.Routes() {
+		for _, method := range strings.Fields(route.Methods) {
+			if method == http.MethodGet {
+				continue
+			}
+			name := method + " " + route.Path
+			seen[name] = true
+			if ungatedRoutes[name] {
+				continue
+			}
+			path, body := route.Path, "{}"
+			if req, ok := lockdownRequests[name]; ok {
+				path, body = req.path, req.body
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(method, api.Prefix+path, strings.NewReader(body)))
+			if rr.Code != http.StatusForbidden {
+				t.Errorf("%s: status %d while locked down, want 403", name, rr.Code)
+			}
+		}
+	}
+	for name := range ungatedRoutes {
+		if !seen[name] {
+			t.Errorf("ungatedRoutes lists %s, which isn't registered", name)
+		}
+	}
+}
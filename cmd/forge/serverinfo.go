@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/access"
+	"github.com/mikejsmith1985/forge-terminal/internal/am"
+	"github.com/mikejsmith1985/forge-terminal/internal/ports"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+	"github.com/mikejsmith1985/forge-terminal/internal/updater"
+)
+
+// serverInfo is returned by /api/server/info so the UI can feature-gate
+// without probing individual endpoints
+type serverInfo struct {
+	Version        string           `json:"version"`
+	OS             string           `json:"os"`
+	Arch           string           `json:"arch"`
+	PID            int              `json:"pid"`
+	Profile        string           `json:"profile,omitempty"` // Set in a profile's process
+	ListenAddr     string           `json:"listenAddr"`
+	TLS            bool             `json:"tls"`
+	RemoteAccess   bool             `json:"remoteAccess"` // Listening beyond loopback
+	StartedAt      time.Time        `json:"startedAt"`
+	Uptime         string           `json:"uptime"`
+	UptimeSeconds  int64            `json:"uptimeSeconds"`
+	PreferredPorts []int            `json:"preferredPorts"`
+	PortConflicts  []ports.Conflict `json:"portConflicts"` // Preferred ports that were taken at startup
+	Features       serverFeatures   `json:"features"`
+}
+
+// serverFeatures reports which optional features are usable
+type serverFeatures struct {
+	Assistant struct {
+		Backend   string `json:"backend"` // "ollama"
+		Available bool   `json:"available"`
+		Model     string `json:"model,omitempty"`
+	} `json:"assistant"`
+	AM struct {
+		Enabled bool     `json:"enabled"`
+		Status  string   `json:"status,omitempty"` // HEALTHY, DEGRADED, FAILED
+		Layers  []string `json:"layers"`
+	} `json:"am"`
+	WSL struct {
+		Available bool     `json:"available"`
+		Distros   []string `json:"distros,omitempty"`
+	} `json:"wsl"`
+}
+
+// assistantProbeTimeout bounds the check for a running assistant backend
+const assistantProbeTimeout = 2 * time.Second
+
+func handleServerInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	uptime := time.Since(serverStartedAt)
+	info := serverInfo{
+		Version:        updater.GetVersion(),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		PID:            os.Getpid(),
+		Profile:        storage.CurrentProfile(),
+		ListenAddr:     serverAddr,
+		TLS:            serverTLS != nil,
+		StartedAt:      serverStartedAt,
+		Uptime:         uptime.Round(time.Second).String(),
+		UptimeSeconds:  int64(uptime.Seconds()),
+		PreferredPorts: preferredPorts,
+		PortConflicts:  portConflicts,
+		Features:       detectFeatures(r.Context()),
+	}
+	if host, _, err := net.SplitHostPort(serverAddr); err == nil {
+		info.RemoteAccess = !access.IsLoopbackHost(host)
+	}
+	if info.PortConflicts == nil {
+		info.PortConflicts = []ports.Conflict{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+func detectFeatures(ctx context.Context) serverFeatures {
+	var features serverFeatures
+
+	features.Assistant.Backend = "ollama"
+	if assistantService != nil {
+		ctx, cancel := context.WithTimeout(ctx, assistantProbeTimeout)
+		defer cancel()
+		if status, err := assistantService.GetStatus(ctx); err == nil {
+			features.Assistant.Available = status.Available
+			features.Assistant.Model = status.CurrentModel
+		}
+	}
+
+	features.AM.Layers = []string{}
+	if system := am.GetSystem(); system != nil {
+		features.AM.Enabled = system.IsEnabled()
+		features.AM.Status = system.GetHealth().Status
+		features.AM.Layers = system.ActiveLayers()
+	}
+
+	if runtime.GOOS == "windows" {
+		if distros, err := listWSLDistros(); err == nil && len(distros) > 0 {
+			features.WSL.Available = true
+			features.WSL.Distros = distros
+		}
+	}
+	return features
+}
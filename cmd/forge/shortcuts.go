@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Shortcut locations
+const (
+	shortcutDesktop = "desktop" // The user's desktop
+	shortcutMenu    = "menu"    // Start Menu, application launcher, or ~/Applications
+)
+
+// ShortcutRequest is the body for POST /api/desktop-shortcut
+type ShortcutRequest struct {
+	Locations []string `json:"locations,omitempty"` // "desktop" and/or "menu" (empty = both)
+}
+
+// forgeIconSVG is the launcher icon written next to Linux .desktop entries
+const forgeIconSVG = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 64 64">
+  <rect width="64" height="64" rx="12" fill="#1e1e2e"/>
+  <path d="M14 20l12 12-12 12" fill="none" stroke="#f97316" stroke-width="6" stroke-linecap="round" stroke-linejoin="round"/>
+  <path d="M32 46h18" stroke="#f97316" stroke-width="6" stroke-linecap="round"/>
+</svg>
+`
+
+// forgeExecutable returns the real path of the running binary, which
+// shortcuts launch
+func forgeExecutable() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	return execPath, nil
+}
+
+// handleDesktopShortcut creates launchers for Forge on the desktop and in the
+// platform's application menu (POST), or removes them (DELETE).
+func handleDesktopShortcut(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodPost:
+		var req ShortcutRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		locations := map[string]bool{}
+		for _, location := range req.Locations {
+			if location != shortcutDesktop && location != shortcutMenu {
+				http.Error(w, fmt.Sprintf("unknown location %q (use %q or %q)", location, shortcutDesktop, shortcutMenu), http.StatusBadRequest)
+				return
+			}
+			locations[location] = true
+		}
+		if len(locations) == 0 {
+			locations[shortcutDesktop], locations[shortcutMenu] = true, true
+		}
+
+		created, err := createShortcuts(locations)
+		if err != nil {
+			log.Printf("[Desktop] Failed to create shortcut: %v", err)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+				"created": created,
+			})
+			return
+		}
+		log.Printf("[Desktop] Shortcuts created: %v", created)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Shortcuts created",
+			"created": created,
+		})
+
+	case http.MethodDelete:
+		removed, err := removeShortcuts()
+		if err != nil {
+			log.Printf("[Desktop] Failed to remove shortcuts: %v", err)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+				"removed": removed,
+			})
+			return
+		}
+		log.Printf("[Desktop] Shortcuts removed: %v", removed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"removed": removed,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// removePaths removes the files and directories that exist, returning them
+func removePaths(paths []string) ([]string, error) {
+	removed := []string{}
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 )
 
 // hideWindow is a no-op on non-Windows platforms
@@ -16,42 +17,71 @@ func hideWindow(cmd *exec.Cmd) {
 	// Nothing to do on Unix-like systems
 }
 
-// createDesktopShortcut creates a desktop shortcut for Forge Terminal
-func createDesktopShortcut() error {
-	// Get the executable path
-	execPath, err := os.Executable()
+// createShortcuts creates Forge launchers in the given locations, returning
+// the paths written
+func createShortcuts(locations map[string]bool) ([]string, error) {
+	execPath, err := forgeExecutable()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
-	}
-
-	// Resolve symlinks to get the real path
-	execPath, err = filepath.EvalSymlinks(execPath)
-	if err != nil {
-		return fmt.Errorf("failed to resolve executable path: %w", err)
+		return nil, err
 	}
 
 	// Get user's home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	if runtime.GOOS == "darwin" {
-		return createMacOSShortcut(execPath, homeDir)
+		return createMacOSShortcuts(execPath, homeDir, locations)
 	}
-	return createLinuxShortcut(execPath, homeDir)
+	return createLinuxShortcuts(execPath, homeDir, locations)
 }
 
-// createLinuxShortcut creates a .desktop file on Linux
-func createLinuxShortcut(execPath, homeDir string) error {
-	desktopDir := filepath.Join(homeDir, "Desktop")
+// removeShortcuts removes every launcher createShortcuts makes, returning the
+// paths removed
+func removeShortcuts() ([]string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if runtime.GOOS == "darwin" {
+		return removePaths([]string{
+			filepath.Join(homeDir, "Desktop", "Forge Terminal"),
+			macOSAppPath(homeDir),
+		})
+	}
+	return removePaths([]string{
+		filepath.Join(homeDir, "Desktop", "forge-terminal.desktop"),
+		filepath.Join(linuxDataDir(homeDir), "applications", "forge-terminal.desktop"),
+		linuxIconPath(homeDir),
+	})
+}
 
-	// Create Desktop directory if it doesn't exist
-	if err := os.MkdirAll(desktopDir, 0755); err != nil {
-		return fmt.Errorf("failed to create Desktop directory: %w", err)
+// linuxDataDir is $XDG_DATA_HOME, where application menu entries and icons go
+func linuxDataDir(homeDir string) string {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return dir
 	}
+	return filepath.Join(homeDir, ".local", "share")
+}
 
-	shortcutPath := filepath.Join(desktopDir, "forge-terminal.desktop")
+func linuxIconPath(homeDir string) string {
+	return filepath.Join(linuxDataDir(homeDir), "icons", "hicolor", "scalable", "apps", "forge-terminal.svg")
+}
+
+// createLinuxShortcuts writes .desktop files to the Desktop and the
+// application menu, with the Forge icon
+func createLinuxShortcuts(execPath, homeDir string, locations map[string]bool) ([]string, error) {
+	created := []string{}
+
+	iconPath := linuxIconPath(homeDir)
+	icon := "utilities-terminal"
+	if err := os.MkdirAll(filepath.Dir(iconPath), 0755); err == nil {
+		if err := os.WriteFile(iconPath, []byte(forgeIconSVG), 0644); err == nil {
+			icon = iconPath
+			created = append(created, iconPath)
+		}
+	}
 
 	content := fmt.Sprintf(`[Desktop Entry]
 Version=1.0
@@ -59,44 +89,122 @@ Type=Application
 Name=Forge Terminal
 Comment=A modern terminal with AI integrations
 Exec=%s
-Icon=utilities-terminal
+Icon=%s
 Terminal=false
 Categories=Development;System;TerminalEmulator;
-`, execPath)
+`, desktopEntryQuote(execPath), icon)
+
+	if locations[shortcutMenu] {
+		menuDir := filepath.Join(linuxDataDir(homeDir), "applications")
+		if err := os.MkdirAll(menuDir, 0755); err != nil {
+			return created, fmt.Errorf("failed to create applications directory: %w", err)
+		}
+		menuPath := filepath.Join(menuDir, "forge-terminal.desktop")
+		if err := os.WriteFile(menuPath, []byte(content), 0644); err != nil {
+			return created, fmt.Errorf("failed to write menu entry: %w", err)
+		}
+		created = append(created, menuPath)
+		// Refresh the launcher's cache where the tool exists
+		_ = exec.Command("update-desktop-database", menuDir).Run()
+	}
+
+	if locations[shortcutDesktop] {
+		desktopDir := filepath.Join(homeDir, "Desktop")
 
-	if err := os.WriteFile(shortcutPath, []byte(content), 0755); err != nil {
-		return fmt.Errorf("failed to write desktop file: %w", err)
+		// Create Desktop directory if it doesn't exist
+		if err := os.MkdirAll(desktopDir, 0755); err != nil {
+			return created, fmt.Errorf("failed to create Desktop directory: %w", err)
+		}
+
+		shortcutPath := filepath.Join(desktopDir, "forge-terminal.desktop")
+		if err := os.WriteFile(shortcutPath, []byte(content), 0755); err != nil {
+			return created, fmt.Errorf("failed to write desktop file: %w", err)
+		}
+		created = append(created, shortcutPath)
+
+		// Try to mark as trusted using gio (works on GNOME-based systems)
+		gioCmd := exec.Command("gio", "set", shortcutPath, "metadata::trusted", "true")
+		_ = gioCmd.Run() // Ignore errors - this is optional
 	}
 
-	// Try to mark as trusted using gio (works on GNOME-based systems)
-	gioCmd := exec.Command("gio", "set", shortcutPath, "metadata::trusted", "true")
-	_ = gioCmd.Run() // Ignore errors - this is optional
+	return created, nil
+}
 
-	// Also try chmod to make it executable (redundant but ensures compatibility)
-	chmodCmd := exec.Command("chmod", "+x", shortcutPath)
-	_ = chmodCmd.Run()
+// desktopEntryQuote quotes a path for a .desktop Exec line, which is split on spaces
+func desktopEntryQuote(path string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(path) + `"`
+}
 
-	return nil
+func macOSAppPath(homeDir string) string {
+	return filepath.Join(homeDir, "Applications", "Forge Terminal.app")
 }
 
-// createMacOSShortcut creates an alias on macOS Desktop
-func createMacOSShortcut(execPath, homeDir string) error {
-	desktopDir := filepath.Join(homeDir, "Desktop")
+// createMacOSShortcuts creates an app stub in ~/Applications, so Forge shows
+// up in Launchpad and Spotlight, and an alias to it on the Desktop
+func createMacOSShortcuts(execPath, homeDir string, locations map[string]bool) ([]string, error) {
+	created := []string{}
+
+	// The Desktop alias points at the stub, so it is created either way
+	appPath := macOSAppPath(homeDir)
+	if err := writeMacOSApp(appPath, execPath); err != nil {
+		return created, err
+	}
+	created = append(created, appPath)
+
+	if locations[shortcutDesktop] {
+		desktopDir := filepath.Join(homeDir, "Desktop")
+
+		// Create Desktop directory if it doesn't exist
+		if err := os.MkdirAll(desktopDir, 0755); err != nil {
+			return created, fmt.Errorf("failed to create Desktop directory: %w", err)
+		}
+
+		aliasPath := filepath.Join(desktopDir, "Forge Terminal")
+
+		// Remove existing alias if present
+		os.Remove(aliasPath)
 
-	// Create Desktop directory if it doesn't exist
-	if err := os.MkdirAll(desktopDir, 0755); err != nil {
-		return fmt.Errorf("failed to create Desktop directory: %w", err)
+		if err := os.Symlink(appPath, aliasPath); err != nil {
+			return created, fmt.Errorf("failed to create symlink: %w", err)
+		}
+		created = append(created, aliasPath)
 	}
 
-	aliasPath := filepath.Join(desktopDir, "Forge Terminal")
+	return created, nil
+}
 
-	// Remove existing alias if present
-	os.Remove(aliasPath)
+// writeMacOSApp writes a minimal .app bundle whose executable starts Forge
+func writeMacOSApp(appPath, execPath string) error {
+	macOSDir := filepath.Join(appPath, "Contents", "MacOS")
+	if err := os.MkdirAll(macOSDir, 0755); err != nil {
+		return fmt.Errorf("failed to create app bundle: %w", err)
+	}
 
-	// Create a symlink as macOS alias (simple approach)
-	if err := os.Symlink(execPath, aliasPath); err != nil {
-		return fmt.Errorf("failed to create symlink: %w", err)
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleName</key>
+	<string>Forge Terminal</string>
+	<key>CFBundleIdentifier</key>
+	<string>com.github.mikejsmith1985.forge-terminal</string>
+	<key>CFBundleExecutable</key>
+	<string>forge-terminal</string>
+	<key>CFBundlePackageType</key>
+	<string>APPL</string>
+	<key>LSUIElement</key>
+	<true/>
+</dict>
+</plist>
+`
+	if err := os.WriteFile(filepath.Join(appPath, "Contents", "Info.plist"), []byte(plist), 0644); err != nil {
+		return fmt.Errorf("failed to write Info.plist: %w", err)
 	}
 
+	quoted := "'" + strings.ReplaceAll(execPath, "'", `'\''`) + "'"
+	launcher := fmt.Sprintf("#!/bin/sh\nexec %s \"$@\"\n", quoted)
+	if err := os.WriteFile(filepath.Join(macOSDir, "forge-terminal"), []byte(launcher), 0755); err != nil {
+		return fmt.Errorf("failed to write app launcher: %w", err)
+	}
 	return nil
 }
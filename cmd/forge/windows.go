@@ -5,9 +5,9 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"syscall"
 )
 
@@ -19,50 +19,85 @@ func hideWindow(cmd *exec.Cmd) {
 	}
 }
 
-// createDesktopShortcut creates a desktop shortcut for Forge Terminal on Windows
-func createDesktopShortcut() error {
-	// Get the executable path
-	execPath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
-	}
-
-	// Resolve symlinks to get the real path
-	execPath, err = filepath.EvalSymlinks(execPath)
-	if err != nil {
-		return fmt.Errorf("failed to resolve executable path: %w", err)
-	}
+// shortcutFolders maps shortcut locations to .NET special folders
+var shortcutFolders = map[string]string{
+	shortcutDesktop: "Desktop",
+	shortcutMenu:    "Programs", // Start Menu\Programs
+}
 
-	// Get user's Desktop folder using PowerShell
+// specialFolder returns a Windows special folder path using PowerShell
+func specialFolder(name string) (string, error) {
 	cmd := exec.Command("powershell", "-NoProfile", "-Command",
-		"[Environment]::GetFolderPath('Desktop')")
+		fmt.Sprintf("[Environment]::GetFolderPath('%s')", name))
 	hideWindow(cmd)
 	output, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("failed to get Desktop path: %w", err)
+		return "", fmt.Errorf("failed to get %s path: %w", name, err)
+	}
+	folder := strings.TrimSpace(string(output))
+	if folder == "" {
+		return "", fmt.Errorf("no %s folder", name)
+	}
+	return filepath.Clean(folder), nil
+}
+
+// createShortcuts creates Forge Terminal.lnk on the Desktop and in the Start
+// Menu, returning the paths written
+func createShortcuts(locations map[string]bool) ([]string, error) {
+	execPath, err := forgeExecutable()
+	if err != nil {
+		return nil, err
 	}
 
-	desktopPath := string(output)
-	// Trim whitespace/newlines
-	desktopPath = filepath.Clean(desktopPath[:len(desktopPath)-1])
+	created := []string{}
+	for _, location := range []string{shortcutDesktop, shortcutMenu} {
+		if !locations[location] {
+			continue
+		}
+		folder, err := specialFolder(shortcutFolders[location])
+		if err != nil {
+			return created, err
+		}
+		shortcutPath := filepath.Join(folder, "Forge Terminal.lnk")
+		if err := writeShortcut(shortcutPath, execPath); err != nil {
+			return created, err
+		}
+		created = append(created, shortcutPath)
+	}
+	return created, nil
+}
 
-	shortcutPath := filepath.Join(desktopPath, "Forge Terminal.lnk")
+// removeShortcuts removes the shortcuts createShortcuts makes, returning the
+// paths removed
+func removeShortcuts() ([]string, error) {
+	var paths []string
+	for _, location := range []string{shortcutDesktop, shortcutMenu} {
+		folder, err := specialFolder(shortcutFolders[location])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, filepath.Join(folder, "Forge Terminal.lnk"))
+	}
+	return removePaths(paths)
+}
 
-	// Create shortcut using PowerShell
+// writeShortcut creates a .lnk to execPath with its icon, using PowerShell
+func writeShortcut(shortcutPath, execPath string) error {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
 	psScript := fmt.Sprintf(`
 $WshShell = New-Object -ComObject WScript.Shell
-$Shortcut = $WshShell.CreateShortcut('%s')
-$Shortcut.TargetPath = '%s'
-$Shortcut.WorkingDirectory = '%s'
+$Shortcut = $WshShell.CreateShortcut(%s)
+$Shortcut.TargetPath = %s
+$Shortcut.WorkingDirectory = %s
+$Shortcut.IconLocation = %s
 $Shortcut.Description = 'Forge Terminal - Modern terminal with AI integrations'
 $Shortcut.Save()
-`, shortcutPath, execPath, filepath.Dir(execPath))
+`, quote(shortcutPath), quote(execPath), quote(filepath.Dir(execPath)), quote(execPath+",0"))
 
 	createCmd := exec.Command("powershell", "-NoProfile", "-Command", psScript)
 	hideWindow(createCmd)
 	if err := createCmd.Run(); err != nil {
-		return fmt.Errorf("failed to create shortcut: %w", err)
+		return fmt.Errorf("failed to create shortcut %s: %w", shortcutPath, err)
 	}
-
 	return nil
 }
@@ -0,0 +1,200 @@
+// Package access guards a Forge server that is reachable from other machines:
+// an IP allowlist plus a required access token or basic-auth login for
+// non-loopback clients.
+package access
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Request selectors for the access token. The query parameter is remembered in
+// a cookie so the page's own API and WebSocket requests are authenticated too.
+const (
+	TokenParam  = "access_token"
+	TokenCookie = "forge_access"
+)
+
+// Policy decides which clients may use the server.
+type Policy struct {
+	Token      string       // Access token (query parameter, cookie, or bearer header)
+	Username   string       // Basic-auth login, used when Password is set too
+	Password   string       // Basic-auth password
+	AllowedIPs []*net.IPNet // Remote clients allowed to connect (empty = any)
+}
+
+// ParseAllowedIPs parses allowlist entries, each an IP address or CIDR range.
+func ParseAllowedIPs(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// IsLoopbackHost reports whether a listen host only accepts local connections.
+func IsLoopbackHost(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// NewToken generates a random access token.
+func NewToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HasAuth reports whether the policy requires remote clients to authenticate.
+func (p *Policy) HasAuth() bool {
+	return p.Token != "" || (p.Username != "" && p.Password != "")
+}
+
+// Middleware enforces the policy. Clients on this machine are always let
+// through: the browser Forge opens at startup and later launches handing over
+// to this server have no token, and reach it from one of the machine's own
+// addresses when Forge listens on a LAN address rather than loopback.
+func (p *Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(r)
+		if localIP(ip) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !p.allowedIP(ip) {
+			log.Printf("[Access] Rejected connection from %s (not in allowedIPs)", r.RemoteAddr)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if !p.authenticated(r) {
+			if p.Username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Forge Terminal", charset="UTF-8"`)
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Remember a token passed in the URL for the page's follow-up requests
+		if token := r.URL.Query().Get(TokenParam); token != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     TokenCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p *Policy) allowedIP(ip net.IP) bool {
+	if len(p.AllowedIPs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range p.AllowedIPs {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticated checks the request's token or basic-auth credentials. A policy
+// without any credentials configured lets everyone through.
+func (p *Policy) authenticated(r *http.Request) bool {
+	if !p.HasAuth() {
+		return true
+	}
+	if p.Token != "" {
+		for _, candidate := range requestTokens(r) {
+			if equal(candidate, p.Token) {
+				return true
+			}
+		}
+	}
+	if p.Username != "" && p.Password != "" {
+		if user, pass, ok := r.BasicAuth(); ok && equal(user, p.Username) && equal(pass, p.Password) {
+			return true
+		}
+	}
+	return false
+}
+
+func requestTokens(r *http.Request) []string {
+	var tokens []string
+	if token := r.URL.Query().Get(TokenParam); token != "" {
+		tokens = append(tokens, token)
+	}
+	if c, err := r.Cookie(TokenCookie); err == nil && c.Value != "" {
+		tokens = append(tokens, c.Value)
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		tokens = append(tokens, strings.TrimPrefix(auth, "Bearer "))
+	}
+	return tokens
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// localIP reports whether ip is loopback or one of this machine's addresses.
+func localIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
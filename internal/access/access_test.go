@@ -0,0 +1,138 @@
+package access
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(p *Policy, req *http.Request) *httptest.ResponseRecorder {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+	rec := httptest.NewRecorder()
+	p.Middleware(ok).ServeHTTP(rec, req)
+	return rec
+}
+
+func request(remote, target string) *http.Request {
+	req := httptest.NewRequest("GET", target, nil)
+	req.RemoteAddr = remote
+	return req
+}
+
+func TestTokenAuth(t *testing.T) {
+	p := &Policy{Token: "secret"}
+
+	if rec := serve(p, request("127.0.0.1:5000", "/")); rec.Code != http.StatusOK {
+		t.Errorf("Expected loopback clients through, got %d", rec.Code)
+	}
+	if rec := serve(p, request("192.168.1.20:5000", "/")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+
+	rec := serve(p, request("192.168.1.20:5000", "/?access_token=secret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the query token to be accepted, got %d", rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != TokenCookie {
+		t.Fatalf("Expected %s cookie, got %v", TokenCookie, cookies)
+	}
+
+	req := request("192.168.1.20:5000", "/ws")
+	req.AddCookie(cookies[0])
+	if rec := serve(p, req); rec.Code != http.StatusOK {
+		t.Errorf("Expected the cookie to be accepted, got %d", rec.Code)
+	}
+
+	req = request("192.168.1.20:5000", "/api/commands")
+	req.Header.Set("Authorization", "Bearer secret")
+	if rec := serve(p, req); rec.Code != http.StatusOK {
+		t.Errorf("Expected the bearer token to be accepted, got %d", rec.Code)
+	}
+}
+
+func TestOwnAddressesAreLocal(t *testing.T) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		t.Skipf("No interface addresses: %v", err)
+	}
+	var own net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			own = ipNet.IP
+			break
+		}
+	}
+	if own == nil {
+		t.Skip("No non-loopback interface address")
+	}
+
+	p := &Policy{Token: "secret"}
+	if rec := serve(p, request(net.JoinHostPort(own.String(), "5000"), "/")); rec.Code != http.StatusOK {
+		t.Errorf("Expected a client on this machine's address %s through, got %d", own, rec.Code)
+	}
+	if rec := serve(p, request("203.0.113.9:5000", "/")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for another machine, got %d", rec.Code)
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	p := &Policy{Username: "me", Password: "pw"}
+
+	rec := serve(p, request("10.0.0.5:5000", "/"))
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected a basic-auth challenge, got %d %v", rec.Code, rec.Header())
+	}
+
+	req := request("10.0.0.5:5000", "/")
+	req.SetBasicAuth("me", "wrong")
+	if rec := serve(p, req); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong password, got %d", rec.Code)
+	}
+	req.SetBasicAuth("me", "pw")
+	if rec := serve(p, req); rec.Code != http.StatusOK {
+		t.Errorf("Expected valid credentials to be accepted, got %d", rec.Code)
+	}
+}
+
+func TestAllowedIPs(t *testing.T) {
+	nets, err := ParseAllowedIPs([]string{"192.168.1.0/24", "10.0.0.7"})
+	if err != nil {
+		t.Fatalf("ParseAllowedIPs failed: %v", err)
+	}
+	p := &Policy{AllowedIPs: nets}
+
+	for remote, want := range map[string]int{
+		"192.168.1.44:1": http.StatusOK,
+		"10.0.0.7:1":     http.StatusOK,
+		"10.0.0.8:1":     http.StatusForbidden,
+		"[::1]:1":        http.StatusOK,
+	} {
+		if rec := serve(p, request(remote, "/")); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", remote, want, rec.Code)
+		}
+	}
+
+	if _, err := ParseAllowedIPs([]string{"not-an-ip"}); err == nil {
+		t.Error("Expected error for an invalid entry")
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"":            true,
+		"localhost":   true,
+		"127.0.0.1":   true,
+		"::1":         true,
+		"0.0.0.0":     false,
+		"192.168.1.2": false,
+	} {
+		if got := IsLoopbackHost(host); got != want {
+			t.Errorf("IsLoopbackHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
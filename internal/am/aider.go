@@ -0,0 +1,78 @@
+package am
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+// maxAiderLine bounds the partial output line kept between chunks
+const maxAiderLine = 4096
+
+// scanAiderOutputLocked reads Aider's output a line at a time, recording the
+// files it edits and commits it makes in the conversation's metadata and
+// publishing a FILE_MODIFIED event for each edit. Must be called with lock
+// held.
+func (l *LLMLogger) scanAiderOutputLocked(conv *LLMConversation, output string) {
+	l.aiderLine += output
+	for {
+		end := strings.IndexByte(l.aiderLine, '\n')
+		if end < 0 {
+			break
+		}
+		line := l.aiderLine[:end]
+		l.aiderLine = l.aiderLine[end+1:]
+		if activity, ok := llm.ParseAiderLine(line); ok {
+			l.recordAiderActivityLocked(conv, activity)
+		}
+	}
+	if len(l.aiderLine) > maxAiderLine {
+		l.aiderLine = l.aiderLine[len(l.aiderLine)-maxAiderLine:]
+	}
+}
+
+// recordAiderActivityLocked adds an edit or commit to the conversation's
+// metadata. The metadata is replaced rather than changed in place, as
+// pending async saves share it. Must be called with lock held.
+func (l *LLMLogger) recordAiderActivityLocked(conv *LLMConversation, activity llm.AiderActivity) {
+	metadata := ConversationMetadata{}
+	if conv.Metadata != nil {
+		metadata = *conv.Metadata
+	}
+	if activity.CommitSHA != "" {
+		if !containsString(metadata.Commits, activity.CommitSHA) {
+			metadata.Commits = append(append([]string(nil), metadata.Commits...), activity.CommitSHA)
+			conv.Metadata = &metadata
+		}
+		log.Printf("[LLM Logger] Aider committed %s in %s", activity.CommitSHA, conv.ConversationID)
+		return
+	}
+
+	if !containsString(metadata.FilesModified, activity.EditedFile) {
+		metadata.FilesModified = append(append([]string(nil), metadata.FilesModified...), activity.EditedFile)
+		conv.Metadata = &metadata
+	}
+	EventBus.Publish(&LayerEvent{
+		Type:      "FILE_MODIFIED",
+		Layer:     1,
+		TabID:     l.tabID,
+		ConvID:    conv.ConversationID,
+		Provider:  conv.Provider,
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			"file":             activity.EditedFile,
+			"workingDirectory": metadata.WorkingDirectory,
+		},
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package am
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestAiderActivityRecorded(t *testing.T) {
+	logger := &LLMLogger{tabID: "aider-tab", conversations: make(map[string]*LLMConversation), amDir: t.TempDir()}
+	defer WaitForPendingWrites()
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderAider, Type: llm.CommandCode})
+	if convID == "" {
+		t.Fatal("Expected a conversation")
+	}
+
+	edits := make(chan *LayerEvent, 4)
+	EventBus.Subscribe(func(event *LayerEvent) {
+		if event.Type == "FILE_MODIFIED" && event.ConvID == convID {
+			edits <- event
+		}
+	})
+
+	// Lines arrive split across chunks and wrapped in colour codes
+	logger.AddOutput("\x1b[32mApplied edit to src/ap")
+	logger.AddOutput("p.py\x1b[0m\r\nApplied edit to README.md\r\n")
+	logger.AddOutput("Applied edit to src/app.py\r\nCommit 1a2b3c4 fix: handle empty input\r\n")
+
+	metadata := logger.GetConversation(convID).Metadata
+	if len(metadata.FilesModified) != 2 || metadata.FilesModified[0] != "src/app.py" || metadata.FilesModified[1] != "README.md" {
+		t.Errorf("Unexpected files modified: %v", metadata.FilesModified)
+	}
+	if len(metadata.Commits) != 1 || metadata.Commits[0] != "1a2b3c4" {
+		t.Errorf("Unexpected commits: %v", metadata.Commits)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-edits:
+			if event.TabID != "aider-tab" || event.Metadata["file"] == "" {
+				t.Errorf("Unexpected event: %+v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 3 FILE_MODIFIED events, got %d", i)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package am
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDiffLines bounds the answers compared line by line
+const maxDiffLines = 400
+
+// Fork is a conversation waiting to be resumed in a new tab. The next
+// conversation started in TabID is recorded as its child.
+type Fork struct {
+	ParentID      string `json:"parentId"`
+	TabID         string `json:"tabId"`
+	ForkTurn      int    `json:"forkTurn"` // Parent turns the fork shares
+	RestorePrompt string `json:"restorePrompt"`
+}
+
+// BranchOutcome is where one branch of a forked conversation ended up.
+type BranchOutcome struct {
+	ConversationID string    `json:"conversationId"`
+	TabID          string    `json:"tabId"`
+	Turns          int       `json:"turns"` // Turns since the fork
+	Complete       bool      `json:"complete"`
+	LastActivity   time.Time `json:"lastActivity"`
+	FinalAnswer    string    `json:"finalAnswer"` // The branch's last assistant answer
+}
+
+// BranchComparison compares two conversations that share a fork point.
+type BranchComparison struct {
+	ParentID   string        `json:"parentId"`
+	ForkTurn   int           `json:"forkTurn"`
+	A          BranchOutcome `json:"a"`
+	B          BranchOutcome `json:"b"`
+	AnswerDiff string        `json:"answerDiff"` // Line diff of the final answers, A to B
+}
+
+var (
+	pendingForksMu sync.Mutex
+	pendingForks   = map[string]*Fork{} // By tab ID
+)
+
+// lookupConversation finds a conversation in an open tab or on disk. The
+// result is a copy.
+func lookupConversation(amDir, convID string) *LLMConversation {
+	llmLoggersMu.RLock()
+	tabIDs := make([]string, 0, len(llmLoggers))
+	for tabID := range llmLoggers {
+		tabIDs = append(tabIDs, tabID)
+	}
+	llmLoggersMu.RUnlock()
+	for _, tabID := range tabIDs {
+		if conv := snapshotConversation(tabID, convID); conv != nil {
+			return conv
+		}
+	}
+	for _, conv := range conversationFiles(amDir) {
+		if conv.ConversationID == convID {
+			return conv
+		}
+	}
+	return nil
+}
+
+// ForkConversation prepares to resume a stored conversation in the tab
+// tabID, leaving the original as it is: the next conversation started in
+// that tab is recorded as a fork of it. The error wraps os.ErrNotExist when
+// there is no such conversation.
+func ForkConversation(amDir, convID, tabID string) (*Fork, error) {
+	if tabID == "" {
+		return nil, fmt.Errorf("tab ID required")
+	}
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	parent := lookupConversation(amDir, convID)
+	if parent == nil {
+		return nil, fmt.Errorf("conversation %s: %w", convID, os.ErrNotExist)
+	}
+	fork := &Fork{ParentID: convID, TabID: tabID, ForkTurn: len(parent.Turns), RestorePrompt: restorePrompt(parent.Turns)}
+
+	pendingForksMu.Lock()
+	pendingForks[tabID] = fork
+	pendingForksMu.Unlock()
+	log.Printf("[LLM Logger] Tab %s will continue conversation %s as a fork", tabID, convID)
+	return fork, nil
+}
+
+// adoptForkLocked records conv as the fork pending for the logger's tab, if
+// there is one. Must be called with lock held.
+func (l *LLMLogger) adoptForkLocked(conv *LLMConversation) {
+	pendingForksMu.Lock()
+	fork := pendingForks[l.tabID]
+	delete(pendingForks, l.tabID)
+	pendingForksMu.Unlock()
+	if fork == nil {
+		return
+	}
+	if conv.Metadata == nil {
+		conv.Metadata = &ConversationMetadata{}
+	}
+	conv.Metadata.ForkedFrom = fork.ParentID
+	conv.Metadata.ForkTurn = fork.ForkTurn
+}
+
+// ListForks returns the IDs of conversations forked from convID, oldest
+// first.
+func ListForks(amDir, convID string) []string {
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	forks := map[string]time.Time{}
+	for _, conv := range conversationFiles(amDir) {
+		if conv.Metadata != nil && conv.Metadata.ForkedFrom == convID {
+			forks[conv.ConversationID] = conv.StartTime
+		}
+	}
+	ids := make([]string, 0, len(forks))
+	for id := range forks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return forks[ids[i]].Before(forks[ids[j]]) })
+	return ids
+}
+
+// CompareBranches compares the outcomes of two conversations that branch
+// from the same point: a conversation and a fork of it, or two forks of the
+// same conversation.
+func CompareBranches(amDir, idA, idB string) (*BranchComparison, error) {
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	a := lookupConversation(amDir, idA)
+	if a == nil {
+		return nil, fmt.Errorf("conversation %s: %w", idA, os.ErrNotExist)
+	}
+	b := lookupConversation(amDir, idB)
+	if b == nil {
+		return nil, fmt.Errorf("conversation %s: %w", idB, os.ErrNotExist)
+	}
+	forkOf := func(conv *LLMConversation) (string, int) {
+		if conv.Metadata == nil {
+			return "", 0
+		}
+		return conv.Metadata.ForkedFrom, conv.Metadata.ForkTurn
+	}
+	parentA, turnA := forkOf(a)
+	parentB, turnB := forkOf(b)
+
+	cmp := &BranchComparison{}
+	switch {
+	case parentB == idA:
+		cmp.ParentID, cmp.ForkTurn = idA, turnB
+		cmp.A, cmp.B = branchOutcome(a, turnB), branchOutcome(b, 0)
+	case parentA == idB:
+		cmp.ParentID, cmp.ForkTurn = idB, turnA
+		cmp.A, cmp.B = branchOutcome(a, 0), branchOutcome(b, turnA)
+	case parentA != "" && parentA == parentB:
+		cmp.ParentID, cmp.ForkTurn = parentA, turnA
+		if turnB < turnA {
+			cmp.ForkTurn = turnB
+		}
+		cmp.A, cmp.B = branchOutcome(a, 0), branchOutcome(b, 0)
+	default:
+		return nil, fmt.Errorf("conversations %s and %s are not branches of one another", idA, idB)
+	}
+	cmp.AnswerDiff = lineDiff(cmp.A.FinalAnswer, cmp.B.FinalAnswer)
+	return cmp, nil
+}
+
+// branchOutcome summarises conv, counting turns from turn from on. Its final
+// answer is the last it holds, so a parent that was not continued past the
+// fork is represented by its answer there.
+func branchOutcome(conv *LLMConversation, from int) BranchOutcome {
+	if from > len(conv.Turns) {
+		from = len(conv.Turns)
+	}
+	turns := conv.Turns[from:]
+	outcome := BranchOutcome{
+		ConversationID: conv.ConversationID,
+		TabID:          conv.TabID,
+		Turns:          len(turns),
+		Complete:       conv.Complete,
+		LastActivity:   conv.StartTime,
+	}
+	if !conv.EndTime.IsZero() {
+		outcome.LastActivity = conv.EndTime
+	}
+	for _, turn := range turns {
+		if turn.Timestamp.After(outcome.LastActivity) {
+			outcome.LastActivity = turn.Timestamp
+		}
+	}
+	for _, turn := range conv.Turns {
+		if turn.Role == "assistant" && strings.TrimSpace(turn.Content) != "" {
+			outcome.FinalAnswer = turn.Content
+		}
+	}
+	return outcome
+}
+
+// lineDiff renders the line changes from a to b, "- " for removed lines and
+// "+ " for added ones, with unchanged lines indented.
+func lineDiff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	linesA, linesB := diffLines(a), diffLines(b)
+
+	// Longest common subsequence, filled from the end
+	lcs := make([][]int, len(linesA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(linesB)+1)
+	}
+	for i := len(linesA) - 1; i >= 0; i-- {
+		for j := len(linesB) - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(linesA) || j < len(linesB) {
+		switch {
+		case i < len(linesA) && j < len(linesB) && linesA[i] == linesB[j]:
+			out.WriteString("  " + linesA[i] + "\n")
+			i, j = i+1, j+1
+		case i < len(linesA) && (j == len(linesB) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + linesA[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + linesB[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
+
+// diffLines splits text into lines for lineDiff, keeping the first
+// maxDiffLines.
+func diffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > maxDiffLines {
+		lines = append(lines[:maxDiffLines], fmt.Sprintf("... %d more lines", len(lines)-maxDiffLines))
+	}
+	return lines
+}
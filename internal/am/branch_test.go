@@ -0,0 +1,110 @@
+package am
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestForkAndCompareBranches(t *testing.T) {
+	amDir := t.TempDir()
+	defer WaitForPendingWrites()
+
+	parent := &LLMConversation{
+		ConversationID: "conv-1111111111",
+		TabID:          "tab-1",
+		Provider:       "claude",
+		StartTime:      time.Now(),
+		Complete:       true,
+		Metadata:       &ConversationMetadata{WorkingDirectory: "/src/app"},
+		Turns: []ConversationTurn{
+			{Role: "user", Content: "how should we cache results?"},
+			{Role: "assistant", Content: "Use an LRU cache."},
+			{Role: "user", Content: "what size?"},
+			{Role: "assistant", Content: "Start with 100 entries.\nMeasure hit rate."},
+		},
+	}
+	data, err := json.Marshal(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentPath := filepath.Join(amDir, (&LLMLogger{}).generateConversationFilename(parent))
+	if err := os.WriteFile(parentPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ForkConversation(amDir, "conv-missing", "tab-2"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist for a missing conversation, got %v", err)
+	}
+	fork, err := ForkConversation(amDir, parent.ConversationID, "tab-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork.ForkTurn != 4 || !strings.Contains(fork.RestorePrompt, "Start with 100 entries.") {
+		t.Errorf("Unexpected fork: %+v", fork)
+	}
+
+	// The next conversation in the tab becomes the fork
+	logger := GetLLMLogger("tab-2", amDir)
+	defer RemoveLLMLogger("tab-2")
+	childID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "what size, if memory is tight?"})
+	if childID == "" {
+		t.Fatal("Expected a conversation")
+	}
+	logger.mu.Lock()
+	child := logger.conversations[childID]
+	child.Turns = append(child.Turns, ConversationTurn{Role: "assistant", Content: "Start with 20 entries.\nMeasure hit rate."})
+	logger.mu.Unlock()
+	if child.Metadata.ForkedFrom != parent.ConversationID || child.Metadata.ForkTurn != 4 {
+		t.Errorf("Expected the child to record its parent, got %+v", child.Metadata)
+	}
+	if second := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude}); logger.conversations[second].Metadata.ForkedFrom != "" {
+		t.Error("Expected only the first conversation in the tab to be a fork")
+	}
+
+	// The original is left as it was
+	if after, _ := os.ReadFile(parentPath); string(after) != string(data) {
+		t.Error("Expected the parent conversation unchanged")
+	}
+
+	cmp, err := CompareBranches(amDir, parent.ConversationID, childID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmp.ParentID != parent.ConversationID || cmp.ForkTurn != 4 {
+		t.Errorf("Unexpected fork point: %+v", cmp)
+	}
+	if cmp.A.Turns != 0 || cmp.B.Turns != 2 || cmp.B.FinalAnswer != "Start with 20 entries.\nMeasure hit rate." {
+		t.Errorf("Unexpected outcomes: %+v, %+v", cmp.A, cmp.B)
+	}
+	wantDiff := "- Start with 100 entries.\n+ Start with 20 entries.\n  Measure hit rate.\n"
+	if cmp.AnswerDiff != wantDiff {
+		t.Errorf("AnswerDiff = %q, want %q", cmp.AnswerDiff, wantDiff)
+	}
+
+	if _, err := CompareBranches(amDir, parent.ConversationID, "conv-missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"same", "same", ""},
+		{"", "new", "+ new\n"},
+		{"old", "", "- old\n"},
+		{"a\nb\nc", "a\nc\nd", "  a\n- b\n  c\n+ d\n"},
+	}
+	for _, tt := range tests {
+		if got := lineDiff(tt.a, tt.b); got != tt.want {
+			t.Errorf("lineDiff(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
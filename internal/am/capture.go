@@ -6,6 +6,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
 )
 
 // CaptureState represents the current state of conversation capture.
@@ -268,17 +270,13 @@ func (c *ConversationCapture) Reset() {
 
 // --- Input Cleaning Functions ---
 
-// ANSI escape sequence pattern - comprehensive pattern for terminal escape codes
-// Matches: CSI sequences, OSC sequences (including rgb colors), DCS, PM, APC, and single-char escapes
-var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[PX^_][^\x1b]*\x1b\\|\x1b.`)
-
 // CleanUserInput processes raw PTY input into clean user prompt text.
 func CleanUserInput(raw string) string {
 	// Step 1: Apply backspace logic
 	result := applyBackspaces(raw)
 
 	// Step 2: Remove ANSI escape sequences
-	result = ansiPattern.ReplaceAllString(result, "")
+	result = ansi.Strip(result)
 
 	// Step 3: Remove control characters except newline/tab
 	result = removeControlChars(result)
@@ -339,8 +337,12 @@ func normalizeWhitespace(s string) string {
 
 // ParseAssistantOutput cleans assistant output and returns confidence score.
 func ParseAssistantOutput(raw string, provider string) (string, float64) {
+	// Step 0: Keep only the final state of progress bars and spinners, which
+	// is also what confidence is measured against
+	raw = ansi.CollapseOverwrites(raw)
+
 	// Step 1: Remove ANSI sequences
-	cleaned := ansiPattern.ReplaceAllString(raw, "")
+	cleaned := ansi.Strip(raw)
 
 	// Step 2: Remove common TUI artifacts
 	cleaned = removeTUIArtifacts(cleaned, provider)
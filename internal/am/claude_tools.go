@@ -0,0 +1,67 @@
+package am
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ToolUse is a tool an AI agent ran during a turn, as its TUI showed it.
+type ToolUse struct {
+	Name   string `json:"name"`             // "Read", "Edit", "Bash", ...
+	Target string `json:"target,omitempty"` // The path, command or pattern it was given
+	Status string `json:"status"`           // "done", "error", "interrupted", "running" or "pending"
+}
+
+// Claude Code shows each tool call as "⏺ Name(target)", followed by its
+// result on a "⎿" line and any lines indented under that.
+var claudeToolCall = regexp.MustCompile(`^[⏺●]\s*([A-Z][A-Za-z]*)\((.*)\)$`)
+
+const claudeToolResult = "⎿"
+
+// extractClaudeToolUses takes Claude Code's tool blocks out of screen text,
+// returning the text left and the tools in the order they ran.
+func extractClaudeToolUses(content string) (string, []ToolUse) {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	var tools []ToolUse
+	for i := 0; i < len(lines); i++ {
+		m := claudeToolCall.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if m == nil {
+			kept = append(kept, lines[i])
+			continue
+		}
+		tool := ToolUse{Name: m[1], Target: strings.TrimSpace(m[2]), Status: "pending"}
+		for i+1 < len(lines) {
+			next := lines[i+1]
+			trimmed := strings.TrimSpace(next)
+			if result, ok := strings.CutPrefix(trimmed, claudeToolResult); ok && tool.Status == "pending" {
+				tool.Status = claudeToolStatus(strings.TrimSpace(result))
+				i++
+				continue
+			}
+			// Output indented under the result belongs to the tool
+			if tool.Status != "pending" && trimmed != "" && next != trimmed && !claudeToolCall.MatchString(trimmed) {
+				i++
+				continue
+			}
+			break
+		}
+		tools = append(tools, tool)
+	}
+	return strings.Join(kept, "\n"), tools
+}
+
+// claudeToolStatus reads a tool's status from the first line of its result.
+func claudeToolStatus(result string) string {
+	lower := strings.ToLower(result)
+	switch {
+	case strings.HasPrefix(lower, "error"):
+		return "error"
+	case strings.Contains(lower, "interrupted"):
+		return "interrupted"
+	case strings.HasPrefix(lower, "running"), strings.HasPrefix(lower, "waiting"):
+		return "running"
+	default:
+		return "done"
+	}
+}
@@ -0,0 +1,69 @@
+package am
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestExtractClaudeToolUses(t *testing.T) {
+	screen := strings.Join([]string{
+		"> fix the failing test",
+		"",
+		"⏺ Read(internal/am/llm_logger.go)",
+		"  ⎿  Read 1700 lines (ctrl+r to expand)",
+		"",
+		"⏺ Update(internal/am/llm_logger.go)",
+		"  ⎿  Updated internal/am/llm_logger.go with 2 additions",
+		"       12 +  if conv == nil {",
+		"       13 +      return",
+		"⏺ Bash(go test ./internal/am)",
+		"  ⎿  Error: exit status 1",
+		"     --- FAIL: TestUsage",
+		"",
+		"⏺ Bash(go vet ./...)",
+		"",
+		"⏺ The nil check was missing; the test passes now.",
+	}, "\n")
+
+	text, tools := extractClaudeToolUses(screen)
+	want := []ToolUse{
+		{Name: "Read", Target: "internal/am/llm_logger.go", Status: "done"},
+		{Name: "Update", Target: "internal/am/llm_logger.go", Status: "done"},
+		{Name: "Bash", Target: "go test ./internal/am", Status: "error"},
+		{Name: "Bash", Target: "go vet ./...", Status: "pending"},
+	}
+	if !reflect.DeepEqual(tools, want) {
+		t.Errorf("tools = %+v\nwant %+v", tools, want)
+	}
+	for _, gone := range []string{"Read(", "ctrl+r", "return", "FAIL"} {
+		if strings.Contains(text, gone) {
+			t.Errorf("Expected %q removed from the text:\n%s", gone, text)
+		}
+	}
+	for _, kept := range []string{"> fix the failing test", "The nil check was missing"} {
+		if !strings.Contains(text, kept) {
+			t.Errorf("Expected %q kept in the text:\n%s", kept, text)
+		}
+	}
+}
+
+func TestFlushOutputAnnotatesClaudeTools(t *testing.T) {
+	logger := &LLMLogger{tabID: "claude-tools", conversations: make(map[string]*LLMConversation), amDir: t.TempDir()}
+	defer WaitForPendingWrites()
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "list the files"})
+
+	logger.AddOutput("⏺ Bash(ls)\r\n  ⎿  go.mod\r\n     main.go\r\n\r\nThere are two files in the directory: go.mod and main.go.\r\n")
+	logger.FlushOutput()
+
+	turns := logger.GetConversation(convID).Turns
+	last := turns[len(turns)-1]
+	if last.Role != "assistant" || len(last.Tools) != 1 || last.Tools[0] != (ToolUse{Name: "Bash", Target: "ls", Status: "done"}) {
+		t.Fatalf("Expected the Bash call as an annotation, got %+v", last)
+	}
+	if strings.Contains(last.Content, "Bash(ls)") || !strings.Contains(last.Content, "two files") {
+		t.Errorf("Unexpected content: %q", last.Content)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 // RestoreContext represents all information needed to restore a conversation.
@@ -189,6 +191,9 @@ func (cb *ContextBuilder) buildFullContext(conv *LLMConversation) string {
 		if conv.Metadata.GitBranch != "" {
 			sb.WriteString(fmt.Sprintf("Git Branch: %s\n", conv.Metadata.GitBranch))
 		}
+		if env := conv.Metadata.Environment; env != nil {
+			sb.WriteString(fmt.Sprintf("Environment: %s (as of %s)\n", env.Summary, env.CheckedAt.Format(time.RFC3339)))
+		}
 	}
 
 	sb.WriteString(fmt.Sprintf("Session Start: %s\n", conv.StartTime.Format(time.RFC3339)))
@@ -216,8 +221,8 @@ func (cb *ContextBuilder) buildFullContext(conv *LLMConversation) string {
 	return sb.String()
 }
 
-// GetRecoverableSessions finds all sessions that can be recovered.
-func (cb *ContextBuilder) GetRecoverableSessions() ([]*RecoverableSession, error) {
+// conversationFiles lists the conversation logs in the AM directory.
+func (cb *ContextBuilder) conversationFiles() map[string]bool {
 	// Support both new and legacy file patterns
 	patterns := []string{
 		filepath.Join(cb.amDir, "*-conv-*.json"),     // New format
@@ -234,6 +239,12 @@ func (cb *ContextBuilder) GetRecoverableSessions() ([]*RecoverableSession, error
 			allFiles[file] = true
 		}
 	}
+	return allFiles
+}
+
+// GetRecoverableSessions finds all sessions that can be recovered.
+func (cb *ContextBuilder) GetRecoverableSessions() ([]*RecoverableSession, error) {
+	allFiles := cb.conversationFiles()
 
 	var sessions []*RecoverableSession
 
@@ -273,6 +284,23 @@ func (cb *ContextBuilder) GetRecoverableSessions() ([]*RecoverableSession, error
 	return sessions, nil
 }
 
+// LatestSession returns the context of the most recently active
+// conversation, finished or not, or nil if there are none.
+func (cb *ContextBuilder) LatestSession() *RestoreContext {
+	var latest *RestoreContext
+	for file := range cb.conversationFiles() {
+		conv, err := cb.loadConversation(file)
+		if err != nil {
+			continue
+		}
+		ctx := cb.BuildRestoreContext(conv)
+		if ctx != nil && (latest == nil || ctx.LastActivity.After(latest.LastActivity)) {
+			latest = ctx
+		}
+	}
+	return latest
+}
+
 // GetRestoreContextByID retrieves restore context for a specific conversation.
 func (cb *ContextBuilder) GetRestoreContextByID(conversationID string) (*RestoreContext, error) {
 	// Find the conversation file
@@ -338,7 +366,7 @@ func (cb *ContextBuilder) MarkAsRestored(conversationID string) error {
 				return err
 			}
 
-			return os.WriteFile(session.FilePath, data, 0644)
+			return storage.WriteFileAtomic(session.FilePath, data, 0644)
 		}
 	}
 
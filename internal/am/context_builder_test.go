@@ -195,6 +195,35 @@ func TestContextBuilder_GetRecoverableSessions(t *testing.T) {
 	}
 }
 
+func TestContextBuilder_LatestSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	cb := NewContextBuilder(tmpDir)
+	if cb.LatestSession() != nil {
+		t.Error("Expected no session in an empty directory")
+	}
+
+	older := &LLMConversation{
+		ConversationID: "conv-older",
+		Provider:       "claude",
+		StartTime:      time.Now().Add(-time.Hour),
+		Turns:          []ConversationTurn{{Role: "user", Content: "old", Timestamp: time.Now().Add(-time.Hour)}},
+	}
+	newer := &LLMConversation{
+		ConversationID: "conv-newer",
+		Provider:       "github-copilot",
+		StartTime:      time.Now().Add(-5 * time.Minute),
+		Complete:       true,
+		Turns:          []ConversationTurn{{Role: "user", Content: "new", Timestamp: time.Now().Add(-time.Minute)}},
+	}
+	writeConv(t, tmpDir, "llm-conv-tab-1-conv-older.json", older)
+	writeConv(t, tmpDir, "llm-conv-tab-2-conv-newer.json", newer)
+
+	latest := cb.LatestSession()
+	if latest == nil || latest.ConversationID != "conv-newer" || latest.LastUserPrompt != "new" {
+		t.Errorf("Expected the most recent conversation, got %+v", latest)
+	}
+}
+
 func TestContextBuilder_MarkAsRestored(t *testing.T) {
 	// Create temp directory
 	tmpDir, err := os.MkdirTemp("", "am-test-*")
@@ -0,0 +1,255 @@
+package am
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// eraseTokenTTL is how long a workspace erase confirmation token stays valid
+const eraseTokenTTL = 5 * time.Minute
+
+var errBadEraseToken = errors.New("confirmation token is invalid or expired; request a new one")
+
+// ErasePlan lists what erasing a workspace removes. Token, with ExpiresAt,
+// is only set on the preview and must be passed back to EraseWorkspace.
+type ErasePlan struct {
+	Workspace     string     `json:"workspace"`
+	Conversations []string   `json:"conversations"`
+	SessionLogs   []string   `json:"sessionLogs"`
+	Token         string     `json:"confirmToken,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+}
+
+var (
+	eraseTokensMu sync.Mutex
+	eraseTokens   = map[string]eraseToken{}
+)
+
+// eraseToken is a pending confirmation for erasing one workspace
+type eraseToken struct {
+	workspace string
+	expires   time.Time
+}
+
+// conversationFiles returns the conversations saved in amDir by file path.
+func conversationFiles(amDir string) map[string]*LLMConversation {
+	files := map[string]*LLMConversation{}
+	for _, pattern := range []string{"*-conv-*.json", "llm-conv-*.json"} {
+		matches, _ := filepath.Glob(filepath.Join(amDir, pattern))
+		for _, path := range matches {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var conv LLMConversation
+			if json.Unmarshal(data, &conv) == nil {
+				files[path] = &conv
+			}
+		}
+	}
+	return files
+}
+
+// forgetConversations drops conversations from the open tabs' loggers, ending
+// capture of any that are active.
+func forgetConversations(ids map[string]bool) {
+	llmLoggersMu.RLock()
+	defer llmLoggersMu.RUnlock()
+	for _, logger := range llmLoggers {
+		logger.mu.Lock()
+		for id := range ids {
+			delete(logger.conversations, id)
+			if logger.activeConvID == id {
+				logger.activeConvID = ""
+				logger.outputBuffer, logger.inputBuffer = "", ""
+				logger.currentScreen.Reset()
+			}
+		}
+		logger.mu.Unlock()
+	}
+}
+
+// DeleteConversation removes a captured conversation from disk and memory.
+// The error wraps os.ErrNotExist when there is no such conversation.
+func DeleteConversation(amDir, convID string) error {
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	found := false
+	for path, conv := range conversationFiles(amDir) {
+		if conv.ConversationID != convID {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		os.Remove(path + storage.BackupSuffix)
+		found = true
+	}
+
+	llmLoggersMu.RLock()
+	for _, logger := range llmLoggers {
+		logger.mu.Lock()
+		_, ok := logger.conversations[convID]
+		logger.mu.Unlock()
+		found = found || ok
+	}
+	llmLoggersMu.RUnlock()
+	if !found {
+		return fmt.Errorf("conversation %s: %w", convID, os.ErrNotExist)
+	}
+	forgetConversations(map[string]bool{convID: true})
+	return nil
+}
+
+// sessionLogFiles returns the session logs, live and archived, for which
+// match returns true given the file name and its parsed contents.
+func sessionLogFiles(match func(name string, log *SessionLog) bool) []string {
+	paths := []string{}
+	for _, dir := range []string{GetAMDir(), GetArchiveDir()} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			content, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			sessionLog, _ := parseSessionLogContent(string(content))
+			if sessionLog == nil {
+				sessionLog = &SessionLog{}
+			}
+			if match(entry.Name(), sessionLog) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// DeleteSessionLog removes a tab's session logs, including archived ones.
+// The error wraps os.ErrNotExist when the tab has none.
+func DeleteSessionLog(tabID string) error {
+	if tabID == "" {
+		return fmt.Errorf("tab ID required")
+	}
+	paths := sessionLogFiles(func(name string, _ *SessionLog) bool {
+		return strings.Contains(name, tabID)
+	})
+	if len(paths) == 0 {
+		return fmt.Errorf("log for tab %s: %w", tabID, os.ErrNotExist)
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inWorkspace reports whether dir belongs to workspace: a directory path, or
+// a project name as conversations are filed under.
+func inWorkspace(dir, workspace string) bool {
+	if dir == "" {
+		return false
+	}
+	if filepath.IsAbs(workspace) {
+		dir, workspace = filepath.Clean(dir), filepath.Clean(workspace)
+		return dir == workspace || strings.HasPrefix(dir, strings.TrimSuffix(workspace, string(filepath.Separator))+string(filepath.Separator))
+	}
+	if filepath.IsAbs(dir) && detectProject(dir) == workspace {
+		return true
+	}
+	return extractWorkspaceName(normalizePath(dir), "") == workspace
+}
+
+// planErase finds everything AM holds for workspace.
+func planErase(amDir, workspace string) (*ErasePlan, map[string]bool) {
+	plan := &ErasePlan{Workspace: workspace, Conversations: []string{}, SessionLogs: []string{}}
+	ids := map[string]bool{}
+	for path, conv := range conversationFiles(amDir) {
+		if conv.Metadata != nil && inWorkspace(conv.Metadata.WorkingDirectory, workspace) {
+			plan.Conversations = append(plan.Conversations, path)
+			ids[conv.ConversationID] = true
+		}
+	}
+	plan.SessionLogs = sessionLogFiles(func(name string, log *SessionLog) bool {
+		return inWorkspace(log.Workspace, workspace)
+	})
+	return plan, ids
+}
+
+// PreviewWorkspaceErase lists what EraseWorkspace would remove for workspace
+// and issues the confirmation token it requires.
+func PreviewWorkspaceErase(amDir, workspace string) (*ErasePlan, error) {
+	if strings.TrimSpace(workspace) == "" {
+		return nil, fmt.Errorf("workspace required")
+	}
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	plan, _ := planErase(amDir, workspace)
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	plan.Token = hex.EncodeToString(buf)
+	expires := time.Now().Add(eraseTokenTTL)
+	plan.ExpiresAt = &expires
+
+	eraseTokensMu.Lock()
+	defer eraseTokensMu.Unlock()
+	for token, pending := range eraseTokens {
+		if time.Now().After(pending.expires) {
+			delete(eraseTokens, token)
+		}
+	}
+	eraseTokens[plan.Token] = eraseToken{workspace: workspace, expires: expires}
+	return plan, nil
+}
+
+// EraseWorkspace removes every conversation and session log AM holds for
+// workspace, given a token from PreviewWorkspaceErase for the same workspace.
+// Each token works once.
+func EraseWorkspace(amDir, workspace, token string) (*ErasePlan, error) {
+	eraseTokensMu.Lock()
+	pending, ok := eraseTokens[token]
+	delete(eraseTokens, token)
+	eraseTokensMu.Unlock()
+	if !ok || pending.workspace != workspace || time.Now().After(pending.expires) {
+		return nil, errBadEraseToken
+	}
+
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	plan, ids := planErase(amDir, workspace)
+	forgetConversations(ids)
+	for _, path := range plan.Conversations {
+		if err := os.Remove(path + storage.BackupSuffix); err != nil && !os.IsNotExist(err) {
+			return plan, err
+		}
+	}
+	for _, path := range append(append([]string{}, plan.Conversations...), plan.SessionLogs...) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
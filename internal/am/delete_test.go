@@ -0,0 +1,97 @@
+package am
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// writeConversation saves a conversation in amDir as the logger would.
+func writeConversation(t *testing.T, amDir, convID, workingDir string) string {
+	t.Helper()
+	conv := &LLMConversation{
+		ConversationID: convID,
+		TabID:          "tab-1",
+		StartTime:      time.Now(),
+		Metadata:       &ConversationMetadata{WorkingDirectory: workingDir},
+	}
+	data, err := json.Marshal(conv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(amDir, (&LLMLogger{}).generateConversationFilename(conv))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDeleteConversation(t *testing.T) {
+	amDir := t.TempDir()
+	kept := writeConversation(t, amDir, "conv-1111111111", "/src/app")
+	deleted := writeConversation(t, amDir, "conv-2222222222", "/src/app")
+	if err := os.WriteFile(deleted+storage.BackupSuffix, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteConversation(amDir, "conv-2222222222"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{deleted, deleted + storage.BackupSuffix} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s removed, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("Expected %s kept: %v", kept, err)
+	}
+	if err := DeleteConversation(amDir, "conv-2222222222"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected not found deleting it again, got %v", err)
+	}
+}
+
+func TestEraseWorkspace(t *testing.T) {
+	amDir := t.TempDir()
+	root := t.TempDir()
+	secret := filepath.Join(root, "secret")
+	inside := writeConversation(t, amDir, "conv-3333333333", filepath.Join(secret, "api"))
+	outside := writeConversation(t, amDir, "conv-4444444444", filepath.Join(root, "secret-other"))
+
+	if _, err := EraseWorkspace(amDir, secret, "made-up"); err == nil {
+		t.Error("Expected erasing without a token from a preview to fail")
+	}
+
+	plan, err := PreviewWorkspaceErase(amDir, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Conversations) != 1 || plan.Conversations[0] != inside || plan.Token == "" {
+		t.Fatalf("Expected a preview of %s with a token, got %+v", inside, plan)
+	}
+	if _, err := os.Stat(inside); err != nil {
+		t.Fatalf("Expected the preview to remove nothing: %v", err)
+	}
+	if _, err := EraseWorkspace(amDir, filepath.Join(root, "secret-other"), plan.Token); err == nil {
+		t.Error("Expected the token to be refused for another workspace")
+	}
+
+	// A refused token is spent too
+	plan, _ = PreviewWorkspaceErase(amDir, secret)
+	if _, err := EraseWorkspace(amDir, secret, plan.Token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(inside); !os.IsNotExist(err) {
+		t.Errorf("Expected %s erased, got %v", inside, err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected %s kept: %v", outside, err)
+	}
+	if _, err := EraseWorkspace(amDir, secret, plan.Token); err == nil {
+		t.Error("Expected a token to work only once")
+	}
+}
@@ -0,0 +1,328 @@
+package am
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/git"
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+	"github.com/mikejsmith1985/forge-terminal/internal/terminal/vision"
+	"github.com/mikejsmith1985/forge-terminal/internal/workspaces"
+)
+
+// digestDateFormat names the day a digest covers, in local time
+const digestDateFormat = "2006-01-02"
+
+// Digest summarises one day of activity in a workspace.
+type Digest struct {
+	Date          string               `json:"date"`
+	Workspace     string               `json:"workspace"` // Workspace root ("" = outside any workspace)
+	Commands      []history.Entry      `json:"commands"`
+	Conversations []DigestConversation `json:"conversations"`
+	FilesChanged  []string             `json:"filesChanged"` // Committed that day, relative to the root
+	Errors        []DigestError        `json:"errors"`
+}
+
+// DigestConversation is an AI conversation held during the day.
+type DigestConversation struct {
+	ID       string    `json:"id"`
+	Provider string    `json:"provider"`
+	Started  time.Time `json:"started"`
+	Turns    int       `json:"turns"`
+	Prompt   string    `json:"prompt,omitempty"` // The first thing the user asked
+}
+
+// DigestError is an error Vision spotted in a tab during the day.
+type DigestError struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+	Command string    `json:"command,omitempty"`
+}
+
+// DigestFile is a saved digest.
+type DigestFile struct {
+	Name    string `json:"name"`
+	Date    string `json:"date"`
+	Project string `json:"project"`
+}
+
+// Digest sources; overridden in tests
+var (
+	digestCommands     = history.UsedBetween
+	digestWorkspaceFor = history.WorkspaceFor
+	digestWorkspaces   = func() []string {
+		list, err := workspaces.List()
+		if err != nil {
+			return nil
+		}
+		roots := make([]string, 0, len(list))
+		for _, ws := range list {
+			roots = append(roots, ws.Path)
+		}
+		return roots
+	}
+	digestChangedFiles = git.ChangedFiles
+)
+
+// DigestsDir returns the directory daily digests are saved in.
+func DigestsDir(amDir string) string {
+	return filepath.Join(amDir, "digests")
+}
+
+// isEmpty reports whether nothing happened in the digest's workspace.
+func (d *Digest) isEmpty() bool {
+	return len(d.Commands) == 0 && len(d.Conversations) == 0 && len(d.FilesChanged) == 0 && len(d.Errors) == 0
+}
+
+// project names the digest's workspace in file names and headings.
+func (d *Digest) project() string {
+	if d.Workspace == "" {
+		return "other"
+	}
+	return sanitizeProjectName(filepath.Base(d.Workspace))
+}
+
+// BuildDigests summarises the day containing day, one digest per workspace
+// with any activity. Private workspaces (see IsPrivate) are left out.
+func BuildDigests(amDir string, day time.Time) ([]*Digest, error) {
+	since := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	until := since.AddDate(0, 0, 1)
+	date := since.Format(digestDateFormat)
+
+	byWorkspace := map[string]*Digest{}
+	digestFor := func(workspace string) *Digest {
+		d := byWorkspace[workspace]
+		if d == nil {
+			d = &Digest{Date: date, Workspace: workspace, Commands: []history.Entry{}, Conversations: []DigestConversation{}, FilesChanged: []string{}, Errors: []DigestError{}}
+			byWorkspace[workspace] = d
+		}
+		return d
+	}
+
+	commands, err := digestCommands(since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range commands {
+		d := digestFor(entry.Workspace)
+		d.Commands = append(d.Commands, entry)
+	}
+
+	for _, conv := range conversationFiles(amDir) {
+		if conv.StartTime.Before(since) || !conv.StartTime.Before(until) {
+			continue
+		}
+		dir := ""
+		if conv.Metadata != nil {
+			dir = conv.Metadata.WorkingDirectory
+		}
+		summary := DigestConversation{ID: conv.ConversationID, Provider: conv.Provider, Started: conv.StartTime, Turns: len(conv.Turns)}
+		for _, turn := range conv.Turns {
+			if turn.Role == "user" {
+				summary.Prompt = truncateForLog(strings.Join(strings.Fields(turn.Content), " "), 120)
+				break
+			}
+		}
+		d := digestFor(digestWorkspaceFor(dir))
+		d.Conversations = append(d.Conversations, summary)
+	}
+
+	insightFiles, _ := filepath.Glob(filepath.Join(amDir, "vision-insights-*.json"))
+	for _, path := range insightFiles {
+		tabID := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "vision-insights-"), ".json")
+		insights, err := vision.LoadInsights(amDir, tabID)
+		if err != nil {
+			continue
+		}
+		for _, insight := range insights {
+			if insight.Severity != vision.SeverityError && insight.Severity != vision.SeverityCritical {
+				continue
+			}
+			if insight.Timestamp.Before(since) || !insight.Timestamp.Before(until) || IsPrivate(insight.SessionInfo.WorkingDir) {
+				continue
+			}
+			d := digestFor(digestWorkspaceFor(insight.SessionInfo.WorkingDir))
+			d.Errors = append(d.Errors, DigestError{
+				Time:    insight.Timestamp,
+				Type:    insight.Type,
+				Message: insight.Message,
+				Command: insight.SessionInfo.CommandLine,
+			})
+		}
+	}
+
+	for _, root := range digestWorkspaces() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		files, err := digestChangedFiles(ctx, root, since, until)
+		cancel()
+		if err == nil && len(files) > 0 {
+			digestFor(root).FilesChanged = files
+		}
+	}
+
+	digests := []*Digest{}
+	for workspace, d := range byWorkspace {
+		if d.isEmpty() || (workspace != "" && IsPrivate(workspace)) {
+			continue
+		}
+		sort.Slice(d.Conversations, func(i, j int) bool { return d.Conversations[i].Started.Before(d.Conversations[j].Started) })
+		sort.Slice(d.Errors, func(i, j int) bool { return d.Errors[i].Time.Before(d.Errors[j].Time) })
+		digests = append(digests, d)
+	}
+	sort.Slice(digests, func(i, j int) bool { return digests[i].Workspace < digests[j].Workspace })
+	return digests, nil
+}
+
+// Markdown renders the digest as a markdown document.
+func (d *Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", d.Date, d.project())
+	if d.Workspace != "" {
+		fmt.Fprintf(&b, "Workspace: `%s`\n\n", d.Workspace)
+	} else {
+		b.WriteString("Activity outside any workspace.\n\n")
+	}
+
+	fmt.Fprintf(&b, "## Commands run (%d)\n\n", len(d.Commands))
+	for _, entry := range d.Commands {
+		fmt.Fprintf(&b, "- %s `%s`\n", entry.LastUsed.Format("15:04"), entry.Command)
+	}
+	if len(d.Commands) == 0 {
+		b.WriteString("None.\n")
+	}
+
+	fmt.Fprintf(&b, "\n## Conversations (%d)\n\n", len(d.Conversations))
+	for _, conv := range d.Conversations {
+		fmt.Fprintf(&b, "- %s %s, %d turns", conv.Started.Format("15:04"), conv.Provider, conv.Turns)
+		if conv.Prompt != "" {
+			fmt.Fprintf(&b, ": %q", conv.Prompt)
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Conversations) == 0 {
+		b.WriteString("None.\n")
+	}
+
+	fmt.Fprintf(&b, "\n## Files changed (%d)\n\n", len(d.FilesChanged))
+	for _, file := range d.FilesChanged {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+	if len(d.FilesChanged) == 0 {
+		b.WriteString("None committed.\n")
+	}
+
+	fmt.Fprintf(&b, "\n## Errors seen (%d)\n\n", len(d.Errors))
+	for _, e := range d.Errors {
+		fmt.Fprintf(&b, "- %s %s: %s", e.Time.Format("15:04"), e.Type, e.Message)
+		if e.Command != "" {
+			fmt.Fprintf(&b, " (`%s`)", e.Command)
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Errors) == 0 {
+		b.WriteString("None.\n")
+	}
+	return b.String()
+}
+
+// WriteDigests saves the digests for the day containing day as markdown,
+// replacing any saved before, and returns their names.
+func WriteDigests(amDir string, day time.Time) ([]string, error) {
+	digests, err := BuildDigests(amDir, day)
+	if err != nil {
+		return nil, err
+	}
+	dir := DigestsDir(amDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, d := range digests {
+		name := d.Date + "-" + d.project() + ".md"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(redact(d.Markdown())), 0600); err != nil {
+			return names, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ListDigests returns the saved digests, newest first.
+func ListDigests(amDir string) ([]DigestFile, error) {
+	entries, err := os.ReadDir(DigestsDir(amDir))
+	if os.IsNotExist(err) {
+		return []DigestFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := []DigestFile{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".md") || len(name) <= len(digestDateFormat)+1 {
+			continue
+		}
+		date := name[:len(digestDateFormat)]
+		if _, err := time.Parse(digestDateFormat, date); err != nil {
+			continue
+		}
+		files = append(files, DigestFile{Name: name, Date: date, Project: strings.TrimSuffix(name[len(date)+1:], ".md")})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Date != files[j].Date {
+			return files[i].Date > files[j].Date
+		}
+		return files[i].Project < files[j].Project
+	})
+	return files, nil
+}
+
+// ReadDigest returns a saved digest's markdown.
+func ReadDigest(amDir, name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || !strings.HasSuffix(name, ".md") {
+		return "", fmt.Errorf("invalid digest name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(DigestsDir(amDir), name))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ScheduleDigests writes each day's digests at five past midnight, and on
+// start writes yesterday's if they are missing. It runs until the process
+// exits.
+func ScheduleDigests(amDir string) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	if !hasDigests(amDir, yesterday) {
+		writeScheduledDigests(amDir, yesterday)
+	}
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 5, 0, 0, now.Location())
+		time.Sleep(time.Until(next))
+		writeScheduledDigests(amDir, next.AddDate(0, 0, -1))
+	}
+}
+
+// hasDigests reports whether digests were saved for the day containing day.
+func hasDigests(amDir string, day time.Time) bool {
+	matches, _ := filepath.Glob(filepath.Join(DigestsDir(amDir), day.Format(digestDateFormat)+"-*.md"))
+	return len(matches) > 0
+}
+
+func writeScheduledDigests(amDir string, day time.Time) {
+	names, err := WriteDigests(amDir, day)
+	if err != nil {
+		log.Printf("[AM] Failed to write digests for %s: %v", day.Format(digestDateFormat), err)
+		return
+	}
+	log.Printf("[AM] Wrote %d digest(s) for %s", len(names), day.Format(digestDateFormat))
+}
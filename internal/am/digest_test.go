@@ -0,0 +1,119 @@
+package am
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+	"github.com/mikejsmith1985/forge-terminal/internal/terminal/vision"
+)
+
+func TestDigests(t *testing.T) {
+	amDir := t.TempDir()
+	workspace := t.TempDir()
+	now := time.Now()
+
+	origCommands, origWorkspaceFor, origWorkspaces, origChanged := digestCommands, digestWorkspaceFor, digestWorkspaces, digestChangedFiles
+	defer func() {
+		digestCommands, digestWorkspaceFor, digestWorkspaces, digestChangedFiles = origCommands, origWorkspaceFor, origWorkspaces, origChanged
+	}()
+	digestCommands = func(since, until time.Time) ([]history.Entry, error) {
+		return []history.Entry{
+			{Command: "go test ./...", Workspace: workspace, LastUsed: now},
+			{Command: "ls", LastUsed: now},
+		}, nil
+	}
+	digestWorkspaceFor = func(dir string) string {
+		if inWorkspace(dir, workspace) {
+			return workspace
+		}
+		return ""
+	}
+	digestWorkspaces = func() []string { return []string{workspace} }
+	digestChangedFiles = func(ctx context.Context, dir string, since, until time.Time) ([]string, error) {
+		return []string{"main.go"}, nil
+	}
+
+	writeConversation(t, amDir, "conv-1111111111", filepath.Join(workspace, "src"))
+	insights := []*vision.Insight{
+		{Timestamp: now, Type: "COMPILER_ERROR", Severity: vision.SeverityError, Message: "undefined: foo", SessionInfo: vision.SessionInfo{WorkingDir: workspace, CommandLine: "go build"}},
+		{Timestamp: now, Type: "HINT", Severity: vision.SeverityInfo, Message: "not an error", SessionInfo: vision.SessionInfo{WorkingDir: workspace}},
+		{Timestamp: now.AddDate(0, 0, -2), Type: "COMPILER_ERROR", Severity: vision.SeverityError, Message: "old", SessionInfo: vision.SessionInfo{WorkingDir: workspace}},
+	}
+	data, err := json.Marshal(insights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(amDir, "vision-insights-tab-1.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	digests, err := BuildDigests(amDir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digests) != 2 {
+		t.Fatalf("Expected digests for the workspace and outside it, got %d", len(digests))
+	}
+	other, ws := digests[0], digests[1]
+	if other.Workspace != "" || len(other.Commands) != 1 || other.Commands[0].Command != "ls" {
+		t.Errorf("Unexpected digest outside workspaces: %+v", other)
+	}
+	if len(ws.Commands) != 1 || len(ws.Conversations) != 1 || len(ws.FilesChanged) != 1 {
+		t.Errorf("Unexpected workspace digest: %+v", ws)
+	}
+	if len(ws.Errors) != 1 || ws.Errors[0].Message != "undefined: foo" {
+		t.Errorf("Expected only today's error, got %+v", ws.Errors)
+	}
+
+	names, err := WriteDigests(amDir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := now.Format(digestDateFormat)
+	wantName := date + "-" + sanitizeProjectName(filepath.Base(workspace)) + ".md"
+	if len(names) != 2 || names[1] != wantName {
+		t.Fatalf("Unexpected digest names: %v", names)
+	}
+	if !hasDigests(amDir, now) {
+		t.Error("Expected digests for today")
+	}
+
+	list, err := ListDigests(amDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Date != date {
+		t.Errorf("Unexpected digest list: %+v", list)
+	}
+
+	content, err := ReadDigest(amDir, wantName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"go test ./...", "main.go", "undefined: foo", "## Conversations (1)"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Digest missing %q:\n%s", want, content)
+		}
+	}
+	if _, err := ReadDigest(amDir, "../secrets.md"); err == nil {
+		t.Error("Expected an error for a name outside the digests directory")
+	}
+
+	// Private workspaces get no digest
+	if err := os.WriteFile(filepath.Join(workspace, IgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	digests, err = BuildDigests(amDir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digests) != 1 || digests[0].Workspace != "" {
+		t.Errorf("Expected only the digest outside workspaces, got %d", len(digests))
+	}
+}
@@ -0,0 +1,247 @@
+package am
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/git"
+)
+
+// environmentTimeout bounds the commands run for one environment snapshot
+const environmentTimeout = 10 * time.Second
+
+// composeFiles are the names Docker Compose looks for, in its order
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// EnvironmentSnapshot records the state of a conversation's project: what a
+// recovery needs to know to pick up where the work was interrupted.
+type EnvironmentSnapshot struct {
+	Directory     string           `json:"directory"`
+	GitBranch     string           `json:"gitBranch,omitempty"` // Empty outside a repository
+	DirtyFiles    int              `json:"dirtyFiles"`          // Uncommitted and untracked files
+	Virtualenv    string           `json:"virtualenv,omitempty"`
+	PythonVersion string           `json:"pythonVersion,omitempty"` // The virtualenv's
+	NodeVersion   string           `json:"nodeVersion,omitempty"`   // Only for Node projects
+	Compose       []ComposeService `json:"compose,omitempty"`
+	Summary       string           `json:"summary"`    // One line for the recovery card
+	CapturedAt    time.Time        `json:"capturedAt"` // When this state was first seen
+	CheckedAt     time.Time        `json:"checkedAt"`  // When it was last found unchanged
+}
+
+// ComposeService is one service of the project's Docker Compose stack.
+type ComposeService struct {
+	Service string `json:"service"`
+	State   string `json:"state"` // "running", "exited", ...
+}
+
+// Commands run for environment snapshots; overridden in tests
+var (
+	nodeVersion = func(ctx context.Context, dir string) (string, error) {
+		cmd := exec.CommandContext(ctx, "node", "--version")
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	composeServices = func(ctx context.Context, dir string) ([]ComposeService, error) {
+		cmd := exec.CommandContext(ctx, "docker", "compose", "ps", "--all", "--format", "json")
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+		return parseComposePS(out)
+	}
+)
+
+// RecordEnvironment snapshots the project in dir (the tab's starting
+// directory if dir is empty) into the active conversation's metadata. A
+// changed state is saved straight away; an unchanged one is only marked as
+// checked. It does nothing outside a conversation or while capture is off.
+func (l *LLMLogger) RecordEnvironment(dir string) {
+	l.mu.Lock()
+	convID := l.activeConvID
+	if dir == "" {
+		dir = l.workingDir
+	}
+	skip := convID == "" || dir == "" || l.captureOff
+	l.mu.Unlock()
+	if skip || IsPaused() {
+		return
+	}
+
+	// The commands run without the lock, so output keeps flowing meanwhile
+	ctx, cancel := context.WithTimeout(context.Background(), environmentTimeout)
+	defer cancel()
+	snapshot := captureEnvironment(ctx, dir)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	conv := l.conversations[convID]
+	if conv == nil || l.activeConvID != convID {
+		return
+	}
+	metadata := ConversationMetadata{}
+	if conv.Metadata != nil {
+		metadata = *conv.Metadata
+	}
+	changed := metadata.Environment == nil || !metadata.Environment.sameState(snapshot)
+	if !changed {
+		snapshot.CapturedAt = metadata.Environment.CapturedAt
+	}
+	metadata.Environment = snapshot
+	conv.Metadata = &metadata
+	if changed {
+		l.saveConversation(conv)
+	}
+}
+
+// captureEnvironment inspects the project in dir. Facts that can't be
+// found, such as a branch outside a repository, are left out.
+func captureEnvironment(ctx context.Context, dir string) *EnvironmentSnapshot {
+	now := time.Now()
+	snapshot := &EnvironmentSnapshot{Directory: dir, CapturedAt: now, CheckedAt: now}
+
+	// The project's files are looked for in dir and at the repository root
+	roots := []string{dir}
+	if status, err := git.GetStatus(ctx, dir); err == nil {
+		snapshot.GitBranch = status.Branch
+		snapshot.DirtyFiles = len(status.Files)
+		if status.Root != dir {
+			roots = append(roots, status.Root)
+		}
+	}
+
+	if venv, version := findVirtualenv(roots); venv != "" {
+		snapshot.Virtualenv, snapshot.PythonVersion = venv, version
+	}
+	if root := findFile(roots, "package.json"); root != "" {
+		if version, err := nodeVersion(ctx, root); err == nil {
+			snapshot.NodeVersion = version
+		}
+	}
+	for _, name := range composeFiles {
+		if root := findFile(roots, name); root != "" {
+			snapshot.Compose, _ = composeServices(ctx, root)
+			break
+		}
+	}
+
+	snapshot.Summary = snapshot.summarize()
+	return snapshot
+}
+
+// findFile returns the first of roots holding a file called name.
+func findFile(roots []string, name string) string {
+	for _, root := range roots {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return root
+		}
+	}
+	return ""
+}
+
+// findVirtualenv returns the path of a project virtualenv (.venv, venv or
+// env, holding a pyvenv.cfg) and its Python version.
+func findVirtualenv(roots []string) (path, version string) {
+	for _, root := range roots {
+		for _, name := range []string{".venv", "venv", "env"} {
+			cfg, err := os.ReadFile(filepath.Join(root, name, "pyvenv.cfg"))
+			if err != nil {
+				continue
+			}
+			scanner := bufio.NewScanner(bytes.NewReader(cfg))
+			for scanner.Scan() {
+				key, value, ok := strings.Cut(scanner.Text(), "=")
+				key = strings.TrimSpace(key)
+				if ok && (key == "version" || key == "version_info") {
+					version = strings.TrimSpace(value)
+					break
+				}
+			}
+			return filepath.Join(root, name), version
+		}
+	}
+	return "", ""
+}
+
+// parseComposePS reads `docker compose ps --format json` output: a JSON
+// array from older Compose releases, one object per line from newer ones.
+func parseComposePS(out []byte) ([]ComposeService, error) {
+	type entry struct {
+		Service string `json:"Service"`
+		State   string `json:"State"`
+	}
+	var entries []entry
+	out = bytes.TrimSpace(out)
+	if bytes.HasPrefix(out, []byte("[")) {
+		if err := json.Unmarshal(out, &entries); err != nil {
+			return nil, fmt.Errorf("unexpected docker compose ps output: %w", err)
+		}
+	} else {
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var e entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("unexpected docker compose ps output: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+	services := make([]ComposeService, 0, len(entries))
+	for _, e := range entries {
+		services = append(services, ComposeService{Service: e.Service, State: e.State})
+	}
+	return services, nil
+}
+
+// sameState reports whether two snapshots found the project the same.
+func (s *EnvironmentSnapshot) sameState(other *EnvironmentSnapshot) bool {
+	return s.Directory == other.Directory && s.Summary == other.Summary
+}
+
+// summarize describes the snapshot in one line, such as "On branch main
+// with 3 uncommitted files; virtualenv .venv (Python 3.11.4); Node v20.11.0;
+// compose: web running, db exited".
+func (s *EnvironmentSnapshot) summarize() string {
+	var parts []string
+	switch {
+	case s.GitBranch == "":
+	case s.DirtyFiles == 0:
+		parts = append(parts, fmt.Sprintf("On branch %s, clean", s.GitBranch))
+	case s.DirtyFiles == 1:
+		parts = append(parts, fmt.Sprintf("On branch %s with 1 uncommitted file", s.GitBranch))
+	default:
+		parts = append(parts, fmt.Sprintf("On branch %s with %d uncommitted files", s.GitBranch, s.DirtyFiles))
+	}
+	if s.Virtualenv != "" {
+		venv := "virtualenv " + filepath.Base(s.Virtualenv)
+		if s.PythonVersion != "" {
+			venv += " (Python " + s.PythonVersion + ")"
+		}
+		parts = append(parts, venv)
+	}
+	if s.NodeVersion != "" {
+		parts = append(parts, "Node "+s.NodeVersion)
+	}
+	if len(s.Compose) > 0 {
+		states := make([]string, len(s.Compose))
+		for i, service := range s.Compose {
+			states[i] = service.Service + " " + service.State
+		}
+		parts = append(parts, "compose: "+strings.Join(states, ", "))
+	}
+	if len(parts) == 0 {
+		return "In " + s.Directory
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,114 @@
+package am
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestCaptureEnvironment(t *testing.T) {
+	origNode, origCompose := nodeVersion, composeServices
+	defer func() { nodeVersion, composeServices = origNode, origCompose }()
+	nodeVersion = func(ctx context.Context, dir string) (string, error) { return "v20.11.0", nil }
+	composeServices = func(ctx context.Context, dir string) ([]ComposeService, error) {
+		return []ComposeService{{Service: "web", State: "running"}, {Service: "db", State: "exited"}}, nil
+	}
+
+	dir := t.TempDir()
+	if snapshot := captureEnvironment(context.Background(), dir); snapshot.Summary != "In "+dir {
+		t.Errorf("Expected an empty project described by its directory, got %q", snapshot.Summary)
+	}
+
+	files := map[string]string{
+		".venv/pyvenv.cfg":   "home = /usr/bin\nversion = 3.11.4\n",
+		"package.json":       "{}",
+		"docker-compose.yml": "services: {}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot := captureEnvironment(context.Background(), dir)
+	want := "virtualenv .venv (Python 3.11.4); Node v20.11.0; compose: web running, db exited"
+	if snapshot.Summary != want {
+		t.Errorf("Summary = %q, want %q", snapshot.Summary, want)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if out, err := exec.Command("git", "-C", dir, "init", "-q", "-b", "feature").CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %s", out)
+	}
+	snapshot = captureEnvironment(context.Background(), dir)
+	if snapshot.GitBranch != "feature" || snapshot.DirtyFiles != 3 {
+		t.Errorf("Expected branch feature with 3 dirty files, got %q with %d", snapshot.GitBranch, snapshot.DirtyFiles)
+	}
+	if want := "On branch feature with 3 uncommitted files; " + want; snapshot.Summary != want {
+		t.Errorf("Summary = %q, want %q", snapshot.Summary, want)
+	}
+}
+
+func TestRecordEnvironment(t *testing.T) {
+	defer WaitForPendingWrites()
+	origNode, origCompose := nodeVersion, composeServices
+	defer func() { nodeVersion, composeServices = origNode, origCompose }()
+	nodeVersion = func(ctx context.Context, dir string) (string, error) { return "v20.11.0", nil }
+
+	dir := t.TempDir()
+	logger := &LLMLogger{tabID: "env-tab", conversations: make(map[string]*LLMConversation), amDir: t.TempDir()}
+	logger.SetWorkingDirectory(dir)
+
+	// Nothing is recorded outside a conversation
+	logger.RecordEnvironment("")
+
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "fix the build"})
+	logger.RecordEnvironment("")
+	conv := logger.conversations[convID]
+	first := conv.Metadata.Environment
+	if first == nil || first.Directory != dir {
+		t.Fatalf("Expected the tab's directory recorded, got %+v", first)
+	}
+
+	// An unchanged project keeps when it was first seen
+	logger.RecordEnvironment(dir)
+	if env := conv.Metadata.Environment; env.CapturedAt != first.CapturedAt || env.CheckedAt.Before(first.CheckedAt) {
+		t.Errorf("Expected the same state rechecked, got %+v after %+v", env, first)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger.RecordEnvironment(dir)
+	if env := conv.Metadata.Environment; env.NodeVersion != "v20.11.0" || env.CapturedAt.Before(first.CheckedAt) {
+		t.Errorf("Expected the changed state recorded, got %+v", env)
+	}
+}
+
+func TestParseComposePS(t *testing.T) {
+	want := []ComposeService{{Service: "web", State: "running"}, {Service: "db", State: "exited"}}
+	for name, out := range map[string]string{
+		"lines": `{"Service":"web","State":"running","Name":"app-web-1"}
+{"Service":"db","State":"exited","Name":"app-db-1"}
+`,
+		"array": `[{"Service":"web","State":"running"},{"Service":"db","State":"exited"}]`,
+	} {
+		got, err := parseComposePS([]byte(out))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, %v", name, got, err)
+		}
+	}
+	if _, err := parseComposePS([]byte("not json")); err == nil {
+		t.Error("Expected an error for unexpected output")
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 // ContentValidation represents validation results for conversation content.
@@ -25,6 +27,8 @@ type SystemHealth struct {
 	Status     string             `json:"status"` // HEALTHY, DEGRADED, FAILED
 	Metrics    *CaptureMetrics    `json:"metrics"`
 	Validation *ContentValidation `json:"validation,omitempty"`
+	Paused     bool               `json:"paused"` // Capture suspended (see Pause)
+	PausedAt   *time.Time         `json:"pausedAt,omitempty"`
 }
 
 // HealthMonitor tracks the health of the AM capture pipeline.
@@ -146,10 +150,19 @@ func (hm *HealthMonitor) GetSystemHealth() *SystemHealth {
 
 	status := hm.computeStatus()
 
-	return &SystemHealth{
+	health := &SystemHealth{
 		Status:  status,
 		Metrics: metrics,
 	}
+	health.setPaused()
+	return health
+}
+
+// setPaused fills in whether capture is paused.
+func (h *SystemHealth) setPaused() {
+	if since := PausedSince(); !since.IsZero() {
+		h.Paused, h.PausedAt = true, &since
+	}
 }
 
 // GetMetrics returns current metrics.
@@ -222,7 +235,7 @@ func (hm *HealthMonitor) ExportHealthReport(path string) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	return storage.WriteFileAtomic(path, data, 0644)
 }
 
 // RecordPTYHeartbeat is kept for backward compatibility but is a no-op.
@@ -230,9 +243,6 @@ func (hm *HealthMonitor) RecordPTYHeartbeat() {
 	// No-op - legacy compatibility
 }
 
-// ANSI artifact patterns that indicate corrupted content
-var ansiArtifacts = regexp.MustCompile(`\[\??[0-9;]*[a-zA-Z]|\x1b`)
-
 // ValidateConversationContent checks if a conversation file has valid, clean content.
 func ValidateConversationContent(filePath string) (bool, string) {
 	data, err := os.ReadFile(filePath)
@@ -259,7 +269,7 @@ func ValidateConversationContent(filePath string) (bool, string) {
 		if len(turn.Content) == 0 {
 			continue
 		}
-		if ansiArtifacts.MatchString(turn.Content) {
+		if ansi.HasArtifacts(turn.Content) {
 			return false, "turn " + string(rune('0'+i)) + " contains ANSI artifacts"
 		}
 	}
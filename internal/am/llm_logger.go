@@ -10,8 +10,12 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
 	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 // Memory limits to prevent unbounded growth
@@ -32,13 +36,15 @@ type ScreenSnapshot struct {
 
 // ConversationTurn represents a single exchange in an LLM conversation.
 type ConversationTurn struct {
-	Role            string    `json:"role"`
-	Content         string    `json:"content"`
-	Timestamp       time.Time `json:"timestamp"`
-	Provider        string    `json:"provider"`
-	Raw             string    `json:"raw,omitempty"`             // Raw PTY data for debugging
-	CaptureMethod   string    `json:"captureMethod,omitempty"`   // "pty_input", "pty_output", "tui_snapshot"
-	ParseConfidence float64   `json:"parseConfidence,omitempty"` // 0.0-1.0 for output parsing
+	Role            string       `json:"role"`
+	Content         string       `json:"content"`
+	Timestamp       time.Time    `json:"timestamp"`
+	Provider        string       `json:"provider"`
+	Raw             string       `json:"raw,omitempty"`             // Raw PTY data for debugging
+	CaptureMethod   string       `json:"captureMethod,omitempty"`   // "pty_input", "pty_output", "tui_snapshot"
+	ParseConfidence float64      `json:"parseConfidence,omitempty"` // 0.0-1.0 for output parsing
+	Tools           []ToolUse    `json:"tools,omitempty"`           // Tools the assistant ran (Claude Code)
+	Suggestions     []Suggestion `json:"suggestions,omitempty"`     // Numbered options offered (Copilot)
 }
 
 // ConversationRecovery holds recovery metadata for a conversation.
@@ -54,6 +60,22 @@ type ConversationMetadata struct {
 	WorkingDirectory string `json:"workingDirectory,omitempty"`
 	GitBranch        string `json:"gitBranch,omitempty"`
 	ShellType        string `json:"shellType,omitempty"`
+	ForkedFrom       string `json:"forkedFrom,omitempty"` // Conversation this one was forked from (see ForkConversation)
+	ForkTurn         int    `json:"forkTurn,omitempty"`   // Turns it shares with that conversation
+
+	// Files edited and commits made during the conversation, as reported by
+	// the tool (Aider)
+	FilesModified []string `json:"filesModified,omitempty"`
+	Commits       []string `json:"commits,omitempty"`
+
+	// Times capture was turned off in the tab, so missing turns are explained
+	CaptureGaps []CaptureGap `json:"captureGaps,omitempty"`
+
+	// Times a command was blocked waiting for the user's answer
+	WaitPeriods []WaitPeriod `json:"waitPeriods,omitempty"`
+
+	// The project's state when last checked (see RecordEnvironment)
+	Environment *EnvironmentSnapshot `json:"environment,omitempty"`
 }
 
 // LLMConversation represents a complete LLM conversation session.
@@ -61,6 +83,7 @@ type LLMConversation struct {
 	ConversationID  string                `json:"conversationId"`
 	TabID           string                `json:"tabId"`
 	Provider        string                `json:"provider"`
+	Model           string                `json:"model,omitempty"` // From --model, if the command chose one
 	CommandType     string                `json:"commandType"`
 	StartTime       time.Time             `json:"startTime"`
 	EndTime         time.Time             `json:"endTime,omitempty"`
@@ -82,6 +105,8 @@ type LLMLogger struct {
 	activeConvID      string
 	outputBuffer      string
 	inputBuffer       string
+	aiderLine         string // Aider output since the last newline
+	captureOff        bool   // Capture turned off in this tab (see SetCaptureEnabled)
 	lastOutputTime    time.Time
 	lastInputTime     time.Time
 	lastSnapshotTime  time.Time // NEW: Track when last snapshot was saved
@@ -95,6 +120,7 @@ type LLMLogger struct {
 	lastScreen        string
 	snapshotCount     int
 	onProcessCallback func(pid int, provider string) // Callback when Layer 3 detects process
+	workingDir        string                          // The tab's starting directory, if known
 }
 
 var (
@@ -115,15 +141,15 @@ func GetLLMLogger(tabID string, amDir string) *LLMLogger {
 	llmLoggersMu.Lock()
 	defer llmLoggersMu.Unlock()
 
-	log.Printf("[LLM Logger] GetLLMLogger called for tab '%s'", tabID)
-	log.Printf("[LLM Logger] Global logger map size: %d", len(llmLoggers))
+	logging.Debugf("[LLM Logger] GetLLMLogger called for tab '%s'", tabID)
+	logging.Debugf("[LLM Logger] Global logger map size: %d", len(llmLoggers))
 
 	if logger, exists := llmLoggers[tabID]; exists {
-		log.Printf("[LLM Logger] ✓ Found existing logger for tab %s (conversations=%d)", tabID, len(logger.conversations))
+		logging.Debugf("[LLM Logger] ✓ Found existing logger for tab %s (conversations=%d)", tabID, len(logger.conversations))
 		return logger
 	}
 
-	log.Printf("[LLM Logger] Creating NEW logger for tab %s", tabID)
+	logging.Debugf("[LLM Logger] Creating NEW logger for tab %s", tabID)
 	logger := &LLMLogger{
 		tabID:         tabID,
 		conversations: make(map[string]*LLMConversation),
@@ -134,8 +160,8 @@ func GetLLMLogger(tabID string, amDir string) *LLMLogger {
 	logger.loadConversationsFromDisk()
 
 	llmLoggers[tabID] = logger
-	log.Printf("[LLM Logger] ✓ Logger created and registered for tab %s", tabID)
-	log.Printf("[LLM Logger] Global logger map size now: %d", len(llmLoggers))
+	logging.Debugf("[LLM Logger] ✓ Logger created and registered for tab %s", tabID)
+	logging.Debugf("[LLM Logger] Global logger map size now: %d", len(llmLoggers))
 	return logger
 }
 
@@ -144,6 +170,10 @@ func RemoveLLMLogger(tabID string) {
 	llmLoggersMu.Lock()
 	defer llmLoggersMu.Unlock()
 	delete(llmLoggers, tabID)
+
+	pendingForksMu.Lock()
+	delete(pendingForks, tabID)
+	pendingForksMu.Unlock()
 }
 
 // SetAutoRespond updates the auto-respond flag for the logger.
@@ -161,6 +191,31 @@ func (l *LLMLogger) IsAutoRespond() bool {
 	return l.autoRespond
 }
 
+// SetWorkingDirectory records the tab shell's directory, so conversations are
+// attributed to that project rather than the server's, and none are captured
+// in a private workspace (see IsPrivate).
+func (l *LLMLogger) SetWorkingDirectory(dir string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.workingDir = dir
+}
+
+// privateLocked reports whether the tab is working in a private workspace.
+// Caller must hold l.mu.
+func (l *LLMLogger) privateLocked() bool {
+	dir := l.workingDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return IsPrivate(dir)
+}
+
+// isPrivateConversation reports whether conv happened in a private workspace,
+// which may have been marked after the conversation started
+func isPrivateConversation(conv *LLMConversation) bool {
+	return conv.Metadata != nil && IsPrivate(conv.Metadata.WorkingDirectory)
+}
+
 // SetLowConfidenceCallback sets the callback for low-confidence parsing alerts.
 // This is used to notify the user via Forge Vision when parsing quality is poor.
 func (l *LLMLogger) SetLowConfidenceCallback(callback func(raw string)) {
@@ -195,11 +250,18 @@ func (l *LLMLogger) StartConversationFromProcess(provider string, cmdType string
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	log.Printf("[LLM Logger] ═══ START CONVERSATION FROM PROCESS ═══")
-	log.Printf("[LLM Logger] TabID: %s, Provider: %s, Type: %s, PID: %d", l.tabID, provider, cmdType, pid)
+	logging.Debugf("[LLM Logger] TabID: %s, Provider: %s, Type: %s, PID: %d", l.tabID, provider, cmdType, pid)
+	if IsPaused() {
+		log.Printf("[LLM Logger] Not capturing tab %s: capture is paused", l.tabID)
+		return ""
+	}
+	if l.privateLocked() {
+		log.Printf("[LLM Logger] Not capturing tab %s: private workspace", l.tabID)
+		return ""
+	}
 
 	convID := fmt.Sprintf("conv-%d", time.Now().UnixNano())
-	log.Printf("[LLM Logger] Generated conversation ID: '%s'", convID)
+	logging.Debugf("[LLM Logger] Generated conversation ID: '%s'", convID)
 
 	conv := &LLMConversation{
 		ConversationID:  convID,
@@ -223,6 +285,10 @@ func (l *LLMLogger) StartConversationFromProcess(provider string, cmdType string
 		Provider:      provider,
 		CaptureMethod: "process_detection",
 	})
+	l.adoptForkLocked(conv)
+	if l.captureOff {
+		openCaptureGap(conv, conv.StartTime)
+	}
 
 	l.conversations[convID] = conv
 	l.activeConvID = convID
@@ -246,9 +312,7 @@ func (l *LLMLogger) StartConversationFromProcess(provider string, cmdType string
 		},
 	})
 
-	log.Printf("[LLM Logger] ✅ CONVERSATION STARTED FROM PROCESS")
-	log.Printf("[LLM Logger] ConvID: %s, TUI Mode: true", convID)
-	log.Printf("[LLM Logger] ═══ END START CONVERSATION FROM PROCESS ═══")
+	log.Printf("[LLM Logger] Started conversation %s in tab %s (%s, PID %d)", convID, l.tabID, provider, pid)
 	return convID
 }
 
@@ -257,59 +321,72 @@ func (l *LLMLogger) StartConversation(detected *llm.DetectedCommand) string {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	log.Printf("[LLM Logger] ═══ START CONVERSATION ═══")
-	log.Printf("[LLM Logger] TabID: %s", l.tabID)
-	log.Printf("[LLM Logger] Provider: %s, Type: %s", detected.Provider, detected.Type)
-	log.Printf("[LLM Logger] RawInput: '%s'", detected.RawInput)
-	log.Printf("[LLM Logger] Prompt: '%s'", detected.Prompt)
-	log.Printf("[LLM Logger] Current conversation map size: %d", len(l.conversations))
-	log.Printf("[LLM Logger] Current active conversation: '%s'", l.activeConvID)
+	logging.Debugf("[LLM Logger] TabID: %s", l.tabID)
+	logging.Debugf("[LLM Logger] Provider: %s, Type: %s", detected.Provider, detected.Type)
+	if IsPaused() {
+		log.Printf("[LLM Logger] Not capturing tab %s: capture is paused", l.tabID)
+		return ""
+	}
+	if l.privateLocked() {
+		log.Printf("[LLM Logger] Not capturing tab %s: private workspace", l.tabID)
+		return ""
+	}
+	logging.Debugf("[LLM Logger] RawInput: '%s'", detected.RawInput)
+	logging.Debugf("[LLM Logger] Prompt: '%s'", detected.Prompt)
+	logging.Debugf("[LLM Logger] Current conversation map size: %d", len(l.conversations))
+	logging.Debugf("[LLM Logger] Current active conversation: '%s'", l.activeConvID)
 
 	convID := fmt.Sprintf("conv-%d", time.Now().UnixNano())
-	log.Printf("[LLM Logger] Generated new conversation ID: '%s'", convID)
+	logging.Debugf("[LLM Logger] Generated new conversation ID: '%s'", convID)
 
 	conv := &LLMConversation{
 		ConversationID: convID,
 		TabID:          l.tabID,
 		Provider:       string(detected.Provider),
+		Model:          llm.ParseModel(detected.RawInput),
 		CommandType:    string(detected.Type),
 		StartTime:      time.Now(),
 		Turns:          []ConversationTurn{},
 		Complete:       false,
 		Metadata:       l.captureMetadata(),
 	}
-	log.Printf("[LLM Logger] Created conversation struct")
+	logging.Debugf("[LLM Logger] Created conversation struct")
 
 	if detected.Prompt != "" {
-		log.Printf("[LLM Logger] Adding initial user turn with prompt: '%s'", detected.Prompt)
+		logging.Debugf("[LLM Logger] Adding initial user turn with prompt: '%s'", detected.Prompt)
 		conv.Turns = append(conv.Turns, ConversationTurn{
 			Role:      "user",
 			Content:   detected.Prompt,
 			Timestamp: time.Now(),
 			Provider:  string(detected.Provider),
 		})
-		log.Printf("[LLM Logger] Initial turn added, total turns: %d", len(conv.Turns))
+		logging.Debugf("[LLM Logger] Initial turn added, total turns: %d", len(conv.Turns))
 	} else {
-		log.Printf("[LLM Logger] No initial prompt provided")
+		logging.Debugf("[LLM Logger] No initial prompt provided")
 	}
 
-	log.Printf("[LLM Logger] Adding conversation to map with key '%s'", convID)
+	l.adoptForkLocked(conv)
+	if l.captureOff {
+		openCaptureGap(conv, conv.StartTime)
+	}
+
+	logging.Debugf("[LLM Logger] Adding conversation to map with key '%s'", convID)
 	l.conversations[convID] = conv
-	log.Printf("[LLM Logger] ✓ Conversation added to map, new size: %d", len(l.conversations))
+	logging.Debugf("[LLM Logger] ✓ Conversation added to map, new size: %d", len(l.conversations))
 
-	log.Printf("[LLM Logger] Setting active conversation ID to '%s'", convID)
+	logging.Debugf("[LLM Logger] Setting active conversation ID to '%s'", convID)
 	l.activeConvID = convID
-	log.Printf("[LLM Logger] ✓ Active conversation set")
+	logging.Debugf("[LLM Logger] ✓ Active conversation set")
 
 	l.outputBuffer = ""
 	l.lastOutputTime = time.Now()
-	log.Printf("[LLM Logger] Output buffer reset")
+	logging.Debugf("[LLM Logger] Output buffer reset")
 
-	log.Printf("[LLM Logger] Saving conversation to disk...")
+	logging.Debugf("[LLM Logger] Saving conversation to disk...")
 	l.saveConversation(conv)
-	log.Printf("[LLM Logger] ✓ Conversation saved")
+	logging.Debugf("[LLM Logger] ✓ Conversation saved")
 
-	log.Printf("[LLM Logger] Publishing LLM_START event...")
+	logging.Debugf("[LLM Logger] Publishing LLM_START event...")
 	EventBus.Publish(&LayerEvent{
 		Type:      "LLM_START",
 		Layer:     1,
@@ -318,11 +395,10 @@ func (l *LLMLogger) StartConversation(detected *llm.DetectedCommand) string {
 		Provider:  string(detected.Provider),
 		Timestamp: time.Now(),
 	})
-	log.Printf("[LLM Logger] ✓ Event published")
+	logging.Debugf("[LLM Logger] ✓ Event published")
 
-	log.Printf("[LLM Logger] ✅ CONVERSATION STARTED SUCCESSFULLY")
-	log.Printf("[LLM Logger] Final state: activeConvID='%s', mapSize=%d", l.activeConvID, len(l.conversations))
-	log.Printf("[LLM Logger] ═══ END START CONVERSATION ═══")
+	log.Printf("[LLM Logger] Started conversation %s in tab %s (%s %s)", convID, l.tabID, detected.Provider, detected.Type)
+	logging.Debugf("[LLM Logger] Final state: activeConvID='%s', mapSize=%d", l.activeConvID, len(l.conversations))
 	return convID
 }
 
@@ -331,9 +407,13 @@ func (l *LLMLogger) AddOutput(rawOutput string) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if l.activeConvID == "" {
+	if l.activeConvID == "" || IsPaused() || l.captureOff {
 		return
 	}
+	rawOutput = redact(rawOutput)
+	if conv := l.conversations[l.activeConvID]; conv != nil && conv.Provider == string(llm.ProviderAider) {
+		l.scanAiderOutputLocked(conv, rawOutput)
+	}
 
 	// CRITICAL: Detect if shell prompt returned (LLM TUI exited)
 	// This ends the conversation to prevent unbounded growth
@@ -350,7 +430,7 @@ func (l *LLMLogger) AddOutput(rawOutput string) {
 
 		// Trigger snapshot on screen clear (event-based trigger)
 		if l.detectScreenClear(rawOutput) {
-			log.Printf("[LLM Logger] 📸 Screen clear detected! Saving snapshot (bufferSize=%d)", l.currentScreen.Len())
+			logging.Debugf("[LLM Logger] 📸 Screen clear detected! Saving snapshot (bufferSize=%d)", l.currentScreen.Len())
 			l.saveScreenSnapshotLocked()
 			return
 		}
@@ -375,7 +455,7 @@ func (l *LLMLogger) detectScreenClear(output string) bool {
 // indicating the LLM TUI has exited and we're back at the shell.
 func (l *LLMLogger) detectShellPromptReturn(output string) bool {
 	// Strip ANSI codes for pattern matching
-	clean := l.stripANSI(output)
+	clean := ansi.Strip(output)
 
 	// Common shell prompt patterns that indicate TUI exited:
 	// PowerShell: "PS C:\...>" or "PS /home/...>"
@@ -469,6 +549,7 @@ func (l *LLMLogger) endConversationLocked() {
 	l.currentScreen.Reset()
 	l.lastScreen = ""
 	l.snapshotCount = 0
+	l.aiderLine = ""
 }
 
 // saveScreenSnapshotLocked saves the current screen buffer as a snapshot.
@@ -492,7 +573,7 @@ func (l *LLMLogger) saveScreenSnapshotLocked() {
 	}
 
 	// Clean ANSI sequences for display
-	cleanedContent := l.stripANSI(rawContent)
+	cleanedContent := ansi.Strip(ansi.CollapseOverwrites(rawContent))
 
 	// Calculate diff from previous snapshot
 	diff := l.calculateDiff(l.lastScreen, cleanedContent)
@@ -511,7 +592,7 @@ func (l *LLMLogger) saveScreenSnapshotLocked() {
 	l.lastSnapshotTime = time.Now() // NEW: Track snapshot time
 	l.currentScreen.Reset()
 
-	log.Printf("[LLM Logger] 📸 Snapshot #%d saved for %s (%d chars, %d total snapshots)",
+	logging.Debugf("[LLM Logger] 📸 Snapshot #%d saved for %s (%d chars, %d total snapshots)",
 		l.snapshotCount, l.activeConvID, len(cleanedContent), len(conv.ScreenSnapshots))
 
 	// NEW: Parse snapshots incrementally to extract assistant responses
@@ -543,36 +624,6 @@ func (l *LLMLogger) saveScreenSnapshotLocked() {
 	}()
 }
 
-// stripANSI removes ANSI escape sequences from text.
-func (l *LLMLogger) stripANSI(text string) string {
-	// Pattern matches ANSI CSI sequences, OSC sequences, etc.
-	re := strings.NewReplacer(
-		"\x1b[2J", "",
-		"\x1b[H", "",
-		"\x1b[3J", "",
-	)
-	cleaned := re.Replace(text)
-	// Remove remaining ANSI sequences
-	for _, seq := range []string{"\x1b[", "\x1b]", "\x1b"} {
-		if strings.Contains(cleaned, seq) {
-			// Simplified removal - just keep printable chars
-			var result strings.Builder
-			inEscape := false
-			for _, r := range cleaned {
-				if r == '\x1b' {
-					inEscape = true
-				} else if inEscape && (r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z') {
-					inEscape = false
-				} else if !inEscape && (r >= 32 || r == '\n' || r == '\t' || r == '\r') {
-					result.WriteRune(r)
-				}
-			}
-			return result.String()
-		}
-	}
-	return cleaned
-}
-
 // calculateDiff computes a simple diff between two screens.
 func (l *LLMLogger) calculateDiff(oldScreen, newScreen string) string {
 	if oldScreen == "" {
@@ -616,10 +667,14 @@ func (l *LLMLogger) parseLatestSnapshotToTurns(conv *LLMConversation, snapshot S
 
 	// Provider-specific assistant response detection
 	var response string
+	var tools []ToolUse
+	var suggestions []Suggestion
 	switch conv.Provider {
 	case "github-copilot":
+		suggestions = parseSuggestions(content)
 		response = l.extractCopilotResponseFromSnapshot(content)
 	case "claude":
+		content, tools = extractClaudeToolUses(content)
 		response = l.extractClaudeResponseFromSnapshot(content)
 	case "aider":
 		response = l.extractAiderResponseFromSnapshot(content)
@@ -627,10 +682,14 @@ func (l *LLMLogger) parseLatestSnapshotToTurns(conv *LLMConversation, snapshot S
 		response = l.extractGenericResponseFromSnapshot(content)
 	}
 
-	// If we found a response and it's not a duplicate of the last turn
-	if response != "" && len(response) > 20 {
+	if len(response) <= 20 {
+		response = ""
+	}
+
+	// If we found a response, tool calls or options, and it's not a duplicate of the last turn
+	if response != "" || len(tools) > 0 || len(suggestions) > 0 {
 		// Check if this is a duplicate of the last turn
-		if len(conv.Turns) > 0 {
+		if response != "" && len(conv.Turns) > 0 {
 			lastTurn := conv.Turns[len(conv.Turns)-1]
 			if lastTurn.Role == "assistant" && strings.Contains(lastTurn.Content, response[:min(50, len(response))]) {
 				// Duplicate, skip
@@ -646,10 +705,12 @@ func (l *LLMLogger) parseLatestSnapshotToTurns(conv *LLMConversation, snapshot S
 			Provider:        conv.Provider,
 			CaptureMethod:   "tui_snapshot",
 			ParseConfidence: 0.75,
+			Tools:           tools,
+			Suggestions:     suggestions,
 		})
 
-		log.Printf("[LLM Logger] ✨ Extracted assistant response from snapshot #%d (%d chars)",
-			snapshot.SequenceNumber, len(response))
+		logging.Debugf("[LLM Logger] ✨ Extracted assistant response from snapshot #%d (%d chars, %d tools)",
+			snapshot.SequenceNumber, len(response), len(tools))
 	}
 }
 
@@ -782,11 +843,12 @@ func (l *LLMLogger) AddUserInput(rawInput string) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if l.activeConvID == "" {
+	l.endWaitLocked()
+	if l.activeConvID == "" || IsPaused() || l.captureOff {
 		return
 	}
 
-	l.inputBuffer += rawInput
+	l.inputBuffer += redact(rawInput)
 	l.lastInputTime = time.Now()
 
 	// Only trigger snapshot on Enter press (user submitted prompt), not every keystroke
@@ -794,7 +856,7 @@ func (l *LLMLogger) AddUserInput(rawInput string) {
 	if strings.Contains(rawInput, "\r") || strings.Contains(rawInput, "\n") {
 		// Save snapshot ONLY on Enter press in TUI mode
 		if l.tuiCaptureMode && l.currentScreen.Len() > 0 {
-			log.Printf("[LLM Logger] 📸 Post-enter snapshot trigger (bufferSize=%d)", l.currentScreen.Len())
+			logging.Debugf("[LLM Logger] 📸 Post-enter snapshot trigger (bufferSize=%d)", l.currentScreen.Len())
 			l.saveScreenSnapshotLocked()
 		}
 		l.flushUserInputLocked()
@@ -828,6 +890,7 @@ func (l *LLMLogger) flushUserInputLocked() {
 	if cleaned == "" {
 		return
 	}
+	chooseSuggestion(conv, cleaned)
 
 	conv.Turns = append(conv.Turns, ConversationTurn{
 		Role:          "user",
@@ -838,16 +901,10 @@ func (l *LLMLogger) flushUserInputLocked() {
 		CaptureMethod: "pty_input",
 	})
 
-	// Update recovery info
-	if conv.Recovery == nil {
-		conv.Recovery = &ConversationRecovery{}
-	}
-	conv.Recovery.LastSavedTurn = len(conv.Turns) - 1
-	conv.Recovery.CanRestore = true
-	conv.Recovery.SuggestedRestorePrompt = "Continue from: " + truncateForRestore(cleaned, 100)
+	conv.updateRecovery()
 
 	l.saveConversation(conv)
-	log.Printf("[LLM Logger] Captured user input for %s: '%s' (turns=%d)", l.activeConvID, truncateForLog(cleaned, 50), len(conv.Turns))
+	logging.Debugf("[LLM Logger] Captured user input for %s: '%s' (turns=%d)", l.activeConvID, truncateForLog(cleaned, 50), len(conv.Turns))
 }
 
 // truncateForLog truncates a string for logging purposes.
@@ -858,14 +915,99 @@ func truncateForLog(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-// truncateForRestore truncates a string for restore prompts.
+// truncateForRestore truncates a string for restore prompts, without
+// splitting a character.
 func truncateForRestore(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
 	return s[:maxLen] + "..."
 }
 
+// updateRecovery points the recovery info at the latest turn.
+func (conv *LLMConversation) updateRecovery() {
+	if len(conv.Turns) == 0 {
+		return
+	}
+	if conv.Recovery == nil {
+		conv.Recovery = &ConversationRecovery{}
+	}
+	conv.Recovery.LastSavedTurn = len(conv.Turns) - 1
+	conv.Recovery.CanRestore = true
+	conv.Recovery.SuggestedRestorePrompt = restorePrompt(conv.Turns)
+}
+
+// restorePrompt builds a prompt to pick a conversation up in a new session
+// from its stored turns: the gist of the assistant's last answer, and the
+// question left open, whether the user's unanswered input or one the
+// assistant ended by asking.
+func restorePrompt(turns []ConversationTurn) string {
+	var lastUser, lastAssistant string
+	pendingUser := false
+	for _, turn := range turns {
+		content := strings.Join(strings.Fields(turn.Content), " ")
+		if content == "" {
+			continue
+		}
+		switch turn.Role {
+		case "user":
+			lastUser, pendingUser = content, true
+		case "assistant":
+			lastAssistant, pendingUser = content, false
+		}
+	}
+	if lastAssistant == "" {
+		if lastUser == "" {
+			return ""
+		}
+		return "Continue from: " + truncateForRestore(lastUser, 100)
+	}
+
+	var b strings.Builder
+	b.WriteString("We are continuing an earlier conversation.")
+	if lastUser != "" && !pendingUser {
+		fmt.Fprintf(&b, " I had asked: %q.", truncateForRestore(lastUser, 100))
+	}
+	fmt.Fprintf(&b, " Your last answer was, in short: %q.", summarizeAnswer(lastAssistant))
+	switch {
+	case pendingUser:
+		fmt.Fprintf(&b, " My next question was never answered: %q. Please answer it.", truncateForRestore(lastUser, 200))
+	case strings.HasSuffix(lastAssistant, "?"):
+		fmt.Fprintf(&b, " You ended by asking: %q. Let's pick up from there.", truncateForRestore(lastSentence(lastAssistant), 200))
+	default:
+		b.WriteString(" Let's pick up from there.")
+	}
+	return b.String()
+}
+
+// summarizeAnswer cuts an answer to its first two sentences, at most 240
+// bytes.
+func summarizeAnswer(answer string) string {
+	end, sentences := 0, 0
+	for i := 0; i < len(answer) && sentences < 2; i++ {
+		if strings.ContainsRune(".!?", rune(answer[i])) && (i+1 == len(answer) || answer[i+1] == ' ') {
+			end, sentences = i+1, sentences+1
+		}
+	}
+	if end == 0 {
+		end = len(answer)
+	}
+	return truncateForRestore(answer[:end], 240)
+}
+
+// lastSentence returns the final sentence of text.
+func lastSentence(text string) string {
+	body := strings.TrimRight(text, ".!? ")
+	start := strings.LastIndexAny(body, ".!?")
+	for start >= 0 && start+1 < len(body) && body[start+1] != ' ' {
+		start = strings.LastIndexAny(body[:start], ".!?")
+	}
+	return strings.TrimSpace(text[start+1:])
+}
+
 // FlushOutput processes accumulated output and adds it as an assistant turn.
 func (l *LLMLogger) FlushOutput() {
 	l.mu.Lock()
@@ -882,17 +1024,31 @@ func (l *LLMLogger) FlushOutput() {
 
 	raw := l.outputBuffer
 
+	// Progress bars and spinners are kept as they finally looked, and Claude
+	// Code's tool calls as annotations rather than response text
+	text := ansi.CollapseOverwrites(raw)
+	var tools []ToolUse
+	if conv.Provider == string(llm.ProviderClaude) {
+		if withoutTools, found := extractClaudeToolUses(llm.CleanANSI(text)); len(found) > 0 {
+			text, tools = withoutTools, found
+		}
+	}
+
 	// Use new parsing with confidence scoring
-	cleanedOutput, confidence := ParseAssistantOutput(raw, conv.Provider)
+	cleanedOutput, confidence := ParseAssistantOutput(text, conv.Provider)
 	if cleanedOutput == "" {
 		// Fallback to old parser
-		cleanedOutput = llm.ParseLLMOutput(raw, llm.Provider(conv.Provider))
+		cleanedOutput = llm.ParseLLMOutput(text, llm.Provider(conv.Provider))
 	}
 
-	if cleanedOutput == "" {
+	if cleanedOutput == "" && len(tools) == 0 {
 		l.outputBuffer = ""
 		return
 	}
+	var suggestions []Suggestion
+	if conv.Provider == string(llm.ProviderGitHubCopilot) {
+		suggestions = parseSuggestions(cleanedOutput)
+	}
 
 	// Handle low confidence
 	if confidence < 0.8 {
@@ -911,12 +1067,45 @@ func (l *LLMLogger) FlushOutput() {
 		Raw:             raw,
 		CaptureMethod:   "pty_output",
 		ParseConfidence: confidence,
+		Tools:           tools,
+		Suggestions:     suggestions,
 	})
 
 	l.outputBuffer = ""
+	conv.updateRecovery()
 	l.saveConversation(conv)
 
-	log.Printf("[LLM Logger] Flushed output for %s (turns=%d, confidence=%.2f)", l.activeConvID, len(conv.Turns), confidence)
+	logging.Debugf("[LLM Logger] Flushed output for %s (turns=%d, confidence=%.2f)", l.activeConvID, len(conv.Turns), confidence)
+}
+
+// Flush adds buffered input and output to the active conversation and saves
+// it without ending it, so an interrupted conversation can still be recovered.
+func (l *LLMLogger) Flush() {
+	l.FlushOutput()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.activeConvID == "" {
+		return
+	}
+	l.flushUserInputLocked()
+	if conv, exists := l.conversations[l.activeConvID]; exists {
+		l.saveConversation(conv)
+	}
+}
+
+// FlushLLMLoggers flushes every tab's logger, e.g. before the server exits.
+func FlushLLMLoggers() {
+	llmLoggersMu.RLock()
+	loggers := make([]*LLMLogger, 0, len(llmLoggers))
+	for _, logger := range llmLoggers {
+		loggers = append(loggers, logger)
+	}
+	llmLoggersMu.RUnlock()
+
+	for _, logger := range loggers {
+		logger.Flush()
+	}
 }
 
 // EndConversation marks the active conversation as complete.
@@ -939,7 +1128,7 @@ func (l *LLMLogger) EndConversation() {
 		l.saveScreenSnapshotLocked()
 
 		// Parse screen snapshots into conversation turns
-		log.Printf("[LLM Logger] Parsing %d screen snapshots into turns...", len(conv.ScreenSnapshots))
+		logging.Debugf("[LLM Logger] Parsing %d screen snapshots into turns...", len(conv.ScreenSnapshots))
 		parsedTurns := l.parseScreenSnapshotsToTurns(conv.ScreenSnapshots, conv.Provider)
 
 		// Add parsed turns to conversation
@@ -947,7 +1136,7 @@ func (l *LLMLogger) EndConversation() {
 			conv.Turns = append(conv.Turns, turn)
 		}
 
-		log.Printf("[LLM Logger] Parsed %d turns from TUI snapshots", len(parsedTurns))
+		logging.Debugf("[LLM Logger] Parsed %d turns from TUI snapshots", len(parsedTurns))
 	}
 
 	// Traditional mode: Flush remaining output buffer
@@ -964,6 +1153,7 @@ func (l *LLMLogger) EndConversation() {
 		l.outputBuffer = ""
 	}
 
+	conv.updateRecovery()
 	conv.Complete = true
 	conv.EndTime = time.Now()
 	l.saveConversation(conv)
@@ -989,6 +1179,7 @@ func (l *LLMLogger) EndConversation() {
 	l.currentScreen.Reset()
 	l.lastScreen = ""
 	l.snapshotCount = 0
+	l.aiderLine = ""
 }
 
 // ShouldFlushOutput checks if output buffer should be flushed.
@@ -1014,9 +1205,9 @@ func (l *LLMLogger) GetConversations() []*LLMConversation {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	log.Printf("[LLM Logger] GetConversations called for tab %s", l.tabID)
-	log.Printf("[LLM Logger] In-memory conversation map size: %d", len(l.conversations))
-	log.Printf("[LLM Logger] Active conversation: '%s'", l.activeConvID)
+	logging.Debugf("[LLM Logger] GetConversations called for tab %s", l.tabID)
+	logging.Debugf("[LLM Logger] In-memory conversation map size: %d", len(l.conversations))
+	logging.Debugf("[LLM Logger] Active conversation: '%s'", l.activeConvID)
 
 	// Build map of in-memory conversation IDs for deduplication
 	inMemory := make(map[string]bool)
@@ -1024,7 +1215,7 @@ func (l *LLMLogger) GetConversations() []*LLMConversation {
 
 	// First, add all in-memory conversations
 	for convID, conv := range l.conversations {
-		log.Printf("[LLM Logger]   In-memory: ID=%s provider=%s type=%s complete=%v turns=%d snapshots=%d",
+		logging.Debugf("[LLM Logger]   In-memory: ID=%s provider=%s type=%s complete=%v turns=%d snapshots=%d",
 			convID, conv.Provider, conv.CommandType, conv.Complete, len(conv.Turns), len(conv.ScreenSnapshots))
 		convs = append(convs, conv)
 		inMemory[convID] = true
@@ -1033,8 +1224,8 @@ func (l *LLMLogger) GetConversations() []*LLMConversation {
 	// Skip disk reads if we loaded recently (within 60 seconds) - performance optimization
 	diskLoadCooldown := 60 * time.Second
 	if time.Since(l.lastDiskLoadTime) < diskLoadCooldown {
-		log.Printf("[LLM Logger] Skipping disk read (last load: %v ago)", time.Since(l.lastDiskLoadTime))
-		log.Printf("[LLM Logger] Returning %d conversations from memory only", len(convs))
+		logging.Debugf("[LLM Logger] Skipping disk read (last load: %v ago)", time.Since(l.lastDiskLoadTime))
+		logging.Debugf("[LLM Logger] Returning %d conversations from memory only", len(convs))
 		return convs
 	}
 
@@ -1070,7 +1261,7 @@ func (l *LLMLogger) GetConversations() []*LLMConversation {
 
 			// Only add if not already in memory AND belongs to this tab
 			if !inMemory[conv.ConversationID] && conv.TabID == l.tabID {
-				log.Printf("[LLM Logger]   From disk: ID=%s provider=%s type=%s complete=%v turns=%d snapshots=%d",
+				logging.Debugf("[LLM Logger]   From disk: ID=%s provider=%s type=%s complete=%v turns=%d snapshots=%d",
 					conv.ConversationID, conv.Provider, conv.CommandType, conv.Complete, len(conv.Turns), len(conv.ScreenSnapshots))
 				convs = append(convs, &conv)
 				// Also add to in-memory map for future calls
@@ -1079,7 +1270,7 @@ func (l *LLMLogger) GetConversations() []*LLMConversation {
 		}
 	}
 
-	log.Printf("[LLM Logger] Returning %d total conversations (%d from memory, %d loaded from disk)",
+	logging.Debugf("[LLM Logger] Returning %d total conversations (%d from memory, %d loaded from disk)",
 		len(convs), len(inMemory), len(convs)-len(inMemory))
 	return convs
 }
@@ -1122,7 +1313,7 @@ func (l *LLMLogger) GetConversation(convID string) *LLMConversation {
 
 				// Check if this is the conversation we're looking for AND belongs to this tab
 				if diskConv.ConversationID == convID && diskConv.TabID == l.tabID {
-					log.Printf("[LLM Logger] ✓ Loaded conversation %s from disk", convID)
+					logging.Debugf("[LLM Logger] ✓ Loaded conversation %s from disk", convID)
 					// Cache in memory for future calls
 					l.conversations[convID] = &diskConv
 					return &diskConv
@@ -1201,7 +1392,7 @@ func GetActiveConversations() map[string]*LLMConversation {
 // saveConversationAsync saves conversation to disk without blocking.
 // Used for snapshot saves to prevent keyboard lag.
 func (l *LLMLogger) saveConversationAsync(conv *LLMConversation) {
-	if l.amDir == "" {
+	if l.amDir == "" || IsPaused() || isPrivateConversation(conv) {
 		return
 	}
 
@@ -1218,13 +1409,15 @@ func (l *LLMLogger) saveConversationAsync(conv *LLMConversation) {
 		log.Printf("[LLM Logger] ❌ Failed to marshal conversation %s: %v", conv.ConversationID, err)
 		return
 	}
+	// Secrets split across output chunks are only whole once joined
+	data = []byte(redact(string(data)))
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := storage.WriteFileAtomic(filePath, data, 0644); err != nil {
 		log.Printf("[LLM Logger] ❌ Failed to write conversation to %s: %v", filePath, err)
 		return
 	}
 
-	log.Printf("[LLM Logger] ✅ Async saved conversation %s to %s (%d bytes)",
+	logging.Debugf("[LLM Logger] ✅ Async saved conversation %s to %s (%d bytes)",
 		conv.ConversationID, filename, len(data))
 }
 
@@ -1233,6 +1426,10 @@ func (l *LLMLogger) saveConversation(conv *LLMConversation) {
 		log.Printf("[LLM Logger] ⚠️ saveConversation skipped: amDir is empty")
 		return
 	}
+	if IsPaused() || isPrivateConversation(conv) {
+		logging.Debugf("[LLM Logger] saveConversation skipped: capture paused or private workspace")
+		return
+	}
 
 	if err := os.MkdirAll(l.amDir, 0755); err != nil {
 		log.Printf("[LLM Logger] ❌ Failed to create AM dir %s: %v", l.amDir, err)
@@ -1247,13 +1444,15 @@ func (l *LLMLogger) saveConversation(conv *LLMConversation) {
 		log.Printf("[LLM Logger] ❌ Failed to marshal conversation %s: %v", conv.ConversationID, err)
 		return
 	}
+	// Secrets split across output chunks are only whole once joined
+	data = []byte(redact(string(data)))
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := storage.WriteFileAtomic(filePath, data, 0644); err != nil {
 		log.Printf("[LLM Logger] ❌ Failed to write conversation to %s: %v", filePath, err)
 		return
 	}
 
-	log.Printf("[LLM Logger] ✅ Saved conversation %s to %s (%d bytes, %d turns, %d snapshots)",
+	logging.Debugf("[LLM Logger] ✅ Saved conversation %s to %s (%d bytes, %d turns, %d snapshots)",
 		conv.ConversationID, filename, len(data), len(conv.Turns), len(conv.ScreenSnapshots))
 }
 
@@ -1332,7 +1531,7 @@ func (l *LLMLogger) loadConversationsFromDisk() {
 				}
 			}
 
-			log.Printf("[LLM Logger] ✓ Loaded conversation %s (%d turns, %d snapshots, complete=%v)",
+			logging.Debugf("[LLM Logger] ✓ Loaded conversation %s (%d turns, %d snapshots, complete=%v)",
 				conv.ConversationID, len(conv.Turns), len(conv.ScreenSnapshots), conv.Complete)
 		}
 	}
@@ -1396,12 +1595,15 @@ func GetTestConversations() map[string]*LLMConversation {
 // METADATA AND PROJECT DETECTION HELPERS
 // ============================================================================
 
-// captureMetadata captures the current working directory, git branch, and shell type.
+// captureMetadata captures the working directory, git branch, and shell type.
+// Caller must hold l.mu.
 func (l *LLMLogger) captureMetadata() *ConversationMetadata {
 	metadata := &ConversationMetadata{}
 
-	// Capture working directory
-	if cwd, err := os.Getwd(); err == nil {
+	// Capture working directory: the tab's, or the server's if it isn't known
+	if l.workingDir != "" {
+		metadata.WorkingDirectory = l.workingDir
+	} else if cwd, err := os.Getwd(); err == nil {
 		metadata.WorkingDirectory = cwd
 	}
 
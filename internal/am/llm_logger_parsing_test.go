@@ -3,6 +3,8 @@ package am
 import (
 	"strings"
 	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
 )
 
 func TestCleanUserInput_ANSIRemoval(t *testing.T) {
@@ -150,7 +152,7 @@ func TestANSIPatternMatching(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ansiPattern.ReplaceAllString(tt.input, "")
+			result := ansi.Strip(tt.input)
 			if tt.removed && result != "" {
 				t.Errorf("ANSI not removed: input=%q result=%q", tt.input, result)
 			}
@@ -160,3 +162,67 @@ func TestANSIPatternMatching(t *testing.T) {
 		})
 	}
 }
+
+func TestRestorePrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		turns   []ConversationTurn
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "no turns",
+			turns: nil,
+		},
+		{
+			name:  "only user input",
+			turns: []ConversationTurn{{Role: "user", Content: "fix the tests"}},
+			want:  []string{"Continue from: fix the tests"},
+		},
+		{
+			name: "answered",
+			turns: []ConversationTurn{
+				{Role: "user", Content: "why is CI red?"},
+				{Role: "assistant", Content: "The lint step fails.  It needs gofmt. Run it on main.go and push again."},
+			},
+			want:    []string{`I had asked: "why is CI red?"`, `"The lint step fails. It needs gofmt."`, "pick up from there"},
+			notWant: []string{"push again", "never answered"},
+		},
+		{
+			name: "user question pending",
+			turns: []ConversationTurn{
+				{Role: "user", Content: "add a flag"},
+				{Role: "assistant", Content: "Added --verbose."},
+				{Role: "user", Content: "now document it"},
+			},
+			want:    []string{`"Added --verbose."`, `never answered: "now document it"`},
+			notWant: []string{"I had asked"},
+		},
+		{
+			name: "assistant asked a question",
+			turns: []ConversationTurn{
+				{Role: "user", Content: "rename the package"},
+				{Role: "assistant", Content: "I found two packages named util. Which one, internal/util or pkg/util?"},
+			},
+			want: []string{`You ended by asking: "Which one, internal/util or pkg/util?"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := restorePrompt(tt.turns)
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("Expected no prompt, got %q", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Prompt missing %q: %q", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Prompt should not contain %q: %q", notWant, got)
+				}
+			}
+		})
+	}
+}
@@ -2,12 +2,16 @@
 package am
 
 import (
+	"context"
 	"crypto/md5"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 const (
@@ -29,14 +33,20 @@ type SessionInfo struct {
 	Timestamp       time.Time `json:"timestamp"`
 }
 
-// GetAMDir returns the AM directory path.
+// GetAMDir returns the AM directory path. Profiles keep AM logs in their own tree.
 func GetAMDir() string {
+	if storage.CurrentProfile() != "" {
+		return storage.GetAMDir()
+	}
 	cwd, _ := os.Getwd()
 	return filepath.Join(cwd, amDir)
 }
 
 // GetArchiveDir returns the archive directory path.
 func GetArchiveDir() string {
+	if storage.CurrentProfile() != "" {
+		return filepath.Join(storage.GetAMDir(), "archive")
+	}
 	cwd, _ := os.Getwd()
 	return filepath.Join(cwd, archiveDir)
 }
@@ -179,6 +189,7 @@ func ArchiveLog(tabID string) error {
 			if err := os.WriteFile(dstPath, data, 0644); err != nil {
 				return err
 			}
+			go uploadArchive(entry.Name(), data)
 			return os.Remove(srcPath)
 		}
 	}
@@ -186,6 +197,22 @@ func ArchiveLog(tabID string) error {
 	return fmt.Errorf("log not found for tab %s", tabID)
 }
 
+// uploadArchive copies an archived log to the remote storage backend, if one is
+// configured, so long-term memory survives the local retention cleanup.
+func uploadArchive(name string, data []byte) {
+	backend, ok := storage.Remote()
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := backend.Put(ctx, "am/archive/"+name, data); err != nil {
+		log.Printf("[AM] Failed to upload archive %s: %v", name, err)
+		return
+	}
+	log.Printf("[AM] Uploaded archive %s to remote storage", name)
+}
+
 // RecoveryInfo represents session recovery information.
 type RecoveryInfo struct {
 	Status          string        `json:"status"`
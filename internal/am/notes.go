@@ -0,0 +1,191 @@
+package am
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+)
+
+// ProjectNotesFile is where notes extracted from finished conversations are
+// appended, in the workspace root.
+const ProjectNotesFile = "PROJECT_MEMORY.md"
+
+const (
+	notesTimeout       = 2 * time.Minute
+	notesTurnChars     = 2000  // Longest a single turn is sent as
+	notesTranscriptMax = 12000 // Longest transcript sent; the end is kept
+)
+
+// NoteExtractor sends a prompt to the assistant and returns its answer.
+type NoteExtractor func(ctx context.Context, prompt string) (string, error)
+
+var (
+	notesMu        sync.RWMutex
+	notesExtractor NoteExtractor
+	notesSubscribe sync.Once
+	notesWriteMu   sync.Mutex // Serialises appends to notes files
+
+	// notesWorkspaceFor finds the directory a conversation's notes go in;
+	// overridden in tests
+	notesWorkspaceFor = func(dir string) string {
+		if root := history.WorkspaceFor(dir); root != "" {
+			return root
+		}
+		return findGitRoot(dir)
+	}
+)
+
+// SetProjectNotes turns on extracting notes from each finished conversation
+// into its workspace's ProjectNotesFile, using extract to ask the assistant.
+// nil turns it off.
+func SetProjectNotes(extract NoteExtractor) {
+	notesMu.Lock()
+	notesExtractor = extract
+	notesMu.Unlock()
+	if extract != nil {
+		notesSubscribe.Do(func() { EventBus.Subscribe(handleNotesEvent) })
+	}
+}
+
+// handleNotesEvent extracts notes when a conversation ends.
+func handleNotesEvent(event *LayerEvent) {
+	if event.Type != "LLM_END" {
+		return
+	}
+	notesMu.RLock()
+	extract := notesExtractor
+	notesMu.RUnlock()
+	if extract == nil {
+		return
+	}
+	conv := snapshotConversation(event.TabID, event.ConvID)
+	if conv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notesTimeout)
+	defer cancel()
+	path, err := extractProjectNotes(ctx, extract, conv)
+	if err != nil {
+		log.Printf("[AM] Failed to extract project notes from %s: %v", conv.ConversationID, err)
+	} else if path != "" {
+		log.Printf("[AM] Added notes from %s to %s", conv.ConversationID, path)
+	}
+}
+
+// snapshotConversation copies a tab's conversation so it can be read
+// without the logger's lock.
+func snapshotConversation(tabID, convID string) *LLMConversation {
+	llmLoggersMu.RLock()
+	logger := llmLoggers[tabID]
+	llmLoggersMu.RUnlock()
+	if logger == nil {
+		return nil
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	conv, ok := logger.conversations[convID]
+	if !ok {
+		return nil
+	}
+	snapshot := *conv
+	snapshot.Turns = append([]ConversationTurn(nil), conv.Turns...)
+	if conv.Metadata != nil {
+		metadata := *conv.Metadata
+		snapshot.Metadata = &metadata
+	}
+	return &snapshot
+}
+
+// notesPrompt asks for the resolved problems and decisions in a transcript.
+func notesPrompt(transcript string) string {
+	return "Below is a conversation between a developer and an AI coding assistant.\n" +
+		"List what it settled that is worth remembering in this project later, as markdown in exactly this form:\n\n" +
+		"### Problems solved\n- <problem>: <how it was fixed>\n\n" +
+		"### Decisions\n- <decision>: <why>\n\n" +
+		"Leave out a section with nothing in it. Keep each bullet to one line. " +
+		"If nothing was resolved or decided, answer only NONE.\n\n" +
+		"Conversation:\n\n" + transcript
+}
+
+// conversationTranscript renders the conversation's turns as plain text,
+// redacted and cut to notesTranscriptMax from the end.
+func conversationTranscript(conv *LLMConversation) string {
+	var b strings.Builder
+	for _, turn := range conv.Turns {
+		content := strings.TrimSpace(turn.Content)
+		if content == "" {
+			continue
+		}
+		role := "Assistant"
+		if turn.Role == "user" {
+			role = "Developer"
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", role, truncateForLog(content, notesTurnChars))
+	}
+	transcript := redact(b.String())
+	if len(transcript) > notesTranscriptMax {
+		transcript = "..." + transcript[len(transcript)-notesTranscriptMax:]
+	}
+	return transcript
+}
+
+// extractProjectNotes asks extract for notes on a finished conversation and
+// appends them to its workspace's ProjectNotesFile, returning the file's
+// path, or "" when there was nothing to add.
+func extractProjectNotes(ctx context.Context, extract NoteExtractor, conv *LLMConversation) (string, error) {
+	if IsPaused() || conv.Metadata == nil || conv.Metadata.WorkingDirectory == "" {
+		return "", nil
+	}
+	workspace := notesWorkspaceFor(conv.Metadata.WorkingDirectory)
+	if workspace == "" || IsPrivate(workspace) {
+		return "", nil
+	}
+	var user, assistant bool
+	for _, turn := range conv.Turns {
+		user = user || turn.Role == "user"
+		assistant = assistant || turn.Role == "assistant"
+	}
+	if !user || !assistant {
+		return "", nil
+	}
+
+	path := filepath.Join(workspace, ProjectNotesFile)
+	heading := fmt.Sprintf("## %s: %s (%s)", conv.StartTime.Local().Format("2006-01-02"), conv.Provider, conv.ConversationID)
+	if existing, err := os.ReadFile(path); err == nil && strings.Contains(string(existing), heading) {
+		return "", nil
+	}
+
+	answer, err := extract(ctx, notesPrompt(conversationTranscript(conv)))
+	if err != nil {
+		return "", err
+	}
+	notes := strings.TrimSpace(answer)
+	if notes == "" || strings.EqualFold(strings.Trim(notes, ". "), "none") {
+		return "", nil
+	}
+
+	notesWriteMu.Lock()
+	defer notesWriteMu.Unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var b strings.Builder
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		b.WriteString("# Project Memory\n\nProblems solved and decisions made in AI conversations in this project, noted by Forge when each ended. Edit freely; new notes are appended.\n\n")
+	}
+	fmt.Fprintf(&b, "%s\n\n%s\n\n", heading, redact(notes))
+	if _, err := f.WriteString(b.String()); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,87 @@
+package am
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractProjectNotes(t *testing.T) {
+	workspace := t.TempDir()
+	orig := notesWorkspaceFor
+	defer func() { notesWorkspaceFor = orig }()
+	notesWorkspaceFor = func(dir string) string { return workspace }
+
+	conv := &LLMConversation{
+		ConversationID: "conv-1111111111",
+		Provider:       "claude",
+		StartTime:      time.Now(),
+		Metadata:       &ConversationMetadata{WorkingDirectory: filepath.Join(workspace, "src")},
+		Turns: []ConversationTurn{
+			{Role: "user", Content: "why does the build fail?"},
+			{Role: "assistant", Content: "the go.mod is missing a replace directive"},
+		},
+	}
+	var prompts []string
+	extract := func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "### Problems solved\n- Build failure: added a replace directive", nil
+	}
+
+	path, err := extractProjectNotes(context.Background(), extract, conv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(workspace, ProjectNotesFile) {
+		t.Fatalf("Expected notes in the workspace root, got %q", path)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "Developer: why does the build fail?") {
+		t.Errorf("Expected the transcript in the prompt, got %q", prompts)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "# Project Memory") || !strings.Contains(content, "(conv-1111111111)") || !strings.Contains(content, "added a replace directive") {
+		t.Errorf("Unexpected notes file:\n%s", content)
+	}
+
+	// A conversation already noted is not sent again
+	if path, err := extractProjectNotes(context.Background(), extract, conv); err != nil || path != "" || len(prompts) != 1 {
+		t.Errorf("Expected no second extraction, got %q, %v after %d prompts", path, err, len(prompts))
+	}
+
+	// Nothing is appended when the assistant finds nothing
+	conv.ConversationID = "conv-2222222222"
+	none := func(ctx context.Context, prompt string) (string, error) { return "NONE", nil }
+	if path, err := extractProjectNotes(context.Background(), none, conv); err != nil || path != "" {
+		t.Errorf("Expected nothing added, got %q, %v", path, err)
+	}
+	if after, _ := os.ReadFile(filepath.Join(workspace, ProjectNotesFile)); string(after) != content {
+		t.Errorf("Expected the notes file unchanged, got:\n%s", after)
+	}
+
+	// Assistant failures are returned
+	failing := func(ctx context.Context, prompt string) (string, error) { return "", errors.New("offline") }
+	if _, err := extractProjectNotes(context.Background(), failing, conv); err == nil {
+		t.Error("Expected the assistant's error")
+	}
+
+	// Conversations without an answer, or in private workspaces, are skipped
+	unanswered := *conv
+	unanswered.Turns = conv.Turns[:1]
+	if path, err := extractProjectNotes(context.Background(), failing, &unanswered); err != nil || path != "" {
+		t.Errorf("Expected an unanswered conversation skipped, got %q, %v", path, err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, IgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := extractProjectNotes(context.Background(), failing, conv); err != nil || path != "" {
+		t.Errorf("Expected a private workspace skipped, got %q, %v", path, err)
+	}
+}
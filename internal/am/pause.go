@@ -0,0 +1,39 @@
+package am
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// pausedAt is when capture was paused (Unix nanoseconds), or 0 while running
+var pausedAt atomic.Int64
+
+// Pause suspends all AM capture until Resume: no conversations start, and
+// terminal input and output reaching the loggers is dropped rather than
+// saved. For screen sharing or typing credentials.
+func Pause() {
+	if pausedAt.CompareAndSwap(0, time.Now().UnixNano()) {
+		log.Printf("[AM] Capture paused")
+	}
+}
+
+// Resume restarts capture after Pause.
+func Resume() {
+	if pausedAt.Swap(0) != 0 {
+		log.Printf("[AM] Capture resumed")
+	}
+}
+
+// IsPaused reports whether capture is paused.
+func IsPaused() bool {
+	return pausedAt.Load() != 0
+}
+
+// PausedSince returns when capture was paused, or the zero time if it isn't.
+func PausedSince() time.Time {
+	if at := pausedAt.Load(); at != 0 {
+		return time.Unix(0, at)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,44 @@
+package am
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestPause(t *testing.T) {
+	defer Resume()
+
+	amDir := t.TempDir()
+	logger := &LLMLogger{tabID: "pause-tab", conversations: make(map[string]*LLMConversation), amDir: amDir}
+	logger.SetWorkingDirectory(t.TempDir())
+
+	Pause()
+	if health := (&System{}).GetHealth(); !health.Paused || health.PausedAt == nil {
+		t.Errorf("Expected health to report the pause, got %+v", health)
+	}
+	if convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "hi"}); convID != "" {
+		t.Errorf("Expected no conversation while paused, got %q", convID)
+	}
+
+	Resume()
+	if IsPaused() || (&System{}).GetHealth().Paused {
+		t.Error("Expected capture resumed")
+	}
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "hi"})
+	if convID == "" {
+		t.Fatal("Expected a conversation after resuming")
+	}
+
+	// Output arriving while paused is dropped
+	Pause()
+	logger.AddOutput("typed while sharing the screen")
+	if logger.outputBuffer != "" {
+		t.Errorf("Expected no output captured while paused, got %q", logger.outputBuffer)
+	}
+	entries, _ := os.ReadDir(amDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the conversation saved before pausing, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,63 @@
+package am
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// IgnoreFile marks a directory, and everything under it, as private: AM
+// captures nothing from tabs working there
+const IgnoreFile = ".forge-ignore"
+
+var (
+	privateMu   sync.RWMutex
+	privateDirs []string
+)
+
+// SetPrivateWorkspaces sets directories AM never captures from, in addition
+// to those holding an IgnoreFile. Paths must be absolute.
+func SetPrivateWorkspaces(dirs []string) error {
+	cleaned := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("private workspace %q must be an absolute path", dir)
+		}
+		cleaned = append(cleaned, filepath.Clean(dir))
+	}
+	privateMu.Lock()
+	privateDirs = cleaned
+	privateMu.Unlock()
+	return nil
+}
+
+// IsPrivate reports whether dir is inside a private workspace: one set with
+// SetPrivateWorkspaces, or a directory holding an IgnoreFile.
+func IsPrivate(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	dir = filepath.Clean(dir)
+
+	privateMu.RLock()
+	for _, private := range privateDirs {
+		if dir == private || strings.HasPrefix(dir, strings.TrimSuffix(private, string(filepath.Separator))+string(filepath.Separator)) {
+			privateMu.RUnlock()
+			return true
+		}
+	}
+	privateMu.RUnlock()
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, IgnoreFile)); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,85 @@
+package am
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestIsPrivate(t *testing.T) {
+	defer SetPrivateWorkspaces(nil)
+
+	root := t.TempDir()
+	marked := filepath.Join(root, "marked")
+	configured := filepath.Join(root, "configured")
+	for _, dir := range []string{filepath.Join(marked, "src"), filepath.Join(configured, "src"), filepath.Join(root, "public")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(marked, IgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPrivateWorkspaces([]string{configured}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{marked, true},
+		{filepath.Join(marked, "src"), true},
+		{filepath.Join(configured, "src"), true},
+		{configured + "-other", false},
+		{filepath.Join(root, "public"), false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsPrivate(tt.dir); got != tt.want {
+			t.Errorf("IsPrivate(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+
+	if err := SetPrivateWorkspaces([]string{"relative"}); err == nil {
+		t.Error("Expected an error for a relative path")
+	}
+}
+
+func TestLLMLoggerSkipsPrivateWorkspace(t *testing.T) {
+	amDir := t.TempDir()
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, IgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &LLMLogger{tabID: "private-tab", conversations: make(map[string]*LLMConversation), amDir: amDir}
+	logger.SetWorkingDirectory(workspace)
+	if convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "secret plans"}); convID != "" {
+		t.Errorf("Expected no conversation in a private workspace, got %q", convID)
+	}
+	if convID := logger.StartConversationFromProcess("claude", "chat", 0); convID != "" {
+		t.Errorf("Expected no process conversation in a private workspace, got %q", convID)
+	}
+
+	// Marking a workspace private mid-conversation stops further saves
+	logger.SetWorkingDirectory(t.TempDir())
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "hello"})
+	if convID == "" {
+		t.Fatal("Expected a conversation outside private workspaces")
+	}
+	conv := logger.conversations[convID]
+	path := filepath.Join(amDir, logger.generateConversationFilename(conv))
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Expected the conversation saved: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(conv.Metadata.WorkingDirectory, IgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger.saveConversation(conv)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no save once the workspace is private, got %v", err)
+	}
+}
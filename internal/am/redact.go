@@ -0,0 +1,73 @@
+package am
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redactedText replaces secret values in conversation logs
+const redactedText = "[redacted]"
+
+// Limits on registered secrets: shorter values would mask ordinary text, and
+// the oldest are forgotten past maxRedactions
+const (
+	minRedactLen  = 4
+	maxRedactions = 256
+)
+
+var (
+	redactMu   sync.RWMutex
+	redactions []string
+	redactor   *strings.Replacer
+)
+
+// RedactSecret masks value wherever it appears in conversation logs from now
+// on. Snippet expansion registers the secrets it types into a tab, so they
+// reach the shell but never the AM logs.
+func RedactSecret(value string) {
+	if len(value) < minRedactLen {
+		return
+	}
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	for _, existing := range redactions {
+		if existing == value {
+			return
+		}
+	}
+	redactions = append(redactions, value)
+	if len(redactions) > maxRedactions {
+		redactions = redactions[len(redactions)-maxRedactions:]
+	}
+
+	// Match values as typed and as escaped in saved JSON, longest first so a
+	// secret containing another is masked whole
+	var forms []string
+	for _, secret := range redactions {
+		forms = append(forms, secret)
+		if escaped, err := json.Marshal(secret); err == nil {
+			if s := string(escaped[1 : len(escaped)-1]); s != secret {
+				forms = append(forms, s)
+			}
+		}
+	}
+	sort.SliceStable(forms, func(i, j int) bool { return len(forms[i]) > len(forms[j]) })
+	pairs := make([]string, 0, 2*len(forms))
+	for _, form := range forms {
+		pairs = append(pairs, form, redactedText)
+	}
+	redactor = strings.NewReplacer(pairs...)
+}
+
+// redact masks registered secrets in text
+func redact(text string) string {
+	redactMu.RLock()
+	r := redactor
+	redactMu.RUnlock()
+	if r == nil {
+		return text
+	}
+	return r.Replace(text)
+}
@@ -0,0 +1,25 @@
+package am
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactSecret(t *testing.T) {
+	defer func() { redactions, redactor = nil, nil }()
+
+	RedactSecret("abc") // Too short to mask
+	RedactSecret("hunter22")
+	RedactSecret(`pa"ss\word`)
+
+	got := redact("login abc hunter22 and pa\"ss\\word")
+	if want := "login abc [redacted] and [redacted]"; got != want {
+		t.Errorf("redact = %q, want %q", got, want)
+	}
+
+	data, _ := json.Marshal(map[string]string{"content": `pa"ss\word`})
+	if got := redact(string(data)); strings.Contains(got, "word") {
+		t.Errorf("Expected the JSON-escaped secret masked, got %s", got)
+	}
+}
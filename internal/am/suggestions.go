@@ -0,0 +1,84 @@
+package am
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Suggestion is one of the numbered options an assistant offered, such as
+// the commands `gh copilot suggest` lists.
+type Suggestion struct {
+	Number  int    `json:"number"`
+	Command string `json:"command"`
+	Chosen  bool   `json:"chosen,omitempty"` // The user picked it in their next input
+}
+
+// numberedOption matches "1. git status" or "❯ 2) git status --short"
+var numberedOption = regexp.MustCompile(`^(?:[❯>›]\s*)?(\d{1,2})[.)]\s+(.+)$`)
+
+// parseSuggestions returns the last list of options numbered from 1 in
+// text, if it has at least two.
+func parseSuggestions(text string) []Suggestion {
+	var current, last []Suggestion
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		m := numberedOption.FindStringSubmatch(trimmed)
+		if m == nil {
+			if len(current) >= 2 {
+				last = current
+			}
+			current = nil
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		option := Suggestion{Number: n, Command: strings.Trim(strings.TrimSpace(m[2]), "`")}
+		switch {
+		case n == len(current)+1:
+			current = append(current, option)
+		case n == 1:
+			if len(current) >= 2 {
+				last = current
+			}
+			current = []Suggestion{option}
+		default:
+			current = nil
+		}
+	}
+	if len(current) >= 2 {
+		last = current
+	}
+	return last
+}
+
+// chooseSuggestion marks the option the user picked with input, by number or
+// by typing its command, among those offered in the last assistant turn.
+func chooseSuggestion(conv *LLMConversation, input string) {
+	for i := len(conv.Turns) - 1; i >= 0; i-- {
+		turn := &conv.Turns[i]
+		if turn.Role == "user" {
+			return
+		}
+		if turn.Role != "assistant" || len(turn.Suggestions) == 0 {
+			continue
+		}
+		for _, option := range turn.Suggestions {
+			if option.Chosen {
+				return
+			}
+		}
+		input = strings.TrimSpace(input)
+		n, err := strconv.Atoi(input)
+		for j := range turn.Suggestions {
+			option := &turn.Suggestions[j]
+			if (err == nil && option.Number == n) || option.Command == input {
+				option.Chosen = true
+				return
+			}
+		}
+		return
+	}
+}
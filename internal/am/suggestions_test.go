@@ -0,0 +1,75 @@
+package am
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestParseSuggestions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Suggestion
+	}{
+		{
+			name: "numbered commands",
+			text: "Here are some options:\n\n1. `git log --oneline`\n2. git log --graph\n\n3) git shortlog -s\nPick one.",
+			want: []Suggestion{{Number: 1, Command: "git log --oneline"}, {Number: 2, Command: "git log --graph"}, {Number: 3, Command: "git shortlog -s"}},
+		},
+		{
+			name: "selection marker",
+			text: "❯ 1. Yes\n  2. No",
+			want: []Suggestion{{Number: 1, Command: "Yes"}, {Number: 2, Command: "No"}},
+		},
+		{
+			name: "last list wins",
+			text: "1. a\n2. b\nThen:\n1. c\n2. d",
+			want: []Suggestion{{Number: 1, Command: "c"}, {Number: 2, Command: "d"}},
+		},
+		{
+			name: "a single numbered line is not a list",
+			text: "1. Run the tests first.",
+		},
+		{
+			name: "not numbered from one",
+			text: "3. foo\n4. bar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSuggestions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSuggestions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopilotSuggestionChosen(t *testing.T) {
+	logger := &LLMLogger{tabID: "copilot-options", conversations: make(map[string]*LLMConversation), amDir: t.TempDir()}
+	defer WaitForPendingWrites()
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderGitHubCopilot, Type: llm.CommandSuggest, Prompt: "show recent commits"})
+
+	logger.AddOutput("Suggestions:\r\n1. git log --oneline -10\r\n2. git log --since=yesterday\r\n")
+	logger.FlushOutput()
+	logger.AddUserInput("2\r")
+
+	turns := logger.GetConversation(convID).Turns
+	var offered []Suggestion
+	for _, turn := range turns {
+		if turn.Role == "assistant" {
+			offered = turn.Suggestions
+		}
+	}
+	want := []Suggestion{{Number: 1, Command: "git log --oneline -10"}, {Number: 2, Command: "git log --since=yesterday", Chosen: true}}
+	if !reflect.DeepEqual(offered, want) {
+		t.Errorf("Suggestions = %+v, want %+v", offered, want)
+	}
+
+	// Later input doesn't change an earlier choice
+	logger.AddUserInput("1\r")
+	if got := logger.GetConversation(convID).Turns; !got[len(got)-3].Suggestions[1].Chosen || got[len(got)-3].Suggestions[0].Chosen {
+		t.Errorf("Expected the first choice kept, got %+v", got[len(got)-3].Suggestions)
+	}
+}
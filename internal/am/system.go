@@ -67,6 +67,21 @@ func (s *System) IsEnabled() bool {
 	return s.enabled
 }
 
+// ActiveLayers names the parts of the capture pipeline that are running:
+// "pty" (Layer 1, terminal output capture) and "health" (the monitor
+// tracking it). Empty when the system is stopped.
+func (s *System) ActiveLayers() []string {
+	layers := []string{}
+	if !s.enabled {
+		return layers
+	}
+	layers = append(layers, "pty")
+	if s.HealthMonitor != nil {
+		layers = append(layers, "health")
+	}
+	return layers
+}
+
 // GetLLMLogger returns an LLM logger for a specific tab.
 func (s *System) GetLLMLogger(tabID string) *LLMLogger {
 	return GetLLMLogger(tabID, s.AMDir)
@@ -75,9 +90,11 @@ func (s *System) GetLLMLogger(tabID string) *LLMLogger {
 // GetHealth returns current system health.
 func (s *System) GetHealth() *SystemHealth {
 	if s.HealthMonitor == nil {
-		return &SystemHealth{
+		health := &SystemHealth{
 			Status: "NOT_INITIALIZED",
 		}
+		health.setPaused()
+		return health
 	}
 	return s.HealthMonitor.GetSystemHealth()
 }
@@ -0,0 +1,79 @@
+package am
+
+import (
+	"log"
+	"time"
+)
+
+// CaptureGap is a stretch of a conversation that was not captured because
+// capture was turned off in its tab (see SetCaptureEnabled).
+type CaptureGap struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"` // Unset if capture was off until the conversation ended
+}
+
+// SetCaptureEnabled turns capture of the tab's input and output on or off
+// without pausing AM in other tabs, for example while a password is typed.
+// Input typed but not yet submitted is dropped when capture is turned off.
+// The time capture was off is recorded in the active conversation's
+// metadata.
+func (l *LLMLogger) SetCaptureEnabled(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.captureOff != enabled {
+		return
+	}
+	l.captureOff = !enabled
+	if !enabled {
+		l.inputBuffer = ""
+		log.Printf("[LLM Logger] Capture turned off in tab %s", l.tabID)
+	} else {
+		log.Printf("[LLM Logger] Capture turned on in tab %s", l.tabID)
+	}
+
+	conv := l.conversations[l.activeConvID]
+	if conv == nil {
+		return
+	}
+	if enabled {
+		closeCaptureGap(conv, time.Now())
+	} else {
+		openCaptureGap(conv, time.Now())
+	}
+	l.saveConversation(conv)
+}
+
+// CaptureEnabled reports whether the tab's input and output are captured.
+func (l *LLMLogger) CaptureEnabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !l.captureOff
+}
+
+// openCaptureGap records that capture stopped at the given time. The
+// metadata is replaced rather than changed in place, as pending async saves
+// share it.
+func openCaptureGap(conv *LLMConversation, at time.Time) {
+	metadata := ConversationMetadata{}
+	if conv.Metadata != nil {
+		metadata = *conv.Metadata
+	}
+	metadata.CaptureGaps = append(append([]CaptureGap(nil), metadata.CaptureGaps...), CaptureGap{Start: at})
+	conv.Metadata = &metadata
+}
+
+// closeCaptureGap ends the conversation's open capture gap, if it has one.
+func closeCaptureGap(conv *LLMConversation, at time.Time) {
+	if conv.Metadata == nil || len(conv.Metadata.CaptureGaps) == 0 {
+		return
+	}
+	last := len(conv.Metadata.CaptureGaps) - 1
+	if conv.Metadata.CaptureGaps[last].End != nil {
+		return
+	}
+	metadata := *conv.Metadata
+	metadata.CaptureGaps = append([]CaptureGap(nil), metadata.CaptureGaps...)
+	metadata.CaptureGaps[last].End = &at
+	conv.Metadata = &metadata
+}
@@ -0,0 +1,51 @@
+package am
+
+import (
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestSetCaptureEnabled(t *testing.T) {
+	defer WaitForPendingWrites()
+
+	logger := &LLMLogger{tabID: "capture-tab", conversations: make(map[string]*LLMConversation), amDir: t.TempDir()}
+	logger.SetWorkingDirectory(t.TempDir())
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude, Prompt: "hi"})
+	if convID == "" {
+		t.Fatal("Expected a conversation")
+	}
+
+	logger.AddUserInput("sudo apt ")
+	logger.SetCaptureEnabled(false)
+	if logger.CaptureEnabled() {
+		t.Error("Expected capture off")
+	}
+	logger.AddUserInput("hunter2\r")
+	logger.AddOutput("password accepted")
+	if logger.inputBuffer != "" || logger.outputBuffer != "" {
+		t.Errorf("Expected nothing captured while off, got input %q, output %q", logger.inputBuffer, logger.outputBuffer)
+	}
+	logger.SetCaptureEnabled(true)
+	logger.SetCaptureEnabled(true)
+
+	conv := logger.conversations[convID]
+	if len(conv.Turns) != 1 {
+		t.Errorf("Expected only the initial prompt, got %+v", conv.Turns)
+	}
+	gaps := conv.Metadata.CaptureGaps
+	if len(gaps) != 1 || gaps[0].End == nil || gaps[0].End.Before(gaps[0].Start) {
+		t.Fatalf("Expected one closed capture gap, got %+v", gaps)
+	}
+
+	// A conversation started while capture is off opens with a gap
+	logger.EndConversation()
+	logger.SetCaptureEnabled(false)
+	second := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderClaude})
+	if gaps := logger.conversations[second].Metadata.CaptureGaps; len(gaps) != 1 || gaps[0].End != nil {
+		t.Errorf("Expected an open gap from the start, got %+v", gaps)
+	}
+	if len(logger.conversations[convID].Metadata.CaptureGaps) != 1 {
+		t.Error("Expected the earlier conversation's gaps unchanged")
+	}
+}
@@ -3,10 +3,11 @@ package am
 
 import (
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
 )
 
 // parseScreenSnapshotsToTurns extracts conversation turns from TUI screen snapshots.
@@ -16,7 +17,7 @@ func (l *LLMLogger) parseScreenSnapshotsToTurns(snapshots []ScreenSnapshot, prov
 		return []ConversationTurn{}
 	}
 
-	log.Printf("[TUI Parser] Parsing %d snapshots for provider: %s", len(snapshots), provider)
+	logging.Debugf("[TUI Parser] Parsing %d snapshots for provider: %s", len(snapshots), provider)
 
 	switch provider {
 	case "github-copilot":
@@ -52,7 +53,7 @@ func parseCopilotSnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 				CaptureMethod:   "tui_snapshot",
 				ParseConfidence: 0.7,
 			})
-			log.Printf("[TUI Parser] Copilot turn %d: user prompt detected", i)
+			logging.Debugf("[TUI Parser] Copilot turn %d: user prompt detected", i)
 		}
 		
 		// Try to identify if this snapshot contains an AI response
@@ -66,11 +67,11 @@ func parseCopilotSnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 				CaptureMethod:   "tui_snapshot",
 				ParseConfidence: 0.7,
 			})
-			log.Printf("[TUI Parser] Copilot turn %d: AI response detected", i)
+			logging.Debugf("[TUI Parser] Copilot turn %d: AI response detected", i)
 		}
 	}
 	
-	log.Printf("[TUI Parser] Extracted %d turns from Copilot snapshots", len(turns))
+	logging.Debugf("[TUI Parser] Extracted %d turns from Copilot snapshots", len(turns))
 	return turns
 }
 
@@ -143,7 +144,7 @@ func parseClaudeSnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 	turns := []ConversationTurn{}
 	
 	for i, snapshot := range snapshots {
-		content := snapshot.CleanedContent
+		content, tools := extractClaudeToolUses(snapshot.CleanedContent)
 		
 		// Claude TUI patterns (similar to Copilot but different markers)
 		userPrompt := extractGenericUserPrompt(content, []string{">", "You:", "User:"})
@@ -156,11 +157,11 @@ func parseClaudeSnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 				CaptureMethod:   "tui_snapshot",
 				ParseConfidence: 0.7,
 			})
-			log.Printf("[TUI Parser] Claude turn %d: user prompt detected", i)
+			logging.Debugf("[TUI Parser] Claude turn %d: user prompt detected", i)
 		}
 		
 		aiResponse := extractGenericAIResponse(content, []string{"Claude:", "Assistant:", "AI:"})
-		if aiResponse != "" {
+		if aiResponse != "" || len(tools) > 0 {
 			turns = append(turns, ConversationTurn{
 				Role:            "assistant",
 				Content:         aiResponse,
@@ -168,12 +169,13 @@ func parseClaudeSnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 				Provider:        "claude",
 				CaptureMethod:   "tui_snapshot",
 				ParseConfidence: 0.7,
+				Tools:           tools,
 			})
-			log.Printf("[TUI Parser] Claude turn %d: AI response detected", i)
+			logging.Debugf("[TUI Parser] Claude turn %d: AI response detected", i)
 		}
 	}
 	
-	log.Printf("[TUI Parser] Extracted %d turns from Claude snapshots", len(turns))
+	logging.Debugf("[TUI Parser] Extracted %d turns from Claude snapshots", len(turns))
 	return turns
 }
 
@@ -203,7 +205,7 @@ func parseAiderSnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 			}
 		}
 		
-		log.Printf("[TUI Parser] Aider snapshot %d: parsed %d turns", i, len(turns))
+		logging.Debugf("[TUI Parser] Aider snapshot %d: parsed %d turns", i, len(turns))
 	}
 	
 	return turns
@@ -234,7 +236,7 @@ func parseGenericTUISnapshots(snapshots []ScreenSnapshot) []ConversationTurn {
 		})
 	}
 	
-	log.Printf("[TUI Parser] Generic parsing: extracted %d turn snapshots", len(turns))
+	logging.Debugf("[TUI Parser] Generic parsing: extracted %d turn snapshots", len(turns))
 	return turns
 }
 
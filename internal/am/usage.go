@@ -0,0 +1,87 @@
+package am
+
+import (
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+// UsageTotals adds up the estimated tokens and spend of some conversations.
+// Tokens are estimated from the captured text; EstimatedCost covers
+// providers with a price (see llm.SetPrices), and UnpricedTokens counts the
+// rest.
+type UsageTotals struct {
+	Conversations  int     `json:"conversations"`
+	InputTokens    int     `json:"inputTokens"`
+	OutputTokens   int     `json:"outputTokens"`
+	EstimatedCost  float64 `json:"estimatedCost"`
+	UnpricedTokens int     `json:"unpricedTokens"`
+}
+
+// UsageReport is estimated AI usage over a period, by project, day and
+// provider.
+type UsageReport struct {
+	Since      time.Time               `json:"since"`
+	Until      time.Time               `json:"until"`
+	Total      UsageTotals             `json:"total"`
+	ByProject  map[string]*UsageTotals `json:"byProject"`
+	ByDay      map[string]*UsageTotals `json:"byDay"` // YYYY-MM-DD, local time
+	ByProvider map[string]*UsageTotals `json:"byProvider"`
+}
+
+// add counts one conversation.
+func (t *UsageTotals) add(input, output int, cost float64, priced bool) {
+	t.Conversations++
+	t.InputTokens += input
+	t.OutputTokens += output
+	if priced {
+		t.EstimatedCost += cost
+	} else {
+		t.UnpricedTokens += input + output
+	}
+}
+
+// Usage estimates the tokens and spend of the conversations saved in amDir
+// that started in [since, until). User turns count as input and assistant
+// turns as output.
+func Usage(amDir string, since, until time.Time) *UsageReport {
+	if amDir == "" {
+		amDir = DefaultAMDir()
+	}
+	report := &UsageReport{
+		Since:      since,
+		Until:      until,
+		ByProject:  map[string]*UsageTotals{},
+		ByDay:      map[string]*UsageTotals{},
+		ByProvider: map[string]*UsageTotals{},
+	}
+	totalsIn := func(group map[string]*UsageTotals, key string) *UsageTotals {
+		if group[key] == nil {
+			group[key] = &UsageTotals{}
+		}
+		return group[key]
+	}
+
+	for _, conv := range conversationFiles(amDir) {
+		if conv.StartTime.Before(since) || !conv.StartTime.Before(until) {
+			continue
+		}
+		input, output := 0, 0
+		for _, turn := range conv.Turns {
+			switch turn.Role {
+			case "user":
+				input += llm.EstimateTokens(turn.Content)
+			case "assistant":
+				output += llm.EstimateTokens(turn.Content)
+			}
+		}
+		price, priced := llm.PriceFor(conv.Provider, conv.Model)
+		cost := price.Cost(input, output)
+
+		report.Total.add(input, output, cost, priced)
+		totalsIn(report.ByProject, conv.GetProjectName()).add(input, output, cost, priced)
+		totalsIn(report.ByDay, conv.StartTime.Local().Format(digestDateFormat)).add(input, output, cost, priced)
+		totalsIn(report.ByProvider, conv.Provider).add(input, output, cost, priced)
+	}
+	return report
+}
@@ -0,0 +1,100 @@
+package am
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestUsage(t *testing.T) {
+	amDir := t.TempDir()
+	defer llm.SetPrices(nil)
+	if err := llm.SetPrices(map[string]llm.Price{"claude": {InputPer1K: 1, OutputPer1K: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	save := func(id, provider string, started time.Time) {
+		conv := &LLMConversation{
+			ConversationID: id,
+			TabID:          "tab-1",
+			Provider:       provider,
+			StartTime:      started,
+			Metadata:       &ConversationMetadata{WorkingDirectory: "/src/app"},
+			Turns: []ConversationTurn{
+				{Role: "user", Content: strings.Repeat("q", 4000)},      // 1,000 tokens
+				{Role: "assistant", Content: strings.Repeat("a", 8000)}, // 2,000 tokens
+				{Role: "system", Content: strings.Repeat("s", 4000)},
+			},
+		}
+		data, err := json.Marshal(conv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(amDir, (&LLMLogger{}).generateConversationFilename(conv)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save("conv-1111111111", "claude", now)
+	save("conv-2222222222", "github-copilot", now)
+	save("conv-3333333333", "claude", now.AddDate(0, 0, -40))
+
+	report := Usage(amDir, now.AddDate(0, 0, -30), now.Add(time.Minute))
+	if report.Total.Conversations != 2 || report.Total.InputTokens != 2000 || report.Total.OutputTokens != 4000 {
+		t.Errorf("Unexpected totals: %+v", report.Total)
+	}
+	if math.Abs(report.Total.EstimatedCost-5) > 1e-9 || report.Total.UnpricedTokens != 3000 {
+		t.Errorf("Expected $5 for claude and copilot unpriced, got %+v", report.Total)
+	}
+	if claude := report.ByProvider["claude"]; claude == nil || claude.Conversations != 1 {
+		t.Errorf("Unexpected claude totals: %+v", claude)
+	}
+	if day := report.ByDay[now.Format(digestDateFormat)]; day == nil || day.Conversations != 2 {
+		t.Errorf("Unexpected totals for today: %+v", day)
+	}
+	if app := report.ByProject["app"]; app == nil || app.Conversations != 2 {
+		t.Errorf("Unexpected project totals: %+v", report.ByProject)
+	}
+}
+
+func TestUsage_ModelPrices(t *testing.T) {
+	amDir := t.TempDir()
+	defer llm.SetPrices(nil)
+	err := llm.SetPrices(map[string]llm.Price{"claude-code": {
+		InputPer1K: 1,
+		Models:     map[string]llm.Price{"opus": {InputPer1K: 5}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i, model := range []string{"", "claude-opus-4-1"} {
+		conv := &LLMConversation{
+			ConversationID: fmt.Sprintf("conv-%d%d%d", i, i, now.Unix()),
+			Provider:       "claude",
+			Model:          model,
+			StartTime:      now,
+			Turns:          []ConversationTurn{{Role: "user", Content: strings.Repeat("q", 4000)}},
+		}
+		data, err := json.Marshal(conv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(amDir, (&LLMLogger{}).generateConversationFilename(conv)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := Usage(amDir, now.Add(-time.Minute), now.Add(time.Minute))
+	if math.Abs(report.Total.EstimatedCost-6) > 1e-9 {
+		t.Errorf("Expected $1 at claude's price and $5 at opus's, got %+v", report.Total)
+	}
+}
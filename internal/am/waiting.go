@@ -0,0 +1,76 @@
+package am
+
+import (
+	"time"
+)
+
+// WaitPeriod is a stretch of a conversation in which a command in the tab
+// was waiting for the user to type a password or answer a question.
+type WaitPeriod struct {
+	Kind   string     `json:"kind"` // "password", "host_key" or "confirm"
+	Prompt string     `json:"prompt"`
+	Start  time.Time  `json:"start"`
+	End    *time.Time `json:"end,omitempty"` // Unset until the user answers
+}
+
+// MarkWaitingForInput records that a command in the tab stopped at an
+// interactive prompt, publishing an INPUT_WAITING event and, during a
+// conversation, starting a wait period in its metadata. The wait ends at
+// the user's next input.
+func (l *LLMLogger) MarkWaitingForInput(kind, prompt string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prompt = redact(prompt)
+	conv := l.conversations[l.activeConvID]
+	convID, provider := "", ""
+	if conv != nil {
+		convID, provider = conv.ConversationID, conv.Provider
+	}
+	EventBus.Publish(&LayerEvent{
+		Type:      "INPUT_WAITING",
+		Layer:     1,
+		TabID:     l.tabID,
+		ConvID:    convID,
+		Provider:  provider,
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			"kind":   kind,
+			"prompt": prompt,
+		},
+	})
+	if conv == nil || IsPaused() || waitingIn(conv) {
+		return
+	}
+
+	metadata := ConversationMetadata{}
+	if conv.Metadata != nil {
+		metadata = *conv.Metadata
+	}
+	metadata.WaitPeriods = append(append([]WaitPeriod(nil), metadata.WaitPeriods...), WaitPeriod{Kind: kind, Prompt: prompt, Start: time.Now()})
+	conv.Metadata = &metadata
+	l.saveConversation(conv)
+}
+
+// endWaitLocked ends the active conversation's wait period, if one is open.
+// Must be called with lock held.
+func (l *LLMLogger) endWaitLocked() {
+	conv := l.conversations[l.activeConvID]
+	if conv == nil || !waitingIn(conv) {
+		return
+	}
+	metadata := *conv.Metadata
+	metadata.WaitPeriods = append([]WaitPeriod(nil), metadata.WaitPeriods...)
+	now := time.Now()
+	metadata.WaitPeriods[len(metadata.WaitPeriods)-1].End = &now
+	conv.Metadata = &metadata
+	l.saveConversation(conv)
+}
+
+// waitingIn reports whether conv has an open wait period.
+func waitingIn(conv *LLMConversation) bool {
+	if conv.Metadata == nil || len(conv.Metadata.WaitPeriods) == 0 {
+		return false
+	}
+	return conv.Metadata.WaitPeriods[len(conv.Metadata.WaitPeriods)-1].End == nil
+}
@@ -0,0 +1,56 @@
+package am
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestMarkWaitingForInput(t *testing.T) {
+	defer WaitForPendingWrites()
+
+	var mu sync.Mutex
+	var events []*LayerEvent
+	EventBus.Subscribe(func(e *LayerEvent) {
+		if e.Type == "INPUT_WAITING" && e.TabID == "waiting-tab" {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}
+	})
+
+	logger := &LLMLogger{tabID: "waiting-tab", conversations: make(map[string]*LLMConversation), amDir: t.TempDir()}
+	logger.SetWorkingDirectory(t.TempDir())
+
+	// Outside a conversation only the event is published
+	logger.MarkWaitingForInput("confirm", "Ok to proceed? (y)")
+
+	convID := logger.StartConversation(&llm.DetectedCommand{Provider: llm.ProviderAider, Prompt: "add tests"})
+	logger.MarkWaitingForInput("password", "[sudo] password for mike:")
+	logger.MarkWaitingForInput("password", "[sudo] password for mike:")
+	conv := logger.conversations[convID]
+	if periods := conv.Metadata.WaitPeriods; len(periods) != 1 || periods[0].Kind != "password" || periods[0].End != nil {
+		t.Fatalf("Expected one open wait period, got %+v", periods)
+	}
+
+	logger.AddUserInput("x")
+	if end := conv.Metadata.WaitPeriods[0].End; end == nil || end.Before(conv.Metadata.WaitPeriods[0].Start) {
+		t.Errorf("Expected the wait ended by input, got %+v", conv.Metadata.WaitPeriods)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 3 INPUT_WAITING events, got %d", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,127 @@
+package ansi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxAlertText bounds the OSC text kept while scanning for an alert
+const maxAlertText = 1024
+
+// conEmuCommand matches OSC 9 text that is a ConEmu or Windows Terminal
+// command (progress, working directory, ...) rather than a message
+var conEmuCommand = regexp.MustCompile(`^\d+(;|$)`)
+
+// Alert is a request for the user's attention found in terminal output.
+type Alert struct {
+	Sequence string // "BEL", "OSC 9" or "OSC 777"
+	Title    string // Only OSC 777 carries a title
+	Body     string // Empty for a plain BEL
+}
+
+// scanner states
+const (
+	stateGround = iota
+	stateEscape
+	stateCSI
+	stateOSC
+	stateOSCEscape
+	stateString
+	stateStringEscape
+)
+
+// AlertScanner finds alerts in PTY output read in pieces: BEL characters
+// outside escape sequences, iTerm2's OSC 9 notifications and rxvt's
+// OSC 777 ;notify ones. A sequence split between reads is found when its
+// end arrives. Only the 7-bit (ESC) forms are recognised, since raw C1
+// bytes can't be told apart from UTF-8 one read at a time.
+type AlertScanner struct {
+	state int
+	text  []byte // The OSC sequence so far
+}
+
+// Feed scans the next piece of output and returns the alerts it completes.
+func (s *AlertScanner) Feed(data []byte) []Alert {
+	var alerts []Alert
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch s.state {
+		case stateGround:
+			if b == esc {
+				s.state = stateEscape
+			} else if b == bel {
+				alerts = append(alerts, Alert{Sequence: "BEL"})
+			}
+		case stateEscape:
+			switch b {
+			case ']':
+				s.state, s.text = stateOSC, s.text[:0]
+			case '[':
+				s.state = stateCSI
+			case 'P', 'X', '^', '_':
+				s.state = stateString
+			case esc: // The second ESC starts the sequence
+			default:
+				s.state = stateGround
+			}
+		case stateCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = stateGround
+			} else if b < 0x20 || b > 0x3f {
+				s.state = stateGround
+				i-- // A control ends the sequence early and counts on its own
+			}
+		case stateOSC:
+			switch b {
+			case bel:
+				alerts = s.endOSC(alerts)
+			case esc:
+				s.state = stateOSCEscape
+			default:
+				if len(s.text) < maxAlertText {
+					s.text = append(s.text, b)
+				}
+			}
+		case stateOSCEscape:
+			if b == '\\' {
+				alerts = s.endOSC(alerts)
+			} else {
+				s.state = stateEscape // ESC aborts the OSC and starts a sequence
+				i--
+			}
+		case stateString:
+			if b == esc {
+				s.state = stateStringEscape
+			}
+		case stateStringEscape:
+			if b == '\\' {
+				s.state = stateGround
+			} else {
+				s.state = stateEscape
+				i--
+			}
+		}
+	}
+	return alerts
+}
+
+// endOSC finishes the OSC sequence being read, adding it to alerts if it is
+// a notification.
+func (s *AlertScanner) endOSC(alerts []Alert) []Alert {
+	s.state = stateGround
+	text := string(s.text)
+	switch {
+	case strings.HasPrefix(text, "9;"):
+		body := strings.TrimSpace(Strip(text[len("9;"):]))
+		if body != "" && !conEmuCommand.MatchString(body) {
+			alerts = append(alerts, Alert{Sequence: "OSC 9", Body: body})
+		}
+	case strings.HasPrefix(text, "777;notify;"):
+		title, body, _ := strings.Cut(text[len("777;notify;"):], ";")
+		title, body = strings.TrimSpace(Strip(title)), strings.TrimSpace(Strip(body))
+		if title != "" || body != "" {
+			alerts = append(alerts, Alert{Sequence: "OSC 777", Title: title, Body: body})
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,65 @@
+package ansi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlertScanner(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Alert
+	}{
+		{"plain output", "hello\r\n", nil},
+		{"bell", "done\a", []Alert{{Sequence: "BEL"}}},
+		{"two bells", "\a\a", []Alert{{Sequence: "BEL"}, {Sequence: "BEL"}}},
+		{"title bel is not a bell", "\x1b]0;user@host: ~\a$ ", nil},
+		{"other osc st", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", nil},
+		{"dcs", "\x1bPq\a#0\x1b\\", nil},
+		{"osc 9 bel", "\x1b]9;Build finished\a", []Alert{{Sequence: "OSC 9", Body: "Build finished"}}},
+		{"osc 9 st", "\x1b]9;Tests passed\x1b\\", []Alert{{Sequence: "OSC 9", Body: "Tests passed"}}},
+		{"osc 9 progress", "\x1b]9;4;1;50\a", nil},
+		{"osc 9 directory", "\x1b]9;9;C:\\src\a", nil},
+		{"osc 9 message with a number", "\x1b]9;3 tests failed\a", []Alert{{Sequence: "OSC 9", Body: "3 tests failed"}}},
+		{"osc 777", "\x1b]777;notify;Deploy;Finished in 42s\a", []Alert{{Sequence: "OSC 777", Title: "Deploy", Body: "Finished in 42s"}}},
+		{"osc 777 body with semicolons", "\x1b]777;notify;Job;a;b\x1b\\", []Alert{{Sequence: "OSC 777", Title: "Job", Body: "a;b"}}},
+		{"osc 777 other command", "\x1b]777;preexec\a", nil},
+		{"controls stripped", "\x1b]9;line\x01 one\a", []Alert{{Sequence: "OSC 9", Body: "line one"}}},
+		{"esc aborts osc", "\x1b]9;lost\x1b[31m\a", []Alert{{Sequence: "BEL"}}},
+		{"csi then bell", "\x1b[1;31mred\x1b[0m\a", []Alert{{Sequence: "BEL"}}},
+		{"csi aborted by bell", "\x1b[1\a", []Alert{{Sequence: "BEL"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s AlertScanner
+			if got := s.Feed([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Feed(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertScanner_SplitReads(t *testing.T) {
+	var s AlertScanner
+	var got []Alert
+	for _, chunk := range []string{"\x1b", "]9;Bui", "ld done\x1b", "\\", "\x1b]0;title", "\a", "\a"} {
+		got = append(got, s.Feed([]byte(chunk))...)
+	}
+	want := []Alert{{Sequence: "OSC 9", Body: "Build done"}, {Sequence: "BEL"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+}
+
+func TestAlertScanner_LongOSC(t *testing.T) {
+	var s AlertScanner
+	long := make([]byte, 10*maxAlertText)
+	for i := range long {
+		long[i] = 'x'
+	}
+	alerts := s.Feed(append(append([]byte("\x1b]9;"), long...), '\a'))
+	if len(alerts) != 1 || len(alerts[0].Body) != maxAlertText-len("9;") {
+		t.Errorf("Expected one alert with the text cut short, got %d", len(alerts))
+	}
+}
@@ -0,0 +1,189 @@
+// Package ansi removes terminal escape sequences and control characters
+// from PTY output and input, so captured text can be parsed and saved, and
+// finds the alerts programs raise with them.
+package ansi
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	esc = 0x1b
+	bel = 0x07
+
+	// 8-bit (C1) equivalents of ESC P, ESC X, ESC [, ESC \, ESC ], ESC ^
+	// and ESC _
+	c1DCS = 0x90
+	c1SOS = 0x98
+	c1CSI = 0x9b
+	c1ST  = 0x9c
+	c1OSC = 0x9d
+	c1PM  = 0x9e
+	c1APC = 0x9f
+)
+
+// orphanedCSI matches what is left of a CSI sequence whose ESC was lost,
+// such as "[?25l" when output is split between reads
+var orphanedCSI = regexp.MustCompile(`\[\??[0-9;]*[a-zA-Z]`)
+
+// Strip removes escape sequences from text: CSI, OSC, DCS, SOS, PM and APC
+// sequences and two-byte escapes, in their 7-bit (ESC) and 8-bit (C1) forms,
+// whether the C1 controls arrive as UTF-8 or as raw bytes. Control
+// characters other than newline, carriage return and tab are removed too,
+// as is invalid UTF-8. A sequence cut off by the end of text is removed up
+// to the end.
+func Strip(text string) string {
+	var out strings.Builder
+	out.Grow(len(text))
+	for i := 0; i < len(text); {
+		r, size := decode(text, i)
+		switch {
+		case r == esc:
+			i = skipEscape(text, i+size)
+		case r == c1CSI:
+			i = skipCSI(text, i+size)
+		case r == c1OSC:
+			i = skipString(text, i+size, true)
+		case r == c1DCS || r == c1SOS || r == c1PM || r == c1APC:
+			i = skipString(text, i+size, false)
+		case r == '\n' || r == '\r' || r == '\t' || (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r != utf8.RuneError):
+			out.WriteString(text[i : i+size])
+			i += size
+		case r == utf8.RuneError && size == 3:
+			out.WriteRune(r) // A literal replacement character
+			i += size
+		default:
+			i += size
+		}
+	}
+	return out.String()
+}
+
+// Clean is Strip for PTY output that may be read in pieces: it also removes
+// CSI sequences that lost their ESC, like "[?2004h".
+func Clean(text string) string {
+	return orphanedCSI.ReplaceAllString(Strip(text), "")
+}
+
+// HasArtifacts reports whether text holds anything Clean would remove.
+func HasArtifacts(text string) bool {
+	return Strip(text) != text || orphanedCSI.MatchString(text)
+}
+
+// CollapseOverwrites reduces lines redrawn in place, such as progress bars
+// and spinners, to what the terminal finally showed: of the parts of a line
+// separated by carriage returns the last with visible text is kept, and
+// backspaces delete the character before them. CRLF line endings are kept as
+// plain newlines.
+func CollapseOverwrites(text string) string {
+	if !strings.ContainsAny(text, "\r\b") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = finalState(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// finalState returns what is left of one line after its redraws.
+func finalState(line string) string {
+	parts := strings.Split(strings.TrimRight(line, "\r"), "\r")
+	line = parts[len(parts)-1]
+	for i := len(parts) - 1; i >= 0; i-- {
+		if strings.TrimSpace(Strip(parts[i])) != "" {
+			line = parts[i]
+			break
+		}
+	}
+	if !strings.Contains(line, "\b") {
+		return line
+	}
+	var out []rune
+	for _, r := range line {
+		if r != '\b' {
+			out = append(out, r)
+		} else if len(out) > 0 {
+			out = out[:len(out)-1]
+		}
+	}
+	return string(out)
+}
+
+// decode returns the rune at text[i] and its size. A byte that is not valid
+// UTF-8 is returned as itself, so raw 8-bit controls can be recognised, or
+// as utf8.RuneError when it is not one.
+func decode(text string, i int) (rune, int) {
+	r, size := utf8.DecodeRuneInString(text[i:])
+	if r == utf8.RuneError && size == 1 {
+		if b := text[i]; b >= 0x80 && b <= 0x9f {
+			return rune(b), 1
+		}
+	}
+	return r, size
+}
+
+// skipEscape returns the end of the escape sequence whose ESC ends just
+// before text[i].
+func skipEscape(text string, i int) int {
+	if i >= len(text) {
+		return i
+	}
+	switch b := text[i]; {
+	case b == '[':
+		return skipCSI(text, i+1)
+	case b == ']':
+		return skipString(text, i+1, true)
+	case b == 'P' || b == 'X' || b == '^' || b == '_':
+		return skipString(text, i+1, false)
+	case b >= 0x20 && b <= 0x2f:
+		// Intermediate bytes and a final byte, as in ESC ( B
+		for i < len(text) && text[i] >= 0x20 && text[i] <= 0x2f {
+			i++
+		}
+		if i < len(text) && text[i] >= 0x30 && text[i] <= 0x7e {
+			i++
+		}
+		return i
+	case b >= 0x30 && b <= 0x7e:
+		return i + 1 // ESC 7, ESC M, ESC =, ...
+	default:
+		return i // A lone ESC
+	}
+}
+
+// skipCSI returns the end of the CSI sequence whose parameters start at
+// text[i]. A byte that cannot be part of the sequence ends it early.
+func skipCSI(text string, i int) int {
+	for i < len(text) && text[i] >= 0x20 && text[i] <= 0x3f {
+		i++ // Parameter and intermediate bytes
+	}
+	if i < len(text) && text[i] >= 0x40 && text[i] <= 0x7e {
+		i++
+	}
+	return i
+}
+
+// skipString returns the end of the control string (OSC, DCS, ...) whose
+// content starts at text[i]. It ends at ST, or at BEL for OSC. Any other
+// ESC ends it and starts a sequence of its own.
+func skipString(text string, i int, belEnds bool) int {
+	for i < len(text) {
+		r, size := decode(text, i)
+		switch {
+		case r == bel && belEnds:
+			return i + size
+		case r == c1ST:
+			return i + size
+		case r == esc:
+			if i+1 < len(text) && text[i+1] == '\\' {
+				return i + 2
+			}
+			return i
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,102 @@
+package ansi
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"plain", "hello, world\r\n\tdone", "hello, world\r\n\tdone"},
+		{"unicode", "héllo ✓ 日本 �", "héllo ✓ 日本 �"},
+		{"color", "\x1b[1;31mred\x1b[0m text", "red text"},
+		{"private mode", "\x1b[?25l\x1b[?2004hhidden\x1b[?2004l", "hidden"},
+		{"intermediate", "\x1b[2 qblock", "block"},
+		{"osc bel", "\x1b]0;my title\x07prompt", "prompt"},
+		{"osc st", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"osc query", "\x1b]11;?\x07bg", "bg"},
+		{"dcs", "\x1bPq#0;2;0;0;0\x1b\\after", "after"},
+		{"apc", "\x1b_Gf=100;AAAA\x1b\\image", "image"},
+		{"two byte", "\x1b7saved\x1b8\x1bM", "saved"},
+		{"charset", "\x1b(Bascii", "ascii"},
+		{"c1 utf-8", "\u009b31mred\u009b0m \u009d0;title\u009cok", "red ok"},
+		{"c1 raw bytes", "\x9b31mred\x9b0m \x9d0;title\x07ok", "red ok"},
+		{"controls", "bell\x07 back\x08 del\x7f nul\x00", "bell back del nul"},
+		{"invalid utf-8", "a\xffb\xc3", "ab"},
+		{"truncated csi", "text\x1b[1;3", "text"},
+		{"truncated osc", "text\x1b]0;unfinished", "text"},
+		{"lone esc", "text\x1b", "text"},
+		{"esc aborts string", "\x1b]0;title\x1b[31mred", "red"},
+		{"csi aborted by control", "\x1b[1\nnext", "\nnext"},
+		{"brackets kept", "arr[i] = [?]", "arr[i] = [?]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Strip(tt.input); got != tt.want {
+				t.Errorf("Strip(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	if got := Clean("[?25l Welcome \x1b[?25h[0m"); got != " Welcome " {
+		t.Errorf("Expected orphaned sequences removed, got %q", got)
+	}
+	if !HasArtifacts("done[?2004h") || !HasArtifacts("\x1b[0mdone") || HasArtifacts("done\n") {
+		t.Error("Unexpected HasArtifacts result")
+	}
+}
+
+func TestCollapseOverwrites(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"plain", "one\ntwo", "one\ntwo"},
+		{"crlf", "one\r\ntwo\r\n", "one\ntwo\n"},
+		{"progress", "npm install\r\n[#---] 25%\r[##--] 50%\r[####] 100%\r\ndone", "npm install\n[####] 100%\ndone"},
+		{"cleared line", "Downloading 40%\r\x1b[2K\rDone\n", "Done\n"},
+		{"trailing redraw clear", "Compiled ok\r   \r", "Compiled ok"},
+		{"backspace spinner", "Working |\b/\b-\b\\\bdone", "Working done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CollapseOverwrites(tt.input); got != tt.want {
+				t.Errorf("CollapseOverwrites(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzStrip(f *testing.F) {
+	for _, seed := range []string{
+		"\x1b[1;31mred\x1b[0m",
+		"\x1b]0;title\x07",
+		"\x1bPq\x1b\\",
+		"\u009b31m\u009d0;t\u009c",
+		"\x9b1m\x9c",
+		"plain text ✓",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		out := Strip(input)
+		if !utf8.ValidString(out) {
+			t.Fatalf("Strip(%q) = %q, not valid UTF-8", input, out)
+		}
+		for _, r := range out {
+			if (r < 0x20 && r != '\n' && r != '\r' && r != '\t') || (r >= 0x7f && r < 0xa0) {
+				t.Fatalf("Strip(%q) = %q, contains control %U", input, out, r)
+			}
+		}
+		if again := Strip(out); again != out {
+			t.Fatalf("Strip not idempotent: %q then %q", out, again)
+		}
+		if strings.ContainsRune(Clean(input), esc) {
+			t.Fatalf("Clean(%q) kept an ESC", input)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Package api versions Forge's REST routes. Routes are served under /api/v1;
+// the unversioned /api paths the frontend and existing scripts use are
+// rewritten onto them by Compat. Every route is registered with a Doc, from
+// which the OpenAPI document at /api/v1/spec is generated.
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Prefix is where the current API version is served
+	Prefix = "/api/v1"
+	// LegacyPrefix is the unversioned prefix kept working by Compat
+	LegacyPrefix = "/api"
+)
+
+// Doc describes a route for the OpenAPI document.
+type Doc struct {
+	Methods  string      // Space-separated, e.g. "GET POST"
+	Summary  string      // One line, shown in API tooling
+	Query    []string    // Query parameters the route reads
+	Request  interface{} // Zero value of the JSON body type for POST/PUT, if named
+	Response interface{} // Zero value of the JSON response type, if named
+	Stream   bool        // Responds with a text/event-stream
+}
+
+// Route is a registered route. Path is relative to Prefix; a trailing slash
+// serves the whole subtree, as with http.ServeMux.
+type Route struct {
+	Path string
+	Doc
+}
+
+// Router registers versioned routes on a mux and records their docs.
+type Router struct {
+	mux  *http.ServeMux
+	wrap func(http.HandlerFunc) http.HandlerFunc
+
+	mu     sync.Mutex
+	routes []Route
+}
+
+// NewRouter creates a router registering on mux, wrapping every handler with
+// wrap (nil for none).
+func NewRouter(mux *http.ServeMux, wrap func(http.HandlerFunc) http.HandlerFunc) *Router {
+	if wrap == nil {
+		wrap = func(h http.HandlerFunc) http.HandlerFunc { return h }
+	}
+	return &Router{mux: mux, wrap: wrap}
+}
+
+// HandleFunc serves handler at Prefix+path.
+func (r *Router) HandleFunc(path string, handler http.HandlerFunc, doc Doc) {
+	r.mux.HandleFunc(Prefix+path, r.wrap(handler))
+	r.mu.Lock()
+	r.routes = append(r.routes, Route{Path: path, Doc: doc})
+	r.mu.Unlock()
+}
+
+// Routes returns the registered routes sorted by path.
+func (r *Router) Routes() []Route {
+	r.mu.Lock()
+	routes := append([]Route(nil), r.routes...)
+	r.mu.Unlock()
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+	return routes
+}
+
+// Compat serves unversioned /api paths from their /api/v1 routes. Responses
+// carry a Deprecation header and a Link to the versioned path so clients
+// can migrate.
+func Compat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versioned, ok := Versioned(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+versioned+`>; rel="successor-version"`)
+
+		// Shallow copy with the rewritten path, as http.StripPrefix does
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = versioned
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// Versioned maps a legacy /api path to its /api/v1 equivalent. It reports
+// false for paths that are already versioned or outside /api.
+func Versioned(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, LegacyPrefix+"/")
+	if !ok || path == Prefix || strings.HasPrefix(path, Prefix+"/") {
+		return path, false
+	}
+	return Prefix + "/" + rest, true
+}
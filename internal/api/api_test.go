@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	Parent  *item     `json:"parent,omitempty"`
+	secret  string
+	Skipped string `json:"-"`
+}
+
+func newTestRouter() (*Router, *http.ServeMux) {
+	mux := http.NewServeMux()
+	r := NewRouter(mux, func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Wrapped", "1")
+			h(w, req)
+		}
+	})
+	echo := func(w http.ResponseWriter, req *http.Request) { w.Write([]byte(req.URL.Path)) }
+	r.HandleFunc("/items", echo, Doc{Methods: "GET POST", Summary: "List or replace items", Query: []string{"tag"}, Request: []item{}, Response: []item{}})
+	r.HandleFunc("/items/", echo, Doc{Methods: "GET DELETE", Summary: "One item"})
+	r.HandleFunc("/events", echo, Doc{Methods: "GET", Summary: "Item changes", Stream: true})
+	return r, mux
+}
+
+func TestVersioned(t *testing.T) {
+	tests := []struct {
+		path, want string
+		ok         bool
+	}{
+		{"/api/commands", "/api/v1/commands", true},
+		{"/api/sessions/work/switch", "/api/v1/sessions/work/switch", true},
+		{"/api/v1/commands", "/api/v1/commands", false},
+		{"/api/v1", "/api/v1", false},
+		{"/api", "/api", false},
+		{"/ws", "/ws", false},
+		{"/apis/x", "/apis/x", false},
+	}
+	for _, tt := range tests {
+		got, ok := Versioned(tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Versioned(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCompat(t *testing.T) {
+	_, mux := newTestRouter()
+	handler := Compat(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/items", nil))
+	if rec.Body.String() != "/api/v1/items" || rec.Header().Get("Deprecation") != "" {
+		t.Errorf("Versioned request: body %q, Deprecation %q", rec.Body.String(), rec.Header().Get("Deprecation"))
+	}
+	if rec.Header().Get("X-Wrapped") != "1" {
+		t.Error("Expected handler to be wrapped")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/items/42?x=1", nil))
+	if rec.Body.String() != "/api/v1/items/42" {
+		t.Errorf("Expected legacy path to reach the versioned route, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Link") != `</api/v1/items/42>; rel="successor-version"` {
+		t.Errorf("Expected deprecation headers, got %v", rec.Header())
+	}
+}
+
+func TestSpec(t *testing.T) {
+	r, _ := newTestRouter()
+	data, err := json.Marshal(r.Spec("1.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Round-trip through JSON to check the document as clients see it
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.OpenAPI != "3.0.3" || spec.Info.Version != "1.2.3" || spec.Servers[0].URL != Prefix {
+		t.Errorf("Unexpected header fields: %+v %+v", spec.Info, spec.Servers)
+	}
+
+	items := spec.Paths["/items"]
+	if len(items) != 2 {
+		t.Fatalf("Expected get and post on /items, got %v", items)
+	}
+	get, post := items["get"], items["post"]
+	if get.OperationID != "getItems" || get.Tags[0] != "items" || get.Parameters[0].Name != "tag" {
+		t.Errorf("Unexpected get operation %+v", get)
+	}
+	if get.RequestBody != nil {
+		t.Error("Expected no request body on GET")
+	}
+	body := post.RequestBody.Content["application/json"].Schema
+	if body.Type != "array" || body.Items.Ref != "#/components/schemas/api.item" {
+		t.Errorf("Unexpected request schema %+v", body)
+	}
+
+	schema := spec.Components.Schemas["api.item"]
+	if schema == nil {
+		t.Fatal("Expected api.item component")
+	}
+	for name, want := range map[string]string{"id": "integer", "name": "string", "tags": "array", "created": "string"} {
+		if got := schema.Properties[name]; got == nil || got.Type != want {
+			t.Errorf("Property %s: expected %s, got %+v", name, want, got)
+		}
+	}
+	if schema.Properties["parent"].Ref != "#/components/schemas/api.item" {
+		t.Error("Expected recursive field to reference the component")
+	}
+	if _, ok := schema.Properties["secret"]; ok {
+		t.Error("Expected unexported field to be skipped")
+	}
+	if _, ok := schema.Properties["Skipped"]; ok {
+		t.Error(`Expected json:"-" field to be skipped`)
+	}
+
+	subtree := spec.Paths["/items/{path}"]
+	if _, ok := subtree["delete"]; !ok || subtree["get"].Parameters[0].In != "path" {
+		t.Errorf("Expected subtree route with a path parameter, got %+v", subtree)
+	}
+	if _, ok := spec.Paths["/events"]["get"].Responses["200"].Content["text/event-stream"]; !ok {
+		t.Error("Expected event stream response")
+	}
+}
+
+func TestHandleSpec(t *testing.T) {
+	r, _ := newTestRouter()
+	rec := httptest.NewRecorder()
+	r.HandleSpec("1.0.0")(rec, httptest.NewRequest("GET", "/api/v1/spec", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Unexpected response %d %v", rec.Code, rec.Header())
+	}
+	var spec Spec
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil || len(spec.Paths) != 3 {
+		t.Errorf("Expected 3 paths, got %d (%v)", len(spec.Paths), err)
+	}
+}
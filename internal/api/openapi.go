@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Spec is an OpenAPI 3.0 document, limited to what Router.Spec produces.
+type Spec struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       SpecInfo                        `json:"info"`
+	Servers    []SpecServer                    `json:"servers"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// SpecInfo is the document's info object
+type SpecInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// SpecServer is a base URL the paths are relative to
+type SpecServer struct {
+	URL string `json:"url"`
+}
+
+// Operation is one method on a path
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	OperationID string              `json:"operationId"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *Body               `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a query or path parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // "query" or "path"
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Body is an operation's request body
+type Body struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is an operation's response
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body for one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the named schemas referenced from operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON Schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// subtreeParam names the path parameter standing in for a subtree route's remainder
+const subtreeParam = "path"
+
+// Spec generates the OpenAPI document for the registered routes.
+func (r *Router) Spec(version string) *Spec {
+	spec := &Spec{
+		OpenAPI:    "3.0.3",
+		Info:       SpecInfo{Title: "Forge Terminal API", Version: version},
+		Servers:    []SpecServer{{URL: Prefix}},
+		Paths:      make(map[string]map[string]Operation),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+	gen := &schemaGen{schemas: spec.Components.Schemas}
+
+	for _, route := range r.Routes() {
+		specPath := route.Path
+		var params []Parameter
+		if strings.HasSuffix(specPath, "/") {
+			specPath += "{" + subtreeParam + "}"
+			params = append(params, Parameter{Name: subtreeParam, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, q := range route.Query {
+			params = append(params, Parameter{Name: q, In: "query", Schema: &Schema{Type: "string"}})
+		}
+
+		ops := make(map[string]Operation)
+		for _, method := range strings.Fields(route.Methods) {
+			op := Operation{
+				Summary:     route.Summary,
+				OperationID: operationID(method, route.Path),
+				Tags:        []string{tag(route.Path)},
+				Parameters:  params,
+				Responses:   map[string]Response{"200": gen.response(route.Doc)},
+			}
+			if route.Request != nil && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch) {
+				op.RequestBody = &Body{
+					Required: true,
+					Content:  map[string]MediaType{"application/json": {Schema: gen.schema(reflect.TypeOf(route.Request))}},
+				}
+			}
+			ops[strings.ToLower(method)] = op
+		}
+		spec.Paths[specPath] = ops
+	}
+	return spec
+}
+
+// HandleSpec serves the OpenAPI document (GET /api/v1/spec)
+func (r *Router) HandleSpec(version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r.Spec(version))
+	}
+}
+
+// tag groups a route by its first path segment
+func tag(routePath string) string {
+	first, _, _ := strings.Cut(strings.TrimPrefix(routePath, "/"), "/")
+	return first
+}
+
+// operationID derives a stable camel-case ID such as getCommandsSchema
+func operationID(method, routePath string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(routePath, func(r rune) bool { return r == '/' || r == '-' || r == '_' }) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaGen converts Go types to schemas, collecting named structs as components
+type schemaGen struct {
+	schemas map[string]*Schema
+}
+
+func (g *schemaGen) response(doc Doc) Response {
+	switch {
+	case doc.Stream:
+		return Response{Description: "Server-sent event stream", Content: map[string]MediaType{
+			"text/event-stream": {Schema: &Schema{Type: "string"}},
+		}}
+	case doc.Response != nil:
+		return Response{Description: "OK", Content: map[string]MediaType{
+			"application/json": {Schema: g.schema(reflect.TypeOf(doc.Response))},
+		}}
+	default:
+		return Response{Description: "OK", Content: map[string]MediaType{
+			"application/json": {Schema: &Schema{Type: "object"}},
+		}}
+	}
+}
+
+func (g *schemaGen) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = &Schema{} // Placeholder so recursive types terminate
+			*g.schemas[name] = *g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{} // Any value
+	}
+}
+
+// object builds a struct's schema from its exported, JSON-visible fields
+func (g *schemaGen) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			// Embedded struct fields are promoted into the parent object
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range g.object(ft).Properties {
+					s.Properties[k] = v
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+	return s
+}
@@ -69,6 +69,7 @@ func (c *EmbeddingsClient) Embed(ctx context.Context, text string) ([]float32, e
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	authorize(httpReq)
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
@@ -174,6 +175,7 @@ func (c *EmbeddingsClient) EnsureModelAvailable(ctx context.Context) bool {
 		return false
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	authorize(httpReq)
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
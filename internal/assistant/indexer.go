@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/gitignore"
 )
 
 // Indexer builds vector indexes from documents.
@@ -139,6 +141,11 @@ func (idx *Indexer) IndexAllContent(ctx context.Context, rootPath string, includ
 func (idx *Indexer) indexPath(ctx context.Context, rootPath, searchPath string, patterns []string, includeGitignored bool) (int, error) {
 	totalDocuments := 0
 
+	var ignore *gitignore.Matcher
+	if !includeGitignored {
+		ignore = gitignore.New(rootPath)
+	}
+
 	err := filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip errors
@@ -150,6 +157,14 @@ func (idx *Indexer) indexPath(ctx context.Context, rootPath, searchPath string,
 			if !includeGitignored && (base == "node_modules" || base == "vendor" || base == ".git" || base == "dist" || base == "build") {
 				return filepath.SkipDir
 			}
+			if ignore != nil && path != searchPath && ignore.Match(path, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Respect .gitignore (including nested files and negations) unless explicitly included
+		if ignore != nil && ignore.Match(path, false) {
 			return nil
 		}
 
@@ -5,13 +5,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/secrets"
 )
 
+// OllamaTokenSecret names the secret sent to Ollama as a bearer token, for a
+// server behind an authenticating proxy; unset, requests carry no credentials
+const OllamaTokenSecret = "assistant/ollama-token"
+
 // OllamaClient handles communication with Ollama API.
 type OllamaClient struct {
 	baseURL string
@@ -76,6 +84,7 @@ func (c *OllamaClient) IsAvailable(ctx context.Context) bool {
 		return false
 	}
 
+	authorize(req)
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return false
@@ -92,6 +101,7 @@ func (c *OllamaClient) GetModels(ctx context.Context) ([]ModelInfo, error) {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	authorize(req)
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
@@ -143,6 +153,7 @@ func (c *OllamaClient) Chat(ctx context.Context, messages []OllamaMessage) (stri
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	authorize(req)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -292,3 +303,17 @@ func formatSize(bytes int64) string {
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// authorize adds the Ollama token from the keychain to req, if one is stored
+func authorize(req *http.Request) {
+	token, err := secrets.Get(OllamaTokenSecret)
+	if err != nil {
+		if !errors.Is(err, secrets.ErrNotFound) {
+			log.Printf("[Assistant] Failed to read the Ollama token: %v", err)
+		}
+		return
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
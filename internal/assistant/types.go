@@ -34,6 +34,7 @@ type TerminalContext struct {
 type ExecuteCommandRequest struct {
 	Command string `json:"command"`
 	TabID   string `json:"tabId"`
+	Confirm bool   `json:"confirm,omitempty"` // Run a command the dangerous-command guard asked to confirm
 }
 
 // ExecuteCommandResponse represents the result of command execution.
@@ -0,0 +1,200 @@
+// Package audit keeps an append-only record of privileged operations Forge
+// performs on the user's machine: deleting files and AM history, running
+// commands for the assistant, auto-responding to AI tools, and installing
+// updates.
+//
+// Events are appended as JSON lines to one file per month under
+// ~/.forge/audit and never rewritten, so the log can be read (or tailed)
+// with ordinary tools.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Audited actions
+const (
+	FileDelete       = "file.delete"       // Moved to the trash, deleted, or purged from the trash
+	AssistantExecute = "assistant.execute" // Command the assistant ran in a tab
+	AutoRespond      = "am.auto-respond"   // Auto-respond turned on or off for a tab's AI tool
+	UpdateApply      = "update.apply"      // Forge binary replaced
+	AMDelete         = "am.delete"         // AM conversation, session log, or workspace history deleted
+)
+
+// Event is one audited operation.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Target string    `json:"target,omitempty"` // File, command, or binary acted on
+	Detail string    `json:"detail,omitempty"`
+	TabID  string    `json:"tabId,omitempty"`
+	Error  string    `json:"error,omitempty"` // Set when the operation failed
+}
+
+// Query limits
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Query filters audit events. Zero fields match everything.
+type Query struct {
+	Action string    // Exact action, or a prefix ending in "." (e.g. "file.")
+	Since  time.Time // Inclusive
+	Until  time.Time // Exclusive
+	Text   string    // Case-insensitive substring of the target or detail
+	TabID  string
+	Limit  int // Default DefaultLimit, at most MaxLimit
+}
+
+var mu sync.Mutex
+
+// Record appends an event to the log, stamping it with the current time.
+// Failures are logged rather than returned: auditing never blocks the
+// operation itself.
+func Record(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if err := appendEvent(event); err != nil {
+		log.Printf("[Audit] Failed to record %s %q: %v", event.Action, event.Target, err)
+	}
+}
+
+// RecordResult records an event, with err's message when the operation failed.
+func RecordResult(event Event, err error) {
+	if err != nil {
+		event.Error = err.Error()
+	}
+	Record(event)
+}
+
+func appendEvent(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir := storage.GetAuditDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, fileName(event.Time)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fileName is the log file for events in t's month
+func fileName(t time.Time) string {
+	return "audit-" + t.UTC().Format("2006-01") + ".jsonl"
+}
+
+// Search returns the events matching q, newest first.
+func Search(q Query) ([]Event, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+	text := strings.ToLower(q.Text)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(storage.GetAuditDir(), "audit-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+
+	events := []Event{}
+	for _, file := range files {
+		// Skip months entirely outside the range
+		month, err := time.Parse("2006-01", strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "audit-"), ".jsonl"))
+		if err == nil {
+			if !q.Since.IsZero() && month.AddDate(0, 1, 0).Before(q.Since) {
+				break
+			}
+			if !q.Until.IsZero() && !month.Before(q.Until) {
+				continue
+			}
+		}
+
+		fileEvents, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(fileEvents) - 1; i >= 0; i-- {
+			event := fileEvents[i]
+			if !q.matches(event, text) {
+				continue
+			}
+			events = append(events, event)
+			if len(events) == q.Limit {
+				return events, nil
+			}
+		}
+	}
+	return events, nil
+}
+
+func (q Query) matches(event Event, text string) bool {
+	if q.Action != "" && event.Action != q.Action && !(strings.HasSuffix(q.Action, ".") && strings.HasPrefix(event.Action, q.Action)) {
+		return false
+	}
+	if !q.Since.IsZero() && event.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !event.Time.Before(q.Until) {
+		return false
+	}
+	if q.TabID != "" && event.TabID != q.TabID {
+		return false
+	}
+	if text != "" && !strings.Contains(strings.ToLower(event.Target), text) && !strings.Contains(strings.ToLower(event.Detail), text) {
+		return false
+	}
+	return true
+}
+
+// readFile returns a log file's events in the order they were written,
+// skipping lines that don't parse (e.g. one cut short by a crash)
+func readFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
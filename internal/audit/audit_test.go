@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func useTempAuditDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	return storage.GetAuditDir()
+}
+
+func TestRecordAndSearch(t *testing.T) {
+	dir := useTempAuditDir(t)
+
+	lastMonth := time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC)
+	thisMonth := time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)
+	Record(Event{Time: lastMonth, Action: FileDelete, Target: "/src/old.go", Detail: "moved to the trash"})
+	Record(Event{Time: thisMonth, Action: AssistantExecute, Target: "go test ./...", TabID: "tab-1"})
+	RecordResult(Event{Time: thisMonth.Add(time.Hour), Action: UpdateApply, Target: "/usr/local/bin/forge"}, errors.New("permission denied"))
+	Record(Event{Time: thisMonth.Add(2 * time.Hour), Action: FileDelete, Target: "/src/New.go", Detail: "deleted permanently"})
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "audit-*.jsonl")); len(files) != 2 {
+		t.Errorf("Expected one file per month, got %v", files)
+	}
+
+	events, err := Search(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 || events[0].Target != "/src/New.go" || events[3].Target != "/src/old.go" {
+		t.Fatalf("Expected all events newest first, got %+v", events)
+	}
+	if events[1].Error != "permission denied" {
+		t.Errorf("Expected the failure recorded, got %+v", events[1])
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{"action", Query{Action: FileDelete}, 2},
+		{"action prefix", Query{Action: "file."}, 2},
+		{"partial action", Query{Action: "file"}, 0},
+		{"since", Query{Since: thisMonth}, 3},
+		{"until", Query{Until: thisMonth}, 1},
+		{"text", Query{Text: "NEW.GO"}, 1},
+		{"tab", Query{TabID: "tab-1"}, 1},
+		{"limit", Query{Limit: 2}, 2},
+	}
+	for _, tt := range tests {
+		events, err := Search(tt.query)
+		if err != nil || len(events) != tt.want {
+			t.Errorf("%s: expected %d events, got %d (%v)", tt.name, tt.want, len(events), err)
+		}
+	}
+}
+
+func TestSearchSkipsBadLines(t *testing.T) {
+	dir := useTempAuditDir(t)
+	Record(Event{Action: UpdateApply})
+	f, _ := os.OpenFile(filepath.Join(dir, fileName(time.Now())), os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"time":"2026-10-`)
+	f.Close()
+
+	if events, err := Search(Query{}); err != nil || len(events) != 1 {
+		t.Errorf("Expected the truncated line skipped, got %+v (%v)", events, err)
+	}
+}
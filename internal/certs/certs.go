@@ -0,0 +1,198 @@
+// Package certs provides the certificate Forge serves HTTPS with: a pair the
+// user supplies, or a self-signed certificate generated on first run.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+const (
+	// Browsers reject leaf certificates valid for more than 398 days
+	selfSignedValidity = 397 * 24 * time.Hour
+	// Self-signed certificates are regenerated this close to expiry
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// Info describes the certificate being served.
+type Info struct {
+	SelfSigned  bool      `json:"selfSigned"`
+	CertFile    string    `json:"certFile"`
+	Hosts       []string  `json:"hosts"`
+	NotAfter    time.Time `json:"notAfter"`
+	Fingerprint string    `json:"fingerprint"` // SHA-256 of the DER certificate
+}
+
+// Load returns a TLS config for the given certificate and key files. When both
+// are empty, a self-signed certificate for DefaultHosts is used from
+// storage.GetTLSDir, generated if missing, expiring, or lacking a host.
+func Load(certFile, keyFile string) (*tls.Config, *Info, error) {
+	selfSigned := certFile == "" && keyFile == ""
+	if selfSigned {
+		var err error
+		certFile, keyFile, err = EnsureSelfSigned(storage.GetTLSDir(), DefaultHosts())
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if certFile == "" || keyFile == "" {
+		return nil, nil, fmt.Errorf("both a certificate and a key file are required")
+	}
+
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	info := &Info{
+		SelfSigned:  selfSigned,
+		CertFile:    certFile,
+		Hosts:       certHosts(leaf),
+		NotAfter:    leaf.NotAfter,
+		Fingerprint: fingerprint(leaf),
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   tls.VersionTLS12,
+	}
+	return config, info, nil
+}
+
+// DefaultHosts returns the names a local certificate should cover.
+func DefaultHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if name, err := os.Hostname(); err == nil && name != "" && name != "localhost" {
+		hosts = append(hosts, name)
+	}
+	return hosts
+}
+
+// EnsureSelfSigned returns cert.pem and key.pem in dir, generating a new
+// self-signed pair when the existing one is missing, due for renewal, or
+// doesn't cover every host.
+func EnsureSelfSigned(dir string, hosts []string) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	if leaf, err := readCert(certFile); err == nil {
+		if _, err := os.Stat(keyFile); err == nil && time.Until(leaf.NotAfter) > renewBefore && covers(leaf, hosts) {
+			return certFile, keyFile, nil
+		}
+	}
+
+	log.Printf("[TLS] Generating self-signed certificate for %s", strings.Join(hosts, ", "))
+	certPEM, keyPEM, err := generate(hosts, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", err
+	}
+	if err := storage.WriteFileAtomic(keyFile, keyPEM, 0600); err != nil {
+		return "", "", err
+	}
+	if err := storage.WriteFileAtomic(certFile, certPEM, 0644); err != nil {
+		return "", "", err
+	}
+	return certFile, keyFile, nil
+}
+
+// generate creates a PEM-encoded self-signed certificate and ECDSA key.
+func generate(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Forge Terminal"}, CommonName: hosts[0]},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+func readCert(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s does not contain a PEM certificate", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// covers reports whether a certificate is valid for every host.
+func covers(cert *x509.Certificate, hosts []string) bool {
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func certHosts(cert *x509.Certificate) []string {
+	hosts := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+	return hosts
+}
+
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = strings.ToUpper(hex.EncodeToString([]byte{b}))
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,50 @@
+package certs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEnsureSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	hosts := []string{"localhost", "127.0.0.1"}
+
+	certFile, keyFile, err := EnsureSelfSigned(dir, hosts)
+	if err != nil {
+		t.Fatalf("EnsureSelfSigned failed: %v", err)
+	}
+	config, info, err := Load(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(config.Certificates) != 1 || info.Fingerprint == "" {
+		t.Errorf("Expected a loaded certificate with a fingerprint, got %+v", info)
+	}
+	if len(info.Hosts) != 2 {
+		t.Errorf("Expected hosts %v, got %v", hosts, info.Hosts)
+	}
+
+	// An existing certificate is reused
+	before, _ := os.ReadFile(certFile)
+	EnsureSelfSigned(dir, hosts)
+	after, _ := os.ReadFile(certFile)
+	if string(before) != string(after) {
+		t.Error("Expected the existing certificate to be reused")
+	}
+
+	// A new host triggers regeneration
+	EnsureSelfSigned(dir, append(hosts, "devbox"))
+	leaf, err := readCert(certFile)
+	if err != nil {
+		t.Fatalf("readCert failed: %v", err)
+	}
+	if !covers(leaf, []string{"devbox"}) {
+		t.Error("Expected the regenerated certificate to cover devbox")
+	}
+}
+
+func TestLoadRequiresBothFiles(t *testing.T) {
+	if _, _, err := Load("cert.pem", ""); err == nil {
+		t.Error("Expected error when the key file is missing")
+	}
+}
@@ -0,0 +1,345 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikejsmith1985/forge-terminal/internal/guard"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// defaultPromptTimeout bounds how long a step waits for the shell prompt to return
+const defaultPromptTimeout = 5 * time.Minute
+
+// maxChainRuns is how many finished runs are kept for status queries
+const maxChainRuns = 50
+
+// Chain run states
+const (
+	ChainRunning   = "running"
+	ChainCompleted = "completed"
+	ChainFailed    = "failed"
+	ChainCancelled = "cancelled"
+)
+
+// ChainStep is one command in a chain.
+type ChainStep struct {
+	CommandID     int    `json:"commandId,omitempty"`     // Card to run...
+	Command       string `json:"command,omitempty"`       // ...or inline command text
+	DelayMs       int    `json:"delayMs,omitempty"`       // Pause before running this step
+	WaitForPrompt bool   `json:"waitForPrompt,omitempty"` // Wait for the shell prompt before the next step
+	TimeoutSec    int    `json:"timeoutSec,omitempty"`    // Prompt wait limit (default 300)
+}
+
+// Chain is an ordered list of commands run as one action, e.g. "pull, build, test".
+type Chain struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Steps       []ChainStep `json:"steps"`
+}
+
+// ChainTarget is the terminal a chain runs in.
+type ChainTarget interface {
+	InjectInput(input string, submit bool) error
+	WaitForPrompt(ctx context.Context, since time.Time) error
+}
+
+// ChainRun tracks the progress of a running or finished chain.
+type ChainRun struct {
+	ID         string     `json:"id"`
+	ChainID    string     `json:"chainId"`
+	ChainName  string     `json:"chainName"`
+	TabID      string     `json:"tabId"`
+	Status     string     `json:"status"`
+	Step       int        `json:"step"` // 1-based index of the current or last step
+	TotalSteps int        `json:"totalSteps"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// resolvedStep is a chain step with its command text rendered.
+type resolvedStep struct {
+	ChainStep
+	text   string
+	submit bool
+}
+
+var (
+	chainsMutex sync.Mutex
+
+	chainRuns      = make(map[string]*ChainRun)
+	chainRunsMutex sync.Mutex
+)
+
+// LoadChains returns saved chains.
+func LoadChains() ([]Chain, error) {
+	chainsMutex.Lock()
+	defer chainsMutex.Unlock()
+
+	data, err := storage.ReadJSONFile(storage.GetChainsPath())
+	if os.IsNotExist(err) {
+		return []Chain{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chains file: %w", err)
+	}
+
+	var chains []Chain
+	if err := json.Unmarshal(data, &chains); err != nil {
+		return nil, fmt.Errorf("failed to parse chains JSON: %w", err)
+	}
+	return chains, nil
+}
+
+// SaveChains validates and saves chains, assigning IDs to new ones.
+func SaveChains(chains []Chain) error {
+	for i := range chains {
+		if chains[i].ID == "" {
+			chains[i].ID = uuid.New().String()
+		}
+		if err := validateChain(chains[i]); err != nil {
+			return err
+		}
+	}
+
+	chainsMutex.Lock()
+	defer chainsMutex.Unlock()
+
+	path := storage.GetChainsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(chains, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+func validateChain(chain Chain) error {
+	if chain.Name == "" {
+		return fmt.Errorf("chain %s: name is required", chain.ID)
+	}
+	if len(chain.Steps) == 0 {
+		return fmt.Errorf("chain %q has no steps", chain.Name)
+	}
+	for i, step := range chain.Steps {
+		if step.CommandID == 0 && step.Command == "" {
+			return fmt.Errorf("chain %q step %d: commandId or command is required", chain.Name, i+1)
+		}
+		if step.DelayMs < 0 || step.TimeoutSec < 0 {
+			return fmt.Errorf("chain %q step %d: delay and timeout must be non-negative", chain.Name, i+1)
+		}
+	}
+	return nil
+}
+
+// resolveChain looks up each step's command and expands placeholders, failing
+// before anything runs if a card is missing or an input wasn't supplied.
+func resolveChain(chain Chain, cmds []Command, rctx RenderContext) ([]resolvedStep, error) {
+	byID := make(map[int]Command, len(cmds))
+	for _, cmd := range cmds {
+		byID[cmd.ID] = cmd
+	}
+
+	steps := make([]resolvedStep, 0, len(chain.Steps))
+	for i, step := range chain.Steps {
+		text, submit := step.Command, true
+		if step.CommandID != 0 {
+			cmd, ok := byID[step.CommandID]
+			if !ok {
+				return nil, fmt.Errorf("step %d: command %d not found", i+1, step.CommandID)
+			}
+			text, submit = cmd.Command, !cmd.PasteOnly
+		}
+
+		rendered := RenderCommand(text, rctx)
+		if !rendered.Complete {
+			return nil, fmt.Errorf("step %d: missing values for %d placeholder(s)", i+1, len(rendered.Missing))
+		}
+		steps = append(steps, resolvedStep{ChainStep: step, text: rendered.Command, submit: submit})
+	}
+	return steps, nil
+}
+
+// CheckChain resolves a chain's steps and checks them against the
+// dangerous-command guard, returning the strictest decision.
+func CheckChain(chain Chain, cmds []Command, rctx RenderContext) (guard.Decision, error) {
+	steps, err := resolveChain(chain, cmds, rctx)
+	if err != nil {
+		return guard.Decision{}, err
+	}
+	texts := make([]string, len(steps))
+	for i, step := range steps {
+		texts[i] = step.text
+	}
+	return guard.Check(texts...), nil
+}
+
+// runSteps executes resolved steps against target, updating run as it goes.
+func runSteps(ctx context.Context, run *ChainRun, steps []resolvedStep, target ChainTarget) error {
+	for i, step := range steps {
+		chainRunsMutex.Lock()
+		run.Step = i + 1
+		chainRunsMutex.Unlock()
+
+		if step.DelayMs > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(step.DelayMs) * time.Millisecond):
+			}
+		}
+
+		sent := time.Now()
+		if err := target.InjectInput(step.text, step.submit); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if err := RecordUsage(step.CommandID); err != nil {
+			log.Printf("[Commands] Failed to record usage for command %d: %v", step.CommandID, err)
+		}
+
+		if step.WaitForPrompt {
+			timeout := defaultPromptTimeout
+			if step.TimeoutSec > 0 {
+				timeout = time.Duration(step.TimeoutSec) * time.Second
+			}
+			waitCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := target.WaitForPrompt(waitCtx, sent)
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return fmt.Errorf("step %d: waiting for prompt: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// StartChain validates a chain and runs it in the background against target.
+func StartChain(chain Chain, cmds []Command, tabID string, target ChainTarget, rctx RenderContext) (*ChainRun, error) {
+	steps, err := resolveChain(chain, cmds, rctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &ChainRun{
+		ID:         uuid.New().String(),
+		ChainID:    chain.ID,
+		ChainName:  chain.Name,
+		TabID:      tabID,
+		Status:     ChainRunning,
+		TotalSteps: len(steps),
+		StartedAt:  time.Now(),
+		cancel:     cancel,
+	}
+
+	chainRunsMutex.Lock()
+	chainRuns[run.ID] = run
+	pruneChainRuns()
+	started := run.snapshot()
+	chainRunsMutex.Unlock()
+
+	log.Printf("[Commands] Starting chain %q (%d steps) in tab %s", chain.Name, len(steps), tabID)
+
+	go func() {
+		defer cancel()
+		err := runSteps(ctx, run, steps, target)
+
+		chainRunsMutex.Lock()
+		defer chainRunsMutex.Unlock()
+		now := time.Now()
+		run.FinishedAt = &now
+		switch {
+		case err == nil:
+			run.Status = ChainCompleted
+		case ctx.Err() == context.Canceled:
+			run.Status = ChainCancelled
+		default:
+			run.Status = ChainFailed
+			run.Error = err.Error()
+		}
+		log.Printf("[Commands] Chain %q finished: %s %s", chain.Name, run.Status, run.Error)
+	}()
+
+	return started, nil
+}
+
+// snapshot copies a run for safe use outside chainRunsMutex.
+func (r *ChainRun) snapshot() *ChainRun {
+	copied := *r
+	copied.cancel = nil
+	return &copied
+}
+
+// pruneChainRuns drops the oldest finished runs. Caller must hold chainRunsMutex.
+func pruneChainRuns() {
+	if len(chainRuns) <= maxChainRuns {
+		return
+	}
+	finished := []*ChainRun{}
+	for _, run := range chainRuns {
+		if run.Status != ChainRunning {
+			finished = append(finished, run)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].StartedAt.Before(finished[j].StartedAt)
+	})
+	for i := 0; i < len(chainRuns)-maxChainRuns && i < len(finished); i++ {
+		delete(chainRuns, finished[i].ID)
+	}
+}
+
+// GetChainRun returns the status of a run.
+func GetChainRun(id string) (*ChainRun, bool) {
+	chainRunsMutex.Lock()
+	defer chainRunsMutex.Unlock()
+	run, ok := chainRuns[id]
+	if !ok {
+		return nil, false
+	}
+	return run.snapshot(), true
+}
+
+// ListChainRuns returns all tracked runs, newest first.
+func ListChainRuns() []*ChainRun {
+	chainRunsMutex.Lock()
+	defer chainRunsMutex.Unlock()
+
+	runs := make([]*ChainRun, 0, len(chainRuns))
+	for _, run := range chainRuns {
+		runs = append(runs, run.snapshot())
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs
+}
+
+// CancelChainRun stops a running chain. Returns false if the run isn't active.
+func CancelChainRun(id string) bool {
+	chainRunsMutex.Lock()
+	defer chainRunsMutex.Unlock()
+	run, ok := chainRuns[id]
+	if !ok || run.Status != ChainRunning {
+		return false
+	}
+	run.cancel()
+	return true
+}
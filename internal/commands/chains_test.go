@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/guard"
+)
+
+type fakeTarget struct {
+	mu       sync.Mutex
+	inputs   []string
+	waits    int
+	blockFor time.Duration
+}
+
+func (f *fakeTarget) InjectInput(input string, submit bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if submit {
+		input += "\r"
+	}
+	f.inputs = append(f.inputs, input)
+	return nil
+}
+
+func (f *fakeTarget) WaitForPrompt(ctx context.Context, since time.Time) error {
+	f.mu.Lock()
+	f.waits++
+	f.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(f.blockFor):
+		return nil
+	}
+}
+
+func (f *fakeTarget) recorded() ([]string, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.inputs...), f.waits
+}
+
+func waitForRun(t *testing.T, id string) *ChainRun {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if run, ok := GetChainRun(id); ok && run.Status != ChainRunning {
+			return run
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Chain run %s did not finish", id)
+	return nil
+}
+
+func TestSaveAndLoadChains(t *testing.T) {
+	withTempHome(t)
+
+	chains := []Chain{{Name: "Build", Steps: []ChainStep{{Command: "make"}}}}
+	if err := SaveChains(chains); err != nil {
+		t.Fatalf("SaveChains failed: %v", err)
+	}
+	if chains[0].ID == "" {
+		t.Error("Expected ID to be assigned")
+	}
+
+	loaded, err := LoadChains()
+	if err != nil || len(loaded) != 1 || loaded[0].Steps[0].Command != "make" {
+		t.Errorf("Unexpected loaded chains: %+v (%v)", loaded, err)
+	}
+
+	if err := SaveChains([]Chain{{Name: "Empty"}}); err == nil {
+		t.Error("Expected error saving chain without steps")
+	}
+}
+
+func TestStartChain(t *testing.T) {
+	withTempHome(t)
+
+	cmds := []Command{
+		{ID: 1, Command: "git pull"},
+		{ID: 2, Command: "go build ./..."},
+		{ID: 3, Command: "explain this", PasteOnly: true},
+	}
+	chain := Chain{ID: "c1", Name: "Pull and build", Steps: []ChainStep{
+		{CommandID: 1, WaitForPrompt: true},
+		{CommandID: 2, DelayMs: 5},
+		{CommandID: 3},
+		{Command: "echo {{input:msg}}"},
+	}}
+
+	target := &fakeTarget{}
+	run, err := StartChain(chain, cmds, "tab-1", target, RenderContext{Inputs: map[string]string{"msg": "done"}})
+	if err != nil {
+		t.Fatalf("StartChain failed: %v", err)
+	}
+
+	finished := waitForRun(t, run.ID)
+	if finished.Status != ChainCompleted || finished.Step != 4 {
+		t.Errorf("Expected completed run at step 4, got %+v", finished)
+	}
+
+	inputs, waits := target.recorded()
+	expected := []string{"git pull\r", "go build ./...\r", "explain this", "echo done\r"}
+	if len(inputs) != len(expected) {
+		t.Fatalf("Expected %d inputs, got %v", len(expected), inputs)
+	}
+	for i := range expected {
+		if inputs[i] != expected[i] {
+			t.Errorf("Input %d: expected %q, got %q", i, expected[i], inputs[i])
+		}
+	}
+	if waits != 1 {
+		t.Errorf("Expected 1 prompt wait, got %d", waits)
+	}
+
+	usage, _ := LoadUsage()
+	if usage[1].RunCount != 1 || usage[2].RunCount != 1 {
+		t.Errorf("Expected chain steps to record usage, got %+v", usage)
+	}
+}
+
+func TestStartChain_Validation(t *testing.T) {
+	chain := Chain{ID: "c2", Name: "Broken", Steps: []ChainStep{{CommandID: 99}}}
+	if _, err := StartChain(chain, nil, "tab", &fakeTarget{}, RenderContext{}); err == nil {
+		t.Error("Expected error for missing command")
+	}
+
+	chain = Chain{ID: "c3", Name: "Needs input", Steps: []ChainStep{{Command: "echo {{input:x}}"}}}
+	if _, err := StartChain(chain, nil, "tab", &fakeTarget{}, RenderContext{}); err == nil {
+		t.Error("Expected error for missing input")
+	}
+}
+
+func TestCancelChainRun(t *testing.T) {
+	chain := Chain{ID: "c4", Name: "Slow", Steps: []ChainStep{
+		{Command: "sleep 60", WaitForPrompt: true},
+		{Command: "echo never"},
+	}}
+	target := &fakeTarget{blockFor: time.Minute}
+
+	run, err := StartChain(chain, nil, "tab", target, RenderContext{})
+	if err != nil {
+		t.Fatalf("StartChain failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if !CancelChainRun(run.ID) {
+		t.Fatal("Expected cancel to succeed")
+	}
+	finished := waitForRun(t, run.ID)
+	if finished.Status != ChainCancelled {
+		t.Errorf("Expected cancelled status, got %s", finished.Status)
+	}
+	if inputs, _ := target.recorded(); len(inputs) != 1 {
+		t.Errorf("Expected only first step to run, got %v", inputs)
+	}
+}
+
+func TestCheckChain(t *testing.T) {
+	cmds := []Command{{ID: 1, Command: "git push {{input:Flags}}"}}
+	chain := Chain{Name: "ship", Steps: []ChainStep{{Command: "make test"}, {CommandID: 1}}}
+
+	decision, err := CheckChain(chain, cmds, RenderContext{Inputs: map[string]string{"Flags": "--force"}})
+	if err != nil || decision.Action != guard.Confirm || decision.Rule != "git-force-push" {
+		t.Errorf("Expected the rendered force push to need confirmation, got %+v (%v)", decision, err)
+	}
+	if decision, _ := CheckChain(chain, cmds, RenderContext{Inputs: map[string]string{"Flags": "origin main"}}); decision.Action != guard.Allow {
+		t.Errorf("Expected a plain push to be allowed, got %+v", decision)
+	}
+	if _, err := CheckChain(chain, cmds, RenderContext{}); err == nil {
+		t.Error("Expected an error for a missing input")
+	}
+}
@@ -4,15 +4,116 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/guard"
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 // Config represents user configuration
 type Config struct {
 	// Shell settings
-	ShellType   string `json:"shellType"`   // "powershell", "cmd", or "wsl"
-	WSLDistro   string `json:"wslDistro"`   // e.g., "Ubuntu-24.04"
-	WSLHomePath string `json:"wslHomePath"` // e.g., "/home/mikej" (auto-detected if empty)
+	ShellType       string `json:"shellType"`                 // "powershell", "cmd", "wsl", "docker", "tmux", or "ssh"
+	WSLDistro       string `json:"wslDistro"`                 // e.g., "Ubuntu-24.04"
+	WSLHomePath     string `json:"wslHomePath"`               // e.g., "/home/mikej" (auto-detected if empty)
+	DockerContainer string `json:"dockerContainer,omitempty"` // Container ID or name docker tabs open in
+	DockerShell     string `json:"dockerShell,omitempty"`     // Shell run in the container (empty = sh)
+	TmuxSession     string `json:"tmuxSession,omitempty"`     // tmux session tmux tabs attach to (empty = most recent)
+	SSHHost         string `json:"sshHost,omitempty"`         // Host or ~/.ssh/config alias ssh tabs connect to
+
+	// Command pack installed on first run (see CommandPacks; empty = DefaultPackID)
+	CommandPack string `json:"commandPack,omitempty"`
+
+	// Keyboard shortcuts: action -> binding, overriding DefaultKeymap ("" unbinds; see keymap.go)
+	Keymap map[string]string `json:"keymap,omitempty"`
+
+	// File access settings
+	AllowedRoots []string `json:"allowedRoots,omitempty"` // Directories file APIs may access (empty = no server-side limit)
+
+	// Network settings (applied on restart; --host and --port override them)
+	Host           string   `json:"host,omitempty"`           // Listen address (empty = 127.0.0.1); non-loopback requires auth
+	Port           int      `json:"port,omitempty"`           // Listen port (0 = first free preferred port)
+	PreferredPorts []int    `json:"preferredPorts,omitempty"` // Ports tried in order when Port is 0 (empty = 8333, 8080, 9000, 3000, 3333)
+	AuthToken      string   `json:"authToken,omitempty"`      // Access token for remote clients (generated when needed)
+	AuthUsername   string   `json:"authUsername,omitempty"`   // Basic-auth login for remote clients
+	AuthPassword   string   `json:"authPassword,omitempty"`   // Basic-auth password for AuthUsername
+	AllowedIPs     []string `json:"allowedIPs,omitempty"`     // IPs or CIDR ranges allowed to connect (empty = any)
+
+	// Cross-origin settings: other sites that may call the API and open terminals
+	// (CORS), and that may embed Forge in a frame, e.g. a team portal
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"` // e.g. "https://portal.local", "https://*.example.com", "*" (empty = ALLOWED_ORIGINS env, or local dev servers, GitHub Pages and Codespaces)
+	FrameAncestors []string `json:"frameAncestors,omitempty"` // Origins that may frame Forge, same forms (empty = none)
+
+	// HTTPS settings (applied on restart)
+	TLS         bool   `json:"tls,omitempty"`         // Serve over HTTPS
+	TLSCertFile string `json:"tlsCertFile,omitempty"` // PEM certificate (empty = self-signed, generated on first run)
+	TLSKeyFile  string `json:"tlsKeyFile,omitempty"`  // PEM private key for TLSCertFile
+
+	// Long-term storage for AM archives, session exports, and recordings (nil = local)
+	Storage *storage.BackendConfig `json:"storage,omitempty"`
+
+	// Updater settings
+	UpdateChannel        string `json:"updateChannel,omitempty"`        // "stable", "beta", or "nightly" (empty = stable)
+	UpdateProxy          string `json:"updateProxy,omitempty"`          // Proxy URL for update checks (empty = HTTP(S)_PROXY env)
+	UpdateSourceDir      string `json:"updateSourceDir,omitempty"`      // Local directory of releases for offline installs
+	UpdateCheckSeconds   int    `json:"updateCheckSeconds,omitempty"`   // Background check interval (0 = 30s)
+	UpdateChecksDisabled bool   `json:"updateChecksDisabled,omitempty"` // Disable background update checks
+	UpdatePolicy         string `json:"updatePolicy,omitempty"`         // "manual", "download", or "quiet-hours" (empty = manual)
+	UpdateQuietHours     string `json:"updateQuietHours,omitempty"`     // Install window for quiet-hours, e.g. "02:00-05:00"
+
+	// Terminal limits, enforced when a tab connects
+	MaxTabsPerClient int `json:"maxTabsPerClient,omitempty"` // Open terminals per client address (0 = 20)
+	MaxTabs          int `json:"maxTabs,omitempty"`          // Open terminals across all clients (0 = 100)
+
+	// Days deleted files stay in the trash before being purged (0 = 30)
+	TrashRetentionDays int `json:"trashRetentionDays,omitempty"`
+
+	// Notify when a command that ran at least this long finishes in a tab that
+	// isn't focused (0 = 60, negative = never)
+	LongCommandSeconds int `json:"longCommandSeconds,omitempty"`
+
+	// Serve the dev server a tab starts under /preview/{tabId}/, forwarding
+	// ports from SSH and WSL tabs as needed
+	PreviewProxy bool `json:"previewProxy,omitempty"`
+
+	// Keep the computer awake while a tab is printing output or an AI
+	// conversation is running
+	PreventSleep bool `json:"preventSleep,omitempty"`
+
+	// Editor for "Open in editor": "vscode", "jetbrains", or "vim" (empty =
+	// vscode). EditorCommands overrides it per OS ("linux", "darwin",
+	// "windows") with a command template using {path}, {line}, {column}, and
+	// {dir}; prefix it with "tab:" to run it in a new Forge tab.
+	Editor         string            `json:"editor,omitempty"`
+	EditorCommands map[string]string `json:"editorCommands,omitempty"`
+
+	// Rules for dangerous commands Forge types into tabs on the user's behalf
+	// (cards, chains, snippets, the assistant), added to guard.DefaultRules.
+	// A rule named like a default replaces it; action "allow" turns it off.
+	DangerousCommands []guard.Rule `json:"dangerousCommands,omitempty"`
+
+	// Capabilities that change the system ("assistant.execute",
+	// "config.write", "files.delete", "files.write", "hooks.apply",
+	// "terminal.inject"), all on unless set false here. LockedDown turns them
+	// all off.
+	Capabilities map[string]bool `json:"capabilities,omitempty"`
+	LockedDown   bool            `json:"lockedDown,omitempty"`
+
+	// Directories AM never captures conversations from, like those holding a
+	// .forge-ignore file (see am.IsPrivate)
+	AMPrivateWorkspaces []string `json:"amPrivateWorkspaces,omitempty"`
+
+	// Ask the assistant to note the problems solved and decisions made in
+	// each finished AI conversation, in the workspace's PROJECT_MEMORY.md
+	AMProjectNotes bool `json:"amProjectNotes,omitempty"`
+
+	// Prices of API-billed AI providers by provider name, with optional
+	// per-model overrides, for the spend estimates in /api/llm/usage
+	LLMPrices map[string]llm.Price `json:"llmPrices,omitempty"`
+
+	// Update preferences (see /api/update/preferences)
+	UpdatePinnedVersion   string   `json:"updatePinnedVersion,omitempty"`   // Stay on this release (empty = follow the channel)
+	UpdateSkippedVersions []string `json:"updateSkippedVersions,omitempty"` // Releases not to be offered
 }
 
 // DefaultConfig returns default configuration
@@ -24,11 +125,7 @@ var DefaultConfig = Config{
 
 // GetConfigPath returns the path to the config JSON file
 func GetConfigPath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "config.json"), nil
+	return storage.GetTerminalConfigPath(), nil
 }
 
 // LoadConfig loads config from the JSON file, creating defaults if needed
@@ -38,17 +135,18 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	// Return default if doesn't exist
+	// Return a copy of the defaults if it doesn't exist
+	config := DefaultConfig
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return &DefaultConfig, nil
+		return &config, nil
 	}
 
-	data, err := os.ReadFile(path)
+	data, err := storage.ReadJSONFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var config Config
+	// Fields missing from the file keep their defaults
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
@@ -56,15 +154,28 @@ func LoadConfig() (*Config, error) {
 	return &config, nil
 }
 
+// LoadConfigFile returns the raw contents of the config file, or nil if it doesn't exist
+func LoadConfigFile() ([]byte, error) {
+	path, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := storage.ReadJSONFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
 // SaveConfig saves config to the JSON file
 func SaveConfig(config *Config) error {
-	configDir, err := GetConfigDir()
+	path, err := GetConfigPath()
 	if err != nil {
 		return err
 	}
 
 	// Ensure directory exists
-	if err := os.MkdirAll(configDir, 0700); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return err
 	}
 
@@ -73,12 +184,7 @@ func SaveConfig(config *Config) error {
 		return err
 	}
 
-	path, err := GetConfigPath()
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0600)
+	return storage.WriteFileAtomic(path, data, 0600)
 }
 
 // GetWelcomeShownPath returns the path to the welcome_shown file
@@ -92,37 +198,22 @@ func GetWelcomeShownPath() (string, error) {
 
 // IsWelcomeShown checks if the welcome screen has been shown for the current version
 func IsWelcomeShown(currentVersion string) bool {
-	path, err := GetWelcomeShownPath()
+	state, err := LoadOnboarding()
 	if err != nil {
 		return false
 	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return false
-	}
-
-	// File contains the version for which welcome was shown
-	shownVersion := strings.TrimSpace(string(data))
-	return shownVersion == currentVersion
+	return state.WelcomeVersion == currentVersion
 }
 
-// SetWelcomeShown marks the welcome screen as shown for the given version
+// SetWelcomeShown marks the welcome screen as shown for the given version. The
+// first time, it also starts announcements from this version (see Announcements).
 func SetWelcomeShown(version string) error {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return err
-	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return err
-	}
-
-	path, err := GetWelcomeShownPath()
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, []byte(version), 0600)
+	_, err := UpdateOnboarding(func(state *OnboardingState) error {
+		state.WelcomeVersion = version
+		if state.AnnouncedFrom == "" {
+			state.AnnouncedFrom = version
+		}
+		return nil
+	})
+	return err
 }
@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Config bundle identification
+const (
+	ConfigBundleFormat  = "forge-config"
+	ConfigBundleVersion = 1
+)
+
+// Merge strategies for importing a config bundle
+const (
+	MergeBundle  = "merge"   // Bundle values win; existing items not in the bundle are kept (default)
+	MergeKeep    = "keep"    // Existing values win; only missing fields and items are added
+	MergeReplace = "replace" // Each section in the bundle replaces the saved one
+)
+
+// ConfigBundle is a portable snapshot of a user's setup for moving between
+// machines or keeping in a dotfiles repo. Prompts travel as paste-only command
+// cards. Themes are UI preferences kept in the browser (e.g. "theme",
+// "colorTheme"): the client supplies them on export and applies them on import.
+type ConfigBundle struct {
+	Format     string            `json:"format"`
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exportedAt"`
+	AppVersion string            `json:"appVersion,omitempty"`
+	Config     json.RawMessage   `json:"config,omitempty"`
+	Commands   []Command         `json:"commands,omitempty"`
+	Chains     []Chain           `json:"chains,omitempty"`
+	Schedules  []Schedule        `json:"schedules,omitempty"`
+	Themes     map[string]string `json:"themes,omitempty"`
+}
+
+// ConfigImportSummary describes what a bundle import changed.
+type ConfigImportSummary struct {
+	Strategy  string         `json:"strategy"`
+	Config    bool           `json:"config"` // Config was written
+	Commands  *ImportSummary `json:"commands,omitempty"`
+	Chains    *MergeCounts   `json:"chains,omitempty"`
+	Schedules *MergeCounts   `json:"schedules,omitempty"`
+	Warnings  []string       `json:"warnings"`
+}
+
+// MergeCounts tallies items merged by ID.
+type MergeCounts struct {
+	Added    int `json:"added"`
+	Replaced int `json:"replaced"`
+	Kept     int `json:"kept"`
+}
+
+// ExportConfigBundle bundles the saved config, commands, chains and schedules.
+func ExportConfigBundle(appVersion string, themes map[string]string) (*ConfigBundle, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	// Remote access credentials and the lockdown policy stay on this machine
+	config.AuthToken, config.AuthPassword = "", ""
+	config.LockedDown, config.Capabilities = false, nil
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	cmds, err := LoadCommands()
+	if err != nil {
+		return nil, err
+	}
+	chains, err := LoadChains()
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := LoadSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	// Usage counts are per machine and not part of the setup
+	exported := make([]Command, len(cmds))
+	for i, cmd := range cmds {
+		cmd.RunCount, cmd.LastUsed = 0, nil
+		exported[i] = cmd
+	}
+
+	return &ConfigBundle{
+		Format:     ConfigBundleFormat,
+		Version:    ConfigBundleVersion,
+		ExportedAt: time.Now(),
+		AppVersion: appVersion,
+		Config:     configData,
+		Commands:   exported,
+		Chains:     chains,
+		Schedules:  schedules,
+		Themes:     themes,
+	}, nil
+}
+
+// ParseConfigBundle decodes a config bundle.
+func ParseConfigBundle(data []byte) (*ConfigBundle, error) {
+	var bundle ConfigBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid config bundle: %w", err)
+	}
+	if bundle.Format != ConfigBundleFormat {
+		return nil, fmt.Errorf("unrecognized format %q", bundle.Format)
+	}
+	if bundle.Version > ConfigBundleVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than supported version %d - update Forge to import it",
+			bundle.Version, ConfigBundleVersion)
+	}
+	return &bundle, nil
+}
+
+// ImportConfigBundle merges a bundle into the saved setup. Every section is
+// validated before anything is written, so a bad bundle changes nothing.
+// Sections missing from the bundle are left alone.
+func ImportConfigBundle(bundle *ConfigBundle, strategy string) (*ConfigImportSummary, error) {
+	if strategy == "" {
+		strategy = MergeBundle
+	}
+	if strategy != MergeBundle && strategy != MergeKeep && strategy != MergeReplace {
+		return nil, fmt.Errorf("unknown merge strategy %q", strategy)
+	}
+	summary := &ConfigImportSummary{Strategy: strategy, Warnings: []string{}}
+
+	var config *Config
+	if len(bundle.Config) > 0 {
+		existing, err := LoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		// A bundle can't loosen this machine's lockdown policy
+		lockedDown, capabilities := existing.LockedDown, maps.Clone(existing.Capabilities)
+
+		var warnings []string
+		config, warnings, err = mergeConfig(*existing, bundle.Config, strategy)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		summary.Warnings = append(summary.Warnings, warnings...)
+
+		if config.LockedDown != lockedDown || !maps.Equal(config.Capabilities, capabilities) {
+			summary.Warnings = append(summary.Warnings, "lockedDown and capabilities were not imported")
+		}
+		config.LockedDown, config.Capabilities = lockedDown, capabilities
+	}
+
+	var cmds []Command
+	if bundle.Commands != nil {
+		for i := range bundle.Commands {
+			if err := ValidateCommand(&bundle.Commands[i]); err != nil {
+				return nil, err
+			}
+		}
+		if strategy == MergeReplace {
+			cmds = bundle.Commands
+			summary.Commands = &ImportSummary{Added: len(cmds), ClearedKeys: []string{}}
+		} else {
+			existing, err := LoadCommands()
+			if err != nil {
+				return nil, err
+			}
+			conflict := ConflictOverwrite
+			if strategy == MergeKeep {
+				conflict = ConflictSkip
+			}
+			merged, commandSummary, err := ImportCommands(existing, &ExportBundle{Commands: bundle.Commands}, conflict)
+			if err != nil {
+				return nil, err
+			}
+			cmds = merged
+			summary.Commands = &commandSummary
+		}
+	}
+
+	var chains []Chain
+	if bundle.Chains != nil {
+		for _, chain := range bundle.Chains {
+			if err := validateChain(chain); err != nil {
+				return nil, err
+			}
+		}
+		existing, err := LoadChains()
+		if err != nil {
+			return nil, err
+		}
+		var counts MergeCounts
+		chains, counts = mergeByID(existing, bundle.Chains, func(c Chain) string { return c.ID }, strategy)
+		summary.Chains = &counts
+	}
+
+	var schedules []Schedule
+	if bundle.Schedules != nil {
+		for _, schedule := range bundle.Schedules {
+			if err := validateSchedule(schedule); err != nil {
+				return nil, err
+			}
+		}
+		existing, err := LoadSchedules()
+		if err != nil {
+			return nil, err
+		}
+		var counts MergeCounts
+		schedules, counts = mergeByID(existing, bundle.Schedules, func(s Schedule) string { return s.ID }, strategy)
+		summary.Schedules = &counts
+	}
+
+	if config != nil {
+		if err := SaveConfig(config); err != nil {
+			return nil, err
+		}
+		summary.Config = true
+	}
+	if cmds != nil {
+		if err := SaveCommands(cmds); err != nil {
+			return nil, err
+		}
+	}
+	if chains != nil {
+		if err := SaveChains(chains); err != nil {
+			return nil, err
+		}
+	}
+	if schedules != nil {
+		if err := SaveSchedules(schedules); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// mergeConfig combines the saved config with a bundle's config JSON. Only
+// fields present in the bundle are considered, so older bundles don't reset
+// settings added since they were exported.
+func mergeConfig(existing Config, data json.RawMessage, strategy string) (*Config, []string, error) {
+	switch strategy {
+	case MergeReplace:
+		return ParseConfig(data, DefaultConfig)
+	case MergeKeep:
+		imported, warnings, err := ParseConfig(data, DefaultConfig)
+		if err != nil {
+			return nil, warnings, err
+		}
+		existingData, err := LoadConfigFile()
+		if err != nil {
+			return nil, warnings, err
+		}
+		if existingData == nil {
+			return imported, warnings, nil
+		}
+		config, _, err := ParseConfig(existingData, *imported)
+		return config, warnings, err
+	default:
+		return ParseConfig(data, existing)
+	}
+}
+
+// mergeByID merges imported items into existing ones, matching on ID. Items
+// without an ID are always added (the save assigns one).
+func mergeByID[T any](existing, imported []T, id func(T) string, strategy string) ([]T, MergeCounts) {
+	var counts MergeCounts
+	if strategy == MergeReplace {
+		counts.Added = len(imported)
+		return append([]T{}, imported...), counts
+	}
+
+	result := append([]T{}, existing...)
+	index := make(map[string]int, len(result))
+	for i, item := range result {
+		index[id(item)] = i
+	}
+	for _, item := range imported {
+		i, ok := index[id(item)]
+		switch {
+		case !ok || id(item) == "":
+			result = append(result, item)
+			counts.Added++
+		case strategy == MergeKeep:
+			counts.Kept++
+		default:
+			result[i] = item
+			counts.Replaced++
+		}
+	}
+	return result, counts
+}
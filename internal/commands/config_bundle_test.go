@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func withBundlePaths(t *testing.T) string {
+	t.Helper()
+	withTempHome(t)
+	return storage.GetTerminalConfigPath()
+}
+
+func TestConfigBundleRoundTrip(t *testing.T) {
+	withBundlePaths(t)
+
+	if err := SaveConfig(&Config{ShellType: "wsl", WSLDistro: "Ubuntu", AuthToken: "secret", LockedDown: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCommands([]Command{{ID: 1, Description: "List", Command: "ls", RunCount: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveChains([]Chain{{ID: "c1", Name: "Build", Steps: []ChainStep{{Command: "make"}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := ExportConfigBundle("1.0.0", map[string]string{"colorTheme": "molten"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(bundle.Config), "secret") {
+		t.Errorf("bundle config includes the access token: %s", bundle.Config)
+	}
+	if strings.Contains(string(bundle.Config), "lockedDown") {
+		t.Errorf("bundle config includes the lockdown policy: %s", bundle.Config)
+	}
+
+	// Import on a "new machine"
+	withBundlePaths(t)
+	parsed, err := ParseConfigBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Themes["colorTheme"] != "molten" || parsed.Commands[0].RunCount != 0 {
+		t.Errorf("parsed bundle = %+v", parsed)
+	}
+	summary, err := ImportConfigBundle(parsed, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Strategy != MergeBundle || !summary.Config || summary.Chains.Added != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	config, _ := LoadConfig()
+	if config.ShellType != "wsl" || config.WSLDistro != "Ubuntu" {
+		t.Errorf("config = %+v", config)
+	}
+	chains, _ := LoadChains()
+	if len(chains) != 1 || chains[0].Name != "Build" {
+		t.Errorf("chains = %+v", chains)
+	}
+	cmds, _ := LoadCommands()
+	found := false
+	for _, cmd := range cmds {
+		found = found || cmd.Command == "ls"
+	}
+	if !found {
+		t.Errorf("imported command missing: %+v", cmds)
+	}
+}
+
+func TestImportConfigBundleStrategies(t *testing.T) {
+	bundle := func() *ConfigBundle {
+		return &ConfigBundle{
+			Format:   ConfigBundleFormat,
+			Version:  ConfigBundleVersion,
+			Config:   json.RawMessage(`{"shellType":"powershell","commandPack":"devops"}`),
+			Commands: []Command{{ID: 1, Description: "Imported", Command: "git status"}},
+		}
+	}
+	setup := func(t *testing.T) {
+		withBundlePaths(t)
+		if err := SaveConfig(&Config{ShellType: "wsl", WSLDistro: "Ubuntu"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveCommands([]Command{{ID: 1, Description: "Mine", Command: "ls"}, {ID: 2, Description: "Other", Command: "pwd"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		setup(t)
+		if _, err := ImportConfigBundle(bundle(), MergeBundle); err != nil {
+			t.Fatal(err)
+		}
+		config, _ := LoadConfig()
+		if config.ShellType != "powershell" || config.WSLDistro != "Ubuntu" {
+			t.Errorf("config = %+v", config)
+		}
+		cmds, _ := LoadCommands()
+		if len(cmds) != 2 || cmds[0].Command != "git status" {
+			t.Errorf("commands = %+v", cmds)
+		}
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		setup(t)
+		if _, err := ImportConfigBundle(bundle(), MergeKeep); err != nil {
+			t.Fatal(err)
+		}
+		config, _ := LoadConfig()
+		if config.ShellType != "wsl" || config.CommandPack != "devops" {
+			t.Errorf("config = %+v", config)
+		}
+		cmds, _ := LoadCommands()
+		if len(cmds) != 2 || cmds[0].Command != "ls" {
+			t.Errorf("commands = %+v", cmds)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		setup(t)
+		if _, err := ImportConfigBundle(bundle(), MergeReplace); err != nil {
+			t.Fatal(err)
+		}
+		config, _ := LoadConfig()
+		if config.ShellType != "powershell" || config.WSLDistro != "" {
+			t.Errorf("config = %+v", config)
+		}
+		cmds, _ := LoadCommands()
+		if len(cmds) != 1 || cmds[0].Command != "git status" {
+			t.Errorf("commands = %+v", cmds)
+		}
+	})
+
+	t.Run("lockdown policy is kept", func(t *testing.T) {
+		withBundlePaths(t)
+		if err := SaveConfig(&Config{LockedDown: true, Capabilities: map[string]bool{"terminal.inject": false}}); err != nil {
+			t.Fatal(err)
+		}
+		loose := bundle()
+		loose.Config = json.RawMessage(`{"shellType":"powershell","lockedDown":false,"capabilities":{"terminal.inject":true}}`)
+		for _, strategy := range []string{MergeBundle, MergeKeep, MergeReplace} {
+			summary, err := ImportConfigBundle(loose, strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, _ := LoadConfig()
+			if !config.LockedDown || config.Capabilities["terminal.inject"] {
+				t.Errorf("%s: lockdown policy changed: %+v", strategy, config)
+			}
+			if strategy != MergeKeep && len(summary.Warnings) == 0 {
+				t.Errorf("%s: expected a warning", strategy)
+			}
+		}
+	})
+
+	t.Run("invalid bundle changes nothing", func(t *testing.T) {
+		configFile := withBundlePaths(t)
+		if err := SaveConfig(&Config{ShellType: "wsl"}); err != nil {
+			t.Fatal(err)
+		}
+		before, _ := os.ReadFile(configFile)
+
+		bad := bundle()
+		bad.Chains = []Chain{{ID: "c1", Name: "Empty"}}
+		if _, err := ImportConfigBundle(bad, MergeBundle); err == nil {
+			t.Fatal("expected error for chain without steps")
+		}
+		if _, err := ImportConfigBundle(bundle(), "clobber"); err == nil {
+			t.Fatal("expected error for unknown strategy")
+		}
+		after, _ := os.ReadFile(configFile)
+		if string(before) != string(after) {
+			t.Error("config was written despite the failed import")
+		}
+	})
+}
+
+func TestParseConfigBundleRejectsOtherFormats(t *testing.T) {
+	if _, err := ParseConfigBundle([]byte(`{"format":"forge-commands","version":1}`)); err == nil {
+		t.Error("expected error for a commands bundle")
+	}
+	if _, err := ParseConfigBundle([]byte(`{"format":"forge-config","version":99}`)); err == nil {
+		t.Error("expected error for a newer bundle version")
+	}
+}
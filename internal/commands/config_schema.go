@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/access"
+	"github.com/mikejsmith1985/forge-terminal/internal/editor"
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+	"github.com/mikejsmith1985/forge-terminal/internal/middleware"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// ConfigError describes an invalid config value.
+type ConfigError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ConfigError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ConfigFields returns the JSON field names Config accepts.
+func ConfigFields() []string {
+	fields := []string{}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// ParseConfig decodes a config JSON object over base (fields not present keep
+// base's values), validates it, and warns about fields Config doesn't know.
+func ParseConfig(data []byte, base Config) (*Config, []string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, nil, fmt.Errorf("config is not valid JSON (offset %d): %v", syntaxErr.Offset, err)
+		}
+		return nil, nil, errors.New("config must be a JSON object")
+	}
+
+	known := map[string]bool{}
+	for _, name := range ConfigFields() {
+		known[name] = true
+	}
+	warnings := []string{}
+	for key := range raw {
+		if !known[key] {
+			warnings = append(warnings, fmt.Sprintf("unknown field %q was ignored", key))
+		}
+	}
+	sort.Strings(warnings)
+
+	config := base
+	config.AllowedRoots = append([]string(nil), base.AllowedRoots...)
+	config.AMPrivateWorkspaces = append([]string(nil), base.AMPrivateWorkspaces...)
+	config.UpdateSkippedVersions = append([]string(nil), base.UpdateSkippedVersions...)
+	config.PreferredPorts = append([]int(nil), base.PreferredPorts...)
+	if base.Storage != nil {
+		storageConfig := *base.Storage
+		config.Storage = &storageConfig
+	}
+	if base.LLMPrices != nil {
+		config.LLMPrices = make(map[string]llm.Price, len(base.LLMPrices))
+		for provider, price := range base.LLMPrices {
+			if price.Models != nil {
+				models := make(map[string]llm.Price, len(price.Models))
+				for model, modelPrice := range price.Models {
+					models[model] = modelPrice
+				}
+				price.Models = models
+			}
+			config.LLMPrices[provider] = price
+		}
+	}
+	if base.Keymap != nil {
+		config.Keymap = make(map[string]string, len(base.Keymap))
+		for action, binding := range base.Keymap {
+			config.Keymap[action] = binding
+		}
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, warnings, &ConfigError{
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("must be %s, not a %s", describeType(typeErr.Type), typeErr.Value),
+			}
+		}
+		return nil, warnings, err
+	}
+
+	if err := ValidateConfig(&config); err != nil {
+		return nil, warnings, err
+	}
+	return &config, warnings, nil
+}
+
+// describeType names a Go type the way a user editing JSON would.
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "a list of " + strings.TrimPrefix(describeType(t.Elem()), "a ") + "s"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// ValidateConfig checks values that would otherwise break tabs or features at runtime.
+// Updater fields are validated by the updater package when applied.
+func ValidateConfig(config *Config) error {
+	if config.ShellType != "" {
+		valid := false
+		for _, shell := range ShellTypes {
+			if config.ShellType == shell {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return &ConfigError{Field: "shellType", Message: fmt.Sprintf("unknown shell %q (expected %s)", config.ShellType, strings.Join(ShellTypes, ", "))}
+		}
+	}
+	if config.ShellType == "wsl" && config.WSLHomePath != "" && !strings.HasPrefix(config.WSLHomePath, "/") {
+		return &ConfigError{Field: "wslHomePath", Message: fmt.Sprintf("must be a Linux path like /home/you, got %q", config.WSLHomePath)}
+	}
+	if config.ShellType == "docker" && config.DockerContainer == "" {
+		return &ConfigError{Field: "dockerContainer", Message: "required when shellType is docker"}
+	}
+	if config.ShellType == "ssh" && config.SSHHost == "" {
+		return &ConfigError{Field: "sshHost", Message: "required when shellType is ssh"}
+	}
+	if config.CommandPack != "" {
+		if _, err := GetPack(config.CommandPack); err != nil {
+			return &ConfigError{Field: "commandPack", Message: err.Error()}
+		}
+	}
+	if err := normalizeKeymap(config.Keymap); err != nil {
+		return &ConfigError{Field: "keymap", Message: err.Error()}
+	}
+	for _, root := range config.AllowedRoots {
+		if !filepath.IsAbs(root) && !strings.HasPrefix(root, `\\`) {
+			return &ConfigError{Field: "allowedRoots", Message: fmt.Sprintf("%q must be an absolute path", root)}
+		}
+	}
+	for _, dir := range config.AMPrivateWorkspaces {
+		if !filepath.IsAbs(dir) && !strings.HasPrefix(dir, `\\`) {
+			return &ConfigError{Field: "amPrivateWorkspaces", Message: fmt.Sprintf("%q must be an absolute path", dir)}
+		}
+	}
+	if err := llm.ValidatePrices(config.LLMPrices); err != nil {
+		return &ConfigError{Field: "llmPrices", Message: err.Error()}
+	}
+	if config.Port < 0 || config.Port > 65535 {
+		return &ConfigError{Field: "port", Message: fmt.Sprintf("must be between 0 and 65535, got %d", config.Port)}
+	}
+	for _, port := range config.PreferredPorts {
+		if port < 1 || port > 65535 {
+			return &ConfigError{Field: "preferredPorts", Message: fmt.Sprintf("ports must be between 1 and 65535, got %d", port)}
+		}
+	}
+	if (config.AuthUsername == "") != (config.AuthPassword == "") {
+		return &ConfigError{Field: "authUsername", Message: "authUsername and authPassword must be set together"}
+	}
+	if _, err := access.ParseAllowedIPs(config.AllowedIPs); err != nil {
+		return &ConfigError{Field: "allowedIPs", Message: err.Error()}
+	}
+	for _, origin := range config.AllowedOrigins {
+		if err := middleware.ValidateOrigin(origin); err != nil {
+			return &ConfigError{Field: "allowedOrigins", Message: err.Error()}
+		}
+	}
+	for _, origin := range config.FrameAncestors {
+		if err := middleware.ValidateOrigin(origin); err != nil {
+			return &ConfigError{Field: "frameAncestors", Message: err.Error()}
+		}
+	}
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return &ConfigError{Field: "tlsCertFile", Message: "tlsCertFile and tlsKeyFile must be set together"}
+	}
+	if config.Storage != nil {
+		if err := config.Storage.Validate(); err != nil {
+			return &ConfigError{Field: "storage", Message: err.Error()}
+		}
+	}
+	if err := (editor.Settings{Editor: config.Editor}).Validate(); err != nil {
+		return &ConfigError{Field: "editor", Message: err.Error()}
+	}
+	if err := (editor.Settings{Commands: config.EditorCommands}).Validate(); err != nil {
+		return &ConfigError{Field: "editorCommands", Message: err.Error()}
+	}
+	for _, rule := range config.DangerousCommands {
+		if err := rule.Validate(); err != nil {
+			return &ConfigError{Field: "dangerousCommands", Message: err.Error()}
+		}
+	}
+	if err := middleware.ValidateCapabilities(config.Capabilities); err != nil {
+		return &ConfigError{Field: "capabilities", Message: err.Error()}
+	}
+	if config.MaxTabsPerClient < 0 {
+		return &ConfigError{Field: "maxTabsPerClient", Message: "must not be negative"}
+	}
+	if config.MaxTabs < 0 {
+		return &ConfigError{Field: "maxTabs", Message: "must not be negative"}
+	}
+	if config.TrashRetentionDays < 0 {
+		return &ConfigError{Field: "trashRetentionDays", Message: "must not be negative"}
+	}
+	if config.UpdateCheckSeconds < 0 {
+		return &ConfigError{Field: "updateCheckSeconds", Message: "must not be negative"}
+	}
+	return nil
+}
+
+// MergeConfigDefaults adds fields from DefaultConfig that are missing from an
+// existing config file (e.g. settings introduced by an upgrade). Other fields,
+// including ones this version doesn't know, are left untouched. Returns the
+// names of the fields added.
+func MergeConfigDefaults() ([]string, error) {
+	path, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := storage.ReadJSONFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var existing map[string]json.RawMessage
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("config file is not valid JSON: %w", err)
+	}
+
+	defaultData, err := json.Marshal(DefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	var defaults map[string]json.RawMessage
+	if err := json.Unmarshal(defaultData, &defaults); err != nil {
+		return nil, err
+	}
+
+	added := []string{}
+	for key, value := range defaults {
+		if _, ok := existing[key]; !ok {
+			existing[key] = value
+			added = append(added, key)
+		}
+	}
+	if len(added) == 0 {
+		return added, nil
+	}
+	sort.Strings(added)
+
+	merged, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return added, storage.WriteFileAtomic(path, merged, 0600)
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func withConfigPath(t *testing.T) string {
+	t.Helper()
+	withTempHome(t)
+	return storage.GetTerminalConfigPath()
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	path := withConfigPath(t)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ShellType = "wsl"
+	if DefaultConfig.ShellType != "cmd" {
+		t.Fatal("LoadConfig returned the shared DefaultConfig")
+	}
+
+	// Fields missing from the file keep their defaults
+	if err := os.WriteFile(path, []byte(`{"wslDistro":"Ubuntu"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ShellType != "cmd" || config.WSLDistro != "Ubuntu" {
+		t.Errorf("config = %+v", config)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	base := Config{ShellType: "wsl", WSLDistro: "Ubuntu", AllowedRoots: []string{"/home"}}
+
+	config, warnings, err := ParseConfig([]byte(`{"shellType":"powershell","theme":"dark"}`), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ShellType != "powershell" || config.WSLDistro != "Ubuntu" || len(config.AllowedRoots) != 1 {
+		t.Errorf("config = %+v", config)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"theme"`) {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	tests := []struct {
+		body  string
+		field string
+		want  string
+	}{
+		{`{"shellType":"fish"}`, "shellType", "unknown shell"},
+		{`{"shellType":3}`, "shellType", "must be a string, not a number"},
+		{`{"allowedRoots":"/tmp"}`, "allowedRoots", "must be a list of strings"},
+		{`{"allowedRoots":["relative/dir"]}`, "allowedRoots", "absolute path"},
+		{`{"amPrivateWorkspaces":["secret-repo"]}`, "amPrivateWorkspaces", "absolute path"},
+		{`{"llmPrices":{"claude":{"inputPer1K":-0.003}}}`, "llmPrices", "must not be negative"},
+		{`{"updateCheckSeconds":"60"}`, "updateCheckSeconds", "must be a number"},
+		{`{"longCommandSeconds":"5m"}`, "longCommandSeconds", "must be a number"},
+		{`{"maxTabsPerClient":-1}`, "maxTabsPerClient", "must not be negative"},
+		{`{"trashRetentionDays":-1}`, "trashRetentionDays", "must not be negative"},
+		{`{"commandPack":"nope"}`, "commandPack", "unknown command pack"},
+		{`{"shellType":"wsl","wslHomePath":"C:\\Users\\me"}`, "wslHomePath", "Linux path"},
+		{`{"shellType":"docker"}`, "dockerContainer", "required"},
+		{`{"shellType":"ssh"}`, "sshHost", "required"},
+		{`{"editor":"emacs"}`, "editor", "unknown editor"},
+		{`{"editorCommands":{"linux":"subl"}}`, "editorCommands", "must include {path}"},
+		{`{"dangerousCommands":[{"name":"x","pattern":"("}]}`, "dangerousCommands", "invalid pattern"},
+		{`{"dangerousCommands":[{"name":"x","pattern":"x","action":"warn"}]}`, "dangerousCommands", "unknown action"},
+		{`{"capabilities":{"files.rename":false}}`, "capabilities", "unknown capability"},
+		{`{"tlsCertFile":"/etc/forge/cert.pem"}`, "tlsCertFile", "set together"},
+		{`{"port":70000}`, "port", "between 0 and 65535"},
+		{`{"preferredPorts":[8333,0]}`, "preferredPorts", "between 1 and 65535"},
+		{`{"authUsername":"me"}`, "authUsername", "set together"},
+		{`{"allowedIPs":["192.168.1.0/33"]}`, "allowedIPs", "invalid IP range"},
+		{`{"allowedOrigins":["portal.local"]}`, "allowedOrigins", "must be an origin"},
+		{`{"frameAncestors":["https://a.com; script-src *"]}`, "frameAncestors", "wildcard"},
+	}
+	for _, tt := range tests {
+		_, _, err := ParseConfig([]byte(tt.body), base)
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Errorf("%s: err = %v, want ConfigError", tt.body, err)
+			continue
+		}
+		if configErr.Field != tt.field || !strings.Contains(configErr.Message, tt.want) {
+			t.Errorf("%s: err = %v, want %s: %s", tt.body, err, tt.field, tt.want)
+		}
+	}
+
+	if _, _, err := ParseConfig([]byte(`{"shellType":`), base); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Errorf("truncated JSON err = %v", err)
+	}
+	if _, _, err := ParseConfig([]byte(`["cmd"]`), base); err == nil {
+		t.Error("expected error for non-object config")
+	}
+	if base.ShellType != "wsl" {
+		t.Error("ParseConfig modified base")
+	}
+}
+
+func TestMergeConfigDefaults(t *testing.T) {
+	path := withConfigPath(t)
+
+	added, err := MergeConfigDefaults()
+	if err != nil || len(added) != 0 {
+		t.Fatalf("missing file: added = %v, err = %v", added, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"wslDistro":"Ubuntu","futureSetting":true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	added, err = MergeConfigDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(added, ",") != "shellType,wslHomePath" {
+		t.Errorf("added = %v", added)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if merged["shellType"] != "cmd" || merged["wslDistro"] != "Ubuntu" || merged["futureSetting"] != true {
+		t.Errorf("merged = %v", merged)
+	}
+
+	added, err = MergeConfigDefaults()
+	if err != nil || len(added) != 0 {
+		t.Errorf("second merge: added = %v, err = %v", added, err)
+	}
+}
+
+func TestLoadConfigRecoversFromCorruptFile(t *testing.T) {
+	path := withConfigPath(t)
+
+	if err := SaveConfig(&Config{ShellType: "wsl"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveConfig(&Config{ShellType: "powershell"}); err != nil {
+		t.Fatal(err)
+	}
+	// Crash mid-write outside Forge's atomic writer
+	if err := os.WriteFile(path, []byte(`{"shellType":"pow`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ShellType != "wsl" {
+		t.Errorf("shellType = %q, want the last good version", config.ShellType)
+	}
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	valid := []string{"* * * * *", "*/15 * * * *", "30 8 * * 1-5", "0 9,17 * * *", "@daily", "0 0 1 * 7"}
+	for _, expr := range valid {
+		if _, err := parseCron(expr); err != nil {
+			t.Errorf("Expected %q to parse, got %v", expr, err)
+		}
+	}
+
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"}
+	for _, expr := range invalid {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// Monday 2024-01-15 08:30
+	monday := time.Date(2024, 1, 15, 8, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"30 8 * * 1-5", true},
+		{"30 8 * * 0,6", false},
+		{"*/15 * * * *", true},
+		{"*/20 * * * *", false},
+		{"30 8 15 * *", true},
+		{"30 8 1 * 1", true}, // Restricted dom and dow are ORed
+		{"30 8 1 * 2", false},
+		{"0 0 * * 7", false},
+	}
+	for _, tt := range tests {
+		spec, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := spec.matches(monday); got != tt.want {
+			t.Errorf("%q matches Monday 08:30 = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	spec, _ := parseCron("0 9 * * 1-5")
+	// Saturday 2024-01-13 10:00 -> Monday 09:00
+	from := time.Date(2024, 1, 13, 10, 0, 0, 0, time.Local)
+	want := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	if got := spec.next(from); !got.Equal(want) {
+		t.Errorf("Expected next run %v, got %v", want, got)
+	}
+
+	sunday, _ := parseCron("0 0 * * 7")
+	if got := sunday.next(from); got.Weekday() != time.Sunday {
+		t.Errorf("Expected 7 to mean Sunday, got %v", got.Weekday())
+	}
+}
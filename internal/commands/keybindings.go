@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key binding warning kinds
+const (
+	BindingDuplicate = "duplicate" // Same binding on more than one card
+	BindingReserved  = "reserved"  // Shadows a browser or terminal shortcut
+	BindingInvalid   = "invalid"   // Could not be parsed
+)
+
+// KeyBindingWarning describes a problem with a command's key binding.
+type KeyBindingWarning struct {
+	CommandID     int    `json:"commandId"`
+	KeyBinding    string `json:"keyBinding"`
+	Kind          string `json:"kind"`
+	Message       string `json:"message"`
+	ConflictsWith []int  `json:"conflictsWith,omitempty"` // Other card IDs for duplicates
+}
+
+// modifierOrder is the canonical modifier order in a normalized binding
+var modifierOrder = []string{"Ctrl", "Alt", "Shift", "Meta"}
+
+var modifierAliases = map[string]string{
+	"ctrl":    "Ctrl",
+	"control": "Ctrl",
+	"alt":     "Alt",
+	"option":  "Alt",
+	"opt":     "Alt",
+	"shift":   "Shift",
+	"meta":    "Meta",
+	"cmd":     "Meta",
+	"command": "Meta",
+	"win":     "Meta",
+	"super":   "Meta",
+}
+
+var namedKeys = map[string]string{
+	"enter": "Enter", "return": "Enter", "esc": "Escape", "escape": "Escape",
+	"tab": "Tab", "space": "Space", "backspace": "Backspace", "delete": "Delete", "del": "Delete",
+	"insert": "Insert", "home": "Home", "end": "End", "pageup": "PageUp", "pagedown": "PageDown",
+	"up": "ArrowUp", "down": "ArrowDown", "left": "ArrowLeft", "right": "ArrowRight",
+	"arrowup": "ArrowUp", "arrowdown": "ArrowDown", "arrowleft": "ArrowLeft", "arrowright": "ArrowRight",
+}
+
+// reservedKeyBindings are shortcuts the browser or shell handles before a card could,
+// keyed by normalized binding
+var reservedKeyBindings = map[string]string{
+	// Terminal control characters
+	"Ctrl+C": "interrupts the running program",
+	"Ctrl+D": "sends end-of-file / exits the shell",
+	"Ctrl+Z": "suspends the running program",
+	"Ctrl+L": "clears the screen",
+	"Ctrl+R": "searches shell history",
+	"Ctrl+A": "moves to start of line",
+	"Ctrl+E": "moves to end of line",
+	"Ctrl+U": "deletes to start of line",
+	"Ctrl+K": "deletes to end of line",
+	// Terminal copy/paste
+	"Ctrl+Shift+C": "copies the terminal selection",
+	"Ctrl+Shift+V": "pastes into the terminal",
+	// Browser
+	"Ctrl+T":       "opens a browser tab",
+	"Ctrl+W":       "closes the browser tab",
+	"Ctrl+N":       "opens a browser window",
+	"Ctrl+Q":       "quits the browser",
+	"Ctrl+Tab":     "switches browser tabs",
+	"Ctrl+Shift+T": "reopens a closed browser tab",
+	"Ctrl+Shift+N": "opens an incognito window",
+	"Ctrl+Shift+W": "closes the browser window",
+	"Ctrl+Shift+I": "opens developer tools",
+	"Ctrl+Shift+J": "opens the browser console",
+	"Alt+F4":       "closes the window",
+	"F5":           "reloads the page",
+	"Ctrl+F5":      "reloads the page",
+	"F11":          "toggles full screen",
+	"F12":          "opens developer tools",
+	"Meta+Q":       "quits the browser",
+	"Meta+W":       "closes the browser tab",
+	"Meta+T":       "opens a browser tab",
+}
+
+// NormalizeKeyBinding parses a binding like "shift+ctrl+a" into canonical form ("Ctrl+Shift+A").
+func NormalizeKeyBinding(binding string) (string, error) {
+	binding = strings.TrimSpace(binding)
+	if binding == "" {
+		return "", nil
+	}
+
+	parts := strings.Split(binding, "+")
+	// "Ctrl++" binds the plus key
+	if strings.HasSuffix(binding, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+
+	mods := map[string]bool{}
+	key := ""
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if mod, ok := modifierAliases[strings.ToLower(part)]; ok && i < len(parts)-1 {
+			mods[mod] = true
+			continue
+		}
+		if i != len(parts)-1 || part == "" {
+			return "", fmt.Errorf("invalid key binding %q", binding)
+		}
+		key = normalizeKey(part)
+		if key == "" {
+			return "", fmt.Errorf("unknown key %q in binding %q", part, binding)
+		}
+	}
+
+	normalized := []string{}
+	for _, mod := range modifierOrder {
+		if mods[mod] {
+			normalized = append(normalized, mod)
+		}
+	}
+	return strings.Join(append(normalized, key), "+"), nil
+}
+
+// normalizeKey returns the canonical name of a single key, or "" if unknown.
+func normalizeKey(key string) string {
+	if len([]rune(key)) == 1 {
+		return strings.ToUpper(key)
+	}
+	lower := strings.ToLower(key)
+	if named, ok := namedKeys[lower]; ok {
+		return named
+	}
+	if strings.HasPrefix(lower, "f") {
+		var n int
+		if _, err := fmt.Sscanf(lower, "f%d", &n); err == nil && n >= 1 && n <= 24 && fmt.Sprintf("f%d", n) == lower {
+			return fmt.Sprintf("F%d", n)
+		}
+	}
+	return ""
+}
+
+// NormalizeKeyBindings rewrites each command's binding in canonical form,
+// leaving unparseable bindings untouched for ValidateKeyBindings to report.
+func NormalizeKeyBindings(commands []Command) {
+	for i := range commands {
+		if normalized, err := NormalizeKeyBinding(commands[i].KeyBinding); err == nil {
+			commands[i].KeyBinding = normalized
+		}
+	}
+}
+
+// ValidateKeyBindings reports duplicate, reserved, and invalid bindings.
+func ValidateKeyBindings(commands []Command) []KeyBindingWarning {
+	warnings := []KeyBindingWarning{}
+	byBinding := map[string][]int{}
+
+	for _, cmd := range commands {
+		if strings.TrimSpace(cmd.KeyBinding) == "" {
+			continue
+		}
+		normalized, err := NormalizeKeyBinding(cmd.KeyBinding)
+		if err != nil {
+			warnings = append(warnings, KeyBindingWarning{
+				CommandID:  cmd.ID,
+				KeyBinding: cmd.KeyBinding,
+				Kind:       BindingInvalid,
+				Message:    err.Error(),
+			})
+			continue
+		}
+		byBinding[normalized] = append(byBinding[normalized], cmd.ID)
+
+		if reason, ok := reservedKeyBindings[normalized]; ok {
+			warnings = append(warnings, KeyBindingWarning{
+				CommandID:  cmd.ID,
+				KeyBinding: normalized,
+				Kind:       BindingReserved,
+				Message:    fmt.Sprintf("%s %s and may never reach the card", normalized, reason),
+			})
+		}
+	}
+
+	bindings := make([]string, 0, len(byBinding))
+	for binding := range byBinding {
+		bindings = append(bindings, binding)
+	}
+	sort.Strings(bindings)
+
+	for _, binding := range bindings {
+		ids := byBinding[binding]
+		if len(ids) < 2 {
+			continue
+		}
+		for _, id := range ids {
+			others := []int{}
+			for _, other := range ids {
+				if other != id {
+					others = append(others, other)
+				}
+			}
+			warnings = append(warnings, KeyBindingWarning{
+				CommandID:     id,
+				KeyBinding:    binding,
+				Kind:          BindingDuplicate,
+				Message:       fmt.Sprintf("%s is also bound to %d other card(s)", binding, len(others)),
+				ConflictsWith: others,
+			})
+		}
+	}
+	return warnings
+}
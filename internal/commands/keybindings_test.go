@@ -0,0 +1,79 @@
+package commands
+
+import "testing"
+
+func TestNormalizeKeyBinding(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"Ctrl+Shift+1", "Ctrl+Shift+1", false},
+		{"shift+ctrl+a", "Ctrl+Shift+A", false},
+		{"cmd+k", "Meta+K", false},
+		{"Alt+f4", "Alt+F4", false},
+		{"ctrl + enter", "Ctrl+Enter", false},
+		{"Ctrl++", "Ctrl++", false},
+		{"Ctrl+Up", "Ctrl+ArrowUp", false},
+		{"Ctrl+", "", true},
+		{"Ctrl+Foo", "", true},
+		{"F25", "", true},
+		{"A+Ctrl", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeKeyBinding(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeKeyBinding(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeKeyBinding(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateKeyBindings(t *testing.T) {
+	cmds := []Command{
+		{ID: 1, KeyBinding: "Ctrl+Shift+1"},
+		{ID: 2, KeyBinding: "shift+ctrl+1"},
+		{ID: 3, KeyBinding: "Ctrl+W"},
+		{ID: 4, KeyBinding: "Ctrl+Bogus"},
+		{ID: 5, KeyBinding: "Ctrl+Shift+9"},
+		{ID: 6},
+	}
+
+	warnings := ValidateKeyBindings(cmds)
+	kinds := map[int][]string{}
+	for _, w := range warnings {
+		kinds[w.CommandID] = append(kinds[w.CommandID], w.Kind)
+		if w.Kind == BindingDuplicate && len(w.ConflictsWith) != 1 {
+			t.Errorf("Expected one conflict for command %d, got %v", w.CommandID, w.ConflictsWith)
+		}
+	}
+
+	expect := map[int]string{1: BindingDuplicate, 2: BindingDuplicate, 3: BindingReserved, 4: BindingInvalid}
+	for id, kind := range expect {
+		if len(kinds[id]) != 1 || kinds[id][0] != kind {
+			t.Errorf("Command %d: expected [%s], got %v", id, kind, kinds[id])
+		}
+	}
+	if len(kinds[5]) != 0 || len(kinds[6]) != 0 {
+		t.Errorf("Expected no warnings for commands 5 and 6, got %v %v", kinds[5], kinds[6])
+	}
+}
+
+func TestDefaultPacks_NoReservedBindings(t *testing.T) {
+	for _, pack := range CommandPacks {
+		for _, w := range ValidateKeyBindings(pack.Commands) {
+			t.Errorf("Pack %s: %s", pack.ID, w.Message)
+		}
+	}
+}
+
+func TestNormalizeKeyBindings_LeavesInvalid(t *testing.T) {
+	cmds := []Command{{KeyBinding: "ctrl+shift+2"}, {KeyBinding: "Hyper+X"}}
+	NormalizeKeyBindings(cmds)
+	if cmds[0].KeyBinding != "Ctrl+Shift+2" || cmds[1].KeyBinding != "Hyper+X" {
+		t.Errorf("Unexpected bindings: %q %q", cmds[0].KeyBinding, cmds[1].KeyBinding)
+	}
+}
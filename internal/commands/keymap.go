@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Rebindable UI actions (keys of Config.Keymap)
+const (
+	ActionNewTab         = "newTab"
+	ActionCloseTab       = "closeTab"
+	ActionNextTab        = "nextTab"
+	ActionSearch         = "search"
+	ActionScrollToBottom = "scrollToBottom"
+	ActionToggleAM       = "toggleAM"
+	ActionOpenAssistant  = "openAssistant"
+)
+
+// DefaultKeymap is the binding for each action when the config doesn't override it
+var DefaultKeymap = map[string]string{
+	ActionNewTab:         "Ctrl+T",
+	ActionCloseTab:       "Ctrl+W",
+	ActionNextTab:        "Ctrl+Tab",
+	ActionSearch:         "Ctrl+F",
+	ActionScrollToBottom: "Ctrl+End",
+	ActionToggleAM:       "Ctrl+Alt+M",
+	ActionOpenAssistant:  "Ctrl+Alt+A",
+}
+
+// KeymapActions lists the rebindable actions in display order
+var KeymapActions = []string{
+	ActionNewTab, ActionCloseTab, ActionNextTab, ActionSearch,
+	ActionScrollToBottom, ActionToggleAM, ActionOpenAssistant,
+}
+
+// Keymap warning kinds (in addition to the key binding kinds)
+const (
+	BindingUnknownAction = "unknownAction" // Not a rebindable action
+	BindingCardConflict  = "cardConflict"  // Also bound to a command card
+)
+
+// KeymapWarning describes a problem with an action's binding.
+type KeymapWarning struct {
+	Action        string   `json:"action"`
+	KeyBinding    string   `json:"keyBinding"`
+	Kind          string   `json:"kind"`
+	Message       string   `json:"message"`
+	ConflictsWith []string `json:"conflictsWith,omitempty"` // Other actions for duplicates
+	CommandIDs    []int    `json:"commandIds,omitempty"`    // Cards for card conflicts
+}
+
+// ResolveKeymap returns the effective binding for every action: the defaults
+// overridden by keymap. An empty binding in keymap unbinds the action.
+func ResolveKeymap(keymap map[string]string) map[string]string {
+	resolved := make(map[string]string, len(DefaultKeymap))
+	for action, binding := range DefaultKeymap {
+		resolved[action] = binding
+	}
+	for action, binding := range keymap {
+		if _, ok := DefaultKeymap[action]; ok {
+			resolved[action] = binding
+		}
+	}
+	return resolved
+}
+
+// normalizeKeymap checks that every action is known and every binding parses,
+// rewriting bindings in canonical form.
+func normalizeKeymap(keymap map[string]string) error {
+	for _, action := range sortedKeys(keymap) {
+		if _, ok := DefaultKeymap[action]; !ok {
+			return fmt.Errorf("unknown action %q", action)
+		}
+		normalized, err := NormalizeKeyBinding(keymap[action])
+		if err != nil {
+			return fmt.Errorf("%s: %w", action, err)
+		}
+		keymap[action] = normalized
+	}
+	return nil
+}
+
+// ValidateKeymap reports unknown actions, invalid or reserved bindings, actions
+// sharing a binding, and bindings also used by command cards. The default
+// bindings intentionally take over some browser shortcuts, so they are not
+// reported as reserved.
+func ValidateKeymap(keymap map[string]string, cmds []Command) []KeymapWarning {
+	warnings := []KeymapWarning{}
+	resolved := ResolveKeymap(nil)
+
+	for _, action := range sortedKeys(keymap) {
+		binding := keymap[action]
+		if _, ok := DefaultKeymap[action]; !ok {
+			warnings = append(warnings, KeymapWarning{
+				Action:     action,
+				KeyBinding: binding,
+				Kind:       BindingUnknownAction,
+				Message:    fmt.Sprintf("%q is not a rebindable action", action),
+			})
+			continue
+		}
+		normalized, err := NormalizeKeyBinding(binding)
+		if err != nil {
+			warnings = append(warnings, KeymapWarning{
+				Action:     action,
+				KeyBinding: binding,
+				Kind:       BindingInvalid,
+				Message:    err.Error(),
+			})
+			delete(resolved, action)
+			continue
+		}
+		resolved[action] = normalized
+
+		if reason, ok := reservedKeyBindings[normalized]; ok && normalized != DefaultKeymap[action] {
+			warnings = append(warnings, KeymapWarning{
+				Action:     action,
+				KeyBinding: normalized,
+				Kind:       BindingReserved,
+				Message:    fmt.Sprintf("%s %s and may never reach Forge", normalized, reason),
+			})
+		}
+	}
+
+	byBinding := map[string][]string{}
+	for _, action := range sortedKeys(resolved) {
+		if resolved[action] != "" {
+			byBinding[resolved[action]] = append(byBinding[resolved[action]], action)
+		}
+	}
+	cardsByBinding := map[string][]int{}
+	for _, cmd := range cmds {
+		if normalized, err := NormalizeKeyBinding(cmd.KeyBinding); err == nil && normalized != "" {
+			cardsByBinding[normalized] = append(cardsByBinding[normalized], cmd.ID)
+		}
+	}
+
+	for _, binding := range sortedKeys(byBinding) {
+		actions := byBinding[binding]
+		for _, action := range actions {
+			if len(actions) > 1 {
+				others := []string{}
+				for _, other := range actions {
+					if other != action {
+						others = append(others, other)
+					}
+				}
+				warnings = append(warnings, KeymapWarning{
+					Action:        action,
+					KeyBinding:    binding,
+					Kind:          BindingDuplicate,
+					Message:       fmt.Sprintf("%s is also bound to %d other action(s)", binding, len(others)),
+					ConflictsWith: others,
+				})
+			}
+			if ids := cardsByBinding[binding]; len(ids) > 0 {
+				warnings = append(warnings, KeymapWarning{
+					Action:     action,
+					KeyBinding: binding,
+					Kind:       BindingCardConflict,
+					Message:    fmt.Sprintf("%s is also bound to %d command card(s); the action takes precedence", binding, len(ids)),
+					CommandIDs: ids,
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// sortedKeys returns a map's keys in order, for deterministic warnings.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
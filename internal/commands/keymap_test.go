@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestResolveKeymap(t *testing.T) {
+	resolved := ResolveKeymap(map[string]string{
+		ActionNewTab:   "Ctrl+Alt+T",
+		ActionToggleAM: "",
+		"bogus":        "Ctrl+B",
+	})
+	if resolved[ActionNewTab] != "Ctrl+Alt+T" || resolved[ActionToggleAM] != "" || resolved[ActionSearch] != "Ctrl+F" {
+		t.Errorf("resolved = %v", resolved)
+	}
+	if _, ok := resolved["bogus"]; ok {
+		t.Error("unknown action was resolved")
+	}
+	if DefaultKeymap[ActionNewTab] != "Ctrl+T" {
+		t.Error("ResolveKeymap modified DefaultKeymap")
+	}
+}
+
+func TestValidateKeymap(t *testing.T) {
+	cmds := []Command{{ID: 7, KeyBinding: "ctrl+alt+a"}}
+	warnings := ValidateKeymap(map[string]string{
+		"bogus":             "Ctrl+B",
+		ActionSearch:        "Ctrl++Q",
+		ActionNewTab:        "Ctrl+D",
+		ActionCloseTab:      "alt+ctrl+x",
+		ActionNextTab:       "Ctrl+Alt+X",
+		ActionToggleAM:      "",
+		ActionOpenAssistant: "Ctrl+Alt+A",
+	}, cmds)
+
+	kinds := map[string][]string{}
+	for _, w := range warnings {
+		kinds[w.Action] = append(kinds[w.Action], w.Kind)
+	}
+	want := map[string][]string{
+		"bogus":             {BindingUnknownAction},
+		ActionSearch:        {BindingInvalid},
+		ActionNewTab:        {BindingReserved},
+		ActionCloseTab:      {BindingDuplicate},
+		ActionNextTab:       {BindingDuplicate},
+		ActionOpenAssistant: {BindingCardConflict},
+	}
+	if len(kinds) != len(want) {
+		t.Fatalf("warnings = %+v", warnings)
+	}
+	for action, wantKinds := range want {
+		if len(kinds[action]) != len(wantKinds) || kinds[action][0] != wantKinds[0] {
+			t.Errorf("%s: kinds = %v, want %v", action, kinds[action], wantKinds)
+		}
+	}
+
+	// The defaults take over browser shortcuts on purpose
+	if warnings := ValidateKeymap(nil, nil); len(warnings) != 0 {
+		t.Errorf("default keymap warnings = %+v", warnings)
+	}
+}
+
+func TestParseConfigKeymap(t *testing.T) {
+	base := Config{Keymap: map[string]string{ActionSearch: "Ctrl+Alt+F"}}
+
+	config, _, err := ParseConfig([]byte(`{"keymap":{"newTab":"alt+ctrl+t"}}`), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Keymap[ActionNewTab] != "Ctrl+Alt+T" || config.Keymap[ActionSearch] != "Ctrl+Alt+F" {
+		t.Errorf("keymap = %v", config.Keymap)
+	}
+	if len(base.Keymap) != 1 {
+		t.Error("ParseConfig modified base keymap")
+	}
+
+	if _, _, err := ParseConfig([]byte(`{"keymap":{"launchRockets":"Ctrl+R"}}`), base); err == nil {
+		t.Error("expected error for unknown action")
+	}
+	if _, _, err := ParseConfig([]byte(`{"keymap":{"newTab":"Ctrl+Nope+"}}`), base); err == nil {
+		t.Error("expected error for invalid binding")
+	}
+}
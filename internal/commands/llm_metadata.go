@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+// LLMProvider identifies the AI CLI a command card launches, in the short form
+// stored on cards. AM uses the llm package's names; see AMProvider.
+type LLMProvider string
+
+const (
+	LLMProviderCopilot LLMProvider = "copilot"
+	LLMProviderClaude  LLMProvider = "claude"
+	LLMProviderAider   LLMProvider = "aider"
+)
+
+// DefaultLLMType is used for cards with triggerAM set but no llmType
+const DefaultLLMType = llm.CommandChat
+
+// LLMProviders and LLMTypes are the values accepted on command cards
+var (
+	LLMProviders = []LLMProvider{LLMProviderCopilot, LLMProviderClaude, LLMProviderAider}
+	LLMTypes     = []llm.CommandType{llm.CommandChat, llm.CommandSuggest, llm.CommandExplain, llm.CommandCode}
+)
+
+// ShellTypes are the values accepted for Command.ShellType
+var ShellTypes = []string{"cmd", "powershell", "wsl", "docker", "tmux", "ssh"}
+
+// cardProviders maps AM's provider names to the short form stored on cards
+var cardProviders = map[llm.Provider]LLMProvider{
+	llm.ProviderGitHubCopilot: LLMProviderCopilot,
+	llm.ProviderClaude:        LLMProviderClaude,
+	llm.ProviderAider:         LLMProviderAider,
+}
+
+// ParseLLMProvider normalizes a provider name, accepting any spelling
+// llm.ParseProvider does. An empty name returns "".
+func ParseLLMProvider(name string) (LLMProvider, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil
+	}
+	if provider, ok := cardProviders[llm.ParseProvider(name)]; ok {
+		return provider, nil
+	}
+	return "", fmt.Errorf("unknown llmProvider %q (expected copilot, claude, or aider)", strings.ToLower(strings.TrimSpace(name)))
+}
+
+// ParseLLMType normalizes a command type. An empty type returns "".
+func ParseLLMType(name string) (llm.CommandType, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil
+	}
+	if t := llm.ParseCommandType(name); t != llm.CommandUnknown {
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown llmType %q (expected chat, suggest, explain, or code)", strings.ToLower(strings.TrimSpace(name)))
+}
+
+// AMProvider returns the provider name used by AM conversation logging.
+func (p LLMProvider) AMProvider() llm.Provider {
+	return llm.ParseProvider(string(p))
+}
+
+// NormalizeLLMMetadata validates and canonicalizes a card's LLM fields, applying
+// the documented defaults for cards that trigger AM: the provider is inferred from
+// the command text and the type defaults to DefaultLLMType.
+func NormalizeLLMMetadata(cmd *Command) error {
+	provider, err := ParseLLMProvider(string(cmd.LLMProvider))
+	if err != nil {
+		return err
+	}
+	llmType, err := ParseLLMType(string(cmd.LLMType))
+	if err != nil {
+		return err
+	}
+
+	if cmd.TriggerAM {
+		if provider == "" {
+			provider = inferProviderFromCommand(cmd.Command, cmd.Description)
+		}
+		if llmType == "" {
+			llmType = DefaultLLMType
+		}
+	}
+
+	cmd.LLMProvider = provider
+	cmd.LLMType = llmType
+	return nil
+}
+
+// ValidateCommand checks a card's typed fields, normalizing LLM metadata in place.
+func ValidateCommand(cmd *Command) error {
+	if err := NormalizeLLMMetadata(cmd); err != nil {
+		return fmt.Errorf("command %d: %w", cmd.ID, err)
+	}
+	if cmd.ShellType != "" {
+		valid := false
+		for _, shell := range ShellTypes {
+			if cmd.ShellType == shell {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("command %d: unknown shellType %q (expected %s)", cmd.ID, cmd.ShellType, strings.Join(ShellTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// CommandSchema describes the enumerated Command fields and their defaults for clients.
+type CommandSchema struct {
+	LLMProviders   []LLMProvider     `json:"llmProviders"`
+	LLMTypes       []llm.CommandType `json:"llmTypes"`
+	DefaultLLMType llm.CommandType   `json:"defaultLlmType"`
+	ShellTypes     []string          `json:"shellTypes"`
+	Placeholders   []string          `json:"placeholders"`
+}
+
+// GetCommandSchema returns the accepted values for Command fields.
+func GetCommandSchema() CommandSchema {
+	return CommandSchema{
+		LLMProviders:   LLMProviders,
+		LLMTypes:       LLMTypes,
+		DefaultLLMType: DefaultLLMType,
+		ShellTypes:     ShellTypes,
+		Placeholders:   []string{"{{input:name}}", "{{cwd}}", "{{clipboard}}", "{{selection}}"},
+	}
+}
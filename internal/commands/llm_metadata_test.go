@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+func TestParseLLMProvider(t *testing.T) {
+	tests := map[string]LLMProvider{
+		"":               "",
+		"copilot":        LLMProviderCopilot,
+		"GitHub-Copilot": LLMProviderCopilot,
+		"gh-copilot":     LLMProviderCopilot,
+		" Claude ":       LLMProviderClaude,
+		"aider":          LLMProviderAider,
+	}
+	for in, want := range tests {
+		got, err := ParseLLMProvider(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLLMProvider(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLLMProvider("gpt"); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+	if _, err := ParseLLMType("refactor"); err == nil {
+		t.Error("Expected error for unknown type")
+	}
+}
+
+func TestAMProvider(t *testing.T) {
+	if LLMProviderCopilot.AMProvider() != llm.ProviderGitHubCopilot {
+		t.Errorf("Expected copilot to map to %s", llm.ProviderGitHubCopilot)
+	}
+	if LLMProvider("").AMProvider() != llm.ProviderUnknown {
+		t.Error("Expected empty provider to map to unknown")
+	}
+}
+
+func TestValidateCommand(t *testing.T) {
+	cmd := Command{ID: 1, Command: "claude", TriggerAM: true}
+	if err := ValidateCommand(&cmd); err != nil {
+		t.Fatalf("ValidateCommand failed: %v", err)
+	}
+	if cmd.LLMProvider != LLMProviderClaude || cmd.LLMType != DefaultLLMType {
+		t.Errorf("Expected inferred defaults, got %q/%q", cmd.LLMProvider, cmd.LLMType)
+	}
+
+	cmd = Command{ID: 2, LLMProvider: "Copilot", LLMType: "Suggest"}
+	if err := ValidateCommand(&cmd); err != nil || cmd.LLMProvider != LLMProviderCopilot || cmd.LLMType != llm.CommandSuggest {
+		t.Errorf("Expected canonical values, got %q/%q (%v)", cmd.LLMProvider, cmd.LLMType, err)
+	}
+
+	// Without triggerAM no defaults are applied
+	cmd = Command{ID: 3, Command: "claude"}
+	ValidateCommand(&cmd)
+	if cmd.LLMProvider != "" || cmd.LLMType != "" {
+		t.Errorf("Expected no defaults without triggerAM, got %q/%q", cmd.LLMProvider, cmd.LLMType)
+	}
+
+	invalid := []Command{
+		{ID: 4, LLMProvider: "gpt"},
+		{ID: 5, LLMType: "poem"},
+		{ID: 6, ShellType: "zsh"},
+	}
+	for _, c := range invalid {
+		if err := ValidateCommand(&c); err == nil {
+			t.Errorf("Expected command %d to be rejected", c.ID)
+		}
+	}
+}
+
+func TestMigrateCommands_CanonicalProvider(t *testing.T) {
+	migrated, changed := MigrateCommands([]Command{{ID: 1, Order: 1, TriggerAM: true, LLMProvider: "github-copilot", LLMType: "chat"}})
+	if !changed || migrated[0].LLMProvider != LLMProviderCopilot {
+		t.Errorf("Expected provider canonicalized, got %q (changed=%v)", migrated[0].LLMProvider, changed)
+	}
+}
@@ -3,6 +3,8 @@ package commands
 import (
 	"log"
 	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
 )
 
 // MigrateCommands upgrades legacy command cards to include new LLM metadata fields.
@@ -26,32 +28,43 @@ func MigrateCommands(commands []Command) ([]Command, bool) {
 
 		// Set default llmType if missing but triggerAM is enabled
 		if cmd.TriggerAM && cmd.LLMType == "" {
-			updated.LLMType = "chat"
+			updated.LLMType = DefaultLLMType
 			anyChanged = true
 			log.Printf("[Commands] Migration: Set default type 'chat' for command '%s'", cmd.Description)
 		}
 
+		// Canonicalize provider spellings (e.g. "github-copilot" -> "copilot")
+		if provider, err := ParseLLMProvider(string(updated.LLMProvider)); err == nil && provider != updated.LLMProvider {
+			updated.LLMProvider = provider
+			anyChanged = true
+		}
+
+		// Normalize tags so filtering is case-insensitive and duplicate-free
+		if tags := NormalizeTags(cmd.Tags); !equalTags(tags, cmd.Tags) {
+			updated.Tags = tags
+			anyChanged = true
+		}
+
 		migrated = append(migrated, updated)
 	}
 
+	// Legacy cards have no sort order - derive it from their position in the list
+	for i := range migrated {
+		if migrated[i].Order == 0 {
+			NormalizeOrder(migrated)
+			anyChanged = true
+			log.Printf("[Commands] Migration: Assigned sort order to %d commands", len(migrated))
+			break
+		}
+	}
+
 	return migrated, anyChanged
 }
 
-// inferProviderFromCommand attempts to detect LLM provider from command text
-func inferProviderFromCommand(command, description string) string {
-	combined := strings.ToLower(command + " " + description)
-
-	if strings.Contains(combined, "copilot") || strings.Contains(combined, "gh copilot") {
-		return "copilot"
-	}
-	if strings.Contains(combined, "claude") {
-		return "claude"
-	}
-	if strings.Contains(combined, "aider") {
-		return "aider"
-	}
-
-	return ""
+// inferProviderFromCommand infers a card's provider from its command and
+// description the way AM does (llm.InferProvider), or returns "".
+func inferProviderFromCommand(command, description string) LLMProvider {
+	return cardProviders[llm.InferProvider(strings.TrimSpace(command+" "+description))]
 }
 
 // AutoMigrateOnLoad performs automatic migration when commands are loaded
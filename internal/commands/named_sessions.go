@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// DefaultSessionName is the name the current layout is saved under when
+// switching away from it before any named session was active
+const DefaultSessionName = "default"
+
+// ErrSessionNotFound is returned for unknown named sessions
+var ErrSessionNotFound = errors.New("session not found")
+
+// namedSessionsMu serializes reads and writes of the named sessions file
+var namedSessionsMu sync.Mutex
+
+// validSessionName allows short names like "work", "personal", or "forge-terminal"
+var validSessionName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+
+// NamedSession is a saved tab layout the user can switch to.
+type NamedSession struct {
+	Name      string    `json:"name"`
+	Session   Session   `json:"session"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NamedSessionSummary describes a named session without its tabs.
+type NamedSessionSummary struct {
+	Name      string    `json:"name"`
+	TabCount  int       `json:"tabCount"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Active    bool      `json:"active"`
+}
+
+// namedSessionsFile is the on-disk format
+type namedSessionsFile struct {
+	Active   string                   `json:"active"` // Name the current layout belongs to ("" = none yet)
+	Sessions map[string]*NamedSession `json:"sessions"`
+}
+
+// ValidateSessionName checks a session name.
+func ValidateSessionName(name string) error {
+	if name == "history" {
+		return fmt.Errorf("session name %q is reserved", name)
+	}
+	if !validSessionName.MatchString(name) {
+		return fmt.Errorf("invalid session name %q (letters, digits, spaces, '.', '_' and '-', up to 64 characters)", name)
+	}
+	return nil
+}
+
+func loadNamedSessions() (*namedSessionsFile, error) {
+	file := &namedSessionsFile{Sessions: map[string]*NamedSession{}}
+	data, err := storage.ReadJSONFile(storage.GetNamedSessionsPath())
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse named sessions: %w", err)
+	}
+	if file.Sessions == nil {
+		file.Sessions = map[string]*NamedSession{}
+	}
+	return file, nil
+}
+
+func saveNamedSessions(file *namedSessionsFile) error {
+	path := storage.GetNamedSessionsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// ListNamedSessions returns all named sessions sorted by name, and the active one's name.
+func ListNamedSessions() ([]NamedSessionSummary, string, error) {
+	namedSessionsMu.Lock()
+	defer namedSessionsMu.Unlock()
+
+	file, err := loadNamedSessions()
+	if err != nil {
+		return nil, "", err
+	}
+	summaries := make([]NamedSessionSummary, 0, len(file.Sessions))
+	for name, ns := range file.Sessions {
+		summaries = append(summaries, NamedSessionSummary{
+			Name:      name,
+			TabCount:  len(ns.Session.Tabs),
+			UpdatedAt: ns.UpdatedAt,
+			Active:    name == file.Active,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries, file.Active, nil
+}
+
+// GetNamedSession returns a named session. The active session reflects the
+// current layout, which may have changed since it was last switched to.
+func GetNamedSession(name string) (*NamedSession, error) {
+	namedSessionsMu.Lock()
+	defer namedSessionsMu.Unlock()
+
+	file, err := loadNamedSessions()
+	if err != nil {
+		return nil, err
+	}
+	ns, ok := file.Sessions[name]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if name == file.Active {
+		current, err := LoadSession()
+		if err != nil {
+			return nil, err
+		}
+		ns.Session = *current
+	}
+	return ns, nil
+}
+
+// SaveNamedSession creates or replaces a named session. Saving the active
+// session also replaces the current layout.
+func SaveNamedSession(name string, session Session) (*NamedSession, error) {
+	if err := ValidateSessionName(name); err != nil {
+		return nil, err
+	}
+
+	namedSessionsMu.Lock()
+	defer namedSessionsMu.Unlock()
+
+	file, err := loadNamedSessions()
+	if err != nil {
+		return nil, err
+	}
+	if session.Tabs == nil {
+		session.Tabs = []TabState{}
+	}
+	ns := &NamedSession{Name: name, Session: session, UpdatedAt: time.Now()}
+	file.Sessions[name] = ns
+	if err := saveNamedSessions(file); err != nil {
+		return nil, err
+	}
+	if name == file.Active {
+		if err := SaveSession(&session); err != nil {
+			return nil, err
+		}
+	}
+	return ns, nil
+}
+
+// DeleteNamedSession removes a named session. Deleting the active session
+// leaves the current layout in place, no longer tied to a name.
+func DeleteNamedSession(name string) error {
+	namedSessionsMu.Lock()
+	defer namedSessionsMu.Unlock()
+
+	file, err := loadNamedSessions()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Sessions[name]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(file.Sessions, name)
+	if file.Active == name {
+		file.Active = ""
+	}
+	return saveNamedSessions(file)
+}
+
+// SwitchSession saves the current layout under the active name (or
+// DefaultSessionName), then makes the named session the current layout.
+func SwitchSession(name string) (*Session, error) {
+	namedSessionsMu.Lock()
+	defer namedSessionsMu.Unlock()
+
+	file, err := loadNamedSessions()
+	if err != nil {
+		return nil, err
+	}
+	target, ok := file.Sessions[name]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if name == file.Active {
+		return LoadSession()
+	}
+
+	current, err := LoadSession()
+	if err != nil {
+		return nil, err
+	}
+	previous := file.Active
+	if previous == "" {
+		previous = DefaultSessionName
+		// Don't overwrite a saved "default" with an empty layout
+		if _, exists := file.Sessions[previous]; exists && len(current.Tabs) == 0 {
+			previous = ""
+		}
+	}
+	if previous != "" {
+		file.Sessions[previous] = &NamedSession{Name: previous, Session: *current, UpdatedAt: time.Now()}
+	}
+
+	next := target.Session
+	if err := SaveSession(&next); err != nil {
+		return nil, err
+	}
+	file.Active = name
+	if err := saveNamedSessions(file); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
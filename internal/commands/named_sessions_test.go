@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"testing"
+)
+
+func layout(ids ...string) Session {
+	s := Session{Tabs: []TabState{}}
+	for _, id := range ids {
+		s.Tabs = append(s.Tabs, TabState{ID: id, Title: id})
+	}
+	if len(ids) > 0 {
+		s.ActiveTabID = ids[0]
+	}
+	return s
+}
+
+func TestSwitchSession(t *testing.T) {
+	withTempHome(t)
+
+	// The current layout before any named session exists
+	current := layout("a", "b")
+	if err := SaveSession(&current); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveNamedSession("work", layout("w1", "w2", "w3")); err != nil {
+		t.Fatal(err)
+	}
+
+	switched, err := SwitchSession("work")
+	if err != nil {
+		t.Fatalf("SwitchSession: %v", err)
+	}
+	if len(switched.Tabs) != 3 {
+		t.Errorf("expected work layout, got %+v", switched)
+	}
+	if loaded, _ := LoadSession(); len(loaded.Tabs) != 3 || loaded.ActiveTabID != "w1" {
+		t.Errorf("current layout not replaced: %+v", loaded)
+	}
+
+	// The previous layout was kept as "default"
+	def, err := GetNamedSession(DefaultSessionName)
+	if err != nil || len(def.Session.Tabs) != 2 {
+		t.Fatalf("expected previous layout saved as default, got %+v (%v)", def, err)
+	}
+
+	// Changes to the active layout are carried back when switching away
+	edited := layout("w1")
+	SaveSession(&edited)
+	if _, err := SwitchSession(DefaultSessionName); err != nil {
+		t.Fatal(err)
+	}
+	work, _ := GetNamedSession("work")
+	if len(work.Session.Tabs) != 1 {
+		t.Errorf("expected edited work layout to be saved, got %d tabs", len(work.Session.Tabs))
+	}
+
+	list, active, err := ListNamedSessions()
+	if err != nil || active != DefaultSessionName || len(list) != 2 || !list[0].Active || list[1].TabCount != 1 {
+		t.Errorf("unexpected list %+v (active %q, %v)", list, active, err)
+	}
+
+	if _, err := SwitchSession("missing"); err != ErrSessionNotFound {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestNamedSessionCRUD(t *testing.T) {
+	withTempHome(t)
+
+	if _, err := SaveNamedSession("../escape", layout()); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+	if _, err := SaveNamedSession("personal", layout("p1")); err != nil {
+		t.Fatal(err)
+	}
+	ns, err := GetNamedSession("personal")
+	if err != nil || ns.Session.Tabs[0].ID != "p1" {
+		t.Fatalf("unexpected session %+v (%v)", ns, err)
+	}
+
+	if err := DeleteNamedSession("personal"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetNamedSession("personal"); err != ErrSessionNotFound {
+		t.Errorf("expected deleted session to be gone, got %v", err)
+	}
+	if err := DeleteNamedSession("personal"); err != ErrSessionNotFound {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+	"github.com/mikejsmith1985/forge-terminal/internal/updater"
+)
+
+// onboardingMu serializes read-modify-write updates of the onboarding file
+var onboardingMu sync.Mutex
+
+// validOnboardingKey allows feature and tour IDs like "am-panel" or "tour.files"
+var validOnboardingKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Announcement introduces a feature to users upgrading to (or past) Version.
+type Announcement struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+// Announcements are shown once to users who upgrade past their version.
+// Fresh installs start with the welcome screen instead.
+var Announcements = []Announcement{
+	{
+		ID:      "named-sessions",
+		Version: "1.23.0",
+		Title:   "Named sessions",
+		Body:    "Save tab layouts under a name and switch between them from the sessions menu.",
+	},
+	{
+		ID:      "keymap",
+		Version: "1.23.0",
+		Title:   "Rebindable shortcuts",
+		Body:    "New tab, toggle AM, and open assistant shortcuts can now be changed in Settings and are stored on the server.",
+	},
+}
+
+// TourProgress is how far the user got through a guided tour.
+type TourProgress struct {
+	Step      int       `json:"step"`
+	Completed bool      `json:"completed"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OnboardingState records which intro UI the user has already seen.
+type OnboardingState struct {
+	WelcomeVersion string                  `json:"welcomeVersion,omitempty"` // Version the welcome screen was last shown for
+	Seen           map[string]time.Time    `json:"seen"`                     // Feature intro ID -> when dismissed
+	Tours          map[string]TourProgress `json:"tours"`
+	Dismissed      map[string]time.Time    `json:"dismissed"`               // Announcement ID -> when dismissed
+	AnnouncedFrom  string                  `json:"announcedFrom,omitempty"` // Announcements at or before this version are not shown
+}
+
+// LoadOnboarding returns the saved onboarding state. Installs that predate the
+// onboarding file are seeded from the legacy welcome flag.
+func LoadOnboarding() (*OnboardingState, error) {
+	onboardingMu.Lock()
+	defer onboardingMu.Unlock()
+	return loadOnboarding()
+}
+
+func loadOnboarding() (*OnboardingState, error) {
+	state := &OnboardingState{}
+	data, err := storage.ReadJSONFile(storage.GetOnboardingPath())
+	switch {
+	case os.IsNotExist(err):
+		if version := legacyWelcomeVersion(); version != "" {
+			state.WelcomeVersion = version
+			state.AnnouncedFrom = version
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to read onboarding file: %w", err)
+	default:
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to parse onboarding JSON: %w", err)
+		}
+	}
+
+	if state.Seen == nil {
+		state.Seen = map[string]time.Time{}
+	}
+	if state.Tours == nil {
+		state.Tours = map[string]TourProgress{}
+	}
+	if state.Dismissed == nil {
+		state.Dismissed = map[string]time.Time{}
+	}
+	return state, nil
+}
+
+// UpdateOnboarding applies update to the saved state and saves the result.
+func UpdateOnboarding(update func(state *OnboardingState) error) (*OnboardingState, error) {
+	onboardingMu.Lock()
+	defer onboardingMu.Unlock()
+
+	state, err := loadOnboarding()
+	if err != nil {
+		return nil, err
+	}
+	if err := update(state); err != nil {
+		return nil, err
+	}
+
+	path := storage.GetOnboardingPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return state, storage.WriteFileAtomic(path, data, 0600)
+}
+
+// ValidateOnboardingKey checks a feature or tour ID.
+func ValidateOnboardingKey(key string) error {
+	if !validOnboardingKey.MatchString(key) {
+		return fmt.Errorf("invalid id %q (letters, digits, '.', '_' and '-', up to 64 characters)", key)
+	}
+	return nil
+}
+
+// MarkSeen records that a feature's intro UI was shown.
+func (s *OnboardingState) MarkSeen(feature string) error {
+	if err := ValidateOnboardingKey(feature); err != nil {
+		return err
+	}
+	if _, ok := s.Seen[feature]; !ok {
+		s.Seen[feature] = time.Now()
+	}
+	return nil
+}
+
+// SetTourStep records progress through a tour.
+func (s *OnboardingState) SetTourStep(tour string, step int, completed bool) error {
+	if err := ValidateOnboardingKey(tour); err != nil {
+		return err
+	}
+	if step < 0 {
+		return fmt.Errorf("tour step must not be negative")
+	}
+	s.Tours[tour] = TourProgress{Step: step, Completed: completed, UpdatedAt: time.Now()}
+	return nil
+}
+
+// DismissAnnouncements hides announcements by ID.
+func (s *OnboardingState) DismissAnnouncements(ids ...string) {
+	now := time.Now()
+	for _, id := range ids {
+		s.Dismissed[id] = now
+	}
+}
+
+// PendingAnnouncements returns announcements for versions after AnnouncedFrom,
+// up to and including current, that haven't been dismissed. Nothing is pending
+// before the user has finished the welcome screen once.
+func (s *OnboardingState) PendingAnnouncements(current string) []Announcement {
+	pending := []Announcement{}
+	if s.AnnouncedFrom == "" {
+		return pending
+	}
+	for _, a := range Announcements {
+		if _, dismissed := s.Dismissed[a.ID]; dismissed {
+			continue
+		}
+		if updater.CompareVersions(a.Version, s.AnnouncedFrom) > 0 && updater.CompareVersions(a.Version, current) <= 0 {
+			pending = append(pending, a)
+		}
+	}
+	return pending
+}
+
+// legacyWelcomeVersion reads the version from the pre-onboarding welcome flag file.
+func legacyWelcomeVersion() string {
+	path, err := GetWelcomeShownPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func withAnnouncements(t *testing.T, announcements []Announcement) {
+	t.Helper()
+	original := Announcements
+	Announcements = announcements
+	t.Cleanup(func() { Announcements = original })
+}
+
+func TestOnboardingState(t *testing.T) {
+	withTempHome(t)
+
+	_, err := UpdateOnboarding(func(state *OnboardingState) error {
+		if err := state.MarkSeen("am-panel"); err != nil {
+			return err
+		}
+		return state.SetTourStep("intro", 2, false)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadOnboarding()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Seen["am-panel"]; !ok {
+		t.Errorf("seen = %v", state.Seen)
+	}
+	if state.Tours["intro"].Step != 2 || state.Tours["intro"].Completed {
+		t.Errorf("tours = %v", state.Tours)
+	}
+
+	if err := state.MarkSeen("../escape"); err == nil {
+		t.Error("expected error for invalid feature id")
+	}
+	if err := state.SetTourStep("intro", -1, false); err == nil {
+		t.Error("expected error for negative step")
+	}
+}
+
+func TestWelcomeShownUsesOnboarding(t *testing.T) {
+	withTempHome(t)
+	if IsWelcomeShown("1.0.0") {
+		t.Fatal("welcome shown before it was set")
+	}
+	if err := SetWelcomeShown("1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsWelcomeShown("1.0.0") || IsWelcomeShown("1.1.0") {
+		t.Error("welcome shown state is wrong")
+	}
+	state, _ := LoadOnboarding()
+	if state.AnnouncedFrom != "1.0.0" {
+		t.Errorf("announcedFrom = %q", state.AnnouncedFrom)
+	}
+}
+
+func TestOnboardingMigratesLegacyWelcomeFlag(t *testing.T) {
+	withTempHome(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	legacy := filepath.Join(storage.GetTerminalDir(), "welcome_shown")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("1.20.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadOnboarding()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.WelcomeVersion != "1.20.0" || state.AnnouncedFrom != "1.20.0" {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestPendingAnnouncements(t *testing.T) {
+	withAnnouncements(t, []Announcement{
+		{ID: "old", Version: "1.1.0"},
+		{ID: "mid", Version: "1.3.0"},
+		{ID: "new", Version: "1.5.0"},
+	})
+
+	state := &OnboardingState{Dismissed: map[string]time.Time{}}
+	if pending := state.PendingAnnouncements("1.5.0"); len(pending) != 0 {
+		t.Errorf("fresh install pending = %v", pending)
+	}
+
+	state.AnnouncedFrom = "1.2.0"
+	ids := func(list []Announcement) string {
+		s := ""
+		for _, a := range list {
+			s += a.ID + " "
+		}
+		return s
+	}
+	if got := ids(state.PendingAnnouncements("1.5.0")); got != "mid new " {
+		t.Errorf("pending = %q", got)
+	}
+	if got := ids(state.PendingAnnouncements("v1.4.0")); got != "mid " {
+		t.Errorf("pending at 1.4.0 = %q", got)
+	}
+
+	state.DismissAnnouncements("mid")
+	if got := ids(state.PendingAnnouncements("1.5.0")); got != "new " {
+		t.Errorf("pending after dismiss = %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// NormalizeOrder sorts commands by their Order field and renumbers them 1..n.
+// Commands without an order go last, keeping their relative position in the list.
+func NormalizeOrder(commands []Command) {
+	key := func(order int) int {
+		if order <= 0 {
+			return math.MaxInt32
+		}
+		return order
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return key(commands[i].Order) < key(commands[j].Order)
+	})
+	AssignOrderFromPosition(commands)
+}
+
+// AssignOrderFromPosition sets Order from each command's index, treating the
+// list order as authoritative (e.g. after a drag-and-drop reorder in the UI).
+func AssignOrderFromPosition(commands []Command) {
+	for i := range commands {
+		commands[i].Order = i + 1
+	}
+}
+
+// NormalizeTags trims, lowercases, and de-duplicates tags, preserving order.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	return normalized
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CommandFilter selects commands by group and/or tag.
+type CommandFilter struct {
+	Group    string
+	HasGroup bool // Filter on Group even when it is empty (ungrouped cards)
+	Tag      string
+}
+
+// FilterCommands returns the commands matching filter, in Order.
+func FilterCommands(commands []Command, filter CommandFilter) []Command {
+	tag := strings.ToLower(strings.TrimSpace(filter.Tag))
+
+	result := []Command{}
+	for _, cmd := range commands {
+		if filter.HasGroup && !strings.EqualFold(cmd.Group, filter.Group) {
+			continue
+		}
+		if tag != "" && !hasTag(cmd, tag) {
+			continue
+		}
+		result = append(result, cmd)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Order < result[j].Order
+	})
+	return result
+}
+
+func hasTag(cmd Command, tag string) bool {
+	for _, t := range cmd.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
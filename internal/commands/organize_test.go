@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestMigrateCommands_AssignsOrder(t *testing.T) {
+	cmds := []Command{
+		{ID: 1, Description: "a"},
+		{ID: 2, Description: "b", Tags: []string{" Git ", "git", "Build"}},
+		{ID: 3, Description: "c"},
+	}
+
+	migrated, changed := MigrateCommands(cmds)
+	if !changed {
+		t.Fatal("Expected migration to report changes")
+	}
+	for i, cmd := range migrated {
+		if cmd.Order != i+1 || cmd.ID != i+1 {
+			t.Errorf("Expected command %d at order %d, got ID=%d order=%d", i+1, i+1, cmd.ID, cmd.Order)
+		}
+	}
+	if len(migrated[1].Tags) != 2 || migrated[1].Tags[0] != "git" || migrated[1].Tags[1] != "build" {
+		t.Errorf("Expected normalized tags [git build], got %v", migrated[1].Tags)
+	}
+
+	// Already-migrated commands are left alone
+	if _, changed := MigrateCommands(migrated); changed {
+		t.Error("Expected no changes on second migration")
+	}
+}
+
+func TestNormalizeOrder(t *testing.T) {
+	cmds := []Command{
+		{ID: 1, Order: 3},
+		{ID: 2},
+		{ID: 3, Order: 1},
+		{ID: 4},
+	}
+
+	NormalizeOrder(cmds)
+
+	expected := []int{3, 1, 2, 4}
+	for i, id := range expected {
+		if cmds[i].ID != id || cmds[i].Order != i+1 {
+			t.Errorf("Position %d: expected ID %d order %d, got ID %d order %d", i, id, i+1, cmds[i].ID, cmds[i].Order)
+		}
+	}
+}
+
+func TestFilterCommands(t *testing.T) {
+	cmds := []Command{
+		{ID: 1, Order: 2, Group: "Git", Tags: []string{"vcs"}},
+		{ID: 2, Order: 1, Group: "git", Tags: []string{"vcs", "daily"}},
+		{ID: 3, Order: 3, Group: "", Tags: []string{"daily"}},
+	}
+
+	byGroup := FilterCommands(cmds, CommandFilter{Group: "git", HasGroup: true})
+	if len(byGroup) != 2 || byGroup[0].ID != 2 {
+		t.Errorf("Expected group filter to return IDs [2 1], got %+v", byGroup)
+	}
+
+	ungrouped := FilterCommands(cmds, CommandFilter{HasGroup: true})
+	if len(ungrouped) != 1 || ungrouped[0].ID != 3 {
+		t.Errorf("Expected only ungrouped command, got %+v", ungrouped)
+	}
+
+	byTag := FilterCommands(cmds, CommandFilter{Tag: "DAILY"})
+	if len(byTag) != 2 || byTag[0].ID != 2 || byTag[1].ID != 3 {
+		t.Errorf("Expected tag filter to return IDs [2 3], got %+v", byTag)
+	}
+
+	combined := FilterCommands(cmds, CommandFilter{Group: "git", HasGroup: true, Tag: "daily"})
+	if len(combined) != 1 || combined[0].ID != 2 {
+		t.Errorf("Expected combined filter to return ID 2, got %+v", combined)
+	}
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPackID is the pack installed on first run when none was chosen
+const DefaultPackID = "claude"
+
+// Pack install modes
+const (
+	PackMerge   = "merge"   // Add pack commands not already present
+	PackReplace = "replace" // Replace all commands with the pack
+)
+
+// CommandPack is a named set of starter command cards for a workflow.
+type CommandPack struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Commands    []Command `json:"commands"`
+}
+
+// CommandPacks are the built-in packs offered at first run and via the packs API.
+var CommandPacks = []CommandPack{
+	{
+		ID:          "claude",
+		Name:        "Claude Code",
+		Description: "Prompts for driving Claude Code: design, execute, reset, and conversation recall.",
+		Commands:    DefaultCommands,
+	},
+	{
+		ID:          "copilot",
+		Name:        "GitHub Copilot CLI",
+		Description: "Copilot CLI suggest/explain shortcuts and common gh workflows.",
+		Commands: []Command{
+			{
+				ID:          1,
+				Description: "🤖 Copilot Suggest",
+				Command:     "gh copilot suggest ",
+				KeyBinding:  "Ctrl+Shift+1",
+				PasteOnly:   true,
+				Favorite:    true,
+				TriggerAM:   true,
+				LLMProvider: "copilot",
+				LLMType:     "suggest",
+			},
+			{
+				ID:          2,
+				Description: "💡 Copilot Explain",
+				Command:     "gh copilot explain ",
+				KeyBinding:  "Ctrl+Shift+2",
+				PasteOnly:   true,
+				TriggerAM:   true,
+				LLMProvider: "copilot",
+				LLMType:     "explain",
+			},
+			{
+				ID:          3,
+				Description: "📋 My Pull Requests",
+				Command:     "gh pr status",
+				KeyBinding:  "Ctrl+Shift+3",
+			},
+			{
+				ID:          4,
+				Description: "🔀 Checkout PR",
+				Command:     "gh pr checkout {{input:PR number}}",
+				KeyBinding:  "Ctrl+Shift+4",
+			},
+			{
+				ID:          5,
+				Description: "🧪 PR Checks",
+				Command:     "gh pr checks",
+				KeyBinding:  "Ctrl+Shift+5",
+			},
+		},
+	},
+	{
+		ID:          "devops",
+		Name:        "Git & DevOps",
+		Description: "Plain git, Docker and Kubernetes commands with no AI tooling.",
+		Commands: []Command{
+			{
+				ID:          1,
+				Description: "⬇️ Pull (rebase)",
+				Command:     "git pull --rebase",
+				KeyBinding:  "Ctrl+Shift+1",
+				Favorite:    true,
+				Group:       "git",
+			},
+			{
+				ID:          2,
+				Description: "📊 Status",
+				Command:     "git status -sb",
+				KeyBinding:  "Ctrl+Shift+2",
+				Group:       "git",
+			},
+			{
+				ID:          3,
+				Description: "📜 Recent Log",
+				Command:     "git log --oneline --graph -20",
+				KeyBinding:  "Ctrl+Shift+3",
+				Group:       "git",
+			},
+			{
+				ID:          4,
+				Description: "🐳 Compose Up",
+				Command:     "docker compose up -d",
+				KeyBinding:  "Ctrl+Shift+4",
+				Group:       "docker",
+			},
+			{
+				ID:          5,
+				Description: "📦 Containers",
+				Command:     "docker ps --format \"table {{.Names}}\\t{{.Status}}\\t{{.Ports}}\"",
+				KeyBinding:  "Ctrl+Shift+5",
+				Group:       "docker",
+			},
+			{
+				ID:          6,
+				Description: "☸️ Pods",
+				Command:     "kubectl get pods",
+				Group:       "kubernetes",
+			},
+		},
+	},
+}
+
+// GetPack returns a built-in pack by ID.
+func GetPack(id string) (*CommandPack, error) {
+	for i := range CommandPacks {
+		if CommandPacks[i].ID == id {
+			return &CommandPacks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown command pack %q", id)
+}
+
+// packCommands returns a copy of a pack's commands, safe to modify.
+func packCommands(pack *CommandPack) []Command {
+	cmds := make([]Command, len(pack.Commands))
+	copy(cmds, pack.Commands)
+	return cmds
+}
+
+// InstallPack adds a pack to existing commands. Merge skips cards whose command
+// text is already present and renumbers/clears key bindings like an import;
+// replace discards the existing cards.
+func InstallPack(existing []Command, packID, mode string) ([]Command, ImportSummary, error) {
+	pack, err := GetPack(packID)
+	if err != nil {
+		return nil, ImportSummary{}, err
+	}
+
+	switch mode {
+	case PackReplace:
+		cmds := packCommands(pack)
+		NormalizeOrder(cmds)
+		return cmds, ImportSummary{Added: len(cmds), ClearedKeys: []string{}}, nil
+
+	case "", PackMerge:
+		present := make(map[string]bool, len(existing))
+		for _, cmd := range existing {
+			present[strings.TrimSpace(cmd.Command)] = true
+		}
+
+		bundle := &ExportBundle{}
+		skipped := 0
+		for _, cmd := range packCommands(pack) {
+			if present[strings.TrimSpace(cmd.Command)] {
+				skipped++
+				continue
+			}
+			bundle.Commands = append(bundle.Commands, cmd)
+		}
+
+		merged, summary, err := ImportCommands(existing, bundle, ConflictDuplicate)
+		summary.Skipped += skipped
+		return merged, summary, err
+
+	default:
+		return nil, ImportSummary{}, fmt.Errorf("unknown install mode %q", mode)
+	}
+}
+
+// SelectedPackCommands returns the commands of the pack chosen in config
+// (DefaultPackID if none), used on first run and by "restore defaults".
+func SelectedPackCommands() []Command {
+	packID := DefaultPackID
+	if config, err := LoadConfig(); err == nil && config.CommandPack != "" {
+		packID = config.CommandPack
+	}
+	pack, err := GetPack(packID)
+	if err != nil {
+		pack, _ = GetPack(DefaultPackID)
+	}
+	return packCommands(pack)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import "testing"
+
+func TestCommandPacks_Valid(t *testing.T) {
+	seen := map[string]bool{}
+	for _, pack := range CommandPacks {
+		if seen[pack.ID] {
+			t.Errorf("Duplicate pack ID %s", pack.ID)
+		}
+		seen[pack.ID] = true
+
+		ids := map[int]bool{}
+		for _, cmd := range pack.Commands {
+			if cmd.ID <= 0 || ids[cmd.ID] {
+				t.Errorf("Pack %s: invalid or duplicate command ID %d", pack.ID, cmd.ID)
+			}
+			ids[cmd.ID] = true
+			if cmd.KeyBinding != "" && keyBindingInUse(pack.Commands, cmd.KeyBinding, cmd.ID-1) {
+				t.Errorf("Pack %s: duplicate key binding %s", pack.ID, cmd.KeyBinding)
+			}
+		}
+	}
+	if !seen[DefaultPackID] {
+		t.Errorf("Default pack %s is missing", DefaultPackID)
+	}
+}
+
+func TestInstallPack_Merge(t *testing.T) {
+	existing := []Command{
+		{ID: 1, Command: "git status -sb", KeyBinding: "Ctrl+Shift+1", Order: 1},
+		{ID: 2, Command: "make test", Order: 2},
+	}
+
+	merged, summary, err := InstallPack(existing, "devops", PackMerge)
+	if err != nil {
+		t.Fatalf("InstallPack failed: %v", err)
+	}
+
+	devops, _ := GetPack("devops")
+	if summary.Skipped != 1 || summary.Added != len(devops.Commands)-1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if len(merged) != 2+summary.Added {
+		t.Errorf("Expected %d commands, got %d", 2+summary.Added, len(merged))
+	}
+	if merged[0].ID != 1 || merged[0].KeyBinding != "Ctrl+Shift+1" {
+		t.Errorf("Existing card should be untouched, got %+v", merged[0])
+	}
+
+	ids := map[int]bool{}
+	for _, cmd := range merged {
+		if ids[cmd.ID] {
+			t.Errorf("Duplicate ID %d after merge", cmd.ID)
+		}
+		ids[cmd.ID] = true
+	}
+}
+
+func TestInstallPack_Replace(t *testing.T) {
+	cmds, _, err := InstallPack([]Command{{ID: 1, Command: "old"}}, "copilot", PackReplace)
+	if err != nil {
+		t.Fatalf("InstallPack failed: %v", err)
+	}
+	copilot, _ := GetPack("copilot")
+	if len(cmds) != len(copilot.Commands) || cmds[0].Order != 1 {
+		t.Errorf("Expected copilot pack in order, got %+v", cmds)
+	}
+	if copilot.Commands[0].Order != 0 {
+		t.Error("Installing must not modify the built-in pack")
+	}
+
+	if _, _, err := InstallPack(nil, "nope", PackReplace); err == nil {
+		t.Error("Expected error for unknown pack")
+	}
+	if _, _, err := InstallPack(nil, "devops", "append"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
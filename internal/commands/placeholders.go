@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder kinds supported in Command.Command
+const (
+	PlaceholderInput     = "input"     // {{input:Label}} - prompted from the user
+	PlaceholderCwd       = "cwd"       // {{cwd}} - working directory of the target tab
+	PlaceholderClipboard = "clipboard" // {{clipboard}} - current clipboard text
+	PlaceholderSelection = "selection" // {{selection}} - text selected in the terminal
+)
+
+// placeholderPattern matches {{kind}} and {{kind:name}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(input|cwd|clipboard|selection)\s*(?::([^{}]*))?\}\}`)
+
+// Placeholder is a single {{...}} occurrence in a command.
+type Placeholder struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"` // Label for input placeholders
+}
+
+// RenderContext supplies values for placeholders. Clipboard and selection live
+// in the browser, so the client passes them along with the render request.
+type RenderContext struct {
+	Cwd       string            `json:"cwd,omitempty"`
+	Clipboard string            `json:"clipboard,omitempty"`
+	Selection string            `json:"selection,omitempty"`
+	Inputs    map[string]string `json:"inputs,omitempty"` // Input label -> value
+}
+
+// RenderResult is the outcome of expanding a command's placeholders.
+type RenderResult struct {
+	Command  string        `json:"command"`  // Expanded text (unresolved placeholders left intact)
+	Complete bool          `json:"complete"` // True when every placeholder was resolved
+	Inputs   []Placeholder `json:"inputs"`   // All input placeholders, in order of first use
+	Missing  []Placeholder `json:"missing"`  // Placeholders that still need a value
+}
+
+// ParsePlaceholders returns the distinct placeholders in text, in order of first use.
+func ParsePlaceholders(text string) []Placeholder {
+	seen := map[Placeholder]bool{}
+	placeholders := []Placeholder{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		p := Placeholder{Kind: match[1]}
+		if p.Kind == PlaceholderInput {
+			p.Name = strings.TrimSpace(match[2])
+		}
+		if !seen[p] {
+			seen[p] = true
+			placeholders = append(placeholders, p)
+		}
+	}
+	return placeholders
+}
+
+// HasPlaceholders reports whether text contains any placeholders.
+func HasPlaceholders(text string) bool {
+	return placeholderPattern.MatchString(text)
+}
+
+// RenderCommand expands placeholders in text using ctx.
+func RenderCommand(text string, ctx RenderContext) RenderResult {
+	result := RenderResult{
+		Inputs:  []Placeholder{},
+		Missing: []Placeholder{},
+	}
+
+	for _, p := range ParsePlaceholders(text) {
+		if p.Kind == PlaceholderInput {
+			result.Inputs = append(result.Inputs, p)
+		}
+		if _, ok := placeholderValue(p, ctx); !ok {
+			result.Missing = append(result.Missing, p)
+		}
+	}
+
+	result.Command = placeholderPattern.ReplaceAllStringFunc(text, func(raw string) string {
+		match := placeholderPattern.FindStringSubmatch(raw)
+		p := Placeholder{Kind: match[1]}
+		if p.Kind == PlaceholderInput {
+			p.Name = strings.TrimSpace(match[2])
+		}
+		if value, ok := placeholderValue(p, ctx); ok {
+			return value
+		}
+		return raw
+	})
+	result.Complete = len(result.Missing) == 0
+	return result
+}
+
+// placeholderValue looks up the value for p. Inputs may legitimately be empty
+// strings, so presence in the map is what counts.
+func placeholderValue(p Placeholder, ctx RenderContext) (string, bool) {
+	switch p.Kind {
+	case PlaceholderInput:
+		value, ok := ctx.Inputs[p.Name]
+		return value, ok
+	case PlaceholderCwd:
+		return ctx.Cwd, ctx.Cwd != ""
+	case PlaceholderClipboard:
+		return ctx.Clipboard, ctx.Clipboard != ""
+	case PlaceholderSelection:
+		return ctx.Selection, ctx.Selection != ""
+	}
+	return "", false
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestParsePlaceholders(t *testing.T) {
+	text := `git commit -m "{{input:message}}" && cd {{cwd}} && echo {{input: message }} {{ clipboard }} {{unknown}}`
+
+	placeholders := ParsePlaceholders(text)
+	if len(placeholders) != 3 {
+		t.Fatalf("Expected 3 distinct placeholders, got %+v", placeholders)
+	}
+	if placeholders[0] != (Placeholder{Kind: PlaceholderInput, Name: "message"}) {
+		t.Errorf("Unexpected first placeholder: %+v", placeholders[0])
+	}
+	if placeholders[1].Kind != PlaceholderCwd || placeholders[2].Kind != PlaceholderClipboard {
+		t.Errorf("Unexpected placeholders: %+v", placeholders)
+	}
+}
+
+func TestRenderCommand(t *testing.T) {
+	text := `git commit -m "{{input:message}}" -- {{selection}}`
+
+	result := RenderCommand(text, RenderContext{})
+	if result.Complete {
+		t.Error("Expected incomplete render without values")
+	}
+	if len(result.Inputs) != 1 || result.Inputs[0].Name != "message" {
+		t.Errorf("Expected message input, got %+v", result.Inputs)
+	}
+	if len(result.Missing) != 2 {
+		t.Errorf("Expected 2 missing placeholders, got %+v", result.Missing)
+	}
+	if result.Command != text {
+		t.Errorf("Expected unresolved placeholders to be left intact, got %q", result.Command)
+	}
+
+	result = RenderCommand(text, RenderContext{
+		Selection: "main.go",
+		Inputs:    map[string]string{"message": "fix bug"},
+	})
+	if !result.Complete {
+		t.Errorf("Expected complete render, missing %+v", result.Missing)
+	}
+	if result.Command != `git commit -m "fix bug" -- main.go` {
+		t.Errorf("Unexpected render: %q", result.Command)
+	}
+}
+
+func TestRenderCommand_EmptyInputAllowed(t *testing.T) {
+	result := RenderCommand("echo {{input:suffix}}done", RenderContext{Inputs: map[string]string{"suffix": ""}})
+	if !result.Complete || result.Command != "echo done" {
+		t.Errorf("Expected empty input to resolve, got %+v", result)
+	}
+}
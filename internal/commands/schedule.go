@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// schedulerInterval is how often the scheduler checks for due commands
+const schedulerInterval = 15 * time.Second
+
+// startupWindow is how long a startup command waits for its tab to open before giving up
+const startupWindow = 10 * time.Minute
+
+// Schedule runs a command automatically at startup and/or on a cron-like schedule.
+type Schedule struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	CommandID    int      `json:"commandId,omitempty"` // Card to run...
+	Command      string   `json:"command,omitempty"`   // ...or inline command text
+	TabID        string   `json:"tabId,omitempty"`     // Tab to run in ("" = first open tab)
+	Dirs         []string `json:"dirs,omitempty"`      // Run once per directory, with {{cwd}} set to each
+	RunAtStartup bool     `json:"runAtStartup,omitempty"`
+	Cron         string   `json:"cron,omitempty"` // "m h dom mon dow" or @hourly/@daily/@weekly/...
+	Enabled      bool     `json:"enabled"`
+}
+
+// ScheduleStatus reports when a schedule last ran and will next run.
+type ScheduleStatus struct {
+	LastRun   *time.Time `json:"lastRun,omitempty"`
+	LastRunID string     `json:"lastRunId,omitempty"` // Chain run ID, for progress via the chains API
+	LastError string     `json:"lastError,omitempty"`
+	NextRun   *time.Time `json:"nextRun,omitempty"`
+}
+
+// ScheduleView is a schedule with its runtime status, as returned by the API.
+type ScheduleView struct {
+	Schedule
+	ScheduleStatus
+}
+
+// TargetResolver finds the terminal for a tab ID ("" = any open tab).
+type TargetResolver func(tabID string) (target ChainTarget, resolvedTabID string, err error)
+
+var schedulesMutex sync.Mutex
+
+// LoadSchedules returns saved schedules.
+func LoadSchedules() ([]Schedule, error) {
+	schedulesMutex.Lock()
+	defer schedulesMutex.Unlock()
+
+	data, err := storage.ReadJSONFile(storage.GetSchedulesPath())
+	if os.IsNotExist(err) {
+		return []Schedule{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules file: %w", err)
+	}
+
+	var schedules []Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules JSON: %w", err)
+	}
+	return schedules, nil
+}
+
+// SaveSchedules validates and saves schedules, assigning IDs to new ones.
+func SaveSchedules(schedules []Schedule) error {
+	for i := range schedules {
+		if schedules[i].ID == "" {
+			schedules[i].ID = uuid.New().String()
+		}
+		if err := validateSchedule(schedules[i]); err != nil {
+			return err
+		}
+	}
+
+	schedulesMutex.Lock()
+	defer schedulesMutex.Unlock()
+
+	path := storage.GetSchedulesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+func validateSchedule(s Schedule) error {
+	if s.Name == "" {
+		return fmt.Errorf("schedule %s: name is required", s.ID)
+	}
+	if s.CommandID == 0 && s.Command == "" {
+		return fmt.Errorf("schedule %q: commandId or command is required", s.Name)
+	}
+	if !s.RunAtStartup && s.Cron == "" {
+		return fmt.Errorf("schedule %q: set runAtStartup or cron", s.Name)
+	}
+	if s.Cron != "" {
+		if _, err := parseCron(s.Cron); err != nil {
+			return fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// scheduleChain converts a schedule into a chain with one step per directory.
+// Placeholders are rendered here since a schedule has nobody to prompt for inputs.
+func scheduleChain(s Schedule, cmds []Command) (Chain, error) {
+	text := s.Command
+	if s.CommandID != 0 {
+		found := false
+		for _, cmd := range cmds {
+			if cmd.ID == s.CommandID {
+				text, found = cmd.Command, true
+				break
+			}
+		}
+		if !found {
+			return Chain{}, fmt.Errorf("command %d not found", s.CommandID)
+		}
+	}
+
+	dirs := s.Dirs
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+
+	chain := Chain{ID: "schedule:" + s.ID, Name: s.Name}
+	for _, dir := range dirs {
+		rendered := RenderCommand(text, RenderContext{Cwd: dir})
+		if !rendered.Complete {
+			return Chain{}, fmt.Errorf("scheduled commands cannot use input placeholders")
+		}
+		chain.Steps = append(chain.Steps, ChainStep{Command: rendered.Command, WaitForPrompt: len(dirs) > 1})
+	}
+	return chain, nil
+}
+
+// Scheduler runs startup and cron schedules against open terminal tabs.
+type Scheduler struct {
+	resolve TargetResolver
+
+	mu          sync.Mutex
+	status      map[string]*ScheduleStatus
+	startupDone map[string]bool
+	lastFired   map[string]time.Time // Minute each cron schedule last fired
+	startedAt   time.Time
+	stop        chan struct{}
+}
+
+// NewScheduler creates a scheduler that resolves tabs with resolve.
+func NewScheduler(resolve TargetResolver) *Scheduler {
+	return &Scheduler{
+		resolve:     resolve,
+		status:      make(map[string]*ScheduleStatus),
+		startupDone: make(map[string]bool),
+		lastFired:   make(map[string]time.Time),
+	}
+}
+
+// Start begins checking schedules in the background.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.startedAt = time.Now()
+	stop := s.stop
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+		s.tick(time.Now())
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				s.tick(now)
+			}
+		}
+	}()
+}
+
+// Stop halts the background loop.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// tick runs any schedules that are due at now.
+func (s *Scheduler) tick(now time.Time) {
+	schedules, err := LoadSchedules()
+	if err != nil {
+		log.Printf("[Commands] Scheduler: %v", err)
+		return
+	}
+
+	minute := now.Truncate(time.Minute)
+	for _, sched := range schedules {
+		if !sched.Enabled {
+			continue
+		}
+
+		if sched.RunAtStartup && s.startupPending(sched.ID) {
+			if _, _, err := s.resolve(sched.TabID); err != nil {
+				// The browser may not have opened the tab yet; keep retrying for a while
+				if now.Sub(s.startedAt) > startupWindow {
+					s.markStartupDone(sched.ID)
+					s.record(sched.ID, nil, fmt.Errorf("startup: %w", err))
+				}
+			} else {
+				s.markStartupDone(sched.ID)
+				s.run(sched)
+			}
+		}
+
+		if sched.Cron == "" {
+			continue
+		}
+		spec, err := parseCron(sched.Cron)
+		if err != nil || !spec.matches(minute) {
+			continue
+		}
+		s.mu.Lock()
+		due := !s.lastFired[sched.ID].Equal(minute)
+		s.lastFired[sched.ID] = minute
+		s.mu.Unlock()
+		if due {
+			s.run(sched)
+		}
+	}
+}
+
+func (s *Scheduler) startupPending(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.startupDone[id]
+}
+
+func (s *Scheduler) markStartupDone(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startupDone[id] = true
+}
+
+// run starts a schedule's chain and records the outcome.
+func (s *Scheduler) run(sched Schedule) (*ChainRun, error) {
+	run, err := s.start(sched)
+	s.record(sched.ID, run, err)
+	if err != nil {
+		log.Printf("[Commands] Schedule %q failed: %v", sched.Name, err)
+	} else {
+		log.Printf("[Commands] Schedule %q started in tab %s", sched.Name, run.TabID)
+	}
+	return run, err
+}
+
+func (s *Scheduler) start(sched Schedule) (*ChainRun, error) {
+	var cmds []Command
+	if sched.CommandID != 0 {
+		var err error
+		if cmds, err = LoadCommands(); err != nil {
+			return nil, err
+		}
+	}
+	chain, err := scheduleChain(sched, cmds)
+	if err != nil {
+		return nil, err
+	}
+	// Nobody is there to confirm, so commands needing confirmation are blocked
+	decision, err := CheckChain(chain, cmds, RenderContext{})
+	if err != nil {
+		return nil, err
+	}
+	if !decision.Allowed(false) {
+		return nil, fmt.Errorf("blocked by the dangerous-command guard (%s): %s", decision.Rule, decision.Reason)
+	}
+	target, tabID, err := s.resolve(sched.TabID)
+	if err != nil {
+		return nil, err
+	}
+	return StartChain(chain, cmds, tabID, target, RenderContext{})
+}
+
+func (s *Scheduler) record(id string, run *ChainRun, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	st := s.status[id]
+	if st == nil {
+		st = &ScheduleStatus{}
+		s.status[id] = st
+	}
+	st.LastRun = &now
+	st.LastRunID = ""
+	st.LastError = ""
+	if run != nil {
+		st.LastRunID = run.ID
+	}
+	if err != nil {
+		st.LastError = err.Error()
+	}
+}
+
+// RunNow runs a saved schedule immediately, regardless of its timing or enabled flag.
+func (s *Scheduler) RunNow(id string) (*ChainRun, error) {
+	schedules, err := LoadSchedules()
+	if err != nil {
+		return nil, err
+	}
+	for _, sched := range schedules {
+		if sched.ID == id {
+			return s.run(sched)
+		}
+	}
+	return nil, fmt.Errorf("schedule %s not found", id)
+}
+
+// List returns saved schedules with their last and next run times.
+func (s *Scheduler) List() ([]ScheduleView, error) {
+	schedules, err := LoadSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	views := make([]ScheduleView, 0, len(schedules))
+	for _, sched := range schedules {
+		view := ScheduleView{Schedule: sched}
+		if st := s.status[sched.ID]; st != nil {
+			view.ScheduleStatus = *st
+		}
+		if sched.Enabled && sched.Cron != "" {
+			if spec, err := parseCron(sched.Cron); err == nil {
+				if next := spec.next(now); !next.IsZero() {
+					view.NextRun = &next
+				}
+			}
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
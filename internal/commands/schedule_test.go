@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveSchedules_Validation(t *testing.T) {
+	withTempHome(t)
+
+	invalid := []Schedule{
+		{Name: "No command", Cron: "@daily"},
+		{Name: "No timing", Command: "git fetch"},
+		{Name: "Bad cron", Command: "git fetch", Cron: "every morning"},
+	}
+	for _, s := range invalid {
+		if err := SaveSchedules([]Schedule{s}); err == nil {
+			t.Errorf("Expected %q to be rejected", s.Name)
+		}
+	}
+
+	schedules := []Schedule{{Name: "Fetch", Command: "git fetch --all", Cron: "0 9 * * 1-5", Enabled: true}}
+	if err := SaveSchedules(schedules); err != nil {
+		t.Fatalf("SaveSchedules failed: %v", err)
+	}
+	loaded, err := LoadSchedules()
+	if err != nil || len(loaded) != 1 || loaded[0].ID == "" {
+		t.Errorf("Unexpected loaded schedules: %+v (%v)", loaded, err)
+	}
+}
+
+func TestScheduleChain_Dirs(t *testing.T) {
+	s := Schedule{Name: "Fetch all", Command: "git -C {{cwd}} fetch --all", Dirs: []string{"/a", "/b"}}
+	chain, err := scheduleChain(s, nil)
+	if err != nil {
+		t.Fatalf("scheduleChain failed: %v", err)
+	}
+	if len(chain.Steps) != 2 || chain.Steps[1].Command != "git -C /b fetch --all" || !chain.Steps[0].WaitForPrompt {
+		t.Errorf("Unexpected steps: %+v", chain.Steps)
+	}
+
+	s.Command = "echo {{input:name}}"
+	if _, err := scheduleChain(s, nil); err == nil {
+		t.Error("Expected error for input placeholder")
+	}
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	withTempHome(t)
+
+	schedules := []Schedule{
+		{ID: "startup", Name: "Startup", Command: "echo hello", RunAtStartup: true, Enabled: true},
+		{ID: "cron", Name: "Half hour", Command: "echo tick", Cron: "30 * * * *", Enabled: true},
+		{ID: "off", Name: "Disabled", Command: "echo off", RunAtStartup: true, Enabled: false},
+	}
+	if err := SaveSchedules(schedules); err != nil {
+		t.Fatalf("SaveSchedules failed: %v", err)
+	}
+
+	target := &fakeTarget{}
+	tabOpen := false
+	s := NewScheduler(func(tabID string) (ChainTarget, string, error) {
+		if !tabOpen {
+			return nil, "", fmt.Errorf("no terminal tabs are open")
+		}
+		return target, "tab-1", nil
+	})
+	s.startedAt = time.Now()
+
+	at := func(min int) time.Time { return time.Date(2024, 1, 15, 8, min, 0, 0, time.Local) }
+
+	// No tab yet: startup command waits
+	s.tick(at(0))
+	if _, waits := target.recorded(); waits != 0 {
+		t.Fatal("Expected nothing to run without a tab")
+	}
+
+	tabOpen = true
+	s.tick(at(1))
+	s.tick(at(30))
+	s.tick(at(30).Add(15 * time.Second)) // Same minute must not fire twice
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if inputs, _ := target.recorded(); len(inputs) >= 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Each schedule runs in its own goroutine, so order is not guaranteed
+	inputs, _ := target.recorded()
+	sort.Strings(inputs)
+	if len(inputs) != 2 || inputs[0] != "echo hello\r" || inputs[1] != "echo tick\r" {
+		t.Errorf("Unexpected inputs: %q", inputs)
+	}
+
+	views, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	for _, v := range views {
+		switch v.ID {
+		case "startup", "cron":
+			if v.LastRun == nil || v.LastError != "" {
+				t.Errorf("Expected %s to have run cleanly, got %+v", v.ID, v.ScheduleStatus)
+			}
+		case "off":
+			if v.LastRun != nil {
+				t.Error("Disabled schedule should not run")
+			}
+		}
+		if v.ID == "cron" && v.NextRun == nil {
+			t.Error("Expected next run for cron schedule")
+		}
+	}
+}
+
+func TestScheduler_Guard(t *testing.T) {
+	withTempHome(t)
+
+	schedules := []Schedule{
+		{ID: "push", Name: "Force push", Command: "git push --force", Cron: "0 3 * * *"},
+		{ID: "wipe", Name: "Wipe", Command: "rm -rf /", Cron: "0 3 * * *"},
+	}
+	if err := SaveSchedules(schedules); err != nil {
+		t.Fatalf("SaveSchedules failed: %v", err)
+	}
+	target := &fakeTarget{}
+	s := NewScheduler(func(tabID string) (ChainTarget, string, error) { return target, "tab-1", nil })
+
+	// Confirm rules block too, since nobody is there to confirm
+	for _, id := range []string{"push", "wipe"} {
+		if _, err := s.RunNow(id); err == nil || !strings.Contains(err.Error(), "dangerous-command guard") {
+			t.Errorf("%s: expected the guard to block, got %v", id, err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if inputs, _ := target.recorded(); len(inputs) != 0 {
+		t.Errorf("Expected nothing typed, got %q", inputs)
+	}
+}
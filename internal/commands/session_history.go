@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// MaxSessionRestorePoints is how many previous tab layouts are kept
+const MaxSessionRestorePoints = 50
+
+// sessionSnapshotInterval throttles restore points while the frontend autosaves;
+// a save that drops tabs is always snapshotted first
+const sessionSnapshotInterval = time.Minute
+
+// ErrNoRestorePoint is returned when no restore point matches
+var ErrNoRestorePoint = errors.New("no matching restore point")
+
+// SessionRestorePoint describes a saved previous tab layout.
+type SessionRestorePoint struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	TabCount    int       `json:"tabCount"`
+	ActiveTabID string    `json:"activeTabId,omitempty"`
+	Titles      []string  `json:"titles"`
+}
+
+// snapshotBeforeSave keeps the on-disk layout as a restore point before it is
+// replaced by next, unless an equivalent or very recent one already exists.
+func snapshotBeforeSave(path string, next *Session, force bool) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var current Session
+	if json.Unmarshal(data, &current) != nil || len(current.Tabs) == 0 {
+		return nil // Nothing worth restoring
+	}
+	if nextData, err := json.MarshalIndent(next, "", "  "); err == nil && string(nextData) == string(data) {
+		return nil
+	}
+
+	points, err := ListSessionRestorePoints()
+	if err != nil {
+		return err
+	}
+	losingTabs := len(next.Tabs) < len(current.Tabs)
+	if !force && !losingTabs && len(points) > 0 && time.Since(points[0].CreatedAt) < sessionSnapshotInterval {
+		return nil
+	}
+	return writeRestorePoint(data)
+}
+
+// writeRestorePoint stores a layout and prunes the oldest restore points.
+func writeRestorePoint(data []byte) error {
+	dir := storage.GetSessionHistoryDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	name := "session-" + time.Now().Format(backupTimeFormat) + ".json"
+	if err := storage.WriteFileAtomic(filepath.Join(dir, name), data, 0600); err != nil {
+		return err
+	}
+
+	points, err := ListSessionRestorePoints()
+	if err != nil {
+		return err
+	}
+	for _, p := range points[min(len(points), MaxSessionRestorePoints):] {
+		os.Remove(filepath.Join(dir, p.ID))
+	}
+	return nil
+}
+
+// ListSessionRestorePoints returns saved layouts, newest first.
+func ListSessionRestorePoints() ([]SessionRestorePoint, error) {
+	dir := storage.GetSessionHistoryDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []SessionRestorePoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	points := []SessionRestorePoint{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "session-") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		created, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, "session-"), ".json"), time.Local)
+		if err != nil {
+			continue
+		}
+
+		point := SessionRestorePoint{ID: name, CreatedAt: created, Titles: []string{}}
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			var session Session
+			if json.Unmarshal(data, &session) == nil {
+				point.TabCount = len(session.Tabs)
+				point.ActiveTabID = session.ActiveTabID
+				for _, tab := range session.Tabs {
+					point.Titles = append(point.Titles, tab.Title)
+				}
+			}
+		}
+		points = append(points, point)
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].ID > points[j].ID
+	})
+	return points, nil
+}
+
+// FindSessionRestorePoint returns the newest restore point taken at or before t.
+func FindSessionRestorePoint(t time.Time) (*SessionRestorePoint, error) {
+	points, err := ListSessionRestorePoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range points {
+		if !p.CreatedAt.After(t) {
+			return &p, nil
+		}
+	}
+	return nil, ErrNoRestorePoint
+}
+
+// RestoreSessionRestorePoint makes a restore point the current layout. The
+// current layout is kept as a restore point first, so the restore can be undone.
+func RestoreSessionRestorePoint(id string) (*Session, error) {
+	if id != filepath.Base(id) || !strings.HasPrefix(id, "session-") {
+		return nil, fmt.Errorf("invalid restore point id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(storage.GetSessionHistoryDir(), id))
+	if os.IsNotExist(err) {
+		return nil, ErrNoRestorePoint
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("restore point %s is corrupt: %w", id, err)
+	}
+	if err := snapshotBeforeSave(storage.GetSessionsPath(), &session, true); err != nil {
+		return nil, err
+	}
+	if err := writeSession(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionRestorePoints(t *testing.T) {
+	withTempHome(t)
+
+	save := func(s Session) {
+		t.Helper()
+		if err := SaveSession(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	save(layout("a"))           // First save: nothing to snapshot
+	save(layout("a", "b"))      // Snapshots the one-tab layout
+	save(layout("a", "b", "c")) // Throttled: a restore point was just taken
+	save(layout())              // Dropping tabs always snapshots first
+
+	points, err := ListSessionRestorePoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 2 || points[0].TabCount != 3 || points[1].TabCount != 1 {
+		t.Fatalf("unexpected restore points %+v", points)
+	}
+	if points[0].Titles[2] != "c" {
+		t.Errorf("expected tab titles in restore point, got %v", points[0].Titles)
+	}
+
+	point, err := FindSessionRestorePoint(time.Now())
+	if err != nil || point.ID != points[0].ID {
+		t.Fatalf("expected newest restore point, got %+v (%v)", point, err)
+	}
+	if _, err := FindSessionRestorePoint(time.Now().Add(-time.Hour)); err != ErrNoRestorePoint {
+		t.Errorf("expected ErrNoRestorePoint for a time before any snapshot, got %v", err)
+	}
+
+	restored, err := RestoreSessionRestorePoint(point.ID)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if current, _ := LoadSession(); len(restored.Tabs) != 3 || len(current.Tabs) != 3 {
+		t.Errorf("expected the three-tab layout to be restored, got %+v", current)
+	}
+
+	if _, err := RestoreSessionRestorePoint("../sessions.json"); err == nil {
+		t.Error("expected invalid id to be rejected")
+	}
+	if _, err := RestoreSessionRestorePoint("session-missing.json"); err != ErrNoRestorePoint {
+		t.Errorf("expected ErrNoRestorePoint, got %v", err)
+	}
+}
+
+func TestSessionRestorePointsPruned(t *testing.T) {
+	dir := withTempHome(t)
+
+	for i := 0; i < MaxSessionRestorePoints+5; i++ {
+		if err := writeRestorePoint([]byte(`{"tabs":[]}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "session-history"))
+	if len(entries) != MaxSessionRestorePoints {
+		t.Errorf("expected %d restore points, got %d", MaxSessionRestorePoints, len(entries))
+	}
+}
@@ -2,8 +2,11 @@ package commands
 
 import (
 	"encoding/json"
+	"log"
 	"os"
 	"path/filepath"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 // TabState represents the persisted state of a terminal tab
@@ -20,9 +23,13 @@ type TabState struct {
 
 // ShellConfig represents the shell configuration for a tab
 type ShellConfig struct {
-	ShellType   string `json:"shellType"`
-	WSLDistro   string `json:"wslDistro,omitempty"`
-	WSLHomePath string `json:"wslHomePath,omitempty"`
+	ShellType      string `json:"shellType"`
+	WSLDistro      string `json:"wslDistro,omitempty"`
+	WSLHomePath    string `json:"wslHomePath,omitempty"`
+	Container      string `json:"container,omitempty"`      // docker tabs
+	ContainerShell string `json:"containerShell,omitempty"` // docker tabs
+	TmuxSession    string `json:"tmuxSession,omitempty"`    // tmux tabs
+	SSHHost        string `json:"sshHost,omitempty"`        // ssh tabs
 }
 
 // Session represents the persisted session state
@@ -39,11 +46,7 @@ var DefaultSession = Session{
 
 // GetSessionsPath returns the path to the sessions JSON file
 func GetSessionsPath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "sessions.json"), nil
+	return storage.GetSessionsPath(), nil
 }
 
 // LoadSession loads session from the JSON file
@@ -58,7 +61,7 @@ func LoadSession() (*Session, error) {
 		return &DefaultSession, nil
 	}
 
-	data, err := os.ReadFile(path)
+	data, err := storage.ReadJSONFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -72,15 +75,24 @@ func LoadSession() (*Session, error) {
 	return &session, nil
 }
 
-// SaveSession saves session to the JSON file
+// SaveSession saves session to the JSON file, keeping the previous layout as a
+// restore point (see ListSessionRestorePoints)
 func SaveSession(session *Session) error {
-	configDir, err := GetConfigDir()
+	if err := snapshotBeforeSave(storage.GetSessionsPath(), session, false); err != nil {
+		log.Printf("[Sessions] Failed to save restore point: %v", err)
+	}
+	return writeSession(session)
+}
+
+// writeSession writes the active layout without taking a restore point
+func writeSession(session *Session) error {
+	path, err := GetSessionsPath()
 	if err != nil {
 		return err
 	}
 
 	// Ensure directory exists
-	if err := os.MkdirAll(configDir, 0700); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return err
 	}
 
@@ -89,10 +101,5 @@ func SaveSession(session *Session) error {
 		return err
 	}
 
-	path, err := GetSessionsPath()
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0600)
+	return storage.WriteFileAtomic(path, data, 0600)
 }
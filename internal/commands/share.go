@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Export bundle identification
+const (
+	ExportFormat        = "forge-commands"
+	ExportFormatVersion = 1
+)
+
+// Import conflict strategies for commands whose ID already exists
+const (
+	ConflictDuplicate = "duplicate" // Import under a new ID (default)
+	ConflictOverwrite = "overwrite" // Replace the existing command
+	ConflictSkip      = "skip"      // Keep the existing command
+)
+
+// ExportBundle is the versioned, shareable representation of command cards.
+type ExportBundle struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	AppVersion string    `json:"appVersion,omitempty"`
+	Commands   []Command `json:"commands"`
+}
+
+// ImportSummary describes what an import changed.
+type ImportSummary struct {
+	Added       int      `json:"added"`
+	Overwritten int      `json:"overwritten"`
+	Skipped     int      `json:"skipped"`
+	Renumbered  int      `json:"renumbered"`
+	ClearedKeys []string `json:"clearedKeys"` // Key bindings dropped because they were already in use
+}
+
+// ExportCommands bundles the given commands. If ids is non-empty only those are included.
+func ExportCommands(cmds []Command, ids []int, appVersion string) ExportBundle {
+	selected := cmds
+	if len(ids) > 0 {
+		wanted := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		selected = []Command{}
+		for _, cmd := range cmds {
+			if wanted[cmd.ID] {
+				selected = append(selected, cmd)
+			}
+		}
+	}
+
+	return ExportBundle{
+		Format:     ExportFormat,
+		Version:    ExportFormatVersion,
+		ExportedAt: time.Now(),
+		AppVersion: appVersion,
+		Commands:   selected,
+	}
+}
+
+// ParseExportBundle decodes a bundle, also accepting a bare commands.json array
+// from older installs.
+func ParseExportBundle(data []byte) (*ExportBundle, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var cmds []Command
+		if err := json.Unmarshal(trimmed, &cmds); err != nil {
+			return nil, fmt.Errorf("invalid commands array: %w", err)
+		}
+		return &ExportBundle{Format: ExportFormat, Commands: cmds}, nil
+	}
+
+	var bundle ExportBundle
+	if err := json.Unmarshal(trimmed, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid export bundle: %w", err)
+	}
+	if bundle.Format != ExportFormat {
+		return nil, fmt.Errorf("unrecognized format %q", bundle.Format)
+	}
+	if bundle.Version > ExportFormatVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than supported version %d - update Forge to import it",
+			bundle.Version, ExportFormatVersion)
+	}
+	return &bundle, nil
+}
+
+// ImportCommands merges bundle commands into existing using strategy for ID conflicts.
+// Imported key bindings that collide with existing ones are cleared.
+func ImportCommands(existing []Command, bundle *ExportBundle, strategy string) ([]Command, ImportSummary, error) {
+	if strategy == "" {
+		strategy = ConflictDuplicate
+	}
+	if strategy != ConflictDuplicate && strategy != ConflictOverwrite && strategy != ConflictSkip {
+		return nil, ImportSummary{}, fmt.Errorf("unknown conflict strategy %q", strategy)
+	}
+
+	result := make([]Command, len(existing))
+	copy(result, existing)
+	summary := ImportSummary{ClearedKeys: []string{}}
+
+	indexByID := make(map[int]int, len(result))
+	maxID := 0
+	for i, cmd := range result {
+		indexByID[cmd.ID] = i
+		if cmd.ID > maxID {
+			maxID = cmd.ID
+		}
+	}
+
+	for _, cmd := range bundle.Commands {
+		cmd.Tags = NormalizeTags(cmd.Tags)
+		idx, conflict := indexByID[cmd.ID]
+
+		if conflict && strategy == ConflictSkip {
+			summary.Skipped++
+			continue
+		}
+
+		if conflict && strategy == ConflictOverwrite {
+			cmd.Order = result[idx].Order
+			if keyBindingInUse(result, cmd.KeyBinding, idx) {
+				summary.ClearedKeys = append(summary.ClearedKeys, cmd.KeyBinding)
+				cmd.KeyBinding = ""
+			}
+			result[idx] = cmd
+			summary.Overwritten++
+			continue
+		}
+
+		if conflict || cmd.ID <= 0 {
+			maxID++
+			cmd.ID = maxID
+			summary.Renumbered++
+		} else if cmd.ID > maxID {
+			maxID = cmd.ID
+		}
+
+		if keyBindingInUse(result, cmd.KeyBinding, -1) {
+			summary.ClearedKeys = append(summary.ClearedKeys, cmd.KeyBinding)
+			cmd.KeyBinding = ""
+		}
+
+		cmd.Order = 0 // Appended after existing cards
+		indexByID[cmd.ID] = len(result)
+		result = append(result, cmd)
+		summary.Added++
+	}
+
+	NormalizeOrder(result)
+	return result, summary, nil
+}
+
+// keyBindingInUse reports whether binding is used by any command other than skip.
+// Bindings are compared in normalized form, so "shift+ctrl+1" matches "Ctrl+Shift+1".
+func keyBindingInUse(cmds []Command, binding string, skip int) bool {
+	if binding == "" {
+		return false
+	}
+	if normalized, err := NormalizeKeyBinding(binding); err == nil {
+		binding = normalized
+	}
+	for i, cmd := range cmds {
+		if i == skip || cmd.KeyBinding == "" {
+			continue
+		}
+		other := cmd.KeyBinding
+		if normalized, err := NormalizeKeyBinding(other); err == nil {
+			other = normalized
+		}
+		if other == binding {
+			return true
+		}
+	}
+	return false
+}
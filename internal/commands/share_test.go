@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExportAndParseBundle(t *testing.T) {
+	cmds := []Command{{ID: 1, Command: "ls"}, {ID: 2, Command: "pwd"}, {ID: 3, Command: "whoami"}}
+
+	bundle := ExportCommands(cmds, []int{1, 3}, "1.0.0")
+	if bundle.Format != ExportFormat || bundle.Version != ExportFormatVersion || len(bundle.Commands) != 2 {
+		t.Fatalf("Unexpected bundle: %+v", bundle)
+	}
+
+	data, _ := json.Marshal(bundle)
+	parsed, err := ParseExportBundle(data)
+	if err != nil {
+		t.Fatalf("ParseExportBundle failed: %v", err)
+	}
+	if len(parsed.Commands) != 2 || parsed.Commands[1].Command != "whoami" {
+		t.Errorf("Unexpected parsed commands: %+v", parsed.Commands)
+	}
+}
+
+func TestParseExportBundle_LegacyAndInvalid(t *testing.T) {
+	legacy, err := ParseExportBundle([]byte(`[{"id": 7, "command": "make"}]`))
+	if err != nil || len(legacy.Commands) != 1 {
+		t.Errorf("Expected legacy array to parse, got %+v (%v)", legacy, err)
+	}
+
+	if _, err := ParseExportBundle([]byte(`{"format": "other", "version": 1}`)); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if _, err := ParseExportBundle([]byte(`{"format": "forge-commands", "version": 99}`)); err == nil {
+		t.Error("Expected error for newer version")
+	}
+}
+
+func TestImportCommands_Strategies(t *testing.T) {
+	existing := []Command{
+		{ID: 1, Description: "one", KeyBinding: "Ctrl+Shift+1", Order: 1},
+		{ID: 2, Description: "two", Order: 2},
+	}
+	bundle := &ExportBundle{Commands: []Command{
+		{ID: 1, Description: "imported one", KeyBinding: "Ctrl+Shift+1"},
+		{ID: 5, Description: "five", KeyBinding: "Ctrl+Shift+5"},
+	}}
+
+	result, summary, err := ImportCommands(existing, bundle, "")
+	if err != nil {
+		t.Fatalf("ImportCommands failed: %v", err)
+	}
+	if len(result) != 4 || summary.Added != 2 || summary.Renumbered != 1 {
+		t.Errorf("Duplicate: unexpected result %+v summary %+v", result, summary)
+	}
+	if result[2].ID != 3 || result[2].KeyBinding != "" {
+		t.Errorf("Expected conflicting command renumbered to 3 with key cleared, got %+v", result[2])
+	}
+	if len(summary.ClearedKeys) != 1 {
+		t.Errorf("Expected one cleared key binding, got %v", summary.ClearedKeys)
+	}
+
+	result, summary, _ = ImportCommands(existing, bundle, ConflictOverwrite)
+	if len(result) != 3 || summary.Overwritten != 1 || result[0].Description != "imported one" {
+		t.Errorf("Overwrite: unexpected result %+v summary %+v", result, summary)
+	}
+	if result[0].KeyBinding != "Ctrl+Shift+1" {
+		t.Error("Expected overwritten command to keep its own key binding")
+	}
+
+	result, summary, _ = ImportCommands(existing, bundle, ConflictSkip)
+	if len(result) != 3 || summary.Skipped != 1 || result[0].Description != "one" {
+		t.Errorf("Skip: unexpected result %+v summary %+v", result, summary)
+	}
+
+	if _, _, err := ImportCommands(existing, bundle, "merge"); err == nil {
+		t.Error("Expected error for unknown strategy")
+	}
+}
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mikejsmith1985/forge-terminal/internal/secrets"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// snippetFieldPattern matches {{name}} in a snippet body
+var snippetFieldPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// SnippetField is a {{name}} placeholder in a snippet body.
+type SnippetField struct {
+	Name   string `json:"name"`
+	Secret bool   `json:"secret,omitempty"` // Value lives in the OS keychain
+	// Value is the field's value. Plain values are saved with the snippet;
+	// a secret value is moved to the keychain on save and never returned.
+	Value  string `json:"value,omitempty"`
+	Stored bool   `json:"stored,omitempty"` // A secret value is in the keychain
+}
+
+// Snippet is frequently pasted text, e.g. a connection string or
+// boilerplate, with fields filled in when it is injected into a tab.
+type Snippet struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Body        string         `json:"body"`
+	Fields      []SnippetField `json:"fields,omitempty"`
+}
+
+// SnippetExpansion is a snippet body with its fields filled in.
+type SnippetExpansion struct {
+	Text    string   `json:"-"`       // Never returned: may hold secrets
+	Missing []string `json:"missing"` // Fields with no value, left as {{name}}
+	Secrets []string `json:"-"`       // Secret values used, for redaction
+}
+
+// Keychain access; overridden in tests
+var (
+	getSecret    = secrets.Get
+	setSecret    = secrets.Set
+	deleteSecret = secrets.Delete
+)
+
+var snippetsMutex sync.Mutex
+
+// LoadSnippets returns saved snippets, with secret values left out.
+func LoadSnippets() ([]Snippet, error) {
+	snippetsMutex.Lock()
+	defer snippetsMutex.Unlock()
+	return loadSnippetsLocked()
+}
+
+func loadSnippetsLocked() ([]Snippet, error) {
+	data, err := storage.ReadJSONFile(storage.GetSnippetsPath())
+	if os.IsNotExist(err) {
+		return []Snippet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets file: %w", err)
+	}
+
+	var snippets []Snippet
+	if err := json.Unmarshal(data, &snippets); err != nil {
+		return nil, fmt.Errorf("failed to parse snippets JSON: %w", err)
+	}
+	return snippets, nil
+}
+
+func saveSnippetsLocked(snippets []Snippet) error {
+	path := storage.GetSnippetsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snippets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// GetSnippet returns the saved snippet with id.
+func GetSnippet(id string) (Snippet, bool, error) {
+	snippets, err := LoadSnippets()
+	if err != nil {
+		return Snippet{}, false, err
+	}
+	for _, snippet := range snippets {
+		if snippet.ID == id {
+			return snippet, true, nil
+		}
+	}
+	return Snippet{}, false, nil
+}
+
+// SaveSnippet adds a snippet, or replaces the one with the same ID, and
+// returns it as saved. Secret values given are stored in the keychain;
+// secret fields without one keep their stored value. Keychain entries of
+// removed secret fields are deleted.
+func SaveSnippet(snippet Snippet) (Snippet, error) {
+	if snippet.ID == "" {
+		snippet.ID = uuid.New().String()
+	}
+	if err := validateSnippet(snippet); err != nil {
+		return Snippet{}, err
+	}
+
+	snippetsMutex.Lock()
+	defer snippetsMutex.Unlock()
+
+	snippets, err := loadSnippetsLocked()
+	if err != nil {
+		return Snippet{}, err
+	}
+	index := -1
+	previous := map[string]SnippetField{}
+	for i, existing := range snippets {
+		if existing.ID == snippet.ID {
+			index = i
+			for _, field := range existing.Fields {
+				previous[field.Name] = field
+			}
+			break
+		}
+	}
+
+	for i := range snippet.Fields {
+		field := &snippet.Fields[i]
+		if !field.Secret {
+			field.Stored = false
+			continue
+		}
+		if field.Value != "" {
+			if err := setSecret(snippetSecretName(snippet.ID, field.Name), field.Value); err != nil {
+				return Snippet{}, err
+			}
+			field.Value, field.Stored = "", true
+		} else {
+			field.Stored = previous[field.Name].Secret && previous[field.Name].Stored
+		}
+	}
+
+	if index >= 0 {
+		snippets[index] = snippet
+	} else {
+		snippets = append(snippets, snippet)
+	}
+	if err := saveSnippetsLocked(snippets); err != nil {
+		return Snippet{}, err
+	}
+
+	kept := map[string]bool{}
+	for _, field := range snippet.Fields {
+		kept[field.Name] = field.Secret && field.Stored
+	}
+	for name, field := range previous {
+		if field.Secret && field.Stored && !kept[name] {
+			deleteSnippetSecret(snippet.ID, name)
+		}
+	}
+	return snippet, nil
+}
+
+// DeleteSnippet removes a snippet and its keychain entries, returning false if
+// no snippet has id.
+func DeleteSnippet(id string) (bool, error) {
+	snippetsMutex.Lock()
+	defer snippetsMutex.Unlock()
+
+	snippets, err := loadSnippetsLocked()
+	if err != nil {
+		return false, err
+	}
+	for i, snippet := range snippets {
+		if snippet.ID != id {
+			continue
+		}
+		if err := saveSnippetsLocked(append(snippets[:i:i], snippets[i+1:]...)); err != nil {
+			return false, err
+		}
+		for _, field := range snippet.Fields {
+			if field.Secret && field.Stored {
+				deleteSnippetSecret(id, field.Name)
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// ExpandSnippet fills in a snippet's fields, with values overriding the
+// saved ones. Secret values are read from the keychain only here, when the
+// snippet is about to be injected.
+func ExpandSnippet(snippet Snippet, values map[string]string) (SnippetExpansion, error) {
+	resolved := map[string]string{}
+	var expansion SnippetExpansion
+	for _, field := range snippet.Fields {
+		value, ok := values[field.Name]
+		if !ok || value == "" {
+			if !field.Secret {
+				value, ok = field.Value, field.Value != ""
+			} else if field.Stored {
+				stored, err := getSecret(snippetSecretName(snippet.ID, field.Name))
+				if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+					return SnippetExpansion{}, err
+				}
+				value, ok = stored, err == nil
+			}
+		}
+		if !ok {
+			continue
+		}
+		resolved[field.Name] = value
+		if field.Secret {
+			expansion.Secrets = append(expansion.Secrets, value)
+		}
+	}
+
+	expansion.Missing = []string{}
+	seen := map[string]bool{}
+	expansion.Text = snippetFieldPattern.ReplaceAllStringFunc(snippet.Body, func(raw string) string {
+		name := snippetFieldPattern.FindStringSubmatch(raw)[1]
+		if value, ok := resolved[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			expansion.Missing = append(expansion.Missing, name)
+		}
+		return raw
+	})
+	return expansion, nil
+}
+
+func validateSnippet(snippet Snippet) error {
+	if strings.TrimSpace(snippet.Name) == "" {
+		return fmt.Errorf("snippet %s: name is required", snippet.ID)
+	}
+	if snippet.Body == "" {
+		return fmt.Errorf("snippet %q: body is required", snippet.Name)
+	}
+	names := map[string]bool{}
+	for _, field := range snippet.Fields {
+		if !snippetFieldPattern.MatchString("{{" + field.Name + "}}") {
+			return fmt.Errorf("snippet %q: invalid field name %q (use letters, digits, _ . -)", snippet.Name, field.Name)
+		}
+		if names[field.Name] {
+			return fmt.Errorf("snippet %q: duplicate field %q", snippet.Name, field.Name)
+		}
+		names[field.Name] = true
+	}
+	return nil
+}
+
+// snippetSecretName is the keychain name of a snippet's secret field
+func snippetSecretName(id, field string) string {
+	return "snippet/" + id + "/" + field
+}
+
+func deleteSnippetSecret(id, field string) {
+	if err := deleteSecret(snippetSecretName(id, field)); err != nil {
+		log.Printf("[Snippets] Failed to delete secret for field %q of snippet %s: %v", field, id, err)
+	}
+}
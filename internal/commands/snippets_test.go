@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/secrets"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// fakeKeychain replaces the OS keychain for a test
+func fakeKeychain(t *testing.T) map[string]string {
+	t.Helper()
+	stored := map[string]string{}
+	origGet, origSet, origDelete := getSecret, setSecret, deleteSecret
+	getSecret = func(name string) (string, error) {
+		value, ok := stored[name]
+		if !ok {
+			return "", secrets.ErrNotFound
+		}
+		return value, nil
+	}
+	setSecret = func(name, value string) error {
+		stored[name] = value
+		return nil
+	}
+	deleteSecret = func(name string) error {
+		delete(stored, name)
+		return nil
+	}
+	t.Cleanup(func() { getSecret, setSecret, deleteSecret = origGet, origSet, origDelete })
+	return stored
+}
+
+func useSnippetsFile(t *testing.T) string {
+	t.Helper()
+	withTempHome(t)
+	return storage.GetSnippetsPath()
+}
+
+func TestSaveSnippetKeepsSecretsInKeychain(t *testing.T) {
+	path := useSnippetsFile(t)
+	keychain := fakeKeychain(t)
+
+	saved, err := SaveSnippet(Snippet{
+		Name: "Prod DB",
+		Body: "psql postgres://{{user}}:{{password}}@db/{{name}}",
+		Fields: []SnippetField{
+			{Name: "user", Value: "admin"},
+			{Name: "password", Secret: true, Value: "hunter22"},
+			{Name: "name"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.ID == "" || saved.Fields[1].Value != "" || !saved.Fields[1].Stored {
+		t.Errorf("Expected an ID and the secret moved to the keychain, got %+v", saved)
+	}
+	if keychain[snippetSecretName(saved.ID, "password")] != "hunter22" {
+		t.Errorf("Expected the password in the keychain, got %v", keychain)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "hunter22") || !strings.Contains(string(data), "admin") {
+		t.Errorf("Expected only plain values on disk, got %s", data)
+	}
+
+	// Saving without a value keeps the stored secret
+	saved.Description = "Read-only replica"
+	if saved, err = SaveSnippet(saved); err != nil || !saved.Fields[1].Stored {
+		t.Fatalf("Expected the secret kept, got %+v (%v)", saved, err)
+	}
+
+	expansion, err := ExpandSnippet(saved, map[string]string{"name": "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expansion.Text != "psql postgres://admin:hunter22@db/orders" || len(expansion.Missing) != 0 {
+		t.Errorf("Unexpected expansion %q (missing %v)", expansion.Text, expansion.Missing)
+	}
+	if !reflect.DeepEqual(expansion.Secrets, []string{"hunter22"}) {
+		t.Errorf("Expected the secret reported for redaction, got %q", expansion.Secrets)
+	}
+
+	// Dropping the secret field deletes its keychain entry
+	saved.Fields = saved.Fields[:1]
+	if _, err := SaveSnippet(saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(keychain) != 0 {
+		t.Errorf("Expected the keychain entry removed, got %v", keychain)
+	}
+}
+
+func TestExpandSnippetMissing(t *testing.T) {
+	fakeKeychain(t)
+	snippet := Snippet{
+		ID:     "s1",
+		Body:   "{{greeting}}, {{ who }}! {{unknown}} {{who}}",
+		Fields: []SnippetField{{Name: "greeting", Value: "Hello"}, {Name: "who"}, {Name: "token", Secret: true, Stored: true}},
+	}
+	expansion, err := ExpandSnippet(snippet, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"who", "unknown"}; !reflect.DeepEqual(expansion.Missing, want) {
+		t.Errorf("Expected missing %v, got %v", want, expansion.Missing)
+	}
+	if expansion.Text != "Hello, {{ who }}! {{unknown}} {{who}}" {
+		t.Errorf("Expected unresolved fields left intact, got %q", expansion.Text)
+	}
+}
+
+func TestDeleteSnippet(t *testing.T) {
+	useSnippetsFile(t)
+	keychain := fakeKeychain(t)
+
+	saved, err := SaveSnippet(Snippet{Name: "Token", Body: "{{token}}", Fields: []SnippetField{{Name: "token", Secret: true, Value: "abc123"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := DeleteSnippet(saved.ID); !ok || err != nil {
+		t.Fatalf("Expected the snippet deleted, got %v (%v)", ok, err)
+	}
+	if len(keychain) != 0 {
+		t.Errorf("Expected the keychain entry removed, got %v", keychain)
+	}
+	if ok, _ := DeleteSnippet(saved.ID); ok {
+		t.Error("Expected no second delete")
+	}
+	if snippets, _ := LoadSnippets(); len(snippets) != 0 {
+		t.Errorf("Expected no snippets, got %+v", snippets)
+	}
+}
+
+func TestValidateSnippet(t *testing.T) {
+	tests := []struct {
+		snippet Snippet
+		ok      bool
+	}{
+		{Snippet{Name: "ok", Body: "text"}, true},
+		{Snippet{Body: "text"}, false},
+		{Snippet{Name: "ok"}, false},
+		{Snippet{Name: "ok", Body: "{{a b}}", Fields: []SnippetField{{Name: "a b"}}}, false},
+		{Snippet{Name: "ok", Body: "{{a}}", Fields: []SnippetField{{Name: "a"}, {Name: "a"}}}, false},
+	}
+	for _, tt := range tests {
+		if err := validateSnippet(tt.snippet); (err == nil) != tt.ok {
+			t.Errorf("validateSnippet(%+v) = %v, want ok=%v", tt.snippet, err, tt.ok)
+		}
+	}
+}
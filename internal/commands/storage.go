@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
 	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
@@ -16,13 +18,29 @@ type Command struct {
 	KeyBinding  string `json:"keyBinding"`
 	PasteOnly   bool   `json:"pasteOnly"`
 	Favorite    bool   `json:"favorite"`
-	TriggerAM   bool   `json:"triggerAM,omitempty"`
-	LLMProvider string `json:"llmProvider,omitempty"` // "copilot", "claude", "aider"
-	LLMType     string `json:"llmType,omitempty"`     // "chat", "suggest", "explain", "code"
 	Icon        string `json:"icon,omitempty"`
+
+	// LLM metadata (see llm_metadata.go). With triggerAM set, the provider is
+	// inferred from the command text and llmType defaults to "chat".
+	TriggerAM   bool            `json:"triggerAM,omitempty"`
+	LLMProvider LLMProvider     `json:"llmProvider,omitempty"` // "copilot", "claude", "aider"
+	LLMType     llm.CommandType `json:"llmType,omitempty"`     // "chat", "suggest", "explain", "code"
+
+	// Organization
+	Group string   `json:"group,omitempty"` // Folder the card is shown in ("" = ungrouped)
+	Order int      `json:"order,omitempty"` // Position in the card list, 1-based
+	Tags  []string `json:"tags,omitempty"`
+
+	// Execution target (optional): run in this shell/directory regardless of the focused tab
+	ShellType        string `json:"shellType,omitempty"` // "cmd", "powershell", "wsl", "docker" (in Config.DockerContainer), "tmux" (attached to Config.TmuxSession), or "ssh" (to Config.SSHHost)
+	WorkingDirectory string `json:"workingDirectory,omitempty"`
+
+	// Usage (read-only; tracked in usage.json and filled in by ApplyUsage)
+	RunCount int        `json:"runCount,omitempty"`
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
 }
 
-// Default commands created on first run
+// Default commands created on first run (the "claude" pack; see CommandPacks)
 var DefaultCommands = []Command{
 	{
 		ID:          1,
@@ -67,9 +85,6 @@ var DefaultCommands = []Command{
 	},
 }
 
-// UserHomeDir is a variable to allow mocking in tests
-var UserHomeDir = os.UserHomeDir
-
 // GetConfigDir returns the Forge configuration directory
 func GetConfigDir() (string, error) {
 	return storage.GetTerminalDir(), nil
@@ -89,13 +104,14 @@ func LoadCommands() ([]Command, error) {
 
 	// Create default if doesn't exist
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		if err := SaveCommands(DefaultCommands); err != nil {
+		defaults := SelectedPackCommands()
+		if err := SaveCommands(defaults); err != nil {
 			return nil, fmt.Errorf("failed to create default commands: %w", err)
 		}
-		return DefaultCommands, nil
+		return defaults, nil
 	}
 
-	data, err := os.ReadFile(path)
+	data, err := storage.ReadJSONFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read commands file: %w", err)
 	}
@@ -108,27 +124,9 @@ func LoadCommands() ([]Command, error) {
 	return commands, nil
 }
 
-// SaveCommands saves commands to the JSON file
+// SaveCommands saves commands to the JSON file, backing up the previous version.
+// Use SaveCommandsIfVersion when the caller may be working from a stale copy.
 func SaveCommands(commands []Command) error {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return err
-	}
-
-	// Ensure directory exists
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(commands, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	path, err := GetCommandsPath()
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0600)
+	_, err := SaveCommandsIfVersion(commands, "")
+	return err
 }
@@ -4,8 +4,24 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
+// withTempHome points HOME, and with it every storage path, at a temp dir.
+// It creates and returns the terminal data directory.
+func withTempHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	dir := storage.GetTerminalDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
 func TestStorage(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "forge-test")
@@ -14,12 +30,8 @@ func TestStorage(t *testing.T) {
 	}
 	defer os.RemoveAll(tempDir)
 
-	// Mock UserHomeDir
-	originalHomeDir := UserHomeDir
-	defer func() { UserHomeDir = originalHomeDir }()
-	UserHomeDir = func() (string, error) {
-		return tempDir, nil
-	}
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
 
 	// Test 1: LoadCommands should create default commands on first run
 	commands, err := LoadCommands()
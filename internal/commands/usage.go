@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// CommandUsage records how often and how recently a card was run.
+type CommandUsage struct {
+	RunCount int       `json:"runCount"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// Sort orders accepted by SortCommands
+const (
+	SortManual   = "manual"   // Card order set by the user
+	SortMostUsed = "mostUsed" // Highest run count first
+	SortRecent   = "recent"   // Most recently used first
+)
+
+var usageMutex sync.Mutex
+
+// loadUsageLocked reads usage.json. Caller must hold usageMutex.
+// Usage is kept apart from commands.json so the UI saving its card list
+// can't overwrite counts recorded in the meantime.
+func loadUsageLocked() (map[int]CommandUsage, error) {
+	usage := make(map[int]CommandUsage)
+
+	data, err := storage.ReadJSONFile(storage.GetCommandUsagePath())
+	if os.IsNotExist(err) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	var raw map[string]CommandUsage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse usage JSON: %w", err)
+	}
+	for key, u := range raw {
+		if id, err := strconv.Atoi(key); err == nil {
+			usage[id] = u
+		}
+	}
+	return usage, nil
+}
+
+// LoadUsage returns usage for every command that has been run.
+func LoadUsage() (map[int]CommandUsage, error) {
+	usageMutex.Lock()
+	defer usageMutex.Unlock()
+	return loadUsageLocked()
+}
+
+// RecordUsage increments a command's run count and updates its last-used time.
+func RecordUsage(commandID int) error {
+	if commandID <= 0 {
+		return nil
+	}
+
+	usageMutex.Lock()
+	defer usageMutex.Unlock()
+
+	usage, err := loadUsageLocked()
+	if err != nil {
+		return err
+	}
+	u := usage[commandID]
+	u.RunCount++
+	u.LastUsed = time.Now()
+	usage[commandID] = u
+
+	path := storage.GetCommandUsagePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// ApplyUsage fills in RunCount and LastUsed on each command.
+func ApplyUsage(commands []Command, usage map[int]CommandUsage) {
+	for i := range commands {
+		u, ok := usage[commands[i].ID]
+		if !ok {
+			commands[i].RunCount = 0
+			commands[i].LastUsed = nil
+			continue
+		}
+		lastUsed := u.LastUsed
+		commands[i].RunCount = u.RunCount
+		commands[i].LastUsed = &lastUsed
+	}
+}
+
+// SortCommands orders commands for display. Usage sorts fall back to card order
+// so unused cards keep their manual arrangement below the heavily used ones.
+func SortCommands(commands []Command, order string) error {
+	lastUsed := func(c Command) time.Time {
+		if c.LastUsed == nil {
+			return time.Time{}
+		}
+		return *c.LastUsed
+	}
+
+	switch order {
+	case "", SortManual:
+		NormalizeOrder(commands)
+	case SortMostUsed:
+		NormalizeOrder(commands)
+		sort.SliceStable(commands, func(i, j int) bool {
+			if commands[i].RunCount != commands[j].RunCount {
+				return commands[i].RunCount > commands[j].RunCount
+			}
+			return lastUsed(commands[i]).After(lastUsed(commands[j]))
+		})
+	case SortRecent:
+		NormalizeOrder(commands)
+		sort.SliceStable(commands, func(i, j int) bool {
+			return lastUsed(commands[i]).After(lastUsed(commands[j]))
+		})
+	default:
+		return fmt.Errorf("unknown sort %q", order)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordUsage(t *testing.T) {
+	withTempHome(t)
+
+	for i := 0; i < 3; i++ {
+		if err := RecordUsage(2); err != nil {
+			t.Fatalf("RecordUsage failed: %v", err)
+		}
+	}
+	RecordUsage(5)
+	RecordUsage(0) // Ignored
+
+	usage, err := LoadUsage()
+	if err != nil {
+		t.Fatalf("LoadUsage failed: %v", err)
+	}
+	if len(usage) != 2 || usage[2].RunCount != 3 || usage[5].RunCount != 1 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+	if usage[2].LastUsed.IsZero() {
+		t.Error("Expected LastUsed to be set")
+	}
+
+	cmds := []Command{{ID: 2}, {ID: 3, RunCount: 9}}
+	ApplyUsage(cmds, usage)
+	if cmds[0].RunCount != 3 || cmds[0].LastUsed == nil {
+		t.Errorf("Expected usage applied, got %+v", cmds[0])
+	}
+	if cmds[1].RunCount != 0 || cmds[1].LastUsed != nil {
+		t.Errorf("Expected stale usage cleared, got %+v", cmds[1])
+	}
+}
+
+func TestSortCommands(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	newCmds := func() []Command {
+		return []Command{
+			{ID: 1, Order: 1},
+			{ID: 2, Order: 2, RunCount: 5, LastUsed: &earlier},
+			{ID: 3, Order: 3, RunCount: 5, LastUsed: &now},
+			{ID: 4, Order: 4, RunCount: 1, LastUsed: &now},
+		}
+	}
+	ids := func(cmds []Command) []int {
+		result := []int{}
+		for _, c := range cmds {
+			result = append(result, c.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		sort string
+		want []int
+	}{
+		{SortManual, []int{1, 2, 3, 4}},
+		{SortMostUsed, []int{3, 2, 4, 1}},
+		{SortRecent, []int{3, 4, 2, 1}},
+	}
+	for _, tt := range tests {
+		cmds := newCmds()
+		if err := SortCommands(cmds, tt.sort); err != nil {
+			t.Fatalf("SortCommands(%s) failed: %v", tt.sort, err)
+		}
+		got := ids(cmds)
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("SortCommands(%s) = %v, want %v", tt.sort, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if err := SortCommands(newCmds(), "alphabetical"); err == nil {
+		t.Error("Expected error for unknown sort")
+	}
+}
@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// MaxCommandBackups is how many previous versions of commands.json are kept
+const MaxCommandBackups = 20
+
+// Lock file tuning: how long to wait for another writer, and when a lock is considered abandoned
+const (
+	lockWait  = 5 * time.Second
+	lockStale = 30 * time.Second
+)
+
+// backupTimeFormat names backup files so they sort chronologically
+const backupTimeFormat = "20060102-150405.000000000"
+
+// ErrVersionConflict is returned when commands.json changed since the caller loaded it.
+var ErrVersionConflict = errors.New("commands were modified by another window; reload and try again")
+
+// commandsFileMutex serializes writers within this process; the lock file covers other processes
+var commandsFileMutex sync.Mutex
+
+// CommandBackup describes a saved previous version of commands.json.
+type CommandBackup struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Count     int       `json:"count"` // Number of commands in the backup
+	Size      int64     `json:"size"`
+}
+
+// contentVersion derives a version tag from file contents ("" for a missing file).
+func contentVersion(data []byte) string {
+	if data == nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadCommandsWithVersion loads commands along with the version tag to pass
+// back to SaveCommandsIfVersion.
+func LoadCommandsWithVersion() ([]Command, string, error) {
+	cmds, err := LoadCommands()
+	if err != nil {
+		return nil, "", err
+	}
+	version, err := CommandsVersion()
+	if err != nil {
+		return nil, "", err
+	}
+	return cmds, version, nil
+}
+
+// CommandsVersion returns the version tag of the saved commands.
+func CommandsVersion() (string, error) {
+	data, err := storage.ReadJSONFile(storage.GetCommandsPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read commands file: %w", err)
+	}
+	return contentVersion(data), nil
+}
+
+// SaveCommandsIfVersion saves commands only if commands.json still has the given
+// version ("" skips the check). The previous file is backed up first, and the new
+// file is written atomically. RunCount and LastUsed are not saved; usage lives in
+// usage.json. Returns the new version.
+func SaveCommandsIfVersion(commands []Command, expectedVersion string) (string, error) {
+	path := storage.GetCommandsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+
+	saved := make([]Command, len(commands))
+	for i, cmd := range commands {
+		cmd.RunCount, cmd.LastUsed = 0, nil
+		saved[i] = cmd
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	commandsFileMutex.Lock()
+	defer commandsFileMutex.Unlock()
+
+	unlock, err := acquireFileLock(path + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Read as CommandsVersion does, so a version it returned still matches
+	current, err := storage.ReadJSONFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read commands file: %w", err)
+	}
+	if expectedVersion != "" && contentVersion(current) != expectedVersion {
+		return "", ErrVersionConflict
+	}
+
+	if current != nil && string(current) != string(data) {
+		if err := backupCommands(current); err != nil {
+			return "", fmt.Errorf("failed to back up commands: %w", err)
+		}
+	}
+
+	if err := storage.WriteFileAtomic(path, data, 0600); err != nil {
+		return "", err
+	}
+	return contentVersion(data), nil
+}
+
+// acquireFileLock creates an exclusive lock file, waiting for other writers and
+// breaking locks left behind by a crashed process.
+func acquireFileLock(lockPath string) (func(), error) {
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// backupCommands writes data to a timestamped backup and prunes old ones.
+func backupCommands(data []byte) error {
+	dir := storage.GetCommandBackupsDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	name := "commands-" + time.Now().Format(backupTimeFormat) + ".json"
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		return err
+	}
+
+	backups, err := ListCommandBackups()
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(len(backups), MaxCommandBackups):] {
+		os.Remove(filepath.Join(dir, b.ID))
+	}
+	return nil
+}
+
+// ListCommandBackups returns saved backups, newest first.
+func ListCommandBackups() ([]CommandBackup, error) {
+	dir := storage.GetCommandBackupsDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []CommandBackup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []CommandBackup{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "commands-") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		created, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, "commands-"), ".json"), time.Local)
+		if err != nil {
+			continue
+		}
+
+		backup := CommandBackup{ID: name, CreatedAt: created}
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			var cmds []Command
+			if json.Unmarshal(data, &cmds) == nil {
+				backup.Count = len(cmds)
+			}
+			backup.Size = int64(len(data))
+		}
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+	return backups, nil
+}
+
+// RestoreCommandBackup replaces the current commands with a backup. The current
+// commands are themselves backed up, so a restore can be undone.
+func RestoreCommandBackup(id string) ([]Command, error) {
+	if id != filepath.Base(id) || !strings.HasPrefix(id, "commands-") {
+		return nil, fmt.Errorf("invalid backup id %q", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(storage.GetCommandBackupsDir(), id))
+	if err != nil {
+		return nil, fmt.Errorf("backup %s not found", id)
+	}
+
+	var cmds []Command
+	if err := json.Unmarshal(data, &cmds); err != nil {
+		return nil, fmt.Errorf("backup %s is corrupt: %w", id, err)
+	}
+
+	if err := SaveCommands(cmds); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
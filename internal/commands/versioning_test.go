@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSaveCommandsIfVersion(t *testing.T) {
+	withTempHome(t)
+
+	v1, err := SaveCommandsIfVersion([]Command{{ID: 1, Command: "ls"}}, "")
+	if err != nil || v1 == "" {
+		t.Fatalf("Initial save failed: %q %v", v1, err)
+	}
+
+	_, loaded, err := LoadCommandsWithVersion()
+	if err != nil || loaded != v1 {
+		t.Fatalf("Expected version %s, got %s (%v)", v1, loaded, err)
+	}
+
+	// Window A saves on top of v1
+	v2, err := SaveCommandsIfVersion([]Command{{ID: 1, Command: "ls -la"}}, v1)
+	if err != nil || v2 == v1 {
+		t.Fatalf("Expected new version, got %q %v", v2, err)
+	}
+
+	// Window B still holds v1 and must not clobber A's change
+	if _, err := SaveCommandsIfVersion([]Command{{ID: 1, Command: "pwd"}}, v1); err != ErrVersionConflict {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	cmds, _ := LoadCommands()
+	if cmds[0].Command != "ls -la" {
+		t.Errorf("Expected stale save to be rejected, got %q", cmds[0].Command)
+	}
+}
+
+func TestSaveCommandsIfVersion_IgnoresUsage(t *testing.T) {
+	withTempHome(t)
+
+	lastUsed := time.Now()
+	cmds := []Command{{ID: 1, Command: "ls", RunCount: 99, LastUsed: &lastUsed}}
+	if err := SaveCommands(cmds); err != nil {
+		t.Fatal(err)
+	}
+	if cmds[0].RunCount != 99 {
+		t.Error("Expected the caller's commands left alone")
+	}
+	saved, err := LoadCommands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[0].RunCount != 0 || saved[0].LastUsed != nil {
+		t.Errorf("Expected usage not to be saved, got %+v", saved[0])
+	}
+}
+
+func TestSaveCommandsIfVersion_Recovered(t *testing.T) {
+	dir := withTempHome(t)
+
+	v1, err := SaveCommandsIfVersion([]Command{{ID: 1, Command: "ls"}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveCommandsIfVersion([]Command{{ID: 1, Command: "ls -la"}}, v1); err != nil {
+		t.Fatal(err)
+	}
+	// Corrupted outside Forge: the version comes from the recovered backup
+	os.WriteFile(filepath.Join(dir, "commands.json"), []byte("{not json"), 0600)
+	version, err := CommandsVersion()
+	if err != nil || version != v1 {
+		t.Fatalf("Expected the backup's version %s, got %s (%v)", v1, version, err)
+	}
+	if _, err := SaveCommandsIfVersion([]Command{{ID: 1, Command: "pwd"}}, version); err != nil {
+		t.Errorf("Expected a save at the version CommandsVersion returned, got %v", err)
+	}
+}
+
+func TestCommandBackups(t *testing.T) {
+	dir := withTempHome(t)
+
+	SaveCommands([]Command{{ID: 1, Command: "first"}})
+	SaveCommands([]Command{{ID: 1, Command: "first"}}) // Unchanged: no backup
+	time.Sleep(2 * time.Millisecond)
+	SaveCommands([]Command{{ID: 1, Command: "second"}, {ID: 2, Command: "extra"}})
+
+	backups, err := ListCommandBackups()
+	if err != nil {
+		t.Fatalf("ListCommandBackups failed: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Count != 1 {
+		t.Fatalf("Expected one backup of the first version, got %+v", backups)
+	}
+
+	restored, err := RestoreCommandBackup(backups[0].ID)
+	if err != nil || len(restored) != 1 || restored[0].Command != "first" {
+		t.Fatalf("Restore failed: %+v %v", restored, err)
+	}
+
+	// The restore itself backed up the version it replaced
+	backups, _ = ListCommandBackups()
+	if len(backups) != 2 || backups[0].Count != 2 {
+		t.Errorf("Expected restore to back up current commands, got %+v", backups)
+	}
+
+	if _, err := RestoreCommandBackup("../commands.json"); err == nil {
+		t.Error("Expected invalid backup id to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "commands.json.lock")); !os.IsNotExist(err) {
+		t.Error("Expected lock file to be released")
+	}
+}
+
+func TestCommandBackups_Pruned(t *testing.T) {
+	withTempHome(t)
+
+	for i := 0; i <= MaxCommandBackups+3; i++ {
+		SaveCommands([]Command{{ID: i + 1}})
+		time.Sleep(2 * time.Millisecond)
+	}
+	backups, _ := ListCommandBackups()
+	if len(backups) != MaxCommandBackups {
+		t.Errorf("Expected %d backups, got %d", MaxCommandBackups, len(backups))
+	}
+}
+
+func TestSaveCommands_Concurrent(t *testing.T) {
+	withTempHome(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := SaveCommands([]Command{{ID: id}}); err != nil {
+				t.Errorf("Concurrent save failed: %v", err)
+			}
+		}(i + 1)
+	}
+	wg.Wait()
+
+	if _, err := LoadCommands(); err != nil {
+		t.Errorf("Commands file corrupted by concurrent saves: %v", err)
+	}
+}
+
+func TestAcquireFileLock_Stale(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "test.lock")
+	os.WriteFile(lockPath, []byte("999999"), 0600)
+	old := time.Now().Add(-2 * lockStale)
+	os.Chtimes(lockPath, old, old)
+
+	unlock, err := acquireFileLock(lockPath)
+	if err != nil {
+		t.Fatalf("Expected stale lock to be broken, got %v", err)
+	}
+	unlock()
+}
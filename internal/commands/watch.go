@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// watchInterval is how often the config and commands files are checked for external edits
+const watchInterval = 2 * time.Second
+
+// Watched files reported in FileChange.File
+const (
+	WatchConfig   = "config"
+	WatchCommands = "commands"
+)
+
+// FileChange describes a config or commands file that changed on disk. Config or
+// Commands holds the new contents; Error is set (and nothing should be applied)
+// when the edited file doesn't parse or validate.
+type FileChange struct {
+	File     string    `json:"file"`
+	Time     time.Time `json:"time"`
+	Config   *Config   `json:"config,omitempty"`
+	Commands []Command `json:"commands,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Watcher polls config.json and commands.json so edits made outside the UI
+// (dotfile managers, text editors) take effect without a restart.
+type Watcher struct {
+	mu          sync.Mutex
+	stop        chan struct{}
+	contents    map[string][]byte
+	subscribers map[chan FileChange]struct{}
+}
+
+// NewWatcher returns a watcher; call Start to begin polling.
+func NewWatcher() *Watcher {
+	return &Watcher{
+		contents:    make(map[string][]byte),
+		subscribers: make(map[chan FileChange]struct{}),
+	}
+}
+
+// Start records the current file contents and checks for changes in the background.
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	if w.stop != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.stop = make(chan struct{})
+	stop := w.stop
+	w.mu.Unlock()
+
+	w.check(false)
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.check(true)
+			}
+		}
+	}()
+}
+
+// Stop ends polling.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+// Subscribe returns a channel receiving file changes until cancel is called.
+func (w *Watcher) Subscribe() (<-chan FileChange, func()) {
+	ch := make(chan FileChange, 10)
+	w.mu.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			delete(w.subscribers, ch)
+			w.mu.Unlock()
+		})
+	}
+}
+
+// check compares each file with its last seen contents, publishing changes when notify is set.
+func (w *Watcher) check(notify bool) {
+	for _, file := range []string{WatchConfig, WatchCommands} {
+		path := storage.GetTerminalConfigPath()
+		if file == WatchCommands {
+			path = storage.GetCommandsPath()
+		}
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			continue
+		}
+
+		w.mu.Lock()
+		last, seen := w.contents[file]
+		changed := !seen || !bytes.Equal(last, data)
+		w.contents[file] = data
+		w.mu.Unlock()
+
+		// Deleting a file isn't an edit to apply; the defaults return on next load
+		if !notify || !changed || !seen || data == nil {
+			continue
+		}
+		change := loadChange(file, data)
+		if change.Error != "" {
+			log.Printf("[Commands] Ignoring edit to %s.json: %s", file, change.Error)
+		} else {
+			log.Printf("[Commands] Reloaded %s.json after external edit", file)
+		}
+		w.publish(change)
+	}
+}
+
+// loadChange parses and validates new file contents.
+func loadChange(file string, data []byte) FileChange {
+	change := FileChange{File: file, Time: time.Now()}
+	switch file {
+	case WatchConfig:
+		config, _, err := ParseConfig(data, DefaultConfig)
+		if err != nil {
+			change.Error = err.Error()
+			return change
+		}
+		change.Config = config
+	case WatchCommands:
+		cmds, err := parseCommands(data)
+		if err != nil {
+			change.Error = err.Error()
+			return change
+		}
+		change.Commands = cmds
+	}
+	return change
+}
+
+// parseCommands decodes and validates an edited commands file.
+func parseCommands(data []byte) ([]Command, error) {
+	var cmds []Command
+	if err := json.Unmarshal(data, &cmds); err != nil {
+		return nil, fmt.Errorf("failed to parse commands JSON: %w", err)
+	}
+	for i := range cmds {
+		if err := ValidateCommand(&cmds[i]); err != nil {
+			return nil, err
+		}
+	}
+	return cmds, nil
+}
+
+func (w *Watcher) publish(change FileChange) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subscribers {
+		select {
+		case ch <- change:
+		default: // Slow subscriber; it will pick up the file on its next load
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatcherReportsExternalEdits(t *testing.T) {
+	dir := withTempHome(t)
+	configFile := filepath.Join(dir, "config.json")
+	commandsFile := filepath.Join(dir, "commands.json")
+
+	w := NewWatcher()
+	changes, cancel := w.Subscribe()
+	defer cancel()
+
+	// The first check only records the current state
+	w.check(false)
+	w.check(true)
+	if len(changes) != 0 {
+		t.Fatalf("unexpected change before any edit: %+v", <-changes)
+	}
+
+	if err := os.WriteFile(configFile, []byte(`{"shellType":"wsl","wslDistro":"Ubuntu"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check(true)
+	change := <-changes
+	if change.File != WatchConfig || change.Error != "" || change.Config == nil || change.Config.WSLDistro != "Ubuntu" {
+		t.Errorf("config change = %+v", change)
+	}
+
+	// Unchanged contents don't publish again
+	w.check(true)
+	if len(changes) != 0 {
+		t.Errorf("unexpected change: %+v", <-changes)
+	}
+
+	if err := os.WriteFile(commandsFile, []byte(`[{"id":1,"description":"List","command":"ls"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check(true)
+	change = <-changes
+	if change.File != WatchCommands || len(change.Commands) != 1 || change.Commands[0].Command != "ls" {
+		t.Errorf("commands change = %+v", change)
+	}
+
+	// Invalid edits are reported but carry no values to apply
+	if err := os.WriteFile(configFile, []byte(`{"shellType":"fish"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check(true)
+	change = <-changes
+	if change.Error == "" || change.Config != nil {
+		t.Errorf("invalid config change = %+v", change)
+	}
+
+	if err := os.WriteFile(commandsFile, []byte(`[{"id":1,`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check(true)
+	change = <-changes
+	if change.Error == "" || change.Commands != nil {
+		t.Errorf("invalid commands change = %+v", change)
+	}
+}
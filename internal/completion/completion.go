@@ -0,0 +1,217 @@
+// Package completion suggests how to finish a command line as it is typed,
+// from the command history of every tab, command cards, files in the
+// shell's directory, and git branches, most recently used first.
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+	"github.com/mikejsmith1985/forge-terminal/internal/files"
+	"github.com/mikejsmith1985/forge-terminal/internal/git"
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+)
+
+// Suggestion kinds
+const (
+	KindHistory = "history"
+	KindCommand = "command" // A command card
+	KindFile    = "file"
+	KindBranch  = "branch"
+)
+
+// DefaultLimit is how many suggestions are returned when no limit is given
+const DefaultLimit = 20
+
+// maxDirEntries bounds how much of a large directory is read
+const maxDirEntries = 2000
+
+// branchCommands are the git subcommands whose arguments are usually branches
+var branchCommands = map[string]bool{
+	"checkout": true, "switch": true, "merge": true, "rebase": true, "branch": true,
+	"push": true, "pull": true, "log": true, "diff": true, "cherry-pick": true,
+	"reset": true, "show": true, "worktree": true,
+}
+
+// Suggestion is a way to finish the line being typed.
+type Suggestion struct {
+	Text     string    `json:"text"` // The whole line once accepted
+	Kind     string    `json:"kind"`
+	Detail   string    `json:"detail,omitempty"` // Card description, or "directory"
+	LastUsed time.Time `json:"lastUsed,omitempty"`
+}
+
+// Request is what is being completed.
+type Request struct {
+	Prefix string // The line typed so far
+	Dir    string // The shell's directory, for files and branches (empty = skip them)
+	Limit  int    // At most this many suggestions (0 = DefaultLimit)
+}
+
+// Complete returns suggestions that extend req.Prefix, most recently used
+// first. A source that fails is skipped rather than failing the others.
+func Complete(ctx context.Context, req Request) []Suggestion {
+	var all []Suggestion
+	if req.Prefix != "" {
+		all = append(all, fromHistory(req.Prefix)...)
+		all = append(all, fromCommandCards(req.Prefix)...)
+	}
+	if req.Dir != "" {
+		all = append(all, fromFiles(req.Prefix, req.Dir)...)
+		all = append(all, fromBranches(ctx, req.Prefix, req.Dir)...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].LastUsed.After(all[j].LastUsed)
+	})
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	seen := map[string]bool{}
+	suggestions := []Suggestion{}
+	for _, s := range all {
+		if seen[s.Text] {
+			continue
+		}
+		seen[s.Text] = true
+		suggestions = append(suggestions, s)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+	return suggestions
+}
+
+// fromHistory suggests commands typed before that start with prefix
+func fromHistory(prefix string) []Suggestion {
+	matches, err := history.Search(history.Query{Text: prefix, Limit: 500})
+	if err != nil {
+		return nil
+	}
+	var suggestions []Suggestion
+	for _, m := range matches {
+		if len(m.Command) > len(prefix) && strings.HasPrefix(m.Command, prefix) {
+			suggestions = append(suggestions, Suggestion{Text: m.Command, Kind: KindHistory, LastUsed: m.LastUsed})
+		}
+	}
+	return suggestions
+}
+
+// fromCommandCards suggests command cards whose command starts with prefix
+func fromCommandCards(prefix string) []Suggestion {
+	cards, err := commands.LoadCommands()
+	if err != nil {
+		return nil
+	}
+	if usage, err := commands.LoadUsage(); err == nil {
+		commands.ApplyUsage(cards, usage)
+	}
+	var suggestions []Suggestion
+	for _, card := range cards {
+		if len(card.Command) <= len(prefix) || !strings.HasPrefix(card.Command, prefix) || strings.Contains(card.Command, "\n") {
+			continue
+		}
+		s := Suggestion{Text: card.Command, Kind: KindCommand, Detail: card.Description}
+		if card.LastUsed != nil {
+			s.LastUsed = *card.LastUsed
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
+
+// lastWord splits the line into what comes before the word being typed and the word
+func lastWord(prefix string) (string, string) {
+	i := strings.LastIndexAny(prefix, " \t")
+	return prefix[:i+1], prefix[i+1:]
+}
+
+// fromFiles completes the word being typed as a path: an argument, or a
+// command given with a path like ./build.sh
+func fromFiles(prefix, dir string) []Suggestion {
+	before, word := lastWord(prefix)
+	if before == "" && !strings.ContainsAny(word, `/\`) {
+		return nil // A command name, which history and cards cover
+	}
+
+	wordDir, partial := "", word
+	if i := strings.LastIndexAny(word, `/\`); i >= 0 {
+		wordDir, partial = word[:i+1], word[i+1:]
+	}
+	searchDir := wordDir
+	switch {
+	case strings.HasPrefix(wordDir, "~/") || strings.HasPrefix(wordDir, `~\`):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		searchDir = filepath.Join(home, wordDir[2:])
+	case !filepath.IsAbs(wordDir):
+		searchDir = filepath.Join(dir, wordDir)
+	}
+
+	if !files.PathAllowed(filepath.Clean(searchDir)) {
+		return nil
+	}
+	f, err := os.Open(searchDir)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(maxDirEntries)
+	if err != nil && len(entries) == 0 {
+		return nil
+	}
+
+	var suggestions []Suggestion
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, partial) || name == partial || (strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".")) {
+			continue
+		}
+		s := Suggestion{Text: before + wordDir + quote(name), Kind: KindFile}
+		if entry.IsDir() {
+			s.Text += "/"
+			s.Detail = "directory"
+		}
+		if info, err := entry.Info(); err == nil {
+			s.LastUsed = info.ModTime()
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
+
+// quote escapes the characters shells split or expand in a file name
+func quote(name string) string {
+	if !strings.ContainsAny(name, " '\"$`&|;<>()*?!#") {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", `'\''`) + "'"
+}
+
+// fromBranches completes a branch name after git subcommands that take one
+func fromBranches(ctx context.Context, prefix, dir string) []Suggestion {
+	before, word := lastWord(prefix)
+	fields := strings.Fields(before)
+	if len(fields) < 2 || fields[0] != "git" || !branchCommands[fields[1]] || strings.HasPrefix(word, "-") {
+		return nil
+	}
+	branches, err := git.RecentBranches(ctx, dir)
+	if err != nil {
+		return nil
+	}
+	var suggestions []Suggestion
+	for _, b := range branches {
+		if strings.HasPrefix(b.Name, word) && b.Name != word {
+			suggestions = append(suggestions, Suggestion{Text: before + b.Name, Kind: KindBranch, LastUsed: b.CommittedAt})
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,129 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastWord(t *testing.T) {
+	tests := []struct{ prefix, before, word string }{
+		{"git checkout ma", "git checkout ", "ma"},
+		{"ls ", "ls ", ""},
+		{"ls", "", "ls"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if before, word := lastWord(tt.prefix); before != tt.before || word != tt.word {
+			t.Errorf("lastWord(%q) = %q, %q, want %q, %q", tt.prefix, before, word, tt.before, tt.word)
+		}
+	}
+}
+
+func TestFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src", "app"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "setup.sh"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "my notes.txt"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, ".secret"), nil, 0644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "setup.sh"), old, old)
+
+	texts := func(suggestions []Suggestion) map[string]string {
+		got := map[string]string{}
+		for _, s := range suggestions {
+			got[s.Text] = s.Detail
+		}
+		return got
+	}
+
+	got := texts(fromFiles("cat s", dir))
+	if len(got) != 2 || got["cat src/"] != "directory" {
+		t.Errorf("Expected src/ and setup.sh, got %v", got)
+	}
+	if _, ok := got["cat setup.sh"]; !ok {
+		t.Errorf("Expected setup.sh, got %v", got)
+	}
+	if got := texts(fromFiles("vim src/m", dir)); len(got) != 1 {
+		t.Errorf("Expected src/main.go, got %v", got)
+	}
+	if got := texts(fromFiles("cat my", dir)); len(got) != 1 {
+		t.Errorf("Expected the quoted name, got %v", got)
+	} else if _, ok := got["cat 'my notes.txt'"]; !ok {
+		t.Errorf("Expected the name quoted, got %v", got)
+	}
+	if got := texts(fromFiles("cat ", dir)); len(got) != 3 {
+		t.Errorf("Expected hidden files left out, got %v", got)
+	}
+	if got := texts(fromFiles("cat .", dir)); len(got) != 1 {
+		t.Errorf("Expected hidden files when asked for, got %v", got)
+	}
+	if got := fromFiles("s", dir); got != nil {
+		t.Errorf("Expected no files for a command name, got %v", got)
+	}
+	if got := texts(fromFiles("./s", dir)); len(got) != 2 {
+		t.Errorf("Expected files for a command given as a path, got %v", got)
+	}
+}
+
+func TestCompleteOrdersByRecency(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	dir := t.TempDir()
+	for i, name := range []string{"a-old", "a-new", "a-mid"} {
+		at := time.Now().Add(-time.Duration(3-i) * time.Hour)
+		if name == "a-new" {
+			at = time.Now()
+		}
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, nil, 0644)
+		os.Chtimes(path, at, at)
+	}
+
+	got := Complete(context.Background(), Request{Dir: dir, Prefix: "rm a", Limit: 2})
+	if len(got) != 2 || got[0].Text != "rm a-new" || got[1].Text != "rm a-mid" {
+		t.Errorf("Expected the two newest files, newest first, got %+v", got)
+	}
+	if got := Complete(context.Background(), Request{Dir: dir}); len(got) != 0 {
+		t.Errorf("Expected nothing for an empty line, got %+v", got)
+	}
+}
+
+func TestFromBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	gitCmd("init", "-q", "-b", "main")
+	gitCmd("commit", "-q", "--allow-empty", "-m", "First")
+	gitCmd("branch", "feature/login")
+	gitCmd("branch", "fix-typo")
+
+	ctx := context.Background()
+	if got := fromBranches(ctx, "git checkout f", dir); len(got) != 2 {
+		t.Errorf("Expected both f branches, got %+v", got)
+	}
+	if got := fromBranches(ctx, "git switch feature/", dir); len(got) != 1 || got[0].Text != "git switch feature/login" {
+		t.Errorf("Expected feature/login, got %+v", got)
+	}
+	if got := fromBranches(ctx, "git add f", dir); got != nil {
+		t.Errorf("Expected no branches for git add, got %+v", got)
+	}
+	if got := fromBranches(ctx, "git checkout -", dir); got != nil {
+		t.Errorf("Expected no branches for a flag, got %+v", got)
+	}
+}
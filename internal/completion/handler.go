@@ -0,0 +1,51 @@
+package completion
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/files"
+)
+
+// HandleComplete suggests ways to finish a command line (GET ?prefix=&cwd=&limit=).
+// cwd is the shell's directory; files and branches are only suggested when it
+// is given.
+func HandleComplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	req := Request{Prefix: query.Get("prefix")}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		req.Limit = n
+	}
+	if cwd := query.Get("cwd"); cwd != "" {
+		if !filepath.IsAbs(cwd) {
+			http.Error(w, "cwd must be absolute", http.StatusBadRequest)
+			return
+		}
+		if info, err := os.Stat(cwd); err != nil || !info.IsDir() {
+			http.Error(w, "Directory not found: "+cwd, http.StatusNotFound)
+			return
+		}
+		if !files.PathAllowed(cwd) {
+			http.Error(w, "Path is outside the allowed roots", http.StatusForbidden)
+			return
+		}
+		req.Dir = filepath.Clean(cwd)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"suggestions": Complete(r.Context(), req),
+	})
+}
@@ -0,0 +1,238 @@
+// Package crash writes a report when Forge dies from a panic, so the next
+// start can tell the user it recovered and offer the report for a bug report.
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+const (
+	// maxReports is how many reports are kept; older ones are removed
+	maxReports = 20
+
+	// maxLogEntries is how many recent log entries a report includes
+	maxLogEntries = 200
+
+	// maxStackBytes bounds the goroutine dump
+	maxStackBytes = 1 << 20
+)
+
+var (
+	mu      sync.Mutex
+	version = "unknown"
+)
+
+// Report is a crash report as written to ~/.forge/crashes
+type Report struct {
+	ID           string          `json:"id"`
+	Time         time.Time       `json:"time"`
+	Version      string          `json:"version"`
+	PID          int             `json:"pid"`
+	OS           string          `json:"os"`
+	Arch         string          `json:"arch"`
+	GoVersion    string          `json:"goVersion"`
+	Panic        string          `json:"panic"`
+	Stack        string          `json:"stack"` // Every goroutine, the crashing one first
+	Logs         []logging.Entry `json:"logs,omitempty"`
+	Acknowledged bool            `json:"acknowledged"` // The user has seen the recovery notice
+}
+
+// Summary is a report without its stack and logs, for listings
+type Summary struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	Version      string    `json:"version"`
+	Panic        string    `json:"panic"`
+	Acknowledged bool      `json:"acknowledged"`
+}
+
+func (r Report) summary() Summary {
+	return Summary{ID: r.ID, Time: r.Time, Version: r.Version, Panic: r.Panic, Acknowledged: r.Acknowledged}
+}
+
+// SetVersion sets the version recorded in reports
+func SetVersion(v string) {
+	mu.Lock()
+	version = v
+	mu.Unlock()
+}
+
+// Recover writes a report for a panic in progress and lets it continue, so
+// the process still exits with the usual stack on stderr. Defer it at the
+// top of main and of long-lived goroutines; Go has no hook for panics in
+// goroutines that don't defer it (HTTP handlers are covered by
+// middleware.Recover, which keeps the server running instead).
+func Recover() {
+	p := recover()
+	if p == nil {
+		return
+	}
+	if path, err := Save(p); err != nil {
+		fmt.Fprintf(os.Stderr, "[Crash] Failed to write crash report: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "[Crash] Crash report written to %s\n", path)
+	}
+	panic(p)
+}
+
+// Go runs fn in a goroutine that writes a crash report if it panics.
+func Go(fn func()) {
+	go func() {
+		defer Recover()
+		fn()
+	}()
+}
+
+// Save writes a report for the panic value p with the current goroutine
+// stacks and recent log entries, returning its path.
+func Save(p interface{}) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	stack := make([]byte, maxStackBytes)
+	stack = stack[:runtime.Stack(stack, true)]
+	report := Report{
+		ID:        fmt.Sprintf("crash-%s-%d", now.Format("20060102-150405"), os.Getpid()),
+		Time:      now,
+		Version:   version,
+		PID:       os.Getpid(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		Panic:     fmt.Sprint(p),
+		Stack:     string(stack),
+		Logs:      logging.Buffer().Query(slog.LevelDebug, time.Time{}, maxLogEntries),
+	}
+
+	dir := storage.GetCrashesDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, report.ID+".json")
+	if err := write(path, report); err != nil {
+		return "", err
+	}
+	prune(dir)
+	return path, nil
+}
+
+func write(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := storage.WriteFileAtomic(path, data, 0600); err != nil {
+		return err
+	}
+	// Reports are written once and only flagged afterwards; no backup is needed
+	os.Remove(path + storage.BackupSuffix)
+	return nil
+}
+
+// prune removes the oldest reports beyond maxReports
+func prune(dir string) {
+	names := reportNames(dir)
+	for len(names) > maxReports {
+		os.Remove(filepath.Join(dir, names[0]))
+		names = names[1:]
+	}
+}
+
+// reportNames lists report files, oldest first (IDs sort by time)
+func reportNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "crash-") && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get reads the report with the given ID
+func Get(id string) (Report, error) {
+	if id == "" || filepath.Base(id) != id || !strings.HasPrefix(id, "crash-") {
+		return Report{}, fmt.Errorf("invalid crash report ID %q", id)
+	}
+	data, err := storage.ReadJSONFile(filepath.Join(storage.GetCrashesDir(), id+".json"))
+	if err != nil {
+		return Report{}, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+// List summarizes the saved reports, newest first
+func List() []Summary {
+	names := reportNames(storage.GetCrashesDir())
+	summaries := make([]Summary, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		report, err := Get(strings.TrimSuffix(names[i], ".json"))
+		if err != nil {
+			continue
+		}
+		summaries = append(summaries, report.summary())
+	}
+	return summaries
+}
+
+// Unacknowledged summarizes the reports the user hasn't been told about,
+// newest first
+func Unacknowledged() []Summary {
+	var pending []Summary
+	for _, s := range List() {
+		if !s.Acknowledged {
+			pending = append(pending, s)
+		}
+	}
+	return pending
+}
+
+// Acknowledge marks a report as seen, or every report when id is empty
+func Acknowledge(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	ids := []string{id}
+	if id == "" {
+		ids = nil
+		for _, s := range Unacknowledged() {
+			ids = append(ids, s.ID)
+		}
+	}
+	for _, id := range ids {
+		report, err := Get(id)
+		if err != nil {
+			return err
+		}
+		if report.Acknowledged {
+			continue
+		}
+		report.Acknowledged = true
+		if err := write(filepath.Join(storage.GetCrashesDir(), id+".json"), report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
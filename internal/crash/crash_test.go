@@ -0,0 +1,123 @@
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func withReportsDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	dir := storage.GetCrashesDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRecoverWritesReport(t *testing.T) {
+	withReportsDir(t)
+	SetVersion("1.2.3")
+	t.Cleanup(func() { SetVersion("unknown") })
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("Expected the panic to continue, got %v", p)
+			}
+		}()
+		defer Recover()
+		panic("boom")
+	}()
+
+	pending := Unacknowledged()
+	if len(pending) != 1 || pending[0].Panic != "boom" || pending[0].Version != "1.2.3" {
+		t.Fatalf("Expected one pending report for the panic, got %+v", pending)
+	}
+	report, err := Get(pending[0].ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !strings.Contains(report.Stack, "TestRecoverWritesReport") || report.PID != os.Getpid() {
+		t.Errorf("Expected the stack and pid in the report, got pid %d stack %q", report.PID, report.Stack)
+	}
+
+	notice := PendingNotice()
+	if notice == nil || notice.Count != 1 || notice.Latest.ID != report.ID {
+		t.Fatalf("Expected a recovery notice, got %+v", notice)
+	}
+	if err := Acknowledge(""); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+	if PendingNotice() != nil {
+		t.Error("Expected no notice after acknowledging")
+	}
+	if list := List(); len(list) != 1 || !list[0].Acknowledged {
+		t.Errorf("Expected the report to be kept as acknowledged, got %+v", list)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := withReportsDir(t)
+	for i := 0; i < maxReports+3; i++ {
+		id := fmt.Sprintf("crash-20250101-0000%02d-1", i)
+		if err := write(dir+"/"+id+".json", Report{ID: id, Time: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Save("latest"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	list := List()
+	if len(list) != maxReports || list[0].Panic != "latest" {
+		t.Errorf("Expected %d reports, newest first, got %d (first %+v)", maxReports, len(list), list[0])
+	}
+	if _, err := Get("crash-20250101-000000-1"); !os.IsNotExist(err) {
+		t.Errorf("Expected the oldest report to be removed, got %v", err)
+	}
+}
+
+func TestHandleCrashes(t *testing.T) {
+	withReportsDir(t)
+	if _, err := Save("handler"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleCrashes(rec, httptest.NewRequest("GET", "/api/v1/crashes", nil))
+	var listing struct {
+		Crashes []Summary `json:"crashes"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&listing); err != nil || len(listing.Crashes) != 1 {
+		t.Fatalf("Expected one crash listed, got %+v (%v)", listing, err)
+	}
+	id := listing.Crashes[0].ID
+
+	rec = httptest.NewRecorder()
+	HandleCrashes(rec, httptest.NewRequest("GET", "/api/v1/crashes?id="+id, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"stack"`) {
+		t.Errorf("Expected the full report, got %d %s", rec.Code, rec.Body.String())
+	}
+	for _, bad := range []string{"crash-missing", "../secrets"} {
+		rec = httptest.NewRecorder()
+		HandleCrashes(rec, httptest.NewRequest("GET", "/api/v1/crashes?id="+bad, nil))
+		if rec.Code == http.StatusOK {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	HandleCrashes(rec, httptest.NewRequest("POST", "/api/v1/crashes", strings.NewReader(`{"id":"`+id+`"}`)))
+	if rec.Code != http.StatusOK || PendingNotice() != nil {
+		t.Errorf("Expected the report to be acknowledged, got %d", rec.Code)
+	}
+}
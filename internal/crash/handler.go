@@ -0,0 +1,77 @@
+package crash
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+)
+
+// AcknowledgeRequest is the body for POST /api/crashes
+type AcknowledgeRequest struct {
+	ID string `json:"id,omitempty"` // Empty acknowledges every report
+}
+
+// Notice is the "crash" event sent to the UI while there are unacknowledged
+// reports
+type Notice struct {
+	Message string  `json:"message"`
+	Count   int     `json:"count"`
+	Latest  Summary `json:"latest"`
+}
+
+// PendingNotice returns the recovery notice to show, or nil if every report
+// has been acknowledged
+func PendingNotice() *Notice {
+	pending := Unacknowledged()
+	if len(pending) == 0 {
+		return nil
+	}
+	return &Notice{Message: "Forge recovered from an error", Count: len(pending), Latest: pending[0]}
+}
+
+// HandleCrashes lists crash reports, or returns one with ?id= (GET), and
+// acknowledges them so the recovery notice stops showing (POST).
+func HandleCrashes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		if id := r.URL.Query().Get("id"); id != "" {
+			report, err := Get(id)
+			if os.IsNotExist(err) {
+				http.Error(w, "Crash report not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(report)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"crashes": List(),
+		})
+
+	case http.MethodPost:
+		var req AcknowledgeRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := Acknowledge(req.ID); os.IsNotExist(err) {
+			http.Error(w, "Crash report not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,230 @@
+// Package diagnostics builds support bundles users can attach to bug reports.
+package diagnostics
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// maxLogBytes is how much of the end of the server log goes into a bundle
+const maxLogBytes = 1 << 20
+
+// sensitiveKeys are substrings of JSON keys whose values are always redacted
+var sensitiveKeys = []string{"token", "secret", "password", "apikey", "api_key", "credential", "auth"}
+
+// Redacted replaces redacted values
+const Redacted = "[REDACTED]"
+
+// Credentials found in free text, such as log lines: URL user info, token
+// query parameters and Authorization header values. The first group is kept.
+var (
+	urlUserInfo   = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/\s@]+@`)
+	tokenParam    = regexp.MustCompile(`(?i)([?&](?:access_)?token=)[^&\s"'#]+`)
+	authorization = regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?(?:(?:bearer|basic|token)\s+)?)[^\s"',]+`)
+)
+
+// Report is the information gathered into a bundle. Config and AMHealth are
+// written as JSON after redaction.
+type Report struct {
+	Version    string
+	StartedAt  time.Time
+	ListenAddr string
+	Ports      []int // Ports the server tries, in order
+	Config     interface{}
+	AMHealth   interface{}
+	LogPath    string
+	Extra      map[string]interface{} // Additional named sections, written as <name>.json
+	Files      map[string][]byte      // Files written as-is, e.g. screenshots; names may include directories
+}
+
+// Summary is the summary.json section of a bundle
+type Summary struct {
+	Version     string    `json:"version"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	StartedAt   time.Time `json:"startedAt"`
+	Uptime      string    `json:"uptime"`
+	ListenAddr  string    `json:"listenAddr"`
+	Ports       []int     `json:"preferredPorts,omitempty"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	GoVersion   string    `json:"goVersion"`
+	NumCPU      int       `json:"numCpu"`
+	Goroutines  int       `json:"goroutines"`
+	HeapMB      float64   `json:"heapMb"`
+	Shell       string    `json:"shell,omitempty"`
+	WSL         bool      `json:"wsl,omitempty"`
+}
+
+// WriteBundle writes a zip with summary.json, config.json, am-health.json,
+// forge.log (the tail of the server log), and any extra sections and files.
+// Home directories and credentials are redacted throughout, except in Files.
+func WriteBundle(w io.Writer, report Report) error {
+	zw := zip.NewWriter(w)
+
+	if err := writeJSON(zw, "summary.json", Summarize(report)); err != nil {
+		return err
+	}
+	if report.Config != nil {
+		if err := writeJSON(zw, "config.json", Redact(report.Config)); err != nil {
+			return err
+		}
+	}
+	if report.AMHealth != nil {
+		if err := writeJSON(zw, "am-health.json", Redact(report.AMHealth)); err != nil {
+			return err
+		}
+	}
+	for name, section := range report.Extra {
+		if err := writeJSON(zw, name+".json", Redact(section)); err != nil {
+			return err
+		}
+	}
+	for name, data := range report.Files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	if report.LogPath != "" {
+		f, err := zw.Create("forge.log")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, RedactString(tailFile(report.LogPath, maxLogBytes))); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Summarize returns the summary.json section for a report.
+func Summarize(report Report) Summary {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	summary := Summary{
+		Version:     report.Version,
+		GeneratedAt: time.Now(),
+		StartedAt:   report.StartedAt,
+		ListenAddr:  report.ListenAddr,
+		Ports:       report.Ports,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GoVersion:   runtime.Version(),
+		NumCPU:      runtime.NumCPU(),
+		Goroutines:  runtime.NumGoroutine(),
+		HeapMB:      float64(mem.HeapAlloc) / (1 << 20),
+		Shell:       RedactString(os.Getenv("SHELL")),
+		WSL:         os.Getenv("WSL_DISTRO_NAME") != "",
+	}
+	if !report.StartedAt.IsZero() {
+		summary.Uptime = time.Since(report.StartedAt).Round(time.Second).String()
+	}
+	return summary
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// tailFile returns up to max bytes from the end of a file, starting at a line boundary.
+func tailFile(path string, max int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return "(log unavailable: " + err.Error() + ")\n"
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "(log unavailable: " + err.Error() + ")\n"
+	}
+	offset := info.Size() - max
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(data, offset); err != nil && err != io.EOF {
+		return "(log unavailable: " + err.Error() + ")\n"
+	}
+	if offset > 0 {
+		if idx := strings.IndexByte(string(data), '\n'); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+	return string(data)
+}
+
+// Redact returns a JSON-compatible copy of v with credentials removed and the
+// user's home directory replaced by "~".
+func Redact(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Redacted
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return Redacted
+	}
+	return redactValue("", generic)
+}
+
+func redactValue(key string, v interface{}) interface{} {
+	lower := strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(lower, s) {
+			if v == nil || v == "" {
+				return v
+			}
+			return Redacted
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = redactValue(k, item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactValue(key, item)
+		}
+		return val
+	case string:
+		return RedactString(val)
+	default:
+		return v
+	}
+}
+
+// RedactString masks URL credentials, access_token and token query
+// parameters, and Authorization values, and replaces the home directory with
+// "~". It works on single values and on whole log text.
+func RedactString(s string) string {
+	s = urlUserInfo.ReplaceAllString(s, "${1}REDACTED@")
+	s = tokenParam.ReplaceAllString(s, "${1}REDACTED")
+	s = authorization.ReplaceAllString(s, "${1}REDACTED")
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+	return s
+}
@@ -0,0 +1,255 @@
+// Package editor opens files at a line in the user's editor: VS Code, a
+// JetBrains IDE, vim in a new Forge tab, or any command configured per OS.
+//
+// A command template is split into arguments like a shell would (quotes group
+// words) and {path}, {line}, {column} and {dir} are filled in each argument,
+// so paths with spaces stay whole. A template starting with "tab:" is typed
+// into a new Forge tab in the file's directory instead of being started.
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Editor presets
+const (
+	VSCode    = "vscode"
+	JetBrains = "jetbrains"
+	Vim       = "vim"
+)
+
+// DefaultEditor is used when none is configured
+const DefaultEditor = VSCode
+
+// tabPrefix marks a template that runs in a new Forge tab
+const tabPrefix = "tab:"
+
+// presets are the command templates for each editor
+var presets = map[string]string{
+	VSCode:    "code -g {path}:{line}:{column}",
+	JetBrains: "idea --line {line} --column {column} {path}",
+	Vim:       "tab:vim +{line} {path}",
+}
+
+// Location is a position in a file.
+type Location struct {
+	Path   string `json:"path"`             // Absolute path
+	Line   int    `json:"line,omitempty"`   // 1-based; 0 = top of the file
+	Column int    `json:"column,omitempty"` // 1-based; 0 = start of the line
+}
+
+// String renders the location as path:line:column, the form editors accept
+func (l Location) String() string {
+	s := l.Path
+	if l.Line > 0 {
+		s += ":" + strconv.Itoa(l.Line)
+		if l.Column > 0 {
+			s += ":" + strconv.Itoa(l.Column)
+		}
+	}
+	return s
+}
+
+// Settings choose the editor.
+type Settings struct {
+	Editor   string            // A preset (empty = DefaultEditor)
+	Commands map[string]string // OS ("linux", "darwin", "windows") -> template, overriding Editor there
+}
+
+// Result reports how a file was opened.
+type Result struct {
+	Editor  string `json:"editor"`          // Preset name, or "custom"
+	Command string `json:"command"`         // As run, or typed into the tab
+	TabID   string `json:"tabId,omitempty"` // The tab vim (or another tab: template) runs in
+}
+
+var (
+	mu        sync.Mutex
+	settings  = Settings{Editor: DefaultEditor}
+	tabOpener func(dir, command string) (string, error)
+)
+
+// Validate checks the preset name and the command templates.
+func (s Settings) Validate() error {
+	if _, ok := presets[s.Editor]; !ok && s.Editor != "" {
+		return fmt.Errorf("unknown editor %q (expected %s)", s.Editor, strings.Join(Presets(), ", "))
+	}
+	for goos, template := range s.Commands {
+		if goos != "linux" && goos != "darwin" && goos != "windows" {
+			return fmt.Errorf("editor command for unknown OS %q (expected linux, darwin, or windows)", goos)
+		}
+		if err := validateTemplate(template); err != nil {
+			return fmt.Errorf("editor command for %s: %w", goos, err)
+		}
+	}
+	return nil
+}
+
+// Configure validates and applies editor settings.
+func Configure(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if s.Editor == "" {
+		s.Editor = DefaultEditor
+	}
+	mu.Lock()
+	settings = s
+	mu.Unlock()
+	return nil
+}
+
+// Presets returns the preset editor names, sorted.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTabOpener sets how "tab:" templates run: openTab starts command in a
+// new Forge tab in dir and returns the tab's ID. Call before serving.
+func SetTabOpener(openTab func(dir, command string) (string, error)) {
+	mu.Lock()
+	tabOpener = openTab
+	mu.Unlock()
+}
+
+func validateTemplate(template string) error {
+	args := splitArgs(strings.TrimPrefix(template, tabPrefix))
+	if len(args) == 0 {
+		return errors.New("is empty")
+	}
+	if !strings.Contains(template, "{path}") {
+		return errors.New("must include {path}")
+	}
+	return nil
+}
+
+// template returns the template for this OS and the editor it comes from,
+// with name overriding the configured preset when set
+func template(name string) (string, string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" {
+		if custom := settings.Commands[runtime.GOOS]; custom != "" {
+			return custom, "custom", nil
+		}
+		name = settings.Editor
+	}
+	preset, ok := presets[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown editor %q (expected %s)", name, strings.Join(Presets(), ", "))
+	}
+	return preset, name, nil
+}
+
+// Open opens loc in the configured editor, or in the preset named by
+// editorName if set, without waiting for the editor to exit.
+func Open(loc Location, editorName string) (Result, error) {
+	tmpl, name, err := template(editorName)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if rest, ok := strings.CutPrefix(tmpl, tabPrefix); ok {
+		mu.Lock()
+		openTab := tabOpener
+		mu.Unlock()
+		if openTab == nil {
+			return Result{}, errors.New("can't open a tab for the editor: no Forge page is connected")
+		}
+		command := strings.Join(expand(splitArgs(rest), loc, quoteArg), " ")
+		tabID, err := openTab(filepath.Dir(loc.Path), command)
+		if err != nil {
+			return Result{}, err
+		}
+		log.Printf("[Editor] Opened %s in tab %s", loc, tabID)
+		return Result{Editor: name, Command: command, TabID: tabID}, nil
+	}
+
+	args := expand(splitArgs(tmpl), loc, nil)
+	cmd := command(args)
+	if err := cmd.Start(); err != nil {
+		return Result{}, fmt.Errorf("failed to start %s: %w", args[0], err)
+	}
+	log.Printf("[Editor] Opened %s with %s", loc, name)
+	go cmd.Wait()
+	return Result{Editor: name, Command: strings.Join(args, " ")}, nil
+}
+
+// command builds the process for an editor command line; a variable for tests
+var command = func(args []string) *exec.Cmd {
+	return exec.Command(args[0], args[1:]...)
+}
+
+// expand fills the placeholders in each argument, quoting the values with
+// quote when the arguments will be typed into a shell
+func expand(args []string, loc Location, quote func(string) string) []string {
+	if quote == nil {
+		quote = func(s string) string { return s }
+	}
+	line, column := max(loc.Line, 1), max(loc.Column, 1)
+	replacer := strings.NewReplacer(
+		"{path}", quote(loc.Path),
+		"{dir}", quote(filepath.Dir(loc.Path)),
+		"{line}", strconv.Itoa(line),
+		"{column}", strconv.Itoa(column),
+	)
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = replacer.Replace(arg)
+	}
+	return expanded
+}
+
+// quoteArg quotes a value typed into the tab's shell when it needs it
+func quoteArg(s string) string {
+	if !strings.ContainsAny(s, " \t'\"$`\\&|;<>()*?!#") {
+		return s
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// splitArgs splits a template on whitespace, keeping quoted parts together
+func splitArgs(template string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	started := false
+	for _, r := range template {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote, started = r, true
+		case quote == 0 && (r == ' ' || r == '\t'):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args
+}
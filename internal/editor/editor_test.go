@@ -0,0 +1,140 @@
+package editor
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestLocationString(t *testing.T) {
+	tests := []struct {
+		loc  Location
+		want string
+	}{
+		{Location{Path: "/src/main.go"}, "/src/main.go"},
+		{Location{Path: "/src/main.go", Line: 12}, "/src/main.go:12"},
+		{Location{Path: "/src/main.go", Line: 12, Column: 5}, "/src/main.go:12:5"},
+		{Location{Path: "/src/main.go", Column: 5}, "/src/main.go"},
+	}
+	for _, tt := range tests {
+		if got := tt.loc.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.loc, got, tt.want)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"code -g {path}:{line}", []string{"code", "-g", "{path}:{line}"}},
+		{`"C:\Program Files\Sublime\subl.exe"  {path}:{line}`, []string{`C:\Program Files\Sublime\subl.exe`, "{path}:{line}"}},
+		{`emacsclient -n '+{line}' {path}`, []string{"emacsclient", "-n", "+{line}", "{path}"}},
+		{`open -a "" {path}`, []string{"open", "-a", "", "{path}"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := splitArgs(tt.template); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	loc := Location{Path: "/my src/main.go", Line: 12}
+	got := expand(splitArgs("idea --line {line} --column {column} {path}"), loc, nil)
+	want := []string{"idea", "--line", "12", "--column", "1", "/my src/main.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expand = %q, want %q", got, want)
+	}
+
+	if runtime.GOOS != "windows" {
+		got = expand(splitArgs("vim +{line} {path}"), loc, quoteArg)
+		if want := []string{"vim", "+12", "'/my src/main.go'"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expand with quoting = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		settings Settings
+		ok       bool
+	}{
+		{Settings{}, true},
+		{Settings{Editor: Vim}, true},
+		{Settings{Editor: "emacs"}, false},
+		{Settings{Commands: map[string]string{"linux": "tab:nano +{line} {path}"}}, true},
+		{Settings{Commands: map[string]string{"linux": "subl"}}, false},
+		{Settings{Commands: map[string]string{"linux": "tab:"}}, false},
+		{Settings{Commands: map[string]string{"plan9": "acme {path}"}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.settings.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%+v.Validate() = %v, want ok=%v", tt.settings, err, tt.ok)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	original := command
+	defer func() {
+		command = original
+		Configure(Settings{})
+	}()
+
+	var started []string
+	command = func(args []string) *exec.Cmd {
+		started = args
+		return exec.Command("true")
+	}
+	result, err := Open(Location{Path: "/src/main.go", Line: 3}, "")
+	if err != nil || !reflect.DeepEqual(started, []string{"code", "-g", "/src/main.go:3:1"}) {
+		t.Errorf("Expected VS Code started at line 3, got %q (%v)", started, err)
+	}
+	if result.Editor != VSCode {
+		t.Errorf("Expected editor %q, got %q", VSCode, result.Editor)
+	}
+
+	if err := Configure(Settings{Editor: JetBrains, Commands: map[string]string{runtime.GOOS: "subl {path}:{line}"}}); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := Open(Location{Path: "/src/main.go", Line: 7}, ""); result.Editor != "custom" || result.Command != "subl /src/main.go:7" {
+		t.Errorf("Expected the custom command for this OS, got %+v", result)
+	}
+	if result, _ := Open(Location{Path: "/src/main.go"}, JetBrains); result.Editor != JetBrains || started[0] != "idea" {
+		t.Errorf("Expected the named preset to win, got %+v", result)
+	}
+	if _, err := Open(Location{Path: "/src/main.go"}, "emacs"); err == nil {
+		t.Error("Expected an error for an unknown editor")
+	}
+
+	command = func([]string) *exec.Cmd { return exec.Command("forge-no-such-editor") }
+	if _, err := Open(Location{Path: "/src/main.go"}, VSCode); err == nil {
+		t.Error("Expected an error for a missing editor")
+	}
+}
+
+func TestOpenInTab(t *testing.T) {
+	defer SetTabOpener(nil)
+
+	SetTabOpener(nil)
+	if _, err := Open(Location{Path: "/src/main.go"}, Vim); err == nil {
+		t.Error("Expected an error with no way to open a tab")
+	}
+
+	var dir, typed string
+	SetTabOpener(func(d, command string) (string, error) {
+		dir, typed = d, command
+		return "tab-1", nil
+	})
+	result, err := Open(Location{Path: "/src/main.go", Line: 9}, Vim)
+	if err != nil || result.TabID != "tab-1" {
+		t.Fatalf("Expected vim in tab-1, got %+v (%v)", result, err)
+	}
+	if dir != "/src" || typed != "vim +9 /src/main.go" {
+		t.Errorf("Expected vim +9 /src/main.go in /src, got %q in %q", typed, dir)
+	}
+}
@@ -0,0 +1,60 @@
+package editor
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/files"
+)
+
+// OpenRequest is the body for POST /api/open-in-editor
+type OpenRequest struct {
+	Path   string `json:"path"` // Absolute path to an existing file
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Editor string `json:"editor,omitempty"` // A preset, overriding the configured editor
+}
+
+// HandleOpen opens a file at a line in the user's editor (POST), for the file
+// explorer's "Open in editor" action.
+func HandleOpen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req OpenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Path == "" || !filepath.IsAbs(req.Path) {
+		http.Error(w, "path must be absolute", http.StatusBadRequest)
+		return
+	}
+	if req.Line < 0 || req.Column < 0 {
+		http.Error(w, "line and column must not be negative", http.StatusBadRequest)
+		return
+	}
+	path := filepath.Clean(req.Path)
+	if _, err := os.Stat(path); err != nil {
+		http.Error(w, "File not found: "+req.Path, http.StatusNotFound)
+		return
+	}
+	if !files.PathAllowed(path) {
+		http.Error(w, "Path is outside the allowed roots", http.StatusForbidden)
+		return
+	}
+
+	result, err := Open(Location{Path: path, Line: req.Line, Column: req.Column}, req.Editor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"result":  result,
+	})
+}
@@ -0,0 +1,323 @@
+// Package feedback files user feedback as GitHub issues, with screenshots and
+// recent server logs attached, or saves it as a local bundle when it can't.
+package feedback
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/diagnostics"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+const (
+	repo = "mikejsmith1985/forge-terminal"
+
+	// MaxAttachments and MaxAttachmentBytes bound what one submission carries
+	MaxAttachments     = 10
+	MaxAttachmentBytes = 10 << 20
+
+	// maxIssueLogLines is how many recent log lines go into the issue body
+	maxIssueLogLines = 100
+
+	// screenshotDir is where screenshots are uploaded in the repository, as the
+	// feedback modal has always done
+	screenshotDir = "feedback-screenshots"
+)
+
+var (
+	// apiBaseURL is the GitHub API, replaced in tests
+	apiBaseURL = "https://api.github.com"
+
+	client = &http.Client{Timeout: 30 * time.Second}
+
+	unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// Attachment is a file sent with feedback. Data is base64, optionally as a
+// data URL ("data:image/png;base64,...") as produced by a canvas or paste.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Data        string `json:"data"`
+}
+
+// Request is the body for POST /api/feedback
+type Request struct {
+	Title       string       `json:"title,omitempty"` // Defaults to the start of the description
+	Description string       `json:"description"`
+	Token       string       `json:"token,omitempty"` // GitHub token; GITHUB_TOKEN is used when empty
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Result reports whether an issue was filed, and where the bundle was saved
+// if it was not.
+type Result struct {
+	Filed       bool   `json:"filed"`
+	IssueURL    string `json:"issueUrl,omitempty"`
+	IssueNumber int    `json:"issueNumber,omitempty"`
+	BundlePath  string `json:"bundlePath,omitempty"`
+	Error       string `json:"error,omitempty"` // Why filing failed, when a bundle was saved instead
+}
+
+// file is a decoded attachment
+type file struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+func (f file) isImage() bool {
+	return strings.HasPrefix(f.contentType, "image/")
+}
+
+// Submit files feedback as an issue when a token is available, uploading
+// image attachments and including recent logs. Without a token, or when
+// GitHub can't be reached, it saves the feedback, attachments and the
+// diagnostics report as a zip instead.
+func Submit(req Request, report diagnostics.Report, logs []string) (Result, error) {
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Description == "" {
+		return Result{}, errors.New("description is required")
+	}
+	if len(req.Attachments) > MaxAttachments {
+		return Result{}, fmt.Errorf("at most %d attachments are allowed", MaxAttachments)
+	}
+	files := make([]file, 0, len(req.Attachments))
+	for i, a := range req.Attachments {
+		f, err := decode(a, i)
+		if err != nil {
+			return Result{}, err
+		}
+		files = append(files, f)
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		req.Title = defaultTitle(req.Description)
+	}
+
+	token := req.Token
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+
+	var fileErr error
+	if token != "" {
+		result, err := fileIssue(token, req, files, report, logs)
+		if err == nil {
+			return result, nil
+		}
+		fileErr = err
+	} else {
+		fileErr = errors.New("no GitHub token; feedback saved locally")
+	}
+
+	bundlePath, err := saveBundle(req, files, report)
+	if err != nil {
+		return Result{}, fmt.Errorf("%v; saving bundle: %w", fileErr, err)
+	}
+	return Result{BundlePath: bundlePath, Error: fileErr.Error()}, nil
+}
+
+func decode(a Attachment, index int) (file, error) {
+	data := a.Data
+	contentType := a.ContentType
+	if rest, ok := strings.CutPrefix(data, "data:"); ok {
+		meta, payload, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(meta, ";base64") {
+			return file{}, fmt.Errorf("attachment %d: only base64 data URLs are supported", index+1)
+		}
+		if contentType == "" {
+			contentType = strings.TrimSuffix(meta, ";base64")
+		}
+		data = payload
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > MaxAttachmentBytes+2 {
+		return file{}, fmt.Errorf("attachment %d is larger than %d MB", index+1, MaxAttachmentBytes>>20)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return file{}, fmt.Errorf("attachment %d: invalid base64: %w", index+1, err)
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(decoded)
+	}
+
+	name := unsafeName.ReplaceAllString(path.Base(filepath.ToSlash(a.Name)), "-")
+	if name == "" || name == "." || name == "-" {
+		name = fmt.Sprintf("attachment-%d", index+1)
+		if strings.HasPrefix(contentType, "image/") {
+			name += "." + strings.TrimPrefix(strings.SplitN(contentType, ";", 2)[0], "image/")
+		}
+	}
+	return file{name: name, contentType: contentType, data: decoded}, nil
+}
+
+func defaultTitle(description string) string {
+	line, _, _ := strings.Cut(description, "\n")
+	if r := []rune(line); len(r) > 50 {
+		line = string(r[:50]) + "..."
+	}
+	return "Feedback: " + line
+}
+
+// fileIssue uploads images to the repository and creates the issue
+func fileIssue(token string, req Request, files []file, report diagnostics.Report, logs []string) (Result, error) {
+	stamp := time.Now().UTC().Format("20060102-150405")
+	var images []string
+	var others []string
+	for i, f := range files {
+		if !f.isImage() {
+			others = append(others, f.name)
+			continue
+		}
+		url, err := uploadImage(token, fmt.Sprintf("%s/%s-%d-%s", screenshotDir, stamp, i+1, f.name), f.data)
+		if err != nil {
+			return Result{}, err
+		}
+		images = append(images, url)
+	}
+
+	body := issueBody(req.Description, images, others, report, logs)
+	var issue struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := call(token, http.MethodPost, "/repos/"+repo+"/issues", map[string]string{
+		"title": req.Title,
+		"body":  body,
+	}, &issue); err != nil {
+		return Result{}, fmt.Errorf("creating issue: %w", err)
+	}
+	return Result{Filed: true, IssueURL: issue.HTMLURL, IssueNumber: issue.Number}, nil
+}
+
+func uploadImage(token, name string, data []byte) (string, error) {
+	var resp struct {
+		Content struct {
+			DownloadURL string `json:"download_url"`
+		} `json:"content"`
+	}
+	if err := call(token, http.MethodPut, "/repos/"+repo+"/contents/"+name, map[string]string{
+		"message": "Add feedback screenshot " + path.Base(name),
+		"content": base64.StdEncoding.EncodeToString(data),
+	}, &resp); err != nil {
+		return "", fmt.Errorf("uploading %s: %w", path.Base(name), err)
+	}
+	return resp.Content.DownloadURL, nil
+}
+
+func issueBody(description string, images, others []string, report diagnostics.Report, logs []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Description**\n%s\n\n", description)
+	if len(images) > 0 {
+		b.WriteString("**Screenshots**\n")
+		for _, url := range images {
+			fmt.Fprintf(&b, "<img src=%q>\n\n", url)
+		}
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(&b, "**Other attachments** (not uploaded): %s\n\n", strings.Join(others, ", "))
+	}
+
+	b.WriteString("**Environment**\n")
+	summary := diagnostics.Summarize(report)
+	fmt.Fprintf(&b, "- Version: %s\n- OS: %s/%s\n", summary.Version, summary.OS, summary.Arch)
+	if summary.Uptime != "" {
+		fmt.Fprintf(&b, "- Uptime: %s\n", summary.Uptime)
+	}
+	if summary.WSL {
+		b.WriteString("- WSL: yes\n")
+	}
+	fmt.Fprintf(&b, "- Time: %s\n\n", summary.GeneratedAt.UTC().Format(time.RFC3339))
+
+	if len(logs) > maxIssueLogLines {
+		logs = logs[len(logs)-maxIssueLogLines:]
+	}
+	if len(logs) > 0 {
+		b.WriteString("<details>\n<summary>Server Logs</summary>\n\n```\n")
+		for _, line := range logs {
+			// Keep the code fence intact whatever a log line contains
+			b.WriteString(strings.ReplaceAll(diagnostics.RedactString(line), "```", "'''"))
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n</details>\n")
+	}
+	return b.String()
+}
+
+// call makes a GitHub API request, decoding the JSON response into out
+func call(token, method, endpoint string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, apiBaseURL+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("GitHub returned %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// saveBundle writes the feedback, attachments and diagnostics to a zip in the
+// feedback directory
+func saveBundle(req Request, files []file, report diagnostics.Report) (string, error) {
+	if report.Extra == nil {
+		report.Extra = make(map[string]interface{})
+	}
+	report.Extra["feedback"] = map[string]string{
+		"title":       req.Title,
+		"description": req.Description,
+	}
+	report.Files = make(map[string][]byte, len(files))
+	for i, f := range files {
+		name := "attachments/" + f.name
+		if _, dup := report.Files[name]; dup {
+			name = fmt.Sprintf("attachments/%d-%s", i+1, f.name)
+		}
+		report.Files[name] = f.data
+	}
+
+	var buf bytes.Buffer
+	if err := diagnostics.WriteBundle(&buf, report); err != nil {
+		return "", err
+	}
+	dir := storage.GetFeedbackDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("feedback-%s.zip", time.Now().Format("20060102-150405.000")))
+	if err := storage.WriteFileAtomic(name, buf.Bytes(), 0600); err != nil {
+		return "", err
+	}
+	return name, nil
+}
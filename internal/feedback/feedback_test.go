@@ -0,0 +1,184 @@
+package feedback
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/diagnostics"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// pngHeader is enough for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+func withBundleDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	t.Setenv("GITHUB_TOKEN", "")
+	return storage.GetFeedbackDir()
+}
+
+// fakeGitHub records issue bodies and uploaded paths
+func fakeGitHub(t *testing.T, issueStatus int) (*[]string, *string) {
+	t.Helper()
+	var mu sync.Mutex
+	var uploads []string
+	var issueBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("Authorization") != "token secret" {
+			t.Errorf("Expected token auth, got %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/repos/"+repo+"/contents/"+screenshotDir+"/"):
+			uploads = append(uploads, r.URL.Path)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"content": map[string]string{"download_url": "https://raw.example/" + r.URL.Path},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/"+repo+"/issues":
+			if issueStatus != http.StatusCreated {
+				w.WriteHeader(issueStatus)
+				json.NewEncoder(w).Encode(map[string]string{"message": "Bad credentials"})
+				return
+			}
+			issueBody = body["title"] + "\n" + body["body"]
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"number": 42, "html_url": "https://github.com/" + repo + "/issues/42"})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	original := apiBaseURL
+	apiBaseURL = server.URL
+	t.Cleanup(func() { apiBaseURL = original })
+	return &uploads, &issueBody
+}
+
+func TestSubmitFilesIssue(t *testing.T) {
+	dir := withBundleDir(t)
+	uploads, issueBody := fakeGitHub(t, http.StatusCreated)
+
+	req := Request{
+		Description: "Tabs freeze after resume\nSteps: ...",
+		Token:       "secret",
+		Attachments: []Attachment{
+			{Name: "shot.png", Data: "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngHeader)},
+			{Name: "../notes.txt", Data: base64.StdEncoding.EncodeToString([]byte("notes"))},
+		},
+	}
+	result, err := Submit(req, diagnostics.Report{Version: "1.2.3"}, []string{"2025/01/01 12:00:00 INFO [Forge] started"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if !result.Filed || result.IssueNumber != 42 || result.BundlePath != "" {
+		t.Errorf("Expected issue 42 with no bundle, got %+v", result)
+	}
+	if len(*uploads) != 1 || !strings.HasSuffix((*uploads)[0], "-1-shot.png") {
+		t.Errorf("Expected only the image to be uploaded, got %v", *uploads)
+	}
+	for _, want := range []string{"Feedback: Tabs freeze after resume", "<img src=", "notes.txt", "Version: 1.2.3", "[Forge] started"} {
+		if !strings.Contains(*issueBody, want) {
+			t.Errorf("Expected issue to contain %q, got:\n%s", want, *issueBody)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no bundle when filed, got %v", entries)
+	}
+}
+
+func TestSubmitSavesBundleWhenFilingFails(t *testing.T) {
+	dir := withBundleDir(t)
+	fakeGitHub(t, http.StatusUnauthorized)
+
+	req := Request{Description: "It broke", Token: "secret", Attachments: []Attachment{
+		{Name: "log.txt", Data: base64.StdEncoding.EncodeToString([]byte("one"))},
+		{Name: "log.txt", Data: base64.StdEncoding.EncodeToString([]byte("two"))},
+	}}
+	result, err := Submit(req, diagnostics.Report{Version: "1.2.3"}, nil)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if result.Filed || !strings.Contains(result.Error, "Bad credentials") {
+		t.Errorf("Expected the GitHub error to be reported, got %+v", result)
+	}
+	if !strings.HasPrefix(result.BundlePath, dir) {
+		t.Fatalf("Expected a bundle in %s, got %q", dir, result.BundlePath)
+	}
+
+	zr, err := zip.OpenReader(result.BundlePath)
+	if err != nil {
+		t.Fatalf("Failed to open bundle: %v", err)
+	}
+	defer zr.Close()
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{"summary.json", "feedback.json", "attachments/log.txt", "attachments/2-log.txt"} {
+		if !names[want] {
+			t.Errorf("Expected %s in bundle, got %v", want, names)
+		}
+	}
+}
+
+func TestSubmitWithoutToken(t *testing.T) {
+	withBundleDir(t)
+	result, err := Submit(Request{Description: "Offline"}, diagnostics.Report{}, nil)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if result.Filed || result.BundlePath == "" {
+		t.Errorf("Expected a local bundle, got %+v", result)
+	}
+}
+
+func TestSubmitValidation(t *testing.T) {
+	withBundleDir(t)
+	cases := map[string]Request{
+		"empty":     {Description: "  "},
+		"bad data":  {Description: "x", Attachments: []Attachment{{Data: "!!!"}}},
+		"bad url":   {Description: "x", Attachments: []Attachment{{Data: "data:text/plain,hello"}}},
+		"too many":  {Description: "x", Attachments: make([]Attachment, MaxAttachments+1)},
+		"too large": {Description: "x", Attachments: []Attachment{{Data: strings.Repeat("A", MaxAttachmentBytes/3*4+8)}}},
+	}
+	for name, req := range cases {
+		if _, err := Submit(req, diagnostics.Report{}, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	withBundleDir(t)
+	handler := Handler(func() diagnostics.Report { return diagnostics.Report{Version: "1.2.3"} })
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/api/v1/feedback", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("POST", "/api/v1/feedback", strings.NewReader(`{"description":"hello"}`)))
+	var result Result
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected a JSON result, got %d %v", rec.Code, err)
+	}
+	if result.BundlePath == "" {
+		t.Errorf("Expected a saved bundle, got %+v", result)
+	}
+}
@@ -0,0 +1,60 @@
+package feedback
+
+import (
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/diagnostics"
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+)
+
+// maxRequestBytes allows every attachment at full size, base64-encoded
+const maxRequestBytes = MaxAttachments*MaxAttachmentBytes*4/3 + 1<<20
+
+// Handler serves POST /api/feedback. report gathers the diagnostics
+// included with the feedback; recent server logs come from the log buffer.
+func Handler(report func() diagnostics.Report) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := Submit(req, report(), recentLogs())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if result.Filed {
+			log.Printf("[Feedback] Filed issue #%d", result.IssueNumber)
+		} else {
+			log.Printf("[Feedback] Saved bundle %s: %s", result.BundlePath, result.Error)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// recentLogs renders the newest buffered log entries as text lines
+func recentLogs() []string {
+	entries := logging.Buffer().Query(slog.LevelInfo, time.Time{}, maxIssueLogLines)
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := e.Time.Format("2006/01/02 15:04:05") + " " + e.Level
+		if e.Component != "" {
+			line += " [" + e.Component + "]"
+		}
+		lines = append(lines, line+" "+e.Message)
+	}
+	return lines
+}
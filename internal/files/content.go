@@ -0,0 +1,160 @@
+package files
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Content types reported to the editor
+const (
+	ContentTypeText   = "text"
+	ContentTypeBinary = "binary"
+	ContentTypeImage  = "image"
+)
+
+// sniffLen is how many leading bytes are inspected for binary detection
+const sniffLen = 8000
+
+// ContentInfo describes what kind of data a file holds and how it is encoded.
+type ContentInfo struct {
+	Type     string `json:"type"`               // "text", "binary", or "image"
+	Encoding string `json:"encoding,omitempty"` // Source encoding for text files
+	MimeType string `json:"mimeType"`
+}
+
+// imageExtensions maps previewable image extensions to MIME types.
+var imageExtensions = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".ico":  "image/x-icon",
+}
+
+// textImageExtensions are images stored as text. They are edited like any
+// other text file, and reported with their image MIME type for previews.
+var textImageExtensions = map[string]string{
+	".svg": "image/svg+xml",
+}
+
+// detectContent classifies file data as text, image, or binary and reports the text encoding.
+func detectContent(path string, data []byte) ContentInfo {
+	ext := strings.ToLower(filepath.Ext(path))
+	if mime, ok := imageExtensions[ext]; ok {
+		return ContentInfo{Type: ContentTypeImage, MimeType: mime}
+	}
+	info := detectData(data)
+	if mime, ok := textImageExtensions[ext]; ok && info.Type == ContentTypeText {
+		info.MimeType = mime
+	}
+	return info
+}
+
+// detectData classifies file data by its content alone.
+func detectData(data []byte) ContentInfo {
+	sniffed := http.DetectContentType(data)
+	if strings.HasPrefix(sniffed, "image/") {
+		return ContentInfo{Type: ContentTypeImage, MimeType: sniffed}
+	}
+
+	// BOMs identify text encodings unambiguously (UTF-16 contains NULs, so check first)
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return ContentInfo{Type: ContentTypeText, Encoding: "utf-8-bom", MimeType: "text/plain; charset=utf-8"}
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return ContentInfo{Type: ContentTypeText, Encoding: "utf-16le", MimeType: "text/plain; charset=utf-16le"}
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return ContentInfo{Type: ContentTypeText, Encoding: "utf-16be", MimeType: "text/plain; charset=utf-16be"}
+	}
+
+	head := data
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return ContentInfo{Type: ContentTypeBinary, MimeType: "application/octet-stream"}
+	}
+
+	if utf8.Valid(data) {
+		return ContentInfo{Type: ContentTypeText, Encoding: "utf-8", MimeType: sniffed}
+	}
+
+	// No NULs but invalid UTF-8: almost always a legacy single-byte code page
+	return ContentInfo{Type: ContentTypeText, Encoding: "latin-1", MimeType: "text/plain"}
+}
+
+// decodeText converts file data in the given encoding to a UTF-8 string.
+func decodeText(data []byte, encoding string) string {
+	switch encoding {
+	case "utf-8-bom":
+		return string(data[3:])
+	case "utf-16le", "utf-16be":
+		return decodeUTF16(data[2:], encoding == "utf-16be")
+	case "latin-1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(data)
+	}
+}
+
+// encodeText converts text to the given encoding, BOM included, so a file
+// read with decodeText is written back as it was. Text latin-1 can't hold is
+// an error.
+func encodeText(text, encoding string) ([]byte, error) {
+	switch encoding {
+	case "utf-8-bom":
+		return append([]byte{0xEF, 0xBB, 0xBF}, text...), nil
+	case "utf-16le", "utf-16be":
+		bigEndian := encoding == "utf-16be"
+		units := utf16.Encode([]rune(text))
+		out := make([]byte, 0, 2+2*len(units))
+		if bigEndian {
+			out = append(out, 0xFE, 0xFF)
+		} else {
+			out = append(out, 0xFF, 0xFE)
+		}
+		for _, u := range units {
+			if bigEndian {
+				out = append(out, byte(u>>8), byte(u))
+			} else {
+				out = append(out, byte(u), byte(u>>8))
+			}
+		}
+		return out, nil
+	case "latin-1":
+		out := make([]byte, 0, len(text))
+		for _, r := range text {
+			if r > 0xFF {
+				return nil, fmt.Errorf("%q can't be saved in the file's latin-1 encoding", r)
+			}
+			out = append(out, byte(r))
+		}
+		return out, nil
+	default:
+		return []byte(text), nil
+	}
+}
+
+// decodeUTF16 decodes UTF-16 bytes (without BOM) to a UTF-8 string.
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,82 @@
+package files
+
+import (
+	"testing"
+)
+
+func TestDetectContent(t *testing.T) {
+	pngHeader := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d}
+
+	tests := []struct {
+		name     string
+		path     string
+		data     []byte
+		wantType string
+		wantEnc  string
+	}{
+		{"utf-8 text", "main.go", []byte("package main\n// héllo\n"), ContentTypeText, "utf-8"},
+		{"utf-8 with BOM", "notes.txt", []byte("\xEF\xBB\xBFhello"), ContentTypeText, "utf-8-bom"},
+		{"utf-16le with BOM", "win.txt", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, ContentTypeText, "utf-16le"},
+		{"utf-16be with BOM", "mac.txt", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, ContentTypeText, "utf-16be"},
+		{"latin-1 text", "legacy.txt", []byte("caf\xe9\n"), ContentTypeText, "latin-1"},
+		{"binary with NUL", "app.exe", []byte{'M', 'Z', 0x90, 0x00, 0x03}, ContentTypeBinary, ""},
+		{"image by extension", "logo.png", []byte("not really"), ContentTypeImage, ""},
+		{"svg is text", "logo.svg", []byte("<svg></svg>"), ContentTypeText, "utf-8"},
+		{"image by sniffing", "logo.dat", pngHeader, ContentTypeImage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := detectContent(tt.path, tt.data)
+			if info.Type != tt.wantType {
+				t.Errorf("Expected type %s, got %s", tt.wantType, info.Type)
+			}
+			if info.Encoding != tt.wantEnc {
+				t.Errorf("Expected encoding %q, got %q", tt.wantEnc, info.Encoding)
+			}
+		})
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     string
+	}{
+		{"utf-8", []byte("hello"), "utf-8", "hello"},
+		{"utf-8 BOM stripped", []byte("\xEF\xBB\xBFhello"), "utf-8-bom", "hello"},
+		{"utf-16le", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, "utf-16le", "hi"},
+		{"utf-16be", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, "utf-16be", "hi"},
+		{"latin-1", []byte("caf\xe9"), "latin-1", "café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeText(tt.data, tt.encoding); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	for _, enc := range []string{"utf-8", "utf-8-bom", "utf-16le", "utf-16be", "latin-1"} {
+		t.Run(enc, func(t *testing.T) {
+			data, err := encodeText("café ok", enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info := detectContent("notes.txt", data); info.Encoding != enc {
+				t.Errorf("Expected the encoding kept, detected %q", info.Encoding)
+			}
+			if got := decodeText(data, enc); got != "café ok" {
+				t.Errorf("Round trip gave %q", got)
+			}
+		})
+	}
+	if _, err := encodeText("日本", "latin-1"); err == nil {
+		t.Error("Expected an error for text latin-1 can't hold")
+	}
+}
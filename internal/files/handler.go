@@ -1,6 +1,7 @@
 package files
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"log"
@@ -13,6 +14,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/audit"
+	"github.com/mikejsmith1985/forge-terminal/internal/gitignore"
 )
 
 // FileAccessMode represents the file access security level
@@ -197,6 +201,15 @@ func resolveWSLHome(distro, remainder string) (string, error) {
 // isPathWithinRoot checks if targetPath is within rootPath
 // Handles cross-platform paths including WSL on Windows
 func isPathWithinRoot(targetPath, rootPath string) (bool, error) {
+	// Server-side allowlist applies in every mode since rootPath is client-supplied
+	allowTarget, err := resolvePath(targetPath)
+	if err != nil {
+		allowTarget = targetPath
+	}
+	if !isPathAllowed(allowTarget) {
+		return false, nil
+	}
+
 	// Check if unrestricted mode is enabled
 	if getFileAccessMode() {
 		log.Printf("[Files] Unrestricted mode: allowing access to %s", targetPath)
@@ -246,16 +259,16 @@ func isPathWithinRoot(targetPath, rootPath string) (bool, error) {
 		absRoot = filepath.Clean(rootPath)
 	}
 
+	// Resolve symlinks so a link inside the root can't point outside it
+	absTarget = resolveSymlinks(absTarget)
+	absRoot = resolveSymlinks(absRoot)
+
 	// Normalize paths for comparison (lowercase on Windows for case-insensitivity)
 	if runtime.GOOS == "windows" {
 		absTarget = strings.ToLower(absTarget)
 		absRoot = strings.ToLower(absRoot)
 	}
 
-	// Clean paths
-	absTarget = filepath.Clean(absTarget)
-	absRoot = filepath.Clean(absRoot)
-
 	// Check if target is root itself or within root
 	if absTarget == absRoot {
 		return true, nil
@@ -283,8 +296,9 @@ type FileNode struct {
 }
 
 type FileReadRequest struct {
-	Path     string `json:"path"`
-	RootPath string `json:"rootPath"`
+	Path      string `json:"path"`
+	RootPath  string `json:"rootPath"`
+	ImageData bool   `json:"imageData,omitempty"` // Return images as base64 for preview
 }
 
 type FileWriteRequest struct {
@@ -294,8 +308,9 @@ type FileWriteRequest struct {
 }
 
 type FileDeleteRequest struct {
-	Path     string `json:"path"`
-	RootPath string `json:"rootPath"`
+	Path      string `json:"path"`
+	RootPath  string `json:"rootPath"`
+	Permanent bool   `json:"permanent,omitempty"` // Skip the trash and delete immediately
 }
 
 // HandleList returns directory tree structure
@@ -335,7 +350,12 @@ func HandleList(w http.ResponseWriter, r *http.Request) {
 	// Log for debugging
 	if shellType == "wsl" {
 		// For WSL, skip the "within root" check since paths may not be directly comparable
-		// and we trust the frontend to send valid paths from the current terminal directory
+		// and we trust the frontend to send valid paths from the current terminal directory.
+		// The server-side allowlist still applies.
+		if !isPathAllowed(absPath) {
+			http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+			return
+		}
 	} else {
 		// Validate path is within root if rootPath is specified
 		within, err := isPathWithinRoot(absPath, absRootPath)
@@ -356,8 +376,7 @@ func HandleList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gitignorePatterns := loadGitignorePatterns(absPath)
-	tree := buildFileTree(absPath, gitignorePatterns, 0, 3)
+	tree := buildFileTree(absPath, gitignore.New(absPath), 0, 3)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tree)
@@ -439,11 +458,33 @@ func HandleRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Detect binary/image content and legacy encodings so the editor never shows mojibake
+	contentInfo := detectContent(absPath, content)
+	resp := map[string]interface{}{
+		"path":     req.Path,
+		"content":  "",
+		"type":     contentInfo.Type,
+		"mimeType": contentInfo.MimeType,
+		"size":     info.Size(),
+	}
+
+	switch contentInfo.Type {
+	case ContentTypeText:
+		resp["content"] = decodeText(content, contentInfo.Encoding)
+		resp["encoding"] = contentInfo.Encoding
+		if req.ImageData && strings.HasPrefix(contentInfo.MimeType, "image/") {
+			resp["data"] = base64.StdEncoding.EncodeToString(content)
+		}
+	case ContentTypeImage:
+		if req.ImageData {
+			resp["data"] = base64.StdEncoding.EncodeToString(content)
+		}
+	}
+
+	log.Printf("[Files] Read %s: type=%s encoding=%s", absPath, contentInfo.Type, contentInfo.Encoding)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"path":    req.Path,
-		"content": string(content),
-	})
+	json.NewEncoder(w).Encode(resp)
 }
 
 // HandleWrite saves file contents
@@ -489,13 +530,28 @@ func HandleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Existing files are written back in their own encoding. Binary files and
+	// images are read without content, so saving one would erase it.
+	data := []byte(req.Content)
+	if existing, err := os.ReadFile(absPath); err == nil {
+		info := detectContent(absPath, existing)
+		if info.Type != ContentTypeText {
+			http.Error(w, "Can't save over a "+info.Type+" file", http.StatusConflict)
+			return
+		}
+		if data, err = encodeText(req.Content, info.Encoding); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	dir := filepath.Dir(absPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		http.Error(w, "Failed to create directory", http.StatusInternalServerError)
 		return
 	}
 
-	if err := os.WriteFile(absPath, []byte(req.Content), 0644); err != nil {
+	if err := os.WriteFile(absPath, data, 0644); err != nil {
 		http.Error(w, "Failed to write file", http.StatusInternalServerError)
 		return
 	}
@@ -507,7 +563,7 @@ func HandleWrite(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleDelete removes a file or directory
+// HandleDelete moves a file or directory to the trash (or removes it if permanent is set)
 func HandleDelete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -550,19 +606,39 @@ func HandleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := os.RemoveAll(absPath); err != nil {
+	if req.Permanent {
+		err := os.RemoveAll(absPath)
+		audit.RecordResult(audit.Event{Action: audit.FileDelete, Target: absPath, Detail: "deleted permanently"}, err)
+		if err != nil {
+			http.Error(w, "Failed to delete", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status": "success",
+			"path":   req.Path,
+		})
+		return
+	}
+
+	entry, err := MoveToTrash(absPath)
+	audit.RecordResult(audit.Event{Action: audit.FileDelete, Target: absPath, Detail: "moved to the trash"}, err)
+	if err != nil {
+		log.Printf("[Files] Failed to move %s to trash: %v", absPath, err)
 		http.Error(w, "Failed to delete", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
-		"status": "success",
-		"path":   req.Path,
+		"status":  "success",
+		"path":    req.Path,
+		"trashId": entry.ID,
 	})
 }
 
-func buildFileTree(dirPath string, gitignorePatterns []string, depth, maxDepth int) *FileNode {
+func buildFileTree(dirPath string, ignore *gitignore.Matcher, depth, maxDepth int) *FileNode {
 	info, err := os.Stat(dirPath)
 	if err != nil {
 		return nil
@@ -574,7 +650,7 @@ func buildFileTree(dirPath string, gitignorePatterns []string, depth, maxDepth i
 		IsDir:        info.IsDir(),
 		Size:         info.Size(),
 		ModTime:      info.ModTime().Unix(),
-		IsGitIgnored: matchesGitignore(dirPath, gitignorePatterns),
+		IsGitIgnored: ignore.Match(dirPath, info.IsDir()),
 	}
 
 	if !info.IsDir() || depth >= maxDepth {
@@ -588,7 +664,7 @@ func buildFileTree(dirPath string, gitignorePatterns []string, depth, maxDepth i
 
 	for _, entry := range entries {
 		childPath := filepath.Join(dirPath, entry.Name())
-		child := buildFileTree(childPath, gitignorePatterns, depth+1, maxDepth)
+		child := buildFileTree(childPath, ignore, depth+1, maxDepth)
 		if child != nil {
 			node.Children = append(node.Children, child)
 		}
@@ -604,38 +680,9 @@ func buildFileTree(dirPath string, gitignorePatterns []string, depth, maxDepth i
 	return node
 }
 
-func loadGitignorePatterns(dirPath string) []string {
-	gitignorePath := filepath.Join(dirPath, ".gitignore")
-	data, err := os.ReadFile(gitignorePath)
-	if err != nil {
-		return []string{}
-	}
-
-	lines := strings.Split(string(data), "\n")
-	patterns := []string{}
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" && !strings.HasPrefix(line, "#") {
-			patterns = append(patterns, line)
-		}
-	}
-	return patterns
-}
-
-func matchesGitignore(path string, patterns []string) bool {
-	basename := filepath.Base(path)
-	for _, pattern := range patterns {
-		if matched, _ := filepath.Match(pattern, basename); matched {
-			return true
-		}
-		if strings.HasPrefix(basename, strings.TrimSuffix(pattern, "*")) {
-			return true
-		}
-	}
-	return false
-}
-
-// HandleReadStream returns file contents as a stream for large files
+// HandleReadStream returns file contents as a stream for large files.
+// Supports byte ranges via ?offset=&limit= and tailing via ?tail=N; with
+// &follow=true the tail is streamed as Server-Sent Events as the file grows.
 func HandleReadStream(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -673,11 +720,61 @@ func HandleReadStream(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	info, _ := file.Stat()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Failed to stat file", http.StatusInternalServerError)
+		return
+	}
+	size := info.Size()
+
+	query := r.URL.Query()
+	offset, _ := strconv.ParseInt(query.Get("offset"), 10, 64)
+	limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+	if offset < 0 || limit < 0 {
+		http.Error(w, "offset and limit must be non-negative", http.StatusBadRequest)
+		return
+	}
+
+	// Tail mode: start from the last N lines instead of an explicit offset.
+	// Following without either starts near the end of the file.
+	follow := query.Get("follow") == "true"
+	tailParam := query.Get("tail")
+	if tailParam == "" && follow && !query.Has("offset") {
+		tailParam = strconv.Itoa(followDefaultLines)
+	}
+	if tailParam != "" {
+		lines, err := strconv.Atoi(tailParam)
+		if err != nil || lines < 0 {
+			http.Error(w, "Invalid tail parameter", http.StatusBadRequest)
+			return
+		}
+		offset, err = tailOffset(file, size, lines)
+		if err != nil {
+			http.Error(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if follow {
+		log.Printf("[Files] Following %s from offset %d", absPath, offset)
+		streamFollow(w, r, file, offset)
+		return
+	}
+
+	if offset > size {
+		offset = size
+	}
+	length := size - offset
+	if limit > 0 && limit < length {
+		length = limit
+	}
+
 	w.Header().Set("Content-Type", "text/plain")
-	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	w.Header().Set("X-File-Size", strconv.FormatInt(size, 10))
+	w.Header().Set("X-Range-Offset", strconv.FormatInt(offset, 10))
 
-	io.Copy(w, file)
+	io.Copy(w, io.NewSectionReader(file, offset, length))
 }
 
 
@@ -723,6 +820,9 @@ func HandleStats(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
 			return
 		}
+	} else if !isPathAllowed(absPath) {
+		http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+		return
 	}
 
 	// Check if path exists and is a directory
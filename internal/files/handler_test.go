@@ -1,6 +1,12 @@
 package files
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -57,3 +63,53 @@ func TestIsPathWithinRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWrite_KeepsEncoding(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) int {
+		t.Helper()
+		body, _ := json.Marshal(FileWriteRequest{Path: filepath.Join(dir, name), Content: content, RootPath: dir})
+		rec := httptest.NewRecorder()
+		HandleWrite(rec, httptest.NewRequest("POST", "/api/files/write", bytes.NewReader(body)))
+		return rec.Code
+	}
+	files := map[string][]byte{
+		"bom.txt":    []byte("\xEF\xBB\xBFold"),
+		"win.txt":    {0xFF, 0xFE, 'o', 0, 'l', 0, 'd', 0},
+		"legacy.txt": []byte("caf\xe9"),
+		"app.exe":    {'M', 'Z', 0x90, 0x00, 0x03},
+		"logo.png":   {0x89, 'P', 'N', 'G'},
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string][]byte{
+		"bom.txt":    []byte("\xEF\xBB\xBFné"),
+		"win.txt":    {0xFF, 0xFE, 'n', 0, 0xe9, 0},
+		"legacy.txt": []byte("n\xe9"),
+		"new.txt":    []byte("né"),
+	}
+	for name, data := range want {
+		if code := write(name, "né"); code != http.StatusOK {
+			t.Errorf("%s: expected the write to succeed, got %d", name, code)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, name)); !bytes.Equal(got, data) {
+			t.Errorf("%s: expected % x, got % x", name, data, got)
+		}
+	}
+
+	if code := write("legacy.txt", "日本"); code != http.StatusBadRequest {
+		t.Errorf("Expected text latin-1 can't hold refused, got %d", code)
+	}
+	for _, name := range []string{"app.exe", "logo.png"} {
+		if code := write(name, ""); code != http.StatusConflict {
+			t.Errorf("%s: expected the write refused, got %d", name, code)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dir, name)); !bytes.Equal(got, files[name]) {
+			t.Errorf("%s: expected the file unchanged", name)
+		}
+	}
+}
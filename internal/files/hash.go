@@ -0,0 +1,163 @@
+package files
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HashVerifyRequest is the body for POST /api/files/hash (bulk verification)
+type HashVerifyRequest struct {
+	Dir      string            `json:"dir"`
+	RootPath string            `json:"rootPath"`
+	Manifest map[string]string `json:"manifest"` // Slash-separated relative path -> expected SHA-256 hex
+}
+
+// HashVerifyResult reports how a directory compares to a manifest
+type HashVerifyResult struct {
+	OK         bool     `json:"ok"`
+	Matched    []string `json:"matched"`
+	Mismatched []string `json:"mismatched"`
+	Missing    []string `json:"missing"`
+}
+
+// HashFile returns the hex-encoded SHA-256 of a file, streaming its contents.
+func HashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// VerifyManifest hashes every file listed in manifest relative to dir. Entries
+// outside rootPath, including through a symlink, are reported missing.
+func VerifyManifest(dir, rootPath string, manifest map[string]string) *HashVerifyResult {
+	result := &HashVerifyResult{
+		Matched:    []string{},
+		Mismatched: []string{},
+		Missing:    []string{},
+	}
+
+	for rel, expected := range manifest {
+		clean := filepath.Clean(filepath.FromSlash(rel))
+		// Manifest entries must stay inside dir
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			result.Missing = append(result.Missing, rel)
+			continue
+		}
+
+		path := filepath.Join(dir, clean)
+		if within, err := isPathWithinRoot(path, rootPath); err != nil || !within {
+			result.Missing = append(result.Missing, rel)
+			continue
+		}
+
+		sum, _, err := HashFile(path)
+		switch {
+		case err != nil:
+			result.Missing = append(result.Missing, rel)
+		case strings.EqualFold(sum, expected):
+			result.Matched = append(result.Matched, rel)
+		default:
+			result.Mismatched = append(result.Mismatched, rel)
+		}
+	}
+
+	sort.Strings(result.Matched)
+	sort.Strings(result.Mismatched)
+	sort.Strings(result.Missing)
+	result.OK = len(result.Mismatched) == 0 && len(result.Missing) == 0
+	return result
+}
+
+// HandleHash returns the SHA-256 of ?path= (GET) or verifies a directory against a manifest (POST)
+func HandleHash(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		filePath := r.URL.Query().Get("path")
+		if filePath == "" {
+			http.Error(w, "Missing path parameter", http.StatusBadRequest)
+			return
+		}
+		rootPath := r.URL.Query().Get("rootPath")
+		if rootPath == "" {
+			rootPath = "."
+		}
+
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		within, err := isPathWithinRoot(absPath, rootPath)
+		if err != nil || !within {
+			http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+			return
+		}
+
+		if info, err := os.Stat(absPath); err != nil || info.IsDir() {
+			http.Error(w, "File not found", http.StatusNotFound)
+			return
+		}
+
+		sum, size, err := HashFile(absPath)
+		if err != nil {
+			http.Error(w, "Failed to hash file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"path":      filePath,
+			"algorithm": "sha256",
+			"hash":      sum,
+			"size":      size,
+		})
+
+	case http.MethodPost:
+		var req HashVerifyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.Dir == "" || len(req.Manifest) == 0 {
+			http.Error(w, "dir and manifest are required", http.StatusBadRequest)
+			return
+		}
+		rootPath := req.RootPath
+		if rootPath == "" {
+			rootPath = "."
+		}
+
+		absDir, err := filepath.Abs(req.Dir)
+		if err != nil {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		within, err := isPathWithinRoot(absDir, rootPath)
+		if err != nil || !within {
+			http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VerifyManifest(absDir, rootPath, req.Manifest))
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,55 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	os.WriteFile(path, []byte("hello\n"), 0644)
+
+	sum, size, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("HashFile failed: %v", err)
+	}
+	expected := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	if sum != expected || size != 6 {
+		t.Errorf("Expected %s (6 bytes), got %s (%d bytes)", expected, sum, size)
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "hello.txt"), []byte("hello\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "changed.txt"), []byte("changed"), 0644)
+
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	os.WriteFile(outside, []byte("hello\n"), 0644)
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("Symlinks unavailable: %v", err)
+	}
+
+	result := VerifyManifest(dir, dir, map[string]string{
+		"sub/hello.txt":  "5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03",
+		"changed.txt":    "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		"missing.txt":    "00",
+		"../outside.txt": "00",
+		"link.txt":       "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	})
+
+	if result.OK {
+		t.Error("Expected verification to fail")
+	}
+	if len(result.Matched) != 1 || result.Matched[0] != "sub/hello.txt" {
+		t.Errorf("Unexpected matched: %v", result.Matched)
+	}
+	if len(result.Mismatched) != 1 || result.Mismatched[0] != "changed.txt" {
+		t.Errorf("Unexpected mismatched: %v", result.Mismatched)
+	}
+	if len(result.Missing) != 3 {
+		t.Errorf("Expected escaping entries to be reported missing, got %v", result.Missing)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package files
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the owning user and group names, falling back to numeric IDs.
+func fileOwner(info os.FileInfo) (string, string) {
+	sys, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+
+	uid := strconv.FormatUint(uint64(sys.Uid), 10)
+	gid := strconv.FormatUint(uint64(sys.Gid), 10)
+
+	owner := uid
+	if u, err := user.LookupId(uid); err == nil {
+		owner = u.Username
+	}
+	group := gid
+	if g, err := user.LookupGroupId(gid); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
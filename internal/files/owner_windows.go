@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package files
+
+import "os"
+
+// fileOwner is not implemented on Windows, where ownership is ACL-based.
+func fileOwner(info os.FileInfo) (string, string) {
+	return "", ""
+}
@@ -0,0 +1,104 @@
+package files
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	allowedRoots      []string // Symlink-resolved roots; empty means no server-side restriction
+	allowedRootsMutex sync.RWMutex
+)
+
+// SetAllowedRoots configures the server-side allowlist of directories file
+// endpoints may touch, regardless of the rootPath a client supplies.
+func SetAllowedRoots(roots []string) {
+	resolved := make([]string, 0, len(roots))
+	for _, root := range roots {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			continue
+		}
+		abs, err := resolvePath(root)
+		if err != nil {
+			log.Printf("[Files] Ignoring invalid allowed root %q: %v", root, err)
+			continue
+		}
+		resolved = append(resolved, resolveSymlinks(abs))
+	}
+
+	allowedRootsMutex.Lock()
+	allowedRoots = resolved
+	allowedRootsMutex.Unlock()
+
+	log.Printf("[Files] Allowed roots: %v", resolved)
+}
+
+// GetAllowedRoots returns the configured allowlist (empty if unrestricted).
+func GetAllowedRoots() []string {
+	allowedRootsMutex.RLock()
+	defer allowedRootsMutex.RUnlock()
+	return append([]string(nil), allowedRoots...)
+}
+
+// PathAllowed reports whether absPath is inside the allowed roots, for other
+// packages that open files on the user's behalf.
+func PathAllowed(absPath string) bool {
+	return isPathAllowed(absPath)
+}
+
+// isPathAllowed reports whether absPath falls inside one of the configured
+// allowed roots after resolving symlinks. Always true when no roots are configured.
+func isPathAllowed(absPath string) bool {
+	roots := GetAllowedRoots()
+	if len(roots) == 0 {
+		return true
+	}
+
+	target := resolveSymlinks(absPath)
+	for _, root := range roots {
+		if pathHasPrefix(target, root) {
+			return true
+		}
+	}
+	log.Printf("[Files] Path outside allowed roots: %s", target)
+	return false
+}
+
+// resolveSymlinks resolves symlinks in p. Paths that don't exist yet (e.g. a file
+// about to be written) are resolved via their nearest existing ancestor so a
+// symlinked parent directory can't be used to escape the root.
+func resolveSymlinks(p string) string {
+	p = filepath.Clean(p)
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved
+	}
+
+	parent := filepath.Dir(p)
+	if parent == p {
+		return p
+	}
+	return filepath.Join(resolveSymlinks(parent), filepath.Base(p))
+}
+
+// pathHasPrefix reports whether target equals root or lies beneath it.
+func pathHasPrefix(target, root string) bool {
+	target = filepath.Clean(target)
+	root = filepath.Clean(root)
+	if runtime.GOOS == "windows" {
+		target = strings.ToLower(target)
+		root = strings.ToLower(root)
+	}
+
+	if target == root {
+		return true
+	}
+	if !strings.HasSuffix(root, string(os.PathSeparator)) {
+		root += string(os.PathSeparator)
+	}
+	return strings.HasPrefix(target, root)
+}
@@ -0,0 +1,80 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestIsPathWithinRoot_SymlinkEscape(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Symlink creation requires elevated privileges on Windows")
+	}
+
+	SetFileAccessMode(FileAccessRestricted)
+	defer SetFileAccessMode(FileAccessRestricted)
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		expected bool
+	}{
+		{"existing file through symlink", filepath.Join(root, "escape", "secret.txt"), false},
+		{"new file through symlink", filepath.Join(root, "escape", "new.txt"), false},
+		{"regular file in root", filepath.Join(root, "file.txt"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := isPathWithinRoot(tt.target, root)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("Expected allowed=%v for %s, got %v", tt.expected, tt.target, allowed)
+			}
+		})
+	}
+}
+
+func TestAllowedRoots(t *testing.T) {
+	allowed := t.TempDir()
+	other := t.TempDir()
+
+	SetAllowedRoots([]string{allowed, ""})
+	defer SetAllowedRoots(nil)
+
+	if !isPathAllowed(filepath.Join(allowed, "sub", "file.txt")) {
+		t.Error("Expected path under allowed root to be permitted")
+	}
+	if isPathAllowed(filepath.Join(other, "file.txt")) {
+		t.Error("Expected path outside allowed roots to be denied")
+	}
+
+	// Client-supplied rootPath can't widen access beyond the allowlist, even in unrestricted mode
+	SetFileAccessMode(FileAccessUnrestricted)
+	defer SetFileAccessMode(FileAccessRestricted)
+
+	if ok, _ := isPathWithinRoot(filepath.Join(other, "file.txt"), "/"); ok {
+		t.Error("Expected allowlist to override client rootPath")
+	}
+	if ok, _ := isPathWithinRoot(filepath.Join(allowed, "file.txt"), "/"); !ok {
+		t.Error("Expected allowed path to be permitted")
+	}
+}
+
+func TestAllowedRoots_EmptyMeansUnrestricted(t *testing.T) {
+	SetAllowedRoots(nil)
+	if !isPathAllowed("/any/path") {
+		t.Error("Expected no restriction when allowlist is empty")
+	}
+}
@@ -0,0 +1,313 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/git"
+)
+
+// maxLineCountSize bounds the files whose lines are counted for /api/files/stat
+const maxLineCountSize = 10 * 1024 * 1024
+
+// FileStat is the detailed metadata returned by /api/files/stat
+type FileStat struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	IsDir       bool   `json:"isDir"`
+	IsSymlink   bool   `json:"isSymlink"`
+	LinkTarget  string `json:"linkTarget,omitempty"`
+	Size        int64  `json:"size"`
+	Mode        string `json:"mode"`        // e.g. "-rw-r--r--"
+	Permissions string `json:"permissions"` // Octal, e.g. "0644"
+	Owner       string `json:"owner,omitempty"`
+	Group       string `json:"group,omitempty"`
+	ModTime     int64  `json:"modTime"`
+	ContentType string `json:"contentType,omitempty"` // "text", "binary" or "image"
+	LineCount   int    `json:"lineCount"`             // -1 when not counted (binary, directory, too large)
+	Language    string `json:"language,omitempty"`
+	GitStatus   string `json:"gitStatus,omitempty"` // Empty when not inside a repository
+}
+
+// FileChmodRequest is the body for POST /api/files/chmod
+type FileChmodRequest struct {
+	Path     string `json:"path"`
+	RootPath string `json:"rootPath"`
+	Mode     string `json:"mode"` // Octal permissions, e.g. "0755"
+}
+
+// languageByExtension maps file extensions to editor language identifiers
+var languageByExtension = map[string]string{
+	".go":     "go",
+	".js":     "javascript",
+	".jsx":    "javascript",
+	".mjs":    "javascript",
+	".cjs":    "javascript",
+	".ts":     "typescript",
+	".tsx":    "typescript",
+	".py":     "python",
+	".rb":     "ruby",
+	".rs":     "rust",
+	".java":   "java",
+	".kt":     "kotlin",
+	".c":      "c",
+	".h":      "c",
+	".cpp":    "cpp",
+	".cc":     "cpp",
+	".hpp":    "cpp",
+	".cs":     "csharp",
+	".php":    "php",
+	".swift":  "swift",
+	".sh":     "shell",
+	".bash":   "shell",
+	".zsh":    "shell",
+	".ps1":    "powershell",
+	".bat":    "bat",
+	".cmd":    "bat",
+	".html":   "html",
+	".htm":    "html",
+	".css":    "css",
+	".scss":   "scss",
+	".less":   "less",
+	".json":   "json",
+	".yaml":   "yaml",
+	".yml":    "yaml",
+	".toml":   "toml",
+	".xml":    "xml",
+	".md":     "markdown",
+	".sql":    "sql",
+	".lua":    "lua",
+	".vue":    "vue",
+	".svelte": "svelte",
+}
+
+// languageByFilename maps well-known extensionless files to languages
+var languageByFilename = map[string]string{
+	"Makefile":   "makefile",
+	"Dockerfile": "dockerfile",
+	"go.mod":     "go.mod",
+	"go.sum":     "go.sum",
+	".gitignore": "ignore",
+	".bashrc":    "shell",
+	".zshrc":     "shell",
+}
+
+// detectLanguage guesses the language of a file from its name.
+func detectLanguage(path string) string {
+	name := filepath.Base(path)
+	if lang, ok := languageByFilename[name]; ok {
+		return lang
+	}
+	if strings.HasPrefix(name, "Dockerfile.") {
+		return "dockerfile"
+	}
+	if lang, ok := languageByExtension[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+	return "plaintext"
+}
+
+// countLines counts newline-terminated lines, plus a final unterminated line.
+func countLines(r io.Reader) (int, error) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	buf := make([]byte, 64*1024)
+	count := 0
+	var last byte
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			count += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if last != 0 && last != '\n' {
+		count++
+	}
+	return count, nil
+}
+
+// statPath gathers metadata for absPath, including git status when available.
+func statPath(ctx context.Context, absPath string) (*FileStat, error) {
+	linfo, err := os.Lstat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &FileStat{
+		Path:      absPath,
+		Name:      filepath.Base(absPath),
+		IsSymlink: linfo.Mode()&os.ModeSymlink != 0,
+		LineCount: -1,
+	}
+
+	info := linfo
+	if stat.IsSymlink {
+		stat.LinkTarget, _ = os.Readlink(absPath)
+		// Report details of the link target when it resolves
+		if target, err := os.Stat(absPath); err == nil {
+			info = target
+		}
+	}
+
+	stat.IsDir = info.IsDir()
+	stat.Size = info.Size()
+	stat.Mode = info.Mode().String()
+	stat.Permissions = fmt.Sprintf("%04o", info.Mode().Perm())
+	stat.ModTime = info.ModTime().Unix()
+	stat.Owner, stat.Group = fileOwner(info)
+
+	if !stat.IsDir {
+		stat.Language = detectLanguage(absPath)
+		if err := statContent(absPath, stat); err != nil {
+			log.Printf("[Files] Failed to inspect %s: %v", absPath, err)
+		}
+	}
+
+	if status, err := git.GetPathStatus(ctx, absPath); err == nil {
+		stat.GitStatus = status
+	}
+
+	return stat, nil
+}
+
+// statContent fills in content type and line count for regular files.
+func statContent(absPath string, stat *FileStat) error {
+	file, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	stat.ContentType = detectContent(absPath, head[:n]).Type
+
+	if stat.ContentType != ContentTypeText || stat.Size > maxLineCountSize {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	stat.LineCount, err = countLines(file)
+	return err
+}
+
+// HandleStat returns detailed metadata for ?path= (size, mode, owner, lines, language, git status)
+func HandleStat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filePath := r.URL.Query().Get("path")
+	if filePath == "" {
+		http.Error(w, "Missing path parameter", http.StatusBadRequest)
+		return
+	}
+
+	rootPath := r.URL.Query().Get("rootPath")
+	if rootPath == "" {
+		rootPath = "."
+	}
+
+	absPath, err := resolvePathWithDistro(filePath, r.URL.Query().Get("distro"))
+	if err != nil {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	within, err := isPathWithinRoot(absPath, rootPath)
+	if err != nil || !within {
+		http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	stat, err := statPath(ctx, absPath)
+	if err != nil {
+		http.Error(w, "Path not found: "+absPath, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stat)
+}
+
+// HandleChmod changes the permission bits of a file (Unix only)
+func HandleChmod(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if runtime.GOOS == "windows" {
+		http.Error(w, "chmod is not supported on Windows", http.StatusNotImplemented)
+		return
+	}
+
+	var req FileChmodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	mode, err := strconv.ParseUint(req.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		http.Error(w, "Invalid mode: expected octal permissions like 0755", http.StatusBadRequest)
+		return
+	}
+
+	rootPath := req.RootPath
+	if rootPath == "" {
+		rootPath = "."
+	}
+
+	absPath, err := filepath.Abs(req.Path)
+	if err != nil {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	within, err := isPathWithinRoot(absPath, rootPath)
+	if err != nil || !within {
+		http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+		return
+	}
+
+	if err := os.Chmod(absPath, os.FileMode(mode)); err != nil {
+		http.Error(w, "Failed to change permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[Files] chmod %04o %s", mode, absPath)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":      "success",
+		"path":        req.Path,
+		"permissions": fmt.Sprintf("%04o", mode),
+	})
+}
@@ -0,0 +1,87 @@
+package files
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"main.go":            "go",
+		"App.JSX":            "javascript",
+		"Makefile":           "makefile",
+		"Dockerfile.dev":     "dockerfile",
+		"script.ps1":         "powershell",
+		"notes":              "plaintext",
+		"/abs/path/conf.yml": "yaml",
+	}
+
+	for path, expected := range tests {
+		if got := detectLanguage(path); got != expected {
+			t.Errorf("detectLanguage(%q) = %q, want %q", path, got, expected)
+		}
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"one", 1},
+		{"one\n", 1},
+		{"one\ntwo", 2},
+		{"one\ntwo\n\n", 3},
+	}
+
+	for _, tt := range tests {
+		got, err := countLines(strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("countLines(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.expected {
+			t.Errorf("countLines(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestStatPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0640)
+
+	stat, err := statPath(context.Background(), path)
+	if err != nil {
+		t.Fatalf("statPath failed: %v", err)
+	}
+
+	if stat.Language != "go" || stat.LineCount != 3 || stat.ContentType != ContentTypeText {
+		t.Errorf("Unexpected stat: %+v", stat)
+	}
+	if stat.Permissions != "0640" && stat.Permissions != "0666" { // Windows ignores most bits
+		t.Errorf("Unexpected permissions: %s", stat.Permissions)
+	}
+
+	binPath := filepath.Join(dir, "data.bin")
+	os.WriteFile(binPath, []byte{0x00, 0x01, 0x02}, 0644)
+
+	stat, err = statPath(context.Background(), binPath)
+	if err != nil {
+		t.Fatalf("statPath failed: %v", err)
+	}
+	if stat.ContentType != ContentTypeBinary || stat.LineCount != -1 {
+		t.Errorf("Expected binary without line count, got %+v", stat)
+	}
+
+	stat, err = statPath(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("statPath failed: %v", err)
+	}
+	if !stat.IsDir || stat.Language != "" {
+		t.Errorf("Unexpected directory stat: %+v", stat)
+	}
+}
@@ -0,0 +1,154 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// tailChunkSize is how far tailOffset reads backwards per step
+	tailChunkSize = 64 * 1024
+	// followPollInterval is how often a followed file is checked for new data
+	followPollInterval = 500 * time.Millisecond
+	// followMaxChunk caps a single SSE event so a burst of output can't stall the browser
+	followMaxChunk = 256 * 1024
+	// followDefaultLines is where following starts without tail or offset, as tail -f does
+	followDefaultLines = 100
+)
+
+// tailOffset returns the byte offset where the last n lines of file begin.
+// A trailing newline at end of file does not count as an extra empty line.
+func tailOffset(file io.ReaderAt, size int64, n int) (int64, error) {
+	if n <= 0 || size == 0 {
+		return size, nil
+	}
+
+	buf := make([]byte, tailChunkSize)
+	pos := size
+	newlines := 0
+	skipTrailing := true
+
+	for pos > 0 {
+		readSize := int64(tailChunkSize)
+		if pos < readSize {
+			readSize = pos
+		}
+		pos -= readSize
+
+		if _, err := file.ReadAt(buf[:readSize], pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		for i := readSize - 1; i >= 0; i-- {
+			if buf[i] != '\n' {
+				skipTrailing = false
+				continue
+			}
+			if skipTrailing {
+				skipTrailing = false
+				continue
+			}
+			newlines++
+			if newlines == n {
+				return pos + i + 1, nil
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// streamFollow sends the file from offset onwards as Server-Sent Events and keeps
+// sending appended data until the client disconnects. Truncation (e.g. log rotation)
+// restarts the stream from the beginning of the file.
+func streamFollow(w http.ResponseWriter, r *http.Request, file *os.File, offset int64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sendEvent := func(event string, payload interface{}) {
+		data, _ := json.Marshal(payload)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	// sendAvailable flushes everything between offset and the current end of file.
+	// A character cut off at the end of a read is left for the next one.
+	sendAvailable := func(size int64) {
+		buf := make([]byte, followMaxChunk)
+		for offset < size {
+			n, err := file.ReadAt(buf, offset)
+			n = completeRunes(buf[:n])
+			if n > 0 {
+				offset += int64(n)
+				sendEvent("data", map[string]interface{}{
+					"content": string(buf[:n]),
+					"offset":  offset,
+				})
+			}
+			if err != nil || n == 0 {
+				return
+			}
+		}
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		sendEvent("error", map[string]string{"message": err.Error()})
+		return
+	}
+	sendEvent("connected", map[string]interface{}{
+		"path":   file.Name(),
+		"size":   info.Size(),
+		"offset": offset,
+	})
+	sendAvailable(info.Size())
+
+	ticker := time.NewTicker(followPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Printf("[Files] Stopped following %s", file.Name())
+			return
+		case <-ticker.C:
+			info, err := file.Stat()
+			if err != nil {
+				sendEvent("error", map[string]string{"message": err.Error()})
+				return
+			}
+			if info.Size() < offset {
+				offset = 0
+				sendEvent("truncated", map[string]interface{}{"size": info.Size()})
+			}
+			sendAvailable(info.Size())
+		}
+	}
+}
+
+// completeRunes returns the length of data without a UTF-8 character cut off
+// at its end, so streamed chunks don't split one into replacement characters.
+func completeRunes(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return len(data)
+			}
+			return i
+		}
+	}
+	return len(data)
+}
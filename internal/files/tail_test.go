@@ -0,0 +1,196 @@
+package files
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTailOffset(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\n"
+	reader := strings.NewReader(content)
+	size := int64(len(content))
+
+	tests := []struct {
+		lines    int
+		expected string
+	}{
+		{0, ""},
+		{1, "four\n"},
+		{2, "three\nfour\n"},
+		{4, content},
+		{10, content},
+	}
+
+	for _, tt := range tests {
+		offset, err := tailOffset(reader, size, tt.lines)
+		if err != nil {
+			t.Fatalf("tailOffset(%d) failed: %v", tt.lines, err)
+		}
+		if got := content[offset:]; got != tt.expected {
+			t.Errorf("tailOffset(%d) = %q, want %q", tt.lines, got, tt.expected)
+		}
+	}
+}
+
+func TestTailOffset_NoTrailingNewline(t *testing.T) {
+	content := "one\ntwo\nthree"
+	offset, err := tailOffset(strings.NewReader(content), int64(len(content)), 2)
+	if err != nil {
+		t.Fatalf("tailOffset failed: %v", err)
+	}
+	if got := content[offset:]; got != "two\nthree" {
+		t.Errorf("Expected %q, got %q", "two\nthree", got)
+	}
+}
+
+func TestHandleReadStream_Ranges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "build.log")
+	if err := os.WriteFile(path, []byte("0123456789\nline2\nline3\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		params   url.Values
+		expected string
+	}{
+		{"whole file", url.Values{}, "0123456789\nline2\nline3\n"},
+		{"offset and limit", url.Values{"offset": {"2"}, "limit": {"3"}}, "234"},
+		{"offset past end", url.Values{"offset": {"1000"}}, ""},
+		{"tail", url.Values{"tail": {"1"}}, "line3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Set("path", path)
+			tt.params.Set("rootPath", dir)
+			req := httptest.NewRequest("GET", "/api/files/stream?"+tt.params.Encode(), nil)
+			rec := httptest.NewRecorder()
+
+			HandleReadStream(rec, req)
+
+			if rec.Code != 200 {
+				t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			body, _ := io.ReadAll(rec.Body)
+			if string(body) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, body)
+			}
+			if rec.Header().Get("X-File-Size") != "23" {
+				t.Errorf("Expected X-File-Size 23, got %s", rec.Header().Get("X-File-Size"))
+			}
+		})
+	}
+}
+
+func TestCompleteRunes(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"a€", 4},
+		{"a\xe2\x82", 1}, // € cut after two of its three bytes
+		{"a\xf0", 1},
+		{"a\xff", 2}, // Invalid bytes are sent as they are
+	}
+	for _, tt := range tests {
+		if got := completeRunes([]byte(tt.data)); got != tt.expected {
+			t.Errorf("completeRunes(%q) = %d, want %d", tt.data, got, tt.expected)
+		}
+	}
+}
+
+// followEvents runs a follow request that stops after the initial send and
+// returns its SSE events as name and JSON payload pairs.
+func followEvents(t *testing.T, params url.Values) [][2]string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	params.Set("follow", "true")
+	req := httptest.NewRequest("GET", "/api/files/stream?"+params.Encode(), nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	HandleReadStream(rec, req)
+
+	var events [][2]string
+	for _, block := range strings.Split(rec.Body.String(), "\n\n") {
+		name, rest, ok := strings.Cut(block, "\n")
+		if !ok {
+			continue
+		}
+		events = append(events, [2]string{strings.TrimPrefix(name, "event: "), strings.TrimPrefix(rest, "data: ")})
+	}
+	return events
+}
+
+func TestHandleReadStream_FollowStartsNearEnd(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "build.log")
+	var content strings.Builder
+	for i := 0; i < followDefaultLines*2; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	file, _ := os.Open(path)
+	defer file.Close()
+	want, _ := tailOffset(file, int64(content.Len()), followDefaultLines)
+
+	connectedOffset := func(params url.Values) int64 {
+		params.Set("path", path)
+		params.Set("rootPath", dir)
+		events := followEvents(t, params)
+		if len(events) == 0 || events[0][0] != "connected" {
+			t.Fatalf("Expected a connected event, got %v", events)
+		}
+		var connected struct {
+			Offset int64 `json:"offset"`
+		}
+		json.Unmarshal([]byte(events[0][1]), &connected)
+		return connected.Offset
+	}
+
+	if got := connectedOffset(url.Values{}); got != want {
+		t.Errorf("Expected following to start %d lines from the end (offset %d), got %d", followDefaultLines, want, got)
+	}
+	if got := connectedOffset(url.Values{"offset": {"0"}}); got != 0 {
+		t.Errorf("Expected an explicit offset to be kept, got %d", got)
+	}
+}
+
+func TestHandleReadStream_FollowKeepsCharactersWhole(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "build.log")
+	// The first read ends one byte into the €
+	content := strings.Repeat("a", followMaxChunk-1) + "€ done\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var got strings.Builder
+	for _, event := range followEvents(t, url.Values{"path": {path}, "rootPath": {dir}, "offset": {"0"}}) {
+		if event[0] != "data" {
+			continue
+		}
+		var data struct {
+			Content string `json:"content"`
+		}
+		json.Unmarshal([]byte(event[1]), &data)
+		got.WriteString(data.Content)
+	}
+	if got.String() != content {
+		t.Errorf("Expected the file back unchanged, got %d bytes ending %q", got.Len(), got.String()[max(0, got.Len()-12):])
+	}
+}
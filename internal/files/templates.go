@@ -0,0 +1,265 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// defaultTemplates are written to the templates directory the first time it is used.
+// Users can edit or add files there; the file name is the template name.
+var defaultTemplates = map[string]string{
+	".gitignore": `# Dependencies
+node_modules/
+vendor/
+
+# Build output
+dist/
+build/
+*.exe
+*.out
+
+# Environment
+.env
+.env.local
+
+# Editor and OS files
+.vscode/
+.idea/
+.DS_Store
+Thumbs.db
+`,
+	"README.md": `# {{name}}
+
+Short description of the project.
+
+## Getting Started
+
+` + "```bash" + `
+# install and run instructions
+` + "```" + `
+
+## License
+
+See LICENSE.
+`,
+	"LICENSE": `MIT License
+
+Copyright (c) {{year}} {{author}}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`,
+}
+
+// FileCreateRequest is the body for POST /api/files/create
+type FileCreateRequest struct {
+	Path     string `json:"path"`
+	RootPath string `json:"rootPath"`
+	Type     string `json:"type"`               // "file" (default) or "directory"
+	Template string `json:"template,omitempty"` // Template name for files, e.g. "README.md"
+}
+
+// ensureTemplates seeds the templates directory with defaults if it doesn't exist.
+func ensureTemplates() error {
+	dir := storage.GetTemplatesDir()
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, content := range defaultTemplates {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	log.Printf("[Files] Created default templates in %s", dir)
+	return nil
+}
+
+// ListTemplates returns the names of available templates.
+func ListTemplates() ([]string, error) {
+	if err := ensureTemplates(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(storage.GetTemplatesDir())
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// renderTemplate loads a template and expands {{name}}, {{year}}, {{date}} and {{author}}.
+// name is the base name of the directory the file is created in.
+func renderTemplate(template, targetPath string) ([]byte, error) {
+	if err := ensureTemplates(); err != nil {
+		return nil, err
+	}
+
+	// Template names are plain file names; reject anything that could escape the directory
+	if template != filepath.Base(template) || template == "." || template == ".." {
+		return nil, fmt.Errorf("invalid template name: %s", template)
+	}
+
+	data, err := os.ReadFile(filepath.Join(storage.GetTemplatesDir(), template))
+	if err != nil {
+		return nil, fmt.Errorf("template not found: %s", template)
+	}
+
+	author := os.Getenv("USER")
+	if author == "" {
+		author = os.Getenv("USERNAME")
+	}
+
+	now := time.Now()
+	replacer := strings.NewReplacer(
+		"{{name}}", filepath.Base(filepath.Dir(targetPath)),
+		"{{year}}", strconv.Itoa(now.Year()),
+		"{{date}}", now.Format("2006-01-02"),
+		"{{author}}", author,
+	)
+	return []byte(replacer.Replace(string(data))), nil
+}
+
+// HandleTemplates lists available "New file" templates
+func HandleTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	names, err := ListTemplates()
+	if err != nil {
+		http.Error(w, "Failed to load templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": names,
+		"dir":       storage.GetTemplatesDir(),
+	})
+}
+
+// HandleCreate creates an empty file, a directory, or a file from a template
+func HandleCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req FileCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Path == "" {
+		http.Error(w, "Missing path", http.StatusBadRequest)
+		return
+	}
+
+	rootPath := req.RootPath
+	if rootPath == "" {
+		rootPath = "."
+	}
+
+	absPath, err := filepath.Abs(req.Path)
+	if err != nil {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	within, err := isPathWithinRoot(absPath, rootPath)
+	if err != nil || !within {
+		http.Error(w, "Path is outside allowed root directory", http.StatusForbidden)
+		return
+	}
+
+	if _, err := os.Lstat(absPath); err == nil {
+		http.Error(w, "Path already exists", http.StatusConflict)
+		return
+	}
+
+	switch req.Type {
+	case "directory":
+		if err := os.MkdirAll(absPath, 0755); err != nil {
+			http.Error(w, "Failed to create directory: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case "", "file":
+		var content []byte
+		if req.Template != "" {
+			content, err = renderTemplate(req.Template, absPath)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+			http.Error(w, "Failed to create parent directory: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// O_EXCL guards against a file appearing between the existence check and now
+		file, err := os.OpenFile(absPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			http.Error(w, "Failed to create file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, err = file.Write(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			http.Error(w, "Failed to write file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Unknown type: "+req.Type, http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("[Files] Created %s (type=%s template=%s)", absPath, req.Type, req.Template)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "success",
+		"path":   absPath,
+	})
+}
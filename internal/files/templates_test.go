@@ -0,0 +1,88 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func useTempTemplates(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	return storage.GetTemplatesDir()
+}
+
+func postCreate(t *testing.T, req FileCreateRequest) int {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	HandleCreate(rec, httptest.NewRequest("POST", "/api/files/create", bytes.NewReader(body)))
+	return rec.Code
+}
+
+func TestListTemplates_SeedsDefaults(t *testing.T) {
+	useTempTemplates(t)
+
+	names, err := ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates failed: %v", err)
+	}
+	if len(names) != len(defaultTemplates) {
+		t.Errorf("Expected %d default templates, got %v", len(defaultTemplates), names)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	useTempTemplates(t)
+	root := filepath.Join(t.TempDir(), "myproject")
+	os.Mkdir(root, 0755)
+
+	// Empty file in a new subdirectory
+	if code := postCreate(t, FileCreateRequest{Path: filepath.Join(root, "src", "main.go"), RootPath: root}); code != 200 {
+		t.Fatalf("Expected 200 creating file, got %d", code)
+	}
+	if info, err := os.Stat(filepath.Join(root, "src", "main.go")); err != nil || info.Size() != 0 {
+		t.Errorf("Expected empty file, got %v %v", info, err)
+	}
+
+	// Directory
+	if code := postCreate(t, FileCreateRequest{Path: filepath.Join(root, "docs"), RootPath: root, Type: "directory"}); code != 200 {
+		t.Fatalf("Expected 200 creating directory, got %d", code)
+	}
+
+	// Template with variables expanded
+	readme := filepath.Join(root, "README.md")
+	if code := postCreate(t, FileCreateRequest{Path: readme, RootPath: root, Template: "README.md"}); code != 200 {
+		t.Fatalf("Expected 200 creating from template, got %d", code)
+	}
+	data, _ := os.ReadFile(readme)
+	if !strings.HasPrefix(string(data), "# myproject") {
+		t.Errorf("Expected project name in README, got %q", data)
+	}
+
+	license := filepath.Join(root, "LICENSE")
+	postCreate(t, FileCreateRequest{Path: license, RootPath: root, Template: "LICENSE"})
+	data, _ = os.ReadFile(license)
+	if !strings.Contains(string(data), strconv.Itoa(time.Now().Year())) {
+		t.Error("Expected current year in LICENSE")
+	}
+
+	// Existing paths are never overwritten
+	if code := postCreate(t, FileCreateRequest{Path: readme, RootPath: root}); code != 409 {
+		t.Errorf("Expected 409 for existing path, got %d", code)
+	}
+
+	// Template names can't escape the templates directory
+	if code := postCreate(t, FileCreateRequest{Path: filepath.Join(root, "x"), RootPath: root, Template: "../secret"}); code != 400 {
+		t.Errorf("Expected 400 for invalid template, got %d", code)
+	}
+}
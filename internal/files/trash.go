@@ -0,0 +1,432 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikejsmith1985/forge-terminal/internal/audit"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// DefaultTrashRetentionDays is how long deleted files are kept before being
+// purged, unless SetTrashRetentionDays says otherwise
+const DefaultTrashRetentionDays = 30
+
+// trashPurgeInterval is how often ScheduleTrashPurge looks for expired entries
+const trashPurgeInterval = time.Hour
+
+var trashMutex sync.Mutex
+
+var (
+	retentionMu        sync.RWMutex
+	trashRetentionDays = DefaultTrashRetentionDays
+)
+
+// SetTrashRetentionDays sets how many days deleted files are kept; zero or
+// less uses DefaultTrashRetentionDays.
+func SetTrashRetentionDays(days int) {
+	if days <= 0 {
+		days = DefaultTrashRetentionDays
+	}
+	retentionMu.Lock()
+	trashRetentionDays = days
+	retentionMu.Unlock()
+}
+
+// TrashRetentionDays returns how many days deleted files are kept.
+func TrashRetentionDays() int {
+	retentionMu.RLock()
+	defer retentionMu.RUnlock()
+	return trashRetentionDays
+}
+
+// TrashEntry describes a file or directory moved to the trash.
+type TrashEntry struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"originalPath"`
+	Name         string    `json:"name"`
+	IsDir        bool      `json:"isDir"`
+	Size         int64     `json:"size"`
+	DeletedAt    time.Time `json:"deletedAt"`
+}
+
+// TrashRequest is the body for POST /api/files/trash
+type TrashRequest struct {
+	Action    string `json:"action"`              // "restore" or "purge"
+	ID        string `json:"id,omitempty"`        // Entry to act on; empty purges everything
+	Overwrite bool   `json:"overwrite,omitempty"` // Restore over an existing file
+}
+
+func trashManifestPath() string {
+	return filepath.Join(storage.GetTrashDir(), "manifest.json")
+}
+
+func trashItemPath(id string) string {
+	return filepath.Join(storage.GetTrashDir(), "items", id)
+}
+
+// loadTrashManifest reads the manifest. Caller must hold trashMutex.
+func loadTrashManifest() ([]TrashEntry, error) {
+	data, err := storage.ReadJSONFile(trashManifestPath())
+	if os.IsNotExist(err) {
+		return []TrashEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TrashEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// saveTrashManifest writes the manifest. Caller must hold trashMutex.
+func saveTrashManifest(entries []TrashEntry) error {
+	if err := os.MkdirAll(storage.GetTrashDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(trashManifestPath(), data, 0600)
+}
+
+// MoveToTrash moves absPath into the trash and records it in the manifest.
+func MoveToTrash(absPath string) (*TrashEntry, error) {
+	if _, err := os.Lstat(absPath); err != nil {
+		return nil, err
+	}
+
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	entries, err := loadTrashManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash manifest: %w", err)
+	}
+
+	entry, err := moveToTrashLocked(absPath)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, *entry)
+	if err := saveTrashManifest(entries); err != nil {
+		// Put it back rather than leave it in the trash unlisted
+		movePath(trashItemPath(entry.ID), absPath)
+		return nil, err
+	}
+
+	log.Printf("[Files] Moved to trash: %s (%s)", absPath, entry.ID)
+	return entry, nil
+}
+
+// moveToTrashLocked moves absPath into the trash, returning the entry for the
+// caller to add to the manifest. Caller must hold trashMutex.
+func moveToTrashLocked(absPath string) (*TrashEntry, error) {
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := TrashEntry{
+		ID:           uuid.New().String(),
+		OriginalPath: absPath,
+		Name:         filepath.Base(absPath),
+		IsDir:        info.IsDir(),
+		Size:         pathSize(absPath),
+		DeletedAt:    time.Now(),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(trashItemPath(entry.ID)), 0700); err != nil {
+		return nil, err
+	}
+	if err := movePath(absPath, trashItemPath(entry.ID)); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListTrash returns trash entries, most recently deleted first.
+func ListTrash() ([]TrashEntry, error) {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	entries, err := loadTrashManifest()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// RestoreFromTrash moves an entry back to its original location. With
+// overwrite, whatever is there now is moved to the trash in its place.
+func RestoreFromTrash(id string, overwrite bool) (*TrashEntry, error) {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	entries, err := loadTrashManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+
+		var replaced *TrashEntry
+		if _, err := os.Lstat(entry.OriginalPath); err == nil {
+			if !overwrite {
+				return nil, fmt.Errorf("%s already exists", entry.OriginalPath)
+			}
+			if replaced, err = moveToTrashLocked(entry.OriginalPath); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(entry.OriginalPath), 0755); err != nil {
+			return nil, err
+		}
+		if err := movePath(trashItemPath(id), entry.OriginalPath); err != nil {
+			if replaced != nil {
+				movePath(trashItemPath(replaced.ID), entry.OriginalPath)
+			}
+			return nil, err
+		}
+
+		entries = append(entries[:i], entries[i+1:]...)
+		if replaced != nil {
+			entries = append(entries, *replaced)
+			log.Printf("[Files] Moved to trash: %s (%s), replaced by a restore", replaced.OriginalPath, replaced.ID)
+		}
+		if err := saveTrashManifest(entries); err != nil {
+			movePath(entry.OriginalPath, trashItemPath(id))
+			if replaced != nil {
+				movePath(trashItemPath(replaced.ID), entry.OriginalPath)
+			}
+			return nil, err
+		}
+
+		log.Printf("[Files] Restored from trash: %s", entry.OriginalPath)
+		return &entry, nil
+	}
+
+	return nil, fmt.Errorf("trash entry not found: %s", id)
+}
+
+// PurgeTrash permanently deletes the entry with id, or every entry if id is empty.
+// Returns the number of entries removed.
+func PurgeTrash(id string) (int, error) {
+	return purgeTrashWhere(func(entry TrashEntry) bool {
+		return id == "" || entry.ID == id
+	})
+}
+
+// ScheduleTrashPurge purges expired trash entries now and then every
+// trashPurgeInterval. It never returns.
+func ScheduleTrashPurge() {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := PurgeExpiredTrash(); err != nil {
+			log.Printf("[Files] Failed to purge expired trash: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// PurgeExpiredTrash permanently deletes entries older than TrashRetentionDays.
+func PurgeExpiredTrash() error {
+	days := TrashRetentionDays()
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	count, err := purgeTrashWhere(func(entry TrashEntry) bool {
+		return entry.DeletedAt.Before(cutoff)
+	})
+	if count > 0 {
+		log.Printf("[Files] Purged %d expired trash entries", count)
+		audit.Record(audit.Event{Action: audit.FileDelete, Target: "expired trash entries", Detail: fmt.Sprintf("purged %d older than %d days", count, days)})
+	}
+	return err
+}
+
+func purgeTrashWhere(match func(TrashEntry) bool) (int, error) {
+	trashMutex.Lock()
+	defer trashMutex.Unlock()
+
+	entries, err := loadTrashManifest()
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]TrashEntry, 0, len(entries))
+	purged := 0
+	for _, entry := range entries {
+		if !match(entry) {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := os.RemoveAll(trashItemPath(entry.ID)); err != nil {
+			log.Printf("[Files] Failed to purge trash entry %s: %v", entry.ID, err)
+			kept = append(kept, entry)
+			continue
+		}
+		purged++
+	}
+
+	if purged == 0 {
+		return 0, nil
+	}
+	return purged, saveTrashManifest(kept)
+}
+
+// movePath renames src to dst, falling back to copy+delete across filesystems.
+func movePath(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyPath(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPath recursively copies files, directories, and symlinks.
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+}
+
+// pathSize returns the total size of a file or directory tree.
+func pathSize(p string) int64 {
+	var total int64
+	filepath.Walk(p, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// HandleTrash lists trash entries (GET) or restores/purges them (POST)
+func HandleTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		entries, err := ListTrash()
+		if err != nil {
+			http.Error(w, "Failed to read trash: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries":       entries,
+			"retentionDays": TrashRetentionDays(),
+		})
+
+	case http.MethodPost:
+		var req TrashRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		switch req.Action {
+		case "restore":
+			if req.ID == "" {
+				http.Error(w, "Missing id", http.StatusBadRequest)
+				return
+			}
+			entry, err := RestoreFromTrash(req.ID, req.Overwrite)
+			if err != nil {
+				http.Error(w, "Failed to restore: "+err.Error(), http.StatusConflict)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "success",
+				"entry":  entry,
+			})
+
+		case "purge":
+			count, err := PurgeTrash(req.ID)
+			target := "all trash entries"
+			if req.ID != "" {
+				target = "trash entry " + req.ID
+			}
+			audit.RecordResult(audit.Event{Action: audit.FileDelete, Target: target, Detail: fmt.Sprintf("purged %d from the trash", count)}, err)
+			if err != nil {
+				http.Error(w, "Failed to purge: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "success",
+				"purged": count,
+			})
+
+		default:
+			http.Error(w, "Unknown action: "+req.Action, http.StatusBadRequest)
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
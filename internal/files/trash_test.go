@@ -0,0 +1,189 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func useTempTrash(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+}
+
+func TestTrash_MoveAndRestore(t *testing.T) {
+	useTempTrash(t)
+
+	work := t.TempDir()
+	path := filepath.Join(work, "notes.txt")
+	os.WriteFile(path, []byte("keep me"), 0644)
+
+	entry, err := MoveToTrash(path)
+	if err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected file to be removed from original location")
+	}
+	if entry.Size != 7 || entry.OriginalPath != path {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	entries, _ := ListTrash()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 trash entry, got %d", len(entries))
+	}
+
+	// Restoring over an existing file requires overwrite
+	os.WriteFile(path, []byte("new"), 0644)
+	if _, err := RestoreFromTrash(entry.ID, false); err == nil {
+		t.Error("Expected restore to fail when original path exists")
+	}
+	if _, err := RestoreFromTrash(entry.ID, true); err != nil {
+		t.Fatalf("RestoreFromTrash failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "keep me" {
+		t.Errorf("Expected restored content, got %q", data)
+	}
+
+	// The file it replaced went to the trash in its place
+	entries, _ = ListTrash()
+	if len(entries) != 1 || entries[0].ID == entry.ID || entries[0].OriginalPath != path {
+		t.Fatalf("Expected the replaced file in the trash, got %+v", entries)
+	}
+	if data, _ := os.ReadFile(trashItemPath(entries[0].ID)); string(data) != "new" {
+		t.Errorf("Expected the replaced content in the trash, got %q", data)
+	}
+}
+
+func TestTrash_ManifestWriteFailure(t *testing.T) {
+	useTempTrash(t)
+
+	// A directory where the manifest's backup goes makes saving it fail
+	os.MkdirAll(filepath.Join(storage.GetTrashDir(), "items"), 0700)
+	os.WriteFile(trashManifestPath(), []byte("[]"), 0600)
+	os.MkdirAll(filepath.Join(trashManifestPath()+storage.BackupSuffix, "blocked"), 0700)
+
+	work := t.TempDir()
+	path := filepath.Join(work, "notes.txt")
+	os.WriteFile(path, []byte("keep me"), 0644)
+
+	if _, err := MoveToTrash(path); err == nil {
+		t.Fatal("Expected MoveToTrash to fail when the manifest can't be saved")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "keep me" {
+		t.Errorf("Expected the file back in place, got %q (%v)", data, err)
+	}
+	if items, _ := os.ReadDir(filepath.Join(storage.GetTrashDir(), "items")); len(items) != 0 {
+		t.Errorf("Expected no unlisted items in the trash, got %d", len(items))
+	}
+}
+
+func TestTrash_Directory(t *testing.T) {
+	useTempTrash(t)
+
+	dir := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(filepath.Join(dir, "nested"), 0755)
+	os.WriteFile(filepath.Join(dir, "nested", "a.go"), []byte("package a"), 0644)
+
+	entry, err := MoveToTrash(dir)
+	if err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+	if !entry.IsDir {
+		t.Error("Expected directory entry")
+	}
+
+	if _, err := RestoreFromTrash(entry.ID, false); err != nil {
+		t.Fatalf("RestoreFromTrash failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nested", "a.go")); err != nil {
+		t.Errorf("Expected nested file to be restored: %v", err)
+	}
+}
+
+func TestTrash_Purge(t *testing.T) {
+	useTempTrash(t)
+
+	work := t.TempDir()
+	var ids []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		path := filepath.Join(work, name)
+		os.WriteFile(path, []byte(name), 0644)
+		entry, err := MoveToTrash(path)
+		if err != nil {
+			t.Fatalf("MoveToTrash failed: %v", err)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	if count, err := PurgeTrash(ids[0]); err != nil || count != 1 {
+		t.Fatalf("Expected to purge 1 entry, got %d (%v)", count, err)
+	}
+	if _, err := os.Stat(trashItemPath(ids[0])); !os.IsNotExist(err) {
+		t.Error("Expected purged item to be deleted from disk")
+	}
+
+	// Age one entry past the retention window
+	trashMutex.Lock()
+	entries, _ := loadTrashManifest()
+	for i := range entries {
+		if entries[i].ID == ids[1] {
+			entries[i].DeletedAt = time.Now().AddDate(0, 0, -(DefaultTrashRetentionDays + 1))
+		}
+	}
+	saveTrashManifest(entries)
+	trashMutex.Unlock()
+
+	if err := PurgeExpiredTrash(); err != nil {
+		t.Fatalf("PurgeExpiredTrash failed: %v", err)
+	}
+	remaining, _ := ListTrash()
+	if len(remaining) != 1 || remaining[0].ID != ids[2] {
+		t.Errorf("Expected only the recent entry to remain, got %+v", remaining)
+	}
+}
+
+func TestTrash_Retention(t *testing.T) {
+	useTempTrash(t)
+	defer SetTrashRetentionDays(0)
+
+	path := filepath.Join(t.TempDir(), "old.txt")
+	os.WriteFile(path, []byte("old"), 0644)
+	entry, err := MoveToTrash(path)
+	if err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+
+	trashMutex.Lock()
+	entries, _ := loadTrashManifest()
+	entries[0].DeletedAt = time.Now().AddDate(0, 0, -3)
+	saveTrashManifest(entries)
+	trashMutex.Unlock()
+
+	SetTrashRetentionDays(7)
+	PurgeExpiredTrash()
+	if remaining, _ := ListTrash(); len(remaining) != 1 {
+		t.Fatalf("Expected a 3-day-old entry kept for 7 days, got %+v", remaining)
+	}
+
+	SetTrashRetentionDays(2)
+	PurgeExpiredTrash()
+	if remaining, _ := ListTrash(); len(remaining) != 0 {
+		t.Errorf("Expected a 3-day-old entry purged after 2 days, got %+v", remaining)
+	}
+	if _, err := os.Stat(trashItemPath(entry.ID)); !os.IsNotExist(err) {
+		t.Error("Expected purged item to be deleted from disk")
+	}
+
+	SetTrashRetentionDays(-1)
+	if got := TrashRetentionDays(); got != DefaultTrashRetentionDays {
+		t.Errorf("TrashRetentionDays() = %d, want the default", got)
+	}
+}
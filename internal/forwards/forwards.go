@@ -0,0 +1,375 @@
+// Package forwards runs local port forwards to servers started in remote
+// tabs, so a dev server on an SSH host or in WSL can be opened from the
+// browser on this machine. A forward is either an ssh -L tunnel through an
+// SSH host, or a relay Forge runs itself from a local port to a reachable
+// address (a WSL distribution's, when localhost forwarding is off).
+//
+// Forwards last until removed or Forge exits; they are not restored on start.
+// Ports a tab's output says a server is listening on are kept as
+// suggestions, ready to be forwarded with one request.
+package forwards
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
+)
+
+// Forward kinds
+const (
+	KindSSH   = "ssh"   // ssh -L through SSHHost
+	KindRelay = "relay" // Forge relays connections to RemoteHost, or WSLDistro's address
+)
+
+// Forward statuses
+const (
+	StatusActive = "active"
+	StatusFailed = "failed" // The tunnel exited; Error says why
+)
+
+// startTimeout is how long an ssh tunnel may take to start listening
+const startTimeout = 15 * time.Second
+
+// Request describes a forward to create.
+type Request struct {
+	Kind       string `json:"kind"`                 // KindSSH or KindRelay
+	LocalPort  int    `json:"localPort,omitempty"`  // 0 = any free port
+	RemoteHost string `json:"remoteHost,omitempty"` // As seen from the SSH host (default localhost), or the relay target
+	RemotePort int    `json:"remotePort"`
+	SSHHost    string `json:"sshHost,omitempty"`   // KindSSH: host or ~/.ssh/config alias
+	WSLDistro  string `json:"wslDistro,omitempty"` // KindRelay: relay to this distribution when RemoteHost is empty
+	TabID      string `json:"tabId,omitempty"`     // The tab the server runs in, if any
+}
+
+// Forward is a running (or failed) forward.
+type Forward struct {
+	Request
+	ID        string    `json:"id"`
+	URL       string    `json:"url"` // http://localhost:<LocalPort>
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// forward is a Forward with what it takes to stop it
+type forward struct {
+	Forward
+	stop func()
+}
+
+var (
+	mu          sync.Mutex
+	active      = map[string]*forward{}
+	suggestions = map[string][]Request{} // Tab ID -> detected servers, oldest first
+)
+
+// wslAddress returns a WSL distribution's IP address; a variable for tests
+var wslAddress = func(ctx context.Context, distro string) (string, error) {
+	args := []string{"-e", "hostname", "-I"}
+	if distro != "" {
+		args = append([]string{"-d", distro}, args...)
+	}
+	out, err := exec.CommandContext(ctx, "wsl.exe", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the WSL address: %w", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", errors.New("WSL reported no address")
+	}
+	return fields[0], nil
+}
+
+// validate fills in defaults and checks a request
+func validate(req *Request) error {
+	if req.RemotePort < 1 || req.RemotePort > 65535 {
+		return fmt.Errorf("remotePort must be between 1 and 65535, got %d", req.RemotePort)
+	}
+	if req.LocalPort < 0 || req.LocalPort > 65535 {
+		return fmt.Errorf("localPort must be between 0 and 65535, got %d", req.LocalPort)
+	}
+	switch req.Kind {
+	case KindSSH:
+		if req.SSHHost == "" {
+			return errors.New("sshHost is required for an ssh forward")
+		}
+		if req.RemoteHost == "" {
+			req.RemoteHost = "localhost"
+		}
+	case KindRelay:
+		if req.RemoteHost == "" && req.WSLDistro == "" && runtime.GOOS != "windows" {
+			return errors.New("remoteHost is required for a relay")
+		}
+	default:
+		return fmt.Errorf("kind must be %q or %q", KindSSH, KindRelay)
+	}
+	return nil
+}
+
+// Create starts a forward. It returns once the local port is listening, or
+// with the reason the forward couldn't start.
+func Create(req Request) (Forward, error) {
+	if err := validate(&req); err != nil {
+		return Forward{}, err
+	}
+
+	var f *forward
+	var err error
+	if req.Kind == KindSSH {
+		f, err = startSSH(req)
+	} else {
+		f, err = startRelay(req)
+	}
+	if err != nil {
+		return Forward{}, err
+	}
+	f.ID = uuid.New().String()
+	f.URL = fmt.Sprintf("http://localhost:%d", f.LocalPort)
+	f.Status = StatusActive
+	f.CreatedAt = time.Now()
+
+	mu.Lock()
+	active[f.ID] = f
+	mu.Unlock()
+	log.Printf("[Forwards] %s forward %s -> %s:%d started", f.Kind, f.URL, f.RemoteHost, f.RemotePort)
+	return f.Forward, nil
+}
+
+// startSSH runs ssh -N -L and waits for the local port to accept connections
+func startSSH(req Request) (*forward, error) {
+	if req.LocalPort == 0 {
+		port, err := freePort()
+		if err != nil {
+			return nil, err
+		}
+		req.LocalPort = port
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", "-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", fmt.Sprintf("127.0.0.1:%d:%s:%d", req.LocalPort, req.RemoteHost, req.RemotePort),
+		"--", req.SSHHost)
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start ssh: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	local := fmt.Sprintf("127.0.0.1:%d", req.LocalPort)
+	deadline := time.Now().Add(startTimeout)
+	for {
+		select {
+		case err := <-exited:
+			cancel()
+			return nil, sshError(err, &stderr)
+		case <-time.After(200 * time.Millisecond):
+		}
+		if conn, err := net.DialTimeout("tcp", local, time.Second); err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			cancel()
+			<-exited
+			return nil, fmt.Errorf("ssh did not open port %d within %s", req.LocalPort, startTimeout)
+		}
+	}
+
+	f := &forward{Forward: Forward{Request: req}, stop: cancel}
+	go func() {
+		err := <-exited
+		if ctx.Err() != nil {
+			return // Stopped
+		}
+		mu.Lock()
+		f.Status, f.Error = StatusFailed, sshError(err, &stderr).Error()
+		mu.Unlock()
+		log.Printf("[Forwards] ssh forward on port %d exited: %s", req.LocalPort, f.Error)
+	}()
+	return f, nil
+}
+
+func sshError(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("ssh failed: %s", msg)
+	}
+	return fmt.Errorf("ssh failed: %v", err)
+}
+
+// startRelay listens on the local port and copies each connection to the target
+func startRelay(req Request) (*forward, error) {
+	target := req.RemoteHost
+	if target == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		address, err := wslAddress(ctx, req.WSLDistro)
+		if err != nil {
+			return nil, err
+		}
+		target = address
+	}
+	targetAddr := net.JoinHostPort(target, strconv.Itoa(req.RemotePort))
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", req.LocalPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %d: %w", req.LocalPort, err)
+	}
+	req.LocalPort = listener.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return // Closed
+			}
+			go relay(conn, targetAddr)
+		}
+	}()
+	return &forward{Forward: Forward{Request: req}, stop: func() { listener.Close() }}, nil
+}
+
+func relay(conn net.Conn, target string) {
+	defer conn.Close()
+	upstream, err := net.DialTimeout("tcp", target, 5*time.Second)
+	if err != nil {
+		log.Printf("[Forwards] Relay to %s failed: %v", target, err)
+		return
+	}
+	defer upstream.Close()
+
+	done := make(chan struct{}, 2)
+	go func() { io.Copy(upstream, conn); done <- struct{}{} }()
+	go func() { io.Copy(conn, upstream); done <- struct{}{} }()
+	<-done
+}
+
+// freePort returns a local port that is free right now
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// List returns the forwards, oldest first.
+func List() []Forward {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]Forward, 0, len(active))
+	for _, f := range active {
+		list = append(list, f.Forward)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// Remove stops a forward and forgets it.
+func Remove(id string) error {
+	mu.Lock()
+	f, ok := active[id]
+	delete(active, id)
+	mu.Unlock()
+	if !ok {
+		return fmt.Errorf("forward %q not found", id)
+	}
+	f.stop()
+	log.Printf("[Forwards] Forward on port %d removed", f.LocalPort)
+	return nil
+}
+
+// StopAll stops every forward, when Forge shuts down.
+func StopAll() {
+	mu.Lock()
+	stopping := active
+	active = map[string]*forward{}
+	mu.Unlock()
+	for _, f := range stopping {
+		f.stop()
+	}
+}
+
+// Suggest records a server a tab's output says is listening, and raises a
+// notification offering to forward it. Repeats are ignored.
+func Suggest(req Request) {
+	if validate(&req) != nil {
+		return
+	}
+	mu.Lock()
+	for _, existing := range suggestions[req.TabID] {
+		if existing.RemotePort == req.RemotePort {
+			mu.Unlock()
+			return
+		}
+	}
+	for _, f := range active {
+		if f.TabID == req.TabID && f.RemotePort == req.RemotePort {
+			mu.Unlock()
+			return
+		}
+	}
+	suggestions[req.TabID] = append(suggestions[req.TabID], req)
+	mu.Unlock()
+
+	where := req.SSHHost
+	if req.Kind == KindRelay {
+		where = "WSL"
+	}
+	_, err := notify.Publish(notify.Notification{
+		Kind:  notify.KindPort,
+		Key:   fmt.Sprintf("port:%s:%d", req.TabID, req.RemotePort),
+		Title: fmt.Sprintf("Server listening on port %d in %s", req.RemotePort, where),
+		Body:  "Forward it to open it in your browser.",
+		TabID: req.TabID,
+		Data:  map[string]interface{}{"forward": req},
+	})
+	if err != nil {
+		log.Printf("[Forwards] Failed to add port notification: %v", err)
+	}
+}
+
+// Suggestions returns the detected servers not yet forwarded, per tab.
+func Suggestions() map[string][]Request {
+	mu.Lock()
+	defer mu.Unlock()
+	forwarded := map[string]bool{}
+	for _, f := range active {
+		forwarded[fmt.Sprintf("%s:%d", f.TabID, f.RemotePort)] = true
+	}
+	result := map[string][]Request{}
+	for tabID, list := range suggestions {
+		for _, req := range list {
+			if !forwarded[fmt.Sprintf("%s:%d", tabID, req.RemotePort)] {
+				result[tabID] = append(result[tabID], req)
+			}
+		}
+	}
+	return result
+}
+
+// ForgetTab drops a closed tab's suggestions. Its forwards keep running.
+func ForgetTab(tabID string) {
+	mu.Lock()
+	delete(suggestions, tabID)
+	mu.Unlock()
+}
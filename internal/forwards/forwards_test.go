@@ -0,0 +1,142 @@
+package forwards
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
+)
+
+func reset(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() {
+		StopAll()
+		mu.Lock()
+		suggestions = map[string][]Request{}
+		mu.Unlock()
+	})
+}
+
+// echoServer answers each line with "echo: <line>"
+func echoServer(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprintf(conn, "echo: %s", line)
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestRelay(t *testing.T) {
+	reset(t)
+	target := echoServer(t)
+
+	forward, err := Create(Request{Kind: KindRelay, RemoteHost: "127.0.0.1", RemotePort: target})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if forward.LocalPort == 0 || forward.Status != StatusActive || forward.URL != fmt.Sprintf("http://localhost:%d", forward.LocalPort) {
+		t.Fatalf("Unexpected forward %+v", forward)
+	}
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", forward.LocalPort))
+	if err != nil {
+		t.Fatalf("Failed to connect to the relay: %v", err)
+	}
+	fmt.Fprintln(conn, "hello")
+	reply, _ := bufio.NewReader(conn).ReadString('\n')
+	conn.Close()
+	if reply != "echo: hello\n" {
+		t.Errorf("Expected the target's reply, got %q", reply)
+	}
+
+	if list := List(); len(list) != 1 || list[0].ID != forward.ID {
+		t.Errorf("Expected the forward listed, got %+v", list)
+	}
+	if err := Remove(forward.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", forward.LocalPort)); err == nil {
+		t.Error("Expected the relay to stop listening")
+	}
+	if err := Remove(forward.ID); err == nil {
+		t.Error("Expected removing twice to fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{Kind: KindSSH, RemotePort: 3000}, "sshHost is required"},
+		{Request{Kind: KindSSH, SSHHost: "dev", RemotePort: 0}, "remotePort"},
+		{Request{Kind: "socks", RemotePort: 3000}, "kind must be"},
+		{Request{Kind: KindRelay, RemoteHost: "10.0.0.2", RemotePort: 80, LocalPort: 70000}, "localPort"},
+	}
+	for _, tt := range tests {
+		if err := validate(&tt.req); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("validate(%+v) = %v, want %q", tt.req, err, tt.want)
+		}
+	}
+
+	req := Request{Kind: KindSSH, SSHHost: "dev", RemotePort: 3000}
+	if err := validate(&req); err != nil || req.RemoteHost != "localhost" {
+		t.Errorf("Expected localhost as the default remote host, got %+v (%v)", req, err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	reset(t)
+	req := Request{Kind: KindSSH, SSHHost: "dev", LocalPort: 3000, RemotePort: 3000, TabID: "tab-1"}
+	Suggest(req)
+	Suggest(req)
+
+	if got := Suggestions()["tab-1"]; len(got) != 1 || got[0].RemotePort != 3000 {
+		t.Fatalf("Expected one suggestion, got %+v", got)
+	}
+	list, err := notify.List(false)
+	if err != nil || len(list) != 1 || list[0].Kind != notify.KindPort || list[0].TabID != "tab-1" {
+		t.Errorf("Expected one port notification, got %+v (%v)", list, err)
+	}
+
+	ForgetTab("tab-1")
+	if got := Suggestions(); len(got) != 0 {
+		t.Errorf("Expected the tab's suggestions forgotten, got %+v", got)
+	}
+}
+
+func TestHandleForwards(t *testing.T) {
+	reset(t)
+
+	rec := httptest.NewRecorder()
+	HandleForwards(rec, httptest.NewRequest("POST", "/api/v1/forwards", strings.NewReader(`{"kind":"ssh","remotePort":3000}`)))
+	if rec.Code != 400 {
+		t.Errorf("Expected 400 for a missing sshHost, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleForwards(rec, httptest.NewRequest("DELETE", "/api/v1/forwards?id=nope", nil))
+	if rec.Code != 404 {
+		t.Errorf("Expected 404 for an unknown forward, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package forwards
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// HandleForwards lists forwards and suggested servers (GET), creates a
+// forward (POST with a Request), or removes one (DELETE ?id=).
+func HandleForwards(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"forwards":    List(),
+			"suggestions": Suggestions(),
+		})
+
+	case http.MethodPost:
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validate(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		forward, err := Create(req)
+		if err != nil {
+			log.Printf("[Forwards] Failed to forward port %d: %v", req.RemotePort, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(forward)
+
+	case http.MethodDelete:
+		if err := Remove(r.URL.Query().Get("id")); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
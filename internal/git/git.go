@@ -0,0 +1,430 @@
+// Package git provides repository inspection by shelling out to the git CLI.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandTimeout bounds how long a single git invocation may run.
+const commandTimeout = 10 * time.Second
+
+// FileStatus represents the status of a single path in the working tree.
+type FileStatus struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"origPath,omitempty"` // Source path for renames/copies
+	Index    string `json:"index"`              // Staged status code (X)
+	WorkTree string `json:"workTree"`           // Unstaged status code (Y)
+	Status   string `json:"status"`             // "modified", "added", "deleted", "renamed", "untracked", "conflicted", ...
+}
+
+// Status represents the result of `git status` for a repository.
+type Status struct {
+	IsRepo   bool         `json:"isRepo"`
+	Root     string       `json:"root"`
+	Branch   string       `json:"branch"`
+	Upstream string       `json:"upstream,omitempty"`
+	Ahead    int          `json:"ahead"`
+	Behind   int          `json:"behind"`
+	Clean    bool         `json:"clean"`
+	Files    []FileStatus `json:"files"`
+}
+
+// Commit represents a single entry from `git log`.
+type Commit struct {
+	Hash      string `json:"hash"`
+	ShortHash string `json:"shortHash"`
+	Author    string `json:"author"`
+	Email     string `json:"email"`
+	Timestamp int64  `json:"timestamp"`
+	Subject   string `json:"subject"`
+}
+
+// Branches represents the current branch and all local branches.
+type Branches struct {
+	Current  string   `json:"current"`
+	Detached bool     `json:"detached"`
+	Local    []string `json:"local"`
+}
+
+// run executes git with the given arguments in dir and returns stdout.
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Keep output stable and machine-readable regardless of user config
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// ErrPathNotAllowed is returned for directories outside the allowed roots
+var ErrPathNotAllowed = errors.New("path is outside the allowed directories")
+
+var (
+	pathFilterMu sync.RWMutex
+	pathFilter   func(absPath string) bool // Nil allows any directory
+)
+
+// SetPathFilter sets which directories git may be run in, so the file API's
+// allowed roots (files.PathAllowed) apply to repositories too. Call before
+// serving.
+func SetPathFilter(allowed func(absPath string) bool) {
+	pathFilterMu.Lock()
+	pathFilter = allowed
+	pathFilterMu.Unlock()
+}
+
+// ResolveWorkDir converts a client-supplied path into a directory git can run in.
+// Files resolve to their parent directory. Directories the path filter
+// rejects are ErrPathNotAllowed.
+func ResolveWorkDir(p string) (string, error) {
+	if p == "" {
+		p = "."
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		abs = filepath.Dir(abs)
+	}
+	pathFilterMu.RLock()
+	allowed := pathFilter
+	pathFilterMu.RUnlock()
+	if allowed != nil && !allowed(abs) {
+		return "", ErrPathNotAllowed
+	}
+	return abs, nil
+}
+
+// RepoRoot returns the top-level directory of the repository containing dir.
+func RepoRoot(ctx context.Context, dir string) (string, error) {
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(out)), nil
+}
+
+// GetStatus returns the branch and working tree status for the repository containing dir.
+func GetStatus(ctx context.Context, dir string) (*Status, error) {
+	root, err := RepoRoot(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := run(ctx, root, "status", "--porcelain=v1", "--branch", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	status := parseStatus(out)
+	status.IsRepo = true
+	status.Root = root
+	return status, nil
+}
+
+// parseStatus parses NUL-separated `git status --porcelain=v1 --branch -z` output.
+func parseStatus(out string) *Status {
+	status := &Status{Files: []FileStatus{}}
+
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 3 {
+			continue
+		}
+
+		if strings.HasPrefix(entry, "## ") {
+			parseBranchHeader(entry[3:], status)
+			continue
+		}
+
+		x, y := entry[0:1], entry[1:2]
+		file := FileStatus{
+			Path:     entry[3:],
+			Index:    x,
+			WorkTree: y,
+			Status:   describeStatus(x, y),
+		}
+
+		// Renames and copies are followed by the original path as a separate entry
+		if (x == "R" || x == "C") && i+1 < len(entries) {
+			file.OrigPath = entries[i+1]
+			i++
+		}
+
+		status.Files = append(status.Files, file)
+	}
+
+	status.Clean = len(status.Files) == 0
+	return status
+}
+
+// parseBranchHeader parses the "## branch...upstream [ahead N, behind M]" line.
+func parseBranchHeader(header string, status *Status) {
+	// Fresh repository: "No commits yet on main"
+	if strings.HasPrefix(header, "No commits yet on ") {
+		status.Branch = strings.TrimPrefix(header, "No commits yet on ")
+		return
+	}
+
+	tracking := ""
+	if idx := strings.Index(header, " ["); idx >= 0 {
+		tracking = strings.TrimSuffix(header[idx+2:], "]")
+		header = header[:idx]
+	}
+
+	if idx := strings.Index(header, "..."); idx >= 0 {
+		status.Branch = header[:idx]
+		status.Upstream = header[idx+3:]
+	} else {
+		status.Branch = header
+	}
+
+	for _, part := range strings.Split(tracking, ", ") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			continue
+		}
+		n, _ := strconv.Atoi(fields[1])
+		switch fields[0] {
+		case "ahead":
+			status.Ahead = n
+		case "behind":
+			status.Behind = n
+		}
+	}
+}
+
+// describeStatus maps porcelain XY codes to a single human-readable status.
+func describeStatus(x, y string) string {
+	switch {
+	case x == "?" && y == "?":
+		return "untracked"
+	case x == "!" && y == "!":
+		return "ignored"
+	case x == "U" || y == "U" || (x == "A" && y == "A") || (x == "D" && y == "D"):
+		return "conflicted"
+	case x == "R":
+		return "renamed"
+	case x == "C":
+		return "copied"
+	case x == "A":
+		return "added"
+	case x == "D" || y == "D":
+		return "deleted"
+	default:
+		return "modified"
+	}
+}
+
+// GetDiff returns the unified diff for the repository containing dir.
+// If staged is true, the index is compared against HEAD instead of the working tree.
+// An optional path limits the diff to a single file or directory.
+func GetDiff(ctx context.Context, dir, path string, staged bool) (string, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	return run(ctx, dir, args...)
+}
+
+// logFieldSep and logRecordSep delimit fields and records in `git log` output.
+const (
+	logFieldSep  = "\x1f"
+	logRecordSep = "\x1e"
+)
+
+// GetLog returns up to limit commits, optionally restricted to a path.
+func GetLog(ctx context.Context, dir, path string, limit int) ([]Commit, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	format := strings.Join([]string{"%H", "%h", "%an", "%ae", "%at", "%s"}, logFieldSep) + logRecordSep
+	args := []string{"log", fmt.Sprintf("-n%d", limit), "--pretty=format:" + format}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+
+	out, err := run(ctx, dir, args...)
+	if err != nil {
+		// A repository without commits has no log - not an error for callers
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return []Commit{}, nil
+		}
+		return nil, err
+	}
+
+	return parseLog(out), nil
+}
+
+// ChangedFiles returns the paths touched by commits made between since and
+// until, relative to the repository root, without duplicates.
+func ChangedFiles(ctx context.Context, dir string, since, until time.Time) ([]string, error) {
+	out, err := run(ctx, dir, "log", "--name-only", "--pretty=format:",
+		"--since="+since.Format(time.RFC3339), "--until="+until.Format(time.RFC3339))
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	files := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !seen[line] {
+			seen[line] = true
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// parseLog parses `git log` output produced with logFieldSep/logRecordSep delimiters.
+func parseLog(out string) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, logRecordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, logFieldSep)
+		if len(fields) != 6 {
+			continue
+		}
+		ts, _ := strconv.ParseInt(fields[4], 10, 64)
+		commits = append(commits, Commit{
+			Hash:      fields[0],
+			ShortHash: fields[1],
+			Author:    fields[2],
+			Email:     fields[3],
+			Timestamp: ts,
+			Subject:   fields[5],
+		})
+	}
+	return commits
+}
+
+// GetBranches returns the current branch and the list of local branches.
+func GetBranches(ctx context.Context, dir string) (*Branches, error) {
+	branches := &Branches{Local: []string{}}
+
+	current, err := run(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		// Detached HEAD - report the short commit hash instead
+		sha, shaErr := run(ctx, dir, "rev-parse", "--short", "HEAD")
+		if shaErr != nil {
+			return nil, err
+		}
+		branches.Current = strings.TrimSpace(sha)
+		branches.Detached = true
+	} else {
+		branches.Current = strings.TrimSpace(current)
+	}
+
+	out, err := run(ctx, dir, "branch", "--format=%(refname:short)")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			branches.Local = append(branches.Local, line)
+		}
+	}
+
+	return branches, nil
+}
+
+// Branch is a local branch with the time of its latest commit.
+type Branch struct {
+	Name        string    `json:"name"`
+	CommittedAt time.Time `json:"committedAt"`
+}
+
+// RecentBranches returns the local branches, most recently committed to first.
+func RecentBranches(ctx context.Context, dir string) ([]Branch, error) {
+	out, err := run(ctx, dir, "for-each-ref", "--sort=-committerdate",
+		"--format=%(refname:short)"+logFieldSep+"%(committerdate:unix)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return parseBranches(out), nil
+}
+
+// parseBranches parses `git for-each-ref` output of name and commit time
+func parseBranches(out string) []Branch {
+	branches := []Branch{}
+	for _, line := range strings.Split(out, "\n") {
+		name, unix, ok := strings.Cut(strings.TrimSpace(line), logFieldSep)
+		if !ok || name == "" {
+			continue
+		}
+		ts, _ := strconv.ParseInt(unix, 10, 64)
+		branches = append(branches, Branch{Name: name, CommittedAt: time.Unix(ts, 0)})
+	}
+	return branches
+}
+
+// GetPathStatus returns the status of a single file or directory: one of the
+// describeStatus values, "clean" if unchanged, or an error if not in a repository.
+// Directories with any changes beneath them report "modified".
+func GetPathStatus(ctx context.Context, absPath string) (string, error) {
+	dir, err := ResolveWorkDir(absPath)
+	if err != nil {
+		return "", err
+	}
+	root, err := RepoRoot(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := run(ctx, root, "status", "--porcelain=v1", "-z", "--untracked-files=all", "--ignored=matching", "--", absPath)
+	if err != nil {
+		return "", err
+	}
+
+	files := parseStatus(out).Files
+	switch len(files) {
+	case 0:
+		return "clean", nil
+	case 1:
+		rel, _ := filepath.Rel(root, absPath)
+		if files[0].Path == filepath.ToSlash(rel) {
+			return files[0].Status, nil
+		}
+	}
+	return "modified", nil
+}
@@ -0,0 +1,217 @@
+package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatus(t *testing.T) {
+	out := "## main...origin/main [ahead 2, behind 1]\x00" +
+		" M cmd/forge/main.go\x00" +
+		"A  internal/git/git.go\x00" +
+		"R  new.go\x00old.go\x00" +
+		"?? notes.txt\x00" +
+		"UU conflict.go\x00"
+
+	status := parseStatus(out)
+
+	if status.Branch != "main" {
+		t.Errorf("Expected branch 'main', got '%s'", status.Branch)
+	}
+	if status.Upstream != "origin/main" {
+		t.Errorf("Expected upstream 'origin/main', got '%s'", status.Upstream)
+	}
+	if status.Ahead != 2 || status.Behind != 1 {
+		t.Errorf("Expected ahead=2 behind=1, got ahead=%d behind=%d", status.Ahead, status.Behind)
+	}
+	if len(status.Files) != 5 {
+		t.Fatalf("Expected 5 files, got %d", len(status.Files))
+	}
+
+	expected := []struct {
+		path   string
+		status string
+	}{
+		{"cmd/forge/main.go", "modified"},
+		{"internal/git/git.go", "added"},
+		{"new.go", "renamed"},
+		{"notes.txt", "untracked"},
+		{"conflict.go", "conflicted"},
+	}
+	for i, exp := range expected {
+		if status.Files[i].Path != exp.path || status.Files[i].Status != exp.status {
+			t.Errorf("File %d: expected %s (%s), got %s (%s)",
+				i, exp.path, exp.status, status.Files[i].Path, status.Files[i].Status)
+		}
+	}
+	if status.Files[2].OrigPath != "old.go" {
+		t.Errorf("Expected rename origPath 'old.go', got '%s'", status.Files[2].OrigPath)
+	}
+	if status.Clean {
+		t.Error("Expected status to be dirty")
+	}
+}
+
+func TestParseStatus_NoCommits(t *testing.T) {
+	status := parseStatus("## No commits yet on main\x00")
+	if status.Branch != "main" {
+		t.Errorf("Expected branch 'main', got '%s'", status.Branch)
+	}
+	if !status.Clean {
+		t.Error("Expected clean status")
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	out := "abc123" + logFieldSep + "abc" + logFieldSep + "Jane" + logFieldSep + "jane@example.com" +
+		logFieldSep + "1700000000" + logFieldSep + "Initial commit" + logRecordSep + "\n" +
+		"def456" + logFieldSep + "def" + logFieldSep + "John" + logFieldSep + "john@example.com" +
+		logFieldSep + "1700000100" + logFieldSep + "Second commit" + logRecordSep
+
+	commits := parseLog(out)
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %d", len(commits))
+	}
+	if commits[1].Hash != "def456" || commits[1].Subject != "Second commit" || commits[1].Timestamp != 1700000100 {
+		t.Errorf("Unexpected second commit: %+v", commits[1])
+	}
+}
+
+func TestRepositoryOperations(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	gitCmd("init", "-q", "-b", "main")
+	filePath := filepath.Join(dir, "hello.txt")
+	os.WriteFile(filePath, []byte("hello\n"), 0644)
+	gitCmd("add", "hello.txt")
+	gitCmd("commit", "-q", "-m", "Add hello")
+	os.WriteFile(filePath, []byte("hello world\n"), 0644)
+
+	ctx := context.Background()
+
+	// A file path should resolve to its parent directory
+	workDir, err := ResolveWorkDir(filePath)
+	if err != nil {
+		t.Fatalf("ResolveWorkDir failed: %v", err)
+	}
+
+	status, err := GetStatus(ctx, workDir)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if !status.IsRepo || status.Branch != "main" {
+		t.Errorf("Expected repo on branch main, got isRepo=%v branch=%s", status.IsRepo, status.Branch)
+	}
+	if len(status.Files) != 1 || status.Files[0].Status != "modified" {
+		t.Errorf("Expected one modified file, got %+v", status.Files)
+	}
+
+	diff, err := GetDiff(ctx, workDir, "hello.txt", false)
+	if err != nil {
+		t.Fatalf("GetDiff failed: %v", err)
+	}
+	if diff == "" {
+		t.Error("Expected non-empty diff")
+	}
+
+	commits, err := GetLog(ctx, workDir, "", 10)
+	if err != nil {
+		t.Fatalf("GetLog failed: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "Add hello" {
+		t.Errorf("Expected single 'Add hello' commit, got %+v", commits)
+	}
+
+	changed, err := ChangedFiles(ctx, workDir, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ChangedFiles failed: %v", err)
+	}
+	if len(changed) != 1 || changed[0] != "hello.txt" {
+		t.Errorf("Expected hello.txt changed, got %q", changed)
+	}
+	if changed, _ := ChangedFiles(ctx, workDir, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)); len(changed) != 0 {
+		t.Errorf("Expected nothing changed later on, got %q", changed)
+	}
+
+	pathStatus, err := GetPathStatus(ctx, filePath)
+	if err != nil {
+		t.Fatalf("GetPathStatus failed: %v", err)
+	}
+	if pathStatus != "modified" {
+		t.Errorf("Expected path status 'modified', got '%s'", pathStatus)
+	}
+
+	os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("clean\n"), 0644)
+	gitCmd("add", "clean.txt")
+	gitCmd("commit", "-q", "-m", "Add clean")
+	if pathStatus, _ := GetPathStatus(ctx, filepath.Join(dir, "clean.txt")); pathStatus != "clean" {
+		t.Errorf("Expected path status 'clean', got '%s'", pathStatus)
+	}
+
+	branches, err := GetBranches(ctx, workDir)
+	if err != nil {
+		t.Fatalf("GetBranches failed: %v", err)
+	}
+	if branches.Current != "main" || len(branches.Local) != 1 {
+		t.Errorf("Unexpected branches: %+v", branches)
+	}
+
+	recent, err := RecentBranches(ctx, workDir)
+	if err != nil {
+		t.Fatalf("RecentBranches failed: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Name != "main" || recent[0].CommittedAt.IsZero() {
+		t.Errorf("Unexpected recent branches: %+v", recent)
+	}
+}
+
+func TestGetStatus_NotARepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	if _, err := GetStatus(context.Background(), t.TempDir()); err == nil {
+		t.Error("Expected error for directory outside a repository")
+	}
+}
+
+func TestHandlers_PathFilter(t *testing.T) {
+	allowed, outside := t.TempDir(), t.TempDir()
+	SetPathFilter(func(absPath string) bool { return strings.HasPrefix(absPath, allowed) })
+	defer SetPathFilter(nil)
+
+	for _, handler := range []http.HandlerFunc{HandleStatus, HandleDiff, HandleLog, HandleBranch} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest("GET", "/api/git/x?path="+url.QueryEscape(outside), nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Expected 403 outside the allowed roots, got %d", rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	HandleStatus(rec, httptest.NewRequest("GET", "/api/git/status?path="+url.QueryEscape(allowed), nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected an allowed path served, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,133 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// requestDir resolves a request's path to the directory to run git in,
+// answering the request itself when it can't: 403 outside the allowed roots,
+// 400 for other bad paths.
+func requestDir(w http.ResponseWriter, path string) (string, bool) {
+	dir, err := ResolveWorkDir(path)
+	switch {
+	case errors.Is(err, ErrPathNotAllowed):
+		http.Error(w, "Access denied: Path is outside allowed directory", http.StatusForbidden)
+		return "", false
+	case err != nil:
+		http.Error(w, "Invalid path: "+err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return dir, true
+}
+
+// HandleStatus returns branch and working tree status for the repository containing ?path=
+func HandleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dir, ok := requestDir(w, r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	status, err := GetStatus(r.Context(), dir)
+	if err != nil {
+		// Not being inside a repository is a normal state for the file tree
+		log.Printf("[Git] Status unavailable for %s: %v", dir, err)
+		json.NewEncoder(w).Encode(&Status{IsRepo: false, Files: []FileStatus{}})
+		return
+	}
+
+	json.NewEncoder(w).Encode(status)
+}
+
+// HandleDiff returns the unified diff for ?path= (optionally &file= and &staged=true)
+func HandleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	dir, ok := requestDir(w, query.Get("path"))
+	if !ok {
+		return
+	}
+
+	staged := query.Get("staged") == "true"
+	diff, err := GetDiff(r.Context(), dir, query.Get("file"), staged)
+	if err != nil {
+		log.Printf("[Git] Diff failed for %s: %v", dir, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"diff":   diff,
+		"staged": staged,
+	})
+}
+
+// HandleLog returns recent commits for ?path= (optionally &file= and &limit=N)
+func HandleLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	dir, ok := requestDir(w, query.Get("path"))
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit > 500 {
+		limit = 500
+	}
+
+	commits, err := GetLog(r.Context(), dir, query.Get("file"), limit)
+	if err != nil {
+		log.Printf("[Git] Log failed for %s: %v", dir, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"commits": commits,
+		"count":   len(commits),
+	})
+}
+
+// HandleBranch returns the current branch and local branches for ?path=
+func HandleBranch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dir, ok := requestDir(w, r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	branches, err := GetBranches(r.Context(), dir)
+	if err != nil {
+		log.Printf("[Git] Branch lookup failed for %s: %v", dir, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(branches)
+}
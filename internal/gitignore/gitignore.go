@@ -0,0 +1,261 @@
+// Package gitignore implements .gitignore matching compatible with git's rules,
+// including nested .gitignore files, negation, directory-only and ** patterns.
+package gitignore
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// rule is a single parsed line from a .gitignore file.
+type rule struct {
+	pattern  string // Slash-separated glob, leading/trailing slashes removed
+	base     string // Directory containing the .gitignore, relative to the matcher root ("" for root)
+	negate   bool   // Pattern started with "!"
+	dirOnly  bool   // Pattern ended with "/"
+	anchored bool   // Pattern contained a "/" and only matches relative to base
+}
+
+// Matcher evaluates paths against every .gitignore between the repository root and the path.
+// Nested .gitignore files are loaded lazily and cached; a Matcher is safe for concurrent use.
+type Matcher struct {
+	root    string
+	exclude []rule // Rules from .git/info/exclude, lowest precedence
+
+	mu      sync.Mutex
+	rules   map[string][]rule // Rules from each directory's .gitignore, keyed by relative dir
+	ignored map[string]bool   // Cached results for directories, keyed by relative path
+}
+
+// New creates a Matcher for dir. If dir is inside a git repository the repository
+// root is used so that .gitignore files in parent directories also apply.
+func New(dir string) *Matcher {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+
+	m := &Matcher{
+		root:    findRepoRoot(abs),
+		rules:   make(map[string][]rule),
+		ignored: make(map[string]bool),
+	}
+
+	// .git/info/exclude applies like a root .gitignore with lower precedence
+	if data, err := os.ReadFile(filepath.Join(m.root, ".git", "info", "exclude")); err == nil {
+		m.exclude = parseRules(data, "")
+	}
+	return m
+}
+
+// Root returns the directory that patterns are evaluated relative to.
+func (m *Matcher) Root() string {
+	return m.root
+}
+
+// findRepoRoot walks up from dir looking for a .git entry, falling back to dir itself.
+func findRepoRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// Match reports whether absPath is ignored. A path is also ignored when any of
+// its parent directories is ignored, since git never descends into those.
+func (m *Matcher) Match(absPath string, isDir bool) bool {
+	rel, err := filepath.Rel(m.root, absPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	parts := strings.Split(rel, "/")
+	if parts[0] == ".git" {
+		return true
+	}
+
+	for i := 1; i < len(parts); i++ {
+		if m.matchDir(strings.Join(parts[:i], "/")) {
+			return true
+		}
+	}
+	if isDir {
+		return m.matchDir(rel)
+	}
+	return m.matchPath(rel, false)
+}
+
+// matchDir evaluates a directory, caching the result since every path below it re-checks it.
+func (m *Matcher) matchDir(rel string) bool {
+	m.mu.Lock()
+	ignored, ok := m.ignored[rel]
+	m.mu.Unlock()
+	if ok {
+		return ignored
+	}
+
+	ignored = m.matchPath(rel, true)
+	m.mu.Lock()
+	m.ignored[rel] = ignored
+	m.mu.Unlock()
+	return ignored
+}
+
+// matchPath evaluates rules from all applicable .gitignore files for a single path
+// without considering whether its parents are ignored. The last matching rule wins.
+func (m *Matcher) matchPath(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.exclude {
+		if r.matches(rel, isDir) {
+			ignored = !r.negate
+		}
+	}
+
+	dir := ""
+	for {
+		for _, r := range m.rulesFor(dir) {
+			if r.matches(rel, isDir) {
+				ignored = !r.negate
+			}
+		}
+
+		remaining := strings.TrimPrefix(rel, dir)
+		remaining = strings.TrimPrefix(remaining, "/")
+		idx := strings.Index(remaining, "/")
+		if idx < 0 {
+			return ignored
+		}
+		if dir == "" {
+			dir = remaining[:idx]
+		} else {
+			dir = dir + "/" + remaining[:idx]
+		}
+	}
+}
+
+// rulesFor returns the rules declared in the .gitignore of the relative directory dir.
+func (m *Matcher) rulesFor(dir string) []rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	var rules []rule
+	if data, err := os.ReadFile(filepath.Join(m.root, filepath.FromSlash(dir), ".gitignore")); err == nil {
+		rules = parseRules(data, dir)
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// parseRules parses .gitignore content declared in the relative directory base.
+func parseRules(data []byte, base string) []rule {
+	var rules []rule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if r, ok := parseRule(scanner.Text(), base); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseRule parses a single .gitignore line. Blank lines and comments yield ok=false.
+func parseRule(line, base string) (rule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped with a backslash
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	r.pattern = line
+	return r, true
+}
+
+// matches reports whether the rule applies to rel, a path relative to the matcher root.
+func (r rule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+
+	if r.anchored {
+		return matchGlob(r.pattern, rel)
+	}
+	return matchGlob(r.pattern, path.Base(rel))
+}
+
+// matchGlob matches a slash-separated path against a glob where "**" spans
+// any number of path segments and other wildcards stay within one segment.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
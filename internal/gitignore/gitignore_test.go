@@ -0,0 +1,126 @@
+package gitignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.log", "debug.log", true},
+		{"*.log", "debug.txt", false},
+		{"build/*.o", "build/main.o", true},
+		{"build/*.o", "build/sub/main.o", false},
+		{"**/foo", "foo", true},
+		{"**/foo", "a/b/foo", true},
+		{"abc/**", "abc/x/y", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/c", false},
+		{"debug?.log", "debug1.log", true},
+		{"[Dd]ist", "Dist", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+		want rule
+	}{
+		{"", false, rule{}},
+		{"# comment", false, rule{}},
+		{"*.log", true, rule{pattern: "*.log"}},
+		{"!keep.log", true, rule{pattern: "keep.log", negate: true}},
+		{"node_modules/", true, rule{pattern: "node_modules", dirOnly: true}},
+		{"/dist", true, rule{pattern: "dist", anchored: true}},
+		{"docs/*.md", true, rule{pattern: "docs/*.md", anchored: true}},
+		{"\\#file", true, rule{pattern: "#file"}},
+		{"trailing   ", true, rule{pattern: "trailing"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRule(tt.line, "")
+		if ok != tt.ok {
+			t.Errorf("parseRule(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("parseRule(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	writeFile(t, filepath.Join(root, ".gitignore"), "*.log\n!important.log\nnode_modules/\n/dist\n")
+	writeFile(t, filepath.Join(root, "pkg", ".gitignore"), "generated/\n!*.log\n")
+	writeFile(t, filepath.Join(root, ".git", "info", "exclude"), "secret.txt\n")
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{"debug.log", false, true},
+		{"important.log", false, false},
+		{"node_modules", true, true},
+		{"node_modules/lib/index.js", false, true},
+		{"src/node_modules", true, true},
+		{"node_modules", false, false}, // dir-only pattern doesn't match files
+		{"dist", true, true},
+		{"src/dist", true, false}, // anchored to root
+		{"pkg/generated", true, true},
+		{"pkg/generated/file.go", false, true},
+		{"generated", true, false},      // nested rule only applies below pkg/
+		{"pkg/trace.log", false, false}, // nested negation overrides root rule
+		{"secret.txt", false, true},
+		{".git/config", false, true},
+	}
+
+	m := New(root)
+	for _, tt := range tests {
+		if got := m.Match(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, isDir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestMatcher_UsesRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	writeFile(t, filepath.Join(root, ".gitignore"), "*.tmp\n")
+	sub := filepath.Join(root, "sub")
+	os.Mkdir(sub, 0755)
+
+	m := New(sub)
+	if m.Root() != root {
+		t.Errorf("Expected root %s, got %s", root, m.Root())
+	}
+	if !m.Match(filepath.Join(sub, "scratch.tmp"), false) {
+		t.Error("Expected parent .gitignore to apply to subdirectory")
+	}
+}
@@ -0,0 +1,218 @@
+// Package guard checks input Forge types into terminals on the user's behalf
+// (command cards, chains, snippets, assistant commands) against rules for
+// dangerous commands before it reaches the shell. A matching rule either
+// blocks the input or asks the caller to confirm it.
+//
+// Rules are regular expressions matched against each line of the input.
+// The defaults cover the usual foot-guns; configured rules are added to them,
+// and a configured rule with a default's name replaces it (action "allow"
+// turns it off).
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Rule actions, from least to most strict
+const (
+	Allow   = "allow"
+	Confirm = "confirm"
+	Block   = "block"
+)
+
+// Rule flags dangerous input.
+type Rule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`          // Regular expression matched against each line
+	Action  string `json:"action,omitempty"` // Confirm (default), Block, or Allow
+	Reason  string `json:"reason,omitempty"` // Shown when the rule matches
+}
+
+// Decision is the outcome of checking input.
+type Decision struct {
+	Action  string `json:"action"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Command string `json:"command,omitempty"` // The line that matched
+}
+
+// DefaultRules are always checked unless a configured rule of the same name
+// replaces them.
+var DefaultRules = []Rule{
+	{
+		Name:    "rm-root",
+		Pattern: `\brm\s+(?:-\S+\s+)*-(?:[a-zA-Z]*[rR][a-zA-Z]*|-recursive)\s+(?:-\S+\s+)*(?:--\s+)?(?:/|/\*|~/?|\$HOME/?)(?:\s|$|[;&|])`,
+		Action:  Block,
+		Reason:  "Recursively deletes the root or home directory",
+	},
+	{
+		Name:    "fork-bomb",
+		Pattern: `:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`,
+		Action:  Block,
+		Reason:  "Fork bomb",
+	},
+	{
+		Name:    "dd-write",
+		Pattern: `\bdd\b[^;&|]*\bof=`,
+		Action:  Confirm,
+		Reason:  "dd overwrites its output file or device",
+	},
+	{
+		Name:    "disk-overwrite",
+		Pattern: `>\s*/dev/(?:sd[a-z]|hd[a-z]|vd[a-z]|nvme\d|mmcblk\d|disk\d)`,
+		Action:  Confirm,
+		Reason:  "Writes directly to a disk device",
+	},
+	{
+		Name:    "mkfs",
+		Pattern: `\bmkfs(?:\.\w+)?\s`,
+		Action:  Confirm,
+		Reason:  "Formats a filesystem",
+	},
+	{
+		Name:    "format-drive",
+		Pattern: `(?i)\bformat(?:\.com)?\s+[a-z]:`,
+		Action:  Confirm,
+		Reason:  "Formats a drive",
+	},
+	{
+		Name:    "git-force-push",
+		Pattern: `\bgit\b[^;&|]*\bpush\b[^;&|]*\s(?:--force|-f)(?:\s|$|[;&|])`,
+		Action:  Confirm,
+		Reason:  "Force pushes can discard commits on the remote",
+	},
+	{
+		Name:    "chmod-root",
+		Pattern: `\bchmod\s+(?:-\S+\s+)*-[a-zA-Z]*R[a-zA-Z]*\s+(?:-\S+\s+)*\S+\s+/(?:\s|$|[;&|])`,
+		Action:  Confirm,
+		Reason:  "Recursively changes permissions from the root directory",
+	},
+}
+
+// compiledRule is a rule with its pattern compiled
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+var (
+	mu    sync.RWMutex
+	rules = mustCompile(DefaultRules)
+)
+
+// Validate checks a rule's name, action, and pattern.
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("rule name is required")
+	}
+	switch r.Action {
+	case "", Allow, Confirm, Block:
+	default:
+		return fmt.Errorf("rule %q: unknown action %q (expected allow, confirm, or block)", r.Name, r.Action)
+	}
+	if r.Action != Allow && r.Pattern == "" {
+		return fmt.Errorf("rule %q: pattern is required", r.Name)
+	}
+	if _, err := regexp.Compile(r.Pattern); err != nil {
+		return fmt.Errorf("rule %q: invalid pattern: %w", r.Name, err)
+	}
+	return nil
+}
+
+// Configure validates configured rules and applies them on top of the defaults.
+func Configure(configured []Rule) error {
+	for _, rule := range configured {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	compiled := mustCompile(merge(configured))
+	mu.Lock()
+	rules = compiled
+	mu.Unlock()
+	return nil
+}
+
+// Rules returns the rules in effect.
+func Rules() []Rule {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Rule, len(rules))
+	for i, rule := range rules {
+		list[i] = rule.Rule
+	}
+	return list
+}
+
+// merge returns the defaults with configured rules replacing those of the
+// same name and the rest appended, dropping Allow rules
+func merge(configured []Rule) []Rule {
+	byName := map[string]Rule{}
+	for _, rule := range configured {
+		byName[rule.Name] = rule
+	}
+	var merged []Rule
+	for _, rule := range DefaultRules {
+		if override, ok := byName[rule.Name]; ok {
+			rule = override
+			delete(byName, rule.Name)
+		}
+		merged = append(merged, rule)
+	}
+	for _, rule := range configured {
+		if _, ok := byName[rule.Name]; ok {
+			merged = append(merged, rule)
+			delete(byName, rule.Name)
+		}
+	}
+
+	kept := merged[:0]
+	for _, rule := range merged {
+		if rule.Action == "" {
+			rule.Action = Confirm
+		}
+		if rule.Action != Allow {
+			kept = append(kept, rule)
+		}
+	}
+	return kept
+}
+
+func mustCompile(list []Rule) []compiledRule {
+	compiled := make([]compiledRule, len(list))
+	for i, rule := range list {
+		compiled[i] = compiledRule{Rule: rule, re: regexp.MustCompile(rule.Pattern)}
+	}
+	return compiled
+}
+
+// Check returns the strictest decision for the lines of inputs.
+func Check(inputs ...string) Decision {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	decision := Decision{Action: Allow}
+	for _, input := range inputs {
+		for _, line := range strings.FieldsFunc(input, func(r rune) bool { return r == '\n' || r == '\r' }) {
+			for _, rule := range rules {
+				if decision.Action == Block || (decision.Action == Confirm && rule.Action != Block) {
+					continue
+				}
+				if rule.re.MatchString(line) {
+					decision = Decision{Action: rule.Action, Rule: rule.Name, Reason: rule.Reason, Command: strings.TrimSpace(line)}
+				}
+			}
+		}
+	}
+	return decision
+}
+
+// Allowed reports whether input with this decision may run: allowed input
+// always, input needing confirmation once confirmed, blocked input never.
+func (d Decision) Allowed(confirmed bool) bool {
+	return d.Action == Allow || (d.Action == Confirm && confirmed)
+}
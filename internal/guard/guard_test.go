@@ -0,0 +1,83 @@
+package guard
+
+import "testing"
+
+func TestCheckDefaults(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		rule  string
+	}{
+		{"ls -la", Allow, ""},
+		{"rm -rf /", Block, "rm-root"},
+		{"sudo rm -r -f /*", Block, "rm-root"},
+		{"rm --recursive ~/", Block, "rm-root"},
+		{"rm -rf $HOME && echo done", Block, "rm-root"},
+		{"rm -rf /tmp/build", Allow, ""},
+		{"rm -rf ./node_modules", Allow, ""},
+		{":(){ :|:& };:", Block, "fork-bomb"},
+		{"dd if=image.iso of=/dev/sdb bs=4M", Confirm, "dd-write"},
+		{"cat image > /dev/sda", Confirm, "disk-overwrite"},
+		{"sudo mkfs.ext4 /dev/sdb1", Confirm, "mkfs"},
+		{"format D: /q", Confirm, "format-drive"},
+		{"git push --force origin main", Confirm, "git-force-push"},
+		{"git push -f", Confirm, "git-force-push"},
+		{"git push --force-with-lease", Allow, ""},
+		{"git push origin feature-f", Allow, ""},
+		{"chmod -R 777 /", Confirm, "chmod-root"},
+		{"echo ok\nrm -rf /", Block, "rm-root"},
+	}
+	for _, tt := range tests {
+		got := Check(tt.input)
+		if got.Action != tt.want || got.Rule != tt.rule {
+			t.Errorf("Check(%q) = %+v, want %s by %q", tt.input, got, tt.want, tt.rule)
+		}
+	}
+}
+
+func TestCheckStrictest(t *testing.T) {
+	got := Check("git push -f", "rm -rf ~")
+	if got.Action != Block || got.Command != "rm -rf ~" {
+		t.Errorf("Expected the block to win, got %+v", got)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(nil)
+
+	err := Configure([]Rule{
+		{Name: "git-force-push", Action: Allow},
+		{Name: "dd-write", Pattern: `\bdd\b`, Action: Block, Reason: "No dd here"},
+		{Name: "terraform-destroy", Pattern: `\bterraform\s+destroy\b`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Check("git push --force"); got.Action != Allow {
+		t.Errorf("Expected the default turned off, got %+v", got)
+	}
+	if got := Check("dd if=a of=b"); got.Action != Block || got.Reason != "No dd here" {
+		t.Errorf("Expected the default replaced, got %+v", got)
+	}
+	if got := Check("terraform destroy"); got.Action != Confirm {
+		t.Errorf("Expected the new rule to default to confirm, got %+v", got)
+	}
+	if len(Rules()) != len(DefaultRules) {
+		t.Errorf("Expected %d rules, got %d", len(DefaultRules), len(Rules()))
+	}
+
+	for _, bad := range []Rule{{Pattern: "x"}, {Name: "x", Pattern: "("}, {Name: "x", Pattern: "x", Action: "warn"}, {Name: "x"}} {
+		if err := Configure([]Rule{bad}); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	if !(Decision{Action: Confirm}).Allowed(true) || (Decision{Action: Confirm}).Allowed(false) {
+		t.Error("Expected confirm to need confirmation")
+	}
+	if (Decision{Action: Block}).Allowed(true) {
+		t.Error("Expected block to ignore confirmation")
+	}
+}
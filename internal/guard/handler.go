@@ -0,0 +1,36 @@
+package guard
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// WriteDecision reports input the guard stopped: 409 Conflict with
+// needsConfirmation set when the caller may resend it confirmed, 403
+// Forbidden when it is blocked.
+func WriteDecision(w http.ResponseWriter, d Decision) {
+	status, message := http.StatusConflict, "This command needs confirmation: "+d.Reason
+	if d.Action == Block {
+		status, message = http.StatusForbidden, "This command is blocked: "+d.Reason
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":           false,
+		"error":             message,
+		"needsConfirmation": d.Action == Confirm,
+		"guard":             d,
+	})
+}
+
+// HandleRules lists the dangerous-command rules in effect (GET).
+func HandleRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rules": Rules(),
+	})
+}
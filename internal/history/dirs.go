@@ -0,0 +1,163 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// MaxDirs is how many visited directories are kept; the least recently
+// visited are dropped first
+const MaxDirs = 1000
+
+// Dir is a directory a tab's shell has been in.
+type Dir struct {
+	Path      string    `json:"path"`
+	Workspace string    `json:"workspace,omitempty"` // Workspace root (empty = outside any workspace)
+	Visits    int       `json:"visits"`
+	LastVisit time.Time `json:"lastVisit"`
+	Score     float64   `json:"score,omitempty"` // Set in Jump results
+}
+
+// loadDirs reads visited directories. Caller must hold mu.
+func loadDirs() ([]Dir, error) {
+	data, err := storage.ReadJSONFile(storage.GetDirHistoryPath())
+	if os.IsNotExist(err) {
+		return []Dir{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory history: %w", err)
+	}
+	var dirs []Dir
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		return nil, fmt.Errorf("failed to parse directory history: %w", err)
+	}
+	return dirs, nil
+}
+
+// saveDirs writes visited directories to disk. Caller must hold mu.
+func saveDirs(dirs []Dir) error {
+	path := storage.GetDirHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(dirs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// RecordDir counts a visit to dir, an absolute path on this machine.
+func RecordDir(dir string) error {
+	if dir == "" || !filepath.IsAbs(dir) {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	workspace := WorkspaceFor(dir)
+	now := time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	dirs, err := loadDirs()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range dirs {
+		if dirs[i].Path == dir {
+			dirs[i].Visits++
+			dirs[i].LastVisit, dirs[i].Workspace = now, workspace
+			found = true
+			break
+		}
+	}
+	if !found {
+		dirs = append(dirs, Dir{Path: dir, Workspace: workspace, Visits: 1, LastVisit: now})
+	}
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return dirs[i].LastVisit.After(dirs[j].LastVisit)
+	})
+	if len(dirs) > MaxDirs {
+		dirs = dirs[:MaxDirs]
+	}
+	return saveDirs(dirs)
+}
+
+// JumpQuery selects visited directories.
+type JumpQuery struct {
+	Text      string // Words that must appear in the path in order, the last in its final part (empty = any)
+	Workspace string // Only directories in this workspace root ("" = any)
+	Exclude   string // A directory to leave out, such as the one the tab is already in
+	Limit     int    // At most this many (0 = DefaultLimit)
+}
+
+// matchesPath reports whether path contains words in order, like zoxide:
+// "src comp" matches /work/src/app/components but not /work/components/src
+func matchesPath(path string, words []string) bool {
+	lower := strings.ToLower(filepath.ToSlash(path))
+	rest := lower
+	for _, word := range words {
+		i := strings.Index(rest, word)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(word):]
+	}
+	if len(words) == 0 {
+		return true
+	}
+	last := words[len(words)-1]
+	return strings.Contains(lower[strings.LastIndex(lower, "/")+1:], last)
+}
+
+// Jump returns the visited directories matching q that still exist, best
+// first: by visits weighted toward recent ones.
+func Jump(q JumpQuery) ([]Dir, error) {
+	mu.Lock()
+	dirs, err := loadDirs()
+	mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.Fields(strings.ToLower(filepath.ToSlash(q.Text)))
+	exclude := ""
+	if q.Exclude != "" {
+		exclude = filepath.Clean(q.Exclude)
+	}
+	now := time.Now()
+	var found []Dir
+	for _, d := range dirs {
+		if d.Path == exclude || (q.Workspace != "" && d.Workspace != q.Workspace) || !matchesPath(d.Path, words) {
+			continue
+		}
+		if info, err := os.Stat(d.Path); err != nil || !info.IsDir() {
+			continue
+		}
+		d.Score = float64(d.Visits) * recencyWeight(now.Sub(d.LastVisit))
+		found = append(found, d)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return found[i].LastVisit.After(found[j].LastVisit)
+	})
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
@@ -0,0 +1,65 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchesPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		query string
+		want  bool
+	}{
+		{"/work/src/app/components", "src comp", true},
+		{"/work/components/src", "src comp", false},
+		{"/work/components/src", "comp", false}, // The last word must be in the final part
+		{"/work/Forge-Terminal", "forge", true},
+		{"/work/forge", "", true},
+	}
+	for _, tt := range tests {
+		if got := matchesPath(tt.path, strings.Fields(strings.ToLower(tt.query))); got != tt.want {
+			t.Errorf("matchesPath(%q, %q) = %v, want %v", tt.path, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestJump(t *testing.T) {
+	root := t.TempDir()
+	app, web, docs := filepath.Join(root, "app"), filepath.Join(root, "app", "web"), filepath.Join(root, "docs")
+	other := t.TempDir()
+	for _, dir := range []string{web, docs} {
+		os.MkdirAll(dir, 0755)
+	}
+	useTempStore(t, app)
+
+	RecordDir(web)
+	RecordDir(docs)
+	RecordDir(docs)
+	RecordDir(other)
+	RecordDir("relative/dir")
+
+	dirs, err := Jump(JumpQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 3 || dirs[0].Path != docs || dirs[0].Visits != 2 {
+		t.Fatalf("Expected docs first with 2 visits, got %+v", dirs)
+	}
+	if dirs, _ := Jump(JumpQuery{Text: "we"}); len(dirs) != 1 || dirs[0].Path != web || dirs[0].Workspace != app {
+		t.Errorf("Expected web in the app workspace, got %+v", dirs)
+	}
+	if dirs, _ := Jump(JumpQuery{Workspace: app}); len(dirs) != 1 {
+		t.Errorf("Expected only the app workspace's directory, got %+v", dirs)
+	}
+	if dirs, _ := Jump(JumpQuery{Exclude: docs, Limit: 1}); len(dirs) != 1 || dirs[0].Path == docs {
+		t.Errorf("Expected docs left out, got %+v", dirs)
+	}
+
+	os.RemoveAll(docs)
+	if dirs, _ := Jump(JumpQuery{Text: "docs"}); len(dirs) != 0 {
+		t.Errorf("Expected deleted directories skipped, got %+v", dirs)
+	}
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// HandleSearch searches the command history of every tab (GET ?q=&workspace=&limit=).
+// workspace may be any directory inside a workspace, such as a tab's current
+// directory; outside every workspace, all commands are searched.
+func HandleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	q := Query{Text: query.Get("q"), Workspace: WorkspaceFor(query.Get("workspace"))}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		q.Limit = n
+	}
+
+	matches, err := Search(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"matches":   matches,
+		"workspace": q.Workspace,
+	})
+}
+
+// HandleHistory clears the command history (DELETE ?workspace= for one
+// workspace, ?all=true for every command).
+func HandleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	all := query.Get("all") == "true"
+	workspace := ""
+	if dir := query.Get("workspace"); dir != "" {
+		workspace = WorkspaceFor(dir)
+		if workspace == "" {
+			http.Error(w, "Not inside a known workspace: "+dir, http.StatusNotFound)
+			return
+		}
+	} else if !all {
+		http.Error(w, "Give workspace, or all=true to clear every command", http.StatusBadRequest)
+		return
+	}
+
+	if err := Clear(workspace, all); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
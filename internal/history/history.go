@@ -0,0 +1,189 @@
+// Package history collects the command lines typed in every tab into one
+// store, grouped by workspace, for a Ctrl+R-style search across tabs and
+// sessions. Repeated commands are merged and ranked by how often and how
+// recently they ran.
+//
+// The directories tabs visit are kept the same way, so a few letters of a
+// path are enough to jump back to it (see Jump).
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+	"github.com/mikejsmith1985/forge-terminal/internal/workspaces"
+)
+
+// MaxPerWorkspace is how many distinct commands are kept per workspace; the
+// least recently used are dropped first
+const MaxPerWorkspace = 2000
+
+// maxCommandLen skips pasted scripts and other very long lines
+const maxCommandLen = 1000
+
+// Entry is a distinct command line run in a workspace.
+type Entry struct {
+	Command   string    `json:"command"`
+	Workspace string    `json:"workspace,omitempty"` // Workspace root (empty = outside any workspace)
+	Count     int       `json:"count"`
+	FirstUsed time.Time `json:"firstUsed"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// workspaceRoots returns the known workspace roots; overridden in tests
+var workspaceRoots = func() []string {
+	list, err := workspaces.List()
+	if err != nil {
+		return nil
+	}
+	roots := make([]string, 0, len(list))
+	for _, ws := range list {
+		roots = append(roots, ws.Path)
+	}
+	return roots
+}
+
+var mu sync.Mutex
+
+// load reads stored entries. Caller must hold mu.
+func load() ([]Entry, error) {
+	data, err := storage.ReadJSONFile(storage.GetCommandHistoryPath())
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command history: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse command history: %w", err)
+	}
+	return entries, nil
+}
+
+// save writes entries to disk. Caller must hold mu.
+func save(entries []Entry) error {
+	path := storage.GetCommandHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// WorkspaceFor returns the workspace root containing dir: the deepest known
+// workspace that holds it, or "" if none does.
+func WorkspaceFor(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir = filepath.Clean(dir)
+	best := ""
+	for _, root := range workspaceRoots() {
+		root = filepath.Clean(root)
+		if (dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))) && len(root) > len(best) {
+			best = root
+		}
+	}
+	return best
+}
+
+// Record adds a command line run in dir (the shell's directory, or "" when
+// it isn't on this machine). Lines starting with a space are skipped, as
+// shells do with HISTCONTROL=ignorespace.
+func Record(command, dir string) error {
+	if strings.HasPrefix(command, " ") {
+		return nil
+	}
+	command = strings.TrimSpace(command)
+	if command == "" || len(command) > maxCommandLen {
+		return nil
+	}
+	workspace := WorkspaceFor(dir)
+	now := time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range entries {
+		if entries[i].Command == command && entries[i].Workspace == workspace {
+			entries[i].Count++
+			entries[i].LastUsed = now
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, Entry{Command: command, Workspace: workspace, Count: 1, FirstUsed: now, LastUsed: now})
+	}
+	return save(trim(entries))
+}
+
+// trim drops the least recently used commands beyond MaxPerWorkspace in each
+// workspace, leaving entries ordered most recent first
+func trim(entries []Entry) []Entry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	kept := entries[:0]
+	perWorkspace := map[string]int{}
+	for _, e := range entries {
+		if perWorkspace[e.Workspace] < MaxPerWorkspace {
+			perWorkspace[e.Workspace]++
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// UsedBetween returns the commands last run at or after since and before
+// until, most recent first. A command run again later is not included.
+func UsedBetween(since, until time.Time) ([]Entry, error) {
+	mu.Lock()
+	entries, err := load()
+	mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	used := []Entry{}
+	for _, e := range entries {
+		if !e.LastUsed.Before(since) && e.LastUsed.Before(until) {
+			used = append(used, e)
+		}
+	}
+	sort.SliceStable(used, func(i, j int) bool { return used[i].LastUsed.After(used[j].LastUsed) })
+	return used, nil
+}
+
+// Clear forgets the commands run in workspace, or every command if all is set.
+func Clear(workspace string, all bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !all && e.Workspace != workspace {
+			kept = append(kept, e)
+		}
+	}
+	return save(kept)
+}
@@ -0,0 +1,144 @@
+package history
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func useTempStore(t *testing.T, roots ...string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	original := workspaceRoots
+	workspaceRoots = func() []string { return roots }
+	t.Cleanup(func() { workspaceRoots = original })
+}
+
+func TestWorkspaceFor(t *testing.T) {
+	useTempStore(t, "/src/app", "/src/app/web", "/src/lib")
+
+	tests := map[string]string{
+		"/src/app":          "/src/app",
+		"/src/app/cmd":      "/src/app",
+		"/src/app/web/ui":   "/src/app/web",
+		"/src/application":  "",
+		"/home/me":          "",
+		"":                  "",
+		"/src/lib/../app/x": "/src/app",
+	}
+	for dir, want := range tests {
+		if got := WorkspaceFor(dir); got != want {
+			t.Errorf("WorkspaceFor(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestRecordMergesRepeats(t *testing.T) {
+	useTempStore(t, "/src/app")
+
+	for _, command := range []string{"go test ./...", "  go test ./...", "go test ./... ", "", " export TOKEN=secret"} {
+		if err := Record(command, "/src/app/cmd"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Record("go test ./...", "/home/me")
+
+	matches, err := Search(Query{Workspace: "/src/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Command != "go test ./..." || matches[0].Count != 2 {
+		t.Fatalf("Expected one merged command run twice, got %+v", matches)
+	}
+
+	all, _ := Search(Query{})
+	if len(all) != 1 || all[0].Count != 3 || !reflect.DeepEqual(all[0].Workspaces, []string{"", "/src/app"}) {
+		t.Errorf("Expected the command merged across workspaces, got %+v", all)
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	useTempStore(t)
+
+	Record("git status", "")
+	Record("git status", "")
+	Record("make build", "")
+	Record("docker build -t app .", "")
+
+	matches, err := Search(Query{Text: "BUILD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commands []string
+	for _, m := range matches {
+		commands = append(commands, m.Command)
+	}
+	if len(commands) != 2 {
+		t.Fatalf("Expected 2 matches for build, got %q", commands)
+	}
+
+	matches, _ = Search(Query{Text: "git"})
+	if len(matches) != 1 || matches[0].Command != "git status" {
+		t.Errorf("Expected git status, got %+v", matches)
+	}
+	if matches, _ := Search(Query{Text: "docker app"}); len(matches) != 1 {
+		t.Errorf("Expected every word to match, got %+v", matches)
+	}
+	if matches, _ := Search(Query{Limit: 1}); len(matches) != 1 || matches[0].Command != "git status" {
+		t.Errorf("Expected the most used command first, got %+v", matches)
+	}
+}
+
+func TestRecencyWeight(t *testing.T) {
+	if recencyWeight(time.Minute) <= recencyWeight(48*time.Hour) {
+		t.Error("Expected recent commands weighted above old ones")
+	}
+}
+
+func TestTrimAndClear(t *testing.T) {
+	useTempStore(t, "/src/app")
+
+	now := time.Now()
+	entries := make([]Entry, 0, MaxPerWorkspace+2)
+	for i := 0; i < MaxPerWorkspace+1; i++ {
+		entries = append(entries, Entry{Command: string(rune('a' + i%26)), Workspace: "/src/app", Count: 1, LastUsed: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	entries = append(entries, Entry{Command: "ls", Count: 1, LastUsed: now.Add(-time.Hour * 1000)})
+	kept := trim(entries)
+	if len(kept) != MaxPerWorkspace+1 || kept[len(kept)-1].Command != "ls" {
+		t.Errorf("Expected the oldest workspace command dropped and other workspaces kept, got %d", len(kept))
+	}
+
+	Record("ls", "/src/app")
+	Record("pwd", "/tmp")
+	if err := Clear("/src/app", false); err != nil {
+		t.Fatal(err)
+	}
+	if matches, _ := Search(Query{}); len(matches) != 1 || matches[0].Command != "pwd" {
+		t.Errorf("Expected only the other workspace's command left, got %+v", matches)
+	}
+	Clear("", true)
+	if matches, _ := Search(Query{}); len(matches) != 0 {
+		t.Errorf("Expected no commands after clearing all, got %+v", matches)
+	}
+}
+
+func TestUsedBetween(t *testing.T) {
+	useTempStore(t, "/src/app")
+
+	start := time.Now()
+	Record("make", "/src/app")
+	Record("ls", "/tmp")
+	used, err := UsedBetween(start, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(used) != 2 || used[0].Command != "ls" || used[1].Workspace != "/src/app" {
+		t.Errorf("Expected ls then make, got %+v", used)
+	}
+	if used, _ := UsedBetween(start.Add(-time.Hour), start); len(used) != 0 {
+		t.Errorf("Expected nothing used earlier, got %+v", used)
+	}
+}
@@ -0,0 +1,118 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLimit is how many matches a search returns when no limit is given
+const DefaultLimit = 50
+
+// Query selects commands from the history.
+type Query struct {
+	Text      string // Every word must appear in the command, ignoring case (empty = all)
+	Workspace string // Only commands run in this workspace root; "" searches every workspace
+	Limit     int    // At most this many matches (0 = DefaultLimit)
+}
+
+// Match is a command found by Search, merged across workspaces when the
+// search covers all of them.
+type Match struct {
+	Command    string    `json:"command"`
+	Count      int       `json:"count"`
+	LastUsed   time.Time `json:"lastUsed"`
+	Workspaces []string  `json:"workspaces"` // Where it was run ("" = outside any workspace)
+	Score      float64   `json:"score"`
+}
+
+// recencyWeight favours recent commands, so one run a hundred times last
+// year doesn't bury one run five times today
+func recencyWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 4
+	case age < 24*time.Hour:
+		return 2
+	case age < 7*24*time.Hour:
+		return 1
+	case age < 30*24*time.Hour:
+		return 0.5
+	default:
+		return 0.25
+	}
+}
+
+// matches reports whether command contains every word, and whether it
+// starts with the text as typed
+func matches(command string, text string, words []string) (bool, bool) {
+	lower := strings.ToLower(command)
+	for _, word := range words {
+		if !strings.Contains(lower, word) {
+			return false, false
+		}
+	}
+	return true, text != "" && strings.HasPrefix(lower, text)
+}
+
+// Search returns the commands matching q, best first: by run count weighted
+// toward recent use, with commands starting with the text ahead.
+func Search(q Query) ([]Match, error) {
+	mu.Lock()
+	entries, err := load()
+	mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	words := strings.Fields(text)
+	now := time.Now()
+	byCommand := map[string]*Match{}
+	var found []*Match
+	for _, e := range entries {
+		if q.Workspace != "" && e.Workspace != q.Workspace {
+			continue
+		}
+		ok, prefix := matches(e.Command, text, words)
+		if !ok {
+			continue
+		}
+		score := float64(e.Count) * recencyWeight(now.Sub(e.LastUsed))
+		if prefix {
+			score *= 2
+		}
+		m := byCommand[e.Command]
+		if m == nil {
+			m = &Match{Command: e.Command}
+			byCommand[e.Command] = m
+			found = append(found, m)
+		}
+		m.Count += e.Count
+		m.Score += score
+		m.Workspaces = append(m.Workspaces, e.Workspace)
+		if e.LastUsed.After(m.LastUsed) {
+			m.LastUsed = e.LastUsed
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return found[i].LastUsed.After(found[j].LastUsed)
+	})
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	results := make([]Match, len(found))
+	for i, m := range found {
+		sort.Strings(m.Workspaces)
+		results[i] = *m
+	}
+	return results, nil
+}
@@ -0,0 +1,162 @@
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/workspaces"
+)
+
+// OpenRequest is the body for POST /api/instance/open
+type OpenRequest struct {
+	Workspace string `json:"workspace,omitempty"`
+}
+
+// OpenResponse reports how many pages received an open request
+type OpenResponse struct {
+	Pages     int    `json:"pages"`
+	Workspace string `json:"workspace,omitempty"` // Absolute path
+	TabID     string `json:"tabId,omitempty"`     // Tab reserved for the workspace
+}
+
+// OpenEvent is sent to connected pages when another launch asks to open
+type OpenEvent struct {
+	Workspace string `json:"workspace,omitempty"`
+	TabID     string `json:"tabId,omitempty"` // Connect a new tab with this ID to get a shell in Workspace
+}
+
+// Server answers instance probes from later launches and relays their open
+// requests to the connected pages.
+type Server struct {
+	info Info
+
+	mu          sync.Mutex
+	subscribers map[chan OpenEvent]struct{}
+	openTab     func(dir string) string
+}
+
+// NewServer creates a server for the running instance
+func NewServer(url, version string) *Server {
+	return &Server{
+		info:        Info{PID: os.Getpid(), URL: url, Version: version},
+		subscribers: make(map[chan OpenEvent]struct{}),
+	}
+}
+
+// SetTabOpener sets how a workspace tab is reserved: openTab returns the ID
+// of a tab whose shell will start in dir. Call before serving.
+func (s *Server) SetTabOpener(openTab func(dir string) string) {
+	s.openTab = openTab
+}
+
+// AnnounceTab asks the connected pages to open a tab already reserved with
+// tabID, and returns how many pages received it.
+func (s *Server) AnnounceTab(tabID string) int {
+	return s.publish(OpenEvent{TabID: tabID})
+}
+
+// HandleInfo reports the running instance (GET /api/instance)
+func (s *Server) HandleInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.info)
+}
+
+// HandleOpen passes an open request to the connected pages, recording the
+// workspace and reserving a tab for it if one is given, and reports how many pages received it (POST
+// /api/instance/open). With none connected the caller opens a browser itself.
+func (s *Server) HandleOpen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req OpenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	event := OpenEvent{}
+	if req.Workspace != "" {
+		ws, err := workspaces.RecordOpen(req.Workspace)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		event.Workspace = ws.Path
+		if s.openTab != nil {
+			event.TabID = s.openTab(ws.Path)
+		}
+	}
+
+	delivered := s.publish(event)
+	log.Printf("[Instance] Open requested (workspace=%q, pages=%d)", event.Workspace, delivered)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OpenResponse{Pages: delivered, Workspace: event.Workspace, TabID: event.TabID})
+}
+
+// HandleEvents streams an "open" event to the page whenever another launch
+// asks this instance to open (GET /api/instance/events)
+func (s *Server) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	events, cancel := s.subscribe()
+	defer cancel()
+
+	fmt.Fprintf(w, "event: connected\ndata: {}\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: open\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) subscribe() (<-chan OpenEvent, func()) {
+	ch := make(chan OpenEvent, 4)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
+
+// publish sends an event to every connected page and returns how many received it
+func (s *Server) publish(event OpenEvent) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivered := 0
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
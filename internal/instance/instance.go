@@ -0,0 +1,153 @@
+// Package instance keeps Forge to one server per user. The running server
+// records its address in instance.json; a second launch finds it there, hands
+// its workspace to the open pages over the server's HTTP API (or opens a
+// browser if there are none), and exits.
+package instance
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/api"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Info describes the running server.
+type Info struct {
+	PID       int       `json:"pid"`
+	URL       string    `json:"url"`
+	Version   string    `json:"version"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// probeTimeout bounds how long a launch waits for the recorded server to answer
+const probeTimeout = 2 * time.Second
+
+// client talks to the server on this machine. Its certificate may be
+// self-signed, and the PID check below identifies it instead.
+var client = &http.Client{
+	Timeout:   probeTimeout,
+	Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+}
+
+// Register records this process as the running server.
+func Register(url, version string) error {
+	info := Info{PID: os.Getpid(), URL: url, Version: version, StartedAt: time.Now()}
+	path := storage.GetInstancePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// Unregister removes the instance file if it still belongs to this process.
+func Unregister() {
+	if info, err := load(); err == nil && info.PID == os.Getpid() {
+		os.Remove(storage.GetInstancePath())
+	}
+}
+
+func load() (*Info, error) {
+	data, err := storage.ReadJSONFile(storage.GetInstancePath())
+	if err != nil {
+		return nil, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// FindRunning returns the recorded server if it is still answering, or nil.
+// A stale record (crashed server, reused port) is ignored.
+func FindRunning() *Info {
+	info, err := load()
+	if err != nil || info.PID == os.Getpid() {
+		return nil
+	}
+	resp, err := client.Get(info.URL + api.Prefix + "/instance")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var live Info
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&live) != nil || live.PID != info.PID {
+		return nil
+	}
+	return info
+}
+
+// Open asks the running server's pages to come forward, opening workspace (if
+// set) in a new tab. It returns how many pages were told; with none, the caller
+// should open PageURL in a browser.
+func Open(info *Info, workspace string) (int, error) {
+	var result OpenResponse
+	if err := Call(info, http.MethodPost, "/instance/open", OpenRequest{Workspace: workspace}, &result); err != nil {
+		return 0, err
+	}
+	return result.Pages, nil
+}
+
+// callTimeout bounds API calls made on behalf of the CLI
+const callTimeout = 30 * time.Second
+
+// Call sends an API request to the running server, encoding body (if not nil)
+// as JSON and decoding the response into result (if not nil). path is
+// relative to the API prefix, e.g. "/commands/run".
+func Call(info *Info, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, info.URL+api.Prefix+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	callClient := *client
+	callClient.Timeout = callTimeout
+	resp, err := callClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// PageURL returns the address to open in a browser, passing the workspace
+// along so the page can open it as its first tab.
+func (info *Info) PageURL(workspace string) string {
+	if workspace == "" {
+		return info.URL
+	}
+	return info.URL + "/?workspace=" + url.QueryEscape(workspace)
+}
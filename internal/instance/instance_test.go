@@ -0,0 +1,198 @@
+package instance
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+func useTempInfo(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	if err := os.MkdirAll(storage.GetForgeDir(), 0700); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startServer serves an instance as if it belonged to another process
+func startServer(t *testing.T, pid int) (*Server, *httptest.Server) {
+	t.Helper()
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	s := NewServer(ts.URL, "1.0.0")
+	s.info.PID = pid
+	mux.HandleFunc("/api/v1/instance", s.HandleInfo)
+	mux.HandleFunc("/api/v1/instance/open", s.HandleOpen)
+	mux.HandleFunc("/api/v1/instance/events", s.HandleEvents)
+	return s, ts
+}
+
+func writeInfo(t *testing.T, info Info) {
+	t.Helper()
+	data, _ := json.Marshal(info)
+	if err := storage.WriteFileAtomic(storage.GetInstancePath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterUnregister(t *testing.T) {
+	useTempInfo(t)
+
+	if err := Register("http://127.0.0.1:8333", "1.0.0"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	info, err := load()
+	if err != nil || info.PID != os.Getpid() || info.URL != "http://127.0.0.1:8333" {
+		t.Fatalf("Unexpected record %+v (%v)", info, err)
+	}
+
+	// A server never finds itself
+	if FindRunning() != nil {
+		t.Error("Expected FindRunning to ignore this process")
+	}
+
+	Unregister()
+	if _, err := os.Stat(storage.GetInstancePath()); !os.IsNotExist(err) {
+		t.Error("Expected instance file to be removed")
+	}
+}
+
+func TestUnregisterKeepsOtherInstance(t *testing.T) {
+	useTempInfo(t)
+	writeInfo(t, Info{PID: os.Getpid() + 1, URL: "http://127.0.0.1:8333"})
+
+	Unregister()
+	if _, err := os.Stat(storage.GetInstancePath()); err != nil {
+		t.Error("Expected another instance's record to be kept")
+	}
+}
+
+func TestFindRunning(t *testing.T) {
+	useTempInfo(t)
+	pid := os.Getpid() + 1
+	_, ts := startServer(t, pid)
+
+	if FindRunning() != nil {
+		t.Error("Expected nothing running without a record")
+	}
+
+	writeInfo(t, Info{PID: pid, URL: ts.URL})
+	info := FindRunning()
+	if info == nil || info.URL != ts.URL {
+		t.Fatalf("Expected running instance at %s, got %+v", ts.URL, info)
+	}
+
+	// A different server now holds the port
+	writeInfo(t, Info{PID: pid + 1, URL: ts.URL})
+	if FindRunning() != nil {
+		t.Error("Expected PID mismatch to be treated as stale")
+	}
+
+	// Nothing listening any more
+	writeInfo(t, Info{PID: pid, URL: ts.URL})
+	ts.Close()
+	if FindRunning() != nil {
+		t.Error("Expected closed server to be treated as stale")
+	}
+}
+
+func TestOpenWithoutPages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	_, ts := startServer(t, os.Getpid()+1)
+	info := &Info{URL: ts.URL}
+
+	workspace := t.TempDir()
+	pages, err := Open(info, workspace)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if pages != 0 {
+		t.Errorf("Expected no pages, got %d", pages)
+	}
+	if url := info.PageURL(workspace); !strings.HasPrefix(url, ts.URL+"/?workspace=") {
+		t.Errorf("Unexpected page URL %s", url)
+	}
+
+	if _, err := Open(info, filepath.Join(workspace, "missing")); err == nil {
+		t.Error("Expected error for missing workspace")
+	}
+}
+
+func TestOpenNotifiesConnectedPages(t *testing.T) {
+	_, ts := startServer(t, os.Getpid()+1)
+	// The subscription is registered before the connected event is sent
+	resp, err := http.Get(ts.URL + "/api/v1/instance/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var event string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Reading events: %v", err)
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				return event
+			}
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event = name
+			}
+		}
+	}
+	if readEvent() != "connected" {
+		t.Fatal("Expected connected event")
+	}
+
+	pages, err := Open(&Info{URL: ts.URL}, "")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if pages != 1 {
+		t.Errorf("Expected 1 page, got %d", pages)
+	}
+	if readEvent() != "open" {
+		t.Error("Expected open event")
+	}
+}
+
+func TestOpenReservesTab(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	s, ts := startServer(t, os.Getpid()+1)
+	var openedDir string
+	s.SetTabOpener(func(dir string) string {
+		openedDir = dir
+		return "tab-1"
+	})
+
+	workspace := t.TempDir()
+	body, _ := json.Marshal(OpenRequest{Workspace: workspace})
+	resp, err := http.Post(ts.URL+"/api/v1/instance/open", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result OpenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.TabID != "tab-1" || openedDir != result.Workspace {
+		t.Errorf("Expected a tab reserved in %s, got %+v (opened %q)", result.Workspace, result, openedDir)
+	}
+}
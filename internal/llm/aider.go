@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Aider reports each edit it applies and each commit it makes on a line
+	// of its own: "Applied edit to src/app.py", "Commit 1a2b3c4 fix: ..."
+	aiderEditLine   = regexp.MustCompile(`^Applied edit to (.+)$`)
+	aiderCommitLine = regexp.MustCompile(`^Commit ([0-9a-f]{7,40})(?:\s|$)`)
+)
+
+// AiderActivity is a file edit or commit reported in Aider's output.
+type AiderActivity struct {
+	EditedFile string // Path Aider applied an edit to, as printed
+	CommitSHA  string // Abbreviated SHA of a commit Aider made
+}
+
+// ParseAiderLine recognises a line of Aider output reporting an edit or a
+// commit.
+func ParseAiderLine(line string) (AiderActivity, bool) {
+	line = strings.TrimSpace(CleanANSI(line))
+	if m := aiderEditLine.FindStringSubmatch(line); m != nil {
+		return AiderActivity{EditedFile: strings.TrimSpace(m[1])}, true
+	}
+	if m := aiderCommitLine.FindStringSubmatch(line); m != nil {
+		return AiderActivity{CommitSHA: m[1]}, true
+	}
+	return AiderActivity{}, false
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// charsPerToken is the rough size of a token in English text and code
+const charsPerToken = 4
+
+// Price is what an API-billed provider charges, in dollars per 1,000 tokens.
+// Models overrides it for the provider's models by name ("sonnet",
+// "claude-opus-4", ...); a conversation on any other model, or one whose
+// model isn't known, is billed at the provider's price.
+type Price struct {
+	InputPer1K  float64          `json:"inputPer1K"`
+	OutputPer1K float64          `json:"outputPer1K"`
+	Models      map[string]Price `json:"models,omitempty"`
+}
+
+// Validate reports a negative price, or a model price with models of its own.
+func (p Price) Validate() error {
+	if p.InputPer1K < 0 || p.OutputPer1K < 0 {
+		return fmt.Errorf("prices must not be negative")
+	}
+	for model, price := range p.Models {
+		if strings.TrimSpace(model) == "" {
+			return fmt.Errorf("model price needs a model name")
+		}
+		if len(price.Models) > 0 {
+			return fmt.Errorf("model %s: models can't be nested", model)
+		}
+		if err := price.Validate(); err != nil {
+			return fmt.Errorf("model %s: %w", model, err)
+		}
+	}
+	return nil
+}
+
+// forModel returns the price of the named model: the one configured under
+// that exact name (ignoring case), else the longest configured name the model
+// contains, so "sonnet" prices "claude-sonnet-4-5". With no match it's the
+// provider's own price.
+func (p Price) forModel(model string) Price {
+	model = strings.ToLower(strings.TrimSpace(model))
+	base := Price{InputPer1K: p.InputPer1K, OutputPer1K: p.OutputPer1K}
+	if model == "" {
+		return base
+	}
+	best, bestLen := base, 0
+	for name, price := range p.Models {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == model {
+			return price
+		}
+		if len(name) > bestLen && strings.Contains(model, name) {
+			best, bestLen = price, len(name)
+		}
+	}
+	return best
+}
+
+// Cost returns the dollars billed for the given token counts.
+func (p Price) Cost(inputTokens, outputTokens int) float64 {
+	return float64(inputTokens)/1000*p.InputPer1K + float64(outputTokens)/1000*p.OutputPer1K
+}
+
+var (
+	pricesMu sync.RWMutex
+	prices   = map[Provider]Price{}
+)
+
+// normalizePrices keys the prices by Provider, accepting any spelling
+// ParseProvider does. It reports an unknown provider, one priced twice under
+// different spellings, and invalid prices.
+func normalizePrices(byProvider map[string]Price) (map[Provider]Price, error) {
+	normalized := make(map[Provider]Price, len(byProvider))
+	for name, price := range byProvider {
+		provider := ParseProvider(name)
+		if provider == ProviderUnknown {
+			if strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("price needs a provider name")
+			}
+			return nil, fmt.Errorf("unknown provider %q", name)
+		}
+		if _, dup := normalized[provider]; dup {
+			return nil, fmt.Errorf("%s is priced more than once", provider)
+		}
+		if err := price.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if price.Models != nil {
+			models := make(map[string]Price, len(price.Models))
+			for model, modelPrice := range price.Models {
+				models[model] = modelPrice
+			}
+			price.Models = models
+		}
+		normalized[provider] = price
+	}
+	return normalized, nil
+}
+
+// ValidatePrices reports a price for an unknown or repeated provider, or one
+// below zero.
+func ValidatePrices(byProvider map[string]Price) error {
+	_, err := normalizePrices(byProvider)
+	return err
+}
+
+// SetPrices sets the price of each API-billed provider, by any of the names
+// ParseProvider accepts ("claude", "copilot", ...). Providers without one are
+// treated as unbilled.
+func SetPrices(byProvider map[string]Price) error {
+	next, err := normalizePrices(byProvider)
+	if err != nil {
+		return err
+	}
+	pricesMu.Lock()
+	prices = next
+	pricesMu.Unlock()
+	return nil
+}
+
+// PriceFor returns the price of the provider's model, if the provider has
+// one. model may be empty when it isn't known.
+func PriceFor(provider, model string) (Price, bool) {
+	pricesMu.RLock()
+	defer pricesMu.RUnlock()
+	price, ok := prices[ParseProvider(provider)]
+	if !ok {
+		return Price{}, false
+	}
+	return price.forModel(model), true
+}
+
+// EstimateTokens approximates the number of tokens in text. Captured
+// conversations don't carry the provider's own counts.
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
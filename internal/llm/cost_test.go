@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPrices(t *testing.T) {
+	defer SetPrices(nil)
+
+	if err := SetPrices(map[string]Price{"claude": {InputPer1K: 0.003, OutputPer1K: 0.015}}); err != nil {
+		t.Fatal(err)
+	}
+	price, ok := PriceFor("claude", "")
+	if !ok {
+		t.Fatal("Expected a price for claude")
+	}
+	if got := price.Cost(2000, 1000); math.Abs(got-0.021) > 1e-9 {
+		t.Errorf("Cost = %v, want 0.021", got)
+	}
+	if _, ok := PriceFor("github-copilot", ""); ok {
+		t.Error("Expected no price for an unpriced provider")
+	}
+
+	if err := SetPrices(map[string]Price{"claude": {InputPer1K: -1}}); err == nil {
+		t.Error("Expected an error for a negative price")
+	}
+	if err := SetPrices(map[string]Price{"": {}}); err == nil {
+		t.Error("Expected an error for a missing provider")
+	}
+	if err := SetPrices(map[string]Price{"gpt": {}}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+	if err := SetPrices(map[string]Price{"copilot": {}, "github-copilot": {}}); err == nil {
+		t.Error("Expected an error for a provider priced twice")
+	}
+	if _, ok := PriceFor("claude", ""); !ok {
+		t.Error("Expected a rejected update to keep the previous prices")
+	}
+}
+
+func TestPrices_ProviderSpelling(t *testing.T) {
+	defer SetPrices(nil)
+
+	if err := SetPrices(map[string]Price{"Claude-Code": {InputPer1K: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if price, ok := PriceFor("claude", ""); !ok || price.InputPer1K != 1 {
+		t.Errorf("Expected claude-code's price for claude, got %+v, %v", price, ok)
+	}
+}
+
+func TestPrices_Models(t *testing.T) {
+	defer SetPrices(nil)
+
+	err := SetPrices(map[string]Price{"claude": {
+		InputPer1K:  0.003,
+		OutputPer1K: 0.015,
+		Models: map[string]Price{
+			"opus":          {InputPer1K: 0.015, OutputPer1K: 0.075},
+			"claude-opus-4": {InputPer1K: 0.01, OutputPer1K: 0.05},
+			"Haiku":         {InputPer1K: 0.001, OutputPer1K: 0.005},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]float64{
+		"":                  0.003,
+		"sonnet":            0.003,
+		"opus":              0.015,
+		"claude-opus-4-1":   0.01, // the longest name it contains
+		"CLAUDE-OPUS-4":     0.01,
+		"claude-3-5-haiku":  0.001,
+		"haiku":             0.001,
+		"something-unknown": 0.003,
+	}
+	for model, want := range tests {
+		price, ok := PriceFor("claude", model)
+		if !ok || price.InputPer1K != want {
+			t.Errorf("PriceFor(claude, %q) = %+v, %v; want input %v", model, price, ok, want)
+		}
+	}
+
+	if err := SetPrices(map[string]Price{"claude": {Models: map[string]Price{"opus": {InputPer1K: -1}}}}); err == nil {
+		t.Error("Expected an error for a negative model price")
+	}
+	if err := SetPrices(map[string]Price{"claude": {Models: map[string]Price{"opus": {Models: map[string]Price{"x": {}}}}}}); err == nil {
+		t.Error("Expected an error for nested model prices")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{"": 0, "abc": 1, "abcd": 1, "abcde": 2}
+	for text, want := range tests {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
@@ -2,9 +2,10 @@
 package llm
 
 import (
-	"log"
 	"regexp"
 	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
 )
 
 // Provider represents an LLM CLI provider.
@@ -134,19 +135,17 @@ func NewDetector() *Detector {
 func (d *Detector) DetectCommand(input string) *DetectedCommand {
 	trimmed := strings.TrimSpace(input)
 
-	log.Printf("[LLM Detector] ═══ DETECTION START ═══")
-	log.Printf("[LLM Detector] Raw input: '%s' (len=%d)", input, len(input))
-	log.Printf("[LLM Detector] Trimmed: '%s' (len=%d)", trimmed, len(trimmed))
-	log.Printf("[LLM Detector] Hex: % X", []byte(trimmed))
-	log.Printf("[LLM Detector] Testing %d patterns...", len(d.patterns))
+	logging.Debugf("[LLM Detector] Raw input: '%s' (len=%d)", input, len(input))
+	logging.Debugf("[LLM Detector] Trimmed: '%s' (len=%d)", trimmed, len(trimmed))
+	logging.Debugf("[LLM Detector] Hex: % X", []byte(trimmed))
+	logging.Debugf("[LLM Detector] Testing %d patterns...", len(d.patterns))
 
 	for i, pattern := range d.patterns {
-		log.Printf("[LLM Detector] [%d/%d] Testing pattern '%s'...", i+1, len(d.patterns), pattern.Name)
+		logging.Debugf("[LLM Detector] [%d/%d] Testing pattern '%s'...", i+1, len(d.patterns), pattern.Name)
 		
 		if pattern.Regex.MatchString(trimmed) {
 			provider, cmdType := pattern.Extract(trimmed)
-			log.Printf("[LLM Detector] ✅ MATCH! pattern='%s' provider=%s type=%s", pattern.Name, provider, cmdType)
-			log.Printf("[LLM Detector] ═══ DETECTION END (MATCHED) ═══")
+			logging.Debugf("[LLM Detector] ✅ MATCH! pattern='%s' provider=%s type=%s", pattern.Name, provider, cmdType)
 			return &DetectedCommand{
 				Provider: provider,
 				Type:     cmdType,
@@ -155,12 +154,11 @@ func (d *Detector) DetectCommand(input string) *DetectedCommand {
 				Detected: true,
 			}
 		} else {
-			log.Printf("[LLM Detector] ✗ No match for pattern '%s'", pattern.Name)
+			logging.Debugf("[LLM Detector] ✗ No match for pattern '%s'", pattern.Name)
 		}
 	}
 
-	log.Printf("[LLM Detector] ❌ NO PATTERNS MATCHED")
-	log.Printf("[LLM Detector] ═══ DETECTION END (NO MATCH) ═══")
+	logging.Debugf("[LLM Detector] ❌ NO PATTERNS MATCHED")
 	return &DetectedCommand{
 		Provider: ProviderUnknown,
 		Type:     CommandUnknown,
@@ -4,32 +4,11 @@ package llm
 import (
 	"regexp"
 	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
 )
 
 var (
-	// CSI sequences: ESC[ followed by optional ? or > or = for private modes, then params, then command
-	// Matches: ESC[0m, ESC[1;31m, ESC[?25l, ESC[?2004h, ESC[>4;2m, etc.
-	ansiCSI = regexp.MustCompile(`\x1b\[[?>=]?[0-9;]*[a-zA-Z]`)
-
-	// OSC sequences: ESC] followed by number, semicolon, text, and BEL or ST
-	// Matches: ESC]0;title^G, ESC]11;?^G (terminal queries)
-	ansiOSC = regexp.MustCompile(`\x1b\][0-9]*;[^\x07\x1b]*(?:\x07|\x1b\\)`)
-
-	// Simple escape sequences: ESC followed by single character
-	// Matches: ESC7, ESC8, ESCM, etc.
-	ansiSimple = regexp.MustCompile(`\x1b[78DMEH=>]`)
-
-	// DCS sequences: ESC P ... ESC \
-	ansiDCS = regexp.MustCompile(`\x1bP[^\x1b]*\x1b\\`)
-
-	// Orphaned CSI artifacts: literal [? sequences that remain after ESC was stripped
-	// This catches cases where ESC was already removed but bracket sequences remain
-	orphanedCSI = regexp.MustCompile(`\[\??[0-9;]*[a-zA-Z]`)
-
-	// Bracketed paste mode (kept for explicit handling)
-	bracketedPasteStart = regexp.MustCompile(`\x1b\[\??200[0-4]h`)
-	bracketedPasteEnd   = regexp.MustCompile(`\x1b\[\??200[0-4]l`)
-
 	// TUI frame characters
 	tuiFramePattern = regexp.MustCompile(`[╭╮╯╰│─┌┐└┘├┤┬┴┼═║╔╗╚╝╠╣╦╩╬]`)
 
@@ -41,29 +20,10 @@ var (
 	multiNewline = regexp.MustCompile(`\n{3,}`)
 )
 
-// CleanANSI removes all ANSI escape codes from text.
+// CleanANSI removes ANSI escape codes and control characters from text,
+// including CSI sequences that lost their ESC (see ansi.Clean).
 func CleanANSI(text string) string {
-	// First pass: Remove all standard ANSI sequences (with ESC byte intact)
-	cleaned := ansiCSI.ReplaceAllString(text, "")
-	cleaned = ansiOSC.ReplaceAllString(cleaned, "")
-	cleaned = ansiSimple.ReplaceAllString(cleaned, "")
-	cleaned = ansiDCS.ReplaceAllString(cleaned, "")
-	cleaned = bracketedPasteStart.ReplaceAllString(cleaned, "")
-	cleaned = bracketedPasteEnd.ReplaceAllString(cleaned, "")
-
-	// Second pass: Remove orphaned CSI artifacts (where ESC was already stripped)
-	// This handles cases like [?25l, [?2004h that remain after ESC removal
-	cleaned = orphanedCSI.ReplaceAllString(cleaned, "")
-
-	// Third pass: Filter remaining control characters
-	var result strings.Builder
-	for _, r := range cleaned {
-		// Keep: newline, carriage return, tab, printable ASCII, high Unicode
-		if r == '\n' || r == '\r' || r == '\t' || (r >= 32 && r < 127) || r >= 160 {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
+	return ansi.Clean(text)
 }
 
 // ParseCopilotOutput extracts clean content from GitHub Copilot CLI output.
@@ -221,3 +221,23 @@ func TestParseLLMOutput_ProviderRouting(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAiderLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want AiderActivity
+		ok   bool
+	}{
+		{"Applied edit to src/app.py", AiderActivity{EditedFile: "src/app.py"}, true},
+		{"\x1b[32mApplied edit to README.md\x1b[0m\r", AiderActivity{EditedFile: "README.md"}, true},
+		{"Commit 1a2b3c4 fix: handle empty input", AiderActivity{CommitSHA: "1a2b3c4"}, true},
+		{"Commit message below", AiderActivity{}, false},
+		{"Added src/app.py to the chat", AiderActivity{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseAiderLine(tt.line)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseAiderLine(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package llm
+
+import "strings"
+
+// providerNames maps the spellings of each provider used on command cards,
+// in config and by AM to the Provider
+var providerNames = map[string]Provider{
+	"copilot":        ProviderGitHubCopilot,
+	"gh-copilot":     ProviderGitHubCopilot,
+	"gh copilot":     ProviderGitHubCopilot,
+	"github-copilot": ProviderGitHubCopilot,
+	"claude":         ProviderClaude,
+	"claude-code":    ProviderClaude,
+	"aider":          ProviderAider,
+}
+
+// mentionOrder is the order InferProvider looks for a provider's name
+var mentionOrder = []struct {
+	name     string
+	provider Provider
+}{
+	{"copilot", ProviderGitHubCopilot},
+	{"claude", ProviderClaude},
+	{"aider", ProviderAider},
+}
+
+// defaultDetector holds the patterns InferProvider shares with PTY detection
+var defaultDetector = NewDetector()
+
+// ParseProvider returns the provider a name refers to, or ProviderUnknown.
+// Case and surrounding space are ignored.
+func ParseProvider(name string) Provider {
+	if provider, ok := providerNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return provider
+	}
+	return ProviderUnknown
+}
+
+// ParseCommandType returns the command type a name refers to, or
+// CommandUnknown.
+func ParseCommandType(name string) CommandType {
+	switch t := CommandType(strings.ToLower(strings.TrimSpace(name))); t {
+	case CommandChat, CommandSuggest, CommandExplain, CommandCode:
+		return t
+	}
+	return CommandUnknown
+}
+
+// InferProvider determines the provider a command launches, the same way
+// typed commands are detected, falling back to the first provider the text
+// names anywhere. It returns ProviderUnknown if there is none.
+func InferProvider(command string) Provider {
+	if detected := defaultDetector.DetectCommand(command); detected.Detected {
+		return detected.Provider
+	}
+	lower := strings.ToLower(command)
+	for _, mention := range mentionOrder {
+		if strings.Contains(lower, mention.name) {
+			return mention.provider
+		}
+	}
+	return ProviderUnknown
+}
+
+// ParseModel returns the model a command asks for with --model NAME or
+// --model=NAME, or "" if it doesn't choose one.
+func ParseModel(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if model, ok := strings.CutPrefix(field, "--model="); ok {
+			return strings.Trim(model, `"'`)
+		}
+		if field == "--model" && i+1 < len(fields) {
+			return strings.Trim(fields[i+1], `"'`)
+		}
+	}
+	return ""
+}
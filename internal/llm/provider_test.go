@@ -0,0 +1,59 @@
+package llm
+
+import "testing"
+
+func TestParseProvider(t *testing.T) {
+	for name, want := range map[string]Provider{
+		"copilot":         ProviderGitHubCopilot,
+		" GitHub-Copilot": ProviderGitHubCopilot,
+		"gh copilot":      ProviderGitHubCopilot,
+		"Claude":          ProviderClaude,
+		"claude-code":     ProviderClaude,
+		"aider":           ProviderAider,
+		"gpt":             ProviderUnknown,
+		"":                ProviderUnknown,
+	} {
+		if got := ParseProvider(name); got != want {
+			t.Errorf("ParseProvider(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if ParseCommandType(" Explain") != CommandExplain || ParseCommandType("run") != CommandUnknown {
+		t.Error("Unexpected ParseCommandType result")
+	}
+}
+
+func TestInferProvider(t *testing.T) {
+	tests := []struct {
+		command string
+		want    Provider
+	}{
+		{"gh copilot suggest 'list files'", ProviderGitHubCopilot},
+		{"aider --model sonnet", ProviderAider},
+		{"/usr/local/bin/claude", ProviderClaude},
+		// The command itself wins over a provider mentioned in its arguments
+		{"claude code 'port the copilot prompt'", ProviderClaude},
+		{"npx claude -p 'hi'", ProviderClaude},
+		{"ls -la", ProviderUnknown},
+	}
+	for _, tt := range tests {
+		if got := InferProvider(tt.command); got != tt.want {
+			t.Errorf("InferProvider(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestParseModel(t *testing.T) {
+	tests := map[string]string{
+		"claude --model sonnet":            "sonnet",
+		"aider --model=gpt-4o --yes":       "gpt-4o",
+		"claude --model 'claude-opus-4-1'": "claude-opus-4-1",
+		"claude -p 'which --model?'":       "",
+		"claude --model":                   "",
+		"gh copilot suggest 'list files'":  "",
+	}
+	for command, want := range tests {
+		if got := ParseModel(command); got != want {
+			t.Errorf("ParseModel(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RingBuffer keeps the most recent log entries and fans new ones out to subscribers.
+type RingBuffer struct {
+	mu          sync.Mutex
+	entries     []Entry
+	next        int // Index the next entry is written to
+	full        bool
+	seq         uint64
+	subscribers map[chan Entry]struct{}
+}
+
+// NewBuffer returns a ring buffer holding up to size entries.
+func NewBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &RingBuffer{entries: make([]Entry, size), subscribers: map[chan Entry]struct{}{}}
+}
+
+// add stores an entry, assigning its sequence number, and notifies subscribers.
+func (b *RingBuffer) add(e Entry) Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	e.Seq = b.seq
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default: // Slow subscriber; drop rather than block logging
+		}
+	}
+	return e
+}
+
+// Query returns buffered entries at or above minLevel and after since (zero = all),
+// oldest first, keeping at most limit of the newest (0 = no limit).
+func (b *RingBuffer) Query(minLevel slog.Level, since time.Time, limit int) []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ordered := b.entries[:b.next]
+	if b.full {
+		ordered = append(append([]Entry{}, b.entries[b.next:]...), b.entries[:b.next]...)
+	}
+
+	result := []Entry{}
+	for _, e := range ordered {
+		if levelOf(e) < minLevel || (!since.IsZero() && !e.Time.After(since)) {
+			continue
+		}
+		result = append(result, e)
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result
+}
+
+// Subscribe returns a channel receiving new entries until cancel is called.
+func (b *RingBuffer) Subscribe() (<-chan Entry, func()) {
+	ch := make(chan Entry, 100)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// levelOf parses an entry's level name back into a slog.Level.
+func levelOf(e Entry) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(e.Level)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseFilter reads ?level= and ?since= (RFC 3339 or a sequence number from a previous entry).
+func parseFilter(r *http.Request) (slog.Level, time.Time, uint64, error) {
+	query := r.URL.Query()
+	level, err := ParseLevel(query.Get("level"))
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+
+	var since time.Time
+	var afterSeq uint64
+	if s := query.Get("since"); s != "" {
+		if seq, err := strconv.ParseUint(s, 10, 64); err == nil {
+			afterSeq = seq
+		} else if since, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return 0, time.Time{}, 0, fmt.Errorf("invalid since %q (expected RFC 3339 time or sequence number)", s)
+		}
+	}
+	return level, since, afterSeq, nil
+}
+
+// HandleLogs returns buffered server log entries (GET ?level=&since=&limit=)
+func HandleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	level, since, afterSeq, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	entries := Buffer().Query(level, since, 0)
+	if afterSeq > 0 {
+		filtered := []Entry{}
+		for _, e := range entries {
+			if e.Seq > afterSeq {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+	})
+}
+
+// HandleLevel reports the server log level (GET) or changes it until the
+// server restarts (POST {"level": "debug"}).
+func HandleLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		l, err := ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		SetLevel(l)
+		slog.Info("[Logging] Log level set to " + l.String())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"level": strings.ToLower(Level().String()),
+	})
+}
+
+// HandleStream tails the server log over Server-Sent Events (GET ?level=)
+func HandleStream(w http.ResponseWriter, r *http.Request) {
+	level, _, _, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	entries, cancel := Buffer().Subscribe()
+	defer cancel()
+
+	fmt.Fprintf(w, "event: connected\ndata: {}\n\n")
+	flusher.Flush()
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprintf(w, ": keepalive\n\n")
+			flusher.Flush()
+		case e := <-entries:
+			if levelOf(e) < level {
+				continue
+			}
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
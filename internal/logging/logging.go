@@ -0,0 +1,254 @@
+// Package logging provides Forge's leveled, structured server log. Setup installs
+// it as the default slog handler, which also captures the standard log package,
+// so existing log.Printf calls land in the same output and ring buffer.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBufferSize is how many recent entries are kept in memory
+const DefaultBufferSize = 2000
+
+// Entry is one log record as kept in the ring buffer and served by the log API.
+type Entry struct {
+	Seq       uint64                 `json:"seq"`
+	Time      time.Time              `json:"time"`
+	Level     string                 `json:"level"` // "DEBUG", "INFO", "WARN", or "ERROR"
+	Component string                 `json:"component,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Options configures Setup.
+type Options struct {
+	JSON       bool       // Write JSON lines instead of text
+	Level      slog.Level // Minimum level written and buffered (see SetLevel)
+	BufferSize int        // Ring buffer capacity (0 = DefaultBufferSize)
+}
+
+// OptionsFromEnv reads FORGE_LOG_FORMAT ("json" or "text") and FORGE_LOG_LEVEL.
+func OptionsFromEnv() Options {
+	opts := Options{JSON: strings.EqualFold(os.Getenv("FORGE_LOG_FORMAT"), "json")}
+	if level, err := ParseLevel(os.Getenv("FORGE_LOG_LEVEL")); err == nil {
+		opts.Level = level
+	}
+	return opts
+}
+
+var (
+	defaultBuffer = NewBuffer(DefaultBufferSize)
+	level         slog.LevelVar // Level of the handler installed by Setup
+)
+
+// Buffer returns the ring buffer fed by the handler installed by Setup.
+func Buffer() *RingBuffer {
+	return defaultBuffer
+}
+
+// Setup installs the structured logger as the slog and log package default,
+// writing to w and recording entries in Buffer().
+func Setup(w io.Writer, opts Options) {
+	if opts.BufferSize > 0 {
+		defaultBuffer = NewBuffer(opts.BufferSize)
+	}
+	level.Set(opts.Level)
+	handler := NewHandler(w, defaultBuffer, opts)
+	handler.level = &level
+	slog.SetDefault(slog.New(handler))
+}
+
+// Level returns the minimum level of the log installed by Setup.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the minimum level of the log installed by Setup while
+// the server runs, e.g. to debug to see the AM pipeline's detailed trace.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Debugf logs a "[Component] message" line, like log.Printf, at debug
+// level. The message is only formatted when debug logging is on.
+func Debugf(format string, args ...interface{}) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	slog.Debug(fmt.Sprintf(format, args...))
+}
+
+// For returns a logger that tags entries with a component, e.g. For("Updater").
+func For(component string) *slog.Logger {
+	return slog.Default().With("component", component)
+}
+
+// ParseLevel parses debug/info/warn/error (case-insensitive). Empty means info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// componentPrefix matches the "[Component] " prefix used by existing log lines
+var componentPrefix = regexp.MustCompile(`^\[([A-Za-z0-9 _./-]+)\]\s*`)
+
+// Handler is a slog.Handler writing text or JSON lines and feeding a RingBuffer.
+type Handler struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	buffer *RingBuffer
+	opts   Options
+	level  slog.Leveler
+	attrs  []slog.Attr
+	group  string
+}
+
+// NewHandler returns a handler writing to out (may be nil) and recording into buffer.
+func NewHandler(out io.Writer, buffer *RingBuffer, opts Options) *Handler {
+	return &Handler{mu: &sync.Mutex{}, out: out, buffer: buffer, opts: opts, level: opts.Level}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), h.qualify(attrs)...)
+	return &clone
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	clone := *h
+	if clone.group != "" {
+		name = clone.group + "." + name
+	}
+	clone.group = name
+	return &clone
+}
+
+func (h *Handler) qualify(attrs []slog.Attr) []slog.Attr {
+	if h.group == "" {
+		return attrs
+	}
+	qualified := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		qualified[i] = slog.Attr{Key: h.group + "." + a.Key, Value: a.Value}
+	}
+	return qualified
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	entry := Entry{Time: r.Time, Message: r.Message}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	attrs := append([]slog.Attr{}, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, h.qualify([]slog.Attr{a})...)
+		return true
+	})
+	for _, a := range attrs {
+		if a.Key == "component" {
+			entry.Component = a.Value.String()
+			continue
+		}
+		if entry.Fields == nil {
+			entry.Fields = map[string]interface{}{}
+		}
+		entry.Fields[a.Key] = a.Value.Any()
+	}
+
+	// Lines from the log package: "[Component] message", with the level implied by wording
+	level := r.Level
+	if m := componentPrefix.FindStringSubmatch(entry.Message); m != nil && entry.Component == "" {
+		entry.Component = m[1]
+		entry.Message = entry.Message[len(m[0]):]
+	}
+	if level == slog.LevelInfo && len(attrs) == 0 {
+		level = inferLevel(entry.Message)
+	}
+	if level < h.level.Level() {
+		return nil
+	}
+	entry.Level = level.String()
+	entry.Message = strings.TrimRight(entry.Message, "\n")
+
+	entry = h.buffer.add(entry)
+	if h.out == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(h.format(entry))
+	return err
+}
+
+// format renders an entry as a JSON or text line.
+func (h *Handler) format(e Entry) []byte {
+	if h.opts.JSON {
+		data, err := json.Marshal(e)
+		if err != nil {
+			data = []byte(fmt.Sprintf(`{"level":"ERROR","message":%q}`, err.Error()))
+		}
+		return append(data, '\n')
+	}
+
+	var b strings.Builder
+	b.WriteString(e.Time.Format("2006/01/02 15:04:05"))
+	if e.Level != slog.LevelInfo.String() {
+		b.WriteString(" " + e.Level)
+	}
+	if e.Component != "" {
+		b.WriteString(" [" + e.Component + "]")
+	}
+	b.WriteString(" " + e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, e.Fields[k])
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// inferLevel guesses the level of an unstructured message from its wording.
+func inferLevel(msg string) slog.Level {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "error"), strings.Contains(lower, "failed"), strings.Contains(lower, "panic"):
+		return slog.LevelError
+	case strings.Contains(lower, "warning"), strings.Contains(lower, "timed out"), strings.Contains(lower, "timeout"):
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerCapturesStandardLog(t *testing.T) {
+	oldDefault, oldFlags := slog.Default(), log.Flags()
+	defer func() {
+		slog.SetDefault(oldDefault)
+		log.SetFlags(oldFlags)
+	}()
+
+	var out bytes.Buffer
+	Setup(&out, Options{BufferSize: 10})
+
+	log.Printf("[Updater] Download failed: %v", "timeout")
+	log.Printf("[API] Loading commands...")
+	For("Terminal").Info("session started", "tab", "t1")
+
+	entries := Buffer().Query(slog.LevelDebug, time.Time{}, 0)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Component != "Updater" || e.Level != "ERROR" || e.Message != "Download failed: timeout" {
+		t.Errorf("unexpected legacy entry %+v", e)
+	}
+	if e := entries[1]; e.Component != "API" || e.Level != "INFO" {
+		t.Errorf("unexpected info entry %+v", e)
+	}
+	if e := entries[2]; e.Component != "Terminal" || e.Fields["tab"] != "t1" {
+		t.Errorf("unexpected structured entry %+v", e)
+	}
+	if !strings.Contains(out.String(), "ERROR [Updater] Download failed: timeout") || !strings.Contains(out.String(), "[Terminal] session started tab=t1") {
+		t.Errorf("unexpected text output:\n%s", out.String())
+	}
+
+	if errs := Buffer().Query(slog.LevelWarn, time.Time{}, 0); len(errs) != 1 {
+		t.Errorf("expected 1 entry at warn or above, got %d", len(errs))
+	}
+}
+
+func TestHandlerJSON(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(NewHandler(&out, NewBuffer(5), Options{JSON: true}))
+	logger.Warn("disk low", "component", "Storage", "freeMb", 12)
+
+	var e Entry
+	if err := json.Unmarshal(out.Bytes(), &e); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if e.Level != "WARN" || e.Component != "Storage" || e.Message != "disk low" || e.Fields["freeMb"] != float64(12) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestRingBufferWraps(t *testing.T) {
+	buffer := NewBuffer(3)
+	logger := slog.New(NewHandler(nil, buffer, Options{}))
+	for i := 0; i < 5; i++ {
+		logger.Info("entry", "i", i)
+	}
+
+	entries := buffer.Query(slog.LevelDebug, time.Time{}, 0)
+	if len(entries) != 3 || entries[0].Seq != 3 || entries[2].Seq != 5 {
+		t.Fatalf("expected the newest 3 entries in order, got %+v", entries)
+	}
+	if limited := buffer.Query(slog.LevelDebug, time.Time{}, 1); len(limited) != 1 || limited[0].Seq != 5 {
+		t.Errorf("limit should keep the newest entries, got %+v", limited)
+	}
+
+	ch, cancel := buffer.Subscribe()
+	logger.Info("live")
+	select {
+	case e := <-ch:
+		if e.Message != "live" {
+			t.Errorf("unexpected streamed entry %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("subscriber did not receive entry")
+	}
+	cancel()
+	cancel() // Safe to call twice
+}
+
+func TestHandleLogs(t *testing.T) {
+	oldBuffer := defaultBuffer
+	defer func() { defaultBuffer = oldBuffer }()
+	defaultBuffer = NewBuffer(10)
+
+	logger := slog.New(NewHandler(nil, defaultBuffer, Options{}))
+	logger.Info("one")
+	logger.Error("two")
+	logger.Info("three")
+
+	rec := httptest.NewRecorder()
+	HandleLogs(rec, httptest.NewRequest("GET", "/api/logs?level=error", nil))
+	var resp struct {
+		Entries []Entry `json:"entries"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Entries) != 1 || resp.Entries[0].Message != "two" {
+		t.Errorf("expected only the error entry, got %+v", resp.Entries)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleLogs(rec, httptest.NewRequest("GET", "/api/logs?since=2", nil))
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Entries) != 1 || resp.Entries[0].Message != "three" {
+		t.Errorf("expected entries after seq 2, got %+v", resp.Entries)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleLogs(rec, httptest.NewRequest("GET", "/api/logs?level=loud", nil))
+	if rec.Code != 400 {
+		t.Errorf("expected 400 for invalid level, got %d", rec.Code)
+	}
+}
+
+func TestHandleLevel(t *testing.T) {
+	oldDefault, oldFlags := slog.Default(), log.Flags()
+	defer func() {
+		slog.SetDefault(oldDefault)
+		log.SetFlags(oldFlags)
+	}()
+
+	var out bytes.Buffer
+	Setup(&out, Options{BufferSize: 10})
+	Debugf("[LLM Logger] hidden %d", 1)
+
+	rec := httptest.NewRecorder()
+	HandleLevel(rec, httptest.NewRequest("POST", "/api/logs/level", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"level":"debug"`) || Level() != slog.LevelDebug {
+		t.Fatalf("expected the level set to debug, got %d %s", rec.Code, rec.Body.String())
+	}
+	Debugf("[LLM Logger] shown %d", 2)
+	entries := Buffer().Query(slog.LevelDebug, time.Time{}, 0)
+	if e := entries[len(entries)-1]; e.Level != "DEBUG" || e.Component != "LLM Logger" || e.Message != "shown 2" {
+		t.Errorf("unexpected debug entry %+v", e)
+	}
+	if strings.Contains(out.String(), "hidden") {
+		t.Errorf("expected debug lines dropped at info level:\n%s", out.String())
+	}
+
+	rec = httptest.NewRecorder()
+	HandleLevel(rec, httptest.NewRequest("POST", "/api/logs/level", strings.NewReader(`{"level":"loud"}`)))
+	if rec.Code != 400 || Level() != slog.LevelDebug {
+		t.Errorf("expected 400 and the level unchanged, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	HandleLevel(rec, httptest.NewRequest("GET", "/api/logs/level", nil))
+	if !strings.Contains(rec.Body.String(), `"level":"debug"`) {
+		t.Errorf("unexpected level response %s", rec.Body.String())
+	}
+}
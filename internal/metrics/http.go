@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/middleware"
+)
+
+// HTTP records request counts and latency by method, route and status.
+type HTTP struct {
+	requests *CounterVec
+	duration *HistogramVec
+	route    func(*http.Request) string
+}
+
+// NewHTTP registers the HTTP request metrics. route maps a request to the
+// pattern that serves it, keeping label cardinality bounded; paths with IDs
+// in them must not be used as labels directly.
+func (r *Registry) NewHTTP(route func(*http.Request) string) *HTTP {
+	return &HTTP{
+		requests: r.NewCounterVec("forge_http_requests_total",
+			"HTTP requests served, by method, route and status code.", "method", "route", "code"),
+		duration: r.NewHistogramVec("forge_http_request_duration_seconds",
+			"HTTP request latency in seconds, by method and route. WebSocket upgrades are not included.",
+			DefaultBuckets, "method", "route"),
+		route: route,
+	}
+}
+
+// Middleware records every request served by next.
+func (m *HTTP) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := middleware.NewRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := m.route(r)
+		if route == "" {
+			route = "other"
+		}
+		m.requests.Inc(r.Method, route, strconv.Itoa(rec.Status()))
+		// A hijacked request is a WebSocket session; its duration is the session's
+		if !rec.Hijacked() {
+			m.duration.Observe(time.Since(start).Seconds(), r.Method, route)
+		}
+	})
+}
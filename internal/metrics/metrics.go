@@ -0,0 +1,281 @@
+// Package metrics serves runtime metrics in the Prometheus text exposition
+// format, so self-hosted Forge can be scraped like any other service. It
+// implements the small subset of the format Forge needs (counters, gauges and
+// histograms with labels) rather than pulling in the Prometheus client.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is a metric family's type
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// Sample is one value of a metric family. Suffix is appended to the family
+// name (histograms report _bucket, _sum and _count samples).
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Label is a name/value pair attached to a sample
+type Label struct {
+	Name  string
+	Value string
+}
+
+type family struct {
+	name, help string
+	typ        Type
+	collect    func() []Sample
+}
+
+// Registry holds the metric families served by Handler.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a family whose samples are produced by collect at scrape time.
+func (r *Registry) Register(name, help string, typ Type, collect func() []Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.families = append(r.families, &family{name: name, help: help, typ: typ, collect: collect})
+}
+
+// CounterFunc registers an unlabelled counter read from f at scrape time.
+func (r *Registry) CounterFunc(name, help string, f func() float64) {
+	r.Register(name, help, Counter, func() []Sample { return []Sample{{Value: f()}} })
+}
+
+// GaugeFunc registers an unlabelled gauge read from f at scrape time.
+func (r *Registry) GaugeFunc(name, help string, f func() float64) {
+	r.Register(name, help, Gauge, func() []Sample { return []Sample{{Value: f()}} })
+}
+
+// Write renders every family in the text exposition format, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]*family(nil), r.families...)
+	r.mu.Unlock()
+	sort.SliceStable(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	var b strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+		for _, s := range f.collect() {
+			b.WriteString(f.name + s.Suffix)
+			writeLabels(&b, s.Labels)
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.Value))
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the registry (GET /metrics)
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	}
+}
+
+func writeLabels(b *strings.Builder, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name + `="` + escapeLabel(l.Value) + `"`)
+	}
+	b.WriteByte('}')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelKey joins label values into a map key
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func makeLabels(names, values []string) []Label {
+	labels := make([]Label, len(names))
+	for i, name := range names {
+		labels[i] = Label{Name: name, Value: values[i]}
+	}
+	return labels
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	names  []string
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+// NewCounterVec registers a counter with the given label names.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{names: labelNames, values: make(map[string]*counterValue)}
+	r.Register(name, help, Counter, c.collect)
+	return c
+}
+
+// Add increases the counter for the given label values, which must match the
+// label names in number.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := labelKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labels: append([]string(nil), labelValues...)}
+		c.values[key] = cv
+	}
+	cv.value += v
+}
+
+// Inc adds one to the counter for the given label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) collect() []Sample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	samples := make([]Sample, 0, len(c.values))
+	for _, key := range sortedKeys(c.values) {
+		cv := c.values[key]
+		samples = append(samples, Sample{Labels: makeLabels(c.names, cv.labels), Value: cv.value})
+	}
+	return samples
+}
+
+// DefaultBuckets are latency buckets in seconds suited to local HTTP requests
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	names   []string
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram with the given upper bucket bounds
+// (ascending) and label names.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{names: labelNames, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.Register(name, help, Histogram, h.collect)
+	return h
+}
+
+// Observe records a value for the given label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := labelKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labels: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += v
+}
+
+func (h *HistogramVec) collect() []Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var samples []Sample
+	for _, key := range sortedKeys(h.values) {
+		hv := h.values[key]
+		labels := makeLabels(h.names, hv.labels)
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += hv.counts[i]
+			samples = append(samples, Sample{
+				Suffix: "_bucket",
+				Labels: append(append([]Label(nil), labels...), Label{"le", formatValue(bound)}),
+				Value:  float64(cumulative),
+			})
+		}
+		samples = append(samples,
+			Sample{Suffix: "_bucket", Labels: append(append([]Label(nil), labels...), Label{"le", "+Inf"}), Value: float64(hv.count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: hv.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(hv.count)},
+		)
+	}
+	return samples
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
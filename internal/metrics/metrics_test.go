@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func assertContains(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected line %q in output:\n%s", line, out)
+		}
+	}
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_requests_total", "Requests.", "code")
+	c.Inc("200")
+	c.Inc("200")
+	c.Add(3, `a"b`)
+	r.GaugeFunc("test_sessions", "Open\nsessions.", func() float64 { return 4 })
+
+	out := render(t, r)
+	assertContains(t, out,
+		"# HELP test_requests_total Requests.",
+		"# TYPE test_requests_total counter",
+		`test_requests_total{code="200"} 2`,
+		`test_requests_total{code="a\"b"} 3`,
+		`# HELP test_sessions Open\nsessions.`,
+		"# TYPE test_sessions gauge",
+		"test_sessions 4",
+	)
+	if strings.Index(out, "test_requests_total") > strings.Index(out, "test_sessions") {
+		t.Error("Expected families sorted by name")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("test_duration_seconds", "Latency.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(5, "/a")
+
+	assertContains(t, render(t, r),
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{route="/a",le="0.1"} 1`,
+		`test_duration_seconds_bucket{route="/a",le="1"} 2`,
+		`test_duration_seconds_bucket{route="/a",le="+Inf"} 3`,
+		`test_duration_seconds_sum{route="/a"} 5.55`,
+		`test_duration_seconds_count{route="/a"} 3`,
+	)
+}
+
+func TestRuntime(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterRuntime(time.Unix(1700000000, 0))
+	out := render(t, r)
+	for _, name := range []string{"go_goroutines ", "go_memstats_alloc_bytes ", "go_gc_cycles_total "} {
+		if !strings.Contains(out, "\n"+name) {
+			t.Errorf("Expected %s in output", name)
+		}
+	}
+	assertContains(t, out, "process_start_time_seconds 1.7e+09")
+}
+
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed bool
+}
+
+func (f *flushRecorder) Flush() { f.flushed = true }
+
+func TestHTTPMiddleware(t *testing.T) {
+	r := NewRegistry()
+	m := r.NewHTTP(func(req *http.Request) string {
+		if strings.HasPrefix(req.URL.Path, "/api/items/") {
+			return "/api/items/"
+		}
+		return ""
+	})
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/api/items/missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.(http.Flusher).Flush()
+		w.Write([]byte("ok"))
+	}))
+
+	for _, path := range []string{"/api/items/1", "/api/items/2", "/api/items/missing", "/elsewhere"} {
+		rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if path == "/api/items/1" && !rec.flushed {
+			t.Error("Expected Flush to reach the underlying writer")
+		}
+	}
+
+	out := render(t, r)
+	assertContains(t, out,
+		`forge_http_requests_total{method="GET",route="/api/items/",code="200"} 2`,
+		`forge_http_requests_total{method="GET",route="/api/items/",code="404"} 1`,
+		`forge_http_requests_total{method="GET",route="other",code="200"} 1`,
+		`forge_http_request_duration_seconds_count{method="GET",route="/api/items/"} 3`,
+	)
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.GaugeFunc("test_up", "Up.", func() float64 { return 1 })
+
+	rec := httptest.NewRecorder()
+	r.Handler()(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	assertContains(t, rec.Body.String(), "test_up 1")
+
+	rec = httptest.NewRecorder()
+	r.Handler()(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// memStatsMaxAge bounds how often a scrape stops the world to read memory stats
+const memStatsMaxAge = time.Second
+
+// RegisterRuntime adds goroutine, memory, GC and process start time metrics,
+// named as the Prometheus Go client names them so existing dashboards work.
+func (r *Registry) RegisterRuntime(startedAt time.Time) {
+	var (
+		mu      sync.Mutex
+		stats   runtime.MemStats
+		readAt  time.Time
+		memStat = func(f func(*runtime.MemStats) float64) func() float64 {
+			return func() float64 {
+				mu.Lock()
+				defer mu.Unlock()
+				if time.Since(readAt) > memStatsMaxAge {
+					runtime.ReadMemStats(&stats)
+					readAt = time.Now()
+				}
+				return f(&stats)
+			}
+		}
+	)
+
+	r.GaugeFunc("go_goroutines", "Number of goroutines that currently exist.",
+		func() float64 { return float64(runtime.NumGoroutine()) })
+	r.GaugeFunc("go_threads", "Number of OS threads created.",
+		func() float64 { n, _ := runtime.ThreadCreateProfile(nil); return float64(n) })
+	r.GaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.",
+		memStat(func(m *runtime.MemStats) float64 { return float64(m.Alloc) }))
+	r.CounterFunc("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.",
+		memStat(func(m *runtime.MemStats) float64 { return float64(m.TotalAlloc) }))
+	r.GaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from system.",
+		memStat(func(m *runtime.MemStats) float64 { return float64(m.Sys) }))
+	r.GaugeFunc("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.",
+		memStat(func(m *runtime.MemStats) float64 { return float64(m.HeapInuse) }))
+	r.GaugeFunc("go_memstats_heap_objects", "Number of allocated objects.",
+		memStat(func(m *runtime.MemStats) float64 { return float64(m.HeapObjects) }))
+	r.CounterFunc("go_gc_cycles_total", "Number of completed GC cycles.",
+		memStat(func(m *runtime.MemStats) float64 { return float64(m.NumGC) }))
+	r.GaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.",
+		func() float64 { return float64(startedAt.UnixNano()) / 1e9 })
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Capabilities that change the user's system. Each can be turned off in
+// config; locked-down mode turns them all off, so Forge can't type into
+// tabs, run commands, change or delete files, erase AM history, or change
+// its settings. Leaving locked-down mode then takes an edit to config.json.
+const (
+	CapAssistantExecute = "assistant.execute" // The assistant runs commands
+	CapConfigWrite      = "config.write"      // Settings, the update policy or imported bundles are changed
+	CapFilesDelete      = "files.delete"      // Files, stored objects, AM logs or command history are deleted, or the trash restored or purged
+	CapFilesWrite       = "files.write"       // Files or stored objects are written or created, or permissions changed
+	CapHooksApply       = "hooks.apply"       // Hooks are installed or changed (reserved; no routes yet)
+	CapTerminalInject   = "terminal.inject"   // Input is typed into tabs (cards, chains, schedules, snippets, jumps) or the editor is launched
+)
+
+// knownCapabilities are the capabilities config may name
+var knownCapabilities = []string{CapAssistantExecute, CapConfigWrite, CapFilesDelete, CapFilesWrite, CapHooksApply, CapTerminalInject}
+
+// ValidateCapabilities checks that flags only names known capabilities.
+func ValidateCapabilities(flags map[string]bool) error {
+	for name := range flags {
+		if !isKnownCapability(name) {
+			return fmt.Errorf("unknown capability %q (expected %s)", name, strings.Join(knownCapabilities, ", "))
+		}
+	}
+	return nil
+}
+
+func isKnownCapability(name string) bool {
+	for _, known := range knownCapabilities {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities decides which capabilities are enabled. All are, by default.
+type Capabilities struct {
+	mu         sync.RWMutex
+	lockedDown bool
+	disabled   map[string]bool
+}
+
+// NewCapabilities returns a policy with every capability enabled.
+func NewCapabilities() *Capabilities {
+	return &Capabilities{disabled: map[string]bool{}}
+}
+
+// Set replaces the policy: with lockedDown every capability is off,
+// otherwise those flagged false are. Unknown names are ignored.
+func (c *Capabilities) Set(lockedDown bool, flags map[string]bool) {
+	disabled := map[string]bool{}
+	for name, enabled := range flags {
+		if !enabled && isKnownCapability(name) {
+			disabled[name] = true
+		}
+	}
+	c.mu.Lock()
+	c.lockedDown, c.disabled = lockedDown, disabled
+	c.mu.Unlock()
+}
+
+// Enabled reports whether a capability is on.
+func (c *Capabilities) Enabled(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.lockedDown && !c.disabled[name]
+}
+
+// Require refuses requests that would change something (anything but GET,
+// HEAD and OPTIONS) with 403 Forbidden while capability is off.
+func (c *Capabilities) Require(capability string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next(w, r)
+			return
+		}
+		if !c.Enabled(capability) {
+			Forbidden(w, capability)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Forbidden responds 403 Forbidden for a capability that is off.
+func Forbidden(w http.ResponseWriter, capability string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    false,
+		"error":      "This feature is turned off (" + capability + ")",
+		"capability": capability,
+	})
+}
+
+// Handle reports which capabilities are enabled (GET).
+func (c *Capabilities) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	names := append([]string(nil), knownCapabilities...)
+	sort.Strings(names)
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[name] = c.Enabled(name)
+	}
+	c.mu.RLock()
+	lockedDown := c.lockedDown
+	c.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"lockedDown":   lockedDown,
+		"capabilities": enabled,
+	})
+}
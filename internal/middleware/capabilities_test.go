@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCapabilitiesRequire(t *testing.T) {
+	caps := NewCapabilities()
+	ran := 0
+	handler := caps.Require(CapFilesDelete, func(w http.ResponseWriter, r *http.Request) { ran++ })
+
+	serve := func(method string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, "/api/v1/files/delete", nil))
+		return rec.Code
+	}
+
+	if code := serve("POST"); code != http.StatusOK || ran != 1 {
+		t.Errorf("Expected writes allowed by default, got %d", code)
+	}
+
+	caps.Set(false, map[string]bool{CapFilesDelete: false, CapTerminalInject: true, "bogus": false})
+	if code := serve("POST"); code != http.StatusForbidden || ran != 1 {
+		t.Errorf("Expected the write refused, got %d", code)
+	}
+	if code := serve("GET"); code != http.StatusOK || ran != 2 {
+		t.Errorf("Expected reads allowed, got %d", code)
+	}
+	if !caps.Enabled(CapTerminalInject) {
+		t.Error("Expected terminal.inject to stay enabled")
+	}
+
+	caps.Set(true, map[string]bool{CapTerminalInject: true})
+	for _, name := range knownCapabilities {
+		if caps.Enabled(name) {
+			t.Errorf("Expected %s off when locked down", name)
+		}
+	}
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	if err := ValidateCapabilities(map[string]bool{CapAssistantExecute: false, CapHooksApply: true}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := ValidateCapabilities(map[string]bool{"files.rename": false}); err == nil {
+		t.Error("Expected an error for an unknown capability")
+	}
+}
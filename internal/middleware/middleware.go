@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+)
+
+// RequestIDHeader carries the request ID; a client-supplied value is kept
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID returns the ID RequestLog assigned to the request, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID limits client-supplied IDs echoed into logs and headers
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestLog gives every request an ID (echoed in the X-Request-ID response
+// header) and logs it when it completes. Successful requests are logged at
+// debug level (FORGE_LOG_LEVEL=debug) so routine polling stays out of the log;
+// client errors are logged at info and server errors at error.
+func RequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		start := time.Now()
+		rec := NewRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelDebug
+		switch {
+		case rec.Status() >= 500:
+			level = slog.LevelError
+		case rec.Status() >= 400:
+			level = slog.LevelInfo
+		}
+		logging.For("HTTP").Log(r.Context(), level, fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, rec.Status()),
+			"requestId", id,
+			"status", rec.Status(),
+			"duration", time.Since(start).Round(time.Microsecond).String(),
+		)
+	})
+}
+
+// Recover turns a panic in a handler into a 500 response and an error log
+// entry with the stack, instead of a silently dropped connection.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := NewRecorder(w)
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// Handlers abort responses deliberately with ErrAbortHandler
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			id := RequestID(r.Context())
+			logging.For("HTTP").Error(fmt.Sprintf("Panic serving %s %s: %v", r.Method, r.URL.Path, p),
+				"requestId", id,
+				"stack", string(debug.Stack()),
+			)
+			if !rec.Written() {
+				msg := "Internal server error"
+				if id != "" {
+					msg += " (request " + id + ")"
+				}
+				http.Error(rec, msg, http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
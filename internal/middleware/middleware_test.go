@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/logging"
+)
+
+// captureLog routes the default logger into a buffer for the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(&buf, logging.NewBuffer(10), logging.Options{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(original) })
+	return &buf
+}
+
+func TestRequestLog(t *testing.T) {
+	logs := captureLog(t)
+	var seen string
+	handler := RequestLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		http.Error(w, "missing", http.StatusNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/things", nil))
+	id := rec.Header().Get(RequestIDHeader)
+	if id == "" || id != seen {
+		t.Errorf("Expected the response ID %q to match the handler's %q", id, seen)
+	}
+	if out := logs.String(); !strings.Contains(out, "GET /api/v1/things 404") || !strings.Contains(out, id) {
+		t.Errorf("Expected a log line with the request and its ID, got %q", out)
+	}
+
+	// A well-formed client ID is kept; anything else is replaced
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "trace-123")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got != "trace-123" {
+		t.Errorf("Expected client request ID to be kept, got %q", got)
+	}
+	req.Header.Set(RequestIDHeader, "bad id\twith spaces")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got == "bad id\twith spaces" {
+		t.Error("Expected malformed client request ID to be replaced")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	logs := captureLog(t)
+	handler := RequestLog(Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/explode", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+	id := rec.Header().Get(RequestIDHeader)
+	if !strings.Contains(rec.Body.String(), id) {
+		t.Errorf("Expected the error to name the request ID %s, got %q", id, rec.Body.String())
+	}
+	out := logs.String()
+	if !strings.Contains(out, "Panic serving POST /api/v1/explode: boom") || !strings.Contains(out, "POST /api/v1/explode 500") {
+		t.Errorf("Expected panic and request to be logged, got %q", out)
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	captureLog(t)
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("late")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Body.String() != "partial" {
+		t.Errorf("Expected the started response to be left alone, got %q", rec.Body.String())
+	}
+}
+
+func TestRecoverRepanicsAbort(t *testing.T) {
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("Expected ErrAbortHandler to propagate, got %v", p)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(60, 2) // One token a second
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("Expected burst request %d to be allowed", i+1)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok || wait != time.Second {
+		t.Errorf("Expected limit with a 1s wait, got %v %v", ok, wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("Expected other clients to have their own bucket")
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("Expected a token after refill")
+	}
+}
+
+func TestRateLimiterWrap(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	handler := l.Wrap(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+
+	req := httptest.NewRequest("GET", "/api/v1/files/list", nil)
+	req.RemoteAddr = "192.0.2.1:5000"
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected first request to pass, got %d", rec.Code)
+	}
+
+	req.RemoteAddr = "192.0.2.1:5001" // Same client, new connection
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected 429 with Retry-After 60, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		pattern, origin string
+		want            bool
+	}{
+		{"*", "https://anything.example", true},
+		{"https://portal.local", "https://portal.local", true},
+		{"https://portal.local", "http://portal.local", false},
+		{"https://portal.local", "https://portal.local:8443", false},
+		{"https://myname.github.io/forge-terminal/", "https://myname.github.io", true},
+		{"https://*.github.io", "https://myname.github.io", true},
+		{"https://*.github.io", "https://github.io", false},
+		{"https://*.github.io", "https://evilgithub.io", false},
+		{"http://localhost:*", "http://localhost:5173", true},
+		{"http://localhost:*", "http://localhost.evil.com:80", false},
+	}
+	for _, tt := range tests {
+		if got := matchOrigin(tt.pattern, tt.origin); got != tt.want {
+			t.Errorf("matchOrigin(%q, %q) = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"portal.local", "https://por*tal.local", "https://a.com; script-src *", "https://a.com:port"} {
+		if ValidateOrigin(bad) == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestOriginsMiddleware(t *testing.T) {
+	origins := NewOrigins()
+	handler := origins.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/version", nil)
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodGet, "http://localhost:5173")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "http://localhost:5173" {
+		t.Errorf("Expected a default origin to be allowed, got %v", rec.Header())
+	}
+	if rec.Header().Get("Content-Security-Policy") != "frame-ancestors 'self'" || rec.Header().Get("X-Frame-Options") != "SAMEORIGIN" {
+		t.Errorf("Expected framing limited to Forge itself, got %v", rec.Header())
+	}
+	if rec := request(http.MethodOptions, "http://localhost:5173"); rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Errorf("Expected preflight to be answered, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := request(http.MethodGet, "https://evil.example"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected an unknown origin to get no CORS headers")
+	}
+
+	// Locked down to one origin, and embeddable by a portal
+	origins.Set([]string{"https://tools.local"}, []string{"https://portal.local", "not an origin"})
+	if rec := request(http.MethodGet, "http://localhost:5173"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Expected the defaults to be replaced by the configured origins")
+	}
+	rec = request(http.MethodGet, "https://tools.local")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://tools.local" {
+		t.Error("Expected the configured origin to be allowed")
+	}
+	if rec.Header().Get("Content-Security-Policy") != "frame-ancestors 'self' https://portal.local" || rec.Header().Get("X-Frame-Options") != "" {
+		t.Errorf("Expected the portal to be allowed to frame Forge, got %v", rec.Header())
+	}
+}
+
+func TestOriginsAllowRequest(t *testing.T) {
+	origins := NewOrigins()
+	origins.Set([]string{"https://tools.local"}, nil)
+	for origin, want := range map[string]bool{
+		"":                      true, // Not a browser
+		"http://127.0.0.1:8333": true, // Same origin
+		"https://tools.local":   true,
+		"https://evil.example":  false,
+	} {
+		req := httptest.NewRequest("GET", "http://127.0.0.1:8333/ws", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if got := origins.AllowRequest(req); got != want {
+			t.Errorf("AllowRequest(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultAllowedOrigins are the other origins allowed to use the API and open
+// terminal WebSockets when none are configured: local dev servers, GitHub
+// Pages, and Codespaces.
+var DefaultAllowedOrigins = []string{
+	"http://localhost:*",
+	"http://127.0.0.1:*",
+	"https://*.github.io",
+	"https://*.app.github.dev",
+}
+
+// Origins decides which other origins may call the API and open WebSockets
+// (CORS), and which may embed Forge in a frame. The page's own origin is
+// always allowed both.
+type Origins struct {
+	mu             sync.RWMutex
+	allowed        []string
+	frameAncestors []string
+}
+
+// NewOrigins returns the default policy: DefaultAllowedOrigins, and no
+// embedding by other origins.
+func NewOrigins() *Origins {
+	o := &Origins{}
+	o.Set(nil, nil)
+	return o
+}
+
+// Set replaces the policy. Entries are origins such as "https://portal.local",
+// optionally with a wildcard subdomain ("https://*.example.com") or port
+// ("http://localhost:*"), or "*" for any. An empty allowed list means
+// DefaultAllowedOrigins; an empty frameAncestors list allows no other origin.
+// Invalid entries are ignored.
+func (o *Origins) Set(allowed, frameAncestors []string) {
+	allowed, frameAncestors = validOrigins(allowed), validOrigins(frameAncestors)
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedOrigins
+	}
+	o.mu.Lock()
+	o.allowed, o.frameAncestors = allowed, frameAncestors
+	o.mu.Unlock()
+}
+
+func validOrigins(patterns []string) []string {
+	var valid []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if ValidateOrigin(pattern) == nil {
+			valid = append(valid, pattern)
+		}
+	}
+	return valid
+}
+
+// Allowed reports whether a cross-origin caller may use the API.
+func (o *Origins) Allowed(origin string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for _, pattern := range o.allowed {
+		if matchOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowRequest reports whether a request may proceed: it has no Origin (not
+// from a browser), comes from the page's own origin, or from an allowed one.
+// Use it as a WebSocket upgrader's CheckOrigin.
+func (o *Origins) AllowRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return o.Allowed(origin)
+}
+
+// FrameAncestors is the CSP frame-ancestors value: 'self' plus the
+// configured origins.
+func (o *Origins) FrameAncestors() string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return strings.Join(append([]string{"'self'"}, o.frameAncestors...), " ")
+}
+
+// Middleware adds CORS headers for allowed origins, answers their preflight
+// requests, and limits which pages may frame the response.
+func (o *Origins) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if origin := r.Header.Get("Origin"); origin != "" && o.Allowed(origin) {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+RequestIDHeader)
+				header.Set("Access-Control-Max-Age", "3600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		// Handlers with their own Content-Security-Policy replace this one
+		ancestors := o.FrameAncestors()
+		header.Set("Content-Security-Policy", "frame-ancestors "+ancestors)
+		if ancestors == "'self'" {
+			// For browsers without CSP; it can't express a list of origins
+			header.Set("X-Frame-Options", "SAMEORIGIN")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ValidateOrigin checks an allowed origin or frame ancestor entry.
+func ValidateOrigin(pattern string) error {
+	if pattern == "*" {
+		return nil
+	}
+	scheme, rest, ok := strings.Cut(pattern, "://")
+	if !ok || scheme == "" || rest == "" {
+		return fmt.Errorf("%q must be an origin like https://example.com", pattern)
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	name, port := splitHostPort(host)
+	if name == "" || strings.ContainsAny(strings.TrimPrefix(name, "*."), "* ;,'\"") {
+		return fmt.Errorf("%q: only a leading *. subdomain or a :* port may be a wildcard", pattern)
+	}
+	if port != "" && port != "*" {
+		if _, err := strconv.Atoi(port); err != nil {
+			return fmt.Errorf("%q has an invalid port", pattern)
+		}
+	}
+	return nil
+}
+
+// matchOrigin matches an origin against an allowed pattern. A path in the
+// pattern is ignored, since origins have none.
+func matchOrigin(pattern, origin string) bool {
+	if pattern == "*" {
+		return true
+	}
+	pScheme, pRest, ok := strings.Cut(pattern, "://")
+	oScheme, oHost, ok2 := strings.Cut(origin, "://")
+	if !ok || !ok2 || !strings.EqualFold(pScheme, oScheme) {
+		return false
+	}
+	pHost, _, _ := strings.Cut(pRest, "/")
+
+	pName, pPort := splitHostPort(pHost)
+	oName, oPort := splitHostPort(oHost)
+	if pPort != "*" && pPort != oPort {
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(pName, "*."); ok {
+		return len(oName) > len(suffix)+1 && strings.HasSuffix(strings.ToLower(oName), "."+strings.ToLower(suffix))
+	}
+	return strings.EqualFold(pName, oName)
+}
+
+// splitHostPort splits "host:port" (or "[v6]:port"); the port may be empty
+func splitHostPort(hostport string) (string, string) {
+	i := strings.LastIndexByte(hostport, ':')
+	if i < 0 || strings.HasSuffix(hostport, "]") {
+		return hostport, ""
+	}
+	return hostport[:i], hostport[i+1:]
+}
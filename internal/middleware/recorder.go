@@ -0,0 +1,71 @@
+// Package middleware provides the HTTP middleware shared by every Forge
+// route: request IDs and logging, panic recovery, rate limiting, and the
+// cross-origin and framing policy.
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Recorder wraps a ResponseWriter to capture the response status while
+// passing through the optional interfaces streaming (SSE) and WebSocket
+// handlers rely on.
+type Recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+// NewRecorder wraps w. The status is 200 until the handler writes another.
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status is the response status written so far, or 101 after a hijack.
+func (r *Recorder) Status() int { return r.status }
+
+// Written reports whether the response has started (or the connection was hijacked).
+func (r *Recorder) Written() bool { return r.wroteHeader || r.hijacked }
+
+// Hijacked reports whether the handler took over the connection, e.g. for a WebSocket.
+func (r *Recorder) Hijacked() bool { return r.hijacked }
+
+func (r *Recorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = code, true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *Recorder) Flush() {
+	r.wroteHeader = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *Recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		r.status, r.hijacked = http.StatusSwitchingProtocols, true
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// UpdateRequest is the body for POST /api/notifications
+type UpdateRequest struct {
+	Action string   `json:"action"`        // "read" or "clear"
+	IDs    []string `json:"ids,omitempty"` // Empty applies to every notification
+}
+
+// HandleNotifications lists notifications (GET, ?unread=true for unread
+// only), and marks them read or clears them (POST).
+func HandleNotifications(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := List(r.URL.Query().Get("unread") == "true")
+		if err != nil {
+			http.Error(w, "Failed to load notifications: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"notifications": list,
+			"unread":        Unread(),
+		})
+
+	case http.MethodPost:
+		var req UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		var err error
+		switch req.Action {
+		case "read":
+			err = MarkRead(req.IDs)
+		case "clear":
+			err = Clear(req.IDs)
+		default:
+			http.Error(w, "action must be \"read\" or \"clear\"", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to update notifications: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"unread":  Unread(),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleStream sends notification events as they happen (SSE). The
+// "connected" event carries the unread count; each Event is sent with its
+// Type as the event name.
+func HandleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprintf(w, "event: connected\ndata: {\"unread\":%d}\n\n", Unread())
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package notify is the notification center: things worth telling the user
+// about that happen while they aren't looking (an update, a recoverable AM
+// session, a Vision match, a long command finishing). Notifications are kept
+// across restarts and pushed to the UI as they are published, so it doesn't
+// have to poll for each kind.
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Notification kinds
+const (
+	KindUpdate     = "update"      // A newer release is available
+	KindAMRecovery = "am-recovery" // AM has sessions that can be restored
+	KindVision     = "vision"      // Vision spotted an error in a tab
+	KindCommand    = "command"     // A long-running command finished
+	KindCrash      = "crash"       // Forge recovered from a crash
+	KindPort       = "port"        // A remote tab started a server that can be forwarded
+	KindBell       = "bell"        // A program in a tab rang the bell or sent a notification
+)
+
+// maxNotifications is how many notifications are kept; the oldest are dropped
+const maxNotifications = 200
+
+// Notification is one entry in the notification center.
+type Notification struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	TabID string `json:"tabId,omitempty"` // The tab it concerns, if any
+	// Key identifies what the notification is about. Publishing with the key
+	// of an existing notification replaces it, or does nothing if the title
+	// and body are unchanged, so repeated checks don't pile up duplicates.
+	Key       string                 `json:"key,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Read      bool                   `json:"read"`
+}
+
+// Event is sent to subscribers when notifications change.
+type Event struct {
+	Type         string        `json:"type"` // "notification", "read" or "cleared"
+	Notification *Notification `json:"notification,omitempty"`
+	IDs          []string      `json:"ids,omitempty"` // Empty for "read" and "cleared" means all
+	Unread       int           `json:"unread"`
+}
+
+var (
+	mu          sync.Mutex
+	subscribers = make(map[chan Event]struct{})
+)
+
+// load reads stored notifications, oldest first. Caller must hold mu.
+func load() ([]Notification, error) {
+	data, err := storage.ReadJSONFile(storage.GetNotificationsPath())
+	if os.IsNotExist(err) {
+		return []Notification{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Notification
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// save writes notifications to disk. Caller must hold mu.
+func save(list []Notification) error {
+	path := storage.GetNotificationsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+func unreadCount(list []Notification) int {
+	count := 0
+	for _, n := range list {
+		if !n.Read {
+			count++
+		}
+	}
+	return count
+}
+
+// Publish stores a notification and sends it to subscribers. ID and
+// CreatedAt are filled in; see Notification.Key for how repeats are handled.
+func Publish(n Notification) (Notification, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return Notification{}, err
+	}
+	if n.Key != "" {
+		for i, existing := range list {
+			if existing.Key != n.Key {
+				continue
+			}
+			if existing.Kind == n.Kind && existing.Title == n.Title && existing.Body == n.Body {
+				return existing, nil
+			}
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+
+	n.ID = uuid.New().String()
+	n.CreatedAt = time.Now()
+	n.Read = false
+	list = append(list, n)
+	if len(list) > maxNotifications {
+		list = list[len(list)-maxNotifications:]
+	}
+	if err := save(list); err != nil {
+		return Notification{}, err
+	}
+	broadcast(Event{Type: "notification", Notification: &n, Unread: unreadCount(list)})
+	return n, nil
+}
+
+// List returns notifications newest first, optionally only unread ones.
+func List(unreadOnly bool) ([]Notification, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Notification, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		if unreadOnly && list[i].Read {
+			continue
+		}
+		result = append(result, list[i])
+	}
+	return result, nil
+}
+
+// Unread returns the number of unread notifications.
+func Unread() int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return 0
+	}
+	return unreadCount(list)
+}
+
+// MarkRead marks notifications as read, or all of them when ids is empty.
+func MarkRead(ids []string) error {
+	return update("read", ids, func(list []Notification, match func(Notification) bool) []Notification {
+		for i := range list {
+			if match(list[i]) {
+				list[i].Read = true
+			}
+		}
+		return list
+	})
+}
+
+// Clear removes notifications, or all of them when ids is empty.
+func Clear(ids []string) error {
+	return update("cleared", ids, func(list []Notification, match func(Notification) bool) []Notification {
+		kept := list[:0]
+		for _, n := range list {
+			if !match(n) {
+				kept = append(kept, n)
+			}
+		}
+		return kept
+	})
+}
+
+// update applies change to the notifications selected by ids, saves, and
+// tells subscribers.
+func update(event string, ids []string, change func([]Notification, func(Notification) bool) []Notification) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	list = change(list, func(n Notification) bool {
+		return len(ids) == 0 || selected[n.ID]
+	})
+	if err := save(list); err != nil {
+		return err
+	}
+	broadcast(Event{Type: event, IDs: ids, Unread: unreadCount(list)})
+	return nil
+}
+
+// Subscribe returns a channel of notification events and a function that
+// ends the subscription. Events are dropped for subscribers that fall behind.
+func Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+	mu.Lock()
+	subscribers[ch] = struct{}{}
+	mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			mu.Lock()
+			delete(subscribers, ch)
+			mu.Unlock()
+		})
+	}
+}
+
+// broadcast sends an event to every subscriber. Caller must hold mu.
+func broadcast(event Event) {
+	for ch := range subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withStore(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+}
+
+func TestPublishAndMarkRead(t *testing.T) {
+	withStore(t)
+	events, unsubscribe := Subscribe()
+	defer unsubscribe()
+
+	first, err := Publish(Notification{Kind: KindCommand, Title: "make finished"})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if first.ID == "" || first.CreatedAt.IsZero() {
+		t.Errorf("Expected an ID and time, got %+v", first)
+	}
+	select {
+	case event := <-events:
+		if event.Type != "notification" || event.Notification.ID != first.ID || event.Unread != 1 {
+			t.Errorf("Unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a notification event")
+	}
+
+	second, _ := Publish(Notification{Kind: KindCommand, Title: "tests finished"})
+	list, err := List(false)
+	if err != nil || len(list) != 2 || list[0].ID != second.ID {
+		t.Fatalf("Expected two notifications, newest first, got %+v (%v)", list, err)
+	}
+
+	if err := MarkRead([]string{first.ID}); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+	if unread, _ := List(true); len(unread) != 1 || unread[0].ID != second.ID {
+		t.Errorf("Expected only the second to be unread, got %+v", unread)
+	}
+	if err := MarkRead(nil); err != nil || Unread() != 0 {
+		t.Errorf("Expected everything read, got %d unread (%v)", Unread(), err)
+	}
+	if err := Clear([]string{first.ID}); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if list, _ := List(false); len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("Expected the first to be cleared, got %+v", list)
+	}
+}
+
+func TestPublishKeyReplaces(t *testing.T) {
+	withStore(t)
+
+	first, _ := Publish(Notification{Kind: KindUpdate, Key: "update", Title: "v1.2 available"})
+	MarkRead(nil)
+	same, _ := Publish(Notification{Kind: KindUpdate, Key: "update", Title: "v1.2 available"})
+	if same.ID != first.ID || Unread() != 0 {
+		t.Errorf("Expected an unchanged repeat to be ignored, got %+v", same)
+	}
+
+	newer, _ := Publish(Notification{Kind: KindUpdate, Key: "update", Title: "v1.3 available"})
+	list, _ := List(false)
+	if len(list) != 1 || list[0].ID != newer.ID || list[0].Read {
+		t.Errorf("Expected the newer notification to replace the old one, got %+v", list)
+	}
+}
+
+func TestPublishTrims(t *testing.T) {
+	withStore(t)
+	for i := 0; i < maxNotifications+5; i++ {
+		if _, err := Publish(Notification{Kind: KindVision, Title: "match"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if list, _ := List(false); len(list) != maxNotifications {
+		t.Errorf("Expected %d notifications kept, got %d", maxNotifications, len(list))
+	}
+}
+
+func TestHandleNotifications(t *testing.T) {
+	withStore(t)
+	n, _ := Publish(Notification{Kind: KindCrash, Title: "Forge recovered from an error"})
+
+	rec := httptest.NewRecorder()
+	HandleNotifications(rec, httptest.NewRequest("GET", "/api/v1/notifications?unread=true", nil))
+	var listing struct {
+		Notifications []Notification `json:"notifications"`
+		Unread        int            `json:"unread"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&listing); err != nil || listing.Unread != 1 || len(listing.Notifications) != 1 {
+		t.Fatalf("Expected one unread notification, got %+v (%v)", listing, err)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleNotifications(rec, httptest.NewRequest("POST", "/api/v1/notifications", strings.NewReader(`{"action":"read","ids":["`+n.ID+`"]}`)))
+	if rec.Code != http.StatusOK || Unread() != 0 {
+		t.Errorf("Expected the notification to be marked read, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	HandleNotifications(rec, httptest.NewRequest("POST", "/api/v1/notifications", strings.NewReader(`{"action":"delete"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown action, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,205 @@
+// Package ports chooses the ports Forge listens on and, when they are taken,
+// finds out (best-effort) which process holds them.
+package ports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultPreferred are the ports tried in order when none is configured
+var DefaultPreferred = []int{8333, 8080, 9000, 3000, 3333}
+
+// Holder is the process listening on a port
+type Holder struct {
+	PID     int    `json:"pid"`
+	Process string `json:"process,omitempty"` // Executable name, when it could be read
+}
+
+func (h *Holder) String() string {
+	if h.Process == "" {
+		return fmt.Sprintf("pid %d", h.PID)
+	}
+	return fmt.Sprintf("%s (pid %d)", h.Process, h.PID)
+}
+
+// Conflict is a port the server could not listen on
+type Conflict struct {
+	Port   int     `json:"port"`
+	Error  string  `json:"error"`
+	Holder *Holder `json:"holder,omitempty"` // nil if the holder couldn't be determined
+}
+
+// Preferred returns the configured ports in order, without duplicates or
+// out-of-range values, or DefaultPreferred when none are usable.
+func Preferred(configured []int) []int {
+	seen := make(map[int]bool)
+	var result []int
+	for _, port := range configured {
+		if port < 1 || port > 65535 || seen[port] {
+			continue
+		}
+		seen[port] = true
+		result = append(result, port)
+	}
+	if len(result) == 0 {
+		return append([]int(nil), DefaultPreferred...)
+	}
+	return result
+}
+
+// FindHolder returns the process listening on a TCP port, or nil if it can't
+// be determined: the platform tool is missing, or the process belongs to
+// another user.
+func FindHolder(port int) *Holder {
+	switch runtime.GOOS {
+	case "linux":
+		return findHolderProc("/proc", port)
+	case "darwin", "freebsd":
+		out, err := exec.Command("lsof", "-nP", "-iTCP:"+strconv.Itoa(port), "-sTCP:LISTEN", "-Fpc").Output()
+		if err != nil {
+			return nil
+		}
+		return parseLsof(string(out))
+	case "windows":
+		out, err := exec.Command("netstat", "-ano", "-p", "TCP").Output()
+		if err != nil {
+			return nil
+		}
+		holder := parseNetstat(string(out), port)
+		if holder != nil {
+			holder.Process = windowsProcessName(holder.PID)
+		}
+		return holder
+	}
+	return nil
+}
+
+// findHolderProc finds the listening socket's inode in /proc/net/tcp{,6} and
+// the process with a descriptor open on it
+func findHolderProc(proc string, port int) *Holder {
+	inodes := make(map[string]bool)
+	for _, name := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(proc, "net", name))
+		if err != nil {
+			continue
+		}
+		for inode := range listeningInodes(f, port) {
+			inodes[inode] = true
+		}
+		f.Close()
+	}
+	if len(inodes) == 0 {
+		return nil
+	}
+
+	dirs, err := os.ReadDir(proc)
+	if err != nil {
+		return nil
+	}
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join(proc, dir.Name(), "fd"))
+		if err != nil {
+			continue // Another user's process
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(proc, dir.Name(), "fd", fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			if inodes[strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")] {
+				comm, _ := os.ReadFile(filepath.Join(proc, dir.Name(), "comm"))
+				return &Holder{PID: pid, Process: strings.TrimSpace(string(comm))}
+			}
+		}
+	}
+	return nil
+}
+
+// listeningInodes returns the inodes of sockets listening on port in a
+// /proc/net/tcp table
+func listeningInodes(r io.Reader, port int) map[string]bool {
+	const listen = "0A"
+	inodes := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != listen {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if p, err := strconv.ParseInt(hexPort, 16, 32); err == nil && int(p) == port {
+			inodes[fields[9]] = true
+		}
+	}
+	return inodes
+}
+
+// parseLsof reads the first process from lsof -F pc output
+func parseLsof(out string) *Holder {
+	var holder *Holder
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "p"):
+			if holder != nil {
+				return holder
+			}
+			pid, err := strconv.Atoi(line[1:])
+			if err != nil {
+				return nil
+			}
+			holder = &Holder{PID: pid}
+		case strings.HasPrefix(line, "c") && holder != nil:
+			holder.Process = line[1:]
+		}
+	}
+	return holder
+}
+
+// parseNetstat finds the pid listening on port in netstat -ano output
+func parseNetstat(out string, port int) *Holder {
+	suffix := ":" + strconv.Itoa(port)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 || !strings.EqualFold(fields[0], "TCP") || fields[3] != "LISTENING" {
+			continue
+		}
+		if !strings.HasSuffix(fields[1], suffix) {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[4]); err == nil {
+			return &Holder{PID: pid}
+		}
+	}
+	return nil
+}
+
+// windowsProcessName looks up an executable name with tasklist
+func windowsProcessName(pid int) string {
+	out, err := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return ""
+	}
+	// "node.exe","1234","Console","1","45,000 K"
+	name, _, ok := strings.Cut(strings.TrimSpace(string(out)), ",")
+	if !ok {
+		return ""
+	}
+	return strings.Trim(name, `"`)
+}
@@ -0,0 +1,65 @@
+package ports
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestPreferred(t *testing.T) {
+	if got := Preferred(nil); !reflect.DeepEqual(got, DefaultPreferred) {
+		t.Errorf("Expected defaults, got %v", got)
+	}
+	if got := Preferred([]int{9100, 0, 9100, 70000, 9200}); !reflect.DeepEqual(got, []int{9100, 9200}) {
+		t.Errorf("Expected invalid and repeated ports dropped, got %v", got)
+	}
+	if got := Preferred([]int{-1}); !reflect.DeepEqual(got, DefaultPreferred) {
+		t.Errorf("Expected defaults when nothing is usable, got %v", got)
+	}
+}
+
+func TestFindHolderSelf(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads /proc")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	holder := FindHolder(listener.Addr().(*net.TCPAddr).Port)
+	if holder == nil || holder.PID != os.Getpid() || holder.Process == "" {
+		t.Errorf("Expected this process to hold the port, got %v", holder)
+	}
+}
+
+func TestParseLsof(t *testing.T) {
+	holder := parseLsof("p4321\ncnode\nf12\np999\ncother\n")
+	if holder == nil || holder.PID != 4321 || holder.Process != "node" {
+		t.Errorf("Expected node (pid 4321), got %v", holder)
+	}
+	if parseLsof("") != nil {
+		t.Error("Expected nil for empty output")
+	}
+}
+
+func TestParseNetstat(t *testing.T) {
+	out := `
+Active Connections
+
+  Proto  Local Address          Foreign Address        State           PID
+  TCP    0.0.0.0:135            0.0.0.0:0              LISTENING       1000
+  TCP    127.0.0.1:18333        0.0.0.0:0              LISTENING       2000
+  TCP    127.0.0.1:8333         127.0.0.1:50000        ESTABLISHED     3000
+  TCP    [::]:8333              [::]:0                 LISTENING       4000
+`
+	if holder := parseNetstat(out, 8333); holder == nil || holder.PID != 4000 {
+		t.Errorf("Expected pid 4000, got %v", holder)
+	}
+	if holder := parseNetstat(out, 9000); holder != nil {
+		t.Errorf("Expected no holder, got %v", holder)
+	}
+}
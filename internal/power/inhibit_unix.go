@@ -0,0 +1,62 @@
+//go:build !windows
+// +build !windows
+
+package power
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// startupGrace is how long a helper must survive to count as holding the lock;
+// systemd-inhibit exits at once when the lock is refused
+const startupGrace = 300 * time.Millisecond
+
+// platformAcquire runs a helper that holds off sleep until it is killed or
+// Forge exits
+func platformAcquire(reason string) (func(), error) {
+	pid := strconv.Itoa(os.Getpid())
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("systemd-inhibit", "--what=idle:sleep", "--who=Forge Terminal", "--why="+reason, "--mode=block",
+			"tail", "--pid="+pid, "-f", "/dev/null")
+	case "darwin":
+		cmd = exec.Command("caffeinate", "-i", "-w", pid)
+	default:
+		return nil, fmt.Errorf("preventing sleep is not supported on %s", runtime.GOOS)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// Its own process group, so releasing also ends the helper's child
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", cmd.Args[0], err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", cmd.Args[0], msg)
+		}
+		return nil, fmt.Errorf("%s exited: %v", cmd.Args[0], cmd.ProcessState)
+	case <-time.After(startupGrace):
+	}
+
+	return func() {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-exited
+	}, nil
+}
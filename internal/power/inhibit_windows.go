@@ -0,0 +1,40 @@
+//go:build windows
+// +build windows
+
+package power
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+var setThreadExecutionState = syscall.NewLazyDLL("kernel32.dll").NewProc("SetThreadExecutionState")
+
+// Execution state flags (winbase.h)
+const (
+	esContinuous     = 0x80000000
+	esSystemRequired = 0x00000001
+)
+
+// platformAcquire marks the system required from a thread kept for the
+// purpose, since the execution state belongs to the thread that set it
+func platformAcquire(reason string) (func(), error) {
+	result := make(chan error)
+	done := make(chan struct{})
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		if r, _, err := setThreadExecutionState.Call(esContinuous | esSystemRequired); r == 0 {
+			result <- fmt.Errorf("SetThreadExecutionState: %v", err)
+			return
+		}
+		result <- nil
+		<-done
+		setThreadExecutionState.Call(esContinuous)
+	}()
+	if err := <-result; err != nil {
+		return nil, err
+	}
+	return func() { close(done) }, nil
+}
@@ -0,0 +1,144 @@
+// Package power keeps the computer from sleeping while Forge is busy: while a
+// tab is printing output or an AI conversation is running. It is off unless
+// enabled (preventSleep in config).
+//
+// Linux holds a systemd-inhibit lock, macOS runs caffeinate, and Windows sets
+// the thread execution state. The Unix helpers watch Forge's PID, so a crash
+// can't leave the computer awake.
+package power
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/crash"
+)
+
+// IdleAfter is how long after the last terminal output Forge counts as idle
+const IdleAfter = 5 * time.Minute
+
+// pollInterval is how often activity is checked
+const pollInterval = 30 * time.Second
+
+// Status describes the sleep inhibitor.
+type Status struct {
+	Enabled    bool      `json:"enabled"`
+	Inhibiting bool      `json:"inhibiting"`
+	Reason     string    `json:"reason,omitempty"` // Why sleep is held off
+	Since      time.Time `json:"since,omitempty"`
+	Error      string    `json:"error,omitempty"` // Why the inhibitor couldn't be taken
+}
+
+// acquire takes the platform's sleep inhibitor, returning a function that
+// releases it; a variable for tests
+var acquire = platformAcquire
+
+var (
+	mu       sync.Mutex
+	status   Status
+	activity func() string
+	release  func()
+	stop     chan struct{}
+)
+
+// SetEnabled turns the sleep inhibitor on or off.
+func SetEnabled(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	status.Enabled = enabled
+	update()
+}
+
+// Watch checks busy every pollInterval until Stop, holding off sleep while it
+// returns a reason (e.g. "an AI conversation is running") and allowing it when
+// it returns "".
+func Watch(busy func() string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		return
+	}
+	activity = busy
+	stop = make(chan struct{})
+	done := stop
+	crash.Go(func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				update()
+				mu.Unlock()
+			}
+		}
+	})
+	update()
+}
+
+// Stop stops watching and lets the computer sleep again.
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+	activity = nil
+	update()
+}
+
+// Current returns the inhibitor's status.
+func Current() Status {
+	mu.Lock()
+	defer mu.Unlock()
+	return status
+}
+
+// update takes or releases the inhibitor to match the current activity. The
+// caller holds mu.
+func update() {
+	reason := ""
+	if status.Enabled && activity != nil {
+		reason = activity()
+	}
+
+	switch {
+	case reason != "" && release == nil:
+		r, err := acquire(reason)
+		if err != nil {
+			if status.Error != err.Error() {
+				log.Printf("[Power] Can't prevent sleep: %v", err)
+			}
+			status.Error = err.Error()
+			return
+		}
+		release = r
+		status.Inhibiting, status.Reason, status.Since, status.Error = true, reason, time.Now(), ""
+		log.Printf("[Power] Preventing sleep: %s", reason)
+	case reason != "":
+		status.Reason = reason
+	case release != nil:
+		release()
+		release = nil
+		status.Inhibiting, status.Reason, status.Since = false, "", time.Time{}
+		log.Printf("[Power] Allowing sleep")
+	default:
+		status.Error = ""
+	}
+}
+
+// HandleStatus reports whether Forge is keeping the computer awake (GET).
+func HandleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Current())
+}
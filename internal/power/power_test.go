@@ -0,0 +1,70 @@
+package power
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInhibitsWhileBusy(t *testing.T) {
+	original := acquire
+	defer func() {
+		acquire = original
+		Stop()
+		SetEnabled(false)
+	}()
+
+	held := 0
+	acquire = func(string) (func(), error) {
+		held++
+		return func() { held-- }, nil
+	}
+	reason := "a terminal is printing output"
+	Watch(func() string { return reason })
+
+	if Current().Inhibiting || held != 0 {
+		t.Fatal("Expected no inhibitor while disabled")
+	}
+	SetEnabled(true)
+	if status := Current(); !status.Inhibiting || status.Reason != reason || held != 1 {
+		t.Fatalf("Expected the inhibitor held, got %+v (held %d)", status, held)
+	}
+
+	reason = "an AI conversation is running"
+	mu.Lock()
+	update()
+	mu.Unlock()
+	if status := Current(); status.Reason != reason || held != 1 {
+		t.Errorf("Expected the reason updated without a second inhibitor, got %+v (held %d)", status, held)
+	}
+
+	reason = ""
+	mu.Lock()
+	update()
+	mu.Unlock()
+	if Current().Inhibiting || held != 0 {
+		t.Error("Expected the inhibitor released when idle")
+	}
+
+	reason = "busy"
+	SetEnabled(true)
+	Stop()
+	if Current().Inhibiting || held != 0 {
+		t.Error("Expected Stop to release the inhibitor")
+	}
+}
+
+func TestAcquireFailure(t *testing.T) {
+	original := acquire
+	defer func() {
+		acquire = original
+		Stop()
+		SetEnabled(false)
+	}()
+
+	acquire = func(string) (func(), error) { return nil, errors.New("systemd-inhibit not found") }
+	SetEnabled(true)
+	Watch(func() string { return "busy" })
+	if status := Current(); status.Inhibiting || status.Error == "" {
+		t.Errorf("Expected the failure reported, got %+v", status)
+	}
+}
@@ -0,0 +1,254 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/api"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Request selectors. The query parameters are remembered in cookies so the
+// page's own API and WebSocket requests reach the same profile.
+const (
+	ProfileParam  = "profile"
+	TokenParam    = "token"
+	ProfileHeader = "X-Forge-Profile"
+	ProfileCookie = "forge_profile"
+	TokenCookie   = "forge_profile_token"
+)
+
+// managementPath is served by the host server for the default profile only.
+// The unversioned /api/profiles is matched too.
+const managementPath = api.Prefix + "/profiles"
+
+var (
+	errBadToken      = errors.New("invalid profile token")
+	errTokenRequired = errors.New("profile requires an access token")
+	errUnknown       = errors.New("unknown profile")
+)
+
+// Resolve returns the profile a request is for, or "" for the default tree.
+func Resolve(r *http.Request) (string, error) {
+	// A bearer token selects a profile only if it is one; other bearer
+	// credentials are left for the handlers
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		profile, err := FindByToken(strings.TrimPrefix(auth, "Bearer "))
+		if err != nil {
+			return "", err
+		}
+		if profile != nil {
+			return profile.Name, nil
+		}
+	}
+
+	token := r.URL.Query().Get(TokenParam)
+	if token == "" {
+		if c, err := r.Cookie(TokenCookie); err == nil {
+			token = c.Value
+		}
+	}
+	if token != "" {
+		profile, err := FindByToken(token)
+		if err != nil {
+			return "", err
+		}
+		if profile == nil {
+			return "", errBadToken
+		}
+		return profile.Name, nil
+	}
+
+	name := r.URL.Query().Get(ProfileParam)
+	if name == "" {
+		name = r.Header.Get(ProfileHeader)
+	}
+	if name == "" {
+		if c, err := r.Cookie(ProfileCookie); err == nil {
+			name = c.Value
+		}
+	}
+	if name == "" || name == storage.DefaultProfile {
+		return "", nil
+	}
+
+	profile, err := Get(name)
+	if err != nil {
+		return "", err
+	}
+	if profile == nil {
+		return "", errUnknown
+	}
+	if profile.Protected {
+		return "", errTokenRequired
+	}
+	return profile.Name, nil
+}
+
+// Middleware routes requests for a profile to that profile's server and
+// serves everything else with next. Profile processes pass requests straight through.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if storage.CurrentProfile() != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name, err := Resolve(r)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, errUnknown) {
+				status = http.StatusNotFound
+			} else if !errors.Is(err, errBadToken) && !errors.Is(err, errTokenRequired) {
+				status = http.StatusInternalServerError
+			}
+			clearCookies(w)
+			http.Error(w, "Profile: "+err.Error(), status)
+			return
+		}
+		rememberSelection(w, r)
+
+		if path, _ := api.Versioned(r.URL.Path); path == managementPath {
+			if name != "" {
+				http.Error(w, "Profiles can only be managed from the default profile", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		proxy, err := m.Proxy(name)
+		if err != nil {
+			http.Error(w, "Profile failed to start: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+// rememberSelection stores a profile chosen by query parameter in cookies.
+func rememberSelection(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case query.Get(TokenParam) != "":
+		clearCookies(w)
+		setCookie(w, TokenCookie, query.Get(TokenParam))
+	case query.Get(ProfileParam) == storage.DefaultProfile:
+		clearCookies(w)
+	case query.Get(ProfileParam) != "":
+		clearCookies(w)
+		setCookie(w, ProfileCookie, query.Get(ProfileParam))
+	}
+}
+
+func setCookie(w http.ResponseWriter, name, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearCookies(w http.ResponseWriter) {
+	for _, name := range []string{ProfileCookie, TokenCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+}
+
+// profileView adds fields computed at request time
+type profileView struct {
+	Name      string    `json:"name"`
+	Protected bool      `json:"protected"`
+	CreatedAt time.Time `json:"createdAt"`
+	Running   bool      `json:"running"`
+	DataDir   string    `json:"dataDir"`
+}
+
+// HandleProfiles lists (GET), creates (POST), or removes (DELETE ?name=) profiles
+func (m *Manager) HandleProfiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if storage.CurrentProfile() != "" {
+		http.Error(w, "Profiles can only be managed from the default profile", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := List()
+		if err != nil {
+			http.Error(w, "Failed to load profiles: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		running := make(map[string]bool)
+		for _, name := range m.Running() {
+			running[name] = true
+		}
+		views := make([]profileView, 0, len(list))
+		for _, p := range list {
+			views = append(views, profileView{
+				Name:      p.Name,
+				Protected: p.Protected,
+				CreatedAt: p.CreatedAt,
+				Running:   running[p.Name],
+				DataDir:   storage.GetProfileDir(p.Name),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"profiles": views,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Name      string `json:"name"`
+			Protected bool   `json:"protected"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		profile, token, err := Create(strings.TrimSpace(req.Name), req.Protected)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("[Profiles] Created profile %s (protected: %v)", profile.Name, profile.Protected)
+
+		url := "/?" + TokenParam + "=" + token
+		if !profile.Protected {
+			url = "/?" + ProfileParam + "=" + profile.Name
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"name":    profile.Name,
+			"token":   token,
+			"url":     url,
+		})
+
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if err := Remove(name); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		m.Stop(name)
+		log.Printf("[Profiles] Removed profile %s", name)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Profile removed; its data remains in " + storage.GetProfileDir(name),
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
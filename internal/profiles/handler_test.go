@@ -0,0 +1,129 @@
+package profiles
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// fakeManager runs each profile as an in-process server that echoes its name.
+func fakeManager(t *testing.T) *Manager {
+	t.Helper()
+	m := NewManager()
+	m.launch = func(name string) (*instance, error) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "profile:%s", name)
+		}))
+		t.Cleanup(srv.Close)
+		target, _ := url.Parse(srv.URL)
+		done := make(chan struct{})
+		return &instance{URL: target, Stop: func() { srv.Close() }, Done: done}, nil
+	}
+	return m
+}
+
+func serve(m *Manager, req *http.Request) *httptest.ResponseRecorder {
+	host := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "host")
+	})
+	rec := httptest.NewRecorder()
+	m.Middleware(host).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareRouting(t *testing.T) {
+	useTempRegistry(t)
+	t.Setenv(storage.ProfileEnv, "")
+	m := fakeManager(t)
+
+	Create("alice", false)
+	_, bobToken, _ := Create("bob", true)
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+		body   string
+	}{
+		{"no selector", httptest.NewRequest("GET", "/api/commands", nil), 200, "host"},
+		{"query", httptest.NewRequest("GET", "/?profile=alice", nil), 200, "profile:alice"},
+		{"default", httptest.NewRequest("GET", "/?profile=default", nil), 200, "host"},
+		{"unknown", httptest.NewRequest("GET", "/?profile=carol", nil), 404, ""},
+		{"protected by name", httptest.NewRequest("GET", "/?profile=bob", nil), 401, ""},
+		{"token", httptest.NewRequest("GET", "/?token="+bobToken, nil), 200, "profile:bob"},
+		{"bad token", httptest.NewRequest("GET", "/?token=nope", nil), 401, ""},
+	}
+	for _, tt := range tests {
+		rec := serve(m, tt.req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d (%s)", tt.name, tt.status, rec.Code, rec.Body.String())
+			continue
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.name, tt.body, rec.Body.String())
+		}
+	}
+
+	// Header and cookie selection
+	req := httptest.NewRequest("GET", "/api/commands", nil)
+	req.Header.Set("Authorization", "Bearer "+bobToken)
+	if rec := serve(m, req); rec.Body.String() != "profile:bob" {
+		t.Errorf("Expected bearer token to select bob, got %q", rec.Body.String())
+	}
+	req = httptest.NewRequest("GET", "/api/commands", nil)
+	req.AddCookie(&http.Cookie{Name: ProfileCookie, Value: "alice"})
+	if rec := serve(m, req); rec.Body.String() != "profile:alice" {
+		t.Errorf("Expected cookie to select alice, got %q", rec.Body.String())
+	}
+
+	// Other bearer credentials are left for the handlers
+	req = httptest.NewRequest("GET", "/api/commands", nil)
+	req.Header.Set("Authorization", "Bearer something-else")
+	if rec := serve(m, req); rec.Body.String() != "host" {
+		t.Errorf("Expected unrelated bearer token to reach the host, got %q", rec.Body.String())
+	}
+
+	if running := m.Running(); len(running) != 2 {
+		t.Errorf("Expected alice and bob running, got %v", running)
+	}
+}
+
+func TestMiddlewareRemembersSelection(t *testing.T) {
+	useTempRegistry(t)
+	t.Setenv(storage.ProfileEnv, "")
+	m := fakeManager(t)
+	Create("alice", false)
+
+	rec := serve(m, httptest.NewRequest("GET", "/?profile=alice", nil))
+	var found bool
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == ProfileCookie && c.Value == "alice" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected %s cookie to be set, got %v", ProfileCookie, rec.Result().Cookies())
+	}
+}
+
+func TestManagementOnlyFromDefault(t *testing.T) {
+	useTempRegistry(t)
+	t.Setenv(storage.ProfileEnv, "")
+	m := fakeManager(t)
+	Create("alice", false)
+
+	if rec := serve(m, httptest.NewRequest("GET", "/api/profiles", nil)); rec.Body.String() != "host" {
+		t.Errorf("Expected the host to serve profile management, got %q", rec.Body.String())
+	}
+	if rec := serve(m, httptest.NewRequest("GET", "/api/profiles?profile=alice", nil)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 managing profiles from alice, got %d", rec.Code)
+	}
+	if rec := serve(m, httptest.NewRequest("GET", "/api/v1/profiles?profile=alice", nil)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 managing profiles from alice via /api/v1, got %d", rec.Code)
+	}
+}
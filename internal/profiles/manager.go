@@ -0,0 +1,244 @@
+package profiles
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/api"
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// ListenEnv names the environment variable giving a profile process its listen address
+const ListenEnv = "FORGE_LISTEN"
+
+const (
+	// startTimeout bounds how long a profile process has to start answering requests
+	startTimeout = 20 * time.Second
+	// stopTimeout bounds how long a profile process has to shut down cleanly
+	stopTimeout = 15 * time.Second
+)
+
+// instance is a running profile server.
+type instance struct {
+	URL  *url.URL
+	Stop func()
+	Done <-chan struct{} // Closed when the server exits
+}
+
+// backend is a profile server that is starting or running.
+type backend struct {
+	ready chan struct{} // Closed once the start attempt finished
+	err   error
+	inst  *instance
+	proxy *httputil.ReverseProxy
+}
+
+// Manager starts a Forge process per profile on first use and proxies to it.
+type Manager struct {
+	mu       sync.Mutex
+	backends map[string]*backend
+
+	// launch starts a profile server; replaced in tests
+	launch func(name string) (*instance, error)
+}
+
+// NewManager creates a manager that runs profiles as child processes of this executable.
+func NewManager() *Manager {
+	return &Manager{
+		backends: make(map[string]*backend),
+		launch:   launchProcess,
+	}
+}
+
+// Proxy returns a reverse proxy to the profile's server, starting it if needed.
+func (m *Manager) Proxy(name string) (*httputil.ReverseProxy, error) {
+	m.mu.Lock()
+	b, ok := m.backends[name]
+	if !ok {
+		b = &backend{ready: make(chan struct{})}
+		m.backends[name] = b
+		go m.start(name, b)
+	}
+	m.mu.Unlock()
+
+	<-b.ready
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.proxy, nil
+}
+
+// start launches a profile server and forgets it again once it exits, so the
+// next request restarts it.
+func (m *Manager) start(name string, b *backend) {
+	inst, err := m.launch(name)
+	if err != nil {
+		log.Printf("[Profiles] Failed to start profile %s: %v", name, err)
+		b.err = err
+		m.forget(name, b)
+		close(b.ready)
+		return
+	}
+	log.Printf("[Profiles] Profile %s running at %s", name, inst.URL.Host)
+	b.inst = inst
+	b.proxy = httputil.NewSingleHostReverseProxy(inst.URL)
+	close(b.ready)
+
+	<-inst.Done
+	log.Printf("[Profiles] Profile %s stopped", name)
+	m.forget(name, b)
+}
+
+func (m *Manager) forget(name string, b *backend) {
+	m.mu.Lock()
+	if m.backends[name] == b {
+		delete(m.backends, name)
+	}
+	m.mu.Unlock()
+}
+
+// Running returns the names of profiles whose servers are running.
+func (m *Manager) Running() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.backends))
+	for name, b := range m.backends {
+		select {
+		case <-b.ready:
+			if b.err == nil {
+				names = append(names, name)
+			}
+		default:
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stop shuts down a profile's server if it is running.
+func (m *Manager) Stop(name string) {
+	m.mu.Lock()
+	b, ok := m.backends[name]
+	delete(m.backends, name)
+	m.mu.Unlock()
+
+	if ok {
+		<-b.ready
+		if b.inst != nil {
+			b.inst.Stop()
+		}
+	}
+}
+
+// StopAll shuts down every profile server.
+func (m *Manager) StopAll() {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.backends))
+	for name := range m.backends {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+
+	for _, name := range names {
+		m.Stop(name)
+	}
+}
+
+// launchProcess runs this executable as the named profile on a free loopback
+// port and waits for it to answer.
+func launchProcess(name string) (*instance, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(storage.GetProfileDir(name), 0700); err != nil {
+		return nil, err
+	}
+	addr, err := freeAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(),
+		storage.ProfileEnv+"="+name,
+		ListenEnv+"="+addr,
+		"NO_BROWSER=1",
+	)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	inst := &instance{
+		URL:  &url.URL{Scheme: "http", Host: addr},
+		Stop: func() { stopProcess(cmd.Process, done) },
+		Done: done,
+	}
+
+	if err := waitReady(inst, startTimeout); err != nil {
+		inst.Stop()
+		return nil, err
+	}
+	return inst, nil
+}
+
+// stopProcess asks a profile server to shut down cleanly, killing it if it
+// hasn't exited within stopTimeout (or can't be signalled, as on Windows).
+func stopProcess(process *os.Process, done <-chan struct{}) {
+	if err := process.Signal(os.Interrupt); err == nil {
+		select {
+		case <-done:
+			return
+		case <-time.After(stopTimeout):
+		}
+	}
+	process.Kill()
+	<-done
+}
+
+// waitReady polls the server's version endpoint until it answers.
+func waitReady(inst *instance, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-inst.Done:
+			return fmt.Errorf("profile server exited during startup")
+		default:
+		}
+		resp, err := client.Get(inst.URL.String() + api.Prefix + "/version")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("profile server did not start within %s", timeout)
+}
+
+// freeAddr picks an unused loopback port.
+func freeAddr() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer listener.Close()
+	return listener.Addr().String(), nil
+}
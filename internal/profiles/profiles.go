@@ -0,0 +1,174 @@
+// Package profiles lets one Forge server host several users on a shared machine.
+// Each profile runs as its own Forge process with a separate ~/.forge/profiles/<name>
+// tree (commands, sessions, AM), and requests are routed to it by query parameter,
+// header, cookie, or access token.
+package profiles
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Profile is a registered profile. Protected profiles can only be selected
+// with their access token; others can be selected by name alone.
+type Profile struct {
+	Name      string    `json:"name"`
+	Protected bool      `json:"protected"`
+	TokenHash string    `json:"tokenHash,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var mu sync.Mutex
+
+// load reads registered profiles. Caller must hold mu.
+func load() ([]Profile, error) {
+	data, err := storage.ReadJSONFile(storage.GetProfilesPath())
+	if os.IsNotExist(err) {
+		return []Profile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Profile
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// save writes the registry to disk. Caller must hold mu.
+func save(list []Profile) error {
+	path := storage.GetProfilesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// List returns registered profiles sorted by name.
+func List() ([]Profile, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Get returns the named profile, or nil if it isn't registered.
+func Get(name string) (*Profile, error) {
+	list, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].Name == name {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// FindByToken returns the profile an access token belongs to, or nil if none does.
+func FindByToken(token string) (*Profile, error) {
+	list, err := List()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashToken(token)
+	for i := range list {
+		if list[i].TokenHash != "" && subtle.ConstantTimeCompare([]byte(list[i].TokenHash), []byte(hash)) == 1 {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Create registers a profile and returns its access token. Only a hash of the
+// token is stored, so this is the one time it can be shown.
+func Create(name string, protected bool) (*Profile, string, error) {
+	if err := storage.ValidateProfileName(name); err != nil {
+		return nil, "", err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, "", err
+	}
+	for _, p := range list {
+		if p.Name == name {
+			return nil, "", fmt.Errorf("profile %q already exists", name)
+		}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, "", err
+	}
+	profile := Profile{
+		Name:      name,
+		Protected: protected,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+	}
+	if err := save(append(list, profile)); err != nil {
+		return nil, "", err
+	}
+	return &profile, token, nil
+}
+
+// Remove unregisters a profile. Its data directory is left on disk.
+func Remove(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return err
+	}
+	kept := make([]Profile, 0, len(list))
+	for _, p := range list {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(list) {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	return save(kept)
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package profiles
+
+import (
+	"os"
+	"testing"
+)
+
+func useTempRegistry(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+}
+
+func TestCreateAndRemove(t *testing.T) {
+	useTempRegistry(t)
+
+	profile, token, err := Create("alice", true)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if token == "" || profile.TokenHash == token {
+		t.Errorf("Expected a token stored only as a hash, got token %q hash %q", token, profile.TokenHash)
+	}
+	if _, _, err := Create("alice", false); err == nil {
+		t.Error("Expected error for duplicate profile")
+	}
+	if _, _, err := Create("../etc", false); err == nil {
+		t.Error("Expected error for invalid name")
+	}
+
+	found, _ := FindByToken(token)
+	if found == nil || found.Name != "alice" {
+		t.Errorf("Expected token to find alice, got %+v", found)
+	}
+	if found, _ := FindByToken("wrong"); found != nil {
+		t.Errorf("Expected no profile for a wrong token, got %+v", found)
+	}
+
+	if err := Remove("alice"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if p, _ := Get("alice"); p != nil {
+		t.Error("Expected alice to be removed")
+	}
+	if err := Remove("alice"); err == nil {
+		t.Error("Expected error removing a missing profile")
+	}
+}
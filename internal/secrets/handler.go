@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// SetRequest is the body for POST /api/v1/secrets
+type SetRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HandleSecrets lists secret names (GET), stores a value (POST), or deletes
+// one (DELETE ?name=). Values are never returned.
+func HandleSecrets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		infos, err := List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"backend": Backend(),
+			"secrets": infos,
+		})
+
+	case http.MethodPost:
+		var req SetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validName(req.Name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := Set(req.Name, req.Value); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[Secrets] Stored %q in the %s", req.Name, Backend())
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"name":    req.Name,
+		})
+
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if err := validName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !known(name) {
+			http.Error(w, "Secret not found", http.StatusNotFound)
+			return
+		}
+		if err := Delete(name); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[Secrets] Deleted %q", name)
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// known reports whether name is in the index
+func known(name string) bool {
+	infos, err := List()
+	if err != nil {
+		return false
+	}
+	for _, info := range infos {
+		if info.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+//go:build !windows
+// +build !windows
+
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func platformKeychain() keychain {
+	switch runtime.GOOS {
+	case "darwin":
+		return macKeychain{}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return secretService{}
+	}
+	return unsupported{reason: "no keychain is supported on " + runtime.GOOS}
+}
+
+// macKeychain stores generic passwords with the security tool. Commands go
+// through its interactive mode on stdin so values never appear in process
+// arguments, and values are base64-encoded so security prints them back
+// as-is.
+type macKeychain struct{}
+
+func (macKeychain) name() string { return "macOS Keychain" }
+
+// macEncoding marks values encoded by macKeychain
+const macEncoding = "base64:"
+
+// macNotFound is the exit status of security when no item matches
+const macNotFound = 44
+
+func (macKeychain) set(name, value string) error {
+	encoded := hex.EncodeToString([]byte(macEncoding + base64.StdEncoding.EncodeToString([]byte(value))))
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", Service, quoteSecurity(name), encoded)
+	_, err := run(command, "security", "-i")
+	return err
+}
+
+func (macKeychain) get(name string) (string, error) {
+	out, err := run("", "security", "find-generic-password", "-s", Service, "-a", name, "-w")
+	if exitCode(err) == macNotFound {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	out = strings.TrimSuffix(out, "\n")
+	encoded, ok := strings.CutPrefix(out, macEncoding)
+	if !ok {
+		return out, nil // Stored by another tool
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("corrupt keychain value: %w", err)
+	}
+	return string(value), nil
+}
+
+func (macKeychain) remove(name string) error {
+	_, err := run("", "security", "delete-generic-password", "-s", Service, "-a", name)
+	if exitCode(err) == macNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// quoteSecurity quotes an argument for security's interactive mode
+func quoteSecurity(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// secretService stores items in the Secret Service with libsecret's
+// secret-tool, which reads values from stdin.
+type secretService struct{}
+
+func (secretService) name() string { return "Secret Service (libsecret)" }
+
+func (secretService) set(name, value string) error {
+	_, err := run(value, "secret-tool", "store", "--label=Forge Terminal: "+name, "service", Service, "account", name)
+	return err
+}
+
+func (secretService) get(name string) (string, error) {
+	out, err := run("", "secret-tool", "lookup", "service", Service, "account", name)
+	if err != nil {
+		// lookup exits 1 with no output when nothing matches
+		if exitCode(err) == 1 && out == "" {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+func (secretService) remove(name string) error {
+	_, err := run("", "secret-tool", "clear", "service", Service, "account", name)
+	return err
+}
+
+// exitCode returns the exit status of a failed command, or -1
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
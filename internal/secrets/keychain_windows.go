@@ -0,0 +1,113 @@
+//go:build windows
+// +build windows
+
+package secrets
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32        = syscall.NewLazyDLL("advapi32.dll")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// Credential Manager constants (wincred.h, winerror.h)
+const (
+	credTypeGeneric      = 1
+	credPersistLocalUser = 2 // CRED_PERSIST_LOCAL_MACHINE: this user, this computer
+	errorNotFound        = syscall.Errno(1168)
+)
+
+// credential mirrors CREDENTIALW
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func platformKeychain() keychain {
+	return credentialManager{}
+}
+
+// credentialManager stores generic credentials in Windows Credential Manager,
+// targeted "forge-terminal:<name>".
+type credentialManager struct{}
+
+func (credentialManager) name() string { return "Windows Credential Manager" }
+
+func target(name string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(Service + ":" + name)
+}
+
+func (credentialManager) set(name, value string) error {
+	targetName, err := target(name)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	blob := []byte(value)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         targetName,
+		UserName:           userName,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalUser,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (credentialManager) get(name string) (string, error) {
+	targetName, err := target(name)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); r == 0 {
+		if err == errorNotFound {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (credentialManager) remove(name string) error {
+	targetName, err := target(name)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0); r == 0 {
+		if err == errorNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
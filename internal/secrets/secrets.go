@@ -0,0 +1,216 @@
+// Package secrets keeps sensitive values in the OS keychain rather than in
+// Forge's config files: the macOS Keychain, the Secret Service (GNOME
+// Keyring, KWallet) through libsecret's secret-tool on Linux, and Windows
+// Credential Manager. Values are stored under the service Service.
+//
+// Keychains can't list entries portably, so the names Forge has stored are
+// kept in an index beside its other settings; values never leave the
+// keychain except through Get. Secrets the index doesn't know are treated as
+// missing, which also spares a keychain lookup for optional secrets.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Service names Forge's entries in the keychain
+const Service = "forge-terminal"
+
+// ErrNotFound is returned when the keychain has no value for a name
+var ErrNotFound = errors.New("secret not found")
+
+// Info describes a stored secret, without its value.
+type Info struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// keychain stores values by name in the OS keychain.
+type keychain interface {
+	name() string
+	set(name, value string) error
+	get(name string) (string, error)
+	remove(name string) error
+}
+
+// store is the platform keychain; replaced in tests
+var store keychain = platformKeychain()
+
+var (
+	mu    sync.Mutex
+	index map[string]Info   // Loaded on first use
+	cache map[string]string // Values read or written this run
+)
+
+// Backend names the keychain secrets are stored in.
+func Backend() string {
+	return store.name()
+}
+
+// List returns the stored secrets, sorted by name.
+func List() ([]Info, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadIndexLocked(); err != nil {
+		return nil, err
+	}
+	infos := make([]Info, 0, len(index))
+	for _, info := range index {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Set stores value under name, replacing any previous value.
+func Set(name, value string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadIndexLocked(); err != nil {
+		return err
+	}
+	if err := store.set(name, value); err != nil {
+		return fmt.Errorf("failed to store secret %q: %w", name, err)
+	}
+	cache[name] = value
+	index[name] = Info{Name: name, UpdatedAt: time.Now()}
+	return saveIndexLocked()
+}
+
+// Get returns the value stored under name, or ErrNotFound.
+func Get(name string) (string, error) {
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadIndexLocked(); err != nil {
+		return "", err
+	}
+	if value, ok := cache[name]; ok {
+		return value, nil
+	}
+	if _, ok := index[name]; !ok {
+		return "", ErrNotFound
+	}
+	value, err := store.get(name)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	cache[name] = value
+	return value, nil
+}
+
+// Delete removes the value stored under name. Deleting a missing name is not
+// an error.
+func Delete(name string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadIndexLocked(); err != nil {
+		return err
+	}
+	if err := store.remove(name); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete secret %q: %w", name, err)
+	}
+	delete(cache, name)
+	if _, ok := index[name]; !ok {
+		return nil
+	}
+	delete(index, name)
+	return saveIndexLocked()
+}
+
+func loadIndexLocked() error {
+	if index != nil {
+		return nil
+	}
+	loaded := map[string]Info{}
+	data, err := storage.ReadJSONFile(storage.GetSecretsIndexPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read secrets index: %w", err)
+	}
+	if err == nil {
+		var infos []Info
+		if err := json.Unmarshal(data, &infos); err != nil {
+			return fmt.Errorf("failed to parse secrets index: %w", err)
+		}
+		for _, info := range infos {
+			loaded[info.Name] = info
+		}
+	}
+	index, cache = loaded, map[string]string{}
+	return nil
+}
+
+func saveIndexLocked() error {
+	infos := make([]Info, 0, len(index))
+	for _, info := range index {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	path := storage.GetSecretsIndexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+func validName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("secret name is required")
+	}
+	if strings.ContainsAny(name, "\r\n\x00") {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	return nil
+}
+
+// run executes a keychain tool with input on stdin, returning stdout; a
+// variable for tests
+var run = func(input, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return string(out), fmt.Errorf("%s: %s (%w)", name, msg, err)
+		}
+		return string(out), fmt.Errorf("%s: %w", name, err)
+	}
+	return string(out), nil
+}
+
+// unsupported is the keychain on platforms without one
+type unsupported struct{ reason string }
+
+func (u unsupported) name() string               { return "none" }
+func (u unsupported) set(string, string) error   { return errors.New(u.reason) }
+func (u unsupported) get(string) (string, error) { return "", errors.New(u.reason) }
+func (u unsupported) remove(string) error        { return errors.New(u.reason) }
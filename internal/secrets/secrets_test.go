@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+type memoryKeychain struct {
+	values map[string]string
+	reads  int
+}
+
+func (m *memoryKeychain) name() string { return "memory" }
+
+func (m *memoryKeychain) set(name, value string) error {
+	m.values[name] = value
+	return nil
+}
+
+func (m *memoryKeychain) get(name string) (string, error) {
+	m.reads++
+	value, ok := m.values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (m *memoryKeychain) remove(name string) error {
+	if _, ok := m.values[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.values, name)
+	return nil
+}
+
+// useMemoryKeychain replaces the keychain and index for a test
+func useMemoryKeychain(t *testing.T) (*memoryKeychain, string) {
+	t.Helper()
+	keychain := &memoryKeychain{values: map[string]string{}}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	origStore := store
+	store = keychain
+	index, cache = nil, nil
+	t.Cleanup(func() {
+		store = origStore
+		index, cache = nil, nil
+	})
+	return keychain, storage.GetSecretsIndexPath()
+}
+
+func TestSetGetDelete(t *testing.T) {
+	keychain, path := useMemoryKeychain(t)
+
+	if err := Set("db/password", "hunter22"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "db/password") || strings.Contains(string(data), "hunter22") {
+		t.Errorf("Expected only the name in the index, got %s", data)
+	}
+
+	// A fresh start reads the value from the keychain once
+	index, cache = nil, nil
+	for i := 0; i < 2; i++ {
+		if value, err := Get("db/password"); err != nil || value != "hunter22" {
+			t.Errorf("Get = %q, %v", value, err)
+		}
+	}
+	if keychain.reads != 1 {
+		t.Errorf("Expected one keychain read, got %d", keychain.reads)
+	}
+
+	if infos, _ := List(); len(infos) != 1 || infos[0].Name != "db/password" || infos[0].UpdatedAt.IsZero() {
+		t.Errorf("Unexpected list %+v", infos)
+	}
+	if err := Delete("db/password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get("db/password"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := Delete("db/password"); err != nil {
+		t.Errorf("Expected deleting a missing secret to succeed, got %v", err)
+	}
+	if infos, _ := List(); len(infos) != 0 {
+		t.Errorf("Expected an empty list, got %+v", infos)
+	}
+}
+
+func TestGetUnindexed(t *testing.T) {
+	keychain, _ := useMemoryKeychain(t)
+	keychain.values["other"] = "x"
+	if _, err := Get("other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected names outside the index to be missing, got %v", err)
+	}
+	if keychain.reads != 0 {
+		t.Errorf("Expected no keychain read, got %d", keychain.reads)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	useMemoryKeychain(t)
+	if err := Set(" ", "x"); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+	if err := Set("a\nb", "x"); err == nil {
+		t.Error("Expected an error for a name with a newline")
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// launchdLabel identifies the launch agent
+const launchdLabel = "com." + Name
+
+// launchd runs Forge as a per-user launch agent.
+type launchd struct{}
+
+func (launchd) name() string { return "launchd" }
+
+func (launchd) path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+func (launchd) install(spec *Spec, path string) error {
+	// Replace a loaded agent rather than failing on it
+	if _, err := os.Stat(path); err == nil {
+		run("launchctl", "unload", path)
+	}
+	if err := os.WriteFile(path, []byte(launchdPlist(spec)), 0644); err != nil {
+		return err
+	}
+	_, err := run("launchctl", "load", "-w", path)
+	return err
+}
+
+func (launchd) uninstall(path string) error {
+	run("launchctl", "unload", "-w", path)
+	return os.Remove(path)
+}
+
+var launchdPID = regexp.MustCompile(`"PID" = (\d+);`)
+
+func (launchd) running() (bool, string) {
+	out, err := run("launchctl", "list", launchdLabel)
+	if err != nil {
+		return false, "not loaded"
+	}
+	if m := launchdPID.FindStringSubmatch(out); m != nil {
+		return true, "pid " + m[1]
+	}
+	return false, "loaded, not running"
+}
+
+// launchdPlist renders the launch agent for a spec. KeepAlive restarts Forge
+// when it exits with an error but not after a clean shutdown.
+func launchdPlist(spec *Spec) string {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	plistKey(&b, "Label", launchdLabel)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{spec.Executable}, spec.Args...) {
+		b.WriteString("\t\t<string>")
+		xml.EscapeText(&b, []byte(arg))
+		b.WriteString("</string>\n")
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	plistKey(&b, "StandardOutPath", spec.LogPath)
+	plistKey(&b, "StandardErrorPath", spec.LogPath)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func plistKey(b *bytes.Buffer, key, value string) {
+	b.WriteString("\t<key>" + key + "</key>\n\t<string>")
+	xml.EscapeText(b, []byte(value))
+	b.WriteString("</string>\n")
+}
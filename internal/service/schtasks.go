@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// taskName identifies the scheduled task
+const taskName = "ForgeTerminal"
+
+// taskScheduler runs Forge as a scheduled task that starts at logon as the
+// current user. A Windows service would run outside the user's session and
+// profile, where Forge's shells and ~/.forge data don't belong. Tasks have no
+// output redirection; Forge's own forge.log is the log.
+type taskScheduler struct{}
+
+func (taskScheduler) name() string { return "task scheduler" }
+
+func (taskScheduler) path() (string, error) {
+	return filepath.Join(storage.GetForgeDir(), "service", taskName+".xml"), nil
+}
+
+func (taskScheduler) install(spec *Spec, path string) error {
+	account := ""
+	if u, err := user.Current(); err == nil {
+		account = u.Username
+	}
+	if err := os.WriteFile(path, utf16File(taskXML(spec, account)), 0644); err != nil {
+		return err
+	}
+	if _, err := run("schtasks", "/Create", "/TN", taskName, "/XML", path, "/F"); err != nil {
+		return err
+	}
+	_, err := run("schtasks", "/Run", "/TN", taskName)
+	return err
+}
+
+func (taskScheduler) uninstall(path string) error {
+	run("schtasks", "/End", "/TN", taskName)
+	if _, err := run("schtasks", "/Delete", "/TN", taskName, "/F"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (taskScheduler) running() (bool, string) {
+	out, err := run("schtasks", "/Query", "/TN", taskName, "/FO", "LIST")
+	if err != nil {
+		return false, "not registered"
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) == "Status" {
+			status := strings.TrimSpace(value)
+			return status == "Running", status
+		}
+	}
+	return false, ""
+}
+
+// taskXML renders the task definition for a spec. The task restarts Forge
+// every minute if it fails, up to the maximum retry count.
+func taskXML(spec *Spec, account string) string {
+	esc := func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	userID := ""
+	if account != "" {
+		userID = "<UserId>" + esc(account) + "</UserId>"
+	}
+
+	args := make([]string, len(spec.Args))
+	for i, arg := range spec.Args {
+		args[i] = windowsQuote(arg)
+	}
+
+	return `<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <RegistrationInfo>
+    <Description>Forge Terminal</Description>
+  </RegistrationInfo>
+  <Triggers>
+    <LogonTrigger>
+      <Enabled>true</Enabled>` + userID + `
+    </LogonTrigger>
+  </Triggers>
+  <Principals>
+    <Principal id="Author">` + userID + `
+      <LogonType>InteractiveToken</LogonType>
+      <RunLevel>LeastPrivilege</RunLevel>
+    </Principal>
+  </Principals>
+  <Settings>
+    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>
+    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>
+    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>
+    <ExecutionTimeLimit>PT0S</ExecutionTimeLimit>
+    <RestartOnFailure>
+      <Interval>PT1M</Interval>
+      <Count>999</Count>
+    </RestartOnFailure>
+    <Hidden>true</Hidden>
+  </Settings>
+  <Actions Context="Author">
+    <Exec>
+      <Command>` + esc(spec.Executable) + `</Command>
+      <Arguments>` + esc(strings.Join(args, " ")) + `</Arguments>
+    </Exec>
+  </Actions>
+</Task>
+`
+}
+
+// windowsQuote quotes an argument for a Windows command line.
+func windowsQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+}
+
+// utf16File encodes text as UTF-16LE with a byte order mark, which schtasks
+// expects of task XML files.
+func utf16File(text string) []byte {
+	units := utf16.Encode([]rune(text))
+	buf := make([]byte, 2+2*len(units))
+	buf[0], buf[1] = 0xFF, 0xFE
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[2+2*i:], u)
+	}
+	return buf
+}
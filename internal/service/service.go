@@ -0,0 +1,140 @@
+// Package service installs Forge as a per-user background service that starts
+// at login and is restarted if it fails: a systemd user unit on Linux, a
+// launchd agent on macOS, and a logon scheduled task on Windows.
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// Name identifies the service to the platform's service manager
+const Name = "forge-terminal"
+
+// Status describes the installed service.
+type Status struct {
+	Installed bool   `json:"installed"`
+	Running   bool   `json:"running"`
+	Manager   string `json:"manager"` // "systemd", "launchd", or "task scheduler"
+	Path      string `json:"path"`    // Unit, plist, or task definition file
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Spec is what the service runs.
+type Spec struct {
+	Executable string
+	Args       []string // Server flags; --no-browser is always added
+	LogPath    string   // Where stdout and stderr are appended
+}
+
+// NewSpec returns a spec running this executable with the given server flags.
+func NewSpec(args []string) (*Spec, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return &Spec{
+		Executable: exe,
+		Args:       append([]string{"--no-browser"}, args...),
+		LogPath:    filepath.Join(storage.GetForgeDir(), "service.log"),
+	}, nil
+}
+
+// run executes a service manager command; replaced in tests
+var run = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil && output != "" {
+		err = fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), output)
+	}
+	return output, err
+}
+
+// platform is a service manager.
+type platform interface {
+	name() string
+	path() (string, error)
+	install(spec *Spec, path string) error
+	uninstall(path string) error
+	running() (bool, string)
+}
+
+func current() (platform, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return systemd{}, nil
+	case "darwin":
+		return launchd{}, nil
+	case "windows":
+		return taskScheduler{}, nil
+	default:
+		return nil, fmt.Errorf("running as a service is not supported on %s", runtime.GOOS)
+	}
+}
+
+// Install writes the service definition, registers it, and starts it. An
+// existing installation is replaced. Returns the definition file's path.
+func Install(spec *Spec) (string, error) {
+	p, err := current()
+	if err != nil {
+		return "", err
+	}
+	path, err := p.path()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(spec.LogPath), 0700); err != nil {
+		return "", err
+	}
+	if err := p.install(spec, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Uninstall stops the service and removes its definition.
+func Uninstall() error {
+	p, err := current()
+	if err != nil {
+		return err
+	}
+	path, err := p.path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("service is not installed")
+	}
+	return p.uninstall(path)
+}
+
+// GetStatus reports whether the service is installed and running.
+func GetStatus() (*Status, error) {
+	p, err := current()
+	if err != nil {
+		return nil, err
+	}
+	path, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Manager: p.name(), Path: path}
+	if _, err := os.Stat(path); err != nil {
+		return status, nil
+	}
+	status.Installed = true
+	status.Running, status.Detail = p.running()
+	return status, nil
+}
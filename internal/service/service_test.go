@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/xml"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+var testSpec = &Spec{
+	Executable: "/opt/forge dir/forge",
+	Args:       []string{"--no-browser", "--port", "9000"},
+	LogPath:    "/home/me/.forge/service.log",
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit(testSpec)
+	for _, want := range []string{
+		`ExecStart="/opt/forge dir/forge" --no-browser --port 9000`,
+		"Restart=on-failure",
+		"StandardOutput=append:/home/me/.forge/service.log",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("Expected unit to contain %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := launchdPlist(&Spec{Executable: "/Applications/Forge & Co/forge", LogPath: "/tmp/forge.log"})
+	if err := xml.Unmarshal([]byte(plist), new(interface{})); err != nil {
+		t.Fatalf("Expected valid XML: %v\n%s", err, plist)
+	}
+	if !strings.Contains(plist, "<string>/Applications/Forge &amp; Co/forge</string>") {
+		t.Errorf("Expected the escaped executable in ProgramArguments:\n%s", plist)
+	}
+	if !strings.Contains(plist, "<key>SuccessfulExit</key>") {
+		t.Errorf("Expected KeepAlive to restart on failure:\n%s", plist)
+	}
+}
+
+func TestTaskXML(t *testing.T) {
+	task := taskXML(&Spec{Executable: `C:\Program Files\Forge\forge.exe`, Args: []string{"--no-browser", "--workspace", `C:\My Projects`}}, `DESKTOP\me`)
+	for _, want := range []string{
+		`<Command>C:\Program Files\Forge\forge.exe</Command>`,
+		`<Arguments>--no-browser --workspace &#34;C:\My Projects&#34;</Arguments>`,
+		`<UserId>DESKTOP\me</UserId>`,
+		"<RestartOnFailure>",
+	} {
+		if !strings.Contains(task, want) {
+			t.Errorf("Expected task to contain %q:\n%s", want, task)
+		}
+	}
+
+	data := utf16File("<a/>")
+	if data[0] != 0xFF || data[1] != 0xFE {
+		t.Errorf("Expected a UTF-16LE byte order mark, got % x", data[:2])
+	}
+	units := make([]uint16, 0, (len(data)-2)/2)
+	for i := 2; i < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+	if got := string(utf16.Decode(units)); got != "<a/>" {
+		t.Errorf("Expected round trip, got %q", got)
+	}
+}
+
+func TestInstallSystemd(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd only")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var calls []string
+	original := run
+	run = func(name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return "active", nil
+	}
+	t.Cleanup(func() { run = original })
+
+	spec := *testSpec
+	spec.LogPath = t.TempDir() + "/service.log"
+	path, err := Install(&spec)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected unit file at %s: %v", path, err)
+	}
+	if !strings.Contains(strings.Join(calls, "\n"), "systemctl --user enable "+Name+".service") {
+		t.Errorf("Expected the unit to be enabled, got calls %v", calls)
+	}
+
+	status, err := GetStatus()
+	if err != nil || !status.Installed || !status.Running {
+		t.Errorf("Expected installed and running, got %+v (%v)", status, err)
+	}
+
+	if err := Uninstall(); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the unit file to be removed")
+	}
+	if err := Uninstall(); err == nil {
+		t.Error("Expected error uninstalling twice")
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemd runs Forge as a systemd user unit. User units stop at logout unless
+// lingering is enabled (loginctl enable-linger).
+type systemd struct{}
+
+func (systemd) name() string { return "systemd" }
+
+func (systemd) path() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "systemd", "user", Name+".service"), nil
+}
+
+func (systemd) install(spec *Spec, path string) error {
+	if err := os.WriteFile(path, []byte(systemdUnit(spec)), 0644); err != nil {
+		return err
+	}
+	if _, err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	if _, err := run("systemctl", "--user", "enable", Name+".service"); err != nil {
+		return err
+	}
+	_, err := run("systemctl", "--user", "restart", Name+".service")
+	return err
+}
+
+func (systemd) uninstall(path string) error {
+	run("systemctl", "--user", "disable", "--now", Name+".service")
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	_, err := run("systemctl", "--user", "daemon-reload")
+	return err
+}
+
+func (systemd) running() (bool, string) {
+	state, _ := run("systemctl", "--user", "is-active", Name+".service")
+	return state == "active", state
+}
+
+// systemdUnit renders the unit file for a spec.
+func systemdUnit(spec *Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=Forge Terminal\n")
+	b.WriteString("After=network.target\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommand(append([]string{spec.Executable}, spec.Args...)))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	fmt.Fprintf(&b, "StandardOutput=append:%s\n", spec.LogPath)
+	fmt.Fprintf(&b, "StandardError=append:%s\n\n", spec.LogPath)
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdCommand quotes a command line for ExecStart.
+func systemdCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;$%") {
+			quoted[i] = arg
+			continue
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`, `$`, `$$`).Replace(arg)
+		quoted[i] = `"` + escaped + `"`
+	}
+	return strings.Join(quoted, " ")
+}
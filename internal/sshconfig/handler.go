@@ -0,0 +1,24 @@
+package sshconfig
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleHosts lists the hosts in the user's ssh config (GET).
+func HandleHosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	hosts, err := Hosts()
+	if err != nil {
+		http.Error(w, "Failed to read ssh config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"hosts": hosts,
+		"path":  ConfigPath(),
+	})
+}
@@ -0,0 +1,264 @@
+// Package sshconfig reads the user's OpenSSH client config (~/.ssh/config) to
+// offer its hosts when opening an SSH tab. It understands the settings a host
+// picker shows (HostName, User, Port, ProxyJump, IdentityFile), wildcard Host
+// blocks that supply them, and Include; Match blocks are skipped.
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxIncludeDepth stops Include loops
+const maxIncludeDepth = 16
+
+// Host is a concrete host alias from the config, with the settings that
+// apply to it.
+type Host struct {
+	Alias        string   `json:"alias"`    // The name to pass to ssh
+	HostName     string   `json:"hostName"` // The real host (the alias when not set)
+	User         string   `json:"user,omitempty"`
+	Port         int      `json:"port,omitempty"`         // 0 = ssh's default (22)
+	ProxyJump    []string `json:"proxyJump,omitempty"`    // Jump hosts in connection order
+	IdentityFile string   `json:"identityFile,omitempty"` // First identity file, ~ expanded
+}
+
+// block is a Host (or Match) section and the settings in it, in order
+type block struct {
+	patterns []string // Empty for the lines before the first Host
+	match    bool     // A Match block, which never applies here
+	settings [][2]string
+}
+
+// ConfigPath returns the user's ssh client config path.
+func ConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "config")
+}
+
+// Hosts returns the concrete hosts in the user's ssh config, sorted by
+// alias. A missing config has no hosts.
+func Hosts() ([]Host, error) {
+	configPath := ConfigPath()
+	if configPath == "" {
+		return []Host{}, nil
+	}
+	blocks, err := parseFile(configPath, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Host{}, nil
+		}
+		return nil, err
+	}
+	return resolve(blocks), nil
+}
+
+// Parse reads an ssh config and returns its concrete hosts, sorted by
+// alias. Include directives are resolved relative to ~/.ssh.
+func Parse(r io.Reader) ([]Host, error) {
+	blocks, err := parseReader(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resolve(blocks), nil
+}
+
+func parseFile(file string, depth int) ([]block, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseReader(f, depth)
+}
+
+// parseReader splits a config into blocks, inlining included files
+func parseReader(r io.Reader, depth int) ([]block, error) {
+	blocks := []block{{}}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value := splitLine(scanner.Text())
+		if key == "" {
+			continue
+		}
+		switch key {
+		case "host":
+			blocks = append(blocks, block{patterns: append([]string{}, fields(value)...)})
+		case "match":
+			blocks = append(blocks, block{match: true})
+		case "include":
+			if depth >= maxIncludeDepth {
+				return nil, fmt.Errorf("ssh config includes nest deeper than %d", maxIncludeDepth)
+			}
+			for _, pattern := range fields(value) {
+				files, _ := filepath.Glob(includePath(pattern))
+				for _, file := range files {
+					included, err := parseFile(file, depth+1)
+					if err != nil {
+						continue // ssh ignores unreadable includes too
+					}
+					// Settings before the included file's first Host continue
+					// the current block
+					current := &blocks[len(blocks)-1]
+					current.settings = append(current.settings, included[0].settings...)
+					blocks = append(blocks, included[1:]...)
+				}
+			}
+		default:
+			current := &blocks[len(blocks)-1]
+			current.settings = append(current.settings, [2]string{key, value})
+		}
+	}
+	return blocks, scanner.Err()
+}
+
+// splitLine returns a config line's lowercased keyword and its value, which
+// may follow whitespace or "="
+func splitLine(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), ""
+	}
+	key := strings.ToLower(line[:end])
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return key, value
+}
+
+// fields splits a value on whitespace, keeping double-quoted parts together
+func fields(value string) []string {
+	var out []string
+	var current strings.Builder
+	quoted, started := false, false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted, started = !quoted, true
+		case (r == ' ' || r == '\t') && !quoted:
+			if started {
+				out = append(out, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		out = append(out, current.String())
+	}
+	return out
+}
+
+// includePath resolves an Include argument: ~ is the home directory, and
+// relative paths are in ~/.ssh
+func includePath(pattern string) string {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(ConfigPath()), pattern)
+	}
+	return pattern
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	return p
+}
+
+// isWildcard reports whether a Host pattern matches more than one name
+func isWildcard(pattern string) bool {
+	return strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "*?")
+}
+
+// matches reports whether a block applies to alias: some pattern matches it
+// and no negated pattern does
+func (b block) matches(alias string) bool {
+	if b.match {
+		return false
+	}
+	if b.patterns == nil {
+		return true // Settings before the first Host apply to every host
+	}
+	matched := false
+	for _, pattern := range b.patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(alias)); ok {
+			if negated {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+// resolve lists every concrete alias with the settings that apply to it.
+// As in ssh, the first value found for each setting wins.
+func resolve(blocks []block) []Host {
+	seen := map[string]bool{}
+	hosts := []Host{}
+	for _, b := range blocks {
+		for _, alias := range b.patterns {
+			if isWildcard(alias) || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			hosts = append(hosts, hostFor(alias, blocks))
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Alias < hosts[j].Alias })
+	return hosts
+}
+
+func hostFor(alias string, blocks []block) Host {
+	values := map[string]string{}
+	for _, b := range blocks {
+		if !b.matches(alias) {
+			continue
+		}
+		for _, setting := range b.settings {
+			if _, ok := values[setting[0]]; !ok {
+				values[setting[0]] = setting[1]
+			}
+		}
+	}
+
+	host := Host{Alias: alias, HostName: alias, User: values["user"]}
+	if hostName := values["hostname"]; hostName != "" {
+		host.HostName = strings.ReplaceAll(hostName, "%h", alias)
+	}
+	if port, err := strconv.Atoi(values["port"]); err == nil && port > 0 {
+		host.Port = port
+	}
+	if jump := values["proxyjump"]; jump != "" && !strings.EqualFold(jump, "none") {
+		for _, hop := range strings.Split(jump, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				host.ProxyJump = append(host.ProxyJump, hop)
+			}
+		}
+	}
+	if identity := fields(values["identityfile"]); len(identity) > 0 {
+		host.IdentityFile = expandHome(identity[0])
+	}
+	return host
+}
@@ -0,0 +1,121 @@
+package sshconfig
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withHome points HOME at a temp dir, so ConfigPath is ~/.ssh/config in it
+func withHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+}
+
+func withConfig(t *testing.T, content string) string {
+	t.Helper()
+	withHome(t)
+	configPath := ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Dir(configPath)
+}
+
+func TestParse(t *testing.T) {
+	hosts, err := Parse(strings.NewReader(`
+# Work machines
+Host bastion
+    HostName bastion.example.com
+    User ops
+
+Host db web
+    HostName %h.internal
+    ProxyJump bastion,jump2
+    Port=2222
+
+Host web
+    Port 22
+
+Host *.example.com !skip.example.com
+    User deploy
+
+Match host foo
+    User nobody
+
+Host *
+    User me
+    IdentityFile "~/.ssh/id ed25519"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	home, _ := os.UserHomeDir()
+	want := []Host{
+		{Alias: "bastion", HostName: "bastion.example.com", User: "ops", IdentityFile: filepath.Join(home, ".ssh/id ed25519")},
+		{Alias: "db", HostName: "db.internal", User: "me", Port: 2222, ProxyJump: []string{"bastion", "jump2"}, IdentityFile: filepath.Join(home, ".ssh/id ed25519")},
+		{Alias: "web", HostName: "web.internal", User: "me", Port: 2222, ProxyJump: []string{"bastion", "jump2"}, IdentityFile: filepath.Join(home, ".ssh/id ed25519")},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("Parse mismatch\n got: %+v\nwant: %+v", hosts, want)
+	}
+}
+
+func TestHostsInclude(t *testing.T) {
+	dir := withConfig(t, "Include conf.d/*\n\nHost main\n    User root\n")
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	included := "Host extra\n    HostName 10.0.0.5\n    ProxyJump none\n"
+	if err := os.WriteFile(filepath.Join(dir, "conf.d", "extra"), []byte(included), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := Hosts()
+	if err != nil {
+		t.Fatalf("Hosts failed: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Alias != "extra" || hosts[0].HostName != "10.0.0.5" || hosts[0].ProxyJump != nil {
+		t.Fatalf("Expected the included host, got %+v", hosts)
+	}
+	// Host lines after the Include start their own blocks again
+	if hosts[1].Alias != "main" || hosts[1].User != "root" {
+		t.Errorf("Expected main after the include, got %+v", hosts[1])
+	}
+}
+
+func TestHostsMissingConfig(t *testing.T) {
+	withHome(t)
+
+	hosts, err := Hosts()
+	if err != nil || len(hosts) != 0 {
+		t.Errorf("Expected no hosts and no error, got %+v (%v)", hosts, err)
+	}
+}
+
+func TestHandleHosts(t *testing.T) {
+	withConfig(t, "Host dev\n  HostName dev.local\n")
+
+	rec := httptest.NewRecorder()
+	HandleHosts(rec, httptest.NewRequest("GET", "/api/v1/ssh/hosts", nil))
+	var body struct {
+		Hosts []Host `json:"hosts"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Hosts) != 1 || body.Hosts[0].HostName != "dev.local" {
+		t.Errorf("Expected the dev host, got %+v (%v)", body, err)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleHosts(rec, httptest.NewRequest("POST", "/api/v1/ssh/hosts", nil))
+	if rec.Code != 405 {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix is appended to a state file's path for its last good version
+const BackupSuffix = ".bak"
+
+// corruptSuffix is appended to a state file moved aside during recovery
+const corruptSuffix = ".corrupt"
+
+// WriteFileAtomic replaces path with data via a temp file in the same directory
+// and a rename, so a crash mid-write never leaves a truncated file. If the
+// current contents are valid JSON they are first kept as path+BackupSuffix.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if current, err := os.ReadFile(path); err == nil && json.Valid(current) && !bytes.Equal(current, data) {
+		if err := writeTemp(path+BackupSuffix, current, perm); err != nil {
+			return err
+		}
+	}
+	return writeTemp(path, data, perm)
+}
+
+// writeTemp writes data to a temp file, syncs it, and renames it over path.
+func writeTemp(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// ReadJSONFile reads a JSON state file written by WriteFileAtomic. If the file
+// doesn't parse (e.g. it was corrupted outside Forge), the last good version
+// is restored from the backup, the bad file is kept as path+".corrupt", and a
+// warning is logged. Errors from reading, such as os.IsNotExist, are returned
+// unchanged; unrecoverable contents are returned as-is for the caller to reject.
+func ReadJSONFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || json.Valid(data) {
+		return data, err
+	}
+
+	backup, err := os.ReadFile(path + BackupSuffix)
+	if err != nil || !json.Valid(backup) {
+		log.Printf("[Storage] Warning: %s is corrupt and has no usable backup", path)
+		return data, nil
+	}
+
+	log.Printf("[Storage] Warning: %s is corrupt; restored the last good version (bad copy kept as %s)", path, filepath.Base(path)+corruptSuffix)
+	if err := os.WriteFile(path+corruptSuffix, data, 0600); err != nil {
+		log.Printf("[Storage] Failed to keep corrupt copy of %s: %v", path, err)
+	}
+	if err := writeTemp(path, backup, 0600); err != nil {
+		log.Printf("[Storage] Failed to restore %s: %v", path, err)
+	}
+	return backup, nil
+}
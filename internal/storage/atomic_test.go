@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomicKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	if err := WriteFileAtomic(path, []byte(`{"v":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + BackupSuffix); !os.IsNotExist(err) {
+		t.Error("backup written for a new file")
+	}
+
+	if err := WriteFileAtomic(path, []byte(`{"v":2}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"v":2}` {
+		t.Errorf("file = %s", data)
+	}
+	if data, _ := os.ReadFile(path + BackupSuffix); string(data) != `{"v":1}` {
+		t.Errorf("backup = %s", data)
+	}
+
+	// A corrupt current file is never promoted to the backup
+	if err := os.WriteFile(path, []byte(`{"v":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte(`{"v":3}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path + BackupSuffix); string(data) != `{"v":1}` {
+		t.Errorf("backup = %s", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 2 {
+		t.Errorf("leftover temp files: %v", entries)
+	}
+}
+
+func TestReadJSONFileRecovers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	if _, err := ReadJSONFile(path); !os.IsNotExist(err) {
+		t.Errorf("err = %v, want not exist", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(`{"v":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte(`{"v":2}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadJSONFile(path)
+	if err != nil || string(data) != `{"v":2}` {
+		t.Errorf("ReadJSONFile = %s, %v", data, err)
+	}
+
+	// Simulate a truncated write
+	if err := os.WriteFile(path, []byte(`{"v":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ReadJSONFile(path)
+	if err != nil || string(data) != `{"v":1}` {
+		t.Errorf("recovered = %s, %v", data, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"v":1}` {
+		t.Errorf("file after recovery = %s", data)
+	}
+	if data, _ := os.ReadFile(path + corruptSuffix); string(data) != `{"v":` {
+		t.Errorf("corrupt copy = %s", data)
+	}
+
+	// Without a usable backup the bad contents are returned for the caller to reject
+	os.Remove(path + BackupSuffix)
+	if err := os.WriteFile(path, []byte(`garbage`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ReadJSONFile(path); err != nil || string(data) != "garbage" {
+		t.Errorf("ReadJSONFile = %s, %v", data, err)
+	}
+}
@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend types
+const (
+	BackendLocal  = "local"
+	BackendS3     = "s3"
+	BackendWebDAV = "webdav"
+)
+
+// ErrNotFound is returned by Backend.Get for keys that don't exist
+var ErrNotFound = errors.New("object not found")
+
+// backendTimeout bounds a single remote request
+const backendTimeout = 60 * time.Second
+
+// Backend stores long-term data (AM archives, session exports, recordings)
+// under slash-separated keys such as "am/archive/2024-01-02_tab.md".
+type Backend interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// List returns objects whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Object describes a stored object.
+type Object struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// BackendConfig selects and configures the storage backend (Config.Storage).
+type BackendConfig struct {
+	Type string `json:"type"` // "local" (default), "s3", or "webdav"
+
+	// local: directory objects are stored in (default ~/.forge/storage)
+	Dir string `json:"dir,omitempty"`
+
+	// s3 (or any S3-compatible service): Endpoint defaults to AWS for Region
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+
+	// webdav: base collection URL, e.g. https://cloud.example.com/remote.php/dav/files/me/forge
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Prefix is prepended to every key (s3 and webdav), e.g. "laptop/"
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// Validate checks that the fields required by the backend type are set.
+func (c BackendConfig) Validate() error {
+	switch c.Type {
+	case "", BackendLocal:
+		if c.Dir != "" && !filepath.IsAbs(c.Dir) {
+			return fmt.Errorf("storage dir must be an absolute path")
+		}
+	case BackendS3:
+		if c.Bucket == "" {
+			return fmt.Errorf("s3 storage requires a bucket")
+		}
+		if c.Endpoint == "" && c.Region == "" {
+			return fmt.Errorf("s3 storage requires a region or endpoint")
+		}
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return fmt.Errorf("s3 storage requires accessKeyId and secretAccessKey")
+		}
+		if c.Endpoint != "" {
+			if err := checkHTTPURL(c.Endpoint); err != nil {
+				return fmt.Errorf("s3 endpoint: %w", err)
+			}
+		}
+	case BackendWebDAV:
+		if c.URL == "" {
+			return fmt.Errorf("webdav storage requires a url")
+		}
+		if err := checkHTTPURL(c.URL); err != nil {
+			return fmt.Errorf("webdav url: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown storage type %q (expected local, s3, or webdav)", c.Type)
+	}
+	return nil
+}
+
+func checkHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q must be an http(s) URL", raw)
+	}
+	return nil
+}
+
+// NewBackend creates the backend described by config.
+func NewBackend(config BackendConfig) (Backend, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: backendTimeout}
+	switch config.Type {
+	case BackendS3:
+		return newS3Backend(config, client), nil
+	case BackendWebDAV:
+		return newWebDAVBackend(config, client), nil
+	default:
+		dir := config.Dir
+		if dir == "" {
+			dir = GetStorageDir()
+		}
+		return &LocalBackend{Root: dir}, nil
+	}
+}
+
+var (
+	current   Backend
+	currentMu sync.RWMutex
+)
+
+// Configure replaces the backend returned by Current.
+func Configure(config BackendConfig) error {
+	backend, err := NewBackend(config)
+	if err != nil {
+		return err
+	}
+	currentMu.Lock()
+	current = backend
+	currentMu.Unlock()
+	return nil
+}
+
+// Current returns the configured backend (a local one until Configure is called).
+func Current() Backend {
+	currentMu.RLock()
+	backend := current
+	currentMu.RUnlock()
+	if backend == nil {
+		return &LocalBackend{Root: GetStorageDir()}
+	}
+	return backend
+}
+
+// Remote returns the configured backend if it stores data off this machine.
+func Remote() (Backend, bool) {
+	backend := Current()
+	if _, local := backend.(*LocalBackend); local {
+		return nil, false
+	}
+	return backend, true
+}
+
+// CleanKey validates a key and returns it in canonical form. Keys are
+// slash-separated relative paths without "." or ".." segments.
+func CleanKey(key string) (string, error) {
+	key = strings.Trim(strings.ReplaceAll(key, "\\", "/"), "/")
+	if key == "" {
+		return "", fmt.Errorf("empty storage key")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return key, nil
+}
+
+// LocalBackend stores objects as files under Root.
+type LocalBackend struct {
+	Root string
+}
+
+func (b *LocalBackend) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(b.Root, filepath.FromSlash(key)), nil
+}
+
+// Put writes an object, creating parent directories as needed.
+func (b *LocalBackend) Put(ctx context.Context, key string, data []byte) error {
+	p, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0600)
+}
+
+// Get reads an object.
+func (b *LocalBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	p, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Delete removes an object. Deleting a missing object is not an error.
+func (b *LocalBackend) Delete(ctx context.Context, key string) error {
+	p, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List walks Root for files whose key starts with prefix.
+func (b *LocalBackend) List(ctx context.Context, prefix string) ([]Object, error) {
+	objects := []Object{}
+	err := filepath.WalkDir(b.Root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == b.Root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.Root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		objects = append(objects, Object{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(objects)
+	return objects, nil
+}
+
+func sortObjects(objects []Object) {
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+}
+
+// joinKey prepends a backend's configured prefix to a key.
+func joinKey(prefix, key string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return key
+	}
+	return path.Join(prefix, key)
+}
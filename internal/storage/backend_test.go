@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// exerciseBackend runs the same put/get/list/delete sequence against any backend.
+func exerciseBackend(t *testing.T, backend Backend) {
+	t.Helper()
+	ctx := context.Background()
+
+	for key, data := range map[string]string{
+		"am/archive/one.md":     "first",
+		"am/archive/two.md":     "second",
+		"sessions/work.json":    "{}",
+		"recordings/a b/c.cast": "cast",
+	} {
+		if err := backend.Put(ctx, key, []byte(data)); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+
+	data, err := backend.Get(ctx, "am/archive/two.md")
+	if err != nil || string(data) != "second" {
+		t.Errorf("Get = %q, %v", data, err)
+	}
+	if _, err := backend.Get(ctx, "am/archive/missing.md"); err != ErrNotFound {
+		t.Errorf("Get(missing) err = %v, want ErrNotFound", err)
+	}
+
+	objects, err := backend.List(ctx, "am/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 || objects[0].Key != "am/archive/one.md" || objects[1].Size != 6 {
+		t.Errorf("List(am/) = %+v", objects)
+	}
+	all, err := backend.List(ctx, "")
+	if err != nil || len(all) != 4 {
+		t.Errorf("List() = %+v, %v", all, err)
+	}
+
+	if err := backend.Delete(ctx, "am/archive/one.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Delete(ctx, "am/archive/one.md"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+	if objects, _ := backend.List(ctx, "am/"); len(objects) != 1 {
+		t.Errorf("List after delete = %+v", objects)
+	}
+
+	if err := backend.Put(ctx, "../escape", []byte("x")); err == nil {
+		t.Error("expected error for key with ..")
+	}
+}
+
+func TestLocalBackend(t *testing.T) {
+	exerciseBackend(t, &LocalBackend{Root: t.TempDir()})
+
+	empty := &LocalBackend{Root: t.TempDir() + "/missing"}
+	if objects, err := empty.List(context.Background(), ""); err != nil || len(objects) != 0 {
+		t.Errorf("List on missing root = %v, %v", objects, err)
+	}
+}
+
+// fakeS3 is a minimal in-memory S3 that checks requests are signed.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(auth, "/eu-west-1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>unsigned</Message></Error>`)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/bucket":
+		prefix := r.URL.Query().Get("prefix")
+		type content struct {
+			Key          string
+			Size         int
+			LastModified time.Time
+		}
+		result := struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []content
+		}{}
+		for k, v := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				result.Contents = append(result.Contents, content{Key: k, Size: len(v), LastModified: time.Now()})
+			}
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Backend(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	backend, err := NewBackend(BackendConfig{
+		Type:            BackendS3,
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "bucket",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Prefix:          "laptop",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseBackend(t, backend)
+
+	// Keys are stored under the prefix, with spaces escaped in the request path
+	if _, ok := fake.objects["laptop/recordings/a b/c.cast"]; !ok {
+		t.Errorf("objects = %v", fake.objects)
+	}
+
+	bad, _ := NewBackend(BackendConfig{Type: BackendS3, Endpoint: server.URL, Bucket: "bucket", AccessKeyID: "other", SecretAccessKey: "x"})
+	if err := bad.Put(context.Background(), "am/x", nil); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("err = %v, want AccessDenied", err)
+	}
+}
+
+func TestS3CanonicalEncoding(t *testing.T) {
+	if got := s3EscapePath("/bucket/a b/c+d~e.txt"); got != "/bucket/a%20b/c%2Bd~e.txt" {
+		t.Errorf("s3EscapePath = %q", got)
+	}
+	query := map[string][]string{"prefix": {"am/x y"}, "list-type": {"2"}}
+	if got := s3CanonicalQuery(query); got != "list-type=2&prefix=am%2Fx%20y" {
+		t.Errorf("s3CanonicalQuery = %q", got)
+	}
+}
+
+// fakeWebDAV is a minimal in-memory WebDAV server requiring basic auth.
+type fakeWebDAV struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+func (f *fakeWebDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "pw" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := strings.TrimSuffix(r.URL.Path, "/")
+	parent := p[:strings.LastIndex(p, "/")]
+	switch r.Method {
+	case "MKCOL":
+		if f.dirs[p] {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		f.dirs[p] = true
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		if !f.dirs[parent] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.files[p], _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := f.files[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		if _, ok := f.files[p]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.files, p)
+		w.WriteHeader(http.StatusNoContent)
+	case "PROPFIND":
+		if !f.dirs[p] || r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		members := []string{}
+		for name := range f.dirs {
+			if name != p && strings.HasPrefix(name, p+"/") && !strings.Contains(name[len(p)+1:], "/") {
+				members = append(members, fmt.Sprintf(`<d:response><d:href>%s/</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop></d:propstat></d:response>`, name))
+			}
+		}
+		for name, data := range f.files {
+			if strings.HasPrefix(name, p+"/") && !strings.Contains(name[len(p)+1:], "/") {
+				members = append(members, fmt.Sprintf(`<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype/><d:getcontentlength>%d</d:getcontentlength><d:getlastmodified>Mon, 02 Jan 2006 15:04:05 GMT</d:getlastmodified></d:prop></d:propstat></d:response>`, strings.ReplaceAll(name, " ", "%20"), len(data)))
+			}
+		}
+		sort.Strings(members)
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:"><d:response><d:href>%s/</d:href></d:response>%s</d:multistatus>`, p, strings.Join(members, ""))
+	}
+}
+
+func TestWebDAVBackend(t *testing.T) {
+	fake := &fakeWebDAV{files: map[string][]byte{}, dirs: map[string]bool{"/dav": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	backend, err := NewBackend(BackendConfig{Type: BackendWebDAV, URL: server.URL + "/dav/", Username: "me", Password: "pw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseBackend(t, backend)
+
+	if !fake.dirs["/dav/am/archive"] {
+		t.Errorf("parent collections were not created: %v", fake.dirs)
+	}
+}
+
+func TestBackendConfigValidate(t *testing.T) {
+	valid := []BackendConfig{
+		{},
+		{Type: BackendLocal},
+		{Type: BackendS3, Region: "us-east-1", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"},
+		{Type: BackendWebDAV, URL: "https://dav.example.com/forge"},
+	}
+	for _, c := range valid {
+		if err := c.Validate(); err != nil {
+			t.Errorf("%+v: %v", c, err)
+		}
+	}
+	invalid := []BackendConfig{
+		{Type: "ftp"},
+		{Type: BackendLocal, Dir: "relative"},
+		{Type: BackendS3, Region: "us-east-1", AccessKeyID: "a", SecretAccessKey: "s"},
+		{Type: BackendS3, Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"},
+		{Type: BackendS3, Region: "r", Bucket: "b"},
+		{Type: BackendWebDAV},
+		{Type: BackendWebDAV, URL: "ftp://dav.example.com"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%+v: expected error", c)
+		}
+	}
+}
+
+func TestCleanKey(t *testing.T) {
+	for in, want := range map[string]string{"am/x.md": "am/x.md", "/am/x.md/": "am/x.md", `am\x.md`: "am/x.md"} {
+		if got, err := CleanKey(in); err != nil || got != want {
+			t.Errorf("CleanKey(%q) = %q, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "am/../x", "am//x", "./x"} {
+		if _, err := CleanKey(in); err == nil {
+			t.Errorf("CleanKey(%q): expected error", in)
+		}
+	}
+}
@@ -6,8 +6,17 @@ import (
 	"path/filepath"
 )
 
-// GetForgeDir returns the root Forge data directory.
+// GetForgeDir returns the root Forge data directory. A process running as a
+// profile (see ProfileEnv) keeps its data under that profile's directory instead.
 func GetForgeDir() string {
+	if profile := CurrentProfile(); profile != "" {
+		return GetProfileDir(profile)
+	}
+	return GetBaseForgeDir()
+}
+
+// GetBaseForgeDir returns ~/.forge regardless of the current profile.
+func GetBaseForgeDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ".forge"
@@ -25,6 +34,11 @@ func GetAssistantDir() string {
 	return filepath.Join(GetForgeDir(), "assistant")
 }
 
+// GetAuditDir returns the directory of the append-only audit log.
+func GetAuditDir() string {
+	return filepath.Join(GetForgeDir(), "audit")
+}
+
 // GetTerminalConfigPath returns the path to terminal config file.
 func GetTerminalConfigPath() string {
 	return filepath.Join(GetTerminalDir(), "config.json")
@@ -35,6 +49,73 @@ func GetCommandsPath() string {
 	return filepath.Join(GetTerminalDir(), "commands.json")
 }
 
+// GetCommandBackupsDir returns the directory for timestamped commands.json backups.
+func GetCommandBackupsDir() string {
+	return filepath.Join(GetTerminalDir(), "backups")
+}
+
+// GetWorkspacesPath returns the path to recent and pinned workspace roots.
+func GetWorkspacesPath() string {
+	return filepath.Join(GetTerminalDir(), "workspaces.json")
+}
+
+// GetChainsPath returns the path to saved command chains.
+func GetChainsPath() string {
+	return filepath.Join(GetTerminalDir(), "chains.json")
+}
+
+// GetSnippetsPath returns the path to saved text snippets. Secret field
+// values are kept in the OS keychain, not here.
+func GetSnippetsPath() string {
+	return filepath.Join(GetTerminalDir(), "snippets.json")
+}
+
+// GetSecretsIndexPath returns the path to the names of secrets Forge keeps in
+// the OS keychain. Values are never written here.
+func GetSecretsIndexPath() string {
+	return filepath.Join(GetTerminalDir(), "secrets.json")
+}
+
+// GetCommandUsagePath returns the path to per-command run counts.
+func GetCommandUsagePath() string {
+	return filepath.Join(GetTerminalDir(), "usage.json")
+}
+
+// GetCommandHistoryPath returns the path to command lines typed in tabs, per workspace.
+func GetCommandHistoryPath() string {
+	return filepath.Join(GetTerminalDir(), "history.json")
+}
+
+// GetDirHistoryPath returns the path to directories visited in tabs, for jumping back to them.
+func GetDirHistoryPath() string {
+	return filepath.Join(GetTerminalDir(), "dirs.json")
+}
+
+// GetSchedulesPath returns the path to scheduled and startup commands.
+func GetSchedulesPath() string {
+	return filepath.Join(GetTerminalDir(), "schedules.json")
+}
+
+// GetSessionsPath returns the path to the active tab layout.
+func GetSessionsPath() string {
+	return filepath.Join(GetTerminalDir(), "sessions.json")
+}
+
+// GetSessionHistoryDir returns the directory for restore points of the active tab layout.
+func GetSessionHistoryDir() string {
+	return filepath.Join(GetTerminalDir(), "session-history")
+}
+
+// GetNamedSessionsPath returns the path to saved named tab layouts.
+func GetNamedSessionsPath() string {
+	return filepath.Join(GetTerminalDir(), "named-sessions.json")
+}
+
+// GetRestartStatePath returns the path to terminal state saved before an update restart.
+func GetRestartStatePath() string {
+	return filepath.Join(GetTerminalDir(), "restart-state.json")
+}
+
 // GetSessionsDir returns the directory for session data.
 func GetSessionsDir() string {
 	return filepath.Join(GetTerminalDir(), "sessions")
@@ -45,11 +126,56 @@ func GetWelcomePath() string {
 	return filepath.Join(GetTerminalDir(), ".welcome-shown")
 }
 
+// GetOnboardingPath returns the path to the onboarding state file.
+func GetOnboardingPath() string {
+	return filepath.Join(GetTerminalDir(), "onboarding.json")
+}
+
+// GetStorageDir returns the default directory for the local storage backend.
+func GetStorageDir() string {
+	return filepath.Join(GetForgeDir(), "storage")
+}
+
+// GetInstancePath returns the path to the running server's instance record.
+func GetInstancePath() string {
+	return filepath.Join(GetForgeDir(), "instance.json")
+}
+
+// GetFeedbackDir returns the directory holding feedback bundles saved while offline.
+func GetFeedbackDir() string {
+	return filepath.Join(GetForgeDir(), "feedback")
+}
+
+// GetCrashesDir returns the directory holding crash reports.
+func GetCrashesDir() string {
+	return filepath.Join(GetForgeDir(), "crashes")
+}
+
+// GetNotificationsPath returns the path to the notification center's store.
+func GetNotificationsPath() string {
+	return filepath.Join(GetForgeDir(), "notifications.json")
+}
+
+// GetTLSDir returns the directory holding the generated HTTPS certificate.
+func GetTLSDir() string {
+	return filepath.Join(GetForgeDir(), "tls")
+}
+
 // GetAMDir returns the directory for Artificial Memory logs.
 func GetAMDir() string {
 	return filepath.Join(GetForgeDir(), "am")
 }
 
+// GetTrashDir returns the directory holding files deleted from the file explorer.
+func GetTrashDir() string {
+	return filepath.Join(GetForgeDir(), "trash")
+}
+
+// GetTemplatesDir returns the directory holding "New file" templates.
+func GetTemplatesDir() string {
+	return filepath.Join(GetForgeDir(), "templates")
+}
+
 // GetAssistantConfigPath returns the path to assistant config file (v2).
 func GetAssistantConfigPath() string {
 	return filepath.Join(GetAssistantDir(), "config.json")
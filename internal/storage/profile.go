@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ProfileEnv names the environment variable that selects a profile. A Forge
+// process started with it set keeps all of its data under GetProfileDir.
+const ProfileEnv = "FORGE_PROFILE"
+
+// DefaultProfile is the name that selects the unprofiled ~/.forge tree
+const DefaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidateProfileName checks that a profile name is safe to use as a directory name.
+func ValidateProfileName(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("profile name %q is reserved", name)
+	}
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use up to 32 lowercase letters, digits, '-' or '_')", name)
+	}
+	return nil
+}
+
+// CurrentProfile returns the profile this process runs as, or "" for the
+// default tree. Invalid names are ignored rather than escaping ~/.forge.
+func CurrentProfile() string {
+	name := os.Getenv(ProfileEnv)
+	if name == "" || ValidateProfileName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// GetProfilesDir returns the directory holding each profile's data tree.
+func GetProfilesDir() string {
+	return filepath.Join(GetBaseForgeDir(), "profiles")
+}
+
+// GetProfileDir returns the data directory for a profile.
+func GetProfileDir(name string) string {
+	return filepath.Join(GetProfilesDir(), name)
+}
+
+// GetProfilesPath returns the path to the profile registry.
+func GetProfilesPath() string {
+	return filepath.Join(GetBaseForgeDir(), "profiles.json")
+}
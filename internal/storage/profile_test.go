@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	base := filepath.Join(home, ".forge")
+
+	t.Setenv(ProfileEnv, "")
+	if got := GetForgeDir(); got != base {
+		t.Errorf("Expected %s without a profile, got %s", base, got)
+	}
+
+	t.Setenv(ProfileEnv, "alice")
+	want := filepath.Join(base, "profiles", "alice")
+	if got := GetForgeDir(); got != want {
+		t.Errorf("Expected %s for profile alice, got %s", want, got)
+	}
+	if got := GetCommandsPath(); got != filepath.Join(want, "terminal", "commands.json") {
+		t.Errorf("Expected commands under the profile tree, got %s", got)
+	}
+	if got := GetProfilesPath(); got != filepath.Join(base, "profiles.json") {
+		t.Errorf("Expected the registry outside the profile tree, got %s", got)
+	}
+
+	// A name that could escape ~/.forge is ignored
+	t.Setenv(ProfileEnv, "../bob")
+	if got := GetForgeDir(); got != base {
+		t.Errorf("Expected invalid profile to fall back to %s, got %s", base, got)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"alice", "ci-bot", "team_2"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("Expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "default", "Alice", "a/b", "..", "-x"} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
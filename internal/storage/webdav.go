@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// webdavBackend stores objects as files in a WebDAV collection (Nextcloud,
+// ownCloud, Apache mod_dav, ...), creating sub-collections as needed.
+type webdavBackend struct {
+	base     *url.URL
+	prefix   string
+	username string
+	password string
+	client   *http.Client
+}
+
+func newWebDAVBackend(config BackendConfig, client *http.Client) *webdavBackend {
+	u, _ := url.Parse(config.URL) // Validated by BackendConfig.Validate
+	u.Path = strings.TrimRight(u.Path, "/")
+	return &webdavBackend{
+		base:     u,
+		prefix:   strings.Trim(config.Prefix, "/"),
+		username: config.Username,
+		password: config.Password,
+		client:   client,
+	}
+}
+
+// url returns the URL of a key relative to the base collection.
+func (b *webdavBackend) url(key string) string {
+	u := *b.base
+	u.Path = b.base.Path + "/" + key
+	u.RawPath = ""
+	return u.String()
+}
+
+func (b *webdavBackend) do(ctx context.Context, method, target string, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if b.username != "" || b.password != "" {
+		req.SetBasicAuth(b.username, b.password)
+	}
+	return b.client.Do(req)
+}
+
+// Put uploads an object. If the parent collection is missing (409 Conflict),
+// it is created and the upload retried.
+func (b *webdavBackend) Put(ctx context.Context, key string, data []byte) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	key = joinKey(b.prefix, key)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := b.do(ctx, http.MethodPut, b.url(key), data, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusConflict && attempt == 0:
+			if err := b.mkcolAll(ctx, path.Dir(key)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("webdav: PUT %s: %s", key, resp.Status)
+		}
+	}
+}
+
+// mkcolAll creates dir and any missing parent collections.
+func (b *webdavBackend) mkcolAll(ctx context.Context, dir string) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+	current := ""
+	for _, part := range strings.Split(dir, "/") {
+		current = path.Join(current, part)
+		resp, err := b.do(ctx, "MKCOL", b.url(current)+"/", nil, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 means the collection already exists
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("webdav: MKCOL %s: %s", current, resp.Status)
+		}
+	}
+	return nil
+}
+
+// Get downloads an object.
+func (b *webdavBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.do(ctx, http.MethodGet, b.url(joinKey(b.prefix, key)), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webdav: GET %s: %s", key, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Delete removes an object.
+func (b *webdavBackend) Delete(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	resp, err := b.do(ctx, http.MethodDelete, b.url(joinKey(b.prefix, key)), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	return fmt.Errorf("webdav: DELETE %s: %s", key, resp.Status)
+}
+
+// webdavMultistatus is a PROPFIND response body
+type webdavMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ContentLength int64  `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+				ResourceType  struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<propfind xmlns="DAV:"><prop><resourcetype/><getcontentlength/><getlastmodified/></prop></propfind>`
+
+// List walks collections with Depth: 1 PROPFIND requests (many servers
+// disable Depth: infinity), starting from the deepest collection in prefix.
+func (b *webdavBackend) List(ctx context.Context, prefix string) ([]Object, error) {
+	start := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		start = prefix[:i]
+	}
+
+	objects := []Object{}
+	pending := []string{start}
+	for len(pending) > 0 {
+		dir := pending[0]
+		pending = pending[1:]
+
+		entries, err := b.propfind(ctx, joinKey(b.prefix, dir))
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			key := path.Join(dir, entry.name)
+			if entry.collection {
+				if strings.HasPrefix(key+"/", prefix) || strings.HasPrefix(prefix, key+"/") {
+					pending = append(pending, key)
+				}
+				continue
+			}
+			if strings.HasPrefix(key, prefix) {
+				objects = append(objects, Object{Key: key, Size: entry.size, ModTime: entry.modTime})
+			}
+		}
+	}
+	sortObjects(objects)
+	return objects, nil
+}
+
+type webdavEntry struct {
+	name       string
+	collection bool
+	size       int64
+	modTime    time.Time
+}
+
+// propfind lists the direct members of a collection.
+func (b *webdavBackend) propfind(ctx context.Context, dir string) ([]webdavEntry, error) {
+	target := b.base.String() + "/"
+	if dir != "" {
+		target = b.url(dir) + "/"
+	}
+	header := http.Header{"Depth": {"1"}, "Content-Type": {"application/xml"}}
+	resp, err := b.do(ctx, "PROPFIND", target, []byte(propfindBody), header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("webdav: PROPFIND %s: %s", dir, resp.Status)
+	}
+
+	var ms webdavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("webdav: invalid PROPFIND response: %w", err)
+	}
+
+	self, _ := url.Parse(target)
+	entries := []webdavEntry{}
+	for _, r := range ms.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		hrefPath := strings.TrimRight(href.Path, "/")
+		if hrefPath == strings.TrimRight(self.Path, "/") {
+			continue // The collection itself
+		}
+		entry := webdavEntry{name: path.Base(hrefPath)}
+		for _, ps := range r.Propstat {
+			if ps.Prop.ResourceType.Collection != nil {
+				entry.collection = true
+			}
+			if ps.Prop.ContentLength > 0 {
+				entry.size = ps.Prop.ContentLength
+			}
+			if t, err := http.ParseTime(ps.Prop.LastModified); err == nil {
+				entry.modTime = t
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,110 @@
+package terminal
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// backgroundPrefix marks session IDs started by the server rather than a browser tab
+const backgroundPrefix = "bg-"
+
+// backgroundIdleTimeout closes background sessions that have sat at a prompt this long
+const backgroundIdleTimeout = 15 * time.Minute
+
+// IsBackgroundSession reports whether a session ID belongs to a server-started session.
+func IsBackgroundSession(id string) bool {
+	return strings.HasPrefix(id, backgroundPrefix)
+}
+
+// RunTarget returns a target for running a command in a specific shell and
+// directory, independent of any browser tab. An idle background session started
+// with the same config is reused; otherwise a new one is started. Returns the
+// session ID and whether it was newly created.
+func (h *Handler) RunTarget(config ShellConfig) (*TabTarget, string, bool, error) {
+	h.backgroundMu.Lock()
+	defer h.backgroundMu.Unlock()
+
+	var reuse *TerminalSession
+	h.sessions.Range(func(key, value interface{}) bool {
+		session := value.(*TerminalSession)
+		if IsBackgroundSession(key.(string)) && sameShellConfig(session.Config, config) && session.AtPrompt() {
+			reuse = session
+			return false
+		}
+		return true
+	})
+	if reuse != nil {
+		return &TabTarget{session: reuse}, reuse.ID, false, nil
+	}
+
+	id := backgroundPrefix + uuid.New().String()
+	session, err := NewTerminalSessionWithConfig(id, &config)
+	if err != nil {
+		return nil, "", false, err
+	}
+	_ = session.Resize(120, 30)
+	h.sessions.Store(id, session)
+	log.Printf("[Terminal] Background session %s started (shell: %s, dir: %s)", id, config.ShellType, config.WorkingDir)
+
+	go h.pumpBackground(session)
+
+	// Let the shell print its first prompt before input arrives
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = session.WaitForPrompt(ctx, time.Time{})
+	return &TabTarget{session: session}, id, true, nil
+}
+
+// pumpBackground drains a background session's output (nobody else reads it)
+// and closes it once it has been idle for backgroundIdleTimeout.
+func (h *Handler) pumpBackground(session *TerminalSession) {
+	defer func() {
+		session.Close()
+		h.sessions.Delete(session.ID)
+		log.Printf("[Terminal] Background session %s closed", session.ID)
+	}()
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-session.Done():
+				return
+			case <-ticker.C:
+				if session.AtPrompt() && time.Since(session.LastOutputAt()) > backgroundIdleTimeout {
+					session.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	buf := make([]byte, 4096)
+	for {
+		if _, err := session.Read(buf); err != nil {
+			if err != io.EOF {
+				log.Printf("[Terminal] Background session %s read ended: %v", session.ID, err)
+			}
+			return
+		}
+	}
+}
+
+// sameShellConfig reports whether a session started with config a suits commands meant for b.
+func sameShellConfig(a, b ShellConfig) bool {
+	cleanDir := func(dir string) string {
+		if dir == "" {
+			return ""
+		}
+		return filepath.Clean(dir)
+	}
+	return a.ShellType == b.ShellType && a.WSLDistro == b.WSLDistro && cleanDir(a.WorkingDir) == cleanDir(b.WorkingDir) &&
+		a.Container == b.Container && a.ContainerShell == b.ContainerShell && a.TmuxSession == b.TmuxSession && a.SSHHost == b.SSHHost
+}
@@ -0,0 +1,63 @@
+package terminal
+
+import (
+	"log"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
+)
+
+// alertInterval is the least time between notifications from one tab, so a
+// program ringing the bell in a loop raises one
+const alertInterval = 5 * time.Second
+
+// alertWatcher turns the bells and OSC 9/777 notifications a tab prints
+// into notification center entries.
+type alertWatcher struct {
+	scanner ansi.AlertScanner
+	last    time.Time
+}
+
+// watchAlerts looks for alerts in a chunk of a tab's output. Plain bells
+// only raise a notification when the tab isn't focused, since the user is
+// already looking at it (and bells also mark failed Tab completions);
+// notifications a program sends explicitly always do.
+func watchAlerts(w *alertWatcher, tracker *commandTracker, tabID, tabName string, data []byte) {
+	for _, alert := range w.scanner.Feed(data) {
+		if alert.Sequence == "BEL" && tracker.isFocused() {
+			continue
+		}
+		if time.Since(w.last) < alertInterval {
+			return
+		}
+		w.last = time.Now()
+
+		where := "A background tab"
+		if tabName != "" {
+			where = tabName
+		}
+		n := notify.Notification{
+			Kind:  notify.KindBell,
+			Title: alert.Title,
+			Body:  alert.Body,
+			TabID: tabID,
+			Data:  map[string]interface{}{"sequence": alert.Sequence},
+		}
+		switch {
+		case alert.Sequence == "BEL":
+			n.Title = where + " rang the bell"
+		case n.Title == "":
+			n.Title = "Notification from " + where
+			if tabName == "" {
+				n.Title = "Notification from a tab"
+			}
+		}
+		go func() {
+			if _, err := notify.Publish(n); err != nil {
+				log.Printf("[Terminal] Failed to add bell notification: %v", err)
+			}
+		}()
+		return
+	}
+}
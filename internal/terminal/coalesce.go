@@ -0,0 +1,70 @@
+package terminal
+
+import "time"
+
+const (
+	// coalesceWindow is how long PTY output is held so following reads can
+	// join it in one WebSocket frame
+	coalesceWindow = 10 * time.Millisecond
+
+	// interactiveOutput is the largest read sent at once when nothing is
+	// waiting, so keystroke echo and prompts are not delayed
+	interactiveOutput = 256
+
+	// maxFrame is the size at which held output is sent without waiting out
+	// the window
+	maxFrame = 64 * 1024
+)
+
+// coalesceOutput sends the PTY reads arriving on chunks to the browser,
+// merging reads that arrive within coalesceWindow of one another into one
+// frame. During fast output this turns thousands of small frames a second
+// into a few large ones. A small read with nothing held and nothing queued
+// behind it is sent straight away. Held output is flushed when chunks is
+// closed. It returns the first error from send.
+func coalesceOutput(chunks <-chan []byte, send func(frame []byte) error) error {
+	var pending []byte
+	var timer *time.Timer
+	var deadline <-chan time.Time
+
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, deadline = nil, nil
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		err := send(pending)
+		pending = pending[:0]
+		return err
+	}
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return flush()
+			}
+			if len(pending) == 0 && len(chunk) <= interactiveOutput && len(chunks) == 0 {
+				if err := send(chunk); err != nil {
+					return err
+				}
+				continue
+			}
+			pending = append(pending, chunk...)
+			if len(pending) >= maxFrame {
+				if err := flush(); err != nil {
+					return err
+				}
+			} else if timer == nil {
+				timer = time.NewTimer(coalesceWindow)
+				deadline = timer.C
+			}
+		case <-deadline:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
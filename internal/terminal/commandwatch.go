@@ -0,0 +1,227 @@
+package terminal
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
+)
+
+// DefaultLongCommandThreshold is how long a command must run before its
+// completion is announced, when none is configured
+const DefaultLongCommandThreshold = time.Minute
+
+// longCommandThreshold is the configured threshold; zero or less disables the alerts
+var longCommandThreshold atomic.Int64
+
+func init() {
+	longCommandThreshold.Store(int64(DefaultLongCommandThreshold))
+}
+
+// SetLongCommandThreshold sets how long a command must run before a
+// notification is raised when it finishes in a tab that isn't focused. Zero
+// or less turns the notifications off.
+func SetLongCommandThreshold(d time.Duration) {
+	longCommandThreshold.Store(int64(d))
+}
+
+// FocusMessage tells the server whether a tab is the one the user is looking
+// at (the tab is selected and the window is visible).
+type FocusMessage struct {
+	Type    string `json:"type"` // "TAB_FOCUS"
+	Focused bool   `json:"focused"`
+}
+
+// commandTracker follows the command running in a tab, detected from the
+// Enter key at a prompt and the prompt coming back.
+type commandTracker struct {
+	mu        sync.Mutex
+	command   string
+	startedAt time.Time // Zero when no command is running
+	focused   bool      // Unfocused until the client says otherwise
+}
+
+// submitted records a command line entered in the tab, reporting whether it
+// started a command. Input while a command is running goes to that command,
+// so it doesn't start another.
+func (t *commandTracker) submitted(commandLine string) bool {
+	commandLine = printable(commandLine)
+	if commandLine == "" {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.startedAt.IsZero() {
+		return false
+	}
+	t.command, t.startedAt = commandLine, time.Now()
+	return true
+}
+
+func (t *commandTracker) setFocused(focused bool) {
+	t.mu.Lock()
+	t.focused = focused
+	t.mu.Unlock()
+}
+
+func (t *commandTracker) isFocused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.focused
+}
+
+// finished reports a running command whose prompt has come back, and how long it ran
+func (t *commandTracker) finished(session *TerminalSession) (command string, took time.Duration, focused, ok bool) {
+	const quietPeriod = 300 * time.Millisecond
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.startedAt.IsZero() {
+		return "", 0, false, false
+	}
+	last := session.LastOutputAt()
+	if !last.After(t.startedAt) || time.Since(last) < quietPeriod || !session.AtPrompt() {
+		return "", 0, false, false
+	}
+	command, took = t.command, last.Sub(t.startedAt)
+	t.command, t.startedAt = "", time.Time{}
+	return command, took, t.focused, true
+}
+
+// watchCommands raises a notification when a command that ran longer than
+// the threshold finishes while the tab isn't focused, and records the
+// directory each command leaves the shell in, until done is closed.
+func watchCommands(session *TerminalSession, tracker *commandTracker, tabID, tabName string, done <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	lastDir := ""
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			command, took, focused, ok := tracker.finished(session)
+			if ok {
+				lastDir = recordDir(session, lastDir)
+			}
+			threshold := time.Duration(longCommandThreshold.Load())
+			if !ok || focused || threshold <= 0 || took < threshold {
+				continue
+			}
+			where := "in a background tab"
+			if tabName != "" {
+				where = "in " + tabName
+			}
+			_, err := notify.Publish(notify.Notification{
+				Kind:  notify.KindCommand,
+				Title: fmt.Sprintf("%s finished after %s", truncateCommand(command), formatDuration(took)),
+				Body:  fmt.Sprintf("The command ran %s and is done.", where),
+				TabID: tabID,
+				Data: map[string]interface{}{
+					"command":         command,
+					"durationSeconds": int(took.Seconds()),
+				},
+			})
+			if err != nil {
+				log.Printf("[Terminal] Failed to add command notification: %v", err)
+			}
+		}
+	}
+}
+
+// printable strips escape sequences and control characters (arrow keys,
+// backspaces) from raw keyboard input
+func printable(input string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, llm.CleanANSI(input)))
+}
+
+// typedLine rebuilds the command line from the keys typed at a prompt,
+// applying backspaces, Ctrl+C, and Ctrl+U. Lines recalled from the shell's
+// history, edited with arrow keys, or completed with Tab can't be known from
+// the keys alone, so ok is false for those and for multi-line pastes.
+func typedLine(keys string) (line string, ok bool) {
+	keys = strings.NewReplacer("\x1b[200~", "", "\x1b[201~", "").Replace(keys) // Bracketed paste
+	keys = strings.TrimRight(keys, "\r\n")
+	var runes []rune
+	for _, r := range keys {
+		switch {
+		case r == '\x7f' || r == '\b':
+			if len(runes) > 0 {
+				runes = runes[:len(runes)-1]
+			}
+		case r == '\x03' || r == '\x15':
+			runes = runes[:0]
+		case unicode.IsControl(r):
+			return "", false
+		default:
+			runes = append(runes, r)
+		}
+	}
+	return string(runes), true
+}
+
+// recordHistory adds a command line typed in a tab to the command history,
+// against the shell's directory when it is on this machine
+func recordHistory(session *TerminalSession, keys string) {
+	line, ok := typedLine(keys)
+	if !ok {
+		return
+	}
+	dir := ""
+	if localShell(session.Config) {
+		dir = session.CurrentDir()
+	}
+	if err := history.Record(line, dir); err != nil {
+		log.Printf("[Terminal] Failed to record command history: %v", err)
+	}
+}
+
+// recordDir counts a visit to the shell's directory if it changed from
+// lastDir, returning the directory
+func recordDir(session *TerminalSession, lastDir string) string {
+	if !localShell(session.Config) {
+		return lastDir
+	}
+	dir := session.CurrentDir()
+	if dir == lastDir {
+		return dir
+	}
+	if err := history.RecordDir(dir); err != nil {
+		log.Printf("[Terminal] Failed to record directory history: %v", err)
+	}
+	return dir
+}
+
+// truncateCommand shortens a command line for a notification title
+func truncateCommand(command string) string {
+	const maxLen = 60
+	if runes := []rune(command); len(runes) > maxLen {
+		return string(runes[:maxLen-1]) + "…"
+	}
+	return command
+}
+
+// formatDuration renders a duration the way people say it: "45s", "14m", "1h 5m"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,108 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultContainerShell is the shell started in a container when none is given
+const DefaultContainerShell = "sh"
+
+// Container is a running Docker container a tab can open a shell in.
+type Container struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	Status string `json:"status"` // e.g. "Up 2 hours"
+}
+
+// ListContainers returns the running containers, as reported by `docker ps`.
+func ListContainers(ctx context.Context) ([]Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "ps", "--no-trunc", "--format", "{{json .}}")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("docker ps failed: %s", msg)
+		}
+		return nil, fmt.Errorf("docker ps failed: %w", err)
+	}
+	return parseContainers(out)
+}
+
+// parseContainers reads `docker ps --format '{{json .}}'` output, one
+// container per line
+func parseContainers(out []byte) ([]Container, error) {
+	containers := []Container{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var ps struct {
+			ID     string `json:"ID"`
+			Names  string `json:"Names"`
+			Image  string `json:"Image"`
+			Status string `json:"Status"`
+		}
+		if err := json.Unmarshal(line, &ps); err != nil {
+			return nil, fmt.Errorf("unexpected docker ps output: %w", err)
+		}
+		id := ps.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		name, _, _ := strings.Cut(ps.Names, ",")
+		containers = append(containers, Container{ID: id, Name: name, Image: ps.Image, Status: ps.Status})
+	}
+	return containers, scanner.Err()
+}
+
+// dockerExecArgs are the arguments to `docker` that start an interactive
+// shell in the configured container, in WorkingDir (a path inside it) if set
+func dockerExecArgs(config *ShellConfig) []string {
+	args := []string{"exec", "-it", "-e", "TERM=xterm-256color", "-e", "COLORTERM=truecolor"}
+	if config.WorkingDir != "" {
+		args = append(args, "-w", config.WorkingDir)
+	}
+	shell := config.ContainerShell
+	if shell == "" {
+		shell = DefaultContainerShell
+	}
+	return append(args, config.Container, shell)
+}
+
+// HandleContainers lists the running Docker containers (GET).
+func HandleContainers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	containers, err := ListContainers(r.Context())
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"available":  false,
+			"error":      err.Error(),
+			"containers": []Container{},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available":  true,
+		"containers": containers,
+	})
+}
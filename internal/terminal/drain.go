@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// SessionSnapshot is the state of one tab's terminal captured before a restart.
+type SessionSnapshot struct {
+	TabID      string `json:"tabId"`
+	ShellType  string `json:"shellType,omitempty"`
+	WSLDistro  string `json:"wslDistro,omitempty"`
+	WorkingDir string `json:"workingDir,omitempty"`
+	Scrollback string `json:"scrollback"` // Tail of raw PTY output, replayable into xterm
+}
+
+// RestartState is what Drain saves so tabs can be restored after the server restarts.
+type RestartState struct {
+	SavedAt  time.Time         `json:"savedAt"`
+	Version  string            `json:"version"` // Version that was running when the state was saved
+	Sessions []SessionSnapshot `json:"sessions"`
+}
+
+// Draining reports whether Drain has been called.
+func (h *Handler) Draining() bool {
+	select {
+	case <-h.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Drain prepares for a restart: it snapshots every tab's scrollback, closes each
+// WebSocket with CloseCodeRestart, and waits (until ctx is done) for the
+// sessions to shut down. New connections are refused from then on.
+func (h *Handler) Drain(ctx context.Context) RestartState {
+	state := RestartState{SavedAt: time.Now(), Sessions: []SessionSnapshot{}}
+	for _, id := range h.SessionIDs() {
+		session, ok := h.Session(id)
+		if !ok {
+			continue
+		}
+		state.Sessions = append(state.Sessions, SessionSnapshot{
+			TabID:      id,
+			ShellType:  session.Config.ShellType,
+			WSLDistro:  session.Config.WSLDistro,
+			WorkingDir: session.Config.WorkingDir,
+			Scrollback: session.RecentOutput(),
+		})
+	}
+
+	h.drainOnce.Do(func() { close(h.draining) })
+
+	// Background sessions have no WebSocket loop to close them
+	h.sessions.Range(func(key, value interface{}) bool {
+		if IsBackgroundSession(key.(string)) {
+			value.(*TerminalSession).Close()
+		}
+		return true
+	})
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for len(h.SessionIDs()) > 0 {
+		select {
+		case <-ctx.Done():
+			return state
+		case <-ticker.C:
+		}
+	}
+	return state
+}
+
+// Shutdown drains like a restart, but tells clients the server is going away
+// (CloseCodeShutdown) so they don't wait for it to come back.
+func (h *Handler) Shutdown(ctx context.Context) RestartState {
+	h.shuttingDown.Store(true)
+	return h.Drain(ctx)
+}
+
+// drainCloseReason returns the WebSocket close code and reason for drained sessions.
+func (h *Handler) drainCloseReason() (int, string) {
+	if h.shuttingDown.Load() {
+		return CloseCodeShutdown, "Server shutting down"
+	}
+	return CloseCodeRestart, "Server restarting"
+}
+
+// SaveRestartState writes the snapshot taken by Drain.
+func SaveRestartState(state RestartState) error {
+	path := storage.GetRestartStatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// LoadRestartState returns the snapshot saved before the last restart, or nil if there is none.
+func LoadRestartState() (*RestartState, error) {
+	data, err := storage.ReadJSONFile(storage.GetRestartStatePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state RestartState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,38 @@
+package terminal
+
+import (
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/am"
+)
+
+// environmentInterval is how often the project's state is recorded during a
+// conversation
+const environmentInterval = time.Minute
+
+// watchEnvironment records the project's state (git, virtualenv, Node,
+// Docker Compose) in the tab's AM conversations: as each starts and then
+// every environmentInterval, until done is closed. Shells on another
+// machine are skipped, since their projects can't be inspected from here.
+func watchEnvironment(session *TerminalSession, logger *am.LLMLogger, done <-chan struct{}) {
+	if !localShell(session.Config) {
+		return
+	}
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	lastConv, lastAt := "", time.Time{}
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			convID := logger.GetActiveConversationID()
+			if convID == "" || (convID == lastConv && time.Since(lastAt) < environmentInterval) {
+				continue
+			}
+			logger.RecordEnvironment(session.CurrentDir())
+			lastConv, lastAt = convID, time.Now()
+		}
+	}
+}
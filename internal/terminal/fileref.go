@@ -0,0 +1,234 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/editor"
+	"github.com/mikejsmith1985/forge-terminal/internal/files"
+)
+
+// fileRefPatterns match file:line references in compiler errors and stack
+// traces. Each has the path, line, and (optional) column as groups 1-3.
+var fileRefPatterns = []*regexp.Regexp{
+	// Python: File "app/main.py", line 12
+	regexp.MustCompile(`File "([^"]+)", line (\d+)()`),
+	// TypeScript and MSBuild: src/app.ts(10,5)
+	regexp.MustCompile(`((?:[A-Za-z]:)?[\w.~/\\-]*[\w-]\.[A-Za-z0-9]{1,8})\((\d+),(\d+)\)`),
+	// Most others: main.go:12:5, ./src/app.js:10, /home/me/x.rs:3:1, C:\src\x.cs:12
+	regexp.MustCompile(`((?:[A-Za-z]:)?[\w.~/\\-]*[\w-]\.[A-Za-z0-9]{1,8}):(\d+)(?::(\d+))?`),
+}
+
+// Limits on file references, per chunk of output scanned and kept per tab
+const (
+	maxFileRefsPerScan = 20
+	maxFileRefs        = 50
+)
+
+// FileRef is a file:line reference a tab printed, resolved to a file that exists.
+type FileRef struct {
+	Text string `json:"text"` // As printed, e.g. "main.go:12:5"
+	editor.Location
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// findFileRefs returns the file references in lines, unresolved
+func findFileRefs(lines string) []FileRef {
+	var refs []FileRef
+	seen := map[string]bool{}
+	for _, pattern := range fileRefPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(lines, -1) {
+			if len(refs) >= maxFileRefsPerScan {
+				return refs
+			}
+			if seen[match[0]] {
+				continue
+			}
+			seen[match[0]] = true
+			line, _ := strconv.Atoi(match[2])
+			column, _ := strconv.Atoi(match[3])
+			refs = append(refs, FileRef{Text: match[0], Location: editor.Location{Path: match[1], Line: line, Column: column}})
+		}
+	}
+	return refs
+}
+
+// resolveFile makes a printed path absolute against dir, returning false if
+// no such file exists
+func resolveFile(path, dir string) (string, bool) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		if dir == "" {
+			return "", false
+		}
+		path = filepath.Join(dir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return filepath.Clean(path), true
+}
+
+// localShell reports whether a session's shell sees this machine's files
+func localShell(config ShellConfig) bool {
+	switch config.ShellType {
+	case "docker", "ssh":
+		return false
+	case "wsl", "tmux":
+		return runtime.GOOS != "windows"
+	}
+	return true
+}
+
+// CurrentDir returns the shell's working directory: the live one where the
+// platform exposes it, otherwise the one it started in.
+func (s *TerminalSession) CurrentDir() string {
+	if runtime.GOOS == "linux" && s.Cmd != nil && s.Cmd.Process != nil && s.Config.ShellType != "tmux" {
+		if dir, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", s.Cmd.Process.Pid)); err == nil {
+			return dir
+		}
+	}
+	if s.Config.WorkingDir != "" {
+		return s.Config.WorkingDir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// addFileRefs resolves references against the shell's directory and records
+// those that name existing files, newest last
+func (s *TerminalSession) addFileRefs(refs []FileRef) {
+	if len(refs) == 0 || !localShell(s.Config) {
+		return
+	}
+	dir := s.CurrentDir()
+	now := time.Now()
+
+	s.detectedMu.Lock()
+	defer s.detectedMu.Unlock()
+	for _, ref := range refs {
+		path, ok := resolveFile(ref.Path, dir)
+		if !ok {
+			continue
+		}
+		ref.Path, ref.DetectedAt = path, now
+		for i, existing := range s.fileRefs {
+			if existing.Location == ref.Location {
+				s.fileRefs = append(s.fileRefs[:i], s.fileRefs[i+1:]...)
+				break
+			}
+		}
+		s.fileRefs = append(s.fileRefs, ref)
+	}
+	if len(s.fileRefs) > maxFileRefs {
+		s.fileRefs = append([]FileRef(nil), s.fileRefs[len(s.fileRefs)-maxFileRefs:]...)
+	}
+}
+
+// FileRefs returns the file references the tab has printed, oldest first.
+func (s *TerminalSession) FileRefs() []FileRef {
+	s.detectedMu.Lock()
+	defer s.detectedMu.Unlock()
+	return append([]FileRef{}, s.fileRefs...)
+}
+
+// HandleFileRefs lists the file:line references a tab has printed (GET ?tabId=).
+func (h *Handler) HandleFileRefs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session, ok := h.Session(r.URL.Query().Get("tabId"))
+	if !ok {
+		http.Error(w, "No open tab with that tabId", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"refs": session.FileRefs(),
+		"cwd":  session.CurrentDir(),
+	})
+}
+
+// OpenFileRequest is the body for POST /api/terminal/open-file
+type OpenFileRequest struct {
+	TabID  string `json:"tabId"`
+	Path   string `json:"path"` // As printed; relative paths resolve against the tab's directory
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Editor string `json:"editor,omitempty"` // "builtin" (default) or "external"
+}
+
+// HandleOpenFile resolves a file reference clicked in a tab's output and opens
+// it (POST). For the built-in editor the resolved location is returned for
+// the page to open; "external" opens it in the configured editor.
+func (h *Handler) HandleOpenFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req OpenFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Editor == "" {
+		req.Editor = "builtin"
+	}
+	if req.Editor != "builtin" && req.Editor != "external" {
+		http.Error(w, `editor must be "builtin" or "external"`, http.StatusBadRequest)
+		return
+	}
+	if req.Editor == "external" && externalEditorOff.Load() {
+		http.Error(w, "Opening files in an external editor is turned off (terminal.inject)", http.StatusForbidden)
+		return
+	}
+
+	dir := ""
+	if session, ok := h.Session(req.TabID); ok {
+		if !localShell(session.Config) {
+			http.Error(w, "Files in this tab are on another machine", http.StatusBadRequest)
+			return
+		}
+		dir = session.CurrentDir()
+	}
+	path, ok := resolveFile(req.Path, dir)
+	if !ok {
+		http.Error(w, "File not found: "+req.Path, http.StatusNotFound)
+		return
+	}
+	if !files.PathAllowed(path) {
+		http.Error(w, "Path is outside the allowed roots", http.StatusForbidden)
+		return
+	}
+
+	loc := editor.Location{Path: path, Line: req.Line, Column: req.Column}
+	response := map[string]interface{}{
+		"success":  true,
+		"editor":   req.Editor,
+		"location": loc,
+	}
+	if req.Editor == "external" {
+		result, err := editor.Open(loc, "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -2,18 +2,27 @@
 package terminal
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/mikejsmith1985/forge-terminal/internal/am"
 	"github.com/mikejsmith1985/forge-terminal/internal/assistant"
+	"github.com/mikejsmith1985/forge-terminal/internal/audit"
+	"github.com/mikejsmith1985/forge-terminal/internal/commands"
+	"github.com/mikejsmith1985/forge-terminal/internal/forwards"
+	"github.com/mikejsmith1985/forge-terminal/internal/guard"
+	"github.com/mikejsmith1985/forge-terminal/internal/notify"
 	"github.com/mikejsmith1985/forge-terminal/internal/terminal/vision"
 )
 
@@ -22,14 +31,72 @@ const (
 	CloseCodePTYExited = 4000 // Shell process exited normally
 	CloseCodeTimeout   = 4001 // Session timed out
 	CloseCodePTYError  = 4002 // PTY read/write error
+	CloseCodeRestart   = 4003 // Server is restarting (e.g. to apply an update)
+	CloseCodeShutdown  = 4004 // Server is shutting down
+	CloseCodeTabLimit  = 4005 // Too many terminals open; a LimitError message precedes it
 )
 
 // Handler manages WebSocket terminal connections.
 type Handler struct {
 	upgrader      websocket.Upgrader
 	sessions      sync.Map // map[string]*TerminalSession
+	backgroundMu  sync.Mutex
+	draining      chan struct{} // Closed by Drain
+	drainOnce     sync.Once
+	shuttingDown  atomic.Bool // Set by Shutdown; the drain is final rather than a restart
 	assistantCore *assistant.Core
 	assistant     assistant.Service
+	defaultDir    string   // Starting directory for tabs that don't request one
+	pendingTabs   sync.Map // map[string]pendingTab: tabs reserved by OpenTab
+	counter       sessionCounter
+	focusMu       sync.Mutex
+	focusedTab    string // The tab the user is looking at, from TAB_FOCUS messages
+}
+
+// SetDefaultWorkingDir makes new tabs start in dir unless they pass a cwd.
+// Call before serving.
+func (h *Handler) SetDefaultWorkingDir(dir string) {
+	h.defaultDir = dir
+}
+
+// pendingTabTimeout is how long a tab reserved by OpenTab waits for its page
+const pendingTabTimeout = 5 * time.Minute
+
+type pendingTab struct {
+	dir     string
+	command string // Run once the shell is ready, if set
+	expires time.Time
+}
+
+// OpenTab reserves a tab whose shell starts in dir and returns its ID. The
+// page that connects with that tab ID gets a shell there without passing
+// cwd. Reservations not claimed within pendingTabTimeout are dropped.
+func (h *Handler) OpenTab(dir string) string {
+	return h.OpenTabRunning(dir, "")
+}
+
+// OpenTabRunning reserves a tab like OpenTab that also runs command once its
+// shell shows a prompt.
+func (h *Handler) OpenTabRunning(dir, command string) string {
+	now := time.Now()
+	h.pendingTabs.Range(func(key, value interface{}) bool {
+		if now.After(value.(pendingTab).expires) {
+			h.pendingTabs.Delete(key)
+		}
+		return true
+	})
+	tabID := uuid.New().String()
+	h.pendingTabs.Store(tabID, pendingTab{dir: dir, command: command, expires: now.Add(pendingTabTimeout)})
+	return tabID
+}
+
+// claimTab returns the reservation made for tabID by OpenTab, if any.
+func (h *Handler) claimTab(tabID string) (pendingTab, bool) {
+	value, ok := h.pendingTabs.LoadAndDelete(tabID)
+	if !ok || time.Now().After(value.(pendingTab).expires) {
+		return pendingTab{}, false
+	}
+	return value.(pendingTab), true
 }
 
 // ResizeMessage represents a terminal resize request from the client.
@@ -58,6 +125,35 @@ type AMControlMessage struct {
 	AutoRespond bool   `json:"autoRespond"`
 }
 
+// visionNotificationTitles are the Vision matches worth a notification, so
+// an error or a prompt waiting for an answer in a background tab isn't missed
+var visionNotificationTitles = map[string]string{
+	"COMPILER_ERROR":     "Compiler error detected",
+	"STACK_TRACE":        "Stack trace detected",
+	"INTERACTIVE_PROMPT": "Waiting for input",
+}
+
+// publishVisionNotification adds what Vision spotted to the notification
+// center. Each tab keeps only its latest one.
+func publishVisionNotification(tabID string, match *vision.Match) {
+	title, ok := visionNotificationTitles[match.Type]
+	if !ok {
+		return
+	}
+	message, _ := match.Payload["message"].(string)
+	_, err := notify.Publish(notify.Notification{
+		Kind:  notify.KindVision,
+		Key:   notify.KindVision + ":" + tabID,
+		Title: title,
+		Body:  message,
+		TabID: tabID,
+		Data:  map[string]interface{}{"overlayType": match.Type},
+	})
+	if err != nil {
+		log.Printf("[Vision] Failed to add notification: %v", err)
+	}
+}
+
 // NewHandler creates a new terminal WebSocket handler.
 func NewHandler(service assistant.Service, core *assistant.Core) *Handler {
 	return &Handler{
@@ -87,11 +183,167 @@ func NewHandler(service assistant.Service, core *assistant.Core) *Handler {
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
+		draining:      make(chan struct{}),
 		assistantCore: core,
 		assistant:     service,
 	}
 }
 
+// SetOriginCheck replaces the check of which pages may open terminal
+// WebSockets; by default any origin may. Call it before serving.
+func (h *Handler) SetOriginCheck(check func(r *http.Request) bool) {
+	h.upgrader.CheckOrigin = check
+}
+
+// visionInjectOff refuses commands the page injects from Vision suggestions
+var visionInjectOff atomic.Bool
+
+// SetVisionInject allows or refuses running Vision's suggested commands
+// (the terminal.inject capability).
+func SetVisionInject(enabled bool) {
+	visionInjectOff.Store(!enabled)
+}
+
+// externalEditorOff refuses opening clicked file references in an external editor
+var externalEditorOff atomic.Bool
+
+// SetExternalEditor allows or refuses launching the external editor from
+// HandleOpenFile (the terminal.inject capability, as for /open-in-editor).
+func SetExternalEditor(enabled bool) {
+	externalEditorOff.Store(!enabled)
+}
+
+// InjectRequest is the body for POST /api/terminal/inject
+type InjectRequest struct {
+	TabID   string `json:"tabId"`
+	Input   string `json:"input"`
+	Submit  bool   `json:"submit"`            // Append a carriage return to run the input
+	Confirm bool   `json:"confirm,omitempty"` // Run input the dangerous-command guard asked to confirm
+
+	CommandID int `json:"commandId,omitempty"` // Card the input came from, for usage tracking
+}
+
+// Session returns the live terminal session for a tab.
+func (h *Handler) Session(tabID string) (*TerminalSession, bool) {
+	value, ok := h.sessions.Load(tabID)
+	if !ok {
+		return nil, false
+	}
+	return value.(*TerminalSession), true
+}
+
+// SessionIDs returns the tab IDs of all live browser-tab sessions, sorted.
+// Background sessions started by RunTarget are not included.
+func (h *Handler) SessionIDs() []string {
+	ids := []string{}
+	h.sessions.Range(func(key, _ interface{}) bool {
+		if !IsBackgroundSession(key.(string)) {
+			ids = append(ids, key.(string))
+		}
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// LastOutputAt returns when any terminal, including background sessions,
+// last produced output (zero if none has).
+func (h *Handler) LastOutputAt() time.Time {
+	var last time.Time
+	h.sessions.Range(func(_, value interface{}) bool {
+		if at := value.(*TerminalSession).LastOutputAt(); at.After(last) {
+			last = at
+		}
+		return true
+	})
+	return last
+}
+
+// BusySessionIDs returns the tabs whose shell is not at a prompt, i.e. still running a command.
+func (h *Handler) BusySessionIDs() []string {
+	busy := []string{}
+	for _, id := range h.SessionIDs() {
+		if session, ok := h.Session(id); ok && !session.AtPrompt() {
+			busy = append(busy, id)
+		}
+	}
+	return busy
+}
+
+// InjectInput writes input to a tab's PTY as if typed, optionally submitting it.
+func (h *Handler) InjectInput(tabID, input string, submit bool) error {
+	target, err := h.Target(tabID)
+	if err != nil {
+		return err
+	}
+	return target.InjectInput(input, submit)
+}
+
+// TabTarget drives a single tab's session programmatically, e.g. for command chains.
+type TabTarget struct {
+	session *TerminalSession
+}
+
+// Target returns a TabTarget for a live tab.
+func (h *Handler) Target(tabID string) (*TabTarget, error) {
+	session, ok := h.Session(tabID)
+	if !ok {
+		return nil, fmt.Errorf("no active session for tab %s", tabID)
+	}
+	return &TabTarget{session: session}, nil
+}
+
+// InjectInput writes input to the tab, optionally submitting it.
+func (t *TabTarget) InjectInput(input string, submit bool) error {
+	if submit {
+		input += "\r"
+	}
+	_, err := t.session.Write([]byte(input))
+	return err
+}
+
+// WaitForPrompt waits until the tab's shell shows a prompt after since.
+func (t *TabTarget) WaitForPrompt(ctx context.Context, since time.Time) error {
+	return t.session.WaitForPrompt(ctx, since)
+}
+
+// HandleInject injects input into a tab's terminal (POST) or lists injectable tabs (GET)
+func (h *Handler) HandleInject(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tabs": h.SessionIDs(),
+		})
+
+	case http.MethodPost:
+		var req InjectRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if decision := guard.Check(req.Input); !decision.Allowed(req.Confirm) {
+			guard.WriteDecision(w, decision)
+			return
+		}
+		if err := h.InjectInput(req.TabID, req.Input, req.Submit); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("[Terminal] Injected %d bytes into tab %s (submit=%v)", len(req.Input), req.TabID, req.Submit)
+		if err := commands.RecordUsage(req.CommandID); err != nil {
+			log.Printf("[Terminal] Failed to record usage for command %d: %v", req.CommandID, err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // HandleWebSocket upgrades the HTTP connection to WebSocket and manages PTY I/O.
 func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Upgrade to WebSocket
@@ -102,12 +354,25 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
+	// Refuse new terminals once a restart has begun; the client reconnects afterwards
+	if h.Draining() {
+		code, reason := h.drainCloseReason()
+		closeMessage := websocket.FormatCloseMessage(code, reason)
+		_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		return
+	}
+
 	// Parse shell config from query params
 	query := r.URL.Query()
 	shellConfig := &ShellConfig{
-		ShellType:   query.Get("shell"),
-		WSLDistro:   query.Get("distro"),
-		WSLHomePath: query.Get("home"),
+		ShellType:      query.Get("shell"),
+		WSLDistro:      query.Get("distro"),
+		WSLHomePath:    query.Get("home"),
+		WorkingDir:     query.Get("cwd"),
+		Container:      query.Get("container"),
+		ContainerShell: query.Get("containerShell"),
+		TmuxSession:    query.Get("tmuxSession"),
+		SSHHost:        query.Get("sshHost"),
 	}
 
 	// Get tabID from query params (for AM/LLM logging)
@@ -118,6 +383,29 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[Terminal] Warning: No tabID provided, using session ID: %s", tabID)
 	}
 
+	// A tab opened for a workspace (OpenTab) starts there, others in the default
+	// directory. Host directories mean nothing inside a container or on a
+	// remote host, and a tmux session keeps its own.
+	pending, reserved := h.claimTab(tabID)
+	if shellConfig.WorkingDir == "" && shellConfig.ShellType != "docker" && shellConfig.ShellType != "tmux" && shellConfig.ShellType != "ssh" {
+		if reserved {
+			shellConfig.WorkingDir = pending.dir
+		} else {
+			shellConfig.WorkingDir = h.defaultDir
+		}
+	}
+
+	// Enforce the tab limits before starting a shell
+	release, limitErr := h.counter.acquire(clientAddress(r))
+	if limitErr != nil {
+		log.Printf("[Terminal] Refused tab %s: %s", tabID, limitErr.Error)
+		_ = conn.WriteJSON(limitErr)
+		closeMessage := websocket.FormatCloseMessage(CloseCodeTabLimit, "Too many terminals open")
+		_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		return
+	}
+	defer release()
+
 	// Create terminal session with config
 	sessionID := tabID // Use tabID as session ID for consistency
 	session, err := NewTerminalSessionWithConfig(sessionID, shellConfig)
@@ -129,9 +417,19 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		session.Close()
 		h.sessions.Delete(sessionID)
+		webSocketSessions.Add(-1)
+		forwards.ForgetTab(tabID)
+		h.setFocused(tabID, false)
 	}()
 
 	h.sessions.Store(sessionID, session)
+	webSocketSessions.Add(1)
+
+	// ssh tabs log in with a stored password or key passphrase, if any
+	if name := sshSecretName(shellConfig, query.Get("sshSecret")); name != "" {
+		go answerSSHPrompt(session, name)
+	}
+
 	log.Printf("[Terminal] Session %s created (shell: %s, tabID: %s)", sessionID, shellConfig.ShellType, tabID)
 
 	// Set initial terminal size (default 80x24)
@@ -150,6 +448,9 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go func() {
 		if amSystem != nil {
 			llmLogger = amSystem.GetLLMLogger(tabID)
+			if llmLogger != nil && shellConfig.WorkingDir != "" {
+				llmLogger.SetWorkingDirectory(shellConfig.WorkingDir)
+			}
 			if llmLogger != nil {
 				activeConv := llmLogger.GetActiveConversationID()
 				log.Printf("[Terminal] Using LLM logger for tabID: %s, activeConv: %s", tabID, activeConv)
@@ -162,7 +463,10 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// Initialize Vision Insights tracker
-			cwd, _ := os.Getwd()
+			cwd := shellConfig.WorkingDir
+			if cwd == "" {
+				cwd, _ = os.Getwd()
+			}
 			sessionInfo := vision.SessionInfo{
 				TabID:      tabID,
 				WorkingDir: cwd,
@@ -212,6 +516,15 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	done := make(chan struct{})
 	var closeOnce sync.Once
 
+	// Announce long-running commands that finish while the user is elsewhere
+	tracker := &commandTracker{}
+	go watchCommands(session, tracker, tabID, query.Get("tabName"), done)
+
+	// Record the project's state in AM conversations, for recovery
+	if llmLogger != nil {
+		go watchEnvironment(session, llmLogger, done)
+	}
+
 	// Layer 1: PTY Heartbeat - Send periodic heartbeats for health monitoring
 	go func() {
 		ticker := time.NewTicker(15 * time.Second)
@@ -229,9 +542,27 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// PTY -> WebSocket (read from terminal, send to browser)
+	// Servers and file references the tab prints are recorded, and remote
+	// servers offered for forwarding
+	lines := &lineScanner{}
+	// Bells and notifications the tab's programs send are forwarded
+	alerts := &alertWatcher{}
+
+	// PTY -> WebSocket (read from terminal, send to browser). Reads are
+	// coalesced into fewer, larger frames (see coalesceOutput).
+	chunks := make(chan []byte, 64)
+	go func() {
+		err := coalesceOutput(chunks, func(frame []byte) error {
+			return conn.WriteMessage(websocket.BinaryMessage, frame)
+		})
+		if err != nil {
+			log.Printf("[Terminal] WebSocket write error: %v", err)
+			closeOnce.Do(func() { close(done) })
+		}
+	}()
 	go func() {
 		defer closeOnce.Do(func() { close(done) })
+		defer close(chunks)
 		buf := make([]byte, 4096)
 		for {
 			n, err := session.Read(buf)
@@ -244,11 +575,13 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			if n > 0 {
+				data := append([]byte(nil), buf[:n]...)
+
 				// ═══ CRITICAL PERFORMANCE: Send to browser FIRST ═══
 				// This ensures terminal output is immediately visible
-				err = conn.WriteMessage(websocket.BinaryMessage, buf[:n])
-				if err != nil {
-					log.Printf("[Terminal] WebSocket write error: %v", err)
+				select {
+				case chunks <- data:
+				case <-done:
 					return
 				}
 
@@ -262,8 +595,14 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 								Payload:     match.Payload,
 							}
 							conn.WriteJSON(overlayMsg) // Best effort, ignore errors
+							publishVisionNotification(tabID, match)
+							if match.Type == "INTERACTIVE_PROMPT" && llmLogger != nil {
+								kind, _ := match.Payload["kind"].(string)
+								prompt, _ := match.Payload["prompt"].(string)
+								llmLogger.MarkWaitingForInput(kind, prompt)
+							}
 						}
-					}(buf[:n])
+					}(data)
 				}
 
 				// Feed output to LLM logger asynchronously (non-blocking)
@@ -272,12 +611,27 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 						if llmLogger.GetActiveConversationID() != "" {
 							llmLogger.AddOutput(data)
 						}
-					}(string(buf[:n]))
+					}(string(data))
 				}
+
+				watchOutput(session, lines, tabID, data)
+				watchAlerts(alerts, tracker, tabID, query.Get("tabName"), data)
 			}
 		}
 	}()
 
+	// A tab opened to run a command (OpenTabRunning) runs it at the first prompt
+	if reserved && pending.command != "" {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = session.WaitForPrompt(ctx, time.Time{})
+			if _, err := session.Write([]byte(pending.command + "\r")); err != nil {
+				log.Printf("[Terminal] Failed to run %q in tab %s: %v", pending.command, tabID, err)
+			}
+		}()
+	}
+
 	// WebSocket -> PTY (read from browser, send to terminal)
 	go func() {
 		defer closeOnce.Do(func() { close(done) })
@@ -300,6 +654,13 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 					continue
 				}
 
+				var focusMsg FocusMessage
+				if err := json.Unmarshal(data, &focusMsg); err == nil && focusMsg.Type == "TAB_FOCUS" {
+					tracker.setFocused(focusMsg.Focused)
+					h.setFocused(tabID, focusMsg.Focused)
+					continue
+				}
+
 				// Check for Vision control messages
 				var visionMsg VisionControlMessage
 				if err := json.Unmarshal(data, &visionMsg); err == nil {
@@ -313,7 +674,12 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 						log.Printf("[Vision] Disabled for session %s", sessionID)
 					case "INJECT_COMMAND":
 						// Execute command in PTY (like git add <file>)
-						if visionMsg.Command != "" {
+						if visionMsg.Command != "" && visionInjectOff.Load() {
+							log.Printf("[Vision] Refused to inject a command: terminal.inject is turned off")
+						} else if decision := guard.Check(visionMsg.Command); visionMsg.Command != "" && !decision.Allowed(false) {
+							// Vision messages carry no confirmation, so confirm rules block too
+							log.Printf("[Vision] Refused to inject %q: %s (%s)", decision.Command, decision.Reason, decision.Rule)
+						} else if visionMsg.Command != "" {
 							log.Printf("[Vision] Injecting command: %s", visionMsg.Command)
 							if _, err := session.Write([]byte(visionMsg.Command + "\r")); err != nil {
 								log.Printf("[Vision] Command injection error: %v", err)
@@ -327,8 +693,16 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 				var amMsg AMControlMessage
 				if err := json.Unmarshal(data, &amMsg); err == nil && amMsg.Type == "AM_AUTO_RESPOND" {
 					if llmLogger != nil {
+						changed := llmLogger.IsAutoRespond() != amMsg.AutoRespond
 						llmLogger.SetAutoRespond(amMsg.AutoRespond)
 						log.Printf("[AM] Auto-respond set to %v for session %s", amMsg.AutoRespond, sessionID)
+						if changed {
+							detail := "disabled"
+							if amMsg.AutoRespond {
+								detail = "enabled"
+							}
+							audit.Record(audit.Event{Action: audit.AutoRespond, Target: llmLogger.GetActiveConversationID(), Detail: detail, TabID: tabID})
+						}
 					}
 					continue
 				}
@@ -360,13 +734,20 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 			// Check for newline/enter (command submission)
 			if strings.Contains(dataStr, "\r") || strings.Contains(dataStr, "\n") {
-				commandLine := strings.TrimSpace(inputBuffer.String())
+				keys := inputBuffer.String()
+				commandLine := strings.TrimSpace(keys)
 				inputBuffer.Reset()
+				if tracker.submitted(commandLine) && !IsBackgroundSession(tabID) {
+					go recordHistory(session, keys)
+				}
 
 				if commandLine != "" && llmLogger != nil {
 					// Only detect new LLM command if no conversation is active
 					activeConv := llmLogger.GetActiveConversationID()
 					if activeConv == "" {
+						if localShell(session.Config) {
+							llmLogger.SetWorkingDirectory(session.CurrentDir())
+						}
 						detected := detector.DetectCommand(commandLine)
 
 						if detected.Detected {
@@ -410,6 +791,10 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	case <-session.Done():
 		log.Printf("[Terminal] Session %s: Process exited", sessionID)
 		finalReason = closeReason{CloseCodePTYExited, "Shell process exited"}
+	case <-h.draining:
+		code, reason := h.drainCloseReason()
+		log.Printf("[Terminal] Session %s: %s", sessionID, reason)
+		finalReason = closeReason{code, reason}
 	case <-time.After(24 * time.Hour):
 		log.Printf("[Terminal] Session %s: Timeout (24h)", sessionID)
 		finalReason = closeReason{CloseCodeTimeout, "Session timed out after 24 hours"}
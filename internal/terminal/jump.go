@@ -0,0 +1,157 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/history"
+)
+
+// setFocused records whether tabID is the tab the user is looking at
+func (h *Handler) setFocused(tabID string, focused bool) {
+	h.focusMu.Lock()
+	defer h.focusMu.Unlock()
+	if focused {
+		h.focusedTab = tabID
+	} else if h.focusedTab == tabID {
+		h.focusedTab = ""
+	}
+}
+
+// FocusedTab returns the tab the user is looking at, or "" if no page says
+// one is focused.
+func (h *Handler) FocusedTab() string {
+	h.focusMu.Lock()
+	defer h.focusMu.Unlock()
+	return h.focusedTab
+}
+
+// cdCommand is the command that changes a shell's directory to dir
+func cdCommand(config ShellConfig, dir string) string {
+	if runtime.GOOS == "windows" {
+		switch config.ShellType {
+		case "powershell":
+			return "Set-Location -LiteralPath '" + strings.ReplaceAll(dir, "'", "''") + "'"
+		case "", "cmd":
+			return `cd /d "` + dir + `"`
+		}
+	}
+	return "cd '" + strings.ReplaceAll(dir, "'", `'\''`) + "'"
+}
+
+// JumpRequest is the body for POST /api/jump
+type JumpRequest struct {
+	Query string `json:"query"`           // Words from the path, in order
+	TabID string `json:"tabId,omitempty"` // The tab to cd (empty = the focused tab)
+	Path  string `json:"path,omitempty"`  // Jump here instead of the best match for query
+}
+
+// jumpQuery builds the directory search for a tab: matches in the tab's
+// workspace, not counting the directory it is in
+func jumpQuery(text string, session *TerminalSession) history.JumpQuery {
+	q := history.JumpQuery{Text: text}
+	if session != nil && localShell(session.Config) {
+		q.Exclude = session.CurrentDir()
+		q.Workspace = history.WorkspaceFor(q.Exclude)
+	}
+	return q
+}
+
+// bestDir returns the best match for q, widening the search beyond the
+// tab's workspace when nothing in it matches
+func bestDir(q history.JumpQuery) ([]history.Dir, error) {
+	dirs, err := history.Jump(q)
+	if err == nil && len(dirs) == 0 && q.Workspace != "" {
+		q.Workspace = ""
+		dirs, err = history.Jump(q)
+	}
+	return dirs, err
+}
+
+// HandleJump finds visited directories (GET ?query=&tabId=&limit=) or changes
+// a tab to the best match (POST JumpRequest), zoxide-style. Directories in
+// the tab's workspace come first, falling back to every workspace.
+func (h *Handler) HandleJump(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		session, _ := h.Session(query.Get("tabId"))
+		q := jumpQuery(query.Get("query"), session)
+		if limit := query.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n < 0 {
+				http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+				return
+			}
+			q.Limit = n
+		}
+		dirs, err := bestDir(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response := map[string]interface{}{"matches": dirs}
+		if len(dirs) > 0 {
+			response["match"] = dirs[0]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+
+	case http.MethodPost:
+		var req JumpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.TabID == "" {
+			req.TabID = h.FocusedTab()
+		}
+		session, ok := h.Session(req.TabID)
+		if !ok {
+			http.Error(w, "No open tab to jump in (give tabId, or focus a tab)", http.StatusNotFound)
+			return
+		}
+		if !localShell(session.Config) {
+			http.Error(w, "The tab's shell is on another machine", http.StatusBadRequest)
+			return
+		}
+
+		dir := req.Path
+		if dir == "" {
+			dirs, err := bestDir(jumpQuery(req.Query, session))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if len(dirs) == 0 {
+				http.Error(w, fmt.Sprintf("No visited directory matches %q", req.Query), http.StatusNotFound)
+				return
+			}
+			dir = dirs[0].Path
+		} else if info, err := os.Stat(dir); err != nil || !info.IsDir() || !filepath.IsAbs(dir) {
+			http.Error(w, "path must be an existing absolute directory", http.StatusBadRequest)
+			return
+		}
+		if err := h.InjectInput(req.TabID, cdCommand(session.Config, dir), true); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[Terminal] Jumped tab %s to %s", req.TabID, dir)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"tabId":   req.TabID,
+			"path":    dir,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
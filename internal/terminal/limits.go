@@ -0,0 +1,154 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Default terminal limits, used when none are configured
+const (
+	DefaultMaxTabsPerClient = 20
+	DefaultMaxTabs          = 100
+)
+
+// Limits caps how many terminals may be open at once.
+type Limits struct {
+	PerClient int `json:"perClient"` // Per client address
+	Total     int `json:"total"`     // Across every client
+}
+
+var (
+	limitsMu sync.Mutex
+	limits   = Limits{PerClient: DefaultMaxTabsPerClient, Total: DefaultMaxTabs}
+)
+
+// SetLimits sets the terminal limits for new connections; zero fields use
+// the defaults. Terminals already open are not closed.
+func SetLimits(l Limits) {
+	if l.PerClient <= 0 {
+		l.PerClient = DefaultMaxTabsPerClient
+	}
+	if l.Total <= 0 {
+		l.Total = DefaultMaxTabs
+	}
+	limitsMu.Lock()
+	limits = l
+	limitsMu.Unlock()
+}
+
+// CurrentLimits returns the terminal limits in effect.
+func CurrentLimits() Limits {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	return limits
+}
+
+// LimitError is sent on /ws, before closing with CloseCodeTabLimit, when
+// opening the terminal would exceed a limit.
+type LimitError struct {
+	Type  string `json:"type"` // "TAB_LIMIT"
+	Error string `json:"error"`
+	Scope string `json:"scope"` // "client" or "server"
+	Limit int    `json:"limit"`
+	Open  int    `json:"open"`
+}
+
+// sessionCounter counts open terminals per client address.
+type sessionCounter struct {
+	mu      sync.Mutex
+	total   int
+	clients map[string]int
+}
+
+// acquire reserves a terminal for client, returning the function that
+// releases it, or the limit that was reached.
+func (c *sessionCounter) acquire(client string) (func(), *LimitError) {
+	l := CurrentLimits()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if open := c.clients[client]; open >= l.PerClient {
+		return nil, &LimitError{
+			Type:  "TAB_LIMIT",
+			Error: fmt.Sprintf("Tab limit reached: %d of %d terminals are open from this client. Close one to open another.", open, l.PerClient),
+			Scope: "client",
+			Limit: l.PerClient,
+			Open:  open,
+		}
+	}
+	if c.total >= l.Total {
+		return nil, &LimitError{
+			Type:  "TAB_LIMIT",
+			Error: fmt.Sprintf("Tab limit reached: %d of %d terminals are open on the server. Close one to open another.", c.total, l.Total),
+			Scope: "server",
+			Limit: l.Total,
+			Open:  c.total,
+		}
+	}
+	if c.clients == nil {
+		c.clients = make(map[string]int)
+	}
+	c.clients[client]++
+	c.total++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.total--
+			if c.clients[client]--; c.clients[client] <= 0 {
+				delete(c.clients, client)
+			}
+		})
+	}, nil
+}
+
+// counts returns the total and per-client open terminals.
+func (c *sessionCounter) counts() (int, map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clients := make(map[string]int, len(c.clients))
+	for client, n := range c.clients {
+		clients[client] = n
+	}
+	return c.total, clients
+}
+
+// clientAddress identifies the client a connection counts against
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// HandleSessions reports how many terminals are open, per client and in
+// total, against the limits (GET).
+func (h *Handler) HandleSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	total, clients := h.counter.counts()
+	background := 0
+	h.sessions.Range(func(key, _ interface{}) bool {
+		if IsBackgroundSession(key.(string)) {
+			background++
+		}
+		return true
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"open":       total,
+		"yours":      clients[clientAddress(r)],
+		"clients":    clients,
+		"background": background, // Sessions run for command chains, not counted against the limits
+		"limits":     CurrentLimits(),
+	})
+}
@@ -0,0 +1,153 @@
+package terminal
+
+import (
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/forwards"
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+)
+
+// listeningPattern matches the lines servers print when they start: "Server
+// listening on port 3000", "Listening on http://0.0.0.0:8080", "listening at :5000"
+var listeningPattern = regexp.MustCompile(`(?i)\blistening\b[^\r\n]{0,40}?(?:\bport\b\s*:?\s*|:)(\d{2,5})\b`)
+
+// localURLPattern matches URLs of servers on the machine the tab's shell runs
+// on: "http://localhost:5173/", "127.0.0.1:8000", "http://[::1]:3000/app"
+var localURLPattern = regexp.MustCompile(`(?i)\b(?:(https?)://)?(localhost|127\.0\.0\.1|0\.0\.0\.0|\[::1?\]):(\d{2,5})(/[^\s'"<>)\]]*)?`)
+
+// maxPartialLine caps the unterminated output kept between reads
+const maxPartialLine = 512
+
+// maxURLs is how many detected URLs are kept per tab
+const maxURLs = 20
+
+// DetectedURL is a local server URL a tab printed.
+type DetectedURL struct {
+	URL        string    `json:"url"` // As printed, with http:// added when missing
+	Port       int       `json:"port"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// lineScanner splits a tab's output into complete lines, joining lines
+// split across reads, with escape sequences removed.
+type lineScanner struct {
+	partial string
+}
+
+// feed returns the complete lines up to the last line break in data
+func (s *lineScanner) feed(data []byte) string {
+	text := s.partial + llm.CleanANSI(string(data))
+	end := strings.LastIndexAny(text, "\r\n")
+	if end < 0 {
+		s.partial = tail(text, maxPartialLine)
+		return ""
+	}
+	s.partial = tail(text[end+1:], maxPartialLine)
+	return text[:end]
+}
+
+func tail(s string, n int) string {
+	if len(s) > n {
+		return s[len(s)-n:]
+	}
+	return s
+}
+
+// listeningPorts returns the ports servers say they are listening on
+func listeningPorts(lines string) []int {
+	var ports []int
+	for _, match := range listeningPattern.FindAllStringSubmatch(lines, -1) {
+		if port, err := strconv.Atoi(match[1]); err == nil && port > 0 && port <= 65535 {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// localURLs returns the local server URLs in lines
+func localURLs(lines string) []DetectedURL {
+	var urls []DetectedURL
+	for _, match := range localURLPattern.FindAllStringSubmatch(lines, -1) {
+		port, err := strconv.Atoi(match[3])
+		if err != nil || port <= 0 || port > 65535 {
+			continue
+		}
+		scheme := strings.ToLower(match[1])
+		if scheme == "" {
+			scheme = "http"
+		}
+		urls = append(urls, DetectedURL{
+			URL:        scheme + "://" + strings.ToLower(match[2]) + ":" + match[3] + strings.TrimRight(match[4], ".,;:"),
+			Port:       port,
+			DetectedAt: time.Now(),
+		})
+	}
+	return urls
+}
+
+// addURLs records URLs a tab printed, newest last. A URL printed again moves
+// to the end.
+func (s *TerminalSession) addURLs(urls []DetectedURL) {
+	s.detectedMu.Lock()
+	defer s.detectedMu.Unlock()
+	for _, u := range urls {
+		for i, existing := range s.urls {
+			if existing.URL == u.URL {
+				s.urls = append(s.urls[:i], s.urls[i+1:]...)
+				break
+			}
+		}
+		s.urls = append(s.urls, u)
+	}
+	if len(s.urls) > maxURLs {
+		s.urls = append([]DetectedURL(nil), s.urls[len(s.urls)-maxURLs:]...)
+	}
+}
+
+// URLs returns the local server URLs the tab has printed, oldest first.
+func (s *TerminalSession) URLs() []DetectedURL {
+	s.detectedMu.Lock()
+	defer s.detectedMu.Unlock()
+	return append([]DetectedURL{}, s.urls...)
+}
+
+// forwardFor is the forward that reaches a server on port in a tab, for
+// shells whose servers aren't already on this machine's localhost
+func forwardFor(config ShellConfig, tabID string, port int) (forwards.Request, bool) {
+	switch {
+	case config.ShellType == "ssh":
+		// Same port locally, so URLs the server prints keep working
+		return forwards.Request{Kind: forwards.KindSSH, LocalPort: port, RemotePort: port, SSHHost: config.SSHHost, TabID: tabID}, true
+	case config.ShellType == "wsl" && runtime.GOOS == "windows":
+		// Any local port: WSL's own localhost forwarding may hold the same one
+		return forwards.Request{Kind: forwards.KindRelay, RemotePort: port, WSLDistro: config.WSLDistro, TabID: tabID}, true
+	}
+	return forwards.Request{}, false
+}
+
+// watchOutput looks for servers starting and file references in a chunk of
+// a tab's output, recording them and offering remote servers for forwarding
+func watchOutput(session *TerminalSession, scanner *lineScanner, tabID string, data []byte) {
+	lines := scanner.feed(data)
+	if lines == "" {
+		return
+	}
+	session.addFileRefs(findFileRefs(lines))
+
+	urls := localURLs(lines)
+	session.addURLs(urls)
+
+	ports := listeningPorts(lines)
+	for _, u := range urls {
+		ports = append(ports, u.Port)
+	}
+	for _, port := range ports {
+		if req, ok := forwardFor(session.Config, tabID, port); ok {
+			forwards.Suggest(req)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/access"
+	"github.com/mikejsmith1985/forge-terminal/internal/forwards"
+)
+
+// PreviewPrefix is where the dev server a tab started is proxied:
+// /preview/{tabId}/ reaches its most recently printed local URL.
+const PreviewPrefix = "/preview/"
+
+// previewSandbox is added to proxied pages' Content-Security-Policy. Without
+// allow-same-origin the page gets an opaque origin, so its scripts can't use
+// Forge's API even though it is served from Forge's origin.
+const previewSandbox = "sandbox allow-scripts allow-forms allow-popups allow-modals allow-downloads"
+
+// previewEnabled turns the preview proxy on; it is off unless configured
+var previewEnabled atomic.Bool
+
+// SetPreviewProxy turns the /preview/ reverse proxy on or off.
+func SetPreviewProxy(enabled bool) {
+	previewEnabled.Store(enabled)
+}
+
+// HandleURLs lists the local server URLs tabs have printed (GET, ?tabId=
+// for one tab), with the preview path for each tab when the proxy is on.
+func (h *Handler) HandleURLs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	type tabURLs struct {
+		URLs    []DetectedURL `json:"urls"`
+		Preview string        `json:"preview,omitempty"` // Proxy path for the newest URL
+	}
+	tabs := map[string]tabURLs{}
+	want := r.URL.Query().Get("tabId")
+	h.sessions.Range(func(key, value interface{}) bool {
+		tabID := key.(string)
+		if IsBackgroundSession(tabID) || (want != "" && tabID != want) {
+			return true
+		}
+		urls := value.(*TerminalSession).URLs()
+		if len(urls) == 0 {
+			return true
+		}
+		entry := tabURLs{URLs: urls}
+		if previewEnabled.Load() {
+			entry.Preview = PreviewPrefix + url.PathEscape(tabID) + "/"
+		}
+		tabs[tabID] = entry
+		return true
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tabs":    tabs,
+		"preview": previewEnabled.Load(),
+	})
+}
+
+// HandlePreview reverse-proxies /preview/{tabId}/... to the newest local URL
+// the tab printed. Servers in SSH and WSL tabs are reached through a port
+// forward, started on first use. Forge's credentials are not passed on, and
+// the page is sandboxed away from Forge's origin (see previewSandbox).
+func (h *Handler) HandlePreview(w http.ResponseWriter, r *http.Request) {
+	if !previewEnabled.Load() {
+		http.Error(w, "The preview proxy is off (set previewProxy in config)", http.StatusNotFound)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, PreviewPrefix)
+	tabID, path, found := strings.Cut(rest, "/")
+	if !found {
+		// Relative links resolve against the directory, so redirect to it
+		http.Redirect(w, r, PreviewPrefix+rest+"/", http.StatusFound)
+		return
+	}
+	session, ok := h.Session(tabID)
+	if !ok {
+		http.Error(w, "No open tab "+tabID, http.StatusNotFound)
+		return
+	}
+	urls := session.URLs()
+	if len(urls) == 0 {
+		http.Error(w, "The tab hasn't printed a local server URL", http.StatusNotFound)
+		return
+	}
+	newest := urls[len(urls)-1]
+
+	target, err := url.Parse(newest.URL)
+	if err != nil {
+		http.Error(w, "Invalid server URL: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	host, err := previewHost(session, tabID, newest.Port)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	target.Host, target.Path, target.RawPath = host, "", ""
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.URL.Path = "/" + path
+		req.URL.RawPath = ""
+		req.Host = target.Host
+		stripForgeCredentials(req)
+	}
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		resp.Header.Add("Content-Security-Policy", previewSandbox)
+		return nil
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, _ *http.Request, err error) {
+		log.Printf("[Terminal] Preview of %s failed: %v", newest.URL, err)
+		http.Error(w, fmt.Sprintf("Can't reach %s: %v", newest.URL, err), http.StatusBadGateway)
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// stripForgeCredentials removes Forge's access token and login from a request
+// before it goes to a tab's server: the Authorization header, the token
+// cookie and the token query parameter. The page's own cookies are kept.
+func stripForgeCredentials(req *http.Request) {
+	req.Header.Del("Authorization")
+
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, c := range cookies {
+		if c.Name != access.TokenCookie {
+			req.AddCookie(c)
+		}
+	}
+
+	if query := req.URL.Query(); query.Has(access.TokenParam) {
+		query.Del(access.TokenParam)
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// previewHost is the local address a tab's server on port is reached at
+func previewHost(session *TerminalSession, tabID string, port int) (string, error) {
+	req, remote := forwardFor(session.Config, tabID, port)
+	if !remote {
+		return fmt.Sprintf("127.0.0.1:%d", port), nil
+	}
+	for _, f := range forwards.List() {
+		if f.TabID == tabID && f.RemotePort == port && f.Status == forwards.StatusActive {
+			return fmt.Sprintf("127.0.0.1:%d", f.LocalPort), nil
+		}
+	}
+	req.LocalPort = 0 // The proxy doesn't need the same port
+	f, err := forwards.Create(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to forward port %d: %w", port, err)
+	}
+	return fmt.Sprintf("127.0.0.1:%d", f.LocalPort), nil
+}
@@ -18,9 +18,10 @@ func startPTY(cmd *exec.Cmd) (io.ReadWriteCloser, error) {
 }
 
 // startPTYWithShell is not used on Unix (shell config handled in session.go).
-func startPTYWithShell(shell string, args []string) (io.ReadWriteCloser, error) {
+func startPTYWithShell(shell string, args []string, workDir string, env []string) (io.ReadWriteCloser, error) {
 	cmd := exec.Command(shell, args...)
-	cmd.Env = append(os.Environ(),
+	cmd.Dir = workDir
+	cmd.Env = append(env,
 		"TERM=xterm-256color",
 		"COLORTERM=truecolor",
 	)
@@ -23,15 +23,20 @@ func startPTY(cmd *exec.Cmd) (io.ReadWriteCloser, error) {
 	return cpty, nil
 }
 
-// startPTYWithShell starts a PTY session with a specific shell and arguments.
-func startPTYWithShell(shell string, args []string) (io.ReadWriteCloser, error) {
+// startPTYWithShell starts a PTY session with a specific shell, arguments and environment.
+func startPTYWithShell(shell string, args []string, workDir string, env []string) (io.ReadWriteCloser, error) {
 	// Build command line
 	commandLine := shell
 	if len(args) > 0 {
 		commandLine += " " + strings.Join(args, " ")
 	}
 
-	cpty, err := conpty.Start(commandLine)
+	options := []conpty.ConPtyOption{conpty.ConPtyEnv(env)}
+	if workDir != "" {
+		options = append(options, conpty.ConPtyWorkDir(workDir))
+	}
+
+	cpty, err := conpty.Start(commandLine, options...)
 	if err != nil {
 		return nil, fmt.Errorf("conpty start failed for %s: %w", commandLine, err)
 	}
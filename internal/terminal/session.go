@@ -2,6 +2,7 @@
 package terminal
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -11,24 +12,67 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
 )
 
 // ShellConfig contains shell configuration options
 type ShellConfig struct {
-	ShellType   string // "cmd", "powershell", or "wsl"
-	WSLDistro   string // WSL distribution name (e.g., "Ubuntu-24.04")
-	WSLHomePath string // WSL home directory (e.g., "/home/mikej")
+	ShellType      string // "cmd", "powershell", "wsl", "docker", "tmux", or "ssh"
+	WSLDistro      string // WSL distribution name (e.g., "Ubuntu-24.04"); tmux runs in it on Windows
+	WSLHomePath    string // WSL home directory (e.g., "/home/mikej")
+	WorkingDir     string // Starting directory; overrides WSLHomePath when set (inside the container for docker)
+	Container      string // Docker container ID or name (docker only)
+	ContainerShell string // Shell run in the container (docker only; empty = DefaultContainerShell)
+	TmuxSession    string // tmux session to attach to (tmux only; empty = most recent)
+	SSHHost        string // Host or ~/.ssh/config alias to connect to (ssh only)
 }
 
 // TerminalSession represents a single PTY terminal session.
 type TerminalSession struct {
-	ID  string
-	PTY io.ReadWriteCloser
-	Cmd *exec.Cmd // nil on Windows (ConPTY manages process internally)
+	ID     string
+	PTY    io.ReadWriteCloser
+	Cmd    *exec.Cmd   // nil on Windows (ConPTY manages process internally)
+	Config ShellConfig // Shell the session was started with
 
 	mu       sync.Mutex
 	closed   bool
 	doneChan chan struct{}
+
+	// Recent output, used to detect when the shell is back at a prompt
+	outputMu   sync.Mutex
+	outputTail []byte
+	lastOutput time.Time
+
+	// Server URLs and file references the tab printed (see portwatch.go, fileref.go)
+	detectedMu sync.Mutex
+	urls       []DetectedURL
+	fileRefs   []FileRef
+}
+
+// TabIDEnv is set in each tab's shell to the tab's ID, so the forge CLI run
+// inside it can act on that tab
+const TabIDEnv = "FORGE_TAB_ID"
+
+// SessionIDEnv is set in each tab's shell to its terminal session's ID, which
+// is the same as the tab's
+const SessionIDEnv = "FORGE_SESSION_ID"
+
+// outputTailSize is how much recent PTY output is retained per session
+const outputTailSize = 2048
+
+// promptSuffixes are the characters common shell prompts end with
+var promptSuffixes = []string{"$", "#", ">", "%", "❯", "λ", "»"}
+
+// withoutEnv returns env without the named variable
+func withoutEnv(env []string, name string) []string {
+	kept := make([]string, 0, len(env))
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, name+"=") {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
 }
 
 // NewTerminalSession creates a new PTY session with default shell.
@@ -74,6 +118,37 @@ func convertWSLPath(windowsPath string) string {
 	return path
 }
 
+// withWSLEnv adds names to WSLENV in env, so wsl.exe passes the variables on
+// to the distro's shell (it shares nothing from Windows otherwise)
+func withWSLEnv(env []string, names ...string) []string {
+	shared := ""
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, "WSLENV="); ok {
+			shared = value
+		}
+	}
+	listed := map[string]bool{}
+	for _, entry := range strings.Split(shared, ":") {
+		listed[strings.SplitN(entry, "/", 2)[0]] = true
+	}
+	added := false
+	for _, name := range names {
+		if listed[name] {
+			continue
+		}
+		if shared != "" {
+			shared += ":"
+		}
+		shared += name
+		listed[name] = true
+		added = true
+	}
+	if !added {
+		return env
+	}
+	return append(withoutEnv(env, "WSLENV"), "WSLENV="+shared)
+}
+
 // NewTerminalSessionWithConfig creates a new PTY session with specified shell config.
 func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSession, error) {
 	// Determine shell
@@ -81,14 +156,33 @@ func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSess
 	shellArgs := []string{}
 	workingDir := ""
 
-	if runtime.GOOS == "windows" {
+	if config != nil && config.ShellType == "docker" {
+		// Shell in a running container, on any platform
+		if config.Container == "" {
+			return nil, fmt.Errorf("no container given for docker shell")
+		}
+		shell = "docker"
+		shellArgs = dockerExecArgs(config)
+	} else if config != nil && config.ShellType == "ssh" {
+		// Remote login; ssh reads the host's settings from ~/.ssh/config
+		if config.SSHHost == "" {
+			return nil, fmt.Errorf("no host given for ssh shell")
+		}
+		shell = "ssh"
+		shellArgs = []string{"-t", "--", config.SSHHost}
+	} else if config != nil && config.ShellType == "tmux" {
+		// Attach to an existing tmux session, which outlives the tab
+		shell, shellArgs = tmuxArgs(config)
+	} else if runtime.GOOS == "windows" {
 		// Windows shell selection
 		if config != nil && config.ShellType == "wsl" {
 			shell = "wsl.exe"
 			if config.WSLDistro != "" {
 				shellArgs = append(shellArgs, "-d", config.WSLDistro)
 			}
-			if config.WSLHomePath != "" {
+			if config.WorkingDir != "" {
+				shellArgs = append(shellArgs, "--cd", convertWSLPath(config.WorkingDir))
+			} else if config.WSLHomePath != "" {
 				// Convert Windows UNC path to Linux path
 				linuxPath := convertWSLPath(config.WSLHomePath)
 				shellArgs = append(shellArgs, "--cd", linuxPath)
@@ -101,6 +195,9 @@ func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSess
 		} else {
 			shell = "cmd.exe"
 		}
+		if config != nil && config.ShellType != "wsl" {
+			workingDir = config.WorkingDir
+		}
 	} else {
 		// Unix shell (including WSL running natively)
 		if shell == "" {
@@ -112,15 +209,25 @@ func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSess
 		if config != nil && config.WSLHomePath != "" {
 			workingDir = convertWSLPath(config.WSLHomePath)
 		}
+		if config != nil && config.WorkingDir != "" {
+			workingDir = config.WorkingDir
+		}
 	}
 
 	// Create command (only used on Unix)
 	var cmd *exec.Cmd
 	if runtime.GOOS != "windows" {
 		cmd = exec.Command(shell, shellArgs...)
-		cmd.Env = append(os.Environ(),
+		env := os.Environ()
+		if config != nil && config.ShellType == "tmux" {
+			// tmux refuses to attach from inside another tmux client
+			env = withoutEnv(env, "TMUX")
+		}
+		cmd.Env = append(env,
 			"TERM=xterm-256color",
 			"COLORTERM=truecolor",
+			TabIDEnv+"="+id,
+			SessionIDEnv+"="+id,
 		)
 		// Set working directory if specified
 		if workingDir != "" {
@@ -132,7 +239,11 @@ func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSess
 	var ptmx io.ReadWriteCloser
 	var err error
 	if runtime.GOOS == "windows" {
-		ptmx, err = startPTYWithShell(shell, shellArgs)
+		env := append(os.Environ(), TabIDEnv+"="+id, SessionIDEnv+"="+id)
+		if shell == "wsl.exe" {
+			env = withWSLEnv(env, TabIDEnv, SessionIDEnv)
+		}
+		ptmx, err = startPTYWithShell(shell, shellArgs, workingDir, env)
 	} else {
 		ptmx, err = startPTY(cmd)
 	}
@@ -146,6 +257,9 @@ func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSess
 		Cmd:      cmd,
 		doneChan: make(chan struct{}),
 	}
+	if config != nil {
+		session.Config = *config
+	}
 
 	// Monitor process exit (only on Unix where we have cmd)
 	if cmd != nil {
@@ -170,12 +284,82 @@ func NewTerminalSessionWithConfig(id string, config *ShellConfig) (*TerminalSess
 
 // Read reads output from the PTY.
 func (s *TerminalSession) Read(p []byte) (int, error) {
-	return s.PTY.Read(p)
+	n, err := s.PTY.Read(p)
+	if n > 0 {
+		ptyBytesRead.Add(uint64(n))
+		s.recordOutput(p[:n])
+	}
+	return n, err
+}
+
+// recordOutput keeps the tail of recent output and when it arrived.
+func (s *TerminalSession) recordOutput(data []byte) {
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+
+	s.outputTail = append(s.outputTail, data...)
+	if len(s.outputTail) > outputTailSize {
+		s.outputTail = append([]byte(nil), s.outputTail[len(s.outputTail)-outputTailSize:]...)
+	}
+	s.lastOutput = time.Now()
+}
+
+// RecentOutput returns the last few KB of raw PTY output.
+func (s *TerminalSession) RecentOutput() string {
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+	return string(s.outputTail)
+}
+
+// LastOutputAt returns when the PTY last produced output.
+func (s *TerminalSession) LastOutputAt() time.Time {
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+	return s.lastOutput
+}
+
+// AtPrompt reports whether the last line of output looks like a shell prompt.
+func (s *TerminalSession) AtPrompt() bool {
+	output := llm.CleanANSI(s.RecentOutput())
+	output = strings.ReplaceAll(output, "\r", "\n")
+	lines := strings.Split(strings.TrimRight(output, " \n"), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	for _, suffix := range promptSuffixes {
+		if strings.HasSuffix(last, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForPrompt blocks until output has arrived since the given time, gone quiet,
+// and ends in something that looks like a shell prompt.
+func (s *TerminalSession) WaitForPrompt(ctx context.Context, since time.Time) error {
+	const quietPeriod = 300 * time.Millisecond
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.Done():
+			return io.ErrClosedPipe
+		case <-ticker.C:
+			last := s.LastOutputAt()
+			if last.After(since) && time.Since(last) >= quietPeriod && s.AtPrompt() {
+				return nil
+			}
+		}
+	}
 }
 
 // Write writes data to the PTY.
 func (s *TerminalSession) Write(p []byte) (int, error) {
-	return s.PTY.Write(p)
+	n, err := s.PTY.Write(p)
+	ptyBytesWritten.Add(uint64(n))
+	return n, err
 }
 
 // Resize changes the terminal size.
@@ -0,0 +1,32 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithWSLEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		want string
+	}{
+		{"unset", []string{"PATH=/bin"}, "FORGE_TAB_ID:FORGE_SESSION_ID"},
+		{"appended", []string{"WSLENV=USERPROFILE/p"}, "USERPROFILE/p:FORGE_TAB_ID:FORGE_SESSION_ID"},
+		{"already listed", []string{"WSLENV=FORGE_TAB_ID/u:GOPATH/l"}, "FORGE_TAB_ID/u:GOPATH/l:FORGE_SESSION_ID"},
+		{"all listed", []string{"WSLENV=FORGE_SESSION_ID:FORGE_TAB_ID"}, "FORGE_SESSION_ID:FORGE_TAB_ID"},
+	}
+	for _, tt := range tests {
+		env := withWSLEnv(tt.env, TabIDEnv, SessionIDEnv)
+		got, count := "", 0
+		for _, kv := range env {
+			if value, ok := strings.CutPrefix(kv, "WSLENV="); ok {
+				got = value
+				count++
+			}
+		}
+		if got != tt.want || count != 1 {
+			t.Errorf("%s: WSLENV = %q (%d entries), want %q", tt.name, got, count, tt.want)
+		}
+	}
+}
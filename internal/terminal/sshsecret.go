@@ -0,0 +1,83 @@
+package terminal
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/am"
+	"github.com/mikejsmith1985/forge-terminal/internal/llm"
+	"github.com/mikejsmith1985/forge-terminal/internal/secrets"
+)
+
+// SSHSecretPrefix names the secrets ssh tabs log in with: a secret named
+// "ssh/<host>" is typed at the first password or passphrase prompt of a tab
+// connecting to <host>
+const SSHSecretPrefix = "ssh/"
+
+// sshPromptWait bounds how long a tab is watched for a password prompt
+const sshPromptWait = 30 * time.Second
+
+// passwordPromptPattern matches ssh's password and key passphrase prompts,
+// e.g. "me@host's password:" and "Enter passphrase for key '/home/me/.ssh/id_ed25519':"
+var passwordPromptPattern = regexp.MustCompile(`(?i)(password|passphrase)[^\n]*:$`)
+
+// sshSecretName returns the secret for an ssh tab: the one named in the
+// request, else ssh/<host> when stored
+func sshSecretName(config *ShellConfig, requested string) string {
+	if config.ShellType != "ssh" {
+		return ""
+	}
+	if requested != "" {
+		return requested
+	}
+	name := SSHSecretPrefix + config.SSHHost
+	if _, err := secrets.Get(name); err != nil {
+		if !errors.Is(err, secrets.ErrNotFound) {
+			log.Printf("[Terminal] Failed to read the ssh secret for %s: %v", config.SSHHost, err)
+		}
+		return ""
+	}
+	return name
+}
+
+// answerSSHPrompt types a secret at the first password or passphrase prompt
+// the tab shows, once. It gives up when a shell prompt appears first (key
+// authentication succeeded) or after sshPromptWait.
+func answerSSHPrompt(session *TerminalSession, secretName string) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(sshPromptWait)
+	for {
+		select {
+		case <-session.Done():
+			return
+		case <-deadline:
+			return
+		case <-ticker.C:
+		}
+
+		output := strings.ReplaceAll(llm.CleanANSI(session.RecentOutput()), "\r", "\n")
+		lines := strings.Split(strings.TrimRight(output, " \n"), "\n")
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if passwordPromptPattern.MatchString(last) {
+			value, err := secrets.Get(secretName)
+			if err != nil {
+				log.Printf("[Terminal] Can't answer the ssh prompt in tab %s: %v", session.ID, err)
+				return
+			}
+			am.RedactSecret(value)
+			if _, err := session.Write([]byte(value + "\r")); err != nil {
+				log.Printf("[Terminal] Failed to answer the ssh prompt in tab %s: %v", session.ID, err)
+				return
+			}
+			log.Printf("[Terminal] Answered the ssh prompt in tab %s with secret %q", session.ID, secretName)
+			return
+		}
+		if session.AtPrompt() {
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package terminal
+
+import "sync/atomic"
+
+// Process-wide terminal counters, reported by the metrics endpoint
+var (
+	ptyBytesRead      atomic.Uint64
+	ptyBytesWritten   atomic.Uint64
+	webSocketSessions atomic.Int64
+)
+
+// Stats summarizes terminal activity since the server started.
+type Stats struct {
+	WebSocketSessions int64  // Browser tabs currently connected
+	PTYBytesRead      uint64 // Output read from shells
+	PTYBytesWritten   uint64 // Input written to shells
+}
+
+// ReadStats returns the current terminal counters.
+func ReadStats() Stats {
+	return Stats{
+		WebSocketSessions: webSocketSessions.Load(),
+		PTYBytesRead:      ptyBytesRead.Load(),
+		PTYBytesWritten:   ptyBytesWritten.Load(),
+	}
+}
@@ -0,0 +1,204 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TmuxSession is a tmux session on the host, with its windows and panes.
+type TmuxSession struct {
+	Name     string       `json:"name"`
+	Attached bool         `json:"attached"` // A client is attached elsewhere
+	Created  time.Time    `json:"created"`
+	Windows  []TmuxWindow `json:"windows"`
+}
+
+// TmuxWindow is a window in a tmux session.
+type TmuxWindow struct {
+	Index  int        `json:"index"`
+	Name   string     `json:"name"`
+	Active bool       `json:"active"`
+	Panes  []TmuxPane `json:"panes"`
+}
+
+// TmuxPane is a pane in a tmux window.
+type TmuxPane struct {
+	Index   int    `json:"index"`
+	Command string `json:"command"` // Program running in the pane
+	Path    string `json:"path"`    // The pane's working directory
+	Active  bool   `json:"active"`
+}
+
+// tmuxInvocation is the program and arguments that run tmux with args on
+// the host, or in a WSL distribution on Windows
+func tmuxInvocation(distro string, args ...string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		wslArgs := []string{}
+		if distro != "" {
+			wslArgs = append(wslArgs, "-d", distro)
+		}
+		return "wsl.exe", append(append(wslArgs, "-e", "tmux"), args...)
+	}
+	return "tmux", args
+}
+
+// tmuxArgs are the shell and arguments that attach a tab to a tmux session
+// (the most recently used one when TmuxSession is empty)
+func tmuxArgs(config *ShellConfig) (string, []string) {
+	args := []string{"attach"}
+	if config.TmuxSession != "" {
+		args = append(args, "-t", config.TmuxSession)
+	}
+	return tmuxInvocation(config.WSLDistro, args...)
+}
+
+// ListTmuxSessions returns the tmux sessions with their windows and panes.
+// No tmux server running means no sessions, not an error.
+func ListTmuxSessions(ctx context.Context, distro string) ([]TmuxSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	sessionLines, err := tmuxList(ctx, distro, "list-sessions", "#{session_name}:#{session_attached}:#{session_created}", 3)
+	if err != nil || len(sessionLines) == 0 {
+		return []TmuxSession{}, err
+	}
+	windowLines, err := tmuxList(ctx, distro, "list-windows", "#{session_name}:#{window_index}:#{window_active}:#{window_name}", 4)
+	if err != nil {
+		return nil, err
+	}
+	paneLines, err := tmuxList(ctx, distro, "list-panes", "#{session_name}:#{window_index}:#{pane_index}:#{pane_active}:#{pane_current_command}:#{pane_current_path}", 6)
+	if err != nil {
+		return nil, err
+	}
+	return parseTmux(sessionLines, windowLines, paneLines), nil
+}
+
+// tmuxList runs a tmux list command across all sessions, returning the
+// fields of each line. tmux replaces tabs in formats, so fields are separated
+// by colons, which session names can't contain; the last field may.
+func tmuxList(ctx context.Context, distro, command, format string, fields int) ([][]string, error) {
+	args := []string{command, "-F", format}
+	if command != "list-sessions" {
+		args = append(args, "-a")
+	}
+	var stderr bytes.Buffer
+	name, args := tmuxInvocation(distro, args...)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = withoutEnv(os.Environ(), "TMUX") // The same server tabs attach to
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "no server running") || strings.Contains(msg, "error connecting to") {
+			return nil, nil
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("tmux %s failed: %s", command, msg)
+		}
+		return nil, fmt.Errorf("tmux %s failed: %w", command, err)
+	}
+
+	var lines [][]string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, strings.SplitN(line, ":", fields))
+		}
+	}
+	return lines, nil
+}
+
+// parseTmux assembles the list-sessions, list-windows and list-panes output
+// into sessions. Lines with missing fields are skipped.
+func parseTmux(sessionLines, windowLines, paneLines [][]string) []TmuxSession {
+	sessions := []TmuxSession{}
+	sessionIndex := map[string]int{}
+	for _, f := range sessionLines {
+		if len(f) < 3 {
+			continue
+		}
+		created, _ := strconv.ParseInt(f[2], 10, 64)
+		sessionIndex[f[0]] = len(sessions)
+		sessions = append(sessions, TmuxSession{
+			Name:     f[0],
+			Attached: f[1] != "0",
+			Created:  time.Unix(created, 0),
+			Windows:  []TmuxWindow{},
+		})
+	}
+
+	type windowKey struct {
+		session string
+		index   int
+	}
+	windowIndex := map[windowKey]int{}
+	for _, f := range windowLines {
+		if len(f) < 4 {
+			continue
+		}
+		s, ok := sessionIndex[f[0]]
+		if !ok {
+			continue
+		}
+		index, _ := strconv.Atoi(f[1])
+		windowIndex[windowKey{f[0], index}] = len(sessions[s].Windows)
+		sessions[s].Windows = append(sessions[s].Windows, TmuxWindow{
+			Index:  index,
+			Name:   f[3],
+			Active: f[2] == "1",
+			Panes:  []TmuxPane{},
+		})
+	}
+
+	for _, f := range paneLines {
+		if len(f) < 6 {
+			continue
+		}
+		window, _ := strconv.Atoi(f[1])
+		w, ok := windowIndex[windowKey{f[0], window}]
+		if !ok {
+			continue
+		}
+		pane, _ := strconv.Atoi(f[2])
+		s := sessionIndex[f[0]]
+		sessions[s].Windows[w].Panes = append(sessions[s].Windows[w].Panes, TmuxPane{
+			Index:   pane,
+			Command: f[4],
+			Path:    f[5],
+			Active:  f[3] == "1",
+		})
+	}
+	return sessions
+}
+
+// HandleTmuxSessions lists the host's tmux sessions with their windows and
+// panes (GET, ?distro= selects the WSL distribution on Windows).
+func HandleTmuxSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	sessions, err := ListTmuxSessions(r.Context(), r.URL.Query().Get("distro"))
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"available": false,
+			"error":     err.Error(),
+			"sessions":  []TmuxSession{},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": true,
+		"sessions":  sessions,
+	})
+}
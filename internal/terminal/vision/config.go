@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
 )
 
 // Config represents Vision feature configuration.
@@ -17,11 +19,12 @@ type Config struct {
 
 // DetectorConfig controls which detectors are active.
 type DetectorConfig struct {
-	JSON          bool `json:"json"`
-	CompilerError bool `json:"compiler_error"`
-	StackTrace    bool `json:"stack_trace"`
-	Git           bool `json:"git"`
-	FilePath      bool `json:"filepath"`
+	JSON              bool `json:"json"`
+	CompilerError     bool `json:"compiler_error"`
+	StackTrace        bool `json:"stack_trace"`
+	Git               bool `json:"git"`
+	FilePath          bool `json:"filepath"`
+	InteractivePrompt bool `json:"interactive_prompt"`
 }
 
 // ConfigManager handles Vision configuration persistence.
@@ -36,11 +39,12 @@ func DefaultConfig() *Config {
 	return &Config{
 		Enabled: false, // Opt-in via Dev Mode
 		Detectors: DetectorConfig{
-			JSON:          true,
-			CompilerError: true,
-			StackTrace:    true,
-			Git:           true,
-			FilePath:      true,
+			JSON:              true,
+			CompilerError:     true,
+			StackTrace:        true,
+			Git:               true,
+			FilePath:          true,
+			InteractivePrompt: true,
 		},
 		JSONMinSize: 30, // Ignore trivial JSON
 		AutoDismiss: true,
@@ -61,7 +65,7 @@ func (cm *ConfigManager) Load() error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	data, err := os.ReadFile(cm.configPath)
+	data, err := storage.ReadJSONFile(cm.configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No config file, use defaults
@@ -95,7 +99,7 @@ func (cm *ConfigManager) Save() error {
 		return err
 	}
 
-	return os.WriteFile(cm.configPath, data, 0644)
+	return storage.WriteFileAtomic(cm.configPath, data, 0644)
 }
 
 // Get returns a copy of the current configuration.
@@ -120,11 +124,12 @@ func (cm *ConfigManager) ApplyToRegistry(registry *Registry) {
 	defer cm.mu.RUnlock()
 
 	detectorMap := map[string]bool{
-		"json":           cm.config.Detectors.JSON,
-		"compiler_error": cm.config.Detectors.CompilerError,
-		"stack_trace":    cm.config.Detectors.StackTrace,
-		"git":            cm.config.Detectors.Git,
-		"filepath":       cm.config.Detectors.FilePath,
+		"json":               cm.config.Detectors.JSON,
+		"compiler_error":     cm.config.Detectors.CompilerError,
+		"stack_trace":        cm.config.Detectors.StackTrace,
+		"git":                cm.config.Detectors.Git,
+		"filepath":           cm.config.Detectors.FilePath,
+		"interactive_prompt": cm.config.Detectors.InteractivePrompt,
 	}
 
 	for name, enabled := range detectorMap {
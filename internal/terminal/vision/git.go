@@ -2,9 +2,10 @@ package vision
 
 import (
 	"bytes"
-	"regexp"
 	"strings"
 	"sync"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/ansi"
 )
 
 // GitStatusDetector detects git status output.
@@ -38,8 +39,7 @@ func (g *GitStatusDetector) SetEnabled(enabled bool) {
 
 // stripAnsi removes ANSI escape codes
 func stripAnsi(text string) string {
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-	return ansiRegex.ReplaceAllString(text, "")
+	return ansi.Strip(text)
 }
 
 // Detect analyzes buffer for git status output.
@@ -0,0 +1,76 @@
+package vision
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Kinds of interactive prompt
+const (
+	PromptPassword = "password" // sudo, ssh and key passphrase prompts
+	PromptHostKey  = "host_key" // ssh asking to trust an unknown host
+	PromptConfirm  = "confirm"  // yes/no questions, e.g. npx's "Ok to proceed? (y)"
+)
+
+// interactivePrompts match the last line of output when a command stops to
+// wait for an answer, checked in order
+var interactivePrompts = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{PromptHostKey, regexp.MustCompile(`(?i)are you sure you want to continue connecting \(yes/no(/\[fingerprint\])?\)\?$`)},
+	{PromptPassword, regexp.MustCompile(`(?i)(password|passphrase|passcode|pin)( for [^:]+)?[^\n:]*:$`)},
+	{PromptConfirm, regexp.MustCompile(`(?i)(\(y\)|\(y/n\)|\[y/n\]|\(yes/no\)|\[yes/no\])\s*[?:]?$`)},
+}
+
+// InteractivePromptDetector spots commands waiting for the user to type a
+// password or answer a question, so a tab left waiting can be highlighted.
+type InteractivePromptDetector struct {
+	mu      sync.RWMutex
+	enabled bool
+}
+
+// NewInteractivePromptDetector creates an interactive prompt detector.
+func NewInteractivePromptDetector() *InteractivePromptDetector {
+	return &InteractivePromptDetector{enabled: true}
+}
+
+func (d *InteractivePromptDetector) Name() string {
+	return "interactive_prompt"
+}
+
+func (d *InteractivePromptDetector) Enabled() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.enabled
+}
+
+func (d *InteractivePromptDetector) SetEnabled(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.enabled = enabled
+}
+
+// Detect matches when the output ends with an interactive prompt. The
+// payload holds its kind (PromptPassword, ...) and text.
+func (d *InteractivePromptDetector) Detect(buffer []byte) *Match {
+	text := strings.TrimRight(strings.ReplaceAll(stripAnsi(string(buffer)), "\r", "\n"), " \t\n")
+	prompt := strings.TrimSpace(text[strings.LastIndexByte(text, '\n')+1:])
+	if prompt == "" {
+		return nil
+	}
+	for _, p := range interactivePrompts {
+		if p.pattern.MatchString(prompt) {
+			return &Match{
+				Type: "INTERACTIVE_PROMPT",
+				Payload: map[string]interface{}{
+					"kind":    p.kind,
+					"prompt":  prompt,
+					"message": prompt,
+				},
+			}
+		}
+	}
+	return nil
+}
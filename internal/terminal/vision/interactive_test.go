@@ -0,0 +1,40 @@
+package vision
+
+import "testing"
+
+func TestInteractivePromptDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantKind string
+	}{
+		{"sudo", "$ sudo apt update\r\n[sudo] password for mike: ", PromptPassword},
+		{"ssh password", "mike@example.com's password: ", PromptPassword},
+		{"key passphrase", "Enter passphrase for key '/home/mike/.ssh/id_ed25519': ", PromptPassword},
+		{"host key", "ED25519 key fingerprint is SHA256:abc.\r\nAre you sure you want to continue connecting (yes/no/[fingerprint])? ", PromptHostKey},
+		{"npx", "Need to install the following packages:\n  create-react-app@5.0.1\nOk to proceed? (y) ", PromptConfirm},
+		{"apt", "Do you want to continue? [Y/n] ", PromptConfirm},
+		{"colored", "\x1b[1m[sudo] password for mike:\x1b[0m ", PromptPassword},
+		{"answered", "[sudo] password for mike: \r\nReading package lists...\r\n", ""},
+		{"password in output", "Updated password policy: done\n$ ", ""},
+		{"plain output", "total 0\ndrwxr-xr-x 2 mike mike 40 .\n", ""},
+	}
+	d := NewInteractivePromptDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := d.Detect([]byte(tt.input))
+			if tt.wantKind == "" {
+				if match != nil {
+					t.Errorf("Expected no match, got %+v", match.Payload)
+				}
+				return
+			}
+			if match == nil {
+				t.Fatal("Expected a match")
+			}
+			if match.Type != "INTERACTIVE_PROMPT" || match.Payload["kind"] != tt.wantKind {
+				t.Errorf("Got %s %+v, want kind %s", match.Type, match.Payload, tt.wantKind)
+			}
+		})
+	}
+}
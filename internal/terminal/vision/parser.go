@@ -145,6 +145,11 @@ func generateInsightMessage(match *Match) string {
 		return "JSON data detected"
 	case "FILE_PATH":
 		return "File path detected"
+	case "INTERACTIVE_PROMPT":
+		if prompt, ok := match.Payload["prompt"].(string); ok {
+			return "Waiting for input: " + prompt
+		}
+		return "Waiting for input"
 	default:
 		return fmt.Sprintf("Pattern detected: %s", match.Type)
 	}
@@ -0,0 +1,77 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// changelogScanLimit is how many releases are scanned when building a changelog (GitHub's page maximum)
+const changelogScanLimit = 100
+
+// ChangelogEntry is one release's notes
+type ChangelogEntry struct {
+	Version     string `json:"version"`
+	Name        string `json:"name"`
+	PublishedAt string `json:"publishedAt"`
+	Notes       string `json:"notes"`
+	Prerelease  bool   `json:"prerelease,omitempty"`
+}
+
+// Changelog returns the notes for every release on the current channel newer
+// than from (default: the running version), newest first, along with the
+// notes concatenated as markdown.
+func Changelog(from string) ([]ChangelogEntry, string, error) {
+	if from == "" {
+		from = Version
+	}
+	from = strings.TrimPrefix(strings.TrimSpace(from), "v")
+
+	releases, err := fetchReleases(changelogScanLimit)
+	if err != nil {
+		return nil, "", err
+	}
+	entries, markdown := buildChangelog(releases, from, GetChannel())
+	return entries, markdown, nil
+}
+
+func buildChangelog(releases []Release, from, channelName string) ([]ChangelogEntry, string) {
+	entries := []ChangelogEntry{}
+	for _, release := range releases {
+		if !onChannel(release, channelName) {
+			continue
+		}
+		if compareVersions(strings.TrimPrefix(release.TagName, "v"), from) <= 0 {
+			continue
+		}
+		entries = append(entries, ChangelogEntry{
+			Version:     release.TagName,
+			Name:        release.Name,
+			PublishedAt: release.PublishedAt,
+			Notes:       strings.TrimSpace(release.Body),
+			Prerelease:  release.Prerelease,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return compareVersions(strings.TrimPrefix(entries[i].Version, "v"), strings.TrimPrefix(entries[j].Version, "v")) > 0
+	})
+
+	var b strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "## %s", entry.Version)
+		if date, _, ok := strings.Cut(entry.PublishedAt, "T"); ok {
+			fmt.Fprintf(&b, " (%s)", date)
+		}
+		b.WriteString("\n\n")
+		if entry.Notes != "" {
+			b.WriteString(entry.Notes)
+		} else {
+			b.WriteString("_No release notes._")
+		}
+	}
+	return entries, b.String()
+}
@@ -0,0 +1,37 @@
+package updater
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildChangelog(t *testing.T) {
+	releases := []Release{
+		{TagName: "v1.4.0-beta.1", Prerelease: true, Body: "beta"},
+		{TagName: "v1.3.0", Body: "three", PublishedAt: "2024-03-01T10:00:00Z"},
+		{TagName: "v1.2.0", Body: ""},
+		{TagName: "v1.1.0", Body: "one"},
+		{TagName: "v1.0.0", Body: "zero"},
+	}
+
+	entries, markdown := buildChangelog(releases, "1.1.0", ChannelStable)
+	if len(entries) != 2 || entries[0].Version != "v1.3.0" || entries[1].Version != "v1.2.0" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if !strings.Contains(markdown, "## v1.3.0 (2024-03-01)\n\nthree") || !strings.Contains(markdown, "_No release notes._") {
+		t.Errorf("unexpected markdown:\n%s", markdown)
+	}
+	if strings.Index(markdown, "v1.3.0") > strings.Index(markdown, "v1.2.0") {
+		t.Error("expected newest release first")
+	}
+
+	entries, _ = buildChangelog(releases, "1.1.0", ChannelBeta)
+	if len(entries) != 3 || entries[0].Version != "v1.4.0-beta.1" {
+		t.Errorf("beta channel should include prereleases, got %+v", entries)
+	}
+
+	entries, markdown = buildChangelog(releases, "1.4.0", ChannelStable)
+	if len(entries) != 0 || markdown != "" {
+		t.Errorf("expected empty changelog when up to date, got %+v", entries)
+	}
+}
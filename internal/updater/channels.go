@@ -0,0 +1,161 @@
+package updater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Release channels
+const (
+	ChannelStable  = "stable"  // Full releases only
+	ChannelBeta    = "beta"    // Stable plus beta/rc prereleases
+	ChannelNightly = "nightly" // Every published build
+)
+
+// Channels lists the valid channel names
+var Channels = []string{ChannelStable, ChannelBeta, ChannelNightly}
+
+var (
+	channel   = ChannelStable
+	channelMu sync.RWMutex
+)
+
+// SetChannel selects the release channel used by CheckForUpdate. Empty selects stable.
+func SetChannel(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = ChannelStable
+	}
+	if !validChannel(name) {
+		return fmt.Errorf("unknown update channel %q", name)
+	}
+	channelMu.Lock()
+	channel = name
+	channelMu.Unlock()
+	return nil
+}
+
+// GetChannel returns the current release channel.
+func GetChannel() string {
+	channelMu.RLock()
+	defer channelMu.RUnlock()
+	return channel
+}
+
+func validChannel(name string) bool {
+	for _, c := range Channels {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseChannel classifies a release by the least adventurous channel that receives it.
+func releaseChannel(release Release) string {
+	tag := strings.ToLower(release.TagName)
+	_, pre := splitPrerelease(strings.TrimPrefix(tag, "v"))
+	switch {
+	case !release.Prerelease && pre == "":
+		return ChannelStable
+	case strings.Contains(pre, "beta") || strings.Contains(pre, "rc"):
+		return ChannelBeta
+	default:
+		return ChannelNightly
+	}
+}
+
+// onChannel reports whether a release should be offered to users on the given channel.
+func onChannel(release Release, name string) bool {
+	if release.Draft {
+		return false
+	}
+	switch releaseChannel(release) {
+	case ChannelStable:
+		return true
+	case ChannelBeta:
+		return name == ChannelBeta || name == ChannelNightly
+	default:
+		return name == ChannelNightly
+	}
+}
+
+// latestOnChannel returns the highest-versioned release available on a channel.
+func latestOnChannel(releases []Release, name string) *Release {
+	var best *Release
+	for i := range releases {
+		if !onChannel(releases[i], name) {
+			continue
+		}
+		if best == nil || compareVersions(strings.TrimPrefix(releases[i].TagName, "v"), strings.TrimPrefix(best.TagName, "v")) > 0 {
+			best = &releases[i]
+		}
+	}
+	return best
+}
+
+// findRelease returns the published release with the given version, on any channel.
+func findRelease(releases []Release, version string) *Release {
+	for i := range releases {
+		if !releases[i].Draft && sameVersion(releases[i].TagName, version) {
+			return &releases[i]
+		}
+	}
+	return nil
+}
+
+// splitPrerelease separates "1.2.3-beta.1+build" into "1.2.3" and "beta.1".
+func splitPrerelease(version string) (core, pre string) {
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		version = version[:idx]
+	}
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		return version[:idx], version[idx+1:]
+	}
+	return version, ""
+}
+
+// comparePrerelease orders prerelease identifiers per semver: a version without
+// a prerelease ranks above one with it, numeric identifiers compare numerically.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA > numB {
+					return 1
+				}
+				return -1
+			}
+		case errA == nil:
+			return -1 // Numeric identifiers rank below alphanumeric ones
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(partsA[i], partsB[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(partsA) > len(partsB):
+		return 1
+	case len(partsA) < len(partsB):
+		return -1
+	}
+	return 0
+}
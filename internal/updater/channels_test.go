@@ -0,0 +1,69 @@
+package updater
+
+import "testing"
+
+func TestCompareVersions_Prerelease(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"1.2.1", "1.2.0", 1},
+		{"1.2.0", "1.2.0-beta.1", 1},
+		{"1.2.0-beta.2", "1.2.0-beta.1", 1},
+		{"1.2.0-beta.10", "1.2.0-beta.9", 1},
+		{"1.2.0-rc.1", "1.2.0-beta.3", 1},
+		{"1.2.0-beta", "1.2.0-beta.1", -1},
+		{"1.3.0-beta.1", "1.2.0", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLatestOnChannel(t *testing.T) {
+	releases := []Release{
+		{TagName: "v1.4.0-nightly.20240101", Prerelease: true},
+		{TagName: "v1.3.0-beta.2", Prerelease: true},
+		{TagName: "v1.5.0", Draft: true},
+		{TagName: "v1.2.0"},
+		{TagName: "v1.1.0"},
+	}
+
+	for channel, want := range map[string]string{
+		ChannelStable:  "v1.2.0",
+		ChannelBeta:    "v1.3.0-beta.2",
+		ChannelNightly: "v1.4.0-nightly.20240101",
+	} {
+		got := latestOnChannel(releases, channel)
+		if got == nil || got.TagName != want {
+			t.Errorf("latestOnChannel(%s) = %v, want %s", channel, got, want)
+		}
+	}
+
+	if got := latestOnChannel([]Release{{TagName: "v2.0.0-beta.1", Prerelease: true}}, ChannelStable); got != nil {
+		t.Errorf("stable channel should not receive prereleases, got %s", got.TagName)
+	}
+}
+
+func TestSetChannel(t *testing.T) {
+	defer SetChannel(ChannelStable)
+
+	if err := SetChannel(" Beta "); err != nil {
+		t.Fatalf("SetChannel: %v", err)
+	}
+	if GetChannel() != ChannelBeta {
+		t.Errorf("GetChannel() = %s, want beta", GetChannel())
+	}
+	if err := SetChannel("canary"); err == nil {
+		t.Error("expected error for unknown channel")
+	}
+	if GetChannel() != ChannelBeta {
+		t.Error("invalid channel should not change the setting")
+	}
+	if err := SetChannel(""); err != nil || GetChannel() != ChannelStable {
+		t.Errorf("empty channel should select stable, got %s (%v)", GetChannel(), err)
+	}
+}
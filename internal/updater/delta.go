@@ -0,0 +1,179 @@
+package updater
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Delta updates are bsdiff (BSDIFF40) patches published next to the full
+// binaries, named for the version they apply to:
+//
+//	forge-linux-amd64-from-1.22.18.bsdiff
+//
+// A patch is only used when it exists for the running version, and the result
+// must match the full asset's size; otherwise the full binary is downloaded.
+const patchSuffix = ".bsdiff"
+
+// bsdiffMagic opens every BSDIFF40 patch
+const bsdiffMagic = "BSDIFF40"
+
+// errCorruptPatch is returned for patches that don't decode cleanly
+var errCorruptPatch = errors.New("corrupt patch")
+
+// patchAssetName returns the delta asset name for upgrading from version.
+func patchAssetName(fromVersion string) string {
+	return strings.TrimSuffix(getAssetName(), ".exe") + "-from-" + strings.TrimPrefix(fromVersion, "v") + patchSuffix
+}
+
+// findPatch returns the delta asset for the running version, if the release has one.
+func findPatch(release Release) (Asset, bool) {
+	name := patchAssetName(Version)
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// downloadPatched builds the new binary by patching the running executable.
+func downloadPatched(info *UpdateInfo, dst string) error {
+	var patch []byte
+	if path := localPath(info.PatchURL); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		patch = data
+	} else {
+		resp, err := httpClient(5 * time.Minute).Get(info.PatchURL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("patch download failed with status %d", resp.StatusCode)
+		}
+		if patch, err = io.ReadAll(resp.Body); err != nil {
+			return err
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	old, err := os.ReadFile(exe)
+	if err != nil {
+		return err
+	}
+
+	patched, err := bspatch(old, patch)
+	if err != nil {
+		return err
+	}
+	if info.AssetSize > 0 && int64(len(patched)) != info.AssetSize {
+		return fmt.Errorf("patched binary is %d bytes, expected %d", len(patched), info.AssetSize)
+	}
+
+	mode := os.FileMode(0644)
+	if runtime.GOOS != "windows" {
+		mode = 0755
+	}
+	return os.WriteFile(dst, patched, mode)
+}
+
+// downloadWithPatch tries the delta update first and reports whether it worked.
+func downloadWithPatch(info *UpdateInfo, dst string) bool {
+	if info.PatchURL == "" {
+		return false
+	}
+	if err := downloadPatched(info, dst); err != nil {
+		log.Printf("[Updater] Delta update failed, downloading full binary: %v", err)
+		os.Remove(dst)
+		return false
+	}
+	log.Printf("[Updater] Applied %d-byte delta update", info.PatchSize)
+	return true
+}
+
+// bspatch applies a BSDIFF40 patch to old.
+func bspatch(old, patch []byte) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != bsdiffMagic {
+		return nil, errCorruptPatch
+	}
+	ctrlLen := offtin(patch[8:16])
+	diffLen := offtin(patch[16:24])
+	newSize := offtin(patch[24:32])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || 32+ctrlLen+diffLen > int64(len(patch)) {
+		return nil, errCorruptPatch
+	}
+
+	body := patch[32:]
+	ctrl := bzip2.NewReader(bytes.NewReader(body[:ctrlLen]))
+	diff := bzip2.NewReader(bytes.NewReader(body[ctrlLen : ctrlLen+diffLen]))
+	extra := bzip2.NewReader(bytes.NewReader(body[ctrlLen+diffLen:]))
+
+	out := make([]byte, newSize)
+	var oldPos, newPos int64
+	var triple [24]byte
+	for newPos < newSize {
+		if _, err := io.ReadFull(ctrl, triple[:]); err != nil {
+			return nil, errCorruptPatch
+		}
+		addLen, copyLen, seek := offtin(triple[0:8]), offtin(triple[8:16]), offtin(triple[16:24])
+		if addLen < 0 || copyLen < 0 || newPos+addLen+copyLen > newSize {
+			return nil, errCorruptPatch
+		}
+
+		// Add old bytes to the diff block
+		if _, err := io.ReadFull(diff, out[newPos:newPos+addLen]); err != nil {
+			return nil, errCorruptPatch
+		}
+		for i := int64(0); i < addLen; i++ {
+			if p := oldPos + i; p >= 0 && p < int64(len(old)) {
+				out[newPos+i] += old[p]
+			}
+		}
+		newPos += addLen
+		oldPos += addLen
+
+		// Copy new bytes from the extra block
+		if _, err := io.ReadFull(extra, out[newPos:newPos+copyLen]); err != nil {
+			return nil, errCorruptPatch
+		}
+		newPos += copyLen
+		oldPos += seek
+	}
+
+	// Read each block to the end so bzip2 verifies its checksums
+	for _, r := range []io.Reader{ctrl, diff, extra} {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return nil, errCorruptPatch
+		}
+	}
+	return out, nil
+}
+
+// offtin decodes bsdiff's sign-magnitude little-endian 64-bit integers.
+func offtin(b []byte) int64 {
+	v := binary.LittleEndian.Uint64(b)
+	n := int64(v &^ (1 << 63))
+	if v&(1<<63) != 0 {
+		return -n
+	}
+	return n
+}
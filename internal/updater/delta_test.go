@@ -0,0 +1,57 @@
+package updater
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// testPatch turns "hello forge terminal v1" into "hello forge terminal v2 with delta"
+// (generated with a reference bsdiff encoder)
+const testPatch = "42534449464634302b0000000000000029000000000000002200000000000000425a6839314159265359d544db0e000005e00048080080200030cd3418c8a5ae38bb9229c28486aa26d870425a683931415926535926e518f9000000600060001000200030cc0cf505ce2ee48a70a1204dca31f2425a68393141592653591fae448c0000009180400026640480200031064c4106989a11da44723f1772453850901fae448c"
+
+func TestBspatch(t *testing.T) {
+	patch, _ := hex.DecodeString(testPatch)
+
+	got, err := bspatch([]byte("hello forge terminal v1"), patch)
+	if err != nil {
+		t.Fatalf("bspatch: %v", err)
+	}
+	if string(got) != "hello forge terminal v2 with delta" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	if _, err := bspatch([]byte("x"), []byte("not a patch")); err == nil {
+		t.Error("expected error for invalid patch")
+	}
+	if _, err := bspatch([]byte("x"), patch[:len(patch)-10]); err == nil {
+		t.Error("expected error for truncated patch")
+	}
+}
+
+func TestFindPatch(t *testing.T) {
+	name := patchAssetName(Version)
+	if !strings.HasSuffix(name, "-from-"+strings.TrimPrefix(Version, "v")+".bsdiff") || strings.Contains(name, ".exe") {
+		t.Errorf("unexpected patch asset name %q", name)
+	}
+
+	release := Release{Assets: []Asset{
+		{Name: getAssetName()},
+		{Name: patchAssetName("0.0.1")},
+		{Name: name, BrowserDownloadURL: "https://example.com/patch", Size: 42},
+	}}
+	patch, ok := findPatch(release)
+	if !ok || patch.Size != 42 {
+		t.Errorf("expected patch for running version, got %+v", patch)
+	}
+	if _, ok := findPatch(Release{Assets: []Asset{{Name: patchAssetName("0.0.1")}}}); ok {
+		t.Error("patches for other versions should be ignored")
+	}
+}
+
+func TestDownloadWithPatchFallback(t *testing.T) {
+	info := &UpdateInfo{PatchURL: localScheme + t.TempDir() + "/missing.bsdiff"}
+	if downloadWithPatch(info, t.TempDir()+"/out") {
+		t.Error("expected fallback when the patch can't be read")
+	}
+}
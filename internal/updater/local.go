@@ -0,0 +1,90 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// localScheme prefixes download URLs for updates served from a local SourceDir
+const localScheme = "file://"
+
+// localNotesFiles are checked, in order, for release notes in a local release directory
+var localNotesFiles = []string{"RELEASE_NOTES.md", "CHANGELOG.md", "notes.md"}
+
+// localReleases reads releases from an offline update directory laid out as one
+// subdirectory per version, each holding the platform binaries named as on GitHub:
+//
+//	updates/v1.23.0/forge-windows-amd64.exe
+//	updates/v1.23.0/RELEASE_NOTES.md
+//	updates/v1.24.0-beta.1/forge-linux-amd64
+//
+// Versions with a prerelease suffix are treated as GitHub prereleases. Releases
+// are returned newest first, like the GitHub API.
+func localReleases(dir string) ([]Release, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read update source: %w", err)
+	}
+
+	releases := []Release{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		tag := entry.Name()
+		core, pre := splitPrerelease(strings.TrimPrefix(tag, "v"))
+		if core == "" || !strings.Contains(core, ".") {
+			continue
+		}
+
+		releaseDir := filepath.Join(dir, tag)
+		release := Release{TagName: tag, Name: tag, Prerelease: pre != ""}
+		if info, err := entry.Info(); err == nil {
+			release.PublishedAt = info.ModTime().UTC().Format("2006-01-02T15:04:05Z")
+		}
+
+		files, err := os.ReadDir(releaseDir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			if strings.HasPrefix(f.Name(), "forge-") {
+				info, err := f.Info()
+				if err != nil {
+					continue
+				}
+				release.Assets = append(release.Assets, Asset{
+					Name:               f.Name(),
+					BrowserDownloadURL: localScheme + filepath.ToSlash(filepath.Join(releaseDir, f.Name())),
+					Size:               info.Size(),
+				})
+			}
+		}
+		for _, name := range localNotesFiles {
+			if data, err := os.ReadFile(filepath.Join(releaseDir, name)); err == nil {
+				release.Body = string(data)
+				break
+			}
+		}
+		releases = append(releases, release)
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return compareVersions(strings.TrimPrefix(releases[i].TagName, "v"), strings.TrimPrefix(releases[j].TagName, "v")) > 0
+	})
+	return releases, nil
+}
+
+// localPath returns the file path for a local download URL, or "" for remote URLs.
+func localPath(downloadURL string) string {
+	if !strings.HasPrefix(downloadURL, localScheme) {
+		return ""
+	}
+	return filepath.FromSlash(strings.TrimPrefix(downloadURL, localScheme))
+}
@@ -0,0 +1,54 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalReleases(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("v1.9.0/"+getAssetName(), "old")
+	write("v1.10.0/"+getAssetName(), "new")
+	write("v1.10.0/RELEASE_NOTES.md", "notes")
+	write("v1.11.0-beta.1/"+getAssetName(), "beta")
+	write("not-a-release/readme.txt", "ignored")
+
+	releases, err := localReleases(dir)
+	if err != nil {
+		t.Fatalf("localReleases: %v", err)
+	}
+	if len(releases) != 3 {
+		t.Fatalf("expected 3 releases, got %d", len(releases))
+	}
+	if releases[0].TagName != "v1.11.0-beta.1" || !releases[0].Prerelease {
+		t.Errorf("expected newest prerelease first, got %+v", releases[0])
+	}
+	if releases[1].TagName != "v1.10.0" || releases[1].Body != "notes" {
+		t.Errorf("unexpected second release %+v", releases[1])
+	}
+
+	stable := latestOnChannel(releases, ChannelStable)
+	if stable == nil || stable.TagName != "v1.10.0" {
+		t.Fatalf("expected v1.10.0 on stable, got %v", stable)
+	}
+
+	info := &UpdateInfo{Available: true, DownloadURL: stable.Assets[0].BrowserDownloadURL}
+	tmp, err := DownloadUpdate(info)
+	if err != nil {
+		t.Fatalf("DownloadUpdate: %v", err)
+	}
+	defer os.Remove(tmp)
+	if data, _ := os.ReadFile(tmp); string(data) != "new" {
+		t.Errorf("expected copied binary contents %q, got %q", "new", data)
+	}
+}
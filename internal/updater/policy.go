@@ -0,0 +1,143 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auto-update policies
+const (
+	PolicyManual     = "manual"      // Notify only; the user downloads and installs
+	PolicyDownload   = "download"    // Download in the background; the user installs
+	PolicyQuietHours = "quiet-hours" // Download, then install automatically during quiet hours
+)
+
+// Policies lists the valid policy names
+var Policies = []string{PolicyManual, PolicyDownload, PolicyQuietHours}
+
+// DefaultQuietHours is used when quiet-hours installs are enabled without a window
+const DefaultQuietHours = "02:00-05:00"
+
+// QuietHours is a daily local-time window, which may wrap past midnight ("22:00-06:00").
+type QuietHours struct {
+	Start int // Minutes after midnight
+	End   int
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM".
+func ParseQuietHours(s string) (QuietHours, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 2 {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q (expected HH:MM-HH:MM)", s)
+	}
+	var q QuietHours
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return QuietHours{}, fmt.Errorf("invalid quiet hours %q (expected HH:MM-HH:MM)", s)
+		}
+		minutes := t.Hour()*60 + t.Minute()
+		if i == 0 {
+			q.Start = minutes
+		} else {
+			q.End = minutes
+		}
+	}
+	if q.Start == q.End {
+		return QuietHours{}, fmt.Errorf("quiet hours %q are empty", s)
+	}
+	return q, nil
+}
+
+// Contains reports whether t falls inside the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return m >= q.Start && m < q.End
+	}
+	return m >= q.Start || m < q.End
+}
+
+// Next returns when the window next opens at or after t (t itself if inside it).
+func (q QuietHours) Next(t time.Time) time.Time {
+	if q.Contains(t) {
+		return t
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), q.Start/60, q.Start%60, 0, 0, t.Location())
+	if !start.After(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+func validPolicy(name string) bool {
+	for _, p := range Policies {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stagedUpdate is an update downloaded ahead of installation
+type stagedUpdate struct {
+	Version string
+	Path    string
+}
+
+var (
+	staged   *stagedUpdate
+	stagedMu sync.Mutex
+)
+
+// StageUpdate downloads an update for later installation, reusing an earlier
+// download of the same version. Returns the path of the downloaded binary.
+func StageUpdate(info *UpdateInfo) (string, error) {
+	stagedMu.Lock()
+	defer stagedMu.Unlock()
+
+	if staged != nil && staged.Version == info.LatestVersion {
+		if _, err := os.Stat(staged.Path); err == nil {
+			return staged.Path, nil
+		}
+	}
+	path, err := DownloadUpdate(info)
+	if err != nil {
+		return "", err
+	}
+	staged = &stagedUpdate{Version: info.LatestVersion, Path: path}
+	return path, nil
+}
+
+// StagedVersion returns the version downloaded by StageUpdate, or "".
+func StagedVersion() string {
+	stagedMu.Lock()
+	defer stagedMu.Unlock()
+	if staged == nil {
+		return ""
+	}
+	if _, err := os.Stat(staged.Path); err != nil {
+		staged = nil
+		return ""
+	}
+	return staged.Version
+}
+
+// TakeStagedUpdate returns the staged binary for version and clears it, since
+// ApplyUpdate consumes the file.
+func TakeStagedUpdate(version string) (string, bool) {
+	stagedMu.Lock()
+	defer stagedMu.Unlock()
+	if staged == nil || staged.Version != version {
+		return "", false
+	}
+	path := staged.Path
+	staged = nil
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
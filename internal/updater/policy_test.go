@@ -0,0 +1,60 @@
+package updater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 10, hour, minute, 0, 0, time.Local)
+	}
+
+	q, err := ParseQuietHours("02:00-05:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Contains(at(3, 30)) || q.Contains(at(5, 0)) || q.Contains(at(1, 59)) {
+		t.Error("unexpected Contains result for 02:00-05:00")
+	}
+	if next := q.Next(at(12, 0)); !next.Equal(time.Date(2024, 3, 11, 2, 0, 0, 0, time.Local)) {
+		t.Errorf("expected next window tomorrow at 02:00, got %s", next)
+	}
+	if next := q.Next(at(1, 0)); !next.Equal(at(2, 0)) {
+		t.Errorf("expected next window today at 02:00, got %s", next)
+	}
+
+	overnight, err := ParseQuietHours("22:30-06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !overnight.Contains(at(23, 0)) || !overnight.Contains(at(5, 59)) || overnight.Contains(at(12, 0)) {
+		t.Error("unexpected Contains result for overnight window")
+	}
+
+	for _, bad := range []string{"", "02:00", "2am-5am", "03:00-03:00"} {
+		if _, err := ParseQuietHours(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestConfigurePolicy(t *testing.T) {
+	defer Configure(Settings{})
+
+	if s := GetSettings(); s.Policy != PolicyManual || s.QuietHours != DefaultQuietHours {
+		t.Errorf("expected manual policy by default, got %+v", s)
+	}
+	if err := Configure(Settings{Policy: "Quiet-Hours", QuietHours: "23:00-01:00"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := GetSettings(); s.Policy != PolicyQuietHours {
+		t.Errorf("expected quiet-hours policy, got %s", s.Policy)
+	}
+	if err := Configure(Settings{Policy: "always"}); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+	if err := Configure(Settings{QuietHours: "late"}); err == nil {
+		t.Error("expected error for invalid quiet hours")
+	}
+}
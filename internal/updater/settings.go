@@ -0,0 +1,154 @@
+package updater
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Background check interval bounds
+const (
+	DefaultCheckInterval = 30 * time.Second
+	MinCheckInterval     = 10 * time.Second
+)
+
+// Settings are the user-configurable updater options, normally read from commands.Config.
+type Settings struct {
+	Channel        string        // See Channels; empty = stable
+	Proxy          string        // Proxy URL for update requests; empty uses HTTP_PROXY/HTTPS_PROXY
+	SourceDir      string        // Install updates from this directory instead of GitHub (offline installs)
+	CheckInterval  time.Duration // How often the notifier checks; 0 = DefaultCheckInterval
+	ChecksDisabled bool          // Stop background checks; manual checks still work
+	Policy         string        // See Policies; empty = manual
+	QuietHours     string        // Install window for PolicyQuietHours, "HH:MM-HH:MM"; empty = DefaultQuietHours
+
+	PinnedVersion   string   // Stay on (or move to) exactly this release; empty = follow the channel
+	SkippedVersions []string // Releases the user declined; never offered
+}
+
+var (
+	settings   Settings
+	settingsMu sync.RWMutex
+)
+
+// Configure validates and applies updater settings. On error nothing is changed.
+func Configure(s Settings) error {
+	if err := validateSettings(&s); err != nil {
+		return err
+	}
+	if err := SetChannel(s.Channel); err != nil {
+		return err
+	}
+	s.Channel = GetChannel()
+
+	settingsMu.Lock()
+	settings = s
+	settingsMu.Unlock()
+	return nil
+}
+
+// GetSettings returns the current updater settings with defaults applied.
+func GetSettings() Settings {
+	settingsMu.RLock()
+	s := settings
+	settingsMu.RUnlock()
+
+	s.Channel = GetChannel()
+	if s.CheckInterval == 0 {
+		s.CheckInterval = DefaultCheckInterval
+	}
+	if s.Policy == "" {
+		s.Policy = PolicyManual
+	}
+	if s.QuietHours == "" {
+		s.QuietHours = DefaultQuietHours
+	}
+	return s
+}
+
+// isSkipped reports whether the user chose to skip a release.
+func isSkipped(tag string) bool {
+	for _, v := range GetSettings().SkippedVersions {
+		if sameVersion(v, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameVersion compares version tags, ignoring a "v" prefix.
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(strings.TrimSpace(a), "v") == strings.TrimPrefix(strings.TrimSpace(b), "v")
+}
+
+// GetQuietHours returns the parsed quiet-hours window.
+func GetQuietHours() QuietHours {
+	q, err := ParseQuietHours(GetSettings().QuietHours)
+	if err != nil {
+		q, _ = ParseQuietHours(DefaultQuietHours)
+	}
+	return q
+}
+
+func validateSettings(s *Settings) error {
+	channelName := strings.ToLower(strings.TrimSpace(s.Channel))
+	if channelName != "" && !validChannel(channelName) {
+		return fmt.Errorf("unknown update channel %q", s.Channel)
+	}
+
+	s.Proxy = strings.TrimSpace(s.Proxy)
+	if s.Proxy != "" {
+		u, err := url.Parse(s.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid update proxy %q (expected e.g. http://proxy:8080)", s.Proxy)
+		}
+	}
+
+	s.SourceDir = strings.TrimSpace(s.SourceDir)
+	if s.SourceDir != "" {
+		info, err := os.Stat(s.SourceDir)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("update source %q is not a directory", s.SourceDir)
+		}
+	}
+
+	s.Policy = strings.ToLower(strings.TrimSpace(s.Policy))
+	if s.Policy != "" && !validPolicy(s.Policy) {
+		return fmt.Errorf("unknown update policy %q (expected manual, download, or quiet-hours)", s.Policy)
+	}
+	s.QuietHours = strings.TrimSpace(s.QuietHours)
+	if s.QuietHours != "" {
+		if _, err := ParseQuietHours(s.QuietHours); err != nil {
+			return err
+		}
+	}
+
+	s.PinnedVersion = strings.TrimSpace(s.PinnedVersion)
+	if s.PinnedVersion != "" {
+		if core, _ := splitPrerelease(strings.TrimPrefix(s.PinnedVersion, "v")); !strings.Contains(core, ".") {
+			return fmt.Errorf("invalid pinned version %q", s.PinnedVersion)
+		}
+	}
+
+	if s.CheckInterval != 0 && s.CheckInterval < MinCheckInterval {
+		return fmt.Errorf("update check interval must be at least %s", MinCheckInterval)
+	}
+	return nil
+}
+
+// httpClient returns a client for update requests that honors the configured
+// proxy, falling back to HTTP_PROXY/HTTPS_PROXY/NO_PROXY from the environment.
+func httpClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxy := GetSettings().Proxy; proxy != "" {
+		if u, err := url.Parse(proxy); err == nil {
+			transport.Proxy = http.ProxyURL(u)
+		}
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
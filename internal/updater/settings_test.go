@@ -0,0 +1,56 @@
+package updater
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConfigure(t *testing.T) {
+	defer Configure(Settings{})
+
+	dir := t.TempDir()
+	if err := Configure(Settings{Channel: "beta", Proxy: "http://proxy:8080", SourceDir: dir, CheckInterval: time.Minute}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	s := GetSettings()
+	if s.Channel != ChannelBeta || s.SourceDir != dir || s.CheckInterval != time.Minute {
+		t.Errorf("unexpected settings %+v", s)
+	}
+
+	invalid := []Settings{
+		{Channel: "canary"},
+		{Proxy: "proxy:8080"},
+		{SourceDir: dir + "/missing"},
+		{CheckInterval: time.Second},
+	}
+	for _, bad := range invalid {
+		if err := Configure(bad); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+	if GetSettings().SourceDir != dir {
+		t.Error("invalid settings should leave the previous settings in place")
+	}
+
+	if err := Configure(Settings{}); err != nil {
+		t.Fatal(err)
+	}
+	if s := GetSettings(); s.Channel != ChannelStable || s.CheckInterval != DefaultCheckInterval {
+		t.Errorf("expected defaults, got %+v", s)
+	}
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	defer Configure(Settings{})
+
+	if err := Configure(Settings{Proxy: "http://proxy.example:3128"}); err != nil {
+		t.Fatal(err)
+	}
+	transport := httpClient(time.Second).Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "https://api.github.com/", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.example:3128" {
+		t.Errorf("expected configured proxy, got %v (%v)", proxy, err)
+	}
+}
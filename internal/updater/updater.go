@@ -10,6 +10,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/audit"
 )
 
 // Version is set at build time via ldflags
@@ -21,12 +23,20 @@ const (
 	repoName  = "forge-terminal"
 )
 
+// apiBaseURL is the GitHub API root; overridden in tests
+var apiBaseURL = "https://api.github.com"
+
+// releaseScanLimit is how many recent releases are scanned for the newest on a channel
+const releaseScanLimit = 30
+
 // Release represents a GitHub release
 type Release struct {
 	TagName     string  `json:"tag_name"`
 	Name        string  `json:"name"`
 	Body        string  `json:"body"`
 	PublishedAt string  `json:"published_at"`
+	Prerelease  bool    `json:"prerelease"`
+	Draft       bool    `json:"draft"`
 	Assets      []Asset `json:"assets"`
 }
 
@@ -46,55 +56,67 @@ type UpdateInfo struct {
 	DownloadURL    string `json:"downloadUrl"`
 	AssetName      string `json:"assetName"`
 	AssetSize      int64  `json:"assetSize"`
+	Channel        string `json:"channel"`
+	Prerelease     bool   `json:"prerelease,omitempty"`
+	PatchURL       string `json:"patchUrl,omitempty"` // Delta from the running version, if published
+	PatchSize      int64  `json:"patchSize,omitempty"`
+	Pinned         bool   `json:"pinned,omitempty"`  // Target is the pinned version rather than the channel's latest
+	Skipped        bool   `json:"skipped,omitempty"` // Latest release was skipped by the user
 }
 
-// CheckForUpdate checks GitHub for a newer version
+// CheckForUpdate checks GitHub for a newer version on the current release channel
 func CheckForUpdate() (*UpdateInfo, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", repoOwner, repoName)
+	currentChannel := GetChannel()
+	pinned := GetSettings().PinnedVersion
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+	limit := releaseScanLimit
+	if pinned != "" {
+		limit = changelogScanLimit // The pinned release may be old
 	}
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "Forge-Terminal-Updater")
-
-	resp, err := client.Do(req)
+	releases, err := fetchReleases(limit)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 404 {
-		// No releases yet
+	var latest *Release
+	if pinned != "" {
+		latest = findRelease(releases, pinned)
+		if latest == nil {
+			return nil, fmt.Errorf("pinned version %s not found", pinned)
+		}
+	} else {
+		latest = latestOnChannel(releases, currentChannel)
+	}
+	if latest == nil {
+		// No releases on this channel yet
 		return &UpdateInfo{
 			Available:      false,
 			CurrentVersion: Version,
+			Channel:        currentChannel,
 		}, nil
 	}
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var release Release
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, err
-	}
+	release := *latest
 
 	// Parse version (remove 'v' prefix if present)
 	latestVersion := strings.TrimPrefix(release.TagName, "v")
 	currentVersion := strings.TrimPrefix(Version, "v")
 
-	// Simple version comparison - assumes semver format
+	// Simple version comparison - assumes semver format. A pinned version is
+	// offered whenever it differs, so pinning to an older release downgrades.
 	isNewer := compareVersions(latestVersion, currentVersion) > 0
+	if pinned != "" {
+		isNewer = latestVersion != currentVersion
+	}
+	skipped := pinned == "" && isSkipped(release.TagName)
 
-	if !isNewer {
+	if !isNewer || skipped {
 		return &UpdateInfo{
 			Available:      false,
 			CurrentVersion: Version,
 			LatestVersion:  release.TagName,
+			Channel:        currentChannel,
+			Pinned:         pinned != "",
+			Skipped:        isNewer && skipped,
 		}, nil
 	}
 
@@ -115,7 +137,7 @@ func CheckForUpdate() (*UpdateInfo, error) {
 		return nil, fmt.Errorf("no binary available for %s/%s", runtime.GOOS, runtime.GOARCH)
 	}
 
-	return &UpdateInfo{
+	info := &UpdateInfo{
 		Available:      true,
 		CurrentVersion: Version,
 		LatestVersion:  release.TagName,
@@ -123,7 +145,15 @@ func CheckForUpdate() (*UpdateInfo, error) {
 		DownloadURL:    downloadURL,
 		AssetName:      assetName,
 		AssetSize:      assetSize,
-	}, nil
+		Channel:        currentChannel,
+		Prerelease:     releaseChannel(release) != ChannelStable,
+		Pinned:         pinned != "",
+	}
+	if patch, ok := findPatch(release); ok {
+		info.PatchURL = patch.BrowserDownloadURL
+		info.PatchSize = patch.Size
+	}
+	return info, nil
 }
 
 // DownloadUpdate downloads the new binary to a temp location
@@ -136,7 +166,20 @@ func DownloadUpdate(info *UpdateInfo) (string, error) {
 	tmpDir := os.TempDir()
 	tmpFile := filepath.Join(tmpDir, "forge-update"+getExeSuffix())
 
-	client := &http.Client{Timeout: 5 * time.Minute}
+	// Offline source: copy the binary instead of downloading it
+	if path := localPath(info.DownloadURL); path != "" {
+		if err := copyFile(path, tmpFile); err != nil {
+			return "", err
+		}
+		return tmpFile, nil
+	}
+
+	// Prefer a delta (much smaller download) patched onto the running binary
+	if downloadWithPatch(info, tmpFile) {
+		return tmpFile, nil
+	}
+
+	client := httpClient(5 * time.Minute)
 	resp, err := client.Get(info.DownloadURL)
 	if err != nil {
 		return "", err
@@ -167,17 +210,29 @@ func DownloadUpdate(info *UpdateInfo) (string, error) {
 	return tmpFile, nil
 }
 
-// ApplyUpdate replaces the current binary with the new one
+// ApplyUpdate replaces the current binary with the new one, recording the
+// attempt in the audit log
 func ApplyUpdate(newBinaryPath string) error {
+	currentPath, err := applyUpdate(newBinaryPath)
+	audit.RecordResult(audit.Event{
+		Action: audit.UpdateApply,
+		Target: currentPath,
+		Detail: fmt.Sprintf("replaced version %s with %s", Version, newBinaryPath),
+	}, err)
+	return err
+}
+
+// applyUpdate installs newBinaryPath over the running binary, returning its path
+func applyUpdate(newBinaryPath string) (string, error) {
 	currentPath, err := os.Executable()
 	if err != nil {
-		return err
+		return currentPath, err
 	}
 
 	// Resolve symlinks
 	currentPath, err = filepath.EvalSymlinks(currentPath)
 	if err != nil {
-		return err
+		return currentPath, err
 	}
 
 	// On Windows, we can't replace a running binary directly
@@ -188,13 +243,13 @@ func ApplyUpdate(newBinaryPath string) error {
 		os.Remove(oldPath)
 		// Rename current to .old
 		if err := os.Rename(currentPath, oldPath); err != nil {
-			return fmt.Errorf("failed to backup current binary: %w", err)
+			return currentPath, fmt.Errorf("failed to backup current binary: %w", err)
 		}
 		// Copy new binary to current path
 		if err := copyFile(newBinaryPath, currentPath); err != nil {
 			// Restore backup
 			os.Rename(oldPath, currentPath)
-			return fmt.Errorf("failed to install new binary: %w", err)
+			return currentPath, fmt.Errorf("failed to install new binary: %w", err)
 		}
 		// Clean up
 		os.Remove(newBinaryPath)
@@ -204,14 +259,14 @@ func ApplyUpdate(newBinaryPath string) error {
 		if err := os.Rename(newBinaryPath, currentPath); err != nil {
 			// Fallback to copy
 			if err := copyFile(newBinaryPath, currentPath); err != nil {
-				return fmt.Errorf("failed to install new binary: %w", err)
+				return currentPath, fmt.Errorf("failed to install new binary: %w", err)
 			}
 			os.Remove(newBinaryPath)
 		}
 		os.Chmod(currentPath, 0755)
 	}
 
-	return nil
+	return currentPath, nil
 }
 
 // GetVersion returns the current version
@@ -231,30 +286,10 @@ type ReleaseInfo struct {
 
 // ListReleases returns the last N releases for rollback
 func ListReleases(limit int) ([]ReleaseInfo, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=%d", repoOwner, repoName, limit)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+	releases, err := fetchReleases(limit)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "Forge-Terminal-Updater")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var releases []Release
-	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-		return nil, err
-	}
 
 	assetName := getAssetName()
 	currentVersion := strings.TrimPrefix(Version, "v")
@@ -285,6 +320,47 @@ func ListReleases(limit int) ([]ReleaseInfo, error) {
 
 // Helper functions
 
+// fetchReleases returns the most recent releases, newest first, from GitHub or
+// the configured local source. A repo without releases returns an empty list.
+func fetchReleases(limit int) ([]Release, error) {
+	if dir := GetSettings().SourceDir; dir != "" {
+		releases, err := localReleases(dir)
+		if err != nil {
+			return nil, err
+		}
+		return releases[:min(len(releases), limit)], nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d", apiBaseURL, repoOwner, repoName, limit)
+
+	client := httpClient(10 * time.Second)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "Forge-Terminal-Updater")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return []Release{}, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
 func getAssetName() string {
 	goos := runtime.GOOS
 	arch := runtime.GOARCH
@@ -303,11 +379,19 @@ func getExeSuffix() string {
 	return ""
 }
 
+// CompareVersions compares two release versions, with or without a "v" prefix.
+// Returns 1 if a is newer, -1 if b is newer, and 0 if they are the same.
+func CompareVersions(a, b string) int {
+	return compareVersions(strings.TrimPrefix(strings.TrimSpace(a), "v"), strings.TrimPrefix(strings.TrimSpace(b), "v"))
+}
+
 func compareVersions(v1, v2 string) int {
-	// Simple semver comparison
+	// Semver comparison, including prerelease suffixes (1.2.0-beta.1 < 1.2.0)
 	// Returns: 1 if v1 > v2, -1 if v1 < v2, 0 if equal
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
+	core1, pre1 := splitPrerelease(v1)
+	core2, pre2 := splitPrerelease(v2)
+	parts1 := strings.Split(core1, ".")
+	parts2 := strings.Split(core2, ".")
 
 	for i := 0; i < 3; i++ {
 		var n1, n2 int
@@ -324,7 +408,7 @@ func compareVersions(v1, v2 string) int {
 			return -1
 		}
 	}
-	return 0
+	return comparePrerelease(pre1, pre2)
 }
 
 func copyFile(src, dst string) error {
@@ -0,0 +1,73 @@
+package updater
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withReleases serves releases from a fake GitHub API for the duration of a test
+func withReleases(t *testing.T, version string, releases []Release) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(releases)
+	}))
+	oldBase, oldVersion := apiBaseURL, Version
+	apiBaseURL, Version = server.URL, version
+	t.Cleanup(func() {
+		server.Close()
+		apiBaseURL, Version = oldBase, oldVersion
+		Configure(Settings{})
+	})
+}
+
+func release(tag string) Release {
+	return Release{TagName: tag, Assets: []Asset{{Name: getAssetName(), BrowserDownloadURL: "https://example.com/" + tag}}}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	withReleases(t, "1.1.0", []Release{release("v1.3.0"), release("v1.2.0"), release("v1.1.0"), release("v1.0.0")})
+
+	info, err := CheckForUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Available || info.LatestVersion != "v1.3.0" {
+		t.Errorf("expected v1.3.0 to be offered, got %+v", info)
+	}
+
+	// Skipping the latest release stops the notification
+	if err := Configure(Settings{SkippedVersions: []string{"1.3.0"}}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = CheckForUpdate()
+	if info.Available || !info.Skipped {
+		t.Errorf("skipped release should not be offered, got %+v", info)
+	}
+
+	// Pinning offers exactly that release, even as a downgrade
+	if err := Configure(Settings{PinnedVersion: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = CheckForUpdate()
+	if !info.Available || info.LatestVersion != "v1.0.0" || !info.Pinned {
+		t.Errorf("expected pinned v1.0.0, got %+v", info)
+	}
+
+	// Already on the pinned release
+	if err := Configure(Settings{PinnedVersion: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = CheckForUpdate()
+	if info.Available || !info.Pinned {
+		t.Errorf("expected no update while on the pinned version, got %+v", info)
+	}
+
+	if err := Configure(Settings{PinnedVersion: "v9.9.9"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CheckForUpdate(); err == nil {
+		t.Error("expected error for a pinned version that doesn't exist")
+	}
+}
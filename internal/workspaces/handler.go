@@ -0,0 +1,85 @@
+package workspaces
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+)
+
+// Request is the body for POST /api/workspaces
+type Request struct {
+	Action string `json:"action"` // "open", "pin", "unpin", or "remove"
+	Path   string `json:"path"`
+}
+
+// workspaceView adds fields computed at request time
+type workspaceView struct {
+	Workspace
+	Exists bool `json:"exists"`
+}
+
+// HandleWorkspaces lists workspaces (GET) or records/pins/removes one (POST)
+func HandleWorkspaces(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := List()
+		if err != nil {
+			http.Error(w, "Failed to load workspaces: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Flag directories that have since been moved or deleted so the picker can grey them out
+		views := make([]workspaceView, 0, len(list))
+		for _, ws := range list {
+			_, err := os.Stat(ws.Path)
+			views = append(views, workspaceView{Workspace: ws, Exists: err == nil})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"workspaces": views,
+		})
+
+	case http.MethodPost:
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.Path == "" {
+			http.Error(w, "Missing path", http.StatusBadRequest)
+			return
+		}
+
+		var ws *Workspace
+		var err error
+		switch req.Action {
+		case "open":
+			ws, err = RecordOpen(req.Path)
+		case "pin":
+			ws, err = SetPinned(req.Path, true)
+		case "unpin":
+			ws, err = SetPinned(req.Path, false)
+		case "remove":
+			err = Remove(req.Path)
+		default:
+			http.Error(w, "Unknown action: "+req.Action, http.StatusBadRequest)
+			return
+		}
+
+		if err != nil {
+			log.Printf("[Workspaces] %s %s failed: %v", req.Action, req.Path, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"workspace": ws,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,200 @@
+// Package workspaces tracks directories opened as workspace roots so new tabs
+// can offer recent and pinned projects.
+package workspaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mikejsmith1985/forge-terminal/internal/storage"
+)
+
+// MaxRecent is the number of unpinned workspaces kept in history
+const MaxRecent = 30
+
+// Workspace is a directory the user has opened as a workspace root.
+type Workspace struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name"`
+	Pinned     bool      `json:"pinned"`
+	LastOpened time.Time `json:"lastOpened"`
+	OpenCount  int       `json:"openCount"`
+}
+
+var mu sync.Mutex
+
+// load reads stored workspaces. Caller must hold mu.
+func load() ([]Workspace, error) {
+	data, err := storage.ReadJSONFile(storage.GetWorkspacesPath())
+	if os.IsNotExist(err) {
+		return []Workspace{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Workspace
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// save writes workspaces to disk. Caller must hold mu.
+func save(list []Workspace) error {
+	path := storage.GetWorkspacesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0600)
+}
+
+// sortWorkspaces orders pinned workspaces first, then by most recently opened.
+func sortWorkspaces(list []Workspace) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Pinned != list[j].Pinned {
+			return list[i].Pinned
+		}
+		return list[i].LastOpened.After(list[j].LastOpened)
+	})
+}
+
+// trimRecent drops the oldest unpinned workspaces beyond MaxRecent. list must be sorted.
+func trimRecent(list []Workspace) []Workspace {
+	kept := make([]Workspace, 0, len(list))
+	recent := 0
+	for _, ws := range list {
+		if !ws.Pinned {
+			recent++
+			if recent > MaxRecent {
+				continue
+			}
+		}
+		kept = append(kept, ws)
+	}
+	return kept
+}
+
+// List returns pinned workspaces followed by recent ones, newest first.
+func List() ([]Workspace, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+	sortWorkspaces(list)
+	return list, nil
+}
+
+// RecordOpen marks dir as opened now, adding it to the recent list if needed.
+func RecordOpen(dir string) (*Workspace, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", abs)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := find(list, abs)
+	if idx < 0 {
+		list = append(list, Workspace{Path: abs, Name: filepath.Base(abs)})
+		idx = len(list) - 1
+	}
+	list[idx].LastOpened = time.Now()
+	list[idx].OpenCount++
+	ws := list[idx]
+
+	sortWorkspaces(list)
+	if err := save(trimRecent(list)); err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// SetPinned pins or unpins a workspace, adding it if it isn't tracked yet.
+func SetPinned(dir string, pinned bool) (*Workspace, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := find(list, abs)
+	if idx < 0 {
+		if !pinned {
+			return nil, fmt.Errorf("workspace not found: %s", abs)
+		}
+		list = append(list, Workspace{Path: abs, Name: filepath.Base(abs), LastOpened: time.Now()})
+		idx = len(list) - 1
+	}
+	list[idx].Pinned = pinned
+	ws := list[idx]
+
+	sortWorkspaces(list)
+	if err := save(trimRecent(list)); err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// Remove deletes a workspace from history.
+func Remove(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return err
+	}
+
+	idx := find(list, abs)
+	if idx < 0 {
+		return fmt.Errorf("workspace not found: %s", abs)
+	}
+	return save(append(list[:idx], list[idx+1:]...))
+}
+
+func find(list []Workspace, path string) int {
+	for i, ws := range list {
+		if ws.Path == path {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,81 @@
+package workspaces
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func useTempStore(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+}
+
+func TestRecordOpen(t *testing.T) {
+	useTempStore(t)
+
+	first, second := t.TempDir(), t.TempDir()
+	RecordOpen(first)
+	time.Sleep(time.Millisecond)
+	RecordOpen(second)
+	time.Sleep(time.Millisecond)
+	ws, err := RecordOpen(first)
+	if err != nil {
+		t.Fatalf("RecordOpen failed: %v", err)
+	}
+	if ws.OpenCount != 2 {
+		t.Errorf("Expected open count 2, got %d", ws.OpenCount)
+	}
+
+	list, _ := List()
+	if len(list) != 2 || list[0].Path != first {
+		t.Errorf("Expected most recently opened first, got %+v", list)
+	}
+
+	if _, err := RecordOpen(filepath.Join(first, "missing")); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestPinAndRemove(t *testing.T) {
+	useTempStore(t)
+
+	pinned, recent := t.TempDir(), t.TempDir()
+	if _, err := SetPinned(pinned, true); err != nil {
+		t.Fatalf("SetPinned failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	RecordOpen(recent)
+
+	list, _ := List()
+	if len(list) != 2 || list[0].Path != pinned || !list[0].Pinned {
+		t.Errorf("Expected pinned workspace first, got %+v", list)
+	}
+
+	if err := Remove(pinned); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if list, _ := List(); len(list) != 1 {
+		t.Errorf("Expected 1 workspace after remove, got %d", len(list))
+	}
+	if err := Remove(pinned); err == nil {
+		t.Error("Expected error removing unknown workspace")
+	}
+}
+
+func TestTrimRecent(t *testing.T) {
+	list := []Workspace{{Path: "/pinned", Pinned: true}}
+	for i := 0; i < MaxRecent+5; i++ {
+		list = append(list, Workspace{Path: filepath.Join("/recent", string(rune('a'+i)))})
+	}
+
+	trimmed := trimRecent(list)
+	if len(trimmed) != MaxRecent+1 {
+		t.Errorf("Expected %d workspaces, got %d", MaxRecent+1, len(trimmed))
+	}
+	if !trimmed[0].Pinned {
+		t.Error("Expected pinned workspace to be kept")
+	}
+}